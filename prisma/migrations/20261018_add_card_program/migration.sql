@@ -0,0 +1,17 @@
+-- Migration: 20261018_add_card_program
+-- Description: Scope corporate cards and card transactions by issuer program so
+-- issuers other than Rain can reuse the same tables. Existing rows are Rain cards.
+
+-- AlterTable: corporate_cards
+ALTER TABLE "corporate_cards" ADD COLUMN "program" TEXT NOT NULL DEFAULT 'RAIN';
+-- Unique key may exist as a constraint (SQL bootstrap) or an index (Prisma)
+ALTER TABLE "corporate_cards" DROP CONSTRAINT IF EXISTS "corporate_cards_external_id_key";
+DROP INDEX IF EXISTS "corporate_cards_external_id_key";
+CREATE UNIQUE INDEX "corporate_cards_program_external_id_key" ON "corporate_cards"("program", "external_id");
+
+-- AlterTable: card_transactions
+ALTER TABLE "card_transactions" ADD COLUMN "program" TEXT NOT NULL DEFAULT 'RAIN';
+-- Unique key may exist as a constraint (SQL bootstrap) or an index (Prisma)
+ALTER TABLE "card_transactions" DROP CONSTRAINT IF EXISTS "card_transactions_external_id_key";
+DROP INDEX IF EXISTS "card_transactions_external_id_key";
+CREATE UNIQUE INDEX "card_transactions_program_external_id_key" ON "card_transactions"("program", "external_id");
@@ -0,0 +1,22 @@
+-- Migration: 20261019_add_notification_templates
+-- Description: Localized notification templates rendered by the webhook-handler
+-- (Go text/template syntax), and a per-user locale preference. Rows override
+-- the built-in English templates; changes are picked up without a redeploy.
+
+-- CreateTable: notification_templates
+CREATE TABLE IF NOT EXISTS "notification_templates" (
+    "id" TEXT NOT NULL,
+    "event_type" TEXT NOT NULL,
+    "locale" TEXT NOT NULL,
+    "title" TEXT NOT NULL,
+    "body" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "notification_templates_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "notification_templates_event_type_locale_key" ON "notification_templates"("event_type", "locale");
+
+-- AlterTable: auth_users
+ALTER TABLE "auth_users" ADD COLUMN "locale" TEXT;
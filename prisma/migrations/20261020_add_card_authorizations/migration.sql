@@ -0,0 +1,33 @@
+-- Migration: 20261020_add_card_authorizations
+-- Description: Approved card authorizations hold funds until their settlement
+-- arrives. The webhook-handler matches settlements back to authorizations
+-- (issuer ID, or amount/merchant/time), releases the hold difference, and
+-- flags settlements without a matching authorization for manual review.
+
+-- CreateTable: card_authorizations
+CREATE TABLE IF NOT EXISTS "card_authorizations" (
+    "id" TEXT NOT NULL,
+    "program" TEXT NOT NULL DEFAULT 'RAIN',
+    "external_id" TEXT NOT NULL,
+    "card_id" TEXT NOT NULL,
+    "merchant_name" TEXT,
+    "amount" DOUBLE PRECISION NOT NULL,
+    "currency" TEXT NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'PENDING',
+    "settled_amount" DOUBLE PRECISION,
+    "transaction_id" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "card_authorizations_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "card_authorizations_card_id_fkey" FOREIGN KEY ("card_id") REFERENCES "corporate_cards"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE UNIQUE INDEX "card_authorizations_program_external_id_key" ON "card_authorizations"("program", "external_id");
+CREATE INDEX "card_authorizations_card_id_status_idx" ON "card_authorizations"("card_id", "status");
+
+-- AlterTable: card_transactions
+ALTER TABLE "card_transactions" ADD COLUMN "authorization_id" TEXT;
+ALTER TABLE "card_transactions" ADD COLUMN "needs_review" BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE "card_transactions" ADD COLUMN "settled_at" TIMESTAMP(3);
+CREATE INDEX "card_transactions_needs_review_idx" ON "card_transactions"("needs_review");
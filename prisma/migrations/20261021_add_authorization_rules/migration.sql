@@ -0,0 +1,30 @@
+-- Migration: 20261021_add_authorization_rules
+-- Description: Declarative card authorization rules evaluated by the
+-- webhook-handler before funds are held. Each rule has a priority (lower runs
+-- first), a JSON list of conditions that must all match, and an action
+-- (decline / approve / flag). Changes are picked up without a redeploy.
+--
+-- conditions example:
+--   [{"field": "mcc", "op": "eq", "value": "7995"},
+--    {"field": "amount", "op": "gt", "value": 100},
+--    {"field": "hour", "op": "between", "value": [22, 6]}]
+
+-- CreateTable: authorization_rules
+CREATE TABLE IF NOT EXISTS "authorization_rules" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "priority" INTEGER NOT NULL DEFAULT 100,
+    "program" TEXT,
+    "conditions" JSONB NOT NULL DEFAULT '[]',
+    "action" TEXT NOT NULL,
+    "reason" TEXT,
+    "timezone" TEXT,
+    "enabled" BOOLEAN NOT NULL DEFAULT true,
+    "created_by" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "authorization_rules_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "authorization_rules_enabled_priority_idx" ON "authorization_rules"("enabled", "priority");
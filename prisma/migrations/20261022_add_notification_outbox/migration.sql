@@ -0,0 +1,39 @@
+-- Migration: 20261022_add_notification_outbox
+-- Description: Transactional processing for inbound webhooks in the
+-- webhook-handler. Each event is recorded in processed_webhook_events in the
+-- same transaction as its balance/order writes, so a retried event is applied
+-- exactly once. User notifications are written to notification_outbox in that
+-- transaction and delivered by a dispatcher with retries, so a crash between
+-- the write and the notification no longer loses it.
+
+-- CreateTable: processed_webhook_events
+CREATE TABLE IF NOT EXISTS "processed_webhook_events" (
+    "source" TEXT NOT NULL,
+    "event_id" TEXT NOT NULL,
+    "event_type" TEXT NOT NULL,
+    "payload" TEXT NOT NULL,
+    "processed_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "processed_webhook_events_pkey" PRIMARY KEY ("source", "event_id")
+);
+
+-- CreateTable: notification_outbox
+CREATE TABLE IF NOT EXISTS "notification_outbox" (
+    "id" TEXT NOT NULL,
+    "message_id" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "event_type" TEXT NOT NULL,
+    "vars" JSONB NOT NULL DEFAULT '{}',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "last_error" TEXT,
+    "dispatched_at" TIMESTAMP(3),
+    "failed_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "notification_outbox_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "notification_outbox_message_id_key" ON "notification_outbox"("message_id");
+CREATE INDEX "notification_outbox_dispatched_at_next_attempt_at_idx" ON "notification_outbox"("dispatched_at", "next_attempt_at");
@@ -4,18 +4,27 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
 	"github.com/protocol-bank/payout-engine/internal/handler"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
 	"github.com/protocol-bank/payout-engine/internal/nonce"
 	"github.com/protocol-bank/payout-engine/internal/queue"
 	"github.com/protocol-bank/payout-engine/internal/service"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -30,12 +39,22 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load config")
 	}
 
-	log.Info().Str("env", cfg.Environment).Msg("Starting Payout Engine")
+	log.Info().Str("env", cfg.Environment).Str("network", cfg.Network).Msg("Starting Payout Engine")
 
 	// 初始化组件
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// 链路追踪 (未配置 OTLP 端点时不导出)
+	cfg.Tracing.Environment = cfg.Environment
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	if cfg.Tracing.Endpoint != "" {
+		log.Info().Str("endpoint", cfg.Tracing.Endpoint).Float64("sample_ratio", cfg.Tracing.SampleRatio).Msg("OTLP tracing enabled")
+	}
+
 	// Nonce 管理器
 	nonceManager, err := nonce.NewManager(ctx, cfg.Redis)
 	if err != nil {
@@ -47,16 +66,179 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize queue consumer")
 	}
+	queueConsumer.SetRetryPolicy(queue.RetryPolicyFromConfig(cfg.JobRetry))
+	queueConsumer.SetCircuitPolicy(queue.CircuitPolicyFromConfig(cfg.Circuit))
+	queueConsumer.SetPriorityPolicy(queue.PriorityPolicyFromConfig(cfg.Priority))
+	queueConsumer.SetLeasePolicy(queue.LeasePolicyFromConfig(cfg.Lease))
+	for chainID, chain := range cfg.Chains {
+		if chain.Type == "tron" {
+			queueConsumer.SetChainConcurrency(chainID, cfg.TronBatchParallelism)
+		}
+	}
+
+	// 任务账本 (Postgres，可选)
+	var jobLedger *ledger.Store
+	if cfg.Database.URL != "" {
+		jobLedger, err = ledger.Open(ctx, cfg.Database.URL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize job ledger")
+		}
+		defer jobLedger.Close()
+		queueConsumer.EnableLedger()
+		log.Info().Msg("Job ledger enabled")
+	}
+
+	// 签名服务调用审计
+	if len(cfg.KMSAudit.Sinks) > 0 {
+		var sinks kms.MultiAuditSink
+		for _, sink := range cfg.KMSAudit.Sinks {
+			switch sink {
+			case config.KMSAuditStdout:
+				sinks = append(sinks, kms.NewJSONAuditSink(os.Stdout))
+			case config.KMSAuditPostgres:
+				sinks = append(sinks, jobLedger.KMSAuditSink())
+			case config.KMSAuditWebhook:
+				sinks = append(sinks, kms.NewWebhookAuditSink(cfg.KMSAudit.WebhookURL, cfg.KMSAudit.WebhookSecret, 10*time.Second))
+			}
+		}
+		cfg.SetSigningAudit(kms.AuditConfig{Sink: sinks, Required: cfg.KMSAudit.Required})
+		log.Info().Strs("sinks", cfg.KMSAudit.Sinks).Bool("required", cfg.KMSAudit.Required).Msg("KMS signing audit enabled")
+	}
+
+	// 签名限速与异常检测: 异常在支付服务创建后投递告警
+	signingAnomalies := make(chan kms.Anomaly, 64)
+	cfg.SetSigningAnomalyHandler(func(anomaly kms.Anomaly) {
+		select {
+		case signingAnomalies <- anomaly:
+		default: // 告警积压时丢弃，kms 已记录日志
+		}
+	})
+
+	// 签名器 (本地私钥、Fireblocks 或 MPC 门限签名)
+	signer, err := kms.NewSigner(ctx, cfg.KMS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize signer")
+	}
+	log.Info().Str("provider", signer.Provider()).Str("address", signer.Address().Hex()).Msg("Signer ready")
+	logKeyVersions(0, signer)
+	chainSigners := make(map[uint64]kms.Signer, len(cfg.ChainSigners))
+	for chainID, signerCfg := range cfg.ChainSigners {
+		chainSigner, err := kms.NewSigner(ctx, signerCfg)
+		if err != nil {
+			log.Fatal().Err(err).Uint64("chain_id", chainID).Msg("Failed to initialize chain signer")
+		}
+		chainSigners[chainID] = chainSigner
+		log.Info().Uint64("chain_id", chainID).Str("provider", chainSigner.Provider()).Str("address", chainSigner.Address().Hex()).Msg("Chain signer ready")
+		logKeyVersions(chainID, chainSigner)
+	}
 
 	// 支付服务
-	payoutService, err := service.NewPayoutService(ctx, cfg, nonceManager, queueConsumer)
+	payoutService, err := service.NewPayoutService(ctx, cfg, nonceManager, queueConsumer, signer, chainSigners, jobLedger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize payout service")
 	}
+	if cfg.TronKMS.Provider != "" {
+		tronSigner, err := kms.NewSigner(ctx, cfg.TronKMS)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize TRON signer")
+		}
+		payoutService.SetTronSigner(tronSigner)
+		log.Info().Str("provider", tronSigner.Provider()).Msg("TRON signer ready")
+	}
+
+	// 批次报告上传 (批次结束时排队生成)
+	if cfg.Reports.Store.Enabled() {
+		queueConsumer.EnableReports()
+	}
+
+	// 签名异常告警
+	go payoutService.RunSigningAlerts(ctx, signingAnomalies)
+
+	// 签名器健康检查: 首次检查通过前及签名器不可用时 gRPC 健康检查为 NOT_SERVING
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
 
 	// 启动队列消费者
 	go queueConsumer.Start(ctx, payoutService.ProcessJob)
 
+	// 启动卡单检测 (replace-by-fee)
+	go payoutService.RunStuckTxMonitor(ctx, cfg.StuckTxCheckInterval)
+
+	// 链熔断: 告警、探测与恢复
+	go payoutService.RunCircuitMonitor(ctx, cfg.Circuit.CheckInterval)
+
+	// 队列积压告警
+	go payoutService.RunQueueMonitor(ctx, cfg.QueueAlert.CheckInterval)
+
+	// 多节点探测 (延迟、故障恢复)
+	go payoutService.RunRPCProbes(ctx)
+
+	// 链配置热加载: CHAINS_FILE 变更或 SIGHUP
+	go payoutService.RunChainWatcher(ctx, cfg.ChainsWatchInterval)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := payoutService.ReloadChains(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to reload chain configuration")
+			}
+		}
+	}()
+
+	// 测试网模式: 自动水龙头充值
+	go payoutService.RunFaucetMonitor(ctx, cfg.FaucetCheckInterval)
+
+	// 运营地址 gas 自动补充
+	if cfg.GasTank.Funding.Provider != "" {
+		fundingSigner, err := kms.NewSigner(ctx, cfg.GasTank.Funding)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize gas tank funding signer")
+		}
+		payoutService.SetGasTankSigner(fundingSigner)
+		log.Info().Str("provider", fundingSigner.Provider()).Str("address", fundingSigner.Address().Hex()).Msg("Gas tank funding signer ready")
+	}
+	if cfg.GasTank.TronFunding.Provider != "" {
+		tronFundingSigner, err := kms.NewSigner(ctx, cfg.GasTank.TronFunding)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize TRON gas tank funding signer")
+		}
+		payoutService.SetTronGasTankSigner(tronFundingSigner)
+	}
+	go payoutService.RunGasTankMonitor(ctx, cfg.GasTank.CheckInterval)
+
+	// gas 补充资金钱包创建后开始签名器健康检查 (启动时立即检查一次)
+	go payoutService.RunSignerHealth(ctx, cfg.SignerHealthInterval, func(ready bool) {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !ready {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus("", status)
+	})
+
+	// 热钱包余额超过上限时归集到冷钱包
+	go payoutService.RunSweepScheduler(ctx, cfg.SweepInterval)
+
+	// 密钥轮换: 旧版本密钥地址的余额转到当前地址
+	go payoutService.RunKeyRotation(ctx, cfg.KeyRotationInterval)
+
+	// 基础费回落到上限以下时放回等待中的任务
+	go payoutService.RunGasCeilingMonitor(ctx, cfg.GasCeilingCheckInterval)
+
+	// 定时批次到期入队
+	go payoutService.RunBatchScheduler(ctx, cfg.ScheduleCheckInterval)
+
+	// 批次状态回调
+	go payoutService.RunWebhookDispatcher(ctx, cfg.WebhookDispatchInterval)
+
+	// 任务账本写入
+	go payoutService.RunLedgerWriter(ctx, cfg.Database.LedgerFlushInterval)
+
+	// 每日结算汇总
+	go payoutService.RunSettlementReporter(ctx, cfg.Settlement.CheckInterval)
+
+	// 批次报告生成与上传
+	go payoutService.RunReportWriter(ctx, cfg.Reports.CheckInterval)
+
 	// 启动 gRPC 服务器
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
 	if err != nil {
@@ -64,11 +246,13 @@ func main() {
 	}
 
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(handler.AuthInterceptor(cfg.APISecret)),
-		grpc.StreamInterceptor(handler.StreamAuthInterceptor(cfg.APISecret)),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(correlation.UnaryServerInterceptor(), handler.AuthInterceptor(cfg.APISecret)),
+		grpc.ChainStreamInterceptor(correlation.StreamServerInterceptor(), handler.StreamAuthInterceptor(cfg.APISecret)),
 	)
 
 	handler.RegisterPayoutServer(grpcServer, payoutService)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	if cfg.Environment == "development" || cfg.Environment == "" {
 		reflection.Register(grpcServer) // Only enable gRPC reflection in development
 	}
@@ -80,13 +264,53 @@ func main() {
 		}
 	}()
 
+	// 运维 REST 接口
+	var adminServer *http.Server
+	if cfg.AdminPort > 0 {
+		adminServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.AdminPort),
+			Handler:           handler.NewAdminHandler(payoutService, cfg.APISecret),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			log.Info().Int("port", cfg.AdminPort).Msg("Admin HTTP server listening")
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().Err(err).Msg("Failed to serve admin HTTP")
+			}
+		}()
+	}
+
 	// 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info().Msg("Shutting down...")
+	healthServer.Shutdown()
 	grpcServer.GracefulStop()
+	if adminServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			log.Warn().Err(err).Msg("Admin HTTP server shutdown failed")
+		}
+		shutdownCancel()
+	}
 	cancel()
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush traces")
+	}
+	tracingCancel()
 	log.Info().Msg("Payout Engine stopped")
 }
+
+// logKeyVersions 记录轮换中的旧版本密钥 (chainID 为 0 时为默认签名器)
+func logKeyVersions(chainID uint64, signer kms.Signer) {
+	for _, v := range kms.KeyVersions(signer, time.Now())[1:] {
+		event := log.Info().Uint64("chain_id", chainID).Str("version", v.Version).Str("address", v.Address.Hex())
+		if v.RetireAt != nil {
+			event = event.Time("retire_at", *v.RetireAt)
+		}
+		event.Msg("Previous signing key in transition window")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/protocol-bank/payout-engine/pb"
+)
+
+// batchColumns CSV 表头 (不区分大小写，顺序不限)。recipient_address 和 amount 必填，
+// id 为空时按行号生成 (item-1, item-2, ...)，token_address 为空表示原生代币。
+var batchColumns = []string{"id", "recipient_address", "amount", "token_address", "token_symbol", "token_decimals", "token_id", "max_fee", "memo"}
+
+// readBatchCSV 读取批次支付项
+func readBatchCSV(r io.Reader) ([]*pb.PayoutItem, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv is empty")
+		}
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !knownColumn(name) {
+			return nil, fmt.Errorf("unknown csv column %q (expected %s)", name, strings.Join(batchColumns, ", "))
+		}
+		index[name] = i
+	}
+	for _, required := range []string{"recipient_address", "amount"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("csv column %q is required", required)
+		}
+	}
+
+	var items []*pb.PayoutItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		item := &pb.PayoutItem{
+			Id:               field("id"),
+			RecipientAddress: field("recipient_address"),
+			Amount:           field("amount"),
+			TokenAddress:     field("token_address"),
+			TokenSymbol:      field("token_symbol"),
+			TokenId:          field("token_id"),
+			MaxFee:           field("max_fee"),
+			Memo:             field("memo"),
+		}
+		if item.RecipientAddress == "" || item.Amount == "" {
+			return nil, fmt.Errorf("line %d: recipient_address and amount are required", line)
+		}
+		if item.Id == "" {
+			item.Id = fmt.Sprintf("item-%d", len(items)+1)
+		}
+		if decimals := field("token_decimals"); decimals != "" {
+			n, err := strconv.ParseUint(decimals, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid token_decimals %q", line, decimals)
+			}
+			item.TokenDecimals = uint32(n)
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("csv has no payout items")
+	}
+	return items, nil
+}
+
+func knownColumn(name string) bool {
+	for _, c := range batchColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
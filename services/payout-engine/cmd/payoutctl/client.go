@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// client 引擎的 gRPC 接口和运维 REST 接口 (均以 x-api-key 认证)
+type client struct {
+	grpcAddr string
+	useTLS   bool
+	adminURL string
+	apiKey   string
+
+	conn       *grpc.ClientConn
+	httpClient *http.Client
+}
+
+func newClient(grpcAddr, adminURL, apiKey string, useTLS bool) *client {
+	return &client{
+		grpcAddr:   grpcAddr,
+		useTLS:     useTLS,
+		adminURL:   strings.TrimRight(adminURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// payout 按需建立 gRPC 连接
+func (c *client) payout() (pb.PayoutServiceClient, error) {
+	if c.conn == nil {
+		creds := insecure.NewCredentials()
+		if c.useTLS {
+			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+		conn, err := grpc.NewClient(c.grpcAddr, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", c.grpcAddr, err)
+		}
+		c.conn = conn
+	}
+	return pb.NewPayoutServiceClient(c.conn), nil
+}
+
+// rpcContext 附带 API key 的 gRPC 调用上下文
+func (c *client) rpcContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", c.apiKey)
+}
+
+func (c *client) Close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// admin 调用运维 REST 接口，out 为 nil 时丢弃响应。非 2xx 响应返回接口的 error 字段。
+func (c *client) admin(ctx context.Context, method, path string, query url.Values, body io.Reader, out interface{}) error {
+	endpoint := c.adminURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s %s: %s (%d)", method, path, apiErr.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
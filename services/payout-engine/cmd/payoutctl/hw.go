@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+)
+
+// hardwareSigner Ledger / Trezor 签名器 (测试中替换为本地私钥)
+type hardwareSigner interface {
+	kms.Signer
+	Wallet() string
+	VerifyAddress(ctx context.Context) error
+	Close() error
+}
+
+var openHardwareSigner = func(ctx context.Context, cfg kms.HardwareConfig) (hardwareSigner, error) {
+	return kms.NewHardwareSigner(ctx, cfg)
+}
+
+// erc20TransferSelector transfer(address,uint256)
+var erc20TransferSelector = common.FromHex("0xa9059cbb")
+
+// hwFlags 硬件钱包命令的公共参数
+func hwFlags(fs *flag.FlagSet) *kms.HardwareConfig {
+	cfg := &kms.HardwareConfig{Prompt: promptStdin}
+	fs.StringVar(&cfg.Wallet, "wallet", kms.WalletLedger, "hardware wallet: ledger or trezor")
+	fs.StringVar(&cfg.Path, "path", kms.DefaultHardwarePath, "BIP-32 derivation path")
+	fs.StringVar(&cfg.Address, "from", "", "expected address at -path (optional)")
+	return cfg
+}
+
+// hwAddressCmd 在设备屏幕上显示并确认签名地址
+func hwAddressCmd(ctx context.Context, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("hw address", flag.ContinueOnError)
+	cfg := hwFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	signer, err := openHardwareSigner(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+
+	fmt.Fprintf(out, "%s %s: %s\nconfirm the address shown on the device matches\n", signer.Wallet(), cfg.Path, signer.Address().Hex())
+	if err := signer.VerifyAddress(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "address confirmed on device")
+	return nil
+}
+
+// hwSendCmd 由硬件钱包签名一笔手动付款 (原生代币或 ERC-20)，直接通过链节点广播。
+// 交易不经过引擎的队列和 nonce 管理，用于低频、大额的手动付款地址。
+func hwSendCmd(ctx context.Context, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("hw send", flag.ContinueOnError)
+	cfg := hwFlags(fs)
+	rpcURL := fs.String("rpc", os.Getenv("PAYOUTCTL_RPC_URL"), "chain RPC URL")
+	chainID := fs.Uint64("chain-id", 0, "chain ID (checked against the RPC endpoint)")
+	to := fs.String("to", "", "recipient address")
+	amount := fs.String("amount", "", "amount in the token's smallest unit (wei for native)")
+	token := fs.String("token", "", "ERC-20 token contract (empty = native token)")
+	verify := fs.Bool("verify-address", true, "confirm the signing address on the device first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rpcURL == "" || *chainID == 0 || *to == "" || *amount == "" {
+		return fmt.Errorf("hw send requires -rpc, -chain-id, -to and -amount")
+	}
+	if !common.IsHexAddress(*to) || (*token != "" && !common.IsHexAddress(*token)) {
+		return fmt.Errorf("invalid -to or -token address")
+	}
+	value, ok := new(big.Int).SetString(*amount, 10)
+	if !ok || value.Sign() <= 0 {
+		return fmt.Errorf("invalid -amount: %s", *amount)
+	}
+
+	eth, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return err
+	}
+	defer eth.Close()
+	remote, err := eth.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if remote.Uint64() != *chainID {
+		return fmt.Errorf("rpc endpoint is on chain %d, not %d", remote.Uint64(), *chainID)
+	}
+
+	signer, err := openHardwareSigner(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+	if *verify {
+		fmt.Fprintf(out, "confirm the address %s on the device\n", signer.Address().Hex())
+		if err := signer.VerifyAddress(ctx); err != nil {
+			return err
+		}
+	}
+
+	tx, err := buildManualTx(ctx, eth, signer.Wallet(), signer.Address(), common.HexToAddress(*to), *token, value, remote)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "from:   %s\nto:     %s\n", signer.Address().Hex(), *to)
+	if *token != "" {
+		fmt.Fprintf(out, "token:  %s\n", *token)
+	}
+	fmt.Fprintf(out, "amount: %s\nnonce:  %d\ngas:    %d (max fee %s wei/gas)\nreview and confirm the transaction on the device\n",
+		value, tx.Nonce(), tx.Gas(), tx.GasFeeCap())
+
+	signed, err := signer.SignTransaction(ctx, tx, remote)
+	if err != nil {
+		return err
+	}
+	if err := eth.SendTransaction(ctx, signed); err != nil {
+		return fmt.Errorf("broadcast failed: %w", err)
+	}
+	fmt.Fprintf(out, "sent %s\n", signed.Hash().Hex())
+	return nil
+}
+
+// buildManualTx 构建付款交易。Trezor 的以太坊应用只能签名 legacy 交易，其他设备使用 EIP-1559。
+func buildManualTx(ctx context.Context, eth *ethclient.Client, wallet string, from, to common.Address, token string, amount, chainID *big.Int) (*types.Transaction, error) {
+	call := ethereum.CallMsg{From: from, To: &to, Value: amount}
+	if token != "" {
+		tokenAddr := common.HexToAddress(token)
+		call = ethereum.CallMsg{From: from, To: &tokenAddr, Value: new(big.Int), Data: erc20TransferData(to, amount)}
+	}
+	nonce, err := eth.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := eth.EstimateGas(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	}
+
+	if wallet == kms.WalletTrezor {
+		gasPrice, err := eth.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce: nonce, GasPrice: gasPrice, Gas: gas, To: call.To, Value: call.Value, Data: call.Data,
+		}), nil
+	}
+	tip, err := eth.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, errors.New("chain does not support EIP-1559")
+	}
+	feeCap := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: nonce, GasTipCap: tip, GasFeeCap: feeCap, Gas: gas, To: call.To, Value: call.Value, Data: call.Data,
+	}), nil
+}
+
+// erc20TransferData transfer(to, amount) 的调用数据
+func erc20TransferData(to common.Address, amount *big.Int) []byte {
+	data := append([]byte{}, erc20TransferSelector...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+}
+
+// promptStdin 从终端读取 Trezor One 的 PIN 矩阵位置或 passphrase
+func promptStdin(kind string) (string, error) {
+	switch kind {
+	case "pin":
+		fmt.Fprintln(os.Stderr, "enter the PIN using the positions shown on the device (keypad layout 7 8 9 / 4 5 6 / 1 2 3):")
+	default:
+		fmt.Fprintf(os.Stderr, "enter the wallet %s:\n", kind)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
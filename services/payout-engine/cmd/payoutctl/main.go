@@ -0,0 +1,440 @@
+// payoutctl 支付引擎运维命令行: 通过引擎的 gRPC 和运维 REST 接口提交批次、查看签名地址余额、
+// 查询任务、重新入队死信队列中的支付和切换 RPC 节点，以及用 Ledger / Trezor 签名手动付款。
+//
+//	payoutctl [全局参数] <命令> [参数]
+//
+// 全局参数默认取环境变量 PAYOUTCTL_GRPC_ADDR、PAYOUTCTL_ADMIN_URL 和 API_SECRET (与引擎相同)。
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/pb"
+)
+
+const usage = `Usage: payoutctl [global flags] <command> [flags]
+
+Commands:
+  submit      submit a batch from a CSV file
+  signers     show signer addresses and balances per chain
+  job         inspect a job
+  dlq list    list failed payouts in the dead-letter queue
+  dlq requeue requeue failed payouts
+  rpc status  show RPC endpoint health per chain
+  rpc rotate  reload chain config so edited RPC endpoints take effect
+  hw address  show and confirm the hardware wallet address on the device
+  hw send     sign a manual payout on a Ledger/Trezor and broadcast it
+              (requires a cgo build; -timeout defaults to 5m to leave time to confirm)
+
+Global flags:
+`
+
+func main() {
+	global := flag.NewFlagSet("payoutctl", flag.ExitOnError)
+	grpcAddr := global.String("grpc", getEnv("PAYOUTCTL_GRPC_ADDR", "localhost:50051"), "engine gRPC address")
+	adminURL := global.String("admin", getEnv("PAYOUTCTL_ADMIN_URL", "http://localhost:8081"), "engine admin REST base URL")
+	apiKey := global.String("api-key", os.Getenv("API_SECRET"), "engine API key (x-api-key)")
+	useTLS := global.Bool("tls", false, "use TLS for gRPC")
+	timeout := global.Duration("timeout", 30*time.Second, "request timeout")
+	global.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		global.PrintDefaults()
+	}
+	global.Parse(os.Args[1:])
+	if global.NArg() == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+	// 硬件钱包命令需要等待设备上的人工确认
+	if global.Arg(0) == "hw" && !flagSet(global, "timeout") {
+		*timeout = 5 * time.Minute
+	}
+
+	c := newClient(*grpcAddr, *adminURL, *apiKey, *useTLS)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	err := run(ctx, c, os.Stdout, global.Args())
+	cancel()
+	c.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "payoutctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run 执行子命令
+func run(ctx context.Context, c *client, out io.Writer, args []string) error {
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "submit":
+		return submitCmd(ctx, c, out, args)
+	case "signers":
+		return signersCmd(ctx, c, out, args)
+	case "job":
+		return jobCmd(ctx, c, out, args)
+	case "dlq":
+		if len(args) > 0 && args[0] == "list" {
+			return dlqListCmd(ctx, c, out, args[1:])
+		}
+		if len(args) > 0 && args[0] == "requeue" {
+			return dlqRequeueCmd(ctx, c, out, args[1:])
+		}
+		return fmt.Errorf("usage: payoutctl dlq list|requeue [flags]")
+	case "rpc":
+		if len(args) > 0 && args[0] == "status" {
+			return rpcStatusCmd(ctx, c, out)
+		}
+		if len(args) > 0 && args[0] == "rotate" {
+			return rpcRotateCmd(ctx, c, out)
+		}
+		return fmt.Errorf("usage: payoutctl rpc status|rotate")
+	case "hw":
+		if len(args) > 0 && args[0] == "address" {
+			return hwAddressCmd(ctx, out, args[1:])
+		}
+		if len(args) > 0 && args[0] == "send" {
+			return hwSendCmd(ctx, out, args[1:])
+		}
+		return fmt.Errorf("usage: payoutctl hw address|send [flags]")
+	default:
+		return fmt.Errorf("unknown command %q (run payoutctl -h)", cmd)
+	}
+}
+
+// submitCmd 从 CSV 提交批次 (表头见 batchColumns)
+func submitCmd(ctx context.Context, c *client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ContinueOnError)
+	file := fs.String("csv", "", "CSV file with payout items (- for stdin)")
+	batchID := fs.String("batch-id", "", "batch ID")
+	userID := fs.String("user-id", "", "user ID")
+	from := fs.String("from", "", "payout address (the chain's signer address)")
+	chainID := fs.Uint64("chain-id", 0, "chain ID")
+	priority := fs.String("priority", "", "fee priority: LOW / MEDIUM / HIGH / URGENT")
+	allowPartial := fs.Bool("allow-partial", false, "queue the items the balance covers when it cannot cover the whole batch")
+	idempotencyKey := fs.String("idempotency-key", "", "idempotency key (defaults to the batch ID)")
+	simulate := fs.Bool("simulate", false, "dry run without queueing")
+	duplicates := fs.String("duplicates", "", "duplicate rows (same recipient, token and amount): flag (skip them) or merge (sum into the first row)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || *batchID == "" || *userID == "" || *from == "" || *chainID == 0 {
+		return fmt.Errorf("submit requires -csv, -batch-id, -user-id, -from and -chain-id")
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	items, err := readBatchCSV(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+
+	payout, err := c.payout()
+	if err != nil {
+		return err
+	}
+	resp, err := payout.SubmitBatchPayout(c.rpcContext(ctx), &pb.BatchPayoutRequest{
+		BatchId:        *batchID,
+		UserId:         *userID,
+		FromAddress:    *from,
+		ChainId:        *chainID,
+		Items:          items,
+		Priority:       *priority,
+		AllowPartial:   *allowPartial,
+		IdempotencyKey: *idempotencyKey,
+		Simulate:       *simulate,
+		DuplicateMode:  *duplicates,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "batch %s: %s (%d items)\n", resp.GetBatchId(), resp.GetStatus(), len(items))
+	if resp.GetMessage() != "" {
+		fmt.Fprintln(out, resp.GetMessage())
+	}
+	if resp.GetReplayed() {
+		fmt.Fprintln(out, "already submitted with this idempotency key, nothing queued")
+	}
+	if resp.GetEstimatedGasCost() != "" {
+		fmt.Fprintf(out, "estimated gas cost: %s\n", resp.GetEstimatedGasCost())
+	}
+	if resp.GetManifestHash() != "" {
+		fmt.Fprintf(out, "manifest: %s (signed by %s)\n", resp.GetManifestHash(), resp.GetManifestSigner())
+	}
+	if len(resp.GetRejected()) > 0 {
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REJECTED\tREASON")
+		for _, item := range resp.GetRejected() {
+			fmt.Fprintf(tw, "%s\t%s\n", item.GetItemId(), item.GetReason())
+		}
+		tw.Flush()
+	}
+	if len(resp.GetDuplicates()) > 0 {
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DUPLICATE	OF	DECISION")
+		for _, item := range resp.GetDuplicates() {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", item.GetItemId(), item.GetDuplicateOf(), item.GetDecision())
+		}
+		tw.Flush()
+	}
+	return nil
+}
+
+// signersCmd 各链付款签名地址的余额、待支出和可用金额
+func signersCmd(ctx context.Context, c *client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("signers", flag.ContinueOnError)
+	chainID := fs.Uint64("chain-id", 0, "only this chain (0 = all chains)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	payout, err := c.payout()
+	if err != nil {
+		return err
+	}
+	resp, err := payout.GetWalletInventory(c.rpcContext(ctx), &pb.WalletInventoryRequest{ChainId: *chainID})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tNAME\tADDRESS\tTOKEN\tBALANCE\tPENDING\tAVAILABLE\tJOBS\tRUNWAY")
+	for _, w := range resp.GetWallets() {
+		token := w.GetTokenSymbol()
+		if token == "" {
+			token = w.GetTokenAddress()
+		}
+		runway := "-"
+		if w.RunwayDays != nil {
+			runway = fmt.Sprintf("%.1fd", w.GetRunwayDays())
+		}
+		balance := w.GetBalance()
+		if w.GetErrorMessage() != "" {
+			balance = "error: " + w.GetErrorMessage()
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			w.GetChainId(), w.GetChainName(), w.GetAddress(), token, balance, w.GetPendingOutflow(), w.GetAvailable(), w.GetPendingJobs(), runway)
+	}
+	return tw.Flush()
+}
+
+// jobCmd 任务状态 (JSON)，-attempts 时另外列出各次发送记录
+func jobCmd(ctx context.Context, c *client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("job", flag.ContinueOnError)
+	jobID := fs.String("id", "", "job ID")
+	userID := fs.String("user-id", "", "user ID (optional)")
+	attempts := fs.Bool("attempts", false, "also show send attempts (requires the job ledger)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *jobID == "" {
+		return fmt.Errorf("job requires -id")
+	}
+	query := url.Values{}
+	if *userID != "" {
+		query.Set("user_id", *userID)
+	}
+
+	path := "/jobs/" + url.PathEscape(*jobID)
+	var job json.RawMessage
+	if err := c.admin(ctx, http.MethodGet, path, query, nil, &job); err != nil {
+		return err
+	}
+	if err := printJSON(out, job); err != nil {
+		return err
+	}
+	if !*attempts {
+		return nil
+	}
+	var history json.RawMessage
+	if err := c.admin(ctx, http.MethodGet, path+"/attempts", query, nil, &history); err != nil {
+		return err
+	}
+	return printJSON(out, history)
+}
+
+// dlqListCmd 死信队列中的失败支付
+func dlqListCmd(ctx context.Context, c *client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("dlq list", flag.ContinueOnError)
+	batchID := fs.String("batch-id", "", "only this batch")
+	offset := fs.Int("offset", 0, "offset")
+	limit := fs.Int("limit", 100, "max items (up to 500)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	payout, err := c.payout()
+	if err != nil {
+		return err
+	}
+	resp, err := payout.ListFailedPayouts(c.rpcContext(ctx), &pb.ListFailedPayoutsRequest{
+		BatchId: *batchID,
+		Offset:  int32(*offset),
+		Limit:   int32(*limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tBATCH\tCHAIN\tRECIPIENT\tAMOUNT\tATTEMPTS\tPERMANENT\tFAILED_AT\tCODE\tERROR")
+	for _, item := range resp.GetItems() {
+		code := item.GetChainErrorCode()
+		if code == "" {
+			code = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%d\t%t\t%s\t%s\t%s\n",
+			item.GetId(), item.GetBatchId(), item.GetChainId(), item.GetRecipientAddress(), item.GetAmount(),
+			item.GetAttempts(), item.GetPermanent(), item.GetFailedAt().AsTime().Format(time.RFC3339), code, item.GetErrorMessage())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d of %d failed payouts\n", len(resp.GetItems()), resp.GetTotal())
+	return nil
+}
+
+// dlqRequeueCmd 将失败支付重新入队 (批次内全部，或指定的支付项)
+func dlqRequeueCmd(ctx context.Context, c *client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("dlq requeue", flag.ContinueOnError)
+	batchID := fs.String("batch-id", "", "batch ID (all failed items of the batch unless -items is set)")
+	items := fs.String("items", "", "comma-separated item IDs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var itemIDs []string
+	for _, id := range strings.Split(*items, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			itemIDs = append(itemIDs, id)
+		}
+	}
+	if *batchID == "" && len(itemIDs) == 0 {
+		return fmt.Errorf("dlq requeue requires -batch-id or -items")
+	}
+	payout, err := c.payout()
+	if err != nil {
+		return err
+	}
+	resp, err := payout.RetryFailedPayouts(c.rpcContext(ctx), &pb.RetryRequest{BatchId: *batchID, ItemIds: itemIDs})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, resp.GetMessage())
+	return nil
+}
+
+// rpcStatus GET /rpc 的响应
+type rpcStatus struct {
+	Chains []struct {
+		ChainID   uint64 `json:"chain_id"`
+		Name      string `json:"name"`
+		Available bool   `json:"available"`
+		Endpoints []struct {
+			URL       string     `json:"url"`
+			Latency   int64      `json:"latency_ns"`
+			Failures  int        `json:"failures"`
+			Height    uint64     `json:"height"`
+			Available bool       `json:"available"`
+			Degraded  bool       `json:"degraded"`
+			DownUntil *time.Time `json:"down_until"`
+			LastError string     `json:"last_error"`
+		} `json:"endpoints"`
+	} `json:"chains"`
+}
+
+// rpcStatusCmd 各链节点的延迟、失败次数和下线状态 (节点地址已脱敏)
+func rpcStatusCmd(ctx context.Context, c *client, out io.Writer) error {
+	var status rpcStatus
+	if err := c.admin(ctx, http.MethodGet, "/rpc", nil, nil, &status); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tNAME\tENDPOINT\tSTATE\tLATENCY\tHEIGHT\tFAILURES\tLAST_ERROR")
+	for _, chain := range status.Chains {
+		for _, ep := range chain.Endpoints {
+			state := "up"
+			switch {
+			case !ep.Available && ep.DownUntil != nil:
+				state = "down until " + ep.DownUntil.Format(time.RFC3339)
+			case !ep.Available:
+				state = "down"
+			case ep.Degraded:
+				state = "degraded"
+			}
+			height := "-"
+			if ep.Height > 0 {
+				height = strconv.FormatUint(ep.Height, 10)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				chain.ChainID, chain.Name, ep.URL, state, time.Duration(ep.Latency).Round(time.Millisecond), height, ep.Failures, ep.LastError)
+		}
+	}
+	return tw.Flush()
+}
+
+// rpcRotateCmd 重新加载链配置 (POST /chains/reload): 在 CHAINS_FILE 中修改节点地址后执行，
+// 节点变化的链重新连接，连接失败时保留原有节点
+func rpcRotateCmd(ctx context.Context, c *client, out io.Writer) error {
+	var result struct {
+		Added   []uint64          `json:"added"`
+		Updated []uint64          `json:"updated"`
+		Removed []uint64          `json:"removed"`
+		Failed  map[uint64]string `json:"failed"`
+	}
+	if err := c.admin(ctx, http.MethodPost, "/chains/reload", nil, nil, &result); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "added: %v\nupdated: %v\nremoved: %v\n", result.Added, result.Updated, result.Removed)
+	if len(result.Failed) == 0 {
+		return nil
+	}
+	for chainID, reason := range result.Failed {
+		fmt.Fprintf(out, "chain %d kept its previous endpoints: %s\n", chainID, reason)
+	}
+	return fmt.Errorf("%d chains failed to reconnect", len(result.Failed))
+}
+
+// printJSON 缩进输出接口响应 (保留字段顺序)
+func printJSON(out io.Writer, raw json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(out)
+	return err
+}
+
+// flagSet 命令行中是否显式设置了该参数
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
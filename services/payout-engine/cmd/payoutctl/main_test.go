@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBatchCSV(t *testing.T) {
+	items, err := readBatchCSV(strings.NewReader(`Recipient_Address, amount, token_address, token_decimals, id
+0x2222222222222222222222222222222222222222, 1000000, 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48, 6, inv-1
+0x3333333333333333333333333333333333333333, 5,,,
+`))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "inv-1", items[0].GetId())
+	assert.Equal(t, "1000000", items[0].GetAmount())
+	assert.Equal(t, uint32(6), items[0].GetTokenDecimals())
+	assert.Equal(t, "item-2", items[1].GetId(), "missing id falls back to the row number")
+	assert.Equal(t, "", items[1].GetTokenAddress())
+
+	for name, input := range map[string]string{
+		"empty":            "",
+		"missing amount":   "recipient_address\n0x2222222222222222222222222222222222222222\n",
+		"unknown column":   "recipient_address,amount,note\n0x2,1,x\n",
+		"blank amount":     "recipient_address,amount\n0x2,\n",
+		"invalid decimals": "recipient_address,amount,token_decimals\n0x2,1,six\n",
+		"no items":         "recipient_address,amount\n",
+	} {
+		_, err := readBatchCSV(strings.NewReader(input))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAdminCommands(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid api key"}`))
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /jobs/job-1":
+			assert.Equal(t, "user-1", r.URL.Query().Get("user_id"))
+			w.Write([]byte(`{"job_id":"job-1","state":"confirmed"}`))
+		case "GET /jobs/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"job not found"}`))
+		case "GET /rpc":
+			w.Write([]byte(`{"chains":[{"chain_id":1,"name":"Ethereum","available":true,"endpoints":[
+				{"url":"https://eth.example/***","latency_ns":42000000,"height":100,"available":true},
+				{"url":"https://backup.example","available":false,"failures":5,"last_error":"timeout"}]}]}`))
+		case "POST /chains/reload":
+			w.Write([]byte(`{"added":[],"updated":[1],"removed":[],"failed":{"137":"dial failed"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	c := newClient("", srv.URL, "secret", false)
+
+	var out bytes.Buffer
+	require.NoError(t, run(ctx, c, &out, []string{"job", "-id", "job-1", "-user-id", "user-1"}))
+	assert.Equal(t, "{\n  \"job_id\": \"job-1\",\n  \"state\": \"confirmed\"\n}\n", out.String())
+
+	err := run(ctx, c, &out, []string{"job", "-id", "missing"})
+	assert.EqualError(t, err, "GET /jobs/missing: job not found (404)")
+
+	out.Reset()
+	require.NoError(t, run(ctx, c, &out, []string{"rpc", "status"}))
+	assert.Contains(t, out.String(), "https://eth.example/***")
+	assert.Contains(t, out.String(), "42ms")
+	assert.Contains(t, out.String(), "timeout")
+
+	// 重新连接失败的链保留原有节点，命令返回错误
+	out.Reset()
+	err = run(ctx, c, &out, []string{"rpc", "rotate"})
+	assert.Error(t, err)
+	assert.Contains(t, out.String(), "chain 137 kept its previous endpoints: dial failed")
+
+	err = run(ctx, newClient("", srv.URL, "wrong", false), &out, []string{"rpc", "status"})
+	assert.EqualError(t, err, "GET /rpc: invalid api key (401)")
+
+	assert.Error(t, run(ctx, c, &out, []string{"dlq"}))
+	assert.Error(t, run(ctx, c, &out, []string{"unknown"}))
+}
+
+// fakeEth 手动付款用到的链节点接口
+type fakeEth struct {
+	sent []*types.Transaction
+}
+
+func (e *fakeEth) ChainId() *hexutil.Big { return (*hexutil.Big)(big.NewInt(8453)) }
+
+func (e *fakeEth) GetTransactionCount(common.Address, string) hexutil.Uint64 { return 12 }
+
+func (e *fakeEth) EstimateGas(map[string]interface{}) hexutil.Uint64 { return 52000 }
+
+func (e *fakeEth) MaxPriorityFeePerGas() *hexutil.Big { return (*hexutil.Big)(big.NewInt(1e6)) }
+
+func (e *fakeEth) GetBlockByNumber(string, bool) *types.Header {
+	return &types.Header{Number: big.NewInt(100), Difficulty: new(big.Int), BaseFee: big.NewInt(5e6)}
+}
+
+func (e *fakeEth) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	e.sent = append(e.sent, &tx)
+	return tx.Hash(), nil
+}
+
+// fakeHardware 以本地私钥代替设备
+type fakeHardware struct {
+	*kms.LocalSigner
+	verified int
+}
+
+func (h *fakeHardware) Wallet() string { return kms.WalletLedger }
+
+func (h *fakeHardware) VerifyAddress(context.Context) error {
+	h.verified++
+	return nil
+}
+
+func (h *fakeHardware) Close() error { return nil }
+
+func TestHardwareSend(t *testing.T) {
+	eth := &fakeEth{}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", eth))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	local, err := kms.NewLocalSigner(hex.EncodeToString(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	device := &fakeHardware{LocalSigner: local}
+	open := openHardwareSigner
+	defer func() { openHardwareSigner = open }()
+	openHardwareSigner = func(ctx context.Context, cfg kms.HardwareConfig) (hardwareSigner, error) {
+		assert.Equal(t, "m/44'/60'/0'/0/1", cfg.Path)
+		return device, nil
+	}
+
+	ctx := context.Background()
+	token := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	var out bytes.Buffer
+	require.NoError(t, run(ctx, nil, &out, []string{"hw", "send", "-rpc", srv.URL, "-chain-id", "8453",
+		"-path", "m/44'/60'/0'/0/1", "-to", to.Hex(), "-token", token.Hex(), "-amount", "2500000"}))
+	assert.Equal(t, 1, device.verified)
+	require.Len(t, eth.sent, 1)
+	tx := eth.sent[0]
+	assert.Equal(t, uint64(12), tx.Nonce())
+	assert.Equal(t, token, *tx.To())
+	assert.Equal(t, erc20TransferData(to, big.NewInt(2500000)), tx.Data())
+	assert.Equal(t, big.NewInt(11e6), tx.GasFeeCap(), "2 x base fee + tip")
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(8453)), tx)
+	require.NoError(t, err)
+	assert.Equal(t, device.Address(), sender)
+	assert.Contains(t, out.String(), "sent "+tx.Hash().Hex())
+
+	err = run(ctx, nil, &out, []string{"hw", "send", "-rpc", srv.URL, "-chain-id", "1", "-to", to.Hex(), "-amount", "1"})
+	assert.EqualError(t, err, "rpc endpoint is on chain 8453, not 1")
+	assert.Error(t, run(ctx, nil, &out, []string{"hw", "send", "-rpc", srv.URL, "-chain-id", "8453", "-to", to.Hex(), "-amount", "-1"}))
+	assert.Error(t, run(ctx, nil, &out, []string{"hw"}))
+}
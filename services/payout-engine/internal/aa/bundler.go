@@ -0,0 +1,196 @@
+package aa
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/protocol-bank/payout-engine/internal/config"
+)
+
+const entryPointABI = `[{"inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+const simpleAccountABI = `[{"inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// GasEstimate is the bundler/paymaster gas estimate for an operation.
+type GasEstimate struct {
+	CallGasLimit         *hexutil.Big  `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big  `json:"preVerificationGas"`
+	PaymasterAndData     hexutil.Bytes `json:"paymasterAndData,omitempty"`
+}
+
+// Receipt is the subset of eth_getUserOperationReceipt used by the engine.
+type Receipt struct {
+	UserOpHash common.Hash `json:"userOpHash"`
+	Success    bool        `json:"success"`
+	Reason     string      `json:"reason"`
+	Receipt    struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		BlockNumber     *hexutil.Big `json:"blockNumber"`
+	} `json:"receipt"`
+}
+
+// Client talks to the EntryPoint, a bundler and an optional paymaster for one chain.
+type Client struct {
+	cfg        config.AAConfig
+	entryPoint common.Address
+	eth        ethereum.ContractCaller
+	bundler    *rpc.Client
+	paymaster  *rpc.Client
+	epABI      abi.ABI
+	accountABI abi.ABI
+}
+
+// NewClient dials the bundler (and paymaster, when configured).
+func NewClient(ctx context.Context, cfg config.AAConfig, eth ethereum.ContractCaller) (*Client, error) {
+	if cfg.BundlerURL == "" {
+		return nil, fmt.Errorf("bundler url is required")
+	}
+	if !common.IsHexAddress(cfg.SmartAccount) {
+		return nil, fmt.Errorf("invalid smart account address: %s", cfg.SmartAccount)
+	}
+	entryPoint := cfg.EntryPoint
+	if entryPoint == "" {
+		entryPoint = EntryPointV06
+	}
+
+	epABI, err := abi.JSON(strings.NewReader(entryPointABI))
+	if err != nil {
+		return nil, err
+	}
+	accountABI, err := abi.JSON(strings.NewReader(simpleAccountABI))
+	if err != nil {
+		return nil, err
+	}
+
+	bundler, err := rpc.DialContext(ctx, cfg.BundlerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial bundler: %w", err)
+	}
+
+	c := &Client{
+		cfg:        cfg,
+		entryPoint: common.HexToAddress(entryPoint),
+		eth:        eth,
+		bundler:    bundler,
+		epABI:      epABI,
+		accountABI: accountABI,
+	}
+
+	if cfg.PaymasterURL != "" {
+		c.paymaster, err = rpc.DialContext(ctx, cfg.PaymasterURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial paymaster: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// EntryPoint returns the EntryPoint address.
+func (c *Client) EntryPoint() common.Address {
+	return c.entryPoint
+}
+
+// SmartAccount returns the sending smart account address.
+func (c *Client) SmartAccount() common.Address {
+	return common.HexToAddress(c.cfg.SmartAccount)
+}
+
+// Sponsored reports whether a paymaster is configured.
+func (c *Client) Sponsored() bool {
+	return c.paymaster != nil
+}
+
+// ExecuteCallData encodes SimpleAccount.execute(dest, value, data).
+func (c *Client) ExecuteCallData(dest common.Address, value *big.Int, data []byte) ([]byte, error) {
+	return c.accountABI.Pack("execute", dest, value, data)
+}
+
+// GetNonce reads the EntryPoint nonce for the smart account and key.
+func (c *Client) GetNonce(ctx context.Context, key *big.Int) (*big.Int, error) {
+	data, err := c.epABI.Pack("getNonce", c.SmartAccount(), key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.eth.CallContract(ctx, ethereum.CallMsg{To: &c.entryPoint, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getNonce call failed: %w", err)
+	}
+	values, err := c.epABI.Unpack("getNonce", out)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("failed to decode getNonce: %v", err)
+	}
+	return values[0].(*big.Int), nil
+}
+
+// EstimateGas asks the bundler for gas limits.
+func (c *Client) EstimateGas(ctx context.Context, op *UserOperation) (*GasEstimate, error) {
+	var est GasEstimate
+	if err := c.bundler.CallContext(ctx, &est, "eth_estimateUserOperationGas", op, c.entryPoint); err != nil {
+		return nil, fmt.Errorf("eth_estimateUserOperationGas: %w", err)
+	}
+	return &est, nil
+}
+
+// Sponsor requests paymaster sponsorship; the result includes paymasterAndData
+// and the gas limits the paymaster signed over.
+func (c *Client) Sponsor(ctx context.Context, op *UserOperation) (*GasEstimate, error) {
+	if c.paymaster == nil {
+		return nil, fmt.Errorf("paymaster not configured")
+	}
+	var policy interface{}
+	if c.cfg.PaymasterPolicyID != "" {
+		policy = map[string]string{"policyId": c.cfg.PaymasterPolicyID}
+	}
+	var est GasEstimate
+	if err := c.paymaster.CallContext(ctx, &est, "pm_sponsorUserOperation", op, c.entryPoint, policy); err != nil {
+		return nil, fmt.Errorf("pm_sponsorUserOperation: %w", err)
+	}
+	if len(est.PaymasterAndData) == 0 {
+		return nil, fmt.Errorf("paymaster declined sponsorship")
+	}
+	return &est, nil
+}
+
+// Send submits a signed operation and returns its userOpHash.
+func (c *Client) Send(ctx context.Context, op *UserOperation) (common.Hash, error) {
+	var hash common.Hash
+	if err := c.bundler.CallContext(ctx, &hash, "eth_sendUserOperation", op, c.entryPoint); err != nil {
+		return common.Hash{}, fmt.Errorf("eth_sendUserOperation: %w", err)
+	}
+	return hash, nil
+}
+
+// WaitForReceipt polls the bundler until the operation is included or timeout elapses.
+// Returns nil without error on timeout; the operation may still be included later.
+func (c *Client) WaitForReceipt(ctx context.Context, userOpHash common.Hash, timeout time.Duration) (*Receipt, error) {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, nil
+		case <-ticker.C:
+			var receipt *Receipt
+			if err := c.bundler.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", userOpHash); err != nil {
+				continue
+			}
+			if receipt != nil {
+				return receipt, nil
+			}
+		}
+	}
+}
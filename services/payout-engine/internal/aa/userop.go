@@ -0,0 +1,88 @@
+package aa
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EntryPointV06 is the canonical ERC-4337 v0.6 EntryPoint address.
+const EntryPointV06 = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+
+// DummySignature is a well-formed ECDSA signature used during gas estimation
+// so account validation follows the same code path as a real signature.
+var DummySignature = hexutil.MustDecode("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+// UserOperation is an ERC-4337 v0.6 user operation.
+// JSON encoding matches the bundler RPC format.
+type UserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+var (
+	addressT, _ = abi.NewType("address", "", nil)
+	uint256T, _ = abi.NewType("uint256", "", nil)
+	bytes32T, _ = abi.NewType("bytes32", "", nil)
+
+	packArgs = abi.Arguments{
+		{Type: addressT}, {Type: uint256T}, {Type: bytes32T}, {Type: bytes32T},
+		{Type: uint256T}, {Type: uint256T}, {Type: uint256T}, {Type: uint256T},
+		{Type: uint256T}, {Type: bytes32T},
+	}
+	hashArgs = abi.Arguments{{Type: bytes32T}, {Type: addressT}, {Type: uint256T}}
+)
+
+// Hash returns the userOpHash the EntryPoint computes for this operation.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	packed, _ := packArgs.Pack(
+		op.Sender,
+		op.Nonce.ToInt(),
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		op.CallGasLimit.ToInt(),
+		op.VerificationGasLimit.ToInt(),
+		op.PreVerificationGas.ToInt(),
+		op.MaxFeePerGas.ToInt(),
+		op.MaxPriorityFeePerGas.ToInt(),
+		crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	encoded, _ := hashArgs.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	return crypto.Keccak256Hash(encoded)
+}
+
+// SigningHash returns the EIP-191 personal message hash of the userOpHash,
+// which SimpleAccount-compatible accounts verify against the owner key.
+func (op *UserOperation) SigningHash(entryPoint common.Address, chainID *big.Int) []byte {
+	return accountsTextHash(op.Hash(entryPoint, chainID).Bytes())
+}
+
+// accountsTextHash mirrors accounts.TextHash without importing the keystore package.
+func accountsTextHash(data []byte) []byte {
+	msg := append([]byte("\x19Ethereum Signed Message:\n32"), data...)
+	return crypto.Keccak256(msg)
+}
+
+// NonceKey derives a 192-bit EntryPoint nonce key from an identifier so that
+// concurrent operations from the same account use independent nonce lanes.
+func NonceKey(id string) *big.Int {
+	h := crypto.Keccak256([]byte(id))
+	return new(big.Int).SetBytes(h[:24])
+}
+
+// NonceSequence returns the sequence part (low 64 bits) of an EntryPoint nonce.
+func NonceSequence(nonce *big.Int) uint64 {
+	return new(big.Int).And(nonce, new(big.Int).SetUint64(^uint64(0))).Uint64()
+}
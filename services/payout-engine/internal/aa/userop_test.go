@@ -0,0 +1,70 @@
+package aa
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOp() *UserOperation {
+	return &UserOperation{
+		Sender:               common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:                (*hexutil.Big)(big.NewInt(0)),
+		InitCode:             hexutil.Bytes{},
+		CallData:             hexutil.MustDecode("0xb61d27f6"),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(150000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2_000_000_000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+		PaymasterAndData:     hexutil.Bytes{},
+	}
+}
+
+func TestUserOperationHash(t *testing.T) {
+	op := newTestOp()
+	entryPoint := common.HexToAddress(EntryPointV06)
+
+	h1 := op.Hash(entryPoint, big.NewInt(8453))
+	assert.Equal(t, h1, op.Hash(entryPoint, big.NewInt(8453)), "hash must be deterministic")
+	assert.NotEqual(t, h1, op.Hash(entryPoint, big.NewInt(1)), "hash must commit to chain id")
+
+	op.PaymasterAndData = hexutil.MustDecode("0xaabbcc")
+	assert.NotEqual(t, h1, op.Hash(entryPoint, big.NewInt(8453)), "hash must commit to paymaster data")
+
+	// Signature is excluded from the hash
+	h2 := op.Hash(entryPoint, big.NewInt(8453))
+	op.Signature = DummySignature
+	assert.Equal(t, h2, op.Hash(entryPoint, big.NewInt(8453)))
+}
+
+func TestUserOperationSigningHashRecovers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	op := newTestOp()
+	digest := op.SigningHash(common.HexToAddress(EntryPointV06), big.NewInt(1))
+	sig, err := crypto.Sign(digest, key)
+	require.NoError(t, err)
+
+	pub, err := crypto.SigToPub(digest, sig)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pub))
+}
+
+func TestNonceKey(t *testing.T) {
+	k1 := NonceKey("job-1")
+	assert.LessOrEqual(t, k1.BitLen(), 192)
+	assert.Equal(t, k1, NonceKey("job-1"))
+	assert.NotEqual(t, k1, NonceKey("job-2"))
+
+	// EntryPoint nonce = key << 64 | sequence
+	nonce := new(big.Int).Lsh(k1, 64)
+	assert.Equal(t, uint64(0), NonceSequence(nonce))
+	assert.Equal(t, uint64(3), NonceSequence(nonce.Add(nonce, big.NewInt(3))))
+}
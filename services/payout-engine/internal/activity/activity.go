@@ -0,0 +1,83 @@
+package activity
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Code is the error code recorded on jobs held until a recipient is approved.
+const Code = "RECIPIENT_REVIEW_REQUIRED"
+
+// Reasons reported in FlaggedError.Reasons
+const (
+	ReasonNew     = "new"     // no on-chain activity at all
+	ReasonYoung   = "young"   // first activity more recent than MinAge
+	ReasonDormant = "dormant" // no activity for longer than DormantAfter
+)
+
+// History is what is known about an address's on-chain activity.
+// Zero times mean the source could not tell (e.g. no explorer configured for the chain).
+type History struct {
+	TxCount   uint64    `json:"tx_count"`   // Transactions sent (EVM nonce)
+	Balance   *big.Int  `json:"balance"`    // Native balance
+	FirstSeen time.Time `json:"first_seen"` // First transaction sent or received
+	LastSeen  time.Time `json:"last_seen"`  // Most recent transaction sent or received
+}
+
+// Config enables the check and sets what counts as new or dormant.
+type Config struct {
+	Enabled      bool
+	Threshold    string        // Check payouts at or above this amount in whole tokens ("" checks every payout)
+	MinAge       time.Duration // Flag addresses first seen more recently than this (0 disables)
+	DormantAfter time.Duration // Flag addresses with no activity for this long (0 disables)
+
+	// Etherscan-compatible API per chain ID, used for first/last activity on EVM chains.
+	// Without it only brand-new addresses (no nonce, no balance) are detected.
+	ExplorerURLs map[uint64]string
+	ExplorerKey  string
+}
+
+// Check returns the reasons the address needs review, or nil.
+func (c Config) Check(address string, h History, now time.Time) *FlaggedError {
+	var reasons []string
+	if h.TxCount == 0 && (h.Balance == nil || h.Balance.Sign() == 0) && h.FirstSeen.IsZero() {
+		reasons = append(reasons, ReasonNew)
+	}
+	if c.MinAge > 0 && !h.FirstSeen.IsZero() && now.Sub(h.FirstSeen) < c.MinAge {
+		reasons = append(reasons, ReasonYoung)
+	}
+	if c.DormantAfter > 0 && !h.LastSeen.IsZero() && now.Sub(h.LastSeen) > c.DormantAfter {
+		reasons = append(reasons, ReasonDormant)
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &FlaggedError{Address: address, Reasons: reasons, History: h}
+}
+
+// FlaggedError is returned when a recipient needs manual approval before it is paid.
+type FlaggedError struct {
+	Address string
+	Reasons []string
+	History History
+}
+
+func (e *FlaggedError) Error() string {
+	details := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		switch reason {
+		case ReasonNew:
+			details = append(details, "no on-chain activity")
+		case ReasonYoung:
+			details = append(details, "first seen "+e.History.FirstSeen.UTC().Format(time.RFC3339))
+		case ReasonDormant:
+			details = append(details, "inactive since "+e.History.LastSeen.UTC().Format(time.RFC3339))
+		}
+	}
+	return fmt.Sprintf("recipient %s requires approval (%s)", e.Address, strings.Join(details, "; "))
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *FlaggedError) ErrorCode() string { return Code }
@@ -0,0 +1,82 @@
+package activity
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const recipient = "0x000000000000000000000000000000000000dEaD"
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	cfg := Config{MinAge: 7 * 24 * time.Hour, DormantAfter: 180 * 24 * time.Hour}
+
+	// 从未使用: 无 nonce、无余额、无历史
+	flagged := cfg.Check(recipient, History{Balance: big.NewInt(0)}, now)
+	require.NotNil(t, flagged)
+	assert.Equal(t, []string{ReasonNew}, flagged.Reasons)
+	assert.Equal(t, Code, flagged.ErrorCode())
+	assert.Contains(t, flagged.Error(), "no on-chain activity")
+
+	// 有余额 (收到过转账) 但未知历史时不视为新地址
+	assert.Nil(t, cfg.Check(recipient, History{Balance: big.NewInt(1)}, now))
+
+	young := History{TxCount: 3, FirstSeen: now.Add(-48 * time.Hour), LastSeen: now.Add(-time.Hour)}
+	flagged = cfg.Check(recipient, young, now)
+	require.NotNil(t, flagged)
+	assert.Equal(t, []string{ReasonYoung}, flagged.Reasons)
+
+	dormant := History{TxCount: 40, FirstSeen: now.AddDate(-3, 0, 0), LastSeen: now.AddDate(-1, 0, 0)}
+	flagged = cfg.Check(recipient, dormant, now)
+	require.NotNil(t, flagged)
+	assert.Equal(t, []string{ReasonDormant}, flagged.Reasons)
+	assert.Contains(t, flagged.Error(), "inactive since 2025-10-18")
+
+	active := History{TxCount: 40, FirstSeen: now.AddDate(-3, 0, 0), LastSeen: now.AddDate(0, 0, -2)}
+	assert.Nil(t, cfg.Check(recipient, active, now))
+
+	// 未配置年龄/休眠阈值时只检查新地址
+	assert.Nil(t, Config{}.Check(recipient, dormant, now))
+}
+
+func TestExplorer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "account", q.Get("module"))
+		assert.Equal(t, "key", q.Get("apikey"))
+		switch q.Get("address") {
+		case recipient:
+			if q.Get("action") == "tokentx" {
+				// 只收到过代币
+				if q.Get("sort") == "asc" {
+					w.Write([]byte(`{"status":"1","message":"OK","result":[{"timeStamp":"1700000000"}]}`))
+				} else {
+					w.Write([]byte(`{"status":"1","message":"OK","result":[{"timeStamp":"1750000000"}]}`))
+				}
+				return
+			}
+			w.Write([]byte(`{"status":"0","message":"No transactions found","result":[]}`))
+		default:
+			w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Invalid API Key"}`))
+		}
+	}))
+	defer srv.Close()
+	e := NewExplorer(map[uint64]string{1: srv.URL}, "key")
+	assert.True(t, e.Supports(1))
+	assert.False(t, e.Supports(8453))
+
+	first, last, err := e.Activity(context.Background(), 1, recipient)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000), first.Unix())
+	assert.Equal(t, int64(1750000000), last.Unix())
+
+	_, _, err = e.Activity(context.Background(), 1, "0x0000000000000000000000000000000000000001")
+	assert.ErrorContains(t, err, "Invalid API Key")
+}
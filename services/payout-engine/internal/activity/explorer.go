@@ -0,0 +1,113 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Explorer reads an address's first and last transaction from an Etherscan-compatible API.
+// Both normal transactions and token transfers count, so receive-only addresses have a history.
+type Explorer struct {
+	urls       map[uint64]string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewExplorer creates an explorer client for the chains in urls.
+func NewExplorer(urls map[uint64]string, apiKey string) *Explorer {
+	return &Explorer{urls: urls, apiKey: apiKey, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Supports reports whether an explorer API is configured for the chain.
+func (e *Explorer) Supports(chainID uint64) bool {
+	return e != nil && e.urls[chainID] != ""
+}
+
+// Activity returns the times of the address's first and last transaction (zero when it has none).
+func (e *Explorer) Activity(ctx context.Context, chainID uint64, address string) (first, last time.Time, err error) {
+	for _, action := range []string{"txlist", "tokentx"} {
+		oldest, err := e.edge(ctx, chainID, address, action, "asc")
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if oldest.IsZero() {
+			continue
+		}
+		newest, err := e.edge(ctx, chainID, address, action, "desc")
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if first.IsZero() || oldest.Before(first) {
+			first = oldest
+		}
+		if newest.After(last) {
+			last = newest
+		}
+	}
+	return first, last, nil
+}
+
+// edge 按 sort 顺序读取第一笔交易的时间
+func (e *Explorer) edge(ctx context.Context, chainID uint64, address, action, sort string) (time.Time, error) {
+	base, ok := e.urls[chainID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("no explorer configured for chain %d", chainID)
+	}
+	q := url.Values{
+		"module":  {"account"},
+		"action":  {action},
+		"address": {address},
+		"page":    {"1"},
+		"offset":  {"1"},
+		"sort":    {sort},
+	}
+	if e.apiKey != "" {
+		q.Set("apikey", e.apiKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"?"+q.Encode(), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("explorer %s failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("explorer %s returned %d", action, resp.StatusCode)
+	}
+
+	// 无交易时 status 为 "0"、result 为空数组；出错时 result 为错误信息字符串
+	var out struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return time.Time{}, fmt.Errorf("explorer %s: invalid response: %w", action, err)
+	}
+	var txs []struct {
+		TimeStamp string `json:"timeStamp"`
+	}
+	if err := json.Unmarshal(out.Result, &txs); err != nil {
+		var msg string
+		_ = json.Unmarshal(out.Result, &msg)
+		return time.Time{}, fmt.Errorf("explorer %s: %s %s", action, out.Message, msg)
+	}
+	if len(txs) == 0 {
+		return time.Time{}, nil
+	}
+	ts, err := strconv.ParseInt(txs[0].TimeStamp, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("explorer %s: invalid timestamp %q", action, txs[0].TimeStamp)
+	}
+	return time.Unix(ts, 0), nil
+}
@@ -0,0 +1,116 @@
+package allowlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultTenant applies to tenants without their own list.
+const DefaultTenant = "*"
+
+// Token 允许支付的代币合约
+type Token struct {
+	ChainID  uint64 `json:"chain_id"`
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals uint32 `json:"decimals"`
+}
+
+// Allowlist 每个租户 (UserID) 可支付的代币合约
+type Allowlist struct {
+	// tenant -> chain -> normalized address -> token
+	tenants map[string]map[uint64]map[string]Token
+}
+
+// file is the on-disk JSON format:
+//
+//	{"tenants": {"*": [{"chain_id": 1, "address": "0xA0b8...", "symbol": "USDC", "decimals": 6}], "user-123": [...]}}
+type file struct {
+	Tenants map[string][]Token `json:"tenants"`
+}
+
+// Load reads an allowlist file. An empty path returns a disabled allowlist.
+func Load(path string) (*Allowlist, error) {
+	if path == "" {
+		return &Allowlist{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token allowlist: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse builds an allowlist from JSON.
+func Parse(data []byte) (*Allowlist, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid token allowlist: %w", err)
+	}
+
+	a := &Allowlist{tenants: make(map[string]map[uint64]map[string]Token)}
+	for tenant, tokens := range f.Tenants {
+		chains := make(map[uint64]map[string]Token)
+		for _, t := range tokens {
+			if t.ChainID == 0 || t.Address == "" || t.Symbol == "" {
+				return nil, fmt.Errorf("tenant %s: chain_id, address and symbol are required", tenant)
+			}
+			if chains[t.ChainID] == nil {
+				chains[t.ChainID] = make(map[string]Token)
+			}
+			chains[t.ChainID][normalize(t.Address)] = t
+		}
+		a.tenants[tenant] = chains
+	}
+	return a, nil
+}
+
+// Enabled reports whether any list is configured.
+func (a *Allowlist) Enabled() bool {
+	return a != nil && len(a.tenants) > 0
+}
+
+// Lookup returns the allowlisted token for a tenant. Tenants without their own
+// list fall back to the default list.
+func (a *Allowlist) Lookup(tenant string, chainID uint64, address string) (Token, bool) {
+	if !a.Enabled() {
+		return Token{}, false
+	}
+	chains, ok := a.tenants[tenant]
+	if !ok {
+		chains, ok = a.tenants[DefaultTenant]
+		if !ok {
+			return Token{}, false
+		}
+	}
+	t, ok := chains[chainID][normalize(address)]
+	return t, ok
+}
+
+// Tokens returns every token allowlisted on a chain across all tenants.
+func (a *Allowlist) Tokens(chainID uint64) []Token {
+	if !a.Enabled() {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var tokens []Token
+	for _, chains := range a.tenants {
+		for addr, t := range chains[chainID] {
+			if !seen[addr] {
+				seen[addr] = true
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+// normalize lower-cases EVM hex addresses; TRON Base58 addresses are case-sensitive.
+func normalize(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
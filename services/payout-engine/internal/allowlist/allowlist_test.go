@@ -0,0 +1,60 @@
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testList = `{
+  "tenants": {
+    "*": [
+      {"chain_id": 1, "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "symbol": "USDC", "decimals": 6},
+      {"chain_id": 728126428, "address": "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "symbol": "USDT", "decimals": 6}
+    ],
+    "tenant-b": [
+      {"chain_id": 1, "address": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "symbol": "USDT", "decimals": 6}
+    ]
+  }
+}`
+
+func TestAllowlistLookup(t *testing.T) {
+	a, err := Parse([]byte(testList))
+	require.NoError(t, err)
+	assert.True(t, a.Enabled())
+
+	tests := []struct {
+		name    string
+		tenant  string
+		chainID uint64
+		address string
+		allowed bool
+	}{
+		{"default list, checksum address", "tenant-a", 1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", true},
+		{"default list, lowercase address", "tenant-a", 1, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", true},
+		{"lookalike address rejected", "tenant-a", 1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49", false},
+		{"wrong chain rejected", "tenant-a", 137, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", false},
+		{"tron address", "tenant-a", 728126428, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", true},
+		{"tenant list replaces default", "tenant-b", 1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", false},
+		{"tenant list entry", "tenant-b", 1, "0xdac17f958d2ee523a2206206994597c13d831ec7", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := a.Lookup(tt.tenant, tt.chainID, tt.address)
+			assert.Equal(t, tt.allowed, ok)
+		})
+	}
+}
+
+func TestAllowlistDisabled(t *testing.T) {
+	a, err := Load("")
+	require.NoError(t, err)
+	assert.False(t, a.Enabled())
+}
+
+func TestAllowlistRejectsIncompleteEntries(t *testing.T) {
+	_, err := Parse([]byte(`{"tenants": {"*": [{"chain_id": 1, "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}]}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package approval
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NativeToken 在价格表中表示链的原生代币
+const NativeToken = "native"
+
+// ErrUnknownOperator 签名不属于任何已配置的审批人
+var ErrUnknownOperator = errors.New("signature is not from a configured approval operator")
+
+// Config 大额批次双人审批
+type Config struct {
+	ThresholdUSD string   // 批次合计美元价值达到该值时须审批 (为空时关闭)
+	Quorum       int      // 放行所需的不同审批人签名数 (默认 1，即提交方之外的第二人)
+	Operators    []string // 审批人 EVM 地址，审批时以 EIP-191 签名证明身份
+	PricesFile   string   // 代币美元价格 JSON 文件
+}
+
+// Price 一种代币的美元单价 (整币)
+type Price struct {
+	ChainID uint64 `json:"chain_id"` // 0 = 所有链
+	Token   string `json:"token"`    // 代币地址、TRC10 资产 ID 或 "native"
+	USD     string `json:"usd"`
+}
+
+// file is the on-disk JSON format:
+//
+//	{"prices": [
+//	  {"chain_id": 8453, "token": "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", "usd": "1"},
+//	  {"chain_id": 1, "token": "native", "usd": "3200"}
+//	]}
+type file struct {
+	Prices []Price `json:"prices"`
+}
+
+type priceKey struct {
+	chainID uint64
+	token   string
+}
+
+// Policy 已加载的审批规则
+type Policy struct {
+	threshold *big.Rat
+	quorum    int
+	operators map[common.Address]bool
+	prices    map[priceKey]*big.Rat
+}
+
+// New 按配置构造审批规则。未配置阈值时返回 nil (不审批)。
+func New(cfg Config) (*Policy, error) {
+	if cfg.ThresholdUSD == "" {
+		return nil, nil
+	}
+	threshold, ok := new(big.Rat).SetString(cfg.ThresholdUSD)
+	if !ok || threshold.Sign() < 0 {
+		return nil, fmt.Errorf("invalid APPROVAL_THRESHOLD_USD: %s", cfg.ThresholdUSD)
+	}
+	quorum := cfg.Quorum
+	if quorum <= 0 {
+		quorum = 1
+	}
+	operators := make(map[common.Address]bool)
+	for _, op := range cfg.Operators {
+		if !common.IsHexAddress(op) {
+			return nil, fmt.Errorf("invalid approval operator address: %s", op)
+		}
+		operators[common.HexToAddress(op)] = true
+	}
+	if len(operators) < quorum {
+		return nil, fmt.Errorf("APPROVAL_QUORUM is %d but only %d approval operators are configured", quorum, len(operators))
+	}
+
+	p := &Policy{threshold: threshold, quorum: quorum, operators: operators, prices: make(map[priceKey]*big.Rat)}
+	if cfg.PricesFile != "" {
+		data, err := os.ReadFile(cfg.PricesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read approval prices: %w", err)
+		}
+		if p.prices, err = parsePrices(data); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// parsePrices 解析价格表 JSON
+func parsePrices(data []byte) (map[priceKey]*big.Rat, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid approval prices: %w", err)
+	}
+	prices := make(map[priceKey]*big.Rat, len(f.Prices))
+	for i, p := range f.Prices {
+		usd, ok := new(big.Rat).SetString(p.USD)
+		if !ok || usd.Sign() < 0 {
+			return nil, fmt.Errorf("prices[%d]: invalid usd: %s", i, p.USD)
+		}
+		prices[priceKey{p.ChainID, normalizeToken(p.Token)}] = usd
+	}
+	return prices, nil
+}
+
+// Quorum 放行所需的审批人数
+func (p *Policy) Quorum() int {
+	return p.quorum
+}
+
+// Price 代币的美元单价 (优先匹配链，其次 chain_id 0)。未定价时 ok=false。
+func (p *Policy) Price(chainID uint64, token string) (*big.Rat, bool) {
+	token = normalizeToken(token)
+	if usd, ok := p.prices[priceKey{chainID, token}]; ok {
+		return usd, true
+	}
+	usd, ok := p.prices[priceKey{0, token}]
+	return usd, ok
+}
+
+// Requires 批次是否须审批。含未定价代币的批次无法估值，一律审批。
+func (p *Policy) Requires(valueUSD *big.Rat, unpriced bool) bool {
+	return unpriced || valueUSD.Cmp(p.threshold) >= 0
+}
+
+// Message 审批人签名的文本 (绑定批次和请求哈希，批次内容变更后旧签名失效)
+func Message(userID, batchID, requestHash string) string {
+	return fmt.Sprintf("Approve payout batch\nuser: %s\nbatch: %s\nrequest: %s", userID, batchID, requestHash)
+}
+
+// Verify 校验审批人对 message 的 EIP-191 (personal_sign) 签名，返回审批人地址
+func (p *Policy) Verify(message, signature string) (common.Address, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid approval signature")
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid approval signature: %w", err)
+	}
+	operator := crypto.PubkeyToAddress(*pub)
+	if !p.operators[operator] {
+		return common.Address{}, ErrUnknownOperator
+	}
+	return operator, nil
+}
+
+func normalizeToken(token string) string {
+	token = strings.ToLower(strings.TrimSpace(token))
+	if token == "" || token == "0x0000000000000000000000000000000000000000" {
+		return NativeToken
+	}
+	return token
+}
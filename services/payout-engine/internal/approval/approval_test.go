@@ -0,0 +1,85 @@
+package approval
+
+import (
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrices = `{
+  "prices": [
+    {"chain_id": 8453, "token": "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "usd": "1"},
+    {"token": "native", "usd": "3000.5"}
+  ]
+}`
+
+func TestNewDisabled(t *testing.T) {
+	p, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(Config{ThresholdUSD: "abc", Operators: []string{"0x0000000000000000000000000000000000000001"}})
+	assert.Error(t, err)
+	_, err = New(Config{ThresholdUSD: "1000", Operators: []string{"not-an-address"}})
+	assert.Error(t, err)
+	_, err = New(Config{ThresholdUSD: "1000", Quorum: 2, Operators: []string{"0x0000000000000000000000000000000000000001"}})
+	assert.Error(t, err, "quorum cannot exceed the operator count")
+}
+
+func TestPrice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, os.WriteFile(path, []byte(testPrices), 0o600))
+	p, err := New(Config{ThresholdUSD: "10000", Operators: []string{"0x0000000000000000000000000000000000000001"}, PricesFile: path})
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Quorum())
+
+	usd, ok := p.Price(8453, "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913")
+	require.True(t, ok)
+	assert.Equal(t, "1", usd.RatString())
+
+	usd, ok = p.Price(1, "")
+	require.True(t, ok, "chain 0 prices apply to every chain")
+	assert.Equal(t, "6001/2", usd.RatString())
+
+	_, ok = p.Price(1, "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913")
+	assert.False(t, ok)
+
+	assert.True(t, p.Requires(big.NewRat(10000, 1), false))
+	assert.False(t, p.Requires(big.NewRat(9999, 1), false))
+	assert.True(t, p.Requires(big.NewRat(0, 1), true), "unpriced batches always need approval")
+}
+
+func TestVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	operator := crypto.PubkeyToAddress(key.PublicKey)
+	p, err := New(Config{ThresholdUSD: "0", Operators: []string{operator.Hex()}})
+	require.NoError(t, err)
+
+	message := Message("user-1", "batch-1", "abc123")
+	sign := func(msg string) string {
+		sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+		require.NoError(t, err)
+		sig[64] += 27
+		return "0x" + hex.EncodeToString(sig)
+	}
+
+	got, err := p.Verify(message, sign(message))
+	require.NoError(t, err)
+	assert.Equal(t, operator, got)
+
+	_, err = p.Verify(message, sign(Message("user-1", "batch-1", "other")))
+	assert.ErrorIs(t, err, ErrUnknownOperator, "signature over another request recovers a different address")
+
+	_, err = p.Verify(message, "0x1234")
+	assert.Error(t, err)
+}
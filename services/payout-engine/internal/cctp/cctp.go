@@ -0,0 +1,131 @@
+// Package cctp moves USDC between chains with Circle's Cross-Chain Transfer
+// Protocol: burn with TokenMessenger.depositForBurn on the source chain, fetch
+// Circle's attestation for the emitted message, and mint with
+// MessageTransmitter.receiveMessage on the destination chain.
+package cctp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Circle attestation service endpoints
+const (
+	MainnetAttestationURL = "https://iris-api.circle.com"
+	SandboxAttestationURL = "https://iris-api-sandbox.circle.com"
+)
+
+const contractsABI = `[
+	{"inputs":[{"name":"amount","type":"uint256"},{"name":"destinationDomain","type":"uint32"},{"name":"mintRecipient","type":"bytes32"},{"name":"burnToken","type":"address"}],"name":"depositForBurn","outputs":[{"name":"_nonce","type":"uint64"}],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"message","type":"bytes"},{"name":"attestation","type":"bytes"}],"name":"receiveMessage","outputs":[{"name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":false,"name":"message","type":"bytes"}],"name":"MessageSent","type":"event"}
+]`
+
+var parsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(contractsABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ErrMessageNotFound means the burn receipt has no MessageSent event from the transmitter.
+var ErrMessageNotFound = errors.New("cctp MessageSent event not found in burn receipt")
+
+// PackDepositForBurn encodes TokenMessenger.depositForBurn minting to recipient on the destination domain.
+func PackDepositForBurn(amount *big.Int, destinationDomain uint32, recipient, burnToken common.Address) ([]byte, error) {
+	return parsedABI.Pack("depositForBurn", amount, destinationDomain, common.BytesToHash(recipient.Bytes()), burnToken)
+}
+
+// PackReceiveMessage encodes MessageTransmitter.receiveMessage.
+func PackReceiveMessage(message, attestation []byte) ([]byte, error) {
+	return parsedABI.Pack("receiveMessage", message, attestation)
+}
+
+// MessageFromLogs returns the message emitted by the source chain's MessageTransmitter in a burn receipt.
+func MessageFromLogs(logs []*types.Log, transmitter common.Address) ([]byte, error) {
+	event := parsedABI.Events["MessageSent"]
+	for _, l := range logs {
+		if l.Address != transmitter || len(l.Topics) == 0 || l.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.Unpack(l.Data)
+		if err != nil || len(values) != 1 {
+			return nil, fmt.Errorf("failed to decode MessageSent: %v", err)
+		}
+		return values[0].([]byte), nil
+	}
+	return nil, ErrMessageNotFound
+}
+
+// MessageHash is the key Circle's attestation service indexes messages by.
+func MessageHash(message []byte) common.Hash {
+	return crypto.Keccak256Hash(message)
+}
+
+// Client fetches attestations from Circle's attestation service (Iris).
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates an attestation client.
+func NewClient(baseURL string) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Attestation returns Circle's signature over the message, or nil while it is
+// still waiting for source chain confirmations.
+func (c *Client) Attestation(ctx context.Context, messageHash common.Hash) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/attestations/"+messageHash.Hex(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cctp attestation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	// 源链交易刚上链时服务尚未收录该消息
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("cctp attestation status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		Status      string `json:"status"`
+		Attestation string `json:"attestation"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode cctp attestation: %w", err)
+	}
+	if out.Status != "complete" {
+		return nil, nil
+	}
+	attestation, err := hexutil.Decode(out.Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cctp attestation: %w", err)
+	}
+	return attestation, nil
+}
@@ -0,0 +1,76 @@
+package cctp
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestation(t *testing.T) {
+	message := []byte("cctp message")
+	hash := MessageHash(message)
+	status := "pending_confirmations"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/attestations/"+hash.Hex() {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Message hash not found"}`))
+			return
+		}
+		if status == "complete" {
+			w.Write([]byte(`{"status":"complete","attestation":"0xabcd"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"pending_confirmations","attestation":"PENDING"}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+
+	// 未收录和等待确认时均返回 nil
+	attestation, err := c.Attestation(context.Background(), MessageHash([]byte("other")))
+	require.NoError(t, err)
+	assert.Nil(t, attestation)
+	attestation, err = c.Attestation(context.Background(), hash)
+	require.NoError(t, err)
+	assert.Nil(t, attestation)
+
+	status = "complete"
+	attestation, err = c.Attestation(context.Background(), hash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xab, 0xcd}, attestation)
+}
+
+func TestMessageFromLogs(t *testing.T) {
+	transmitter := common.HexToAddress("0x0a992d191DEeC32aFe36203Ad87D7d289a738F81")
+	message := []byte{1, 2, 3, 4}
+	data, err := parsedABI.Events["MessageSent"].Inputs.Pack(message)
+	require.NoError(t, err)
+	event := &types.Log{Address: transmitter, Topics: []common.Hash{parsedABI.Events["MessageSent"].ID}, Data: data}
+	other := &types.Log{Address: common.HexToAddress("0x01"), Topics: event.Topics, Data: data}
+
+	got, err := MessageFromLogs([]*types.Log{other, event}, transmitter)
+	require.NoError(t, err)
+	assert.Equal(t, message, got)
+
+	_, err = MessageFromLogs([]*types.Log{other}, transmitter)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func TestPackDepositForBurn(t *testing.T) {
+	recipient := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	data, err := PackDepositForBurn(big.NewInt(1_000_000), 6, recipient, usdc)
+	require.NoError(t, err)
+
+	values, err := parsedABI.Methods["depositForBurn"].Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), values[1])
+	mintRecipient := values[2].([32]byte)
+	assert.Equal(t, recipient, common.BytesToAddress(mintRecipient[:]), "recipient left-padded to bytes32")
+}
@@ -0,0 +1,403 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+)
+
+// chainsFile 链配置文件格式 (JSON)。条目按 chain_id 覆盖内置链，省略的字段沿用内置值；
+// 新 chain_id 即新增链；"disabled": true 移除内置链:
+//
+//	{"chains": [
+//	  {"chain_id": 1, "rpc_url": "https://eth.example.com", "rpc_fallback_urls": ["https://eth-2.example.com"],
+//	   "rpc_rate_limits": {"https://eth.example.com": {"rps": 10, "burst": 20}}},
+//	  {"chain_id": 56, "name": "BNB Chain", "type": "evm", "rpc_url": "https://bsc-dataseed.bnbchain.org",
+//	   "explorer_url": "https://bscscan.com", "native_token": "BNB", "decimals": 18, "stuck_tx_timeout": "1m"},
+//	  {"chain_id": 10, "disabled": true}
+//	]}
+type chainsFile struct {
+	Chains []json.RawMessage `json:"chains"`
+}
+
+type chainEntry struct {
+	ChainID         uint64                       `json:"chain_id"`
+	Disabled        bool                         `json:"disabled"`
+	Name            string                       `json:"name"`
+	Type            string                       `json:"type"`
+	RPCURL          string                       `json:"rpc_url"`
+	RPCFallbackURLs []string                     `json:"rpc_fallback_urls"`
+	RPCRateLimits   map[string]rpcpool.RateLimit `json:"rpc_rate_limits"`
+	ExplorerURL     string                       `json:"explorer_url"`
+	NativeToken     string                       `json:"native_token"`
+	Decimals        int                          `json:"decimals"`
+	StuckTxTimeout  duration                     `json:"stuck_tx_timeout"`
+	GasBumpPercent  int                          `json:"gas_bump_percent"`
+	MaxReplacements int                          `json:"max_replacements"`
+	Confirmations   uint64                       `json:"confirmations"`
+	ReorgDepth      uint64                       `json:"reorg_depth"`
+	L1Fee           string                       `json:"l1_fee"`
+	TxFormat        string                       `json:"tx_format"`
+	AA              AAConfig                     `json:"aa"`
+	Forwarders      []string                     `json:"trusted_forwarders"`
+	Treasury        string                       `json:"treasury"`
+	CCTP            ChainCCTP                    `json:"cctp"`
+	Shadow          ChainShadow                  `json:"shadow"`
+	MaxInFlight     int                          `json:"max_in_flight"`
+	WrappedNative   string                       `json:"wrapped_native"`
+	UnwrapNative    bool                         `json:"unwrap_native"`
+	PrivateTx       privateTxEntry               `json:"private_tx"`
+	GasTank         gasTankEntry                 `json:"gas_tank"`
+	GasCeiling      gasCeilingEntry              `json:"gas_ceiling"`
+	Sweep           sweepEntry                   `json:"sweep"`
+	Testnet         bool                         `json:"testnet"`
+	Faucet          faucetEntry                  `json:"faucet"`
+}
+
+type sweepEntry struct {
+	ColdAddress string                  `json:"cold_address"`
+	Ceilings    map[string]SweepCeiling `json:"ceilings"`
+}
+
+type gasCeilingEntry struct {
+	MaxBaseFeeGwei string `json:"max_base_fee_gwei"`
+	Wait           bool   `json:"wait"`
+}
+
+type gasTankEntry struct {
+	MinBalance    string   `json:"min_balance"`
+	TargetBalance string   `json:"target_balance"`
+	Addresses     []string `json:"addresses"`
+}
+
+type privateTxEntry struct {
+	RPCURL    string   `json:"rpc_url"`
+	Method    string   `json:"method"`
+	MinAmount string   `json:"min_amount"`
+	Deadline  duration `json:"deadline"`
+}
+
+type faucetEntry struct {
+	URL        string   `json:"url"`
+	APIKey     string   `json:"api_key"`
+	MinBalance string   `json:"min_balance"`
+	Cooldown   duration `json:"cooldown"`
+}
+
+// duration 接受 "90s"、"2m" 形式的字符串
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"2m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// LoadChains 内置链叠加链配置文件 (path 为空时只用内置链)，只保留 network 对应的链 (shadow 时另外保留影子测试网)
+func LoadChains(path, network string, shadow bool) (map[uint64]ChainConfig, error) {
+	chains := builtinChains()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chains file: %w", err)
+		}
+		if err := applyChainsFile(chains, data); err != nil {
+			return nil, err
+		}
+	}
+
+	// 主网与测试网互斥，避免测试流量误发到主网。影子模式下另外保留主网链的影子测试网
+	shadows := make(map[uint64]bool)
+	if shadow && network != NetworkTestnet {
+		for chainID, chain := range chains {
+			if chain.Testnet || chain.Shadow.ChainID == 0 {
+				continue
+			}
+			if target, ok := chains[chain.Shadow.ChainID]; !ok || !target.Testnet {
+				return nil, fmt.Errorf("chain %d: shadow chain %d is not a configured testnet", chainID, chain.Shadow.ChainID)
+			}
+			shadows[chain.Shadow.ChainID] = true
+		}
+	}
+	for chainID, chain := range chains {
+		if chain.Testnet && shadows[chainID] {
+			continue
+		}
+		if chain.Testnet != (network == NetworkTestnet) {
+			delete(chains, chainID)
+		}
+	}
+	return chains, nil
+}
+
+// applyChainsFile 将文件中的条目合并到 chains
+func applyChainsFile(chains map[uint64]ChainConfig, data []byte) error {
+	var f chainsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("invalid chains file: %w", err)
+	}
+	for i, raw := range f.Chains {
+		var id struct {
+			ChainID uint64 `json:"chain_id"`
+		}
+		if err := json.Unmarshal(raw, &id); err != nil || id.ChainID == 0 {
+			return fmt.Errorf("chains[%d]: chain_id is required", i)
+		}
+
+		// 先填入内置值，文件中出现的字段覆盖之
+		entry := toChainEntry(chains[id.ChainID])
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return fmt.Errorf("chains[%d]: %w", i, err)
+		}
+		if entry.Disabled {
+			delete(chains, entry.ChainID)
+			continue
+		}
+		chain := entry.chainConfig()
+		if err := validateChain(chain); err != nil {
+			return fmt.Errorf("chains[%d]: %w", i, err)
+		}
+		chains[chain.ChainID] = chain
+	}
+	return nil
+}
+
+func validateChain(c ChainConfig) error {
+	if c.Name == "" || c.RPCURL == "" || c.NativeToken == "" {
+		return fmt.Errorf("chain %d: name, rpc_url and native_token are required", c.ChainID)
+	}
+	if c.Type != "evm" && c.Type != "tron" {
+		return fmt.Errorf("chain %d: type must be evm or tron", c.ChainID)
+	}
+	if c.Decimals <= 0 {
+		return fmt.Errorf("chain %d: decimals must be positive", c.ChainID)
+	}
+	if len(c.RPCRateLimits) > 0 && c.Type != "evm" {
+		return fmt.Errorf("chain %d: rpc_rate_limits is only supported on evm chains", c.ChainID)
+	}
+	for endpoint, limit := range c.RPCRateLimits {
+		if !c.hasRPCEndpoint(endpoint) {
+			return fmt.Errorf("chain %d: rpc_rate_limits[%s] does not match rpc_url or rpc_fallback_urls", c.ChainID, rpcpool.Redact(endpoint))
+		}
+		if limit.RPS <= 0 || limit.Burst < 0 {
+			return fmt.Errorf("chain %d: rpc_rate_limits[%s]: rps must be positive and burst non-negative", c.ChainID, rpcpool.Redact(endpoint))
+		}
+	}
+	switch c.L1Fee {
+	case "":
+	case "op", "arbitrum":
+		if c.Type != "evm" {
+			return fmt.Errorf("chain %d: l1_fee is only supported on evm chains", c.ChainID)
+		}
+	default:
+		return fmt.Errorf("chain %d: l1_fee must be op or arbitrum", c.ChainID)
+	}
+	switch c.TxFormat {
+	case "", TxFormatEIP1559:
+	case TxFormatZkSync:
+		if c.Type != "evm" {
+			return fmt.Errorf("chain %d: tx_format is only supported on evm chains", c.ChainID)
+		}
+	default:
+		return fmt.Errorf("chain %d: tx_format must be %s or %s", c.ChainID, TxFormatEIP1559, TxFormatZkSync)
+	}
+	if len(c.Forwarders) > 0 && c.Type != "evm" {
+		return fmt.Errorf("chain %d: trusted_forwarders is only supported on evm chains", c.ChainID)
+	}
+	for _, forwarder := range c.Forwarders {
+		if !common.IsHexAddress(forwarder) {
+			return fmt.Errorf("chain %d: invalid trusted forwarder: %s", c.ChainID, forwarder)
+		}
+	}
+	if c.Treasury != "" && (c.Type != "evm" || !common.IsHexAddress(c.Treasury)) {
+		return fmt.Errorf("chain %d: treasury must be a contract address on an evm chain", c.ChainID)
+	}
+	if c.CCTP != (ChainCCTP{}) {
+		if c.Type != "evm" {
+			return fmt.Errorf("chain %d: cctp is only supported on evm chains", c.ChainID)
+		}
+		for _, addr := range []string{c.CCTP.TokenMessenger, c.CCTP.MessageTransmitter, c.CCTP.USDC} {
+			if !common.IsHexAddress(addr) {
+				return fmt.Errorf("chain %d: cctp requires token_messenger, message_transmitter and usdc addresses", c.ChainID)
+			}
+		}
+	}
+	if c.Shadow.ChainID == c.ChainID && c.ChainID != 0 {
+		return fmt.Errorf("chain %d: shadow.chain_id must be another (testnet) chain", c.ChainID)
+	}
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("chain %d: max_in_flight must not be negative", c.ChainID)
+	}
+	if c.UnwrapNative && (c.Type != "evm" || c.WrappedNative == "") {
+		return fmt.Errorf("chain %d: unwrap_native requires an evm chain with wrapped_native", c.ChainID)
+	}
+	if c.PrivateTx.RPCURL != "" {
+		if c.Type != "evm" {
+			return fmt.Errorf("chain %d: private_tx is only supported on evm chains", c.ChainID)
+		}
+		if c.PrivateTx.MinAmount != "" {
+			if v, ok := new(big.Rat).SetString(c.PrivateTx.MinAmount); !ok || v.Sign() < 0 {
+				return fmt.Errorf("chain %d: private_tx.min_amount must be a non-negative number", c.ChainID)
+			}
+		}
+		switch c.PrivateTx.Method {
+		case "", "eth_sendRawTransaction", "eth_sendPrivateTransaction":
+		default:
+			return fmt.Errorf("chain %d: private_tx.method must be eth_sendRawTransaction or eth_sendPrivateTransaction", c.ChainID)
+		}
+	}
+	if c.GasTank.MinBalance != "" || c.GasTank.TargetBalance != "" {
+		if _, _, err := c.GasTank.Thresholds(); err != nil {
+			return fmt.Errorf("chain %d: gas_tank: %w", c.ChainID, err)
+		}
+	}
+	if c.GasCeiling.MaxBaseFeeGwei != "" {
+		if c.Type != "evm" {
+			return fmt.Errorf("chain %d: gas_ceiling is only supported on evm chains", c.ChainID)
+		}
+		if _, err := c.GasCeiling.MaxBaseFee(); err != nil {
+			return fmt.Errorf("chain %d: gas_ceiling: %w", c.ChainID, err)
+		}
+	}
+	if len(c.Sweep.Ceilings) > 0 && c.Sweep.ColdAddress == "" {
+		return fmt.Errorf("chain %d: sweep.cold_address is required with sweep.ceilings", c.ChainID)
+	}
+	for asset, ceiling := range c.Sweep.Ceilings {
+		if _, _, err := ceiling.Limits(); err != nil {
+			return fmt.Errorf("chain %d: sweep.ceilings[%s]: %w", c.ChainID, asset, err)
+		}
+	}
+	return nil
+}
+
+// hasRPCEndpoint key 是否为链的某个节点 (完整 URL 或脱敏后的地址)
+func (c ChainConfig) hasRPCEndpoint(key string) bool {
+	for _, u := range c.RPCEndpoints() {
+		if key == u || key == rpcpool.Redact(u) {
+			return true
+		}
+	}
+	return false
+}
+
+func toChainEntry(c ChainConfig) chainEntry {
+	return chainEntry{
+		ChainID:         c.ChainID,
+		Name:            c.Name,
+		Type:            c.Type,
+		RPCURL:          c.RPCURL,
+		RPCFallbackURLs: c.RPCFallbackURLs,
+		RPCRateLimits:   c.RPCRateLimits,
+		ExplorerURL:     c.ExplorerURL,
+		NativeToken:     c.NativeToken,
+		Decimals:        c.Decimals,
+		StuckTxTimeout:  duration(c.StuckTxTimeout),
+		GasBumpPercent:  c.GasBumpPercent,
+		MaxReplacements: c.MaxReplacements,
+		Confirmations:   c.Confirmations,
+		ReorgDepth:      c.ReorgDepth,
+		L1Fee:           c.L1Fee,
+		TxFormat:        c.TxFormat,
+		AA:              c.AA,
+		Forwarders:      c.Forwarders,
+		Treasury:        c.Treasury,
+		CCTP:            c.CCTP,
+		Shadow:          c.Shadow,
+		MaxInFlight:     c.MaxInFlight,
+		WrappedNative:   c.WrappedNative,
+		UnwrapNative:    c.UnwrapNative,
+		PrivateTx: privateTxEntry{
+			RPCURL:    c.PrivateTx.RPCURL,
+			Method:    c.PrivateTx.Method,
+			MinAmount: c.PrivateTx.MinAmount,
+			Deadline:  duration(c.PrivateTx.Deadline),
+		},
+		GasTank: gasTankEntry{
+			MinBalance:    c.GasTank.MinBalance,
+			TargetBalance: c.GasTank.TargetBalance,
+			Addresses:     c.GasTank.Addresses,
+		},
+		GasCeiling: gasCeilingEntry{
+			MaxBaseFeeGwei: c.GasCeiling.MaxBaseFeeGwei,
+			Wait:           c.GasCeiling.Wait,
+		},
+		Sweep: sweepEntry{
+			ColdAddress: c.Sweep.ColdAddress,
+			Ceilings:    c.Sweep.Ceilings,
+		},
+		Testnet: c.Testnet,
+		Faucet: faucetEntry{
+			URL:        c.Faucet.URL,
+			APIKey:     c.Faucet.APIKey,
+			MinBalance: c.Faucet.MinBalance,
+			Cooldown:   duration(c.Faucet.Cooldown),
+		},
+	}
+}
+
+func (e chainEntry) chainConfig() ChainConfig {
+	return ChainConfig{
+		ChainID:         e.ChainID,
+		Name:            e.Name,
+		Type:            e.Type,
+		RPCURL:          e.RPCURL,
+		RPCFallbackURLs: e.RPCFallbackURLs,
+		RPCRateLimits:   e.RPCRateLimits,
+		ExplorerURL:     e.ExplorerURL,
+		NativeToken:     e.NativeToken,
+		Decimals:        e.Decimals,
+		StuckTxTimeout:  time.Duration(e.StuckTxTimeout),
+		GasBumpPercent:  e.GasBumpPercent,
+		MaxReplacements: e.MaxReplacements,
+		Confirmations:   e.Confirmations,
+		ReorgDepth:      e.ReorgDepth,
+		L1Fee:           e.L1Fee,
+		TxFormat:        e.TxFormat,
+		AA:              e.AA,
+		Forwarders:      e.Forwarders,
+		Treasury:        e.Treasury,
+		CCTP:            e.CCTP,
+		Shadow:          e.Shadow,
+		MaxInFlight:     e.MaxInFlight,
+		WrappedNative:   e.WrappedNative,
+		UnwrapNative:    e.UnwrapNative,
+		PrivateTx: PrivateTxConfig{
+			RPCURL:    e.PrivateTx.RPCURL,
+			Method:    e.PrivateTx.Method,
+			MinAmount: e.PrivateTx.MinAmount,
+			Deadline:  time.Duration(e.PrivateTx.Deadline),
+		},
+		GasTank: ChainGasTank{
+			MinBalance:    e.GasTank.MinBalance,
+			TargetBalance: e.GasTank.TargetBalance,
+			Addresses:     e.GasTank.Addresses,
+		},
+		GasCeiling: ChainGasCeiling{
+			MaxBaseFeeGwei: e.GasCeiling.MaxBaseFeeGwei,
+			Wait:           e.GasCeiling.Wait,
+		},
+		Sweep: ChainSweep{
+			ColdAddress: e.Sweep.ColdAddress,
+			Ceilings:    e.Sweep.Ceilings,
+		},
+		Testnet: e.Testnet,
+		Faucet: FaucetConfig{
+			URL:        e.Faucet.URL,
+			APIKey:     e.Faucet.APIKey,
+			MinBalance: e.Faucet.MinBalance,
+			Cooldown:   time.Duration(e.Faucet.Cooldown),
+		},
+	}
+}
@@ -1,19 +1,49 @@
 package config
 
 import (
+	"fmt"
+	"math/big"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/activity"
+	"github.com/protocol-bank/payout-engine/internal/approval"
+	"github.com/protocol-bank/payout-engine/internal/eventschema"
+	"github.com/protocol-bank/payout-engine/internal/forksim"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/objectstore"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/screening"
+	"github.com/protocol-bank/payout-engine/internal/settlement"
+	"github.com/protocol-bank/payout-engine/internal/swap"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
 )
 
 type Config struct {
 	Environment string
+	Network     string // "mainnet" (default) or "testnet": only chains of this network are loaded
 	GRPCPort    int
+	AdminPort   int // 运维 REST 接口端口 (0 关闭)
 	APISecret   string
 	PrivateKey  string // EVM Payout Signing Key
 
+	// Signing provider (local key or Fireblocks)
+	KMS kms.Config
+
+	// 按链覆盖的签名器 (未配置的链使用 KMS)
+	ChainSigners map[uint64]kms.Config
+
 	// TRON-specific
 	TronPrivateKey string // TRON Payout Signing Key (separate from EVM)
-	TRC20FeeLimit  int64  // Fee limit for TRC20 transfers (in SUN, default 100 TRX)
+	TRC20FeeLimit  int64  // Upper bound for the estimated TRC20 fee limit (in SUN, default 100 TRX); used as-is when estimation fails
+	// How long a TRON job waits for its transaction to be included in a block (0: report right after broadcast)
+	TronConfirmTimeout time.Duration
+	// TRON 链同时处理的任务数: 任务并发签名和广播，同一付款地址按出队顺序发送 (0: 由 worker 逐个处理)
+	TronBatchParallelism int
+	// TRON 付款签名器 (由 TronPrivateKey 或 PrivateKey 派生)，与 EVM 签名器同样限速和审计
+	TronKMS kms.Config
 
 	// Database
 	Database DatabaseConfig
@@ -23,10 +53,249 @@ type Config struct {
 
 	// Blockchain
 	Chains map[uint64]ChainConfig
+
+	// 链配置文件 (JSON，覆盖或补充内置链，支持热加载)
+	ChainsFile          string
+	ChainsWatchInterval time.Duration // 检查文件变更的间隔 (0 关闭，仍可通过 SIGHUP 重新加载)
+
+	// 多节点故障切换 (错误预算、探测间隔)
+	RPC rpcpool.Config
+
+	// 节点链 ID 与配置不符时: exit 拒绝启动 (默认)，disable 停用该链
+	ChainIDMismatch string
+
+	// 卡单检测间隔
+	StuckTxCheckInterval time.Duration
+
+	// 租户代币白名单 JSON 文件 (为空时不限制)
+	TokenAllowlistFile string
+
+	// 链上代币元数据 (symbol/decimals) 的 Redis 缓存时间
+	TokenMetadataTTL time.Duration
+
+	// 付款地址支出策略 JSON 文件 (单笔/日/周上限、代币限制，为空时不限制)
+	SpendingPolicyFile string
+
+	// 收款地址制裁/风险筛查 (Chainalysis、TRM，未配置时不筛查)
+	Screening screening.Config
+
+	// 批次中某代币合计金额 (整币，如 "10000") 达到该值时收款地址须在白名单中 (为空时不要求)
+	RecipientAllowlistThreshold string
+
+	// 签名前在分叉上模拟大额交易 (Anvil、Tenderly，未配置时不模拟)
+	ForkSimulation forksim.Config
+
+	// 大额支付前检查收款地址的链上活跃度，新地址/休眠地址须人工批准
+	RecipientActivity activity.Config
+
+	// 转账前兑换的 DEX 聚合器 (0x、1inch，未配置时不支持兑换批次)
+	Swap swap.Config
+
+	// 批次合计美元价值达到阈值时须审批人签名放行后才入队
+	Approval approval.Config
+
+	// 测试网水龙头余额检查间隔
+	FaucetCheckInterval time.Duration
+
+	// 运营地址 gas 自动补充 (阈值按链配置，见 ChainConfig.GasTank)
+	GasTank GasTankConfig
+
+	// x402 中继: 以付款地址代付 Gas 执行 EIP-3009 授权 (POST /x402/submit) 和 ERC-2771 转发请求 (POST /x402/forward)，默认关闭
+	X402Relayer bool
+
+	// 热钱包归集到冷钱包的检查间隔 (上限按链配置，见 ChainConfig.Sweep)
+	SweepInterval time.Duration
+
+	// 旧版本密钥地址余额转移的检查间隔 (轮换配置见 KMS.Previous)
+	KeyRotationInterval time.Duration
+
+	// 签名限速/异常告警 webhook (为空时只记录日志，限速和异常规则见 KMS.Guard)
+	SigningAlertURL    string
+	SigningAlertSecret string
+
+	// 签名器健康检查间隔: 重新获取公钥校验密钥可用，不可用时就绪检查失败并告警
+	SignerHealthInterval time.Duration
+
+	// 签名服务调用审计 (每次 SignHash/SignTransaction 一条记录，见 kms.AuditedSigner)
+	KMSAudit KMSAuditConfig
+
+	// base fee 回落检查间隔: 等待模式下暂存的任务在回落到上限以下后放回队列 (上限按链配置，见 ChainConfig.GasCeiling)
+	GasCeilingCheckInterval time.Duration
+
+	// 付款地址在途交易 (已分配 nonce 未上链) 默认上限，达到后任务稍后重试，0 不限制 (可按链覆盖，见 ChainConfig.MaxInFlight)
+	MaxInFlightTxs int
+
+	// Circle CCTP 证明服务 (Iris) 地址，为空时按网络使用 Circle 主网或沙盒服务 (合约按链配置，见 ChainConfig.CCTP)
+	CCTPAttestationURL string
+
+	// 影子模式: 主网批次按比例缩小金额后镜像到对应测试网 (目标链按链配置，见 ChainConfig.Shadow)
+	Shadow ShadowConfig
+
+	// 定时批次 (execute_at): 到期检查间隔、最远可提前安排的时间
+	ScheduleCheckInterval time.Duration
+	ScheduleMaxAhead      time.Duration
+
+	// 任务失败重试策略
+	JobRetry RetryConfig
+
+	// 链熔断 (失败率过高时暂停该链)
+	Circuit CircuitConfig
+
+	// 队列积压告警 (待处理任务过多或等待过久)
+	QueueAlert QueueAlertConfig
+
+	// 队列优先级通道 (URGENT/HIGH/MEDIUM/LOW)
+	Priority PriorityConfig
+
+	// 任务租约 (worker 崩溃后其他 worker 认领任务的等待时间)
+	Lease LeaseConfig
+
+	// 批次状态回调投递间隔
+	WebhookDispatchInterval time.Duration
+
+	// 批次未指定 webhook_schema_version 时的事件版本 (默认 v1，兼容旧的消费方)
+	WebhookSchemaVersion int
+
+	// 每日结算汇总
+	Settlement SettlementConfig
+
+	// 批次报告上传到对象存储 (未配置时只能通过 GET /batches/{id}/report 下载)
+	Reports ReportConfig
+
+	// OpenTelemetry 链路追踪 (OTLP/gRPC)
+	Tracing tracing.Config
+}
+
+// SettlementConfig 每日结算汇总 (按 UTC 自然日)
+type SettlementConfig struct {
+	DestinationsFile string        // 租户 webhook/邮件目标 JSON 文件 (为空时不发送)
+	Delay            time.Duration // 日切后等待多久再汇总，留给尾部任务结束
+	CheckInterval    time.Duration
+	SMTP             settlement.SMTPConfig
+}
+
+// ReportConfig 批次结束后生成报告 (CSV 和 JSON) 并上传到 S3/GCS
+type ReportConfig struct {
+	Store         objectstore.Config // Provider 为空时不上传
+	CheckInterval time.Duration      // 检查待生成报告的间隔
+}
+
+// ShadowConfig 测试网影子模式: 主网批次提交成功后，按比例缩小金额、映射代币后在影子测试网再执行一次，
+// 用于在不动用真实资金的情况下验证新链和 gas 逻辑
+type ShadowConfig struct {
+	Enabled  bool
+	ScaleBps int // 镜像金额 = 原金额 × ScaleBps / 10000 (最小 1 个最小单位)
+}
+
+// DefaultShadowScaleBps 默认镜像金额比例 (0.1%)
+const DefaultShadowScaleBps = 10
+
+// 签名调用审计的输出
+const (
+	KMSAuditStdout   = "stdout"   // 每行一条 JSON
+	KMSAuditPostgres = "postgres" // kms_signing_audit 表 (需要 DATABASE_URL)
+	KMSAuditWebhook  = "webhook"  // 逐条 POST 到 SIEM 采集端
+)
+
+// KMSAuditConfig 签名调用审计 (Sinks 为空时关闭)
+type KMSAuditConfig struct {
+	Sinks         []string // stdout、postgres、webhook，可多选
+	WebhookURL    string
+	WebhookSecret string
+	Required      bool // 审计记录写入失败时丢弃签名 (默认只记录日志)
+}
+
+// GasTankConfig 运营地址 gas 补充: 资金钱包向原生代币余额低于阈值的付款地址转账补足。
+// EVM 资金钱包与付款签名器同样支持本地私钥和 Fireblocks；两者都未配置时关闭。
+type GasTankConfig struct {
+	Funding        kms.Config    // EVM 资金钱包 (Provider 为空时不补充 EVM 链)
+	TronFundingKey string        // TRON 资金钱包私钥 (为空时不补充 TRON 链)
+	TronFunding    kms.Config    // TRON 资金钱包签名器 (由 TronFundingKey 派生)
+	CheckInterval  time.Duration // 余额检查间隔
+	Cooldown       time.Duration // 同一地址两次补充的最小间隔
+	ConfirmTimeout time.Duration // 等待补充交易上链的时间
+	AlertURL       string        // 补充失败、资金钱包余额不足告警 webhook (为空时只记录日志)
+	AlertSecret    string        // 告警签名密钥
+}
+
+// Enabled 是否配置了资金钱包
+func (c GasTankConfig) Enabled() bool {
+	return c.Funding.Provider != "" || c.TronFundingKey != ""
+}
+
+// RetryConfig 任务重试策略 (零值字段使用默认值)
+type RetryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+}
+
+// PriorityConfig 队列优先级通道策略 (零值字段使用默认值)
+type PriorityConfig struct {
+	MaxWait      time.Duration // 低优先级任务等待超过该时间后先于高优先级处理 (防饿死)
+	PollInterval time.Duration // 各通道均为空时 worker 的轮询间隔
+}
+
+// LeaseConfig 任务租约 (零值字段使用默认值)
+type LeaseConfig struct {
+	Timeout time.Duration // 处理中的任务超过该时间未续约时视为 worker 已崩溃
+}
+
+// CircuitConfig 链熔断策略 (零值字段使用默认值)
+type CircuitConfig struct {
+	FailureRate   float64       // 窗口内失败率阈值 (0-1)
+	MinSamples    int           // 计算失败率所需的最少结果数
+	Window        time.Duration // 统计窗口
+	Cooldown      time.Duration // 熔断后多久开始探测
+	CheckInterval time.Duration // 熔断监控间隔
+	CanaryTimeout time.Duration // 探测交易等待上链的时间
+	AlertURL      string        // 熔断/恢复告警 webhook (为空时只记录日志)
+	AlertSecret   string        // 告警签名密钥
+}
+
+// QueueAlertConfig 队列积压告警: 待处理任务数或最久任务的等待时间超过阈值时告警，恢复后再通知一次 (阈值为 0 时不检查)
+type QueueAlertConfig struct {
+	CheckInterval time.Duration
+	MaxDepth      int64         // 全部通道待投递任务数
+	MaxChainDepth int64         // 单链待投递任务数
+	MaxJobAge     time.Duration // 待处理任务自提交起的等待时间
+	AlertURL      string        // 告警 webhook (为空时只记录日志)
+	AlertSecret   string        // 告警签名密钥
+	PagerDutyKey  string        // PagerDuty Events API v2 routing key (为空时不发送)
+	PagerDutyURL  string
+}
+
+// DefaultPagerDutyURL PagerDuty Events API v2
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// 节点链 ID 不符时的处理 (CHAIN_ID_MISMATCH)
+const (
+	ChainIDMismatchExit    = "exit"
+	ChainIDMismatchDisable = "disable"
+)
+
+// 付款交易格式 (ChainConfig.TxFormat)
+const (
+	TxFormatEIP1559 = "eip1559"
+	TxFormatZkSync  = "zksync"
+)
+
+// Network modes
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+)
+
+// IsTestnet 是否运行在测试网模式
+func (c *Config) IsTestnet() bool {
+	return c.Network == NetworkTestnet
 }
 
 type DatabaseConfig struct {
-	URL string
+	URL                 string        // Postgres 任务账本 (为空时不记录)
+	LedgerFlushInterval time.Duration // 账本写入间隔
 }
 
 type RedisConfig struct {
@@ -44,26 +313,474 @@ type ChainConfig struct {
 	NativeToken string
 	Decimals    int
 	Type        string // "evm" or "tron"
+
+	// 备用节点 (主节点故障时切换，EVM 链按延迟选择)
+	RPCFallbackURLs []string
+	// 按节点覆盖 eth_estimateGas / eth_sendRawTransaction 限速，键为节点 URL (EVM only)
+	RPCRateLimits map[string]rpcpool.RateLimit
+
+	// Stuck transaction replacement (EVM only)
+	StuckTxTimeout  time.Duration // Pending longer than this is considered stuck
+	GasBumpPercent  int           // Fee increase per replacement (min 10)
+	MaxReplacements int           // Give up bumping after this many replacements
+
+	// 交易所在区块之上 (含该区块) 达到该确认数才视为最终 (0 或 1 表示上链即最终)
+	Confirmations uint64
+
+	// 已上链交易在该深度内持续核对区块哈希，检测重组 (EVM only, 0 使用默认值；小于 Confirmations 时按 Confirmations)
+	ReorgDepth uint64
+
+	// L1 数据费模型: "op" (OP Stack) 或 "arbitrum"，估算网络费和预检余额时计入 (EVM only, 为空时不计算)
+	L1Fee string
+
+	// 付款交易格式 (EVM only): 为空或 TxFormatEIP1559 为标准 EIP-1559 交易，TxFormatZkSync 为 zkSync Era 的 EIP-712 交易
+	TxFormat string
+
+	// ERC-4337 smart-account payouts (EVM only, optional)
+	AA AAConfig
+
+	// ERC-2771 可信转发合约: x402 中继执行付款方签名的转发请求 (EVM only, 目标代币须信任该转发合约)
+	Forwarders []string
+
+	// 中央金库合约 (EVM only): UseTreasury 批次以 transferFrom 从该合约出款，合约须对付款地址授权代币额度
+	Treasury string
+
+	// Circle CCTP 合约 (EVM only): 资金在其他链时经 CCTP 销毁/铸造 USDC 后再支付
+	CCTP ChainCCTP
+
+	// 影子模式下该主网链的批次镜像到的测试网 (见 Config.Shadow)
+	Shadow ChainShadow
+
+	// 原生代币不足时从包装代币 (WETH / WMATIC) 即时解包 (EVM only)
+	WrappedNative string // 包装代币合约地址
+	UnwrapNative  bool   // 开启即时解包
+
+	// 付款地址在途交易 (已广播未上链) 上限，0 使用 MAX_IN_FLIGHT_TXS (EVM only)
+	MaxInFlight int
+
+	// 大额 ERC20 支付经私有交易池广播，防止三明治攻击 (EVM only, optional)
+	PrivateTx PrivateTxConfig
+
+	// 运营地址 gas 自动补充阈值 (资金钱包见 Config.GasTank)
+	GasTank ChainGasTank
+
+	// base fee 超过上限时不发送 (EVM only)
+	GasCeiling ChainGasCeiling
+
+	// 付款钱包余额超过上限的部分定期归集到冷钱包
+	Sweep ChainSweep
+
+	// 测试网链 (仅在 testnet 模式下加载)
+	Testnet bool
+	Faucet  FaucetConfig
+}
+
+// ChainGasTank 链上运营地址的补充阈值 (最小单位 wei / SUN)。MinBalance 为空时不监控该链。
+type ChainGasTank struct {
+	MinBalance    string   // 余额低于该值时补充
+	TargetBalance string   // 补充到该余额 (为空时为 MinBalance 的 2 倍)
+	Addresses     []string // 付款钱包之外需要监控的地址
+}
+
+// SweepNative ChainSweep.Ceilings 中表示原生代币的键
+const SweepNative = "native"
+
+// ChainSweep 热钱包归集: 付款钱包的资产余额超过 Ceiling 时，把超出 Target 的部分转到冷钱包
+type ChainSweep struct {
+	ColdAddress string
+	Ceilings    map[string]SweepCeiling // 资产 (SweepNative、代币合约地址或 TRC10 资产 ID) -> 上限
+}
+
+// SweepCeiling 一种资产的归集上限 (最小单位)
+type SweepCeiling struct {
+	Ceiling string `json:"ceiling"` // 余额超过该值时归集
+	Target  string `json:"target"`  // 归集后热钱包保留的余额 (为空时等于 Ceiling)
+}
+
+// Enabled 是否配置了冷钱包和至少一种资产的上限
+func (s ChainSweep) Enabled() bool {
+	return s.ColdAddress != "" && len(s.Ceilings) > 0
+}
+
+// Limits 解析上限和保留余额
+func (c SweepCeiling) Limits() (ceiling, target *big.Int, err error) {
+	ceiling, ok := new(big.Int).SetString(c.Ceiling, 10)
+	if !ok || ceiling.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("ceiling must be a positive integer")
+	}
+	if c.Target == "" {
+		return ceiling, ceiling, nil
+	}
+	target, ok = new(big.Int).SetString(c.Target, 10)
+	if !ok || target.Sign() < 0 || target.Cmp(ceiling) > 0 {
+		return nil, nil, fmt.Errorf("target must be an integer between 0 and the ceiling")
+	}
+	return ceiling, target, nil
+}
+
+// Thresholds 解析补充阈值: 余额低于 min 时补充到 target
+func (t ChainGasTank) Thresholds() (min, target *big.Int, err error) {
+	min, ok := new(big.Int).SetString(t.MinBalance, 10)
+	if !ok || min.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("min_balance must be a positive integer")
+	}
+	if t.TargetBalance == "" {
+		return min, new(big.Int).Lsh(min, 1), nil
+	}
+	target, ok = new(big.Int).SetString(t.TargetBalance, 10)
+	if !ok || target.Cmp(min) <= 0 {
+		return nil, nil, fmt.Errorf("target_balance must be an integer above min_balance")
+	}
+	return min, target, nil
+}
+
+// ChainGasCeiling 链的 base fee 上限 (如避免发薪批次在 gas 高峰期执行)。MaxBaseFeeGwei 为空时不限制。
+type ChainGasCeiling struct {
+	MaxBaseFeeGwei string // 下一区块 base fee (无 EIP-1559 的链为 gas price) 超过该值时不发送，可为小数
+	Wait           bool   // true: 任务暂存，回落后放回队列 (不计重试次数)；false: 按可重试的失败处理
+}
+
+// MaxBaseFee 上限 (wei)，未配置时为 nil
+func (g ChainGasCeiling) MaxBaseFee() (*big.Int, error) {
+	if g.MaxBaseFeeGwei == "" {
+		return nil, nil
+	}
+	gwei, ok := new(big.Rat).SetString(g.MaxBaseFeeGwei)
+	if !ok || gwei.Sign() <= 0 {
+		return nil, fmt.Errorf("max_base_fee_gwei must be a positive number")
+	}
+	wei := new(big.Rat).Mul(gwei, new(big.Rat).SetInt64(1_000_000_000))
+	return new(big.Int).Quo(wei.Num(), wei.Denom()), nil
+}
+
+// FaucetConfig 测试网水龙头: 付款钱包余额低于 MinBalance 时自动请求充值
+type FaucetConfig struct {
+	URL        string        // POST {"address","chain_id"}; empty disables top-ups
+	APIKey     string        // Optional bearer token
+	MinBalance string        // Smallest unit (wei / SUN)
+	Cooldown   time.Duration // Minimum time between requests (faucets rate-limit)
+}
+
+// PrivateTxConfig 私有交易池 (Flashbots Protect、MEV Blocker 等) 广播设置
+type PrivateTxConfig struct {
+	RPCURL    string        // Empty disables private submission on the chain
+	Method    string        // eth_sendRawTransaction (default) or eth_sendPrivateTransaction
+	MinAmount string        // ERC20 amount in whole tokens at or above which payouts go private ("" = all ERC20 payouts)
+	Deadline  time.Duration // Rebroadcast to the public mempool if not mined within this time
+}
+
+// ChainCCTP 链上的 Circle CCTP 合约和原生 USDC，未配置时该链不参与跨链路由
+type ChainCCTP struct {
+	Domain             uint32 `json:"domain"`              // Circle 为该链分配的 domain (Ethereum 为 0)
+	TokenMessenger     string `json:"token_messenger"`     // depositForBurn (源链)
+	MessageTransmitter string `json:"message_transmitter"` // receiveMessage (目标链)
+	USDC               string `json:"usdc"`
+}
+
+// Enabled 是否配置了 CCTP 合约
+func (c ChainCCTP) Enabled() bool {
+	return c.TokenMessenger != "" && c.MessageTransmitter != "" && c.USDC != ""
+}
+
+// builtinCCTP 内置链的 Circle CCTP 合约 (原生 USDC)
+var builtinCCTP = map[uint64]ChainCCTP{
+	1:        {Domain: 0, TokenMessenger: "0xBd3fa81B58Ba92a82136038B25aDec7066af3155", MessageTransmitter: "0x0a992d191DEeC32aFe36203Ad87D7d289a738F81", USDC: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+	10:       {Domain: 2, TokenMessenger: "0x2B4069517957735bE00ceE0fadAE88a26365528f", MessageTransmitter: "0x4D41f22c5a0e5c74090899E5a8Fb597a8842b3e8", USDC: "0x0b2C639c533813f4Aa9D7837cAf62653d097Ff85"},
+	137:      {Domain: 7, TokenMessenger: "0x9daF8c91AEFAE50b9c0E69629D3F6Ca40cA3B3FE", MessageTransmitter: "0xF3be9355363857F3e001be68856A2f96b4C39Ba9", USDC: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"},
+	8453:     {Domain: 6, TokenMessenger: "0x1682Ae6375C4E4A97e4B583BC394c861A46D8962", MessageTransmitter: "0xAD09780d193884d503182aD4588450C416D6F9D4", USDC: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"},
+	42161:    {Domain: 3, TokenMessenger: "0x19330d10D9Cc8751218eaf51E8885D058642E08A", MessageTransmitter: "0xC30362313FBBA5cf9163F0bb16a0e01f01A896ca", USDC: "0xaf88d065e77c8cC2239327C5EDb3A432268e5831"},
+	84532:    {Domain: 6, TokenMessenger: "0x9f3B8679c73C2Fef8b59B4f3444d4e156fb70AA5", MessageTransmitter: "0x7865fAfC2db2093669d92c0F33AeEF291086BEFD", USDC: "0x036CbD53842c5426634e7929541eC2318f3dCF7e"},
+	11155111: {Domain: 0, TokenMessenger: "0x9f3B8679c73C2Fef8b59B4f3444d4e156fb70AA5", MessageTransmitter: "0x7865fAfC2db2093669d92c0F33AeEF291086BEFD", USDC: "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"},
+}
+
+// ChainShadow 影子测试网和代币映射 (主网代币地址 → 测试网代币地址)，未映射的代币不镜像
+type ChainShadow struct {
+	ChainID uint64            `json:"chain_id"`
+	Tokens  map[string]string `json:"tokens"`
+}
+
+// builtinShadow 内置主网链的影子测试网 (USDC / USDT)
+var builtinShadow = map[uint64]ChainShadow{
+	1:         {ChainID: 11155111, Tokens: map[string]string{"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"}},
+	8453:      {ChainID: 84532, Tokens: map[string]string{"0x833589fcd6edb6e08f4c7c32d4f71b54bda02913": "0x036CbD53842c5426634e7929541eC2318f3dCF7e"}},
+	728126428: {ChainID: 3448148188, Tokens: map[string]string{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t": "TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf"}},
+}
+
+// AAConfig ERC-4337 account-abstraction settings for one chain
+type AAConfig struct {
+	EntryPoint        string `json:"entry_point"`         // Defaults to the v0.6 EntryPoint
+	BundlerURL        string `json:"bundler_url"`         // Empty disables smart-account payouts on the chain
+	PaymasterURL      string `json:"paymaster_url"`       // Optional: gas sponsorship
+	PaymasterPolicyID string `json:"paymaster_policy_id"` // Optional: sponsorship policy passed to the paymaster
+	SmartAccount      string `json:"smart_account"`       // SimpleAccount-compatible sender owned by the chain's signer
 }
 
 func Load() (*Config, error) {
 	port, _ := strconv.Atoi(getEnv("GRPC_PORT", "50051"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	adminPort, _ := strconv.Atoi(getEnv("ADMIN_HTTP_PORT", "8081"))
 
 	trc20FeeLimit, _ := strconv.ParseInt(getEnv("TRC20_FEE_LIMIT", "100000000"), 10, 64)
 	if trc20FeeLimit <= 0 {
 		trc20FeeLimit = 100_000_000 // 100 TRX default
 	}
+	tronConfirmTimeout, _ := time.ParseDuration(getEnv("TRON_CONFIRM_TIMEOUT", "90s"))
+	tronBatchParallelism, _ := strconv.Atoi(getEnv("TRON_BATCH_PARALLELISM", "16"))
+
+	fireblocksSecret := getEnv("FIREBLOCKS_SECRET_KEY", "")
+	if path := getEnv("FIREBLOCKS_SECRET_KEY_PATH", ""); path != "" && fireblocksSecret == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FIREBLOCKS_SECRET_KEY_PATH: %w", err)
+		}
+		fireblocksSecret = string(data)
+	}
+	fireblocksTimeout, _ := time.ParseDuration(getEnv("FIREBLOCKS_SIGN_TIMEOUT", "2m"))
+	mpcQuorum, _ := strconv.Atoi(getEnv("MPC_QUORUM", "2"))
+	mpcTimeout, _ := time.ParseDuration(getEnv("MPC_SIGN_TIMEOUT", "2m"))
+	// 同一签名服务的并发请求上限 (0 不限)；Fireblocks 单次请求签名多个摘要，KMS_SIGN_COALESCE_WINDOW 内的并发签名合并为一次请求
+	kmsConcurrency, _ := strconv.Atoi(getEnv("KMS_SIGN_CONCURRENCY", "0"))
+	kmsBatchSize, _ := strconv.Atoi(getEnv("KMS_SIGN_BATCH_SIZE", "50"))
+	kmsCoalesceWindow, _ := time.ParseDuration(getEnv("KMS_SIGN_COALESCE_WINDOW", "0"))
+	stuckTxInterval, _ := time.ParseDuration(getEnv("STUCK_TX_CHECK_INTERVAL", "30s"))
+	faucetInterval, _ := time.ParseDuration(getEnv("FAUCET_CHECK_INTERVAL", "5m"))
+	gasTankInterval, _ := time.ParseDuration(getEnv("GAS_TANK_CHECK_INTERVAL", "1m"))
+	gasTankCooldown, _ := time.ParseDuration(getEnv("GAS_TANK_COOLDOWN", "10m"))
+	gasTankConfirmTimeout, _ := time.ParseDuration(getEnv("GAS_TANK_CONFIRM_TIMEOUT", "2m"))
+	sweepInterval, _ := time.ParseDuration(getEnv("SWEEP_INTERVAL", "1h"))
+	keyRotationInterval, _ := time.ParseDuration(getEnv("KEY_ROTATION_CHECK_INTERVAL", "10m"))
+	signerHealthInterval, _ := time.ParseDuration(getEnv("SIGNER_HEALTH_CHECK_INTERVAL", "1m"))
+	gasCeilingInterval, _ := time.ParseDuration(getEnv("GAS_CEILING_CHECK_INTERVAL", "30s"))
+	scheduleInterval, _ := time.ParseDuration(getEnv("SCHEDULE_CHECK_INTERVAL", "15s"))
+	scheduleMaxAhead, _ := time.ParseDuration(getEnv("SCHEDULE_MAX_AHEAD", "2160h"))
+	tokenMetadataTTL, _ := time.ParseDuration(getEnv("TOKEN_METADATA_TTL", "24h"))
+	jobMaxRetries, _ := strconv.Atoi(getEnv("JOB_MAX_RETRIES", "0"))
+	jobRetryBackoff, _ := time.ParseDuration(getEnv("JOB_RETRY_BACKOFF", "0s"))
+	jobRetryMaxBackoff, _ := time.ParseDuration(getEnv("JOB_RETRY_MAX_BACKOFF", "0s"))
+	jobRetryMultiplier, _ := strconv.ParseFloat(getEnv("JOB_RETRY_MULTIPLIER", "0"), 64)
+	jobRetryJitter, _ := strconv.ParseFloat(getEnv("JOB_RETRY_JITTER", "0"), 64)
+	circuitFailureRate, _ := strconv.ParseFloat(getEnv("CIRCUIT_FAILURE_RATE", "0"), 64)
+	priorityMaxWait, _ := time.ParseDuration(getEnv("QUEUE_PRIORITY_MAX_WAIT", "0s"))
+	queuePollInterval, _ := time.ParseDuration(getEnv("QUEUE_POLL_INTERVAL", "0s"))
+	queueLeaseTimeout, _ := time.ParseDuration(getEnv("QUEUE_LEASE_TIMEOUT", "0s"))
+	circuitMinSamples, _ := strconv.Atoi(getEnv("CIRCUIT_MIN_SAMPLES", "0"))
+	circuitWindow, _ := time.ParseDuration(getEnv("CIRCUIT_WINDOW", "0s"))
+	circuitCooldown, _ := time.ParseDuration(getEnv("CIRCUIT_COOLDOWN", "0s"))
+	circuitInterval, _ := time.ParseDuration(getEnv("CIRCUIT_CHECK_INTERVAL", "15s"))
+	circuitCanaryTimeout, _ := time.ParseDuration(getEnv("CIRCUIT_CANARY_TIMEOUT", "2m"))
+	queueAlertInterval, _ := time.ParseDuration(getEnv("QUEUE_ALERT_CHECK_INTERVAL", "30s"))
+	queueAlertMaxDepth, _ := strconv.ParseInt(getEnv("QUEUE_ALERT_MAX_DEPTH", "0"), 10, 64)
+	queueAlertMaxChainDepth, _ := strconv.ParseInt(getEnv("QUEUE_ALERT_MAX_CHAIN_DEPTH", "0"), 10, 64)
+	queueAlertMaxJobAge, _ := time.ParseDuration(getEnv("QUEUE_ALERT_MAX_JOB_AGE", "0s"))
+	webhookInterval, _ := time.ParseDuration(getEnv("WEBHOOK_DISPATCH_INTERVAL", "2s"))
+	webhookSchemaVersion, _ := strconv.Atoi(getEnv("WEBHOOK_SCHEMA_VERSION", "1"))
+	ledgerInterval, _ := time.ParseDuration(getEnv("LEDGER_FLUSH_INTERVAL", "2s"))
+	settlementDelay, _ := time.ParseDuration(getEnv("SETTLEMENT_REPORT_DELAY", "1h"))
+	settlementInterval, _ := time.ParseDuration(getEnv("SETTLEMENT_CHECK_INTERVAL", "10m"))
+	reportInterval, _ := time.ParseDuration(getEnv("REPORT_CHECK_INTERVAL", "30s"))
+	rpcErrorBudget, _ := strconv.Atoi(getEnv("RPC_ERROR_BUDGET", "0"))
+	rpcErrorWindow, _ := time.ParseDuration(getEnv("RPC_ERROR_WINDOW", "0s"))
+	rpcCooldown, _ := time.ParseDuration(getEnv("RPC_COOLDOWN", "0s"))
+	rpcProbeInterval, _ := time.ParseDuration(getEnv("RPC_PROBE_INTERVAL", "0s"))
+	rpcConsecutiveFailures, _ := strconv.Atoi(getEnv("RPC_CONSECUTIVE_FAILURES", "0"))
+	rpcMaxBlockLag, _ := strconv.ParseUint(getEnv("RPC_MAX_BLOCK_LAG", "0"), 10, 64)
+	rpcRateLimit, _ := strconv.ParseFloat(getEnv("RPC_RATE_LIMIT_RPS", "0"), 64)
+	rpcRateBurst, _ := strconv.Atoi(getEnv("RPC_RATE_LIMIT_BURST", "0"))
+	chainsWatchInterval, _ := time.ParseDuration(getEnv("CHAINS_WATCH_INTERVAL", "30s"))
+	screeningCacheTTL, _ := time.ParseDuration(getEnv("SCREENING_CACHE_TTL", "24h"))
+	recipientMinAge, _ := time.ParseDuration(getEnv("RECIPIENT_MIN_AGE", "0s"))
+	recipientDormantAfter, _ := time.ParseDuration(getEnv("RECIPIENT_DORMANT_AFTER", "0s"))
+	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	approvalQuorum, _ := strconv.Atoi(getEnv("APPROVAL_QUORUM", "1"))
+	swapMaxSlippage, _ := strconv.Atoi(getEnv("SWAP_MAX_SLIPPAGE_BPS", strconv.Itoa(swap.DefaultMaxSlippageBps)))
+	shadowScale, _ := strconv.Atoi(getEnv("SHADOW_SCALE_BPS", strconv.Itoa(DefaultShadowScaleBps)))
+	maxInFlight, _ := strconv.Atoi(getEnv("MAX_IN_FLIGHT_TXS", "16"))
+	traceSampleRatio, _ := strconv.ParseFloat(getEnv("OTEL_TRACES_SAMPLER_ARG", "1"), 64)
+
+	network := getEnv("PAYOUT_NETWORK", NetworkMainnet)
+	if network != NetworkMainnet && network != NetworkTestnet {
+		return nil, fmt.Errorf("invalid PAYOUT_NETWORK: %s (expected mainnet or testnet)", network)
+	}
 
 	cfg := &Config{
-		Environment:    getEnv("ENVIRONMENT", "development"),
-		GRPCPort:       port,
-		APISecret:      getEnv("API_SECRET", ""),
-		PrivateKey:     getEnv("PAYOUT_PRIVATE_KEY", ""),
-		TronPrivateKey: getEnv("TRON_PRIVATE_KEY", ""),
-		TRC20FeeLimit:  trc20FeeLimit,
+		Environment:                 getEnv("ENVIRONMENT", "development"),
+		Network:                     network,
+		GRPCPort:                    port,
+		AdminPort:                   adminPort,
+		APISecret:                   getEnv("API_SECRET", ""),
+		PrivateKey:                  getEnv("PAYOUT_PRIVATE_KEY", ""),
+		TronPrivateKey:              getEnv("TRON_PRIVATE_KEY", ""),
+		TRC20FeeLimit:               trc20FeeLimit,
+		TronConfirmTimeout:          tronConfirmTimeout,
+		TronBatchParallelism:        tronBatchParallelism,
+		StuckTxCheckInterval:        stuckTxInterval,
+		TokenAllowlistFile:          getEnv("TOKEN_ALLOWLIST_FILE", ""),
+		TokenMetadataTTL:            tokenMetadataTTL,
+		SpendingPolicyFile:          getEnv("SPENDING_POLICY_FILE", ""),
+		RecipientAllowlistThreshold: getEnv("RECIPIENT_ALLOWLIST_THRESHOLD", ""),
+		ChainsFile:                  getEnv("CHAINS_FILE", ""),
+		ChainsWatchInterval:         chainsWatchInterval,
+		ChainIDMismatch:             getEnv("CHAIN_ID_MISMATCH", ChainIDMismatchExit),
+		FaucetCheckInterval:         faucetInterval,
+		X402Relayer:                 getEnv("X402_RELAYER_ENABLED", "false") == "true",
+		GasCeilingCheckInterval:     gasCeilingInterval,
+		CCTPAttestationURL:          getEnv("CCTP_ATTESTATION_URL", ""),
+		MaxInFlightTxs:              maxInFlight,
+		GasTank: GasTankConfig{
+			TronFundingKey: getEnv("GAS_TANK_TRON_FUNDING_PRIVATE_KEY", ""),
+			CheckInterval:  gasTankInterval,
+			Cooldown:       gasTankCooldown,
+			ConfirmTimeout: gasTankConfirmTimeout,
+			AlertURL:       getEnv("GAS_TANK_ALERT_WEBHOOK_URL", ""),
+			AlertSecret:    getEnv("GAS_TANK_ALERT_WEBHOOK_SECRET", ""),
+		},
+		SweepInterval:         sweepInterval,
+		KeyRotationInterval:   keyRotationInterval,
+		SigningAlertURL:       getEnv("SIGNING_ALERT_WEBHOOK_URL", ""),
+		SigningAlertSecret:    getEnv("SIGNING_ALERT_WEBHOOK_SECRET", ""),
+		SignerHealthInterval:  signerHealthInterval,
+		ScheduleCheckInterval: scheduleInterval,
+		ScheduleMaxAhead:      scheduleMaxAhead,
+		JobRetry: RetryConfig{
+			MaxRetries:     jobMaxRetries,
+			InitialBackoff: jobRetryBackoff,
+			MaxBackoff:     jobRetryMaxBackoff,
+			Multiplier:     jobRetryMultiplier,
+			Jitter:         jobRetryJitter,
+		},
+		Circuit: CircuitConfig{
+			FailureRate:   circuitFailureRate,
+			MinSamples:    circuitMinSamples,
+			Window:        circuitWindow,
+			Cooldown:      circuitCooldown,
+			CheckInterval: circuitInterval,
+			CanaryTimeout: circuitCanaryTimeout,
+			AlertURL:      getEnv("CIRCUIT_ALERT_WEBHOOK_URL", ""),
+			AlertSecret:   getEnv("CIRCUIT_ALERT_WEBHOOK_SECRET", ""),
+		},
+		QueueAlert: QueueAlertConfig{
+			CheckInterval: queueAlertInterval,
+			MaxDepth:      queueAlertMaxDepth,
+			MaxChainDepth: queueAlertMaxChainDepth,
+			MaxJobAge:     queueAlertMaxJobAge,
+			AlertURL:      getEnv("QUEUE_ALERT_WEBHOOK_URL", ""),
+			AlertSecret:   getEnv("QUEUE_ALERT_WEBHOOK_SECRET", ""),
+			PagerDutyKey:  getEnv("PAGERDUTY_ROUTING_KEY", ""),
+			PagerDutyURL:  getEnv("PAGERDUTY_EVENTS_URL", DefaultPagerDutyURL),
+		},
+		Priority: PriorityConfig{
+			MaxWait:      priorityMaxWait,
+			PollInterval: queuePollInterval,
+		},
+		Lease: LeaseConfig{
+			Timeout: queueLeaseTimeout,
+		},
+		WebhookDispatchInterval: webhookInterval,
+		WebhookSchemaVersion:    webhookSchemaVersion,
+		Tracing: tracing.Config{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			Insecure:    getEnv("OTEL_EXPORTER_OTLP_INSECURE", "false") == "true",
+			SampleRatio: traceSampleRatio,
+		},
+		Settlement: SettlementConfig{
+			DestinationsFile: getEnv("SETTLEMENT_REPORT_FILE", ""),
+			Delay:            settlementDelay,
+			CheckInterval:    settlementInterval,
+			SMTP: settlement.SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     smtpPort,
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", ""),
+			},
+		},
+		Reports: ReportConfig{
+			Store: objectstore.Config{
+				Provider:  getEnv("REPORT_STORE_PROVIDER", ""),
+				Bucket:    getEnv("REPORT_STORE_BUCKET", ""),
+				Region:    getEnv("REPORT_STORE_REGION", ""),
+				Endpoint:  getEnv("REPORT_STORE_ENDPOINT", ""),
+				AccessKey: getEnv("REPORT_STORE_ACCESS_KEY", ""),
+				SecretKey: getEnv("REPORT_STORE_SECRET_KEY", ""),
+				Prefix:    getEnv("REPORT_STORE_PREFIX", "payout-reports/"),
+			},
+			CheckInterval: reportInterval,
+		},
+		Screening: screening.Config{
+			Provider:  getEnv("SCREENING_PROVIDER", ""),
+			APIKey:    getEnv("SCREENING_API_KEY", ""),
+			BaseURL:   getEnv("SCREENING_BASE_URL", ""),
+			BlockRisk: getEnv("SCREENING_BLOCK_RISK", screening.RiskHigh),
+			CacheTTL:  screeningCacheTTL,
+		},
+		ForkSimulation: forksim.Config{
+			Provider:        getEnv("FORK_SIM_PROVIDER", ""),
+			RPCURLs:         getEnvChainURLs("FORK_SIM_RPC_URLS"),
+			Threshold:       getEnv("FORK_SIM_THRESHOLD", ""),
+			TenderlyAccount: getEnv("TENDERLY_ACCOUNT", ""),
+			TenderlyProject: getEnv("TENDERLY_PROJECT", ""),
+			TenderlyKey:     getEnv("TENDERLY_ACCESS_KEY", ""),
+			TenderlyURL:     getEnv("TENDERLY_BASE_URL", ""),
+		},
+		RecipientActivity: activity.Config{
+			Enabled:      getEnv("RECIPIENT_ACTIVITY_CHECK", "false") == "true",
+			Threshold:    getEnv("RECIPIENT_ACTIVITY_THRESHOLD", ""),
+			MinAge:       recipientMinAge,
+			DormantAfter: recipientDormantAfter,
+			ExplorerURLs: getEnvChainURLs("RECIPIENT_ACTIVITY_EXPLORER_URLS"),
+			ExplorerKey:  getEnv("RECIPIENT_ACTIVITY_EXPLORER_KEY", ""),
+		},
+		Shadow: ShadowConfig{
+			Enabled:  getEnv("SHADOW_MODE", "false") == "true",
+			ScaleBps: shadowScale,
+		},
+		Swap: swap.Config{
+			Provider:       getEnv("SWAP_PROVIDER", ""),
+			APIKey:         getEnv("SWAP_API_KEY", ""),
+			BaseURL:        getEnv("SWAP_BASE_URL", ""),
+			MaxSlippageBps: swapMaxSlippage,
+		},
+		Approval: approval.Config{
+			ThresholdUSD: getEnv("APPROVAL_THRESHOLD_USD", ""),
+			Quorum:       approvalQuorum,
+			Operators:    getEnvList("APPROVAL_OPERATORS"),
+			PricesFile:   getEnv("APPROVAL_PRICES_FILE", ""),
+		},
+		KMSAudit: KMSAuditConfig{
+			Sinks:         getEnvList("KMS_AUDIT_SINKS"),
+			WebhookURL:    getEnv("KMS_AUDIT_WEBHOOK_URL", ""),
+			WebhookSecret: getEnv("KMS_AUDIT_WEBHOOK_SECRET", ""),
+			Required:      getEnv("KMS_AUDIT_REQUIRED", "false") == "true",
+		},
+		KMS: kms.Config{
+			Provider:   getEnv("KMS_PROVIDER", kms.ProviderLocal),
+			PrivateKey: getEnv("PAYOUT_PRIVATE_KEY", ""),
+			Fireblocks: kms.FireblocksConfig{
+				APIKey:         getEnv("FIREBLOCKS_API_KEY", ""),
+				SecretKey:      fireblocksSecret,
+				BaseURL:        getEnv("FIREBLOCKS_BASE_URL", "https://api.fireblocks.io"),
+				VaultAccountID: getEnv("FIREBLOCKS_VAULT_ACCOUNT_ID", ""),
+				AssetID:        getEnv("FIREBLOCKS_ASSET_ID", "ETH"),
+				Address:        getEnv("FIREBLOCKS_ADDRESS", ""),
+				SignTimeout:    fireblocksTimeout,
+			},
+			MPC: kms.MPCConfig{
+				CoSigners:   getEnvList("MPC_COSIGNER_URLS"),
+				KeyID:       getEnv("MPC_KEY_ID", ""),
+				Quorum:      mpcQuorum,
+				AuthToken:   getEnv("MPC_AUTH_TOKEN", ""),
+				Address:     getEnv("MPC_ADDRESS", ""),
+				SignTimeout: mpcTimeout,
+			},
+			Batch: kms.BatchConfig{
+				Concurrency:    kmsConcurrency,
+				MaxBatchSize:   kmsBatchSize,
+				CoalesceWindow: kmsCoalesceWindow,
+			},
+		},
 		Database: DatabaseConfig{
-			URL: getEnv("DATABASE_URL", ""),
+			URL:                 getEnv("DATABASE_URL", ""),
+			LedgerFlushInterval: ledgerInterval,
 		},
 		Redis: RedisConfig{
 			URL:        getEnv("REDIS_URL", "localhost:6379"),
@@ -71,78 +788,625 @@ func Load() (*Config, error) {
 			DB:         redisDB,
 			TLSEnabled: getEnv("REDIS_TLS_ENABLED", "false") == "true",
 		},
-		Chains: map[uint64]ChainConfig{
-			// ——— EVM Chains ———
-			1: {
-				ChainID:     1,
-				Name:        "Ethereum",
-				RPCURL:      getEnv("ETH_RPC_URL", "https://eth.llamarpc.com"),
-				ExplorerURL: "https://etherscan.io",
-				NativeToken: "ETH",
-				Decimals:    18,
-				Type:        "evm",
-			},
-			137: {
-				ChainID:     137,
-				Name:        "Polygon",
-				RPCURL:      getEnv("POLYGON_RPC_URL", "https://polygon-rpc.com"),
-				ExplorerURL: "https://polygonscan.com",
-				NativeToken: "MATIC",
-				Decimals:    18,
-				Type:        "evm",
-			},
-			42161: {
-				ChainID:     42161,
-				Name:        "Arbitrum",
-				RPCURL:      getEnv("ARBITRUM_RPC_URL", "https://arb1.arbitrum.io/rpc"),
-				ExplorerURL: "https://arbiscan.io",
-				NativeToken: "ETH",
-				Decimals:    18,
-				Type:        "evm",
-			},
-			8453: {
-				ChainID:     8453,
-				Name:        "Base",
-				RPCURL:      getEnv("BASE_RPC_URL", "https://mainnet.base.org"),
-				ExplorerURL: "https://basescan.org",
-				NativeToken: "ETH",
-				Decimals:    18,
-				Type:        "evm",
-			},
-			10: {
-				ChainID:     10,
-				Name:        "Optimism",
-				RPCURL:      getEnv("OPTIMISM_RPC_URL", "https://mainnet.optimism.io"),
-				ExplorerURL: "https://optimistic.etherscan.io",
-				NativeToken: "ETH",
-				Decimals:    18,
-				Type:        "evm",
-			},
-			// ——— TRON Chains ———
-			728126428: {
-				ChainID:     728126428,
-				Name:        "TRON Mainnet",
-				RPCURL:      getEnv("TRON_RPC_URL", "grpc.trongrid.io:50051"),
-				ExplorerURL: "https://tronscan.org",
-				NativeToken: "TRX",
-				Decimals:    6,
-				Type:        "tron",
-			},
-			3448148188: {
-				ChainID:     3448148188,
-				Name:        "TRON Nile Testnet",
-				RPCURL:      getEnv("TRON_TESTNET_RPC_URL", "grpc.nile.trongrid.io:50051"),
-				ExplorerURL: "https://nile.tronscan.org",
-				NativeToken: "TRX",
-				Decimals:    6,
-				Type:        "tron",
-			},
+		RPC: rpcpool.Config{
+			ErrorBudget:         rpcErrorBudget,
+			ErrorWindow:         rpcErrorWindow,
+			Cooldown:            rpcCooldown,
+			ProbeInterval:       rpcProbeInterval,
+			ConsecutiveFailures: rpcConsecutiveFailures,
+			MaxBlockLag:         rpcMaxBlockLag,
+			RateLimit:           rpcpool.RateLimit{RPS: rpcRateLimit, Burst: rpcRateBurst},
 		},
 	}
 
+	for _, sink := range cfg.KMSAudit.Sinks {
+		switch sink {
+		case KMSAuditStdout:
+		case KMSAuditPostgres:
+			if cfg.Database.URL == "" {
+				return nil, fmt.Errorf("KMS_AUDIT_SINKS=%s requires DATABASE_URL", sink)
+			}
+		case KMSAuditWebhook:
+			if cfg.KMSAudit.WebhookURL == "" {
+				return nil, fmt.Errorf("KMS_AUDIT_SINKS=%s requires KMS_AUDIT_WEBHOOK_URL", sink)
+			}
+		default:
+			return nil, fmt.Errorf("unknown KMS_AUDIT_SINKS entry: %s (expected %s, %s or %s)", sink, KMSAuditStdout, KMSAuditPostgres, KMSAuditWebhook)
+		}
+	}
+
+	// 影子模式镜像主网批次，测试网模式下没有可镜像的批次
+	if cfg.Shadow.Enabled {
+		if cfg.IsTestnet() {
+			return nil, fmt.Errorf("SHADOW_MODE requires PAYOUT_NETWORK=mainnet")
+		}
+		if cfg.Shadow.ScaleBps < 1 || cfg.Shadow.ScaleBps > 10000 {
+			return nil, fmt.Errorf("SHADOW_SCALE_BPS must be between 1 and 10000")
+		}
+	}
+
+	if cfg.ChainIDMismatch != ChainIDMismatchExit && cfg.ChainIDMismatch != ChainIDMismatchDisable {
+		return nil, fmt.Errorf("CHAIN_ID_MISMATCH must be %s or %s", ChainIDMismatchExit, ChainIDMismatchDisable)
+	}
+
+	chains, err := LoadChains(cfg.ChainsFile, cfg.Network, cfg.Shadow.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Chains = chains
+	// 签名限速先于派生各链、旧版本和资金钱包的签名配置，每个密钥各自计数
+	if cfg.KMS.Guard, err = loadSigningGuard(); err != nil {
+		return nil, err
+	}
+	cfg.KMS.Version = getEnv("KMS_KEY_VERSION", "")
+	previous, err := loadPreviousKey(cfg.KMS, "")
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		cfg.KMS.Previous = []kms.PreviousKey{*previous}
+	}
+	if cfg.ChainSigners, err = loadChainSigners(cfg.KMS); err != nil {
+		return nil, err
+	}
+	cfg.GasTank.Funding = loadGasTankFunding(cfg.KMS)
+	tronKey := cfg.TronPrivateKey
+	if tronKey == "" {
+		tronKey = cfg.PrivateKey
+	}
+	cfg.TronKMS = tronSigner(cfg.KMS, tronKey)
+	cfg.GasTank.TronFunding = tronSigner(cfg.KMS, cfg.GasTank.TronFundingKey)
+
+	// gRPC API 以 API_SECRET 认证，非开发环境必须配置
+	if cfg.APISecret == "" && cfg.Environment != "development" {
+		return nil, fmt.Errorf("API_SECRET is required when ENVIRONMENT=%s", cfg.Environment)
+	}
+
+	if !eventschema.Supported(cfg.WebhookSchemaVersion) {
+		return nil, fmt.Errorf("unsupported WEBHOOK_SCHEMA_VERSION: %d (latest is %d)", cfg.WebhookSchemaVersion, eventschema.Latest)
+	}
+
 	return cfg, nil
 }
 
+// builtinChains 内置链定义 (节点地址等可通过环境变量覆盖)
+func builtinChains() map[uint64]ChainConfig {
+	return map[uint64]ChainConfig{
+		// ——— EVM Chains ———
+		1: {
+			ChainID:         1,
+			Name:            "Ethereum",
+			RPCURL:          getEnv("ETH_RPC_URL", "https://eth.llamarpc.com"),
+			RPCFallbackURLs: getEnvList("ETH_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("ETH"),
+			ExplorerURL:     "https://etherscan.io",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   12,
+			StuckTxTimeout:  3 * time.Minute,
+			GasBumpPercent:  15,
+			MaxReplacements: 5,
+			ReorgDepth:      64,
+			AA:              loadAAConfig("ETH"),
+			Forwarders:      getEnvList("ETH_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("ETH_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[1],
+			Shadow:          builtinShadow[1],
+			WrappedNative:   "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+			UnwrapNative:    getEnv("ETH_UNWRAP_NATIVE", "false") == "true",
+			PrivateTx:       loadPrivateTxConfig("ETH"),
+			GasTank:         loadGasTankChain("ETH"),
+			GasCeiling:      loadGasCeiling("ETH"),
+			Sweep:           loadSweepChain("ETH"),
+		},
+		137: {
+			ChainID:         137,
+			Name:            "Polygon",
+			RPCURL:          getEnv("POLYGON_RPC_URL", "https://polygon-rpc.com"),
+			RPCFallbackURLs: getEnvList("POLYGON_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("POLYGON"),
+			ExplorerURL:     "https://polygonscan.com",
+			NativeToken:     "MATIC",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   32,
+			StuckTxTimeout:  2 * time.Minute,
+			GasBumpPercent:  30,
+			MaxReplacements: 5,
+			ReorgDepth:      64,
+			AA:              loadAAConfig("POLYGON"),
+			Forwarders:      getEnvList("POLYGON_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("POLYGON_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[137],
+			WrappedNative:   "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270",
+			UnwrapNative:    getEnv("POLYGON_UNWRAP_NATIVE", "false") == "true",
+			PrivateTx:       loadPrivateTxConfig("POLYGON"),
+			GasTank:         loadGasTankChain("POLYGON"),
+			GasCeiling:      loadGasCeiling("POLYGON"),
+			Sweep:           loadSweepChain("POLYGON"),
+		},
+		42161: {
+			ChainID:         42161,
+			Name:            "Arbitrum",
+			RPCURL:          getEnv("ARBITRUM_RPC_URL", "https://arb1.arbitrum.io/rpc"),
+			RPCFallbackURLs: getEnvList("ARBITRUM_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("ARBITRUM"),
+			ExplorerURL:     "https://arbiscan.io",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   1,
+			StuckTxTimeout:  time.Minute,
+			GasBumpPercent:  20,
+			MaxReplacements: 5,
+			ReorgDepth:      20,
+			L1Fee:           "arbitrum",
+			AA:              loadAAConfig("ARBITRUM"),
+			Forwarders:      getEnvList("ARBITRUM_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("ARBITRUM_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[42161],
+			WrappedNative:   "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1",
+			UnwrapNative:    getEnv("ARBITRUM_UNWRAP_NATIVE", "false") == "true",
+			PrivateTx:       loadPrivateTxConfig("ARBITRUM"),
+			GasTank:         loadGasTankChain("ARBITRUM"),
+			GasCeiling:      loadGasCeiling("ARBITRUM"),
+			Sweep:           loadSweepChain("ARBITRUM"),
+		},
+		8453: {
+			ChainID:         8453,
+			Name:            "Base",
+			RPCURL:          getEnv("BASE_RPC_URL", "https://mainnet.base.org"),
+			RPCFallbackURLs: getEnvList("BASE_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("BASE"),
+			ExplorerURL:     "https://basescan.org",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   1,
+			StuckTxTimeout:  time.Minute,
+			GasBumpPercent:  20,
+			MaxReplacements: 5,
+			ReorgDepth:      20,
+			L1Fee:           "op",
+			AA:              loadAAConfig("BASE"),
+			Forwarders:      getEnvList("BASE_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("BASE_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[8453],
+			Shadow:          builtinShadow[8453],
+			WrappedNative:   "0x4200000000000000000000000000000000000006",
+			UnwrapNative:    getEnv("BASE_UNWRAP_NATIVE", "false") == "true",
+			PrivateTx:       loadPrivateTxConfig("BASE"),
+			GasTank:         loadGasTankChain("BASE"),
+			GasCeiling:      loadGasCeiling("BASE"),
+			Sweep:           loadSweepChain("BASE"),
+		},
+		10: {
+			ChainID:         10,
+			Name:            "Optimism",
+			RPCURL:          getEnv("OPTIMISM_RPC_URL", "https://mainnet.optimism.io"),
+			RPCFallbackURLs: getEnvList("OPTIMISM_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("OPTIMISM"),
+			ExplorerURL:     "https://optimistic.etherscan.io",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   1,
+			StuckTxTimeout:  time.Minute,
+			GasBumpPercent:  20,
+			MaxReplacements: 5,
+			ReorgDepth:      20,
+			L1Fee:           "op",
+			AA:              loadAAConfig("OPTIMISM"),
+			Forwarders:      getEnvList("OPTIMISM_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("OPTIMISM_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[10],
+			WrappedNative:   "0x4200000000000000000000000000000000000006",
+			UnwrapNative:    getEnv("OPTIMISM_UNWRAP_NATIVE", "false") == "true",
+			PrivateTx:       loadPrivateTxConfig("OPTIMISM"),
+			GasTank:         loadGasTankChain("OPTIMISM"),
+			GasCeiling:      loadGasCeiling("OPTIMISM"),
+			Sweep:           loadSweepChain("OPTIMISM"),
+		},
+		// ——— EVM Testnets ———
+		11155111: {
+			ChainID:         11155111,
+			Name:            "Sepolia",
+			RPCURL:          getEnv("SEPOLIA_RPC_URL", "https://ethereum-sepolia-rpc.publicnode.com"),
+			RPCFallbackURLs: getEnvList("SEPOLIA_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("SEPOLIA"),
+			ExplorerURL:     "https://sepolia.etherscan.io",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   3,
+			StuckTxTimeout:  2 * time.Minute,
+			GasBumpPercent:  20,
+			MaxReplacements: 10,
+			ReorgDepth:      12,
+			AA:              loadAAConfig("SEPOLIA"),
+			Forwarders:      getEnvList("SEPOLIA_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("SEPOLIA_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[11155111],
+			GasTank:         loadGasTankChain("SEPOLIA"),
+			GasCeiling:      loadGasCeiling("SEPOLIA"),
+			Sweep:           loadSweepChain("SEPOLIA"),
+			Testnet:         true,
+			Faucet:          loadFaucetConfig("SEPOLIA", "100000000000000000"), // 0.1 ETH
+		},
+		84532: {
+			ChainID:         84532,
+			Name:            "Base Sepolia",
+			RPCURL:          getEnv("BASE_SEPOLIA_RPC_URL", "https://sepolia.base.org"),
+			RPCFallbackURLs: getEnvList("BASE_SEPOLIA_RPC_FALLBACK_URLS"),
+			RPCRateLimits:   loadRPCRateLimits("BASE_SEPOLIA"),
+			ExplorerURL:     "https://sepolia.basescan.org",
+			NativeToken:     "ETH",
+			Decimals:        18,
+			Type:            "evm",
+			Confirmations:   1,
+			StuckTxTimeout:  time.Minute,
+			GasBumpPercent:  20,
+			MaxReplacements: 10,
+			ReorgDepth:      20,
+			L1Fee:           "op",
+			AA:              loadAAConfig("BASE_SEPOLIA"),
+			Forwarders:      getEnvList("BASE_SEPOLIA_TRUSTED_FORWARDERS"),
+			Treasury:        getEnv("BASE_SEPOLIA_TREASURY_ADDRESS", ""),
+			CCTP:            builtinCCTP[84532],
+			GasTank:         loadGasTankChain("BASE_SEPOLIA"),
+			GasCeiling:      loadGasCeiling("BASE_SEPOLIA"),
+			Sweep:           loadSweepChain("BASE_SEPOLIA"),
+			Testnet:         true,
+			Faucet:          loadFaucetConfig("BASE_SEPOLIA", "50000000000000000"), // 0.05 ETH
+		},
+		// ——— TRON Chains ———
+		728126428: {
+			ChainID:         728126428,
+			Name:            "TRON Mainnet",
+			RPCURL:          getEnv("TRON_RPC_URL", "grpc.trongrid.io:50051"),
+			RPCFallbackURLs: getEnvList("TRON_RPC_FALLBACK_URLS"),
+			ExplorerURL:     "https://tronscan.org",
+			NativeToken:     "TRX",
+			Decimals:        6,
+			Type:            "tron",
+			Confirmations:   19,
+			GasTank:         loadGasTankChain("TRON"),
+			Sweep:           loadSweepChain("TRON"),
+			Shadow:          builtinShadow[728126428],
+		},
+		3448148188: {
+			ChainID:         3448148188,
+			Name:            "TRON Nile Testnet",
+			RPCURL:          getEnv("TRON_TESTNET_RPC_URL", "grpc.nile.trongrid.io:50051"),
+			RPCFallbackURLs: getEnvList("TRON_TESTNET_RPC_FALLBACK_URLS"),
+			ExplorerURL:     "https://nile.tronscan.org",
+			NativeToken:     "TRX",
+			Decimals:        6,
+			Type:            "tron",
+			Confirmations:   19,
+			GasTank:         loadGasTankChain("TRON_NILE"),
+			Sweep:           loadSweepChain("TRON_NILE"),
+			Testnet:         true,
+			Faucet:          loadFaucetConfig("TRON_NILE", "1000000000"), // 1000 TRX
+		},
+	}
+}
+
+// RPCEndpoints 主节点和备用节点 (按配置顺序)
+func (c ChainConfig) RPCEndpoints() []string {
+	return append([]string{c.RPCURL}, c.RPCFallbackURLs...)
+}
+
+// loadFaucetConfig 读取测试网水龙头配置 (环境变量前缀如 SEPOLIA、TRON_NILE)
+func loadFaucetConfig(prefix, defaultMinBalance string) FaucetConfig {
+	cooldown, _ := time.ParseDuration(getEnv(prefix+"_FAUCET_COOLDOWN", "1h"))
+	return FaucetConfig{
+		URL:        getEnv(prefix+"_FAUCET_URL", ""),
+		APIKey:     getEnv(prefix+"_FAUCET_API_KEY", ""),
+		MinBalance: getEnv(prefix+"_FAUCET_MIN_BALANCE", defaultMinBalance),
+		Cooldown:   cooldown,
+	}
+}
+
+// loadGasTankChain 读取链的 gas 补充阈值 (环境变量前缀如 ETH、TRON)
+// loadGasCeiling 读取链的 base fee 上限 (<PREFIX>_MAX_BASE_FEE_GWEI、<PREFIX>_GAS_CEILING_WAIT)
+func loadGasCeiling(prefix string) ChainGasCeiling {
+	return ChainGasCeiling{
+		MaxBaseFeeGwei: getEnv(prefix+"_MAX_BASE_FEE_GWEI", ""),
+		Wait:           getEnv(prefix+"_GAS_CEILING_WAIT", "false") == "true",
+	}
+}
+
+func loadGasTankChain(prefix string) ChainGasTank {
+	return ChainGasTank{
+		MinBalance:    getEnv(prefix+"_GAS_TANK_MIN_BALANCE", ""),
+		TargetBalance: getEnv(prefix+"_GAS_TANK_TARGET_BALANCE", ""),
+		Addresses:     getEnvList(prefix + "_GAS_TANK_ADDRESSES"),
+	}
+}
+
+// loadSweepChain 读取链的归集配置 (环境变量前缀如 ETH、TRON)。
+// <PREFIX>_SWEEP_CEILINGS 为 "资产=上限[:保留余额]" 的逗号分隔列表，如 "native=5000000000000000000:2000000000000000000"
+func loadSweepChain(prefix string) ChainSweep {
+	ceilings := make(map[string]SweepCeiling)
+	for _, entry := range getEnvList(prefix + "_SWEEP_CEILINGS") {
+		asset, limits, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		ceiling, target, _ := strings.Cut(strings.TrimSpace(limits), ":")
+		ceilings[strings.TrimSpace(asset)] = SweepCeiling{Ceiling: ceiling, Target: target}
+	}
+	return ChainSweep{
+		ColdAddress: getEnv(prefix+"_SWEEP_COLD_ADDRESS", ""),
+		Ceilings:    ceilings,
+	}
+}
+
+// loadRPCRateLimits 读取链的按节点限速 (环境变量前缀如 ETH、BASE)，
+// 格式为 url=rps[:burst]，多个节点以逗号分隔
+func loadRPCRateLimits(prefix string) map[string]rpcpool.RateLimit {
+	limits := make(map[string]rpcpool.RateLimit)
+	for _, entry := range getEnvList(prefix + "_RPC_RATE_LIMITS") {
+		// URL 的查询参数可能包含 '='，以最后一个为准
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			continue
+		}
+		rps, burst, _ := strings.Cut(strings.TrimSpace(entry[i+1:]), ":")
+		limit := rpcpool.RateLimit{}
+		limit.RPS, _ = strconv.ParseFloat(rps, 64)
+		limit.Burst, _ = strconv.Atoi(burst)
+		limits[strings.TrimSpace(entry[:i])] = limit
+	}
+	return limits
+}
+
+// loadPrivateTxConfig 读取链的私有交易池配置 (环境变量前缀如 ETH、BASE)
+func loadPrivateTxConfig(prefix string) PrivateTxConfig {
+	deadline, _ := time.ParseDuration(getEnv(prefix+"_PRIVATE_TX_DEADLINE", "3m"))
+	return PrivateTxConfig{
+		RPCURL:    getEnv(prefix+"_PRIVATE_RPC_URL", ""),
+		Method:    getEnv(prefix+"_PRIVATE_RPC_METHOD", ""),
+		MinAmount: getEnv(prefix+"_PRIVATE_TX_MIN_AMOUNT", ""),
+		Deadline:  deadline,
+	}
+}
+
+// loadAAConfig 读取链的 ERC-4337 配置 (环境变量前缀如 ETH、BASE)
+func loadAAConfig(prefix string) AAConfig {
+	return AAConfig{
+		EntryPoint:        getEnv(prefix+"_ENTRYPOINT", ""),
+		BundlerURL:        getEnv(prefix+"_BUNDLER_URL", ""),
+		PaymasterURL:      getEnv(prefix+"_PAYMASTER_URL", ""),
+		PaymasterPolicyID: getEnv(prefix+"_PAYMASTER_POLICY_ID", ""),
+		SmartAccount:      getEnv(prefix+"_SMART_ACCOUNT", ""),
+	}
+}
+
+// chainSignerEnv 按链覆盖签名器的环境变量，后缀为链 ID (如 PAYOUT_PRIVATE_KEY_137)
+var chainSignerEnv = []string{
+	"KMS_PROVIDER",
+	"PAYOUT_PRIVATE_KEY",
+	"FIREBLOCKS_VAULT_ACCOUNT_ID",
+	"FIREBLOCKS_ASSET_ID",
+	"FIREBLOCKS_ADDRESS",
+	"MPC_KEY_ID",
+	"MPC_ADDRESS",
+	"KMS_KEY_VERSION",
+	"PAYOUT_PREVIOUS_PRIVATE_KEY",
+	"FIREBLOCKS_PREVIOUS_VAULT_ACCOUNT_ID",
+	"MPC_PREVIOUS_KEY_ID",
+}
+
+// loadChainSigners 读取按链覆盖的签名器配置，未覆盖的字段沿用默认签名器。
+// 只配置私钥的链使用本地签名，只配置 Fireblocks 金库的链使用 Fireblocks，只配置 MPC 密钥的链使用 MPC (共签方沿用默认配置)。
+// 覆盖了密钥的链不沿用默认签名器的旧密钥，轮换时按链配置 (如 PAYOUT_PREVIOUS_PRIVATE_KEY_137)。
+func loadChainSigners(base kms.Config) (map[uint64]kms.Config, error) {
+	chainIDs := make(map[uint64]bool)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if value == "" {
+			continue
+		}
+		for _, name := range chainSignerEnv {
+			if suffix, ok := strings.CutPrefix(key, name+"_"); ok {
+				if chainID, err := strconv.ParseUint(suffix, 10, 64); err == nil && chainID > 0 {
+					chainIDs[chainID] = true
+				}
+			}
+		}
+	}
+
+	signers := make(map[uint64]kms.Config, len(chainIDs))
+	for chainID := range chainIDs {
+		suffix := "_" + strconv.FormatUint(chainID, 10)
+		cfg := base
+		privateKey := getEnv("PAYOUT_PRIVATE_KEY"+suffix, "")
+		vault := getEnv("FIREBLOCKS_VAULT_ACCOUNT_ID"+suffix, "")
+		keyID := getEnv("MPC_KEY_ID"+suffix, "")
+		if privateKey != "" || vault != "" || keyID != "" {
+			cfg.Previous = nil
+		}
+		if privateKey != "" {
+			cfg.Provider = kms.ProviderLocal
+			cfg.PrivateKey = privateKey
+		}
+		if vault != "" {
+			cfg.Provider = kms.ProviderFireblocks
+			cfg.Fireblocks.VaultAccountID = vault
+			cfg.Fireblocks.Address = "" // 其他金库的地址需重新解析
+		}
+		if keyID != "" {
+			cfg.Provider = kms.ProviderMPC
+			cfg.MPC.KeyID = keyID
+			cfg.MPC.Address = "" // 其他密钥的地址需重新解析
+		}
+		cfg.Provider = getEnv("KMS_PROVIDER"+suffix, cfg.Provider)
+		cfg.Fireblocks.AssetID = getEnv("FIREBLOCKS_ASSET_ID"+suffix, cfg.Fireblocks.AssetID)
+		cfg.Fireblocks.Address = getEnv("FIREBLOCKS_ADDRESS"+suffix, cfg.Fireblocks.Address)
+		cfg.MPC.Address = getEnv("MPC_ADDRESS"+suffix, cfg.MPC.Address)
+		cfg.Version = getEnv("KMS_KEY_VERSION"+suffix, cfg.Version)
+		previous, err := loadPreviousKey(cfg, suffix)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			cfg.Previous = []kms.PreviousKey{*previous}
+		}
+		signers[chainID] = cfg
+	}
+	return signers, nil
+}
+
+// loadPreviousKey 读取轮换前的旧密钥 (suffix 为空时为默认签名器，否则为 "_<链 ID>")，未配置时返回 nil。
+// 新交易使用当前密钥；旧密钥在 KMS_PREVIOUS_RETIRE_AT (RFC 3339，为空时不过期) 之前仍为自己的地址签名，
+// 用于替换在途交易和将余额转到新地址。
+func loadPreviousKey(base kms.Config, suffix string) (*kms.PreviousKey, error) {
+	prev := &kms.PreviousKey{Config: base}
+	prev.Previous = nil
+	prev.Version = getEnv("KMS_PREVIOUS_KEY_VERSION"+suffix, "")
+	privateKey := getEnv("PAYOUT_PREVIOUS_PRIVATE_KEY"+suffix, "")
+	vault := getEnv("FIREBLOCKS_PREVIOUS_VAULT_ACCOUNT_ID"+suffix, "")
+	keyID := getEnv("MPC_PREVIOUS_KEY_ID"+suffix, "")
+	switch {
+	case privateKey != "":
+		prev.Provider = kms.ProviderLocal
+		prev.PrivateKey = privateKey
+	case vault != "":
+		prev.Provider = kms.ProviderFireblocks
+		prev.Fireblocks.VaultAccountID = vault
+		prev.Fireblocks.Address = getEnv("FIREBLOCKS_PREVIOUS_ADDRESS"+suffix, "")
+	case keyID != "":
+		prev.Provider = kms.ProviderMPC
+		prev.MPC.KeyID = keyID
+		prev.MPC.Address = getEnv("MPC_PREVIOUS_ADDRESS"+suffix, "")
+	default:
+		return nil, nil
+	}
+	if at := getEnv("KMS_PREVIOUS_RETIRE_AT"+suffix, ""); at != "" {
+		retireAt, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KMS_PREVIOUS_RETIRE_AT%s: %w", suffix, err)
+		}
+		prev.RetireAt = retireAt
+	}
+	return prev, nil
+}
+
+// loadSigningGuard 读取签名限速和异常检测规则: SIGNING_MAX_PER_MINUTE 为每个密钥每分钟的签名上限，
+// SIGNING_SPIKE_FACTOR 为相对前一小时平均速率的突增倍数，SIGNING_BUSINESS_HOURS (如 09:00-18:00) 之外签名视为异常。
+// 异常按 SIGNING_GUARD_ACTION 告警 (alert，默认) 或拒绝签名 (block)，超过上限总是拒绝。
+func loadSigningGuard() (kms.GuardConfig, error) {
+	maxPerMinute, _ := strconv.Atoi(getEnv("SIGNING_MAX_PER_MINUTE", "0"))
+	spikeFactor, _ := strconv.ParseFloat(getEnv("SIGNING_SPIKE_FACTOR", "0"), 64)
+	spikeMinimum, _ := strconv.Atoi(getEnv("SIGNING_SPIKE_MIN", "0"))
+	guard := kms.GuardConfig{
+		MaxPerMinute: maxPerMinute,
+		SpikeFactor:  spikeFactor,
+		SpikeMinimum: spikeMinimum,
+		Action:       getEnv("SIGNING_GUARD_ACTION", kms.GuardActionAlert),
+	}
+	if guard.Action != kms.GuardActionAlert && guard.Action != kms.GuardActionBlock {
+		return guard, fmt.Errorf("SIGNING_GUARD_ACTION must be %s or %s", kms.GuardActionAlert, kms.GuardActionBlock)
+	}
+	if window := getEnv("SIGNING_BUSINESS_HOURS", ""); window != "" {
+		hours, err := kms.ParseBusinessHours(window, getEnv("SIGNING_BUSINESS_DAYS", "mon-fri"), getEnv("SIGNING_TIMEZONE", "UTC"))
+		if err != nil {
+			return guard, fmt.Errorf("SIGNING_BUSINESS_HOURS: %w", err)
+		}
+		guard.BusinessHours = hours
+	}
+	return guard, nil
+}
+
+// SetSigningAnomalyHandler 为所有签名配置设置签名异常回调
+func (c *Config) SetSigningAnomalyHandler(fn func(kms.Anomaly)) {
+	c.eachSigner(func(k *kms.Config) { k.Guard.OnAnomaly = fn })
+}
+
+// SetSigningAudit 为所有签名配置设置签名调用审计
+func (c *Config) SetSigningAudit(audit kms.AuditConfig) {
+	c.eachSigner(func(k *kms.Config) { k.Audit = audit })
+}
+
+// eachSigner 依次修改默认、各链、旧版本密钥、资金钱包和 TRON 的签名配置
+func (c *Config) eachSigner(fn func(*kms.Config)) {
+	set := func(k *kms.Config) {
+		fn(k)
+		for i := range k.Previous {
+			fn(&k.Previous[i].Config)
+		}
+	}
+	set(&c.KMS)
+	for chainID, signerCfg := range c.ChainSigners {
+		set(&signerCfg)
+		c.ChainSigners[chainID] = signerCfg
+	}
+	set(&c.GasTank.Funding)
+	set(&c.TronKMS)
+	set(&c.GasTank.TronFunding)
+}
+
+// loadGasTankFunding 读取 EVM 资金钱包签名器: GAS_TANK_FUNDING_PRIVATE_KEY 使用本地签名，
+// GAS_TANK_FUNDING_VAULT_ACCOUNT_ID 使用 Fireblocks (API 凭据沿用默认签名器)。都未配置时 Provider 为空。
+func loadGasTankFunding(base kms.Config) kms.Config {
+	cfg := base
+	cfg.Provider = ""
+	cfg.Version, cfg.Previous = "", nil
+	cfg.PrivateKey = getEnv("GAS_TANK_FUNDING_PRIVATE_KEY", "")
+	cfg.Fireblocks.VaultAccountID = getEnv("GAS_TANK_FUNDING_VAULT_ACCOUNT_ID", "")
+	cfg.Fireblocks.Address = getEnv("GAS_TANK_FUNDING_ADDRESS", "")
+	switch {
+	case cfg.Fireblocks.VaultAccountID != "":
+		cfg.Provider = kms.ProviderFireblocks
+	case cfg.PrivateKey != "":
+		cfg.Provider = kms.ProviderLocal
+	}
+	return cfg
+}
+
+// tronSigner TRON 私钥的本地签名配置，沿用默认签名器的限速和批量配置 (每个密钥各自计数)。私钥为空时 Provider 为空。
+func tronSigner(base kms.Config, privateKey string) kms.Config {
+	cfg := base
+	cfg.Provider = ""
+	cfg.Version, cfg.Previous = "", nil
+	cfg.PrivateKey = privateKey
+	if privateKey != "" {
+		cfg.Provider = kms.ProviderLocal
+	}
+	return cfg
+}
+
+// getEnvList 读取逗号分隔的列表，去掉空项
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// getEnvChainURLs 读取 "链ID=URL" 的逗号分隔列表，如 "1=http://anvil-eth:8545,8453=http://anvil-base:8545"
+func getEnvChainURLs(key string) map[uint64]string {
+	urls := make(map[uint64]string)
+	for _, entry := range getEnvList(key) {
+		id, url, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		chainID, err := strconv.ParseUint(strings.TrimSpace(id), 10, 64)
+		if err != nil || chainID == 0 {
+			continue
+		}
+		urls[chainID] = strings.TrimSpace(url)
+	}
+	return urls
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,114 @@
+// Package correlation 跨服务关联 ID。
+//
+// 调用方 (SDK) 在发起请求时生成 ID，经 HTTP 请求头 / gRPC metadata 传入，随队列任务、任务状态、
+// 交易日志和 webhook 事件一路传递；按该 ID 检索日志或链路即可还原一笔支付在各服务中的完整过程。
+// 请求未携带或携带的 ID 不合法时由入口生成新的 ID，并在响应中回显。
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	Header      = "X-Correlation-ID" // HTTP 请求/响应头、出站 webhook 请求头
+	MetadataKey = "x-correlation-id" // gRPC metadata
+	LogField    = "correlation_id"   // 日志字段
+
+	maxLength = 128
+)
+
+type ctxKey struct{}
+
+// New 生成新的关联 ID (32 位十六进制)
+func New() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid ID 不超过 128 个字符，只含字母、数字和 - _ . :
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithID 返回携带关联 ID 的 context
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 返回 context 中的关联 ID (没有时为空)
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// orNew 沿用合法的传入 ID，否则生成新的
+func orNew(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// Middleware HTTP 入口: 读取或生成关联 ID，放入请求 context 并在响应头回显
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := orNew(r.Header.Get(Header))
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+	})
+}
+
+// fromIncoming 读取 gRPC 请求 metadata 中的关联 ID
+func fromIncoming(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(MetadataKey); len(values) > 0 {
+		return orNew(values[0])
+	}
+	return New()
+}
+
+// UnaryServerInterceptor gRPC 入口: 读取或生成关联 ID，放入 context 并在响应 header 回显
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := fromIncoming(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, id))
+		return handler(WithID(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor 流式 gRPC 入口，同 UnaryServerInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := fromIncoming(ss.Context())
+		ss.SetHeader(metadata.Pairs(MetadataKey, id))
+		return handler(srv, &serverStream{ServerStream: ss, ctx: WithID(ss.Context(), id)})
+	}
+}
+
+// serverStream 替换流的 context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
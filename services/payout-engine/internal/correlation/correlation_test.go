@@ -0,0 +1,64 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("2f1c9a7e-4b1d-4c7a-9f3e-8d2b6a1c0e5f"))
+	assert.True(t, Valid("sdk:pay_123.attempt-1"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("has space"))
+	assert.False(t, Valid("line\nbreak"))
+	assert.False(t, Valid(strings.Repeat("a", 129)))
+	assert.Len(t, New(), 32)
+	assert.NotEqual(t, New(), New())
+}
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	// 沿用调用方的 ID
+	req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	req.Header.Set(Header, "sdk-req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "sdk-req-1", seen)
+	assert.Equal(t, "sdk-req-1", rec.Header().Get(Header))
+
+	// 未携带或不合法时生成
+	req = httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	req.Header.Set(Header, "bad id")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rec.Header().Get(Header))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return FromContext(ctx), nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "sdk-req-2"))
+	got, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/payout.PayoutService/SubmitBatchPayout"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "sdk-req-2", got)
+
+	got, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Len(t, got, 32)
+}
@@ -0,0 +1,94 @@
+// Package eventschema holds the versioned JSON schemas for outbound payout
+// events (job.*, batch.*) and validates payloads against them before they
+// are emitted. Schemas live in schemas/v<N>/<kind>.json, where kind is the
+// event type prefix.
+package eventschema
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema 版本
+const (
+	V1 = 1 // 扁平结构: user_id / batch_id / job / batch 与 id、type 同级
+	V2 = 2 // 信封结构: {id, type, schema_version, created_at, data}
+
+	Latest = V2
+)
+
+//go:embed schemas
+var schemaFS embed.FS
+
+// Supported 是否为已发布的 schema 版本
+func Supported(version int) bool {
+	return version >= V1 && version <= Latest
+}
+
+// Registry 已加载的事件 schema，按 "v<版本>/<kind>" 索引
+type Registry struct {
+	schemas map[string]*Schema
+}
+
+// NewRegistry 加载内置 schema
+func NewRegistry() (*Registry, error) {
+	r := &Registry{schemas: make(map[string]*Schema)}
+	for version := V1; version <= Latest; version++ {
+		dir := "schemas/v" + strconv.Itoa(version)
+		entries, err := schemaFS.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("schema v%d: %w", version, err)
+		}
+		for _, entry := range entries {
+			data, err := schemaFS.ReadFile(path.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			schema, err := Compile(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", dir, entry.Name(), err)
+			}
+			r.schemas[key(version, strings.TrimSuffix(entry.Name(), ".json"))] = schema
+		}
+	}
+	return r, nil
+}
+
+func key(version int, kind string) string {
+	return "v" + strconv.Itoa(version) + "/" + kind
+}
+
+// kind 事件类型前缀: job.sent -> job
+func kind(eventType string) string {
+	k, _, _ := strings.Cut(eventType, ".")
+	return k
+}
+
+// Kinds 某版本下定义的事件种类
+func (r *Registry) Kinds(version int) []string {
+	prefix := key(version, "")
+	var kinds []string
+	for k := range r.schemas {
+		if strings.HasPrefix(k, prefix) {
+			kinds = append(kinds, strings.TrimPrefix(k, prefix))
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Validate 校验事件 payload 是否符合其类型在该版本下的 schema
+func (r *Registry) Validate(eventType string, version int, payload []byte) error {
+	schema, ok := r.schemas[key(version, kind(eventType))]
+	if !ok {
+		return fmt.Errorf("no schema v%d for event type %q", version, eventType)
+	}
+	if err := schema.Validate(payload); err != nil {
+		return fmt.Errorf("%s payload does not match schema v%d: %w", eventType, version, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package eventschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+	for version := V1; version <= Latest; version++ {
+		assert.Equal(t, []string{"batch", "job"}, r.Kinds(version), "v%d", version)
+	}
+
+	v2 := `{"id":"evt_0a1b","type":"batch.completed","schema_version":2,"created_at":"2026-10-18T08:00:00.123Z",
+		"data":{"user_id":"user-1","batch_id":"batch-1","batch":{"total":2,"sent":1,"failed":1,"cancelled":0}}}`
+	assert.NoError(t, r.Validate("batch.completed", V2, []byte(v2)))
+	assert.ErrorContains(t, r.Validate("batch.completed", V1, []byte(v2)), "missing required property")
+	assert.ErrorContains(t, r.Validate("payout.settled", V2, []byte(v2)), "no schema v2")
+	assert.False(t, Supported(0))
+	assert.False(t, Supported(Latest+1))
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["state", "count"],
+		"properties": {
+			"state": {"enum": ["sent", "failed"]},
+			"count": {"type": "integer", "minimum": 1},
+			"version": {"const": 2},
+			"amount": {"type": "string", "pattern": "^[0-9]+$"},
+			"at": {"type": "string", "format": "date-time"},
+			"tags": {"type": "array", "items": {"type": "string", "minLength": 1}},
+			"note": {"type": ["string", "null"]}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate([]byte(`{"state":"sent","count":3,"version":2.0,"amount":"100","at":"2026-10-18T08:00:00Z","tags":["a"],"note":null,"extra":true}`)))
+
+	cases := map[string]string{
+		`[]`:                                          "expected object",
+		`{"state":"sent"}`:                            `missing required property "count"`,
+		`{"state":"queued","count":1}`:                "not one of the allowed values",
+		`{"state":"sent","count":1.5}`:                "expected integer",
+		`{"state":"sent","count":0}`:                  "less than 1",
+		`{"state":"sent","count":1,"version":"2"}`:    "must be 2",
+		`{"state":"sent","count":1,"amount":"1e3"}`:   "does not match",
+		`{"state":"sent","count":1,"at":"yesterday"}`: "not an RFC 3339 date-time",
+		`{"state":"sent","count":1,"tags":[""]}`:      "$.tags[0]: shorter than 1",
+		`{"state":"sent","count":1,"note":5}`:         "expected string or null",
+	}
+	for payload, want := range cases {
+		assert.ErrorContains(t, schema.Validate([]byte(payload)), want, payload)
+	}
+
+	_, err = Compile([]byte(`{"type":"object","additionalProperties":false}`))
+	assert.ErrorContains(t, err, "unsupported keyword")
+	_, err = Compile([]byte(`{"type":"string","format":"email"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,242 @@
+package eventschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// keywords 支持的 JSON Schema 关键字。出现其他关键字时编译失败，避免规则被静默忽略。
+var keywords = map[string]bool{
+	"$schema": true, "$id": true, "title": true, "description": true,
+	"type": true, "properties": true, "required": true, "items": true,
+	"enum": true, "const": true, "minLength": true, "minimum": true,
+	"pattern": true, "format": true,
+}
+
+// Schema JSON Schema (draft 2020-12) 的子集: 足以描述出站事件的字段、类型和取值
+type Schema struct {
+	types      []string
+	properties map[string]*Schema
+	required   []string
+	items      *Schema
+	enum       []json.RawMessage
+	constant   json.RawMessage
+	minLength  *int
+	minimum    *big.Rat
+	pattern    *regexp.Regexp
+	format     string
+}
+
+// Compile 解析 schema 文档
+func Compile(data []byte) (*Schema, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	s := &Schema{}
+	for name, value := range raw {
+		if !keywords[name] {
+			return nil, fmt.Errorf("unsupported keyword %q", name)
+		}
+		var err error
+		switch name {
+		case "type":
+			var single string
+			if err = json.Unmarshal(value, &single); err == nil {
+				s.types = []string{single}
+			} else {
+				err = json.Unmarshal(value, &s.types)
+			}
+		case "properties":
+			var props map[string]json.RawMessage
+			if err = json.Unmarshal(value, &props); err == nil {
+				s.properties = make(map[string]*Schema, len(props))
+				for prop, sub := range props {
+					if s.properties[prop], err = Compile(sub); err != nil {
+						return nil, fmt.Errorf("properties.%s: %w", prop, err)
+					}
+				}
+			}
+		case "required":
+			err = json.Unmarshal(value, &s.required)
+		case "items":
+			if s.items, err = Compile(value); err != nil {
+				return nil, fmt.Errorf("items: %w", err)
+			}
+		case "enum":
+			var values []json.RawMessage
+			if err = json.Unmarshal(value, &values); err == nil {
+				for _, v := range values {
+					s.enum = append(s.enum, canonical(v))
+				}
+			}
+		case "const":
+			s.constant = canonical(value)
+		case "minLength":
+			err = json.Unmarshal(value, &s.minLength)
+		case "minimum":
+			var n json.Number
+			if err = json.Unmarshal(value, &n); err == nil {
+				s.minimum, err = parseNumber(n)
+			}
+		case "pattern":
+			var expr string
+			if err = json.Unmarshal(value, &expr); err == nil {
+				s.pattern, err = regexp.Compile(expr)
+			}
+		case "format":
+			if err = json.Unmarshal(value, &s.format); err == nil && s.format != "date-time" {
+				err = fmt.Errorf("unsupported format %q", s.format)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// Validate 校验 JSON 文档
+func (s *Schema) Validate(payload []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return s.validate(v, "$")
+}
+
+func (s *Schema) validate(v interface{}, at string) error {
+	if len(s.types) > 0 && !s.matchesType(v) {
+		return fmt.Errorf("%s: expected %s, got %s", at, strings.Join(s.types, " or "), typeOf(v))
+	}
+	if s.constant != nil || len(s.enum) > 0 {
+		got, _ := json.Marshal(v)
+		got = canonical(got)
+		if s.constant != nil && !bytes.Equal(got, s.constant) {
+			return fmt.Errorf("%s: must be %s, got %s", at, s.constant, got)
+		}
+		if len(s.enum) > 0 && !containsRaw(s.enum, got) {
+			return fmt.Errorf("%s: %s is not one of the allowed values", at, got)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+		names := make([]string, 0, len(s.properties))
+		for name := range s.properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := v[name]; ok {
+				if err := s.properties[name].validate(value, at+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.items != nil {
+			for i, item := range v {
+				if err := s.items.validate(item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if s.minLength != nil && len([]rune(v)) < *s.minLength {
+			return fmt.Errorf("%s: shorter than %d characters", at, *s.minLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: %q does not match %s", at, v, s.pattern)
+		}
+		if s.format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return fmt.Errorf("%s: %q is not an RFC 3339 date-time", at, v)
+			}
+		}
+	case json.Number:
+		if s.minimum != nil {
+			n, err := parseNumber(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+			if n.Cmp(s.minimum) < 0 {
+				return fmt.Errorf("%s: %s is less than %s", at, v, s.minimum.RatString())
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) matchesType(v interface{}) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if n, err := parseNumber(v); err == nil && n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func parseNumber(n json.Number) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return nil, errors.New("invalid number " + n.String())
+	}
+	return r, nil
+}
+
+// canonical 规范化 JSON 标量以便比较 (数字 1 与 1.0 视为相同)
+func canonical(raw []byte) json.RawMessage {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && (raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9')) {
+		if r, err := parseNumber(json.Number(raw)); err == nil {
+			return json.RawMessage(r.RatString())
+		}
+	}
+	return json.RawMessage(raw)
+}
+
+func containsRaw(values []json.RawMessage, v json.RawMessage) bool {
+	for _, candidate := range values {
+		if bytes.Equal(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
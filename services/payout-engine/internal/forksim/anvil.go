@@ -0,0 +1,83 @@
+package forksim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Anvil simulates transactions with eth_call against per-chain fork nodes
+// (anvil --fork-url, or any node serving a fork of the chain).
+type Anvil struct {
+	clients map[uint64]*ethclient.Client
+}
+
+// NewAnvil creates an Anvil simulator. HTTP endpoints connect on first use.
+func NewAnvil(rpcURLs map[uint64]string) (*Anvil, error) {
+	a := &Anvil{clients: make(map[uint64]*ethclient.Client, len(rpcURLs))}
+	for chainID, url := range rpcURLs {
+		client, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, fmt.Errorf("fork rpc for chain %d: %w", chainID, err)
+		}
+		a.clients[chainID] = client
+	}
+	return a, nil
+}
+
+// Provider implements Simulator.
+func (a *Anvil) Provider() string { return ProviderAnvil }
+
+// Simulate implements Simulator.
+func (a *Anvil) Simulate(ctx context.Context, chainID uint64, from common.Address, tx *types.Transaction) (*Result, error) {
+	client, ok := a.clients[chainID]
+	if !ok {
+		return nil, &UnsupportedChainError{ChainID: chainID}
+	}
+	msg := ethereum.CallMsg{
+		From:      from,
+		To:        tx.To(),
+		Gas:       tx.Gas(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	if _, err := client.CallContract(ctx, msg, nil); err != nil {
+		if reason, ok := revertReason(err); ok {
+			return &Result{Reverted: true, RevertReason: reason}, nil
+		}
+		return nil, fmt.Errorf("anvil fork simulation failed: %w", err)
+	}
+	return &Result{}, nil
+}
+
+// revertReason extracts the revert reason from an eth_call error.
+// ok is false when the error is not an execution revert (e.g. the fork is unreachable).
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if reason, unpackErr := abi.UnpackRevert(common.FromHex(data)); unpackErr == nil {
+				return reason, true
+			}
+		}
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "revert") {
+		return "", false
+	}
+	// "execution reverted: <reason>"
+	if _, reason, found := strings.Cut(msg, "reverted: "); found {
+		return reason, true
+	}
+	return "", true
+}
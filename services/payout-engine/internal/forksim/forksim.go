@@ -0,0 +1,92 @@
+package forksim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Supported fork simulation providers
+const (
+	ProviderAnvil    = "anvil"
+	ProviderTenderly = "tenderly"
+)
+
+// Code is the error code recorded on jobs aborted by a reverted simulation.
+const Code = "SIMULATION_REVERTED"
+
+// Result is the outcome of simulating one transaction.
+type Result struct {
+	Reverted     bool   `json:"reverted"`
+	RevertReason string `json:"revert_reason,omitempty"`
+	GasUsed      uint64 `json:"gas_used,omitempty"` // 0 when the provider does not report it
+}
+
+// Simulator executes a built, unsigned transaction against a fork of the chain.
+type Simulator interface {
+	// Simulate runs tx as sent by from on a fork of the chain's latest state.
+	Simulate(ctx context.Context, chainID uint64, from common.Address, tx *types.Transaction) (*Result, error)
+	// Provider returns the provider name, used for logging.
+	Provider() string
+}
+
+// Config selects and configures the fork simulation provider.
+type Config struct {
+	Provider  string            // "" (disabled), "anvil" or "tenderly"
+	RPCURLs   map[uint64]string // anvil: fork RPC URL per chain ID
+	Threshold string            // Simulate jobs at or above this amount in whole tokens ("" simulates every job)
+
+	TenderlyAccount string
+	TenderlyProject string
+	TenderlyKey     string
+	TenderlyURL     string // Optional: API base URL
+}
+
+// NewSimulator creates the simulator selected by cfg.Provider.
+// An empty provider disables simulation and returns nil.
+func NewSimulator(cfg Config) (Simulator, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderAnvil:
+		if len(cfg.RPCURLs) == 0 {
+			return nil, fmt.Errorf("anvil fork simulation requires at least one fork rpc url")
+		}
+		return NewAnvil(cfg.RPCURLs)
+	case ProviderTenderly:
+		if cfg.TenderlyAccount == "" || cfg.TenderlyProject == "" || cfg.TenderlyKey == "" {
+			return nil, fmt.Errorf("tenderly fork simulation requires account, project and access key")
+		}
+		return NewTenderly(cfg.TenderlyAccount, cfg.TenderlyProject, cfg.TenderlyKey, cfg.TenderlyURL), nil
+	default:
+		return nil, fmt.Errorf("unknown fork simulation provider: %s", cfg.Provider)
+	}
+}
+
+// UnsupportedChainError is returned when the provider has no fork for a chain.
+type UnsupportedChainError struct {
+	ChainID uint64
+}
+
+func (e *UnsupportedChainError) Error() string {
+	return fmt.Sprintf("no fork simulation configured for chain %d", e.ChainID)
+}
+
+// RevertedError is returned when a transaction reverts on the fork.
+type RevertedError struct {
+	Provider string
+	Result   *Result
+}
+
+func (e *RevertedError) Error() string {
+	reason := e.Result.RevertReason
+	if reason == "" {
+		reason = "no reason"
+	}
+	return fmt.Sprintf("transaction reverted in %s fork simulation: %s", e.Provider, reason)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *RevertedError) ErrorCode() string { return Code }
@@ -0,0 +1,127 @@
+package forksim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	from = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to   = common.HexToAddress("0x0000000000000000000000000000000000000001")
+)
+
+func testTx() *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(8453),
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(100),
+		Gas:       65000,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      []byte{0xa9, 0x05, 0x9c, 0xbb},
+	})
+}
+
+// revertData ABI-encodes Error(string).
+func revertData(t *testing.T, reason string) string {
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	packed, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	require.NoError(t, err)
+	return hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, packed...))
+}
+
+func TestAnvil(t *testing.T) {
+	reverting := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_call", req.Method)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if reverting {
+			resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted: blacklisted", "data": revertData(t, "blacklisted")}
+		} else {
+			resp["result"] = "0x"
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	sim, err := NewAnvil(map[uint64]string{8453: srv.URL})
+	require.NoError(t, err)
+
+	result, err := sim.Simulate(context.Background(), 8453, from, testTx())
+	require.NoError(t, err)
+	assert.True(t, result.Reverted)
+	assert.Equal(t, "blacklisted", result.RevertReason)
+
+	reverting = false
+	result, err = sim.Simulate(context.Background(), 8453, from, testTx())
+	require.NoError(t, err)
+	assert.False(t, result.Reverted)
+
+	_, err = sim.Simulate(context.Background(), 1, from, testTx())
+	var unsupported *UnsupportedChainError
+	assert.True(t, errors.As(err, &unsupported))
+}
+
+func TestRevertReason(t *testing.T) {
+	reason, ok := revertReason(errors.New("execution reverted: ERC20: transfer amount exceeds balance"))
+	assert.True(t, ok)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", reason)
+
+	_, ok = revertReason(errors.New("dial tcp: connection refused"))
+	assert.False(t, ok)
+}
+
+func TestTenderly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/account/acme/project/payouts/simulate", r.URL.Path)
+		assert.Equal(t, "access-key", r.Header.Get("X-Access-Key"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "8453", body["network_id"])
+		assert.Equal(t, to.Hex(), body["to"])
+		assert.Equal(t, "0xa9059cbb", body["input"])
+		w.Write([]byte(`{"transaction":{"status":false,"error_message":"execution reverted","gas_used":31000}}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewTenderly("acme", "payouts", "access-key", srv.URL).Simulate(context.Background(), 8453, from, testTx())
+	require.NoError(t, err)
+	assert.True(t, result.Reverted)
+	assert.Equal(t, "execution reverted", result.RevertReason)
+	assert.EqualValues(t, 31000, result.GasUsed)
+}
+
+func TestNewSimulator(t *testing.T) {
+	sim, err := NewSimulator(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, sim)
+
+	_, err = NewSimulator(Config{Provider: ProviderAnvil})
+	assert.Error(t, err)
+	_, err = NewSimulator(Config{Provider: ProviderTenderly, TenderlyAccount: "acme"})
+	assert.Error(t, err)
+	_, err = NewSimulator(Config{Provider: "hardhat"})
+	assert.Error(t, err)
+
+	err = &RevertedError{Provider: ProviderAnvil, Result: &Result{Reverted: true, RevertReason: "blacklisted"}}
+	assert.Equal(t, "transaction reverted in anvil fork simulation: blacklisted", err.Error())
+}
@@ -0,0 +1,105 @@
+package forksim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const defaultTenderlyURL = "https://api.tenderly.co"
+
+// Tenderly simulates transactions with the Tenderly Simulation API,
+// which runs them on a fork of the latest block.
+type Tenderly struct {
+	account    string
+	project    string
+	accessKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTenderly creates a Tenderly simulator.
+func NewTenderly(account, project, accessKey, baseURL string) *Tenderly {
+	if baseURL == "" {
+		baseURL = defaultTenderlyURL
+	}
+	return &Tenderly{
+		account:    account,
+		project:    project,
+		accessKey:  accessKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Provider implements Simulator.
+func (t *Tenderly) Provider() string { return ProviderTenderly }
+
+// Simulate implements Simulator.
+func (t *Tenderly) Simulate(ctx context.Context, chainID uint64, from common.Address, tx *types.Transaction) (*Result, error) {
+	body := map[string]interface{}{
+		"network_id":      strconv.FormatUint(chainID, 10),
+		"from":            from.Hex(),
+		"input":           hexutil.Encode(tx.Data()),
+		"gas":             tx.Gas(),
+		"gas_price":       tx.GasFeeCap().String(),
+		"value":           tx.Value().String(),
+		"save":            false,
+		"save_if_fails":   false,
+		"simulation_type": "quick",
+	}
+	if tx.To() != nil {
+		body["to"] = tx.To().Hex()
+	}
+	data, _ := json.Marshal(body)
+
+	endpoint := fmt.Sprintf("%s/api/v1/account/%s/project/%s/simulate", t.baseURL, url.PathEscape(t.account), url.PathEscape(t.project))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Access-Key", t.accessKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tenderly simulation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("tenderly simulation failed: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("tenderly simulation failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var out struct {
+		Transaction struct {
+			Status       bool   `json:"status"`
+			ErrorMessage string `json:"error_message"`
+			GasUsed      uint64 `json:"gas_used"`
+		} `json:"transaction"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode tenderly response: %w", err)
+	}
+	return &Result{
+		Reverted:     !out.Transaction.Status,
+		RevertReason: out.Transaction.ErrorMessage,
+		GasUsed:      out.Transaction.GasUsed,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// L1 数据费模型 (config.ChainConfig.L1Fee)。Rollup 上的交易还要为发布到 L1 的数据付费，
+// gas limit × gas price 不能反映总成本。
+const (
+	L1FeeOP       = "op"       // OP Stack: GasPriceOracle.getL1Fee，在 gas limit 之外另行收取
+	L1FeeArbitrum = "arbitrum" // Arbitrum: NodeInterface.gasEstimateL1Component，折算为 L2 gas 计入 gas limit
+)
+
+var (
+	opGasPriceOracle      = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	arbitrumNodeInterface = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+const l1FeeABIJSON = `[
+	{"name":"getL1Fee","type":"function","stateMutability":"view","inputs":[{"name":"_data","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"name":"gasEstimateL1Component","type":"function","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"contractCreation","type":"bool"},{"name":"data","type":"bytes"}],"outputs":[{"name":"gasEstimateForL1","type":"uint64"},{"name":"baseFee","type":"uint256"},{"name":"l1BaseFeeEstimate","type":"uint256"}]}
+]`
+
+var l1FeeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(l1FeeABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Caller L1 数据费估算所需的 RPC 接口 (*rpcpool.Pool 满足)
+type Caller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// L1Cost 交易的 L1 数据成本
+type L1Cost struct {
+	Gas uint64   // Arbitrum: 计入 gas limit 的 L1 部分 (eth_estimateGas 结果已包含)
+	Fee *big.Int // OP Stack: gas limit × gas price 之外另行收取的费用 (wei)
+}
+
+// EstimateL1Cost 估算未签名交易的 L1 数据成本，model 为空时返回零成本
+func EstimateL1Cost(ctx context.Context, client Caller, model string, tx *types.Transaction) (*L1Cost, error) {
+	cost := &L1Cost{Fee: big.NewInt(0)}
+	switch model {
+	case "":
+		return cost, nil
+	case L1FeeOP:
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		out, err := callL1Oracle(ctx, client, opGasPriceOracle, "getL1Fee", raw)
+		if err != nil {
+			return nil, err
+		}
+		cost.Fee = out[0].(*big.Int)
+		return cost, nil
+	case L1FeeArbitrum:
+		to := common.Address{}
+		if tx.To() != nil {
+			to = *tx.To()
+		}
+		out, err := callL1Oracle(ctx, client, arbitrumNodeInterface, "gasEstimateL1Component", to, tx.To() == nil, tx.Data())
+		if err != nil {
+			return nil, err
+		}
+		cost.Gas = out[0].(uint64)
+		return cost, nil
+	default:
+		return nil, fmt.Errorf("unknown l1 fee model: %s", model)
+	}
+}
+
+func callL1Oracle(ctx context.Context, client Caller, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := l1FeeABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s call failed: %w", method, err)
+	}
+	out, err := l1FeeABI.Unpack(method, raw)
+	if err != nil || len(out) == 0 {
+		return nil, fmt.Errorf("failed to decode %s: %v", method, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeL1Caller answers GasPriceOracle and NodeInterface calls and records the calldata.
+type fakeL1Caller struct {
+	calls map[common.Address][]interface{}
+}
+
+func (f *fakeL1Caller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := l1FeeABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	f.calls[*msg.To] = args
+	switch *msg.To {
+	case opGasPriceOracle:
+		return method.Outputs.Pack(big.NewInt(42_000_000_000))
+	case arbitrumNodeInterface:
+		return method.Outputs.Pack(uint64(3500), big.NewInt(10_000_000), big.NewInt(20_000_000_000))
+	}
+	return nil, nil
+}
+
+func TestEstimateL1Cost(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeL1Caller{calls: make(map[common.Address][]interface{})}
+	to := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(10), Gas: 65000, To: &to, Data: []byte{0xa9, 0x05, 0x9c, 0xbb}})
+
+	cost, err := EstimateL1Cost(ctx, client, "", tx)
+	require.NoError(t, err)
+	assert.Zero(t, cost.Gas)
+	assert.Zero(t, cost.Fee.Sign())
+	assert.Empty(t, client.calls)
+
+	// OP Stack: 按序列化的交易计费，不计入 gas limit
+	cost, err = EstimateL1Cost(ctx, client, L1FeeOP, tx)
+	require.NoError(t, err)
+	assert.Zero(t, cost.Gas)
+	assert.Equal(t, big.NewInt(42_000_000_000), cost.Fee)
+	raw, _ := tx.MarshalBinary()
+	assert.Equal(t, raw, client.calls[opGasPriceOracle][0])
+
+	// Arbitrum: L1 部分折算为 L2 gas
+	cost, err = EstimateL1Cost(ctx, client, L1FeeArbitrum, tx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3500, cost.Gas)
+	assert.Zero(t, cost.Fee.Sign())
+	assert.Equal(t, []interface{}{to, false, tx.Data()}, client.calls[arbitrumNodeInterface])
+
+	_, err = EstimateL1Cost(ctx, client, "zksync", tx)
+	assert.Error(t, err)
+}
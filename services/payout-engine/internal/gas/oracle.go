@@ -0,0 +1,160 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// Priority 支付优先级，决定小费分位数、base fee 余量和 gas limit 缓冲
+type Priority string
+
+const (
+	PriorityLow    Priority = "LOW"
+	PriorityMedium Priority = "MEDIUM"
+	PriorityHigh   Priority = "HIGH"
+	PriorityUrgent Priority = "URGENT"
+)
+
+// DefaultPriority is used when a request does not specify one.
+const DefaultPriority = PriorityMedium
+
+// DefaultHistoryBlocks 采样的历史区块数
+const DefaultHistoryBlocks = 20
+
+// Strategy 每个优先级的费用策略
+type Strategy struct {
+	TipPercentile     float64 // eth_feeHistory reward percentile used for the priority fee
+	BaseFeeHeadroom   int64   // maxFeePerGas = baseFee * headroom / 100 + tip
+	GasLimitBufferPct uint64  // gasLimit = estimate * buffer / 100
+}
+
+var strategies = map[Priority]Strategy{
+	PriorityLow:    {TipPercentile: 10, BaseFeeHeadroom: 125, GasLimitBufferPct: 110},
+	PriorityMedium: {TipPercentile: 50, BaseFeeHeadroom: 150, GasLimitBufferPct: 120},
+	PriorityHigh:   {TipPercentile: 75, BaseFeeHeadroom: 200, GasLimitBufferPct: 130},
+	PriorityUrgent: {TipPercentile: 90, BaseFeeHeadroom: 300, GasLimitBufferPct: 150},
+}
+
+// rewardPercentiles 一次 feeHistory 调用覆盖所有策略
+var rewardPercentiles = []float64{10, 50, 75, 90}
+
+// ParsePriority 解析优先级 (大小写不敏感，空值为默认优先级)
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return DefaultPriority, nil
+	}
+	p := Priority(strings.ToUpper(s))
+	if _, ok := strategies[p]; !ok {
+		return "", fmt.Errorf("invalid priority: %s", s)
+	}
+	return p, nil
+}
+
+// StrategyFor 返回优先级对应的策略，未知优先级使用默认策略
+func StrategyFor(p Priority) Strategy {
+	if s, ok := strategies[p]; ok {
+		return s
+	}
+	return strategies[DefaultPriority]
+}
+
+// BufferGas 按策略放大 gas 估算
+func (s Strategy) BufferGas(estimatedGas uint64) uint64 {
+	return estimatedGas * s.GasLimitBufferPct / 100
+}
+
+// Fees EIP-1559 费用建议
+type Fees struct {
+	BaseFee *big.Int // 下一区块 base fee
+	TipCap  *big.Int // maxPriorityFeePerGas
+	FeeCap  *big.Int // maxFeePerGas
+}
+
+// Client 费用预言机所需的 RPC 接口 (*ethclient.Client 满足)
+type Client interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// Oracle 基于 eth_feeHistory 的 EIP-1559 费用预言机
+type Oracle struct {
+	client Client
+	blocks uint64
+}
+
+// NewOracle 创建费用预言机
+func NewOracle(client Client, blocks uint64) *Oracle {
+	if blocks == 0 {
+		blocks = DefaultHistoryBlocks
+	}
+	return &Oracle{client: client, blocks: blocks}
+}
+
+// Suggest 根据优先级给出费用建议。
+// 小费取最近区块对应分位数奖励的中位数；base fee 使用节点给出的下一区块值再乘以余量。
+// 节点不支持 feeHistory 或链无 base fee 时回退到 SuggestGasPrice。
+func (o *Oracle) Suggest(ctx context.Context, p Priority) (*Fees, error) {
+	strategy := StrategyFor(p)
+
+	history, err := o.client.FeeHistory(ctx, o.blocks, nil, rewardPercentiles)
+	if err != nil || len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil {
+		return o.legacy(ctx, strategy)
+	}
+
+	// BaseFee 最后一项为下一个区块的 base fee
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	if baseFee.Sign() == 0 {
+		return o.legacy(ctx, strategy)
+	}
+
+	column := percentileIndex(strategy.TipPercentile)
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, rewards := range history.Reward {
+		if column < len(rewards) && rewards[column] != nil {
+			tips = append(tips, rewards[column])
+		}
+	}
+	tipCap := median(tips)
+
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(strategy.BaseFeeHeadroom))
+	feeCap.Div(feeCap, big.NewInt(100))
+	feeCap.Add(feeCap, tipCap)
+
+	return &Fees{BaseFee: new(big.Int).Set(baseFee), TipCap: tipCap, FeeCap: feeCap}, nil
+}
+
+// legacy 无 EIP-1559 数据时按 gasPrice 估算，tip 与 feeCap 相同
+func (o *Oracle) legacy(ctx context.Context, strategy Strategy) (*Fees, error) {
+	gasPrice, err := o.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	price := new(big.Int).Mul(gasPrice, big.NewInt(strategy.BaseFeeHeadroom))
+	price.Div(price, big.NewInt(100))
+	return &Fees{BaseFee: big.NewInt(0), TipCap: price, FeeCap: new(big.Int).Set(price)}, nil
+}
+
+func percentileIndex(p float64) int {
+	for i, v := range rewardPercentiles {
+		if v == p {
+			return i
+		}
+	}
+	return 1 // 50th
+}
+
+// median 返回中位数 (偶数个取较高者)，空切片返回 0
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return big.NewInt(0)
+	}
+	sorted := make([]*big.Int, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	return new(big.Int).Set(sorted[len(sorted)/2])
+}
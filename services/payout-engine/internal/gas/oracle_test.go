@@ -0,0 +1,85 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	history  *ethereum.FeeHistory
+	err      error
+	gasPrice *big.Int
+}
+
+func (f *fakeClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return f.history, f.err
+}
+
+func (f *fakeClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return f.gasPrice, nil
+}
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000))
+}
+
+func TestOracleSuggest(t *testing.T) {
+	// 3 blocks of rewards at the 10/50/75/90th percentiles; next base fee is 20 gwei
+	client := &fakeClient{history: &ethereum.FeeHistory{
+		Reward: [][]*big.Int{
+			{gwei(1), gwei(2), gwei(3), gwei(10)},
+			{gwei(1), gwei(3), gwei(4), gwei(6)},
+			{gwei(1), gwei(2), gwei(5), gwei(8)},
+		},
+		BaseFee: []*big.Int{gwei(18), gwei(19), gwei(19), gwei(20)},
+	}}
+	oracle := NewOracle(client, 3)
+
+	tests := []struct {
+		priority Priority
+		tip      *big.Int
+		feeCap   *big.Int
+	}{
+		{PriorityLow, gwei(1), gwei(26)},    // 20 * 1.25 + 1
+		{PriorityMedium, gwei(2), gwei(32)}, // 20 * 1.5 + 2
+		{PriorityHigh, gwei(4), gwei(44)},   // 20 * 2 + 4
+		{PriorityUrgent, gwei(8), gwei(68)}, // 20 * 3 + 8
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.priority), func(t *testing.T) {
+			fees, err := oracle.Suggest(context.Background(), tt.priority)
+			require.NoError(t, err)
+			assert.Equal(t, gwei(20), fees.BaseFee)
+			assert.Equal(t, tt.tip, fees.TipCap)
+			assert.Equal(t, tt.feeCap, fees.FeeCap)
+		})
+	}
+}
+
+func TestOracleFallsBackToGasPrice(t *testing.T) {
+	client := &fakeClient{err: errors.New("method not found"), gasPrice: gwei(10)}
+	fees, err := NewOracle(client, 0).Suggest(context.Background(), PriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, gwei(20), fees.TipCap)
+	assert.Equal(t, gwei(20), fees.FeeCap)
+}
+
+func TestParsePriority(t *testing.T) {
+	p, err := ParsePriority("")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityMedium, p)
+
+	p, err = ParsePriority("urgent")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityUrgent, p)
+
+	_, err = ParsePriority("ASAP")
+	assert.Error(t, err)
+}
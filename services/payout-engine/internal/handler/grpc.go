@@ -3,27 +3,416 @@ package handler
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
+	"fmt"
 
+	"github.com/protocol-bank/payout-engine/internal/queue"
 	"github.com/protocol-bank/payout-engine/internal/service"
+	"github.com/protocol-bank/payout-engine/pb"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // PayoutServer gRPC 服务实现
 type PayoutServer struct {
+	pb.UnimplementedPayoutServiceServer
 	service *service.PayoutService
 }
 
 // RegisterPayoutServer 注册 gRPC 服务
 func RegisterPayoutServer(s *grpc.Server, svc *service.PayoutService) {
-	// 注册到 gRPC 服务器
-	// pb.RegisterPayoutServiceServer(s, &PayoutServer{service: svc})
+	pb.RegisterPayoutServiceServer(s, &PayoutServer{service: svc})
 	log.Info().Msg("Payout gRPC server registered")
 }
 
+// SubmitBatchPayout 提交批量支付
+func (p *PayoutServer) SubmitBatchPayout(ctx context.Context, req *pb.BatchPayoutRequest) (*pb.BatchPayoutResponse, error) {
+	if req.GetMultisigConfig().GetEnabled() {
+		return nil, status.Error(codes.Unimplemented, "multisig payouts are not supported by this engine")
+	}
+	resp, err := p.service.SubmitBatchPayout(ctx, batchRequestFromProto(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return batchResponseToProto(resp), nil
+}
+
+// UpdateScheduledBatch 修改尚未到执行时间的定时批次
+func (p *PayoutServer) UpdateScheduledBatch(ctx context.Context, req *pb.BatchPayoutRequest) (*pb.BatchPayoutResponse, error) {
+	if req.GetMultisigConfig().GetEnabled() {
+		return nil, status.Error(codes.Unimplemented, "multisig payouts are not supported by this engine")
+	}
+	resp, err := p.service.UpdateScheduledBatch(ctx, batchRequestFromProto(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return batchResponseToProto(resp), nil
+}
+
+func batchRequestFromProto(req *pb.BatchPayoutRequest) *service.BatchPayoutRequest {
+	items := make([]service.PayoutItem, len(req.GetItems()))
+	for i, item := range req.GetItems() {
+		items[i] = service.PayoutItem{
+			ID:               item.GetId(),
+			RecipientAddress: item.GetRecipientAddress(),
+			Amount:           item.GetAmount(),
+			TokenAddress:     item.GetTokenAddress(),
+			TokenSymbol:      item.GetTokenSymbol(),
+			TokenDecimals:    item.GetTokenDecimals(),
+			TokenID:          item.GetTokenId(),
+			MaxFee:           item.GetMaxFee(),
+		}
+	}
+
+	out := &service.BatchPayoutRequest{
+		BatchID:         req.GetBatchId(),
+		UserID:          req.GetUserId(),
+		FromAddress:     req.GetFromAddress(),
+		ChainID:         req.GetChainId(),
+		SourceChainID:   req.GetSourceChainId(),
+		Items:           items,
+		Priority:        req.GetPriority(),
+		UseSmartAccount: req.GetUseSmartAccount(),
+		UseTreasury:     req.GetUseTreasury(),
+		AllowPartial:    req.GetAllowPartial(),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		WebhookURL:      req.GetWebhookUrl(),
+		WebhookSecret:   req.GetWebhookSecret(),
+
+		WebhookSchemaVersion: int(req.GetWebhookSchemaVersion()),
+
+		ScreeningOverride:       req.GetScreeningOverride(),
+		ScreeningOverrideReason: req.GetScreeningOverrideReason(),
+		Simulate:                req.GetSimulate(),
+		DuplicateMode:           service.DuplicateMode(req.GetDuplicateMode()),
+	}
+	if req.GetExecuteAt() != nil {
+		out.ExecuteAt = req.GetExecuteAt().AsTime()
+	}
+	if p := req.GetPermit(); p != nil {
+		out.Permit = &queue.Permit{
+			Owner:    p.GetOwner(),
+			Value:    p.GetValue(),
+			Deadline: p.GetDeadline(),
+			V:        uint8(p.GetV()),
+			R:        p.GetR(),
+			S:        p.GetS(),
+		}
+	}
+	if sw := req.GetSwap(); sw != nil {
+		out.Swap = &queue.Swap{
+			SellToken:      sw.GetSellToken(),
+			MaxSlippageBps: int(sw.GetMaxSlippageBps()),
+		}
+	}
+	return out
+}
+
+func batchResponseToProto(resp *service.BatchPayoutResponse) *pb.BatchPayoutResponse {
+	out := &pb.BatchPayoutResponse{
+		BatchId:  resp.BatchID,
+		Status:   batchStatusToProto(resp.Status),
+		Message:  resp.Message,
+		Testnet:  resp.Testnet,
+		Replayed: resp.Replayed,
+
+		ManifestHash:      resp.ManifestHash,
+		ManifestSignature: resp.ManifestSignature,
+		ManifestSigner:    resp.ManifestSigner,
+
+		Simulated:        resp.Simulated,
+		EstimatedGasCost: resp.EstimatedGasCost,
+	}
+	if !resp.ExecuteAt.IsZero() {
+		out.ExecuteAt = timestamppb.New(resp.ExecuteAt)
+	}
+	for _, r := range resp.Rejected {
+		out.Rejected = append(out.Rejected, &pb.RejectedItem{ItemId: r.ItemID, Reason: r.Reason})
+	}
+	for _, d := range resp.Duplicates {
+		out.Duplicates = append(out.Duplicates, &pb.DuplicateItem{ItemId: d.ItemID, DuplicateOf: d.DuplicateOf, Decision: string(d.Decision)})
+	}
+	return out
+}
+
+// GetBatchStatus 查询批次状态
+func (p *PayoutServer) GetBatchStatus(ctx context.Context, req *pb.BatchStatusRequest) (*pb.BatchStatusResponse, error) {
+	result, err := p.service.GetBatchStatus(ctx, req.GetUserId(), req.GetBatchId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := &pb.BatchStatusResponse{
+		BatchId:        result.BatchID,
+		Status:         batchStatusToProto(result.Status),
+		TotalCount:     int32(result.TotalCount),
+		CompletedCount: int32(result.CompletedCount),
+		FailedCount:    int32(result.FailedCount),
+		PendingCount:   int32(result.PendingCount),
+		CancelledCount: int32(result.CancelledCount),
+		CreatedAt:      timestamppb.New(result.CreatedAt),
+		UpdatedAt:      timestamppb.New(result.UpdatedAt),
+		ManifestHash:   result.ManifestHash,
+		ErrorMessage:   result.Error,
+
+		ApprovedBy:        result.ApprovedBy,
+		ApprovalsRequired: int32(result.ApprovalsRequired),
+		TotalGasFee:       result.GasFee,
+	}
+	if !result.ExecuteAt.IsZero() {
+		out.ExecuteAt = timestamppb.New(result.ExecuteAt)
+	}
+	for _, job := range result.Items {
+		out.Items = append(out.Items, jobStatusToProto(job))
+	}
+	return out, nil
+}
+
+// CancelBatchPayout 取消批次中尚未发送的支付项 (指定 job_ids 时只取消这些支付项)
+func (p *PayoutServer) CancelBatchPayout(ctx context.Context, req *pb.CancelBatchRequest) (*pb.CancelBatchResponse, error) {
+	if len(req.GetJobIds()) > 0 {
+		result, err := p.service.CancelJobs(ctx, req.GetUserId(), req.GetBatchId(), req.GetJobIds(), req.GetReason())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &pb.CancelBatchResponse{
+			Success:               true,
+			Message:               fmt.Sprintf("Cancelled %d payments; %d already in flight", len(result.Cancelled), len(result.InFlight)),
+			CancelledCount:        int32(len(result.Cancelled)),
+			AlreadyProcessedCount: int32(len(result.InFlight)),
+			CancelledJobIds:       result.Cancelled,
+			InFlightJobIds:        result.InFlight,
+		}, nil
+	}
+	cancelled, processed, err := p.service.CancelBatch(ctx, req.GetUserId(), req.GetBatchId(), req.GetReason())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CancelBatchResponse{
+		Success:               true,
+		Message:               fmt.Sprintf("Cancelled %d payments; %d already processed", cancelled, processed),
+		CancelledCount:        int32(cancelled),
+		AlreadyProcessedCount: int32(processed),
+	}, nil
+}
+
+// ListJobs 列出用户的支付任务
+func (p *PayoutServer) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	// 对外接口按用户隔离
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	filter := service.JobFilter{UserID: req.GetUserId(), BatchID: req.GetBatchId(), State: jobStateFromProto(req.GetStatus())}
+	jobs, total, err := p.service.ListJobs(ctx, filter, int(req.GetOffset()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &pb.ListJobsResponse{Total: int32(total)}
+	for _, job := range jobs {
+		out.Jobs = append(out.Jobs, jobStatusToProto(job))
+	}
+	return out, nil
+}
+
+// RetryFailedPayouts 将死信队列中的失败支付重新入队
+func (p *PayoutServer) RetryFailedPayouts(ctx context.Context, req *pb.RetryRequest) (*pb.RetryResponse, error) {
+	n, err := p.service.RetryFailedPayouts(ctx, req.GetBatchId(), req.GetItemIds())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RetryResponse{
+		Success:    true,
+		Message:    fmt.Sprintf("Requeued %d failed payments", n),
+		RetryCount: int32(n),
+	}, nil
+}
+
+// ListFailedPayouts 列出死信队列中的失败支付
+func (p *PayoutServer) ListFailedPayouts(ctx context.Context, req *pb.ListFailedPayoutsRequest) (*pb.ListFailedPayoutsResponse, error) {
+	entries, total, err := p.service.ListFailedPayouts(ctx, req.GetBatchId(), int(req.GetOffset()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &pb.ListFailedPayoutsResponse{Total: int32(total)}
+	for _, entry := range entries {
+		item := &pb.FailedPayout{
+			Id:               entry.Job.ID,
+			BatchId:          entry.Job.BatchID,
+			RecipientAddress: entry.Job.ToAddress,
+			Amount:           entry.Job.Amount,
+			TokenAddress:     entry.Job.TokenAddress,
+			ChainId:          entry.Job.ChainID,
+			ErrorMessage:     entry.Error,
+			Attempts:         int32(entry.Attempts),
+			Permanent:        entry.Permanent,
+			FailedAt:         timestamppb.New(entry.FailedAt),
+		}
+		if entry.ChainError != nil {
+			item.ChainErrorCode = string(entry.ChainError.Code)
+			item.ChainErrorDetails = entry.ChainError.Details
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+// GetWalletInventory 查询付款钱包库存
+func (p *PayoutServer) GetWalletInventory(ctx context.Context, req *pb.WalletInventoryRequest) (*pb.WalletInventoryResponse, error) {
+	wallets, err := p.service.GetWalletInventory(ctx, req.GetChainId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &pb.WalletInventoryResponse{}
+	for _, w := range wallets {
+		out.Wallets = append(out.Wallets, &pb.WalletInventory{
+			ChainId:         w.ChainID,
+			ChainName:       w.ChainName,
+			Address:         w.Address,
+			TokenAddress:    w.Token,
+			TokenSymbol:     w.Symbol,
+			Balance:         w.Balance,
+			PendingOutflow:  w.PendingOutflow,
+			PendingJobs:     int32(w.PendingJobs),
+			Available:       w.Available,
+			AvgDailyOutflow: w.AvgDailyOutflow,
+			RunwayDays:      w.RunwayDays,
+			ErrorMessage:    w.Error,
+		})
+	}
+	return out, nil
+}
+
+// GetGasCosts 按链、按 UTC 日查询实际网络费
+func (p *PayoutServer) GetGasCosts(ctx context.Context, req *pb.GasCostsRequest) (*pb.GasCostsResponse, error) {
+	query := service.GasAnalyticsQuery{
+		UserID:   req.GetUserId(),
+		ChainID:  req.GetChainId(),
+		Interval: service.GasIntervalDay,
+	}
+	if req.GetFrom() != nil {
+		query.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		query.To = req.GetTo().AsTime()
+	}
+	report, err := p.service.GasAnalytics(ctx, query)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &pb.GasCostsResponse{}
+	for _, chain := range report.Chains {
+		c := &pb.ChainGasCost{
+			ChainId:      chain.ChainID,
+			ChainName:    chain.Name,
+			NativeToken:  chain.NativeToken,
+			Decimals:     int32(chain.Decimals),
+			TotalGasFee:  chain.GasSpent,
+			Payouts:      chain.Payouts,
+			Transactions: chain.Transactions,
+		}
+		for _, day := range chain.Periods {
+			c.Days = append(c.Days, &pb.DailyGasCost{
+				Date:         timestamppb.New(*day.Start),
+				GasFee:       day.GasSpent,
+				Payouts:      day.Payouts,
+				Transactions: day.Transactions,
+			})
+		}
+		out.Chains = append(out.Chains, c)
+	}
+	return out, nil
+}
+
+// toStatus 将服务层错误映射为 gRPC 状态码
+func toStatus(err error) error {
+	switch {
+	case service.IsInvalidArgument(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case service.IsFailedPrecondition(err):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case service.IsUnavailable(err):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, queue.ErrBatchNotFound), errors.Is(err, service.ErrJobNotFound), errors.Is(err, service.ErrAddressListEntryNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, queue.ErrIdempotencyInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	log.Error().Err(err).Msg("gRPC request failed")
+	return status.Error(codes.Internal, "internal error")
+}
+
+func batchStatusToProto(s service.BatchStatus) pb.BatchStatus {
+	switch s {
+	case service.BatchStatusScheduled:
+		return pb.BatchStatus_BATCH_STATUS_SCHEDULED
+	case service.BatchStatusPendingApproval:
+		return pb.BatchStatus_BATCH_STATUS_PENDING_APPROVAL
+	case service.BatchStatusQueued:
+		return pb.BatchStatus_BATCH_STATUS_QUEUED
+	case service.BatchStatusProcessing:
+		return pb.BatchStatus_BATCH_STATUS_PROCESSING
+	case service.BatchStatusCompleted:
+		return pb.BatchStatus_BATCH_STATUS_COMPLETED
+	case service.BatchStatusPartialFailed:
+		return pb.BatchStatus_BATCH_STATUS_PARTIAL_FAILED
+	case service.BatchStatusFailed:
+		return pb.BatchStatus_BATCH_STATUS_FAILED
+	case service.BatchStatusCancelled:
+		return pb.BatchStatus_BATCH_STATUS_CANCELLED
+	}
+	return pb.BatchStatus_BATCH_STATUS_UNSPECIFIED
+}
+
+var jobStates = map[queue.JobState]pb.PayoutStatus{
+	queue.JobStatePending:    pb.PayoutStatus_PAYOUT_STATUS_PENDING,
+	queue.JobStateProcessing: pb.PayoutStatus_PAYOUT_STATUS_SUBMITTED,
+	queue.JobStateRetrying:   pb.PayoutStatus_PAYOUT_STATUS_RETRYING,
+	queue.JobStateConfirmed:  pb.PayoutStatus_PAYOUT_STATUS_CONFIRMED,
+	queue.JobStateFailed:     pb.PayoutStatus_PAYOUT_STATUS_FAILED,
+	queue.JobStateCancelled:  pb.PayoutStatus_PAYOUT_STATUS_CANCELLED,
+}
+
+func jobStateFromProto(s pb.PayoutStatus) queue.JobState {
+	for state, ps := range jobStates {
+		if ps == s {
+			return state
+		}
+	}
+	return "" // UNSPECIFIED: 不过滤
+}
+
+func jobStatusToProto(job *queue.JobStatus) *pb.PayoutItemStatus {
+	item := &pb.PayoutItemStatus{
+		Id:               job.ID,
+		RecipientAddress: job.ToAddress,
+		Amount:           job.Amount,
+		Status:           jobStates[job.State],
+		TxHash:           job.TxHash,
+		ErrorMessage:     job.Error,
+		RetryCount:       int32(job.RetryCount),
+		BatchId:          job.BatchID,
+		ChainId:          job.ChainID,
+		TokenAddress:     job.TokenAddress,
+		UpdatedAt:        timestamppb.New(job.UpdatedAt),
+		GasFee:           job.TotalGasFee().String(),
+		GasUsed:          job.GasUsed,
+		GasPrice:         job.GasPrice,
+	}
+	if job.ChainError != nil {
+		item.ChainErrorCode = string(job.ChainError.Code)
+		item.ChainErrorDetails = job.ChainError.Details
+	}
+	return item
+}
+
 // AuthInterceptor 认证拦截器
 func AuthInterceptor(apiSecret string) grpc.UnaryServerInterceptor {
 	return func(
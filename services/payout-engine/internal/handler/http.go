@@ -0,0 +1,930 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/approval"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// AdminServer 运维 REST 接口: 批次/任务查询与批次取消、收款地址名单、x402 中继
+type AdminServer struct {
+	service   *service.PayoutService
+	apiSecret string
+}
+
+// NewAdminHandler 创建运维 HTTP 处理器 (x-api-key 认证，/health、/ready 和 /metrics 除外)
+func NewAdminHandler(svc *service.PayoutService, apiSecret string) http.Handler {
+	a := &AdminServer{service: svc, apiSecret: apiSecret}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, r *http.Request) {
+		// 签名器不可用时不就绪，避免接收批次后在执行中途签名失败
+		if !a.service.SignersHealthy() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "signer unavailable"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+	mux.HandleFunc("GET /metrics", a.getMetrics)
+	mux.Handle("GET /batches/{id}", a.auth(a.getBatch))
+	mux.Handle("GET /batches/{id}/manifest", a.auth(a.getManifest))
+	mux.Handle("GET /batches/{id}/report", a.auth(a.getReport))
+	mux.Handle("POST /batches/{id}/cancel", a.auth(a.cancelBatch))
+	mux.Handle("POST /batches/{id}/approve", a.auth(a.approveBatch))
+	mux.Handle("GET /approvals", a.auth(a.listApprovals))
+	mux.Handle("GET /jobs", a.auth(a.listJobs))
+	mux.Handle("GET /jobs/{id}", a.auth(a.getJob))
+	mux.Handle("GET /jobs/{id}/attempts", a.auth(a.getJobAttempts))
+	mux.Handle("POST /jobs/{id}/policy-override", a.auth(a.overridePolicy))
+	mux.Handle("POST /jobs/{id}/recipient-approval", a.auth(a.approveRecipient))
+	mux.Handle("POST /jobs/{id}/reversal", a.auth(a.recordReversal))
+	mux.Handle("GET /address-lists", a.auth(a.listAddressLists))
+	mux.Handle("POST /address-lists", a.auth(a.addAddressListEntry))
+	mux.Handle("DELETE /address-lists/{list}/{address}", a.auth(a.removeAddressListEntry))
+	mux.Handle("GET /rpc", a.auth(a.getRPCStatus))
+	mux.Handle("GET /keys", a.auth(a.listSigningKeys))
+	mux.Handle("GET /keys/health", a.auth(a.getSignerHealth))
+	mux.Handle("GET /analytics/gas", a.auth(a.getGasAnalytics))
+	mux.Handle("GET /audit/signing", a.auth(a.listSigningAudit))
+	mux.Handle("GET /audit/signing/verify", a.auth(a.verifySigningAudit))
+	mux.Handle("GET /circuits", a.auth(a.listCircuits))
+	mux.Handle("GET /queue/backlog", a.auth(a.getQueueBacklog))
+	mux.Handle("GET /tron/batches", a.auth(a.listTronBatches))
+	mux.Handle("POST /chains/reload", a.auth(a.reloadChains))
+	mux.Handle("POST /chains/{chain_id}/pause", a.auth(a.pauseChain))
+	mux.Handle("POST /chains/{chain_id}/resume", a.auth(a.resumeChain))
+	mux.Handle("GET /chains/{chain_id}/treasury/allowance", a.auth(a.getTreasuryAllowance))
+	mux.Handle("POST /chains/{chain_id}/treasury/approvals", a.auth(a.requestTreasuryApproval))
+	mux.Handle("GET /treasury/approvals", a.auth(a.listTreasuryApprovals))
+	mux.Handle("POST /x402/submit", a.auth(a.submitX402))
+	mux.Handle("POST /x402/forward", a.auth(a.forwardX402))
+	mux.Handle("GET /x402/{id}", a.auth(a.getX402))
+	return correlation.Middleware(mux)
+}
+
+func (a *AdminServer) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("x-api-key")
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(a.apiSecret)) != 1 {
+			log.Warn().Str("path", r.URL.Path).Msg("Unauthorized admin request")
+			writeError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// batchResponse 批次详情 (jobs 按过滤条件分页)
+type batchResponse struct {
+	BatchID           string             `json:"batch_id"`
+	Status            string             `json:"status"`
+	TotalCount        int                `json:"total_count"`
+	CompletedCount    int                `json:"completed_count"`
+	FailedCount       int                `json:"failed_count"`
+	PendingCount      int                `json:"pending_count"`
+	CancelledCount    int                `json:"cancelled_count"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	ManifestHash      string             `json:"manifest_hash,omitempty"`
+	ExecuteAt         *time.Time         `json:"execute_at,omitempty"` // 定时批次
+	Error             string             `json:"error,omitempty"`      // 定时批次到期后未能入队的原因
+	ValueUSD          string             `json:"value_usd,omitempty"`  // 待审批批次
+	ApprovedBy        []string           `json:"approved_by,omitempty"`
+	ApprovalsRequired int                `json:"approvals_required,omitempty"`
+	ApprovalMessage   string             `json:"approval_message,omitempty"` // 审批人签名的文本
+	Jobs              []*queue.JobStatus `json:"jobs"`
+	MatchedJobs       int                `json:"matched_jobs"`
+	Offset            int                `json:"offset"`
+	Limit             int                `json:"limit"`
+
+	// 已上传到对象存储的批次报告
+	Report *queue.ReportLocation `json:"report,omitempty"`
+}
+
+type jobListResponse struct {
+	Jobs   []*queue.JobStatus `json:"jobs"`
+	Total  int                `json:"total"`
+	Offset int                `json:"offset"`
+	Limit  int                `json:"limit"`
+}
+
+type cancelResponse struct {
+	BatchID          string   `json:"batch_id"`
+	CancelledCount   int      `json:"cancelled_count"`
+	AlreadyProcessed int      `json:"already_processed"`
+	CancelledJobs    []string `json:"cancelled_jobs,omitempty"` // 指定 job_ids 时
+	InFlightJobs     []string `json:"in_flight_jobs,omitempty"` // 指定 job_ids 时: 已在处理中或已结束
+}
+
+// getBatch GET /batches/{id}?user_id=&chain_id=&status=&from=&to=&offset=&limit=
+func (a *AdminServer) getBatch(w http.ResponseWriter, r *http.Request) {
+	filter, offset, limit, err := parseJobQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	batchID := r.PathValue("id")
+
+	result, err := a.service.FindBatch(r.Context(), filter.UserID, batchID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	// 尚未入队的定时批次没有任务
+	var jobs []*queue.JobStatus
+	matched := 0
+	if len(result.Items) > 0 {
+		// FindBatch 已确定所属用户，按用户过滤避免串到同 ID 的其他批次
+		filter.UserID = result.Items[0].UserID
+		filter.BatchID = batchID
+		if jobs, matched, err = a.service.ListJobs(r.Context(), filter, offset, limit); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+
+	resp := batchResponse{
+		BatchID:        result.BatchID,
+		Status:         string(result.Status),
+		TotalCount:     result.TotalCount,
+		CompletedCount: result.CompletedCount,
+		FailedCount:    result.FailedCount,
+		PendingCount:   result.PendingCount,
+		CancelledCount: result.CancelledCount,
+		CreatedAt:      result.CreatedAt,
+		UpdatedAt:      result.UpdatedAt,
+		ManifestHash:   result.ManifestHash,
+		Error:          result.Error,
+		Jobs:           nonNilJobs(jobs),
+
+		ValueUSD:          result.ValueUSD,
+		ApprovedBy:        result.ApprovedBy,
+		ApprovalsRequired: result.ApprovalsRequired,
+		ApprovalMessage:   result.ApprovalMessage,
+		Report:            result.Report,
+		MatchedJobs:       matched,
+		Offset:            offset,
+		Limit:             limit,
+	}
+	if !result.ExecuteAt.IsZero() {
+		resp.ExecuteAt = &result.ExecuteAt
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// getManifest GET /batches/{id}/manifest?user_id=
+func (a *AdminServer) getManifest(w http.ResponseWriter, r *http.Request) {
+	manifest, err := a.service.FindManifest(r.Context(), r.URL.Query().Get("user_id"), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+// getReport GET /batches/{id}/report?user_id=&format=csv|json 已结束批次的报告 (默认 json)
+func (a *AdminServer) getReport(w http.ResponseWriter, r *http.Request) {
+	batchID := r.PathValue("id")
+	report, err := a.service.FindBatchReport(r.Context(), r.URL.Query().Get("user_id"), batchID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = service.ReportFormatJSON
+	}
+	body, contentType, err := report.Encode(format)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", batchID+"."+format))
+	w.Write(body)
+}
+
+// cancelBatch POST /batches/{id}/cancel?user_id=  body: {"reason": "...", "job_ids": [...]} (可选，job_ids 为空时取消整批)
+func (a *AdminServer) cancelBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string   `json:"reason"`
+		JobIDs []string `json:"job_ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	batchID := r.PathValue("id")
+	if len(body.JobIDs) > 0 {
+		result, err := a.service.CancelJobsByID(r.Context(), r.URL.Query().Get("user_id"), batchID, body.JobIDs, body.Reason)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, cancelResponse{
+			BatchID:          batchID,
+			CancelledCount:   len(result.Cancelled),
+			AlreadyProcessed: len(result.InFlight),
+			CancelledJobs:    result.Cancelled,
+			InFlightJobs:     result.InFlight,
+		})
+		return
+	}
+	cancelled, processed, err := a.service.CancelBatchByID(r.Context(), r.URL.Query().Get("user_id"), batchID, body.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cancelResponse{BatchID: batchID, CancelledCount: cancelled, AlreadyProcessed: processed})
+}
+
+// approvalResponse 待审批批次 (不含提交请求，避免泄露 webhook 密钥等)
+type approvalResponse struct {
+	UserID            string    `json:"user_id"`
+	BatchID           string    `json:"batch_id"`
+	Items             int       `json:"items"`
+	ValueUSD          string    `json:"value_usd"`
+	Unpriced          []string  `json:"unpriced,omitempty"`
+	ApprovedBy        []string  `json:"approved_by"`
+	ApprovalsRequired int       `json:"approvals_required"`
+	ApprovalMessage   string    `json:"approval_message"` // 审批人以 personal_sign 签名该文本
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// listApprovals GET /approvals 等待审批的批次
+func (a *AdminServer) listApprovals(w http.ResponseWriter, r *http.Request) {
+	pending, err := a.service.PendingApprovals(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := make([]approvalResponse, 0, len(pending))
+	for _, p := range pending {
+		out = append(out, approvalResponse{
+			UserID:            p.UserID,
+			BatchID:           p.BatchID,
+			Items:             p.Items,
+			ValueUSD:          p.ValueUSD,
+			Unpriced:          p.Unpriced,
+			ApprovedBy:        p.ApprovedBy(),
+			ApprovalsRequired: a.service.ApprovalRequirement(),
+			ApprovalMessage:   approval.Message(p.UserID, p.BatchID, p.RequestHash),
+			CreatedAt:         p.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"approvals": out})
+}
+
+// approveBatch POST /batches/{id}/approve?user_id= 审批人签名放行超过审批阈值的批次
+// (body: {"signature": "0x..."}，对 approval_message 的 EIP-191 签名)
+func (a *AdminServer) approveBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	result, err := a.service.ApproveBatchByID(r.Context(), r.URL.Query().Get("user_id"), r.PathValue("id"), body.Signature)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	resp := map[string]interface{}{
+		"batch_id":           result.BatchID,
+		"operator":           result.Operator,
+		"approved_by":        result.ApprovedBy,
+		"approvals_required": result.Required,
+		"released":           result.Released,
+	}
+	if result.Response != nil {
+		resp["status"] = string(result.Response.Status)
+		resp["message"] = result.Response.Message
+		resp["rejected"] = result.Response.Rejected
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// listJobs GET /jobs?user_id=&batch_id=&chain_id=&status=&from=&to=&offset=&limit=
+func (a *AdminServer) listJobs(w http.ResponseWriter, r *http.Request) {
+	filter, offset, limit, err := parseJobQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.BatchID = r.URL.Query().Get("batch_id")
+
+	jobs, total, err := a.service.ListJobs(r.Context(), filter, offset, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobListResponse{Jobs: nonNilJobs(jobs), Total: total, Offset: offset, Limit: limit})
+}
+
+// getJob GET /jobs/{id}?user_id=
+func (a *AdminServer) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := a.service.GetJob(r.Context(), r.URL.Query().Get("user_id"), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// getJobAttempts GET /jobs/{id}/attempts?user_id= (需配置任务账本)
+func (a *AdminServer) getJobAttempts(w http.ResponseWriter, r *http.Request) {
+	attempts, err := a.service.JobAttempts(r.Context(), r.URL.Query().Get("user_id"), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"attempts": attempts})
+}
+
+// overridePolicy POST /jobs/{id}/policy-override 人工批准被支出策略拦截的任务并重新入队
+func (a *AdminServer) overridePolicy(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ApprovedBy string `json:"approved_by"`
+		Reason     string `json:"reason"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	job, err := a.service.OverrideSpendingPolicy(r.Context(), r.PathValue("id"), body.ApprovedBy, body.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"job": job})
+}
+
+// approveRecipient POST /jobs/{id}/recipient-approval 人工确认被活跃度检查拦截的收款地址并重新入队
+func (a *AdminServer) approveRecipient(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ApprovedBy string `json:"approved_by"`
+		Reason     string `json:"reason"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	job, err := a.service.ApproveRecipient(r.Context(), r.PathValue("id"), body.ApprovedBy, body.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"job": job})
+}
+
+// recordReversal POST /jobs/{id}/reversal 登记收款方退回款项的交易 (链上核对后关联到任务)
+// body: {"tx_hash": "0x...", "recorded_by": "...", "note": "...", "user_id": "" (可选)}
+func (a *AdminServer) recordReversal(w http.ResponseWriter, r *http.Request) {
+	var body service.ReversalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	job, err := a.service.RecordReversal(r.Context(), r.PathValue("id"), body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"job": job})
+}
+
+// listAddressLists GET /address-lists?list=allow|deny&user_id=&chain_id=&address= (需配置任务账本)
+func (a *AdminServer) listAddressLists(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := ledger.AddressListQuery{List: q.Get("list"), UserID: q.Get("user_id"), Address: q.Get("address")}
+	if v := q.Get("chain_id"); v != "" {
+		chainID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid chain_id: %s", v))
+			return
+		}
+		query.ChainID = chainID
+	}
+	entries, err := a.service.AddressListEntries(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// addAddressListEntry POST /address-lists
+// body: {"list": "deny", "address": "0x...", "user_id": "" (所有用户), "chain_id": 0 (所有链), "reason": "...", "created_by": "..."}
+func (a *AdminServer) addAddressListEntry(w http.ResponseWriter, r *http.Request) {
+	var body ledger.AddressListEntry
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	entry, err := a.service.AddAddressListEntry(r.Context(), body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// removeAddressListEntry DELETE /address-lists/{list}/{address}?user_id=&chain_id=
+func (a *AdminServer) removeAddressListEntry(w http.ResponseWriter, r *http.Request) {
+	var chainID uint64
+	if v := r.URL.Query().Get("chain_id"); v != "" {
+		var err error
+		if chainID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid chain_id: %s", v))
+			return
+		}
+	}
+	err := a.service.RemoveAddressListEntry(r.Context(), r.PathValue("list"), r.URL.Query().Get("user_id"), chainID, r.PathValue("address"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listSigningAudit GET /audit/signing?user_id=&job_id=&chain_id=&key_id=&from=&to=&after_id=&limit=
+// 签名审计日志 (按 ID 顺序，用上一页最后一条的 id 作为 after_id 翻页)
+func (a *AdminServer) listSigningAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := ledger.SigningQuery{UserID: q.Get("user_id"), JobID: q.Get("job_id"), KeyID: q.Get("key_id"), Limit: 100}
+	if v := q.Get("chain_id"); v != "" {
+		chainID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid chain_id: %s", v))
+			return
+		}
+		query.ChainID = chainID
+	}
+	if v := q.Get("after_id"); v != "" {
+		afterID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || afterID < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid after_id: %s", v))
+			return
+		}
+		query.AfterID = afterID
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > 1000 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %s (1-1000)", v))
+			return
+		}
+		query.Limit = limit
+	}
+	var err error
+	if query.From, err = parseTimeParam(q.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from: %v", err))
+		return
+	}
+	if query.To, err = parseTimeParam(q.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid to: %v", err))
+		return
+	}
+
+	records, err := a.service.SigningRecords(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"records": records})
+}
+
+// verifySigningAudit GET /audit/signing/verify 从头校验签名审计日志的哈希链
+func (a *AdminServer) verifySigningAudit(w http.ResponseWriter, r *http.Request) {
+	check, err := a.service.VerifySigningLog(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, check)
+}
+
+// getGasAnalytics GET /analytics/gas?user_id=&chain_id=&from=&to=&interval=day|week|month
+// 各链网络费随时间的变化、每笔支付的平均成本和合并交易的节省额
+func (a *AdminServer) getGasAnalytics(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := service.GasAnalyticsQuery{UserID: q.Get("user_id"), Interval: q.Get("interval")}
+	if v := q.Get("chain_id"); v != "" {
+		chainID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid chain_id: %s", v))
+			return
+		}
+		query.ChainID = chainID
+	}
+	var err error
+	if query.From, err = parseTimeParam(q.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from: %v", err))
+		return
+	}
+	if query.To, err = parseTimeParam(q.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid to: %v", err))
+		return
+	}
+
+	report, err := a.service.GasAnalytics(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// getRPCStatus GET /rpc 各链节点延迟、错误预算和下线状态
+func (a *AdminServer) getRPCStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"chains": a.service.RPCStatus()})
+}
+
+// listSigningKeys GET /keys 各链签名密钥的当前版本和过渡期内的旧版本
+func (a *AdminServer) listSigningKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"chains": a.service.SigningKeys()})
+}
+
+// getSignerHealth GET /keys/health 最近一次签名器健康检查结果
+func (a *AdminServer) getSignerHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"healthy": a.service.SignersHealthy(), "signers": a.service.SignerHealth()})
+}
+
+// listCircuits GET /circuits 熔断中的链
+func (a *AdminServer) listCircuits(w http.ResponseWriter, r *http.Request) {
+	circuits, err := a.service.ChainCircuits(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if circuits == nil {
+		circuits = []*queue.Circuit{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"circuits": circuits})
+}
+
+// getQueueBacklog GET /queue/backlog 队列积压: 待处理任务数、最久任务的等待时间和按链统计
+func (a *AdminServer) getQueueBacklog(w http.ResponseWriter, r *http.Request) {
+	backlog, err := a.service.QueueBacklog(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, backlog)
+}
+
+// getMetrics GET /metrics 队列积压和出款流水线 (任务、广播失败、签名耗时、gas、nonce 重置) 的 Prometheus 指标
+// (只有计数和耗时，不需认证以便抓取)
+func (a *AdminServer) getMetrics(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := a.service.WriteQueueMetrics(r.Context(), &buf); err != nil {
+		// 队列不可读时仍导出进程内指标 (此时最需要告警)
+		log.Warn().Err(err).Msg("Failed to read queue backlog for metrics")
+	}
+	metrics.Write(&buf)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+// listTronBatches GET /tron/batches 本实例最近处理的 TRON 批次: 并发中的任务数、已广播、成功、失败和燃烧的 TRX
+func (a *AdminServer) listTronBatches(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"batches": a.service.TronBatches()})
+}
+
+// pauseChain POST /chains/{chain_id}/pause  body: {"reason": "..."} (可选)
+func (a *AdminServer) pauseChain(w http.ResponseWriter, r *http.Request) {
+	chainID, err := strconv.ParseUint(r.PathValue("chain_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid chain_id")
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	circuit, err := a.service.PauseChain(r.Context(), chainID, body.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, circuit)
+}
+
+// resumeChain POST /chains/{chain_id}/resume 恢复链并放回暂存的任务
+func (a *AdminServer) resumeChain(w http.ResponseWriter, r *http.Request) {
+	chainID, err := strconv.ParseUint(r.PathValue("chain_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid chain_id")
+		return
+	}
+	released, err := a.service.ResumeChain(r.Context(), chainID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"chain_id": chainID, "released": released})
+}
+
+// reloadChains POST /chains/reload 重新加载链配置 (同 SIGHUP)
+func (a *AdminServer) reloadChains(w http.ResponseWriter, r *http.Request) {
+	result, err := a.service.ReloadChains(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// getTreasuryAllowance GET /chains/{chain_id}/treasury/allowance?token=0x... 金库对付款签名地址的授权额度和金库余额
+func (a *AdminServer) getTreasuryAllowance(w http.ResponseWriter, r *http.Request) {
+	chainID, err := strconv.ParseUint(r.PathValue("chain_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid chain_id")
+		return
+	}
+	allowance, err := a.service.GetTreasuryAllowance(r.Context(), chainID, r.URL.Query().Get("token"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, allowance)
+}
+
+// requestTreasuryApproval POST /chains/{chain_id}/treasury/approvals  body: {"token": "0x...", "amount": "...", "reason": "..."}
+// 返回金库管理方需执行的 approve 调用 (to / data)
+func (a *AdminServer) requestTreasuryApproval(w http.ResponseWriter, r *http.Request) {
+	chainID, err := strconv.ParseUint(r.PathValue("chain_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid chain_id")
+		return
+	}
+	var body struct {
+		Token  string `json:"token"`
+		Amount string `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	approval, err := a.service.RequestTreasuryApproval(r.Context(), chainID, body.Token, body.Amount, body.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, approval)
+}
+
+// listTreasuryApprovals GET /treasury/approvals?chain_id= 金库授权请求 (等待中、已执行、被取代)
+func (a *AdminServer) listTreasuryApprovals(w http.ResponseWriter, r *http.Request) {
+	var chainID uint64
+	if v := r.URL.Query().Get("chain_id"); v != "" {
+		var err error
+		if chainID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid chain_id: %s", v))
+			return
+		}
+	}
+	approvals, err := a.service.ListTreasuryApprovals(r.Context(), chainID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"approvals": approvals})
+}
+
+// x402Submission SDK X402Module / relayer client 提交的 EIP-3009 授权 (字段沿用其 camelCase 命名)
+type x402Submission struct {
+	ChainID     uint64 `json:"chainId"`
+	Token       string `json:"token"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	ValidAfter  int64  `json:"validAfter"`
+	ValidBefore int64  `json:"validBefore"`
+	Nonce       string `json:"nonce"`
+	Signature   string `json:"signature"`
+}
+
+// x402Forward 付款方签名的 ERC-2771 转发请求 (to 为代币合约，data 为 transfer 调用)
+type x402Forward struct {
+	ChainID   uint64 `json:"chainId"`
+	Forwarder string `json:"forwarder"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     string `json:"value"`
+	Gas       uint64 `json:"gas"`
+	Deadline  int64  `json:"deadline"`
+	Data      string `json:"data"`
+	Signature string `json:"signature"`
+}
+
+// x402Response 中继任务状态 (transactionHash 在交易广播后返回)
+type x402Response struct {
+	Success         bool           `json:"success"`
+	JobID           string         `json:"jobId"`
+	Status          queue.JobState `json:"status"`
+	TransactionHash string         `json:"transactionHash,omitempty"`
+	BlockNumber     uint64         `json:"blockNumber,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	Replayed        bool           `json:"replayed,omitempty"`
+}
+
+func newX402Response(job *queue.JobStatus) x402Response {
+	return x402Response{
+		Success:         job.State != queue.JobStateFailed && job.State != queue.JobStateCancelled,
+		JobID:           job.ID,
+		Status:          job.State,
+		TransactionHash: job.TxHash,
+		BlockNumber:     job.BlockNumber,
+		Error:           job.Error,
+	}
+}
+
+// submitX402 POST /x402/submit 校验并中继 EIP-3009 授权，返回任务 ID (同一授权重复提交返回已有任务)
+func (a *AdminServer) submitX402(w http.ResponseWriter, r *http.Request) {
+	var body x402Submission
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	result, err := a.service.RelayAuthorization(r.Context(), &service.RelayAuthorizationRequest{
+		ChainID:     body.ChainID,
+		Token:       body.Token,
+		From:        body.From,
+		To:          body.To,
+		Value:       body.Value,
+		ValidAfter:  body.ValidAfter,
+		ValidBefore: body.ValidBefore,
+		Nonce:       body.Nonce,
+		Signature:   body.Signature,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeRelayResult(w, result)
+}
+
+// writeRelayResult 新入队返回 202，重复提交返回 200 和已有任务
+func writeRelayResult(w http.ResponseWriter, result *service.RelayAuthorizationResult) {
+	resp := newX402Response(result.Job)
+	resp.Replayed = result.Replayed
+	code := http.StatusAccepted
+	if result.Replayed {
+		code = http.StatusOK
+	}
+	writeJSON(w, code, resp)
+}
+
+// forwardX402 POST /x402/forward 校验并经可信转发合约中继代币转账 (不支持 EIP-3009 的代币)
+func (a *AdminServer) forwardX402(w http.ResponseWriter, r *http.Request) {
+	var body x402Forward
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	result, err := a.service.RelayForward(r.Context(), &service.RelayForwardRequest{
+		ChainID:   body.ChainID,
+		Forwarder: body.Forwarder,
+		From:      body.From,
+		Token:     body.To,
+		Value:     body.Value,
+		Gas:       body.Gas,
+		Deadline:  body.Deadline,
+		Data:      body.Data,
+		Signature: body.Signature,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeRelayResult(w, result)
+}
+
+// getX402 GET /x402/{id} 中继任务 (EIP-3009 或 ERC-2771) 的状态和交易哈希
+func (a *AdminServer) getX402(w http.ResponseWriter, r *http.Request) {
+	job, err := a.service.AuthorizationStatus(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newX402Response(job))
+}
+
+// parseJobQuery 解析通用过滤和分页参数。时间参数支持 RFC3339 或 Unix 秒。
+func parseJobQuery(r *http.Request) (service.JobFilter, int, int, error) {
+	q := r.URL.Query()
+	filter := service.JobFilter{UserID: q.Get("user_id")}
+
+	if v := q.Get("chain_id"); v != "" {
+		chainID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, 0, 0, fmt.Errorf("invalid chain_id: %s", v)
+		}
+		filter.ChainID = chainID
+	}
+	if v := q.Get("status"); v != "" {
+		state := queue.JobState(v)
+		if _, ok := jobStates[state]; !ok {
+			return filter, 0, 0, fmt.Errorf("invalid status: %s", v)
+		}
+		filter.State = state
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(q.Get("from")); err != nil {
+		return filter, 0, 0, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseTimeParam(q.Get("to")); err != nil {
+		return filter, 0, 0, fmt.Errorf("invalid to: %w", err)
+	}
+
+	offset, limit := 0, 100
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return filter, 0, 0, fmt.Errorf("invalid offset: %s", v)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 500 {
+			return filter, 0, 0, fmt.Errorf("invalid limit: %s (1-500)", v)
+		}
+	}
+	return filter, offset, limit, nil
+}
+
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func nonNilJobs(jobs []*queue.JobStatus) []*queue.JobStatus {
+	if jobs == nil {
+		return []*queue.JobStatus{}
+	}
+	return jobs
+}
+
+// writeServiceError 服务层错误映射为 HTTP 状态码 (与 toStatus 对应)
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case service.IsInvalidArgument(err):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case service.IsFailedPrecondition(err):
+		writeError(w, http.StatusConflict, err.Error())
+	case service.IsUnavailable(err):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, queue.ErrBatchNotFound), errors.Is(err, service.ErrJobNotFound), errors.Is(err, service.ErrAddressListEntryNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		log.Error().Err(err).Msg("Admin request failed")
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn().Err(err).Msg("Failed to write admin response")
+	}
+}
@@ -0,0 +1,238 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// Signing methods recorded in the audit trail.
+const (
+	AuditMethodSignHash        = "sign_hash"
+	AuditMethodSignTransaction = "sign_transaction"
+)
+
+// Caller identifies who requested a signature. It travels in the context
+// passed to SignHash and SignTransaction (see WithCaller).
+type Caller struct {
+	Requestor     string `json:"requestor,omitempty"` // tenant or component that asked for the signature
+	UserID        string `json:"user_id,omitempty"`
+	BatchID       string `json:"batch_id,omitempty"`
+	JobID         string `json:"job_id,omitempty"`
+	Purpose       string `json:"purpose,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying c for the audit trail.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// CallerFromContext returns the caller attached by WithCaller, if any.
+func CallerFromContext(ctx context.Context) Caller {
+	c, _ := ctx.Value(callerKey{}).(Caller)
+	return c
+}
+
+// AuditRecord is one call to a signing provider.
+type AuditRecord struct {
+	Provider  string         `json:"provider"`
+	KeyPath   string         `json:"key_path"` // provider key reference, e.g. "fireblocks:vault/3/ETH"
+	Address   common.Address `json:"address"`
+	Method    string         `json:"method"`
+	Digest    string         `json:"digest"`             // hex digest that was signed
+	ChainID   uint64         `json:"chain_id,omitempty"` // sign_transaction only
+	Caller    Caller         `json:"caller"`
+	StartedAt time.Time      `json:"started_at"`
+	Latency   time.Duration  `json:"latency_ns"`
+	Success   bool           `json:"success"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// AuditSink stores audit records.
+type AuditSink interface {
+	RecordAudit(ctx context.Context, r AuditRecord) error
+}
+
+// AuditConfig selects where signing calls are recorded.
+type AuditConfig struct {
+	Sink AuditSink // nil disables the audit trail
+
+	// Required refuses to hand out a signature whose record could not be
+	// stored; otherwise sink failures are only logged.
+	Required bool
+}
+
+// MultiAuditSink records to every sink and joins their errors.
+type MultiAuditSink []AuditSink
+
+// RecordAudit implements AuditSink.
+func (m MultiAuditSink) RecordAudit(ctx context.Context, r AuditRecord) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.RecordAudit(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// JSONAuditSink writes one JSON object per line, e.g. to stdout for a log shipper.
+type JSONAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditSink writes records to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{enc: json.NewEncoder(w)}
+}
+
+// RecordAudit implements AuditSink.
+func (s *JSONAuditSink) RecordAudit(_ context.Context, r AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// WebhookAuditSink posts each record to a SIEM collector, signed like the
+// engine's other outbound webhooks.
+type WebhookAuditSink struct {
+	sender *webhook.Sender
+	url    string
+	secret string
+}
+
+// NewWebhookAuditSink posts records to url.
+func NewWebhookAuditSink(url, secret string, timeout time.Duration) *WebhookAuditSink {
+	return &WebhookAuditSink{sender: webhook.NewSender(timeout), url: url, secret: secret}
+}
+
+// RecordAudit implements AuditSink.
+func (s *WebhookAuditSink) RecordAudit(ctx context.Context, r AuditRecord) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	eventID := fmt.Sprintf("kms.sign:%s:%s:%d", r.Address.Hex(), r.Digest, r.StartedAt.UnixNano())
+	return s.sender.Post(ctx, s.url, s.secret, eventID, body)
+}
+
+// AuditedSigner records every call to the wrapped signer.
+type AuditedSigner struct {
+	signer  Signer
+	keyPath string
+	cfg     AuditConfig
+}
+
+// NewAuditedSigner wraps signer; keyPath identifies the key in the records.
+func NewAuditedSigner(signer Signer, keyPath string, cfg AuditConfig) *AuditedSigner {
+	return &AuditedSigner{signer: signer, keyPath: keyPath, cfg: cfg}
+}
+
+// keyPath describes the key selected by cfg for the audit trail.
+func keyPath(cfg Config, address common.Address) string {
+	switch cfg.Provider {
+	case ProviderFireblocks:
+		return fmt.Sprintf("fireblocks:vault/%s/%s", cfg.Fireblocks.VaultAccountID, cfg.Fireblocks.AssetID)
+	case ProviderMPC:
+		return "mpc:" + cfg.MPC.KeyID
+	default:
+		return "local:" + address.Hex()
+	}
+}
+
+func (a *AuditedSigner) record(ctx context.Context, method string, digest []byte, chainID *big.Int, started time.Time, signErr error) error {
+	r := AuditRecord{
+		Provider:  a.signer.Provider(),
+		KeyPath:   a.keyPath,
+		Address:   a.signer.Address(),
+		Method:    method,
+		Digest:    hexutil.Encode(digest),
+		Caller:    CallerFromContext(ctx),
+		StartedAt: started,
+		Latency:   time.Since(started),
+		Success:   signErr == nil,
+	}
+	if chainID != nil {
+		r.ChainID = chainID.Uint64()
+	}
+	if signErr != nil {
+		r.Error = signErr.Error()
+	}
+	// Signing failures are often context timeouts; record them regardless.
+	err := a.cfg.Sink.RecordAudit(context.WithoutCancel(ctx), r)
+	if err == nil {
+		return nil
+	}
+	log.Error().Err(err).Str("address", r.Address.Hex()).Str("digest", r.Digest).Msg("Failed to record KMS signing audit")
+	if a.cfg.Required && signErr == nil {
+		return fmt.Errorf("signature discarded: audit record not stored: %w", err)
+	}
+	return nil
+}
+
+// Address implements Signer.
+func (a *AuditedSigner) Address() common.Address {
+	return a.signer.Address()
+}
+
+// SignHash implements Signer.
+func (a *AuditedSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	started := time.Now()
+	sig, err := a.signer.SignHash(ctx, hash)
+	if auditErr := a.record(ctx, AuditMethodSignHash, hash, nil, started, err); auditErr != nil {
+		return nil, auditErr
+	}
+	return sig, err
+}
+
+// SignTransaction implements Signer.
+func (a *AuditedSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	started := time.Now()
+	signed, err := a.signer.SignTransaction(ctx, tx, chainID)
+	digest := types.LatestSignerForChainID(chainID).Hash(tx)
+	if auditErr := a.record(ctx, AuditMethodSignTransaction, digest.Bytes(), chainID, started, err); auditErr != nil {
+		return nil, auditErr
+	}
+	return signed, err
+}
+
+// SignHashes implements BatchSigner with one record per digest.
+func (a *AuditedSigner) SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error) {
+	started := time.Now()
+	sigs, err := SignHashes(ctx, a.signer, hashes)
+	var auditErr error
+	for _, hash := range hashes {
+		if recordErr := a.record(ctx, AuditMethodSignHash, hash, nil, started, err); recordErr != nil && auditErr == nil {
+			auditErr = recordErr
+		}
+	}
+	if auditErr != nil {
+		return nil, auditErr
+	}
+	return sigs, err
+}
+
+// Health implements Signer.
+func (a *AuditedSigner) Health(ctx context.Context) error {
+	return a.signer.Health(ctx)
+}
+
+// Provider implements Signer.
+func (a *AuditedSigner) Provider() string {
+	return a.signer.Provider()
+}
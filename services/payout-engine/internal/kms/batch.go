@@ -0,0 +1,330 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultMaxBatchSize caps the digests per provider request when
+// BatchConfig.MaxBatchSize is unset.
+const defaultMaxBatchSize = 50
+
+// BatchSigner is implemented by signers that can sign several digests with
+// fewer provider round trips than one SignHash call each.
+type BatchSigner interface {
+	// SignHashes signs 32-byte digests and returns the signatures in the
+	// same order, in the format of SignHash.
+	SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error)
+}
+
+// BatchConfig tunes how the signatures of a key reach its provider.
+type BatchConfig struct {
+	// Concurrency caps the provider requests in flight across every key of
+	// the same provider in the process; 0 means unlimited.
+	Concurrency int
+
+	// MaxBatchSize caps the digests per request for providers that sign
+	// several at once (default 50).
+	MaxBatchSize int
+
+	// CoalesceWindow holds SignHash calls this long so that concurrent
+	// calls share one provider request; 0 disables coalescing. It only
+	// applies to providers that sign several digests per request.
+	CoalesceWindow time.Duration
+}
+
+// SignHashes signs hashes with s: through its batch support when it has
+// any, otherwise with one SignHash call per digest in parallel.
+func SignHashes(ctx context.Context, s Signer, hashes [][]byte) ([][]byte, error) {
+	if b, ok := s.(BatchSigner); ok {
+		return b.SignHashes(ctx, hashes)
+	}
+	sigs := make([][]byte, len(hashes))
+	err := parallel(len(hashes), func(i int) error {
+		sig, err := s.SignHash(ctx, hashes[i])
+		sigs[i] = sig
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sigs, nil
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]chan struct{})
+)
+
+// providerLimiter returns the semaphore shared by the keys of provider. The
+// first key created with a limit sets its size.
+func providerLimiter(provider string, n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	sem, ok := limiters[provider]
+	if !ok {
+		sem = make(chan struct{}, n)
+		limiters[provider] = sem
+	}
+	return sem
+}
+
+// BatchedSigner sits between a provider signer and the rest of the engine.
+// It bounds the provider requests in flight, removes duplicate digests,
+// splits SignHashes into provider-sized requests run in parallel and, when
+// configured, coalesces concurrent SignHash calls into shared requests.
+type BatchedSigner struct {
+	signer  Signer
+	batcher BatchSigner // signer's batch support; nil when it signs one digest per request
+	cfg     BatchConfig
+	sem     chan struct{} // nil when unlimited
+
+	mu         sync.Mutex
+	pending    []*pendingSignature
+	pendingCtx context.Context
+	timer      *time.Timer
+}
+
+type pendingSignature struct {
+	hash []byte
+	sig  []byte
+	err  error
+	done chan struct{}
+}
+
+// NewBatchedSigner wraps signer with the settings in cfg. Its concurrency
+// limit is private to the returned signer; keys created by NewSigner share
+// one limit per provider.
+func NewBatchedSigner(signer Signer, cfg BatchConfig) *BatchedSigner {
+	var sem chan struct{}
+	if cfg.Concurrency > 0 {
+		sem = make(chan struct{}, cfg.Concurrency)
+	}
+	return newBatchedSigner(signer, cfg, sem)
+}
+
+func newBatchedSigner(signer Signer, cfg BatchConfig, sem chan struct{}) *BatchedSigner {
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultMaxBatchSize
+	}
+	b := &BatchedSigner{signer: signer, cfg: cfg, sem: sem}
+	b.batcher, _ = signer.(BatchSigner)
+	return b
+}
+
+func (b *BatchedSigner) acquire(ctx context.Context) error {
+	if b.sem == nil {
+		return nil
+	}
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a %s signing slot: %w", b.signer.Provider(), ctx.Err())
+	}
+}
+
+func (b *BatchedSigner) release() {
+	if b.sem != nil {
+		<-b.sem
+	}
+}
+
+func (b *BatchedSigner) coalescing() bool {
+	return b.batcher != nil && b.cfg.CoalesceWindow > 0
+}
+
+// Address implements Signer.
+func (b *BatchedSigner) Address() common.Address {
+	return b.signer.Address()
+}
+
+// SignHash implements Signer.
+func (b *BatchedSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	if !b.coalescing() {
+		if err := b.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer b.release()
+		return b.signer.SignHash(ctx, hash)
+	}
+	// Reject bad input here so it cannot fail the callers it would share a request with.
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+	p := b.enqueue(ctx, hash)
+	select {
+	case <-p.done:
+		return p.sig, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// enqueue adds hash to the pending request, which is sent when the
+// coalescing window ends or the request is full.
+func (b *BatchedSigner) enqueue(ctx context.Context, hash []byte) *pendingSignature {
+	p := &pendingSignature{hash: hash, done: make(chan struct{})}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, p)
+	switch {
+	case len(b.pending) >= b.cfg.MaxBatchSize:
+		go b.flush(b.takePending())
+	case len(b.pending) == 1:
+		// The request outlives any one caller; keep the first caller's values only.
+		b.pendingCtx = context.WithoutCancel(ctx)
+		b.timer = time.AfterFunc(b.cfg.CoalesceWindow, func() {
+			b.mu.Lock()
+			batch := b.takePending()
+			b.mu.Unlock()
+			b.flush(batch)
+		})
+	}
+	return p
+}
+
+type pendingBatch struct {
+	ctx     context.Context
+	waiters []*pendingSignature
+}
+
+// takePending detaches the pending request. b.mu must be held.
+func (b *BatchedSigner) takePending() pendingBatch {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch := pendingBatch{ctx: b.pendingCtx, waiters: b.pending}
+	b.pending, b.pendingCtx = nil, nil
+	return batch
+}
+
+func (b *BatchedSigner) flush(batch pendingBatch) {
+	if len(batch.waiters) == 0 {
+		return
+	}
+	hashes := make([][]byte, len(batch.waiters))
+	for i, p := range batch.waiters {
+		hashes[i] = p.hash
+	}
+	sigs, err := b.SignHashes(batch.ctx, hashes)
+	for i, p := range batch.waiters {
+		if err != nil {
+			p.err = err
+		} else {
+			p.sig = sigs[i]
+		}
+		close(p.done)
+	}
+}
+
+// SignHashes implements BatchSigner. Identical digests are signed once.
+func (b *BatchedSigner) SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error) {
+	index := make(map[string]int, len(hashes))
+	positions := make([]int, len(hashes))
+	var unique [][]byte
+	for i, hash := range hashes {
+		pos, ok := index[string(hash)]
+		if !ok {
+			pos = len(unique)
+			index[string(hash)] = pos
+			unique = append(unique, hash)
+		}
+		positions[i] = pos
+	}
+
+	sigs := make([][]byte, len(unique))
+	var err error
+	if b.batcher != nil {
+		chunks := (len(unique) + b.cfg.MaxBatchSize - 1) / b.cfg.MaxBatchSize
+		err = parallel(chunks, func(i int) error {
+			start := i * b.cfg.MaxBatchSize
+			end := min(start+b.cfg.MaxBatchSize, len(unique))
+			if err := b.acquire(ctx); err != nil {
+				return err
+			}
+			defer b.release()
+			chunk, err := b.batcher.SignHashes(ctx, unique[start:end])
+			if err != nil {
+				return err
+			}
+			copy(sigs[start:end], chunk)
+			return nil
+		})
+	} else {
+		err = parallel(len(unique), func(i int) error {
+			if err := b.acquire(ctx); err != nil {
+				return err
+			}
+			defer b.release()
+			sig, err := b.signer.SignHash(ctx, unique[i])
+			sigs[i] = sig
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]byte, len(hashes))
+	for i, pos := range positions {
+		out[i] = sigs[pos]
+	}
+	return out, nil
+}
+
+// SignTransaction implements Signer. With coalescing the transaction digest
+// joins the pending request like any other SignHash call.
+func (b *BatchedSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if b.coalescing() {
+		return signTxWithHash(ctx, b, tx, chainID)
+	}
+	if err := b.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer b.release()
+	return b.signer.SignTransaction(ctx, tx, chainID)
+}
+
+// Health implements Signer.
+func (b *BatchedSigner) Health(ctx context.Context) error {
+	return b.signer.Health(ctx)
+}
+
+// Provider implements Signer.
+func (b *BatchedSigner) Provider() string {
+	return b.signer.Provider()
+}
+
+// parallel runs fn(0..n-1) concurrently and returns the first error.
+func parallel(n int, fn func(i int) error) error {
+	if n == 1 {
+		return fn(0)
+	}
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultFireblocksURL = "https://api.fireblocks.io"
+
+// Fireblocks transaction statuses that end the signing flow.
+// Intermediate statuses such as PENDING_AUTHORIZATION are polled through so
+// workspace approval policies and the API Co-Signer can act on the request.
+var fireblocksFailedStatuses = map[string]bool{
+	"FAILED":    true,
+	"REJECTED":  true,
+	"BLOCKED":   true,
+	"CANCELLED": true,
+	"TIMEOUT":   true,
+}
+
+// FireblocksSigner signs digests through the Fireblocks raw signing API.
+// The key never leaves the Fireblocks MPC vault.
+type FireblocksSigner struct {
+	cfg        FireblocksConfig
+	secretKey  *rsa.PrivateKey
+	address    common.Address
+	httpClient *http.Client
+}
+
+// NewFireblocksSigner creates a Fireblocks signer and resolves the vault address.
+func NewFireblocksSigner(ctx context.Context, cfg FireblocksConfig) (*FireblocksSigner, error) {
+	if cfg.APIKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("fireblocks api key and secret key are required")
+	}
+	if cfg.VaultAccountID == "" {
+		return nil, fmt.Errorf("fireblocks vault account id is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultFireblocksURL
+	}
+	if cfg.AssetID == "" {
+		cfg.AssetID = "ETH"
+	}
+	if cfg.SignTimeout <= 0 {
+		cfg.SignTimeout = 2 * time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+
+	secretKey, err := parseRSAPrivateKey(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fireblocks secret key: %w", err)
+	}
+
+	s := &FireblocksSigner{
+		cfg:        cfg,
+		secretKey:  secretKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if cfg.Address != "" {
+		if !common.IsHexAddress(cfg.Address) {
+			return nil, fmt.Errorf("invalid fireblocks address: %s", cfg.Address)
+		}
+		s.address = common.HexToAddress(cfg.Address)
+	} else {
+		addr, err := s.fetchVaultAddress(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve fireblocks vault address: %w", err)
+		}
+		s.address = addr
+	}
+
+	log.Info().
+		Str("vault_account", cfg.VaultAccountID).
+		Str("asset", cfg.AssetID).
+		Str("address", s.address.Hex()).
+		Msg("Fireblocks signer initialized")
+
+	return s, nil
+}
+
+// Address implements Signer.
+func (s *FireblocksSigner) Address() common.Address {
+	return s.address
+}
+
+// Provider implements Signer.
+func (s *FireblocksSigner) Provider() string {
+	return ProviderFireblocks
+}
+
+// Health implements Signer by resolving the vault address again, which
+// checks the API credentials and that the vault account still holds the key.
+func (s *FireblocksSigner) Health(ctx context.Context) error {
+	addr, err := s.fetchVaultAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("fireblocks vault %s unreachable: %w", s.cfg.VaultAccountID, err)
+	}
+	if addr != s.address {
+		return fmt.Errorf("fireblocks vault %s now controls %s, expected %s", s.cfg.VaultAccountID, addr.Hex(), s.address.Hex())
+	}
+	return nil
+}
+
+// SignTransaction implements Signer by raw-signing the transaction sighash.
+func (s *FireblocksSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return signTxWithHash(ctx, s, tx, chainID)
+}
+
+// SignHash implements Signer.
+func (s *FireblocksSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	sigs, err := s.SignHashes(ctx, [][]byte{hash})
+	if err != nil {
+		return nil, err
+	}
+	return sigs[0], nil
+}
+
+// SignHashes implements BatchSigner. All digests go into one raw signing
+// request, so they share a single policy approval and poll loop.
+func (s *FireblocksSigner) SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	for _, hash := range hashes {
+		if len(hash) != 32 {
+			return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.SignTimeout)
+	defer cancel()
+
+	txID, err := s.createRawSignRequest(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("fireblocks signing %s timed out: %w", txID, ctx.Err())
+		case <-ticker.C:
+			fbTx, err := s.getTransaction(ctx, txID)
+			if err != nil {
+				log.Debug().Err(err).Str("fireblocks_tx", txID).Msg("Waiting for Fireblocks signature...")
+				continue
+			}
+			if fireblocksFailedStatuses[fbTx.Status] {
+				return nil, fmt.Errorf("fireblocks signing %s ended with status %s (%s)", txID, fbTx.Status, fbTx.SubStatus)
+			}
+			if fbTx.Status != "COMPLETED" {
+				continue
+			}
+			if len(fbTx.SignedMessages) == 0 {
+				return nil, fmt.Errorf("fireblocks signing %s completed without signature", txID)
+			}
+			return matchSignedMessages(txID, s.address, hashes, fbTx.SignedMessages)
+		}
+	}
+}
+
+// matchSignedMessages orders the signatures like hashes. Fireblocks echoes
+// each message's content, which is matched rather than relying on order.
+func matchSignedMessages(txID string, address common.Address, hashes [][]byte, messages []fireblocksSignedMessage) ([][]byte, error) {
+	byContent := make(map[string]fireblocksSignature, len(messages))
+	for _, m := range messages {
+		byContent[strings.ToLower(strings.TrimPrefix(m.Content, "0x"))] = m.Signature
+	}
+	sigs := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		sig, ok := byContent[hex.EncodeToString(hash)]
+		if !ok {
+			return nil, fmt.Errorf("fireblocks signing %s completed without signature for %x", txID, hash)
+		}
+		raw, err := sig.recoverable(hash, address)
+		if err != nil {
+			return nil, fmt.Errorf("fireblocks signing %s: %w", txID, err)
+		}
+		sigs[i] = raw
+	}
+	return sigs, nil
+}
+
+type fireblocksTransaction struct {
+	ID             string                    `json:"id"`
+	Status         string                    `json:"status"`
+	SubStatus      string                    `json:"subStatus"`
+	SignedMessages []fireblocksSignedMessage `json:"signedMessages"`
+}
+
+type fireblocksSignedMessage struct {
+	Content   string              `json:"content"`
+	Signature fireblocksSignature `json:"signature"`
+}
+
+type fireblocksSignature struct {
+	FullSig string `json:"fullSig"`
+	R       string `json:"r"`
+	S       string `json:"s"`
+}
+
+// recoverable converts the signature of hash to 65-byte [R || S || V] form
+// (see recoverableSignature). The recovery ID is derived rather than taken
+// from Fireblocks, which reports it for the S value it returned.
+func (sig fireblocksSignature) recoverable(hash []byte, address common.Address) ([]byte, error) {
+	full := sig.FullSig
+	if full == "" {
+		full = sig.R + sig.S
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(full, "0x"))
+	if err != nil || len(raw) != 64 {
+		return nil, fmt.Errorf("malformed fireblocks signature")
+	}
+	return recoverableSignature(hash, new(big.Int).SetBytes(raw[:32]), new(big.Int).SetBytes(raw[32:]), address)
+}
+
+func (s *FireblocksSigner) createRawSignRequest(ctx context.Context, hashes [][]byte) (string, error) {
+	messages := make([]map[string]string, len(hashes))
+	for i, hash := range hashes {
+		messages[i] = map[string]string{"content": hex.EncodeToString(hash)}
+	}
+	body := map[string]interface{}{
+		"operation": "RAW",
+		"assetId":   s.cfg.AssetID,
+		"source": map[string]string{
+			"type": "VAULT_ACCOUNT",
+			"id":   s.cfg.VaultAccountID,
+		},
+		"note": "payout-engine raw signing",
+		"extraParameters": map[string]interface{}{
+			"rawMessageData": map[string]interface{}{
+				"messages": messages,
+			},
+		},
+	}
+
+	var resp struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/transactions", body, &resp); err != nil {
+		return "", fmt.Errorf("fireblocks raw signing request failed: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("fireblocks returned empty transaction id")
+	}
+	return resp.ID, nil
+}
+
+func (s *FireblocksSigner) getTransaction(ctx context.Context, id string) (*fireblocksTransaction, error) {
+	var tx fireblocksTransaction
+	if err := s.do(ctx, http.MethodGet, "/v1/transactions/"+id, nil, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (s *FireblocksSigner) fetchVaultAddress(ctx context.Context) (common.Address, error) {
+	var addrs []struct {
+		Address string `json:"address"`
+	}
+	path := fmt.Sprintf("/v1/vault/accounts/%s/%s/addresses", s.cfg.VaultAccountID, s.cfg.AssetID)
+	if err := s.do(ctx, http.MethodGet, path, nil, &addrs); err != nil {
+		return common.Address{}, err
+	}
+	if len(addrs) == 0 || !common.IsHexAddress(addrs[0].Address) {
+		return common.Address{}, fmt.Errorf("no EVM address in vault account %s", s.cfg.VaultAccountID)
+	}
+	return common.HexToAddress(addrs[0].Address), nil
+}
+
+// do performs an authenticated Fireblocks API call.
+func (s *FireblocksSigner) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	token, err := s.signJWT(path, payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", s.cfg.APIKey)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("fireblocks %s %s: status %d: %s", method, path, resp.StatusCode, string(respBody))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode fireblocks response: %w", err)
+		}
+	}
+	return nil
+}
+
+// signJWT builds the RS256 bearer token Fireblocks requires on every request.
+func (s *FireblocksSigner) signJWT(uri string, body []byte) (string, error) {
+	now := time.Now().Unix()
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	bodyHash := sha256.Sum256(body)
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"uri":      uri,
+		"nonce":    hex.EncodeToString(nonce),
+		"iat":      now,
+		"exp":      now + 55,
+		"sub":      s.cfg.APIKey,
+		"bodyHash": hex.EncodeToString(bodyHash[:]),
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.secretKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fireblocks jwt: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func parseRSAPrivateKey(pemData string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("secret key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,346 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Guard actions taken on an anomaly.
+const (
+	GuardActionAlert = "alert" // sign and report
+	GuardActionBlock = "block" // refuse to sign and report
+)
+
+// Anomaly kinds reported by GuardedSigner.
+const (
+	AnomalyRateLimit = "rate_limit" // MaxPerMinute reached; always blocked
+	AnomalySpike     = "spike"      // signing rate far above the hourly baseline
+	AnomalyOffHours  = "off_hours"  // signing outside business hours
+)
+
+// defaultSpikeMinimum is the per-minute count below which spikes are ignored
+// when GuardConfig.SpikeMinimum is unset.
+const defaultSpikeMinimum = 10
+
+// spikeMinHistory is the history needed before spikes are flagged; a baseline
+// over a few minutes after start-up is mostly noise.
+const spikeMinHistory = 10 * time.Minute
+
+// GuardConfig limits how fast a key signs so that a compromised worker cannot
+// drain the payout wallet at full speed. Zero values disable each check.
+type GuardConfig struct {
+	MaxPerMinute int     // Signatures allowed per rolling minute; further requests are refused
+	SpikeFactor  float64 // Anomaly when the last minute exceeds SpikeFactor × the per-minute average of the previous hour (or since start)
+	SpikeMinimum int     // Minimum signatures in the last minute before a spike is flagged (default 10)
+
+	// BusinessHours flags signatures outside the configured window.
+	BusinessHours *BusinessHours
+
+	// Action for spikes and off-hours signatures: "alert" (default) or "block".
+	Action string
+
+	// OnAnomaly is called for every reported anomaly, at most once per kind
+	// and key per minute. It must not block.
+	OnAnomaly func(Anomaly)
+}
+
+// Enabled reports whether any check is configured.
+func (c GuardConfig) Enabled() bool {
+	return c.MaxPerMinute > 0 || c.SpikeFactor > 0 || c.BusinessHours != nil
+}
+
+// BusinessHours is a daily signing window, e.g. 09:00-18:00 Monday to Friday.
+type BusinessHours struct {
+	Start    time.Duration  // offset from midnight
+	End      time.Duration  // offset from midnight; before Start for windows spanning midnight
+	Weekdays []time.Weekday // days the window applies; empty means every day
+	Location *time.Location // UTC when nil
+}
+
+// Contains reports whether t falls inside the window.
+func (b *BusinessHours) Contains(t time.Time) bool {
+	if b.Location != nil {
+		t = t.In(b.Location)
+	} else {
+		t = t.UTC()
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	day := t.Weekday()
+	inWindow := offset >= b.Start && offset < b.End
+	if b.End <= b.Start { // spans midnight: the early hours belong to the previous day's window
+		inWindow = offset >= b.Start || offset < b.End
+		if offset < b.End {
+			day = (day + 6) % 7
+		}
+	}
+	if !inWindow {
+		return false
+	}
+	if len(b.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range b.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseBusinessHours parses a window such as "09:00-18:00", a day list such
+// as "mon-fri" or "mon,wed,fri" (empty for every day) and an IANA time zone
+// (empty for UTC).
+func ParseBusinessHours(window, days, zone string) (*BusinessHours, error) {
+	start, end, ok := strings.Cut(window, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid business hours %q: expected HH:MM-HH:MM", window)
+	}
+	b := &BusinessHours{}
+	var err error
+	if b.Start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if b.End, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	if b.Start == b.End {
+		return nil, fmt.Errorf("invalid business hours %q: empty window", window)
+	}
+	for _, part := range strings.Split(strings.ToLower(days), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok1 := weekdays[first]
+		to, ok2 := weekdays[last]
+		if !isRange {
+			to, ok2 = from, ok1
+		}
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid business days %q", days)
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			b.Weekdays = append(b.Weekdays, d)
+			if d == to {
+				break
+			}
+		}
+	}
+	if zone != "" {
+		if b.Location, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid business hours time zone: %w", err)
+		}
+	}
+	return b, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	h, m, _ := strings.Cut(strings.TrimSpace(s), ":")
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	minute := 0
+	if m != "" {
+		if minute, err = strconv.Atoi(m); err != nil || minute < 0 || minute > 59 || (hour == 24 && minute > 0) {
+			return 0, fmt.Errorf("invalid time of day %q", s)
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// Anomaly is a signing pattern flagged by GuardedSigner.
+type Anomaly struct {
+	Kind     string         `json:"kind"`
+	Address  common.Address `json:"address"`
+	Provider string         `json:"provider"`
+	Count    int            `json:"count"`              // signatures in the last minute, including this request
+	Baseline float64        `json:"baseline,omitempty"` // spike: per-minute average of the previous hour, or since start
+	Blocked  bool           `json:"blocked"`            // the signature was refused
+	At       time.Time      `json:"at"`
+}
+
+func (a Anomaly) String() string {
+	switch a.Kind {
+	case AnomalyRateLimit:
+		return fmt.Sprintf("%d signatures in the last minute", a.Count)
+	case AnomalySpike:
+		return fmt.Sprintf("%d signatures in the last minute against a baseline of %.1f", a.Count, a.Baseline)
+	default:
+		return "signing outside business hours"
+	}
+}
+
+// GuardError is returned when the guard refuses to sign.
+type GuardError struct {
+	Anomaly Anomaly
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("signing blocked for %s: %s", e.Anomaly.Address.Hex(), e.Anomaly)
+}
+
+// GuardedSigner enforces a GuardConfig in front of another signer. Both
+// SignHash and SignTransaction count as one signature.
+type GuardedSigner struct {
+	signer Signer
+	cfg    GuardConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	started  time.Time            // first signing request; history before it is unknown
+	signed   []time.Time          // signatures of the last hour, oldest first
+	reported map[string]time.Time // last report per anomaly kind
+}
+
+// NewGuardedSigner wraps signer with the limits in cfg.
+func NewGuardedSigner(signer Signer, cfg GuardConfig) (*GuardedSigner, error) {
+	switch cfg.Action {
+	case "":
+		cfg.Action = GuardActionAlert
+	case GuardActionAlert, GuardActionBlock:
+	default:
+		return nil, fmt.Errorf("unknown signing guard action: %q (expected alert or block)", cfg.Action)
+	}
+	if cfg.SpikeMinimum <= 0 {
+		cfg.SpikeMinimum = defaultSpikeMinimum
+	}
+	return &GuardedSigner{signer: signer, cfg: cfg, now: time.Now, reported: make(map[string]time.Time)}, nil
+}
+
+// admit records n signatures, or none and returns a GuardError when they
+// must be refused.
+func (g *GuardedSigner) admit(n int) error {
+	g.mu.Lock()
+	now := g.now()
+	if g.started.IsZero() {
+		g.started = now
+	}
+	cutoff := now.Add(-time.Hour)
+	drop := 0
+	for drop < len(g.signed) && !g.signed[drop].After(cutoff) {
+		drop++
+	}
+	g.signed = g.signed[drop:]
+
+	minute := now.Add(-time.Minute)
+	recent := 0
+	for i := len(g.signed) - 1; i >= 0 && g.signed[i].After(minute); i-- {
+		recent++
+	}
+	count := recent + n
+
+	var anomalies []Anomaly
+	var blocked *Anomaly
+	flag := func(kind string, baseline float64, block bool) {
+		a := Anomaly{
+			Kind: kind, Address: g.signer.Address(), Provider: g.signer.Provider(),
+			Count: count, Baseline: baseline, Blocked: block, At: now,
+		}
+		if block && blocked == nil {
+			blocked = &a
+		}
+		if last, ok := g.reported[kind]; ok && now.Sub(last) < time.Minute {
+			return
+		}
+		g.reported[kind] = now
+		anomalies = append(anomalies, a)
+	}
+	if g.cfg.MaxPerMinute > 0 && count > g.cfg.MaxPerMinute {
+		flag(AnomalyRateLimit, 0, true)
+	}
+	// The hour before the current minute sets the baseline, or as much of it
+	// as the guard has seen since start.
+	since := g.started
+	if since.Before(cutoff) {
+		since = cutoff
+	}
+	covered := minute.Sub(since)
+	if g.cfg.SpikeFactor > 0 && count >= g.cfg.SpikeMinimum && covered >= spikeMinHistory {
+		baseline := float64(len(g.signed)-recent) / covered.Minutes()
+		if float64(count) > g.cfg.SpikeFactor*baseline {
+			flag(AnomalySpike, baseline, g.cfg.Action == GuardActionBlock)
+		}
+	}
+	if g.cfg.BusinessHours != nil && !g.cfg.BusinessHours.Contains(now) {
+		flag(AnomalyOffHours, 0, g.cfg.Action == GuardActionBlock)
+	}
+	if blocked == nil {
+		for i := 0; i < n; i++ {
+			g.signed = append(g.signed, now)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, a := range anomalies {
+		event := log.Warn()
+		if a.Blocked {
+			event = log.Error()
+		}
+		event.Str("kind", a.Kind).Str("address", a.Address.Hex()).Str("provider", a.Provider).
+			Int("count", a.Count).Bool("blocked", a.Blocked).Msg("Signing anomaly: " + a.String())
+		if g.cfg.OnAnomaly != nil {
+			g.cfg.OnAnomaly(a)
+		}
+	}
+	if blocked != nil {
+		return &GuardError{Anomaly: *blocked}
+	}
+	return nil
+}
+
+// Address implements Signer.
+func (g *GuardedSigner) Address() common.Address {
+	return g.signer.Address()
+}
+
+// SignHash implements Signer.
+func (g *GuardedSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	if err := g.admit(1); err != nil {
+		return nil, err
+	}
+	return g.signer.SignHash(ctx, hash)
+}
+
+// SignTransaction implements Signer.
+func (g *GuardedSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := g.admit(1); err != nil {
+		return nil, err
+	}
+	return g.signer.SignTransaction(ctx, tx, chainID)
+}
+
+// SignHashes implements BatchSigner. Each digest counts as one signature;
+// the batch is admitted or refused as a whole, so a refused batch uses none
+// of the quota.
+func (g *GuardedSigner) SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error) {
+	if err := g.admit(len(hashes)); err != nil {
+		return nil, err
+	}
+	return SignHashes(ctx, g.signer, hashes)
+}
+
+// Health implements Signer.
+func (g *GuardedSigner) Health(ctx context.Context) error {
+	return g.signer.Health(ctx)
+}
+
+// Provider implements Signer.
+func (g *GuardedSigner) Provider() string {
+	return g.signer.Provider()
+}
@@ -0,0 +1,253 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/karalabe/hid"
+)
+
+// Hardware wallet models supported by HardwareSigner.
+const (
+	WalletLedger = "ledger"
+	WalletTrezor = "trezor"
+)
+
+// ProviderHardware is reported by HardwareSigner. It is not selectable through
+// Config: every signature needs a button press on the device, so hardware
+// wallets are only used for manual payouts from payoutctl.
+const ProviderHardware = "hardware"
+
+// DefaultHardwarePath is the first account of the standard Ethereum derivation path.
+const DefaultHardwarePath = "m/44'/60'/0'/0/0"
+
+// errHardwareSignHash is returned by HardwareSigner.SignHash: the Ethereum
+// apps on Ledger and Trezor refuse to sign opaque digests, the user has to
+// see the transaction being signed.
+var errHardwareSignHash = errors.New("hardware wallets only sign transactions they can display")
+
+// HardwareConfig configures a Ledger or Trezor signer.
+type HardwareConfig struct {
+	Wallet  string // "ledger" or "trezor"
+	Path    string // BIP-32 derivation path, DefaultHardwarePath when empty
+	Address string // Optional: expected address at Path
+
+	// VerifyAddress shows the address on the device screen and waits for the
+	// user to confirm it matches the one printed on the host.
+	VerifyAddress bool
+
+	// Prompt asks the operator for a secret the device cannot take on its own
+	// screen: the scrambled PIN matrix position ("pin") or the wallet
+	// passphrase ("passphrase") of Trezor One. Optional for Ledger.
+	Prompt func(kind string) (string, error)
+}
+
+// hardwareDevice is one connected hardware wallet speaking its vendor protocol.
+type hardwareDevice interface {
+	// address derives the address at path, showing it on the device screen
+	// and waiting for confirmation when display is set.
+	address(path accounts.DerivationPath, display bool) (common.Address, error)
+	// signTx asks the user to confirm tx on the device and returns the signed transaction.
+	signTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	Close() error
+}
+
+// HardwareSigner signs with a key that never leaves a Ledger or Trezor
+// connected over USB. Signing blocks until the user confirms on the device.
+type HardwareSigner struct {
+	wallet  string
+	path    accounts.DerivationPath
+	address common.Address
+
+	mu     sync.Mutex // the device handles one exchange at a time
+	device hardwareDevice
+}
+
+// hardwareWallet describes how to find and open one wallet model.
+type hardwareWallet struct {
+	vendorIDs []uint16
+	match     func(hid.DeviceInfo) bool // the wallet's vendor interface
+	open      func(dev hid.Device, prompt func(string) (string, error)) (hardwareDevice, error)
+}
+
+// Windows and macOS match interfaces on the usage page, Linux on the interface number.
+var hardwareWallets = map[string]hardwareWallet{
+	WalletLedger: {
+		vendorIDs: []uint16{0x2c97},
+		match: func(info hid.DeviceInfo) bool {
+			return info.UsagePage == 0xffa0 || info.Interface == 0
+		},
+		open: openLedger,
+	},
+	WalletTrezor: {
+		vendorIDs: []uint16{0x534c, 0x1209}, // Trezor One HID, WebUSB (firmware >= 1.8)
+		match: func(info hid.DeviceInfo) bool {
+			return (info.VendorID == 0x534c && info.ProductID == 0x0001 && (info.UsagePage == 0xff00 || info.Interface == 0)) ||
+				(info.VendorID == 0x1209 && info.ProductID == 0x53c1 && info.Interface == 0)
+		},
+		open: openTrezor,
+	},
+}
+
+// NewHardwareSigner opens the first connected device of cfg.Wallet and
+// derives the signing address.
+func NewHardwareSigner(ctx context.Context, cfg HardwareConfig) (*HardwareSigner, error) {
+	wallet, ok := hardwareWallets[cfg.Wallet]
+	if !ok {
+		return nil, fmt.Errorf("unknown hardware wallet: %q (expected ledger or trezor)", cfg.Wallet)
+	}
+	if !hid.Supported() {
+		return nil, errors.New("usb hid is not supported by this build (requires cgo)")
+	}
+	for _, vendorID := range wallet.vendorIDs {
+		infos, err := hid.Enumerate(vendorID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enumerate usb devices: %w", err)
+		}
+		for _, info := range infos {
+			if !wallet.match(info) {
+				continue
+			}
+			dev, err := info.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", cfg.Wallet, err)
+			}
+			device, err := wallet.open(dev, cfg.Prompt)
+			if err != nil {
+				dev.Close()
+				return nil, fmt.Errorf("failed to open %s: %w", cfg.Wallet, err)
+			}
+			signer, err := newHardwareSigner(ctx, cfg, device)
+			if err != nil {
+				device.Close()
+				return nil, err
+			}
+			return signer, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s connected (unlock it and open the Ethereum app)", cfg.Wallet)
+}
+
+func newHardwareSigner(ctx context.Context, cfg HardwareConfig, device hardwareDevice) (*HardwareSigner, error) {
+	if cfg.Path == "" {
+		cfg.Path = DefaultHardwarePath
+	}
+	path, err := accounts.ParseDerivationPath(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %q: %w", cfg.Path, err)
+	}
+	s := &HardwareSigner{wallet: cfg.Wallet, path: path, device: device}
+	if s.address, err = device.address(path, false); err != nil {
+		return nil, fmt.Errorf("failed to derive address at %s: %w", path, err)
+	}
+	if cfg.Address != "" && !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid expected address: %s", cfg.Address)
+	}
+	if cfg.Address != "" && common.HexToAddress(cfg.Address) != s.address {
+		return nil, fmt.Errorf("%s address at %s is %s, expected %s", cfg.Wallet, path, s.address.Hex(), cfg.Address)
+	}
+	if cfg.VerifyAddress {
+		if err := s.VerifyAddress(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// VerifyAddress shows the signing address on the device screen and waits for
+// the user to approve it, guarding against a compromised host substituting
+// its own address.
+func (s *HardwareSigner) VerifyAddress(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	shown, err := s.device.address(s.path, true)
+	if err != nil {
+		return fmt.Errorf("address not confirmed on %s: %w", s.wallet, err)
+	}
+	if shown != s.address {
+		return fmt.Errorf("%s displayed %s, expected %s", s.wallet, shown.Hex(), s.address.Hex())
+	}
+	return nil
+}
+
+// Address implements Signer.
+func (s *HardwareSigner) Address() common.Address {
+	return s.address
+}
+
+// Path returns the derivation path of the signing key.
+func (s *HardwareSigner) Path() accounts.DerivationPath {
+	return s.path
+}
+
+// Wallet returns the device model ("ledger" or "trezor").
+func (s *HardwareSigner) Wallet() string {
+	return s.wallet
+}
+
+// SignHash implements Signer. Hardware wallets do not sign raw digests.
+func (s *HardwareSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return nil, errHardwareSignHash
+}
+
+// SignTransaction implements Signer. It blocks until the user confirms or
+// rejects the transaction on the device; ctx is only checked before the
+// request is sent since the device cannot be interrupted mid-confirmation.
+func (s *HardwareSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	signed, err := s.device.signTx(s.path, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("%s signing failed: %w", s.wallet, err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("%s returned an invalid signature: %w", s.wallet, err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("%s signed with %s, expected %s", s.wallet, sender.Hex(), s.address.Hex())
+	}
+	return signed, nil
+}
+
+// Health implements Signer by reading the address from the device without
+// displaying it.
+func (s *HardwareSigner) Health(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	addr, err := s.device.address(s.path, false)
+	if err != nil {
+		return fmt.Errorf("%s unavailable: %w", s.wallet, err)
+	}
+	if addr != s.address {
+		return fmt.Errorf("%s now derives %s, expected %s", s.wallet, addr.Hex(), s.address.Hex())
+	}
+	return nil
+}
+
+// Provider implements Signer.
+func (s *HardwareSigner) Provider() string {
+	return ProviderHardware
+}
+
+// Close releases the USB device.
+func (s *HardwareSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.device.Close()
+}
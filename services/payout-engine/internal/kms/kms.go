@@ -0,0 +1,164 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Supported signing providers
+const (
+	ProviderLocal      = "local"
+	ProviderFireblocks = "fireblocks"
+	ProviderMPC        = "mpc"
+)
+
+// Signer signs payout transactions without exposing key material to the caller.
+// Implementations must return 65-byte [R || S || V] signatures with V in {0, 1}.
+type Signer interface {
+	// Address returns the EVM address controlled by the signer.
+	Address() common.Address
+	// SignHash signs a 32-byte digest.
+	SignHash(ctx context.Context, hash []byte) ([]byte, error)
+	// SignTransaction signs an EVM transaction for the given chain.
+	SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// Provider returns the provider name, used for logging and metrics.
+	Provider() string
+	// Health checks that the provider is reachable and still controls the
+	// key, e.g. by fetching its public key again.
+	Health(ctx context.Context) error
+}
+
+// Config selects and configures the signing provider.
+type Config struct {
+	Provider   string // "local" (default), "fireblocks" or "mpc"
+	PrivateKey string // Hex private key for the local provider
+	Fireblocks FireblocksConfig
+	MPC        MPCConfig
+
+	// Version labels the key for rotation ("current" when empty). Previous
+	// lists keys rotated out of use, newest first (see RotatingSigner).
+	Version  string
+	Previous []PreviousKey
+
+	// Guard caps and monitors the signing rate of each key (see GuardedSigner).
+	Guard GuardConfig
+
+	// Audit records every signing call of each key (see AuditedSigner).
+	Audit AuditConfig
+
+	// Batch limits and groups the provider requests of each key (see BatchedSigner).
+	Batch BatchConfig
+}
+
+// FireblocksConfig configures the Fireblocks raw signing provider.
+type FireblocksConfig struct {
+	APIKey         string
+	SecretKey      string // PEM-encoded RSA private key of the API user
+	BaseURL        string
+	VaultAccountID string
+	AssetID        string // Asset used for raw signing, e.g. "ETH"
+	Address        string // Optional: expected vault address, resolved via API when empty
+	SignTimeout    time.Duration
+	PollInterval   time.Duration
+}
+
+// MPCConfig configures the threshold ECDSA provider (see MPCSigner).
+type MPCConfig struct {
+	CoSigners   []string // Co-signer base URLs, one per key share
+	KeyID       string   // Key generated by the co-signers' distributed key generation
+	Quorum      int      // Co-signers required to sign (t+1), at least 2
+	AuthToken   string   // Bearer token presented to the co-signers
+	Address     string   // Optional: expected address of the key
+	SignTimeout time.Duration
+}
+
+// NewSigner creates the signer selected by cfg.Provider, wrapped in a
+// RotatingSigner when previous key versions are configured.
+func NewSigner(ctx context.Context, cfg Config) (Signer, error) {
+	if len(cfg.Previous) > 0 {
+		return NewRotatingSigner(ctx, cfg)
+	}
+	return newKeySigner(ctx, cfg)
+}
+
+// newKeySigner creates the signer of one key, batched per cfg.Batch, guarded
+// when cfg.Guard is enabled and audited when cfg.Audit has a sink. The audit
+// wraps the guard so refused requests are recorded too.
+func newKeySigner(ctx context.Context, cfg Config) (Signer, error) {
+	signer, err := newProviderSigner(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	signer = newBatchedSigner(signer, cfg.Batch, providerLimiter(signer.Provider(), cfg.Batch.Concurrency))
+	if cfg.Guard.Enabled() {
+		if signer, err = NewGuardedSigner(signer, cfg.Guard); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Audit.Sink != nil {
+		signer = NewAuditedSigner(signer, keyPath(cfg, signer.Address()), cfg.Audit)
+	}
+	return signer, nil
+}
+
+func newProviderSigner(ctx context.Context, cfg Config) (Signer, error) {
+	switch cfg.Provider {
+	case "", ProviderLocal:
+		return NewLocalSigner(cfg.PrivateKey)
+	case ProviderFireblocks:
+		return NewFireblocksSigner(ctx, cfg.Fireblocks)
+	case ProviderMPC:
+		return NewMPCSigner(ctx, cfg.MPC)
+	default:
+		return nil, fmt.Errorf("unknown kms provider: %s", cfg.Provider)
+	}
+}
+
+// signTxWithHash signs tx by delegating the digest signature to signHash.
+func signTxWithHash(ctx context.Context, s Signer, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	txSigner := types.LatestSignerForChainID(chainID)
+	sig, err := s.SignHash(ctx, txSigner.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := tx.WithSignature(txSigner, sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach signature: %w", err)
+	}
+	return signedTx, nil
+}
+
+// secp256k1HalfN is half the curve order; Ethereum rejects signatures with a
+// larger S value.
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// recoverableSignature converts an (r, s) signature from a remote signer to
+// 65-byte [R || S || V] form with low S, choosing the recovery ID that yields
+// address. It fails when neither does, so a signature from another key is
+// never returned.
+func recoverableSignature(hash []byte, r, s *big.Int, address common.Address) ([]byte, error) {
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.BitLen() > 256 || s.BitLen() > 256 {
+		return nil, errors.New("malformed signature")
+	}
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(crypto.S256().Params().N, s)
+	}
+	out := make([]byte, crypto.SignatureLength)
+	r.FillBytes(out[:32])
+	s.FillBytes(out[32:64])
+	for v := byte(0); v <= 1; v++ {
+		out[64] = v
+		pub, err := crypto.SigToPub(hash, out)
+		if err == nil && crypto.PubkeyToAddress(*pub) == address {
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("signature does not match key address %s", address.Hex())
+}
@@ -0,0 +1,1018 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/usbwallet/trezor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestTx() *types.Transaction {
+	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(2_000_000_000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+}
+
+func TestLocalSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyHex := "0x" + hex.EncodeToString(crypto.FromECDSA(key))
+
+	signer, err := NewLocalSigner(keyHex)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	signed, err := signer.SignTransaction(context.Background(), newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+
+	t.Run("rejects missing key", func(t *testing.T) {
+		_, err := NewLocalSigner("")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects malformed key", func(t *testing.T) {
+		_, err := NewLocalSigner("not-a-key")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewSignerUnknownProvider(t *testing.T) {
+	_, err := NewSigner(context.Background(), Config{Provider: "hsm9000"})
+	assert.Error(t, err)
+}
+
+func TestRotatingSigner(t *testing.T) {
+	ctx := context.Background()
+	newKey := func() (string, common.Address) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		return hexutil.Encode(crypto.FromECDSA(key)), crypto.PubkeyToAddress(key.PublicKey)
+	}
+	activeKey, activeAddr := newKey()
+	prevKey, prevAddr := newKey()
+	retiredKey, retiredAddr := newKey()
+	retireAt := time.Now().Add(time.Hour)
+
+	s, err := NewSigner(ctx, Config{
+		PrivateKey: activeKey,
+		Version:    "v3",
+		Previous: []PreviousKey{
+			{Config: Config{PrivateKey: prevKey, Version: "v2"}, RetireAt: retireAt},
+			{Config: Config{PrivateKey: retiredKey}, RetireAt: time.Now().Add(-time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, activeAddr, s.Address(), "new transactions use the active key")
+
+	signed, err := s.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, activeAddr, sender)
+
+	versions := KeyVersions(s, time.Now())
+	require.Len(t, versions, 2, "retired keys are dropped")
+	assert.Equal(t, KeyVersion{Version: "v3", Address: activeAddr, Provider: ProviderLocal, Active: true}, versions[0])
+	assert.Equal(t, "v2", versions[1].Version)
+	assert.Equal(t, prevAddr, versions[1].Address)
+	require.NotNil(t, versions[1].RetireAt)
+	assert.True(t, retireAt.Equal(*versions[1].RetireAt))
+
+	// The previous key signs for its own address until it retires.
+	prev, ok := SignerForAddress(s, prevAddr, time.Now())
+	require.True(t, ok)
+	signed, err = prev.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+	sender, err = types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, prevAddr, sender)
+
+	_, ok = SignerForAddress(s, prevAddr, retireAt)
+	assert.False(t, ok, "window closed")
+	assert.Len(t, KeyVersions(s, retireAt), 1)
+	_, ok = SignerForAddress(s, retiredAddr, time.Now())
+	assert.False(t, ok)
+
+	// Unversioned signers have a single active version.
+	local, err := NewSigner(ctx, Config{PrivateKey: activeKey})
+	require.NoError(t, err)
+	assert.Equal(t, []KeyVersion{{Version: "current", Address: activeAddr, Provider: ProviderLocal, Active: true}}, KeyVersions(local, time.Now()))
+	_, ok = SignerForAddress(local, prevAddr, time.Now())
+	assert.False(t, ok)
+
+	_, err = NewSigner(ctx, Config{PrivateKey: activeKey, Previous: []PreviousKey{{Config: Config{PrivateKey: activeKey}}}})
+	assert.Error(t, err, "previous key with the active address")
+}
+
+func TestGuardedSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	local, err := NewLocalSigner(hexutil.Encode(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	hash := crypto.Keccak256([]byte("payout"))
+
+	var reported []Anomaly
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC) // Wednesday
+	guarded := func(cfg GuardConfig) *GuardedSigner {
+		reported = nil
+		cfg.OnAnomaly = func(a Anomaly) { reported = append(reported, a) }
+		g, err := NewGuardedSigner(local, cfg)
+		require.NoError(t, err)
+		g.now = func() time.Time { return now }
+		return g
+	}
+
+	t.Run("rate limit", func(t *testing.T) {
+		g := guarded(GuardConfig{MaxPerMinute: 3})
+		for i := 0; i < 3; i++ {
+			_, err := g.SignHash(ctx, hash)
+			require.NoError(t, err)
+		}
+		_, err := g.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+		var guardErr *GuardError
+		require.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, AnomalyRateLimit, guardErr.Anomaly.Kind)
+		assert.Equal(t, local.Address(), guardErr.Anomaly.Address)
+		_, err = g.SignHash(ctx, hash)
+		assert.Error(t, err)
+		require.Len(t, reported, 1, "reported once per minute")
+		assert.True(t, reported[0].Blocked)
+
+		now = now.Add(time.Minute)
+		_, err = g.SignHash(ctx, hash)
+		assert.NoError(t, err, "refused requests do not count against the next minute")
+	})
+
+	t.Run("spike", func(t *testing.T) {
+		g := guarded(GuardConfig{SpikeFactor: 5, SpikeMinimum: 4})
+		// One signature a minute for an hour sets the baseline.
+		for i := 0; i < 59; i++ {
+			_, err := g.SignHash(ctx, hash)
+			require.NoError(t, err)
+			now = now.Add(time.Minute)
+		}
+		for i := 0; i < 5; i++ {
+			_, err := g.SignHash(ctx, hash)
+			require.NoError(t, err, "alert only")
+		}
+		assert.Empty(t, reported, "within 5x the baseline")
+		_, err := g.SignHash(ctx, hash)
+		require.NoError(t, err)
+		require.Len(t, reported, 1)
+		assert.Equal(t, AnomalySpike, reported[0].Kind)
+		assert.Equal(t, 6, reported[0].Count)
+		assert.False(t, reported[0].Blocked)
+	})
+
+	t.Run("spike baseline after start", func(t *testing.T) {
+		g := guarded(GuardConfig{SpikeFactor: 3, SpikeMinimum: 4})
+		for i := 0; i < 5; i++ {
+			_, err := g.SignHash(ctx, hash)
+			require.NoError(t, err)
+		}
+		assert.Empty(t, reported, "no baseline right after start")
+
+		// Five a minute for 16 minutes: the baseline is over those minutes,
+		// not over a full hour.
+		for m := 1; m < 16; m++ {
+			now = now.Add(time.Minute)
+			for i := 0; i < 5; i++ {
+				_, err := g.SignHash(ctx, hash)
+				require.NoError(t, err)
+			}
+		}
+		now = now.Add(time.Minute)
+		for i := 0; i < 16; i++ {
+			_, err := g.SignHash(ctx, hash)
+			require.NoError(t, err)
+		}
+		assert.Empty(t, reported, "within 3x the baseline")
+		_, err := g.SignHash(ctx, hash)
+		require.NoError(t, err)
+		require.Len(t, reported, 1)
+		assert.Equal(t, AnomalySpike, reported[0].Kind)
+		assert.Equal(t, 17, reported[0].Count)
+	})
+
+	t.Run("off hours", func(t *testing.T) {
+		hours, err := ParseBusinessHours("09:00-18:00", "mon-fri", "America/New_York")
+		require.NoError(t, err)
+		g := guarded(GuardConfig{BusinessHours: hours, Action: GuardActionBlock})
+		now = time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC) // 11:00 in New York
+		_, err = g.SignHash(ctx, hash)
+		require.NoError(t, err)
+
+		now = time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC) // Saturday
+		_, err = g.SignHash(ctx, hash)
+		var guardErr *GuardError
+		require.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, AnomalyOffHours, guardErr.Anomaly.Kind)
+		require.Len(t, reported, 1)
+	})
+
+	overnight, err := ParseBusinessHours("22:00-06:00", "fri", "")
+	require.NoError(t, err)
+	assert.True(t, overnight.Contains(time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.Contains(time.Date(2026, 10, 17, 5, 0, 0, 0, time.UTC)), "Friday night continues into Saturday")
+	assert.False(t, overnight.Contains(time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC)))
+	_, err = ParseBusinessHours("9-18", "someday", "")
+	assert.Error(t, err)
+	_, err = NewGuardedSigner(local, GuardConfig{MaxPerMinute: 1, Action: "shrug"})
+	assert.Error(t, err)
+}
+
+type failingAuditSink struct{}
+
+func (failingAuditSink) RecordAudit(context.Context, AuditRecord) error {
+	return errors.New("audit store down")
+}
+
+func TestAuditedSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyHex := hexutil.Encode(crypto.FromECDSA(key))
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	ctx := WithCaller(context.Background(), Caller{Requestor: "user-1", UserID: "user-1", JobID: "job-1", Purpose: "payout"})
+
+	var buf bytes.Buffer
+	signer, err := NewSigner(ctx, Config{
+		PrivateKey: keyHex,
+		Guard:      GuardConfig{MaxPerMinute: 1},
+		Audit:      AuditConfig{Sink: MultiAuditSink{NewJSONAuditSink(&buf)}},
+	})
+	require.NoError(t, err)
+
+	tx := newTestTx()
+	_, err = signer.SignTransaction(ctx, tx, big.NewInt(1))
+	require.NoError(t, err)
+	_, err = signer.SignHash(ctx, crypto.Keccak256([]byte("manifest")))
+	require.Error(t, err, "over the guard limit")
+
+	dec := json.NewDecoder(&buf)
+	var signed, refused AuditRecord
+	require.NoError(t, dec.Decode(&signed))
+	require.NoError(t, dec.Decode(&refused))
+	assert.Equal(t, AuditMethodSignTransaction, signed.Method)
+	assert.Equal(t, "local:"+address.Hex(), signed.KeyPath)
+	assert.Equal(t, address, signed.Address)
+	assert.Equal(t, types.LatestSignerForChainID(big.NewInt(1)).Hash(tx).Hex(), signed.Digest)
+	assert.EqualValues(t, 1, signed.ChainID)
+	assert.Equal(t, "job-1", signed.Caller.JobID)
+	assert.True(t, signed.Success)
+	assert.Equal(t, AuditMethodSignHash, refused.Method)
+	assert.False(t, refused.Success)
+	assert.Contains(t, refused.Error, "signing blocked")
+
+	// Sink failures are logged unless the audit is required.
+	signer, err = NewSigner(ctx, Config{PrivateKey: keyHex, Audit: AuditConfig{Sink: failingAuditSink{}}})
+	require.NoError(t, err)
+	_, err = signer.SignTransaction(ctx, tx, big.NewInt(1))
+	assert.NoError(t, err)
+	signer, err = NewSigner(ctx, Config{PrivateKey: keyHex, Audit: AuditConfig{Sink: failingAuditSink{}, Required: true}})
+	require.NoError(t, err)
+	_, err = signer.SignTransaction(ctx, tx, big.NewInt(1))
+	assert.ErrorContains(t, err, "audit store down")
+}
+
+// fakeFireblocks emulates the raw signing endpoints backed by a local key.
+// It returns the number of status polls and of signing requests.
+func fakeFireblocks(t *testing.T, status string) (*httptest.Server, *int32, *int32) {
+	ecKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var polls, requests int32
+	var mu sync.Mutex
+	contents := make(map[string][]string)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api-key", r.Header.Get("X-API-Key"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "))
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/addresses"):
+			json.NewEncoder(w).Encode([]map[string]string{{"address": crypto.PubkeyToAddress(ecKey.PublicKey).Hex()}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/transactions":
+			var body struct {
+				Operation       string `json:"operation"`
+				ExtraParameters struct {
+					RawMessageData struct {
+						Messages []struct {
+							Content string `json:"content"`
+						} `json:"messages"`
+					} `json:"rawMessageData"`
+				} `json:"extraParameters"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "RAW", body.Operation)
+			id := fmt.Sprintf("fb-%d", atomic.AddInt32(&requests, 1))
+			mu.Lock()
+			for _, m := range body.ExtraParameters.RawMessageData.Messages {
+				contents[id] = append(contents[id], m.Content)
+			}
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]string{"id": id, "status": "SUBMITTED"})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/transactions/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+			if atomic.AddInt32(&polls, 1) < 2 {
+				json.NewEncoder(w).Encode(map[string]string{"id": id, "status": "PENDING_AUTHORIZATION"})
+				return
+			}
+			if status != "COMPLETED" {
+				json.NewEncoder(w).Encode(map[string]string{"id": id, "status": status})
+				return
+			}
+			mu.Lock()
+			messages := contents[id]
+			mu.Unlock()
+			var signed []map[string]interface{}
+			// Reverse the order to check that signatures are matched by content.
+			for i := len(messages) - 1; i >= 0; i-- {
+				hash, _ := hex.DecodeString(messages[i])
+				sig, _ := crypto.Sign(hash, ecKey)
+				signed = append(signed, map[string]interface{}{
+					"content": messages[i],
+					"signature": map[string]interface{}{
+						"fullSig": hex.EncodeToString(sig[:64]),
+						"v":       int(sig[64]),
+					},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":             id,
+				"status":         "COMPLETED",
+				"signedMessages": signed,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv, &polls, &requests
+}
+
+func newFireblocksConfig(t *testing.T, baseURL string) FireblocksConfig {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	return FireblocksConfig{
+		APIKey:         "api-key",
+		SecretKey:      string(pemKey),
+		BaseURL:        baseURL,
+		VaultAccountID: "0",
+		SignTimeout:    5 * time.Second,
+		PollInterval:   10 * time.Millisecond,
+	}
+}
+
+func TestFireblocksSigner_SignTransaction(t *testing.T) {
+	cfg := newFireblocksConfig(t, "")
+	srv, polls, _ := fakeFireblocks(t, "COMPLETED")
+	defer srv.Close()
+	cfg.BaseURL = srv.URL
+
+	signer, err := NewFireblocksSigner(context.Background(), cfg)
+	require.NoError(t, err)
+
+	signed, err := signer.SignTransaction(context.Background(), newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(polls), int32(2), "should poll through pending authorization")
+}
+
+func TestFireblocksSigner_RejectedRequest(t *testing.T) {
+	cfg := newFireblocksConfig(t, "")
+	srv, _, _ := fakeFireblocks(t, "REJECTED")
+	defer srv.Close()
+	cfg.BaseURL = srv.URL
+
+	signer, err := NewFireblocksSigner(context.Background(), cfg)
+	require.NoError(t, err)
+
+	_, err = signer.SignHash(context.Background(), make([]byte, 32))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REJECTED")
+}
+
+func TestFireblocksSignatureRecovery(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	hash := crypto.Keccak256([]byte("payout"))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+
+	// The same signature with high S and the recovery ID Fireblocks reports for it.
+	n := crypto.S256().Params().N
+	highS := new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64]))
+	full := append(append([]byte{}, sig[:32]...), highS.FillBytes(make([]byte, 32))...)
+	messages := []fireblocksSignedMessage{{
+		Content:   hex.EncodeToString(hash),
+		Signature: fireblocksSignature{FullSig: hex.EncodeToString(full)},
+	}}
+
+	sigs, err := matchSignedMessages("fb-1", address, [][]byte{hash}, messages)
+	require.NoError(t, err)
+	assert.Equal(t, sig, sigs[0], "low S with the recovery ID of the vault key")
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = matchSignedMessages("fb-1", crypto.PubkeyToAddress(other.PublicKey), [][]byte{hash}, messages)
+	assert.ErrorContains(t, err, "does not match key address")
+}
+
+func TestFireblocksSigner_RequiresCredentials(t *testing.T) {
+	_, err := NewFireblocksSigner(context.Background(), FireblocksConfig{VaultAccountID: "0"})
+	assert.Error(t, err)
+}
+
+func TestBatchedSigner(t *testing.T) {
+	ctx := context.Background()
+	digest := func(i int) []byte { return crypto.Keccak256([]byte{byte(i)}) }
+	assertSigned := func(t *testing.T, addr common.Address, hash, sig []byte) {
+		pub, err := crypto.SigToPub(hash, sig)
+		require.NoError(t, err)
+		assert.Equal(t, addr, crypto.PubkeyToAddress(*pub))
+	}
+
+	t.Run("splits batches into provider requests and signs duplicates once", func(t *testing.T) {
+		srv, _, requests := fakeFireblocks(t, "COMPLETED")
+		defer srv.Close()
+		fb, err := NewFireblocksSigner(ctx, newFireblocksConfig(t, srv.URL))
+		require.NoError(t, err)
+		signer := NewBatchedSigner(fb, BatchConfig{MaxBatchSize: 2})
+
+		hashes := [][]byte{digest(1), digest(2), digest(1), digest(3), digest(4)}
+		sigs, err := SignHashes(ctx, signer, hashes)
+		require.NoError(t, err)
+		require.Len(t, sigs, len(hashes))
+		for i, hash := range hashes {
+			assertSigned(t, signer.Address(), hash, sigs[i])
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(requests), "4 distinct digests in requests of 2")
+	})
+
+	t.Run("coalesces concurrent SignHash calls", func(t *testing.T) {
+		srv, _, requests := fakeFireblocks(t, "COMPLETED")
+		defer srv.Close()
+		fb, err := NewFireblocksSigner(ctx, newFireblocksConfig(t, srv.URL))
+		require.NoError(t, err)
+		signer := NewBatchedSigner(fb, BatchConfig{CoalesceWindow: 50 * time.Millisecond})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sig, err := signer.SignHash(ctx, digest(i))
+				require.NoError(t, err)
+				assertSigned(t, signer.Address(), digest(i), sig)
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+		signed, err := signer.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+		require.NoError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+		require.NoError(t, err)
+		assert.Equal(t, signer.Address(), sender)
+
+		_, err = signer.SignHash(ctx, []byte("short"))
+		assert.Error(t, err)
+	})
+
+	t.Run("limits provider requests in flight", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		local, err := NewLocalSigner("0x" + hex.EncodeToString(crypto.FromECDSA(key)))
+		require.NoError(t, err)
+		slow := &slowSigner{Signer: local}
+		signer := NewBatchedSigner(slow, BatchConfig{Concurrency: 2})
+
+		hashes := make([][]byte, 6)
+		for i := range hashes {
+			hashes[i] = digest(i)
+		}
+		sigs, err := SignHashes(ctx, signer, hashes)
+		require.NoError(t, err)
+		for i, hash := range hashes {
+			assertSigned(t, signer.Address(), hash, sigs[i])
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(&slow.peak))
+	})
+
+	t.Run("guard and audit count every digest", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		var records bytes.Buffer
+		signer, err := NewSigner(ctx, Config{
+			PrivateKey: "0x" + hex.EncodeToString(crypto.FromECDSA(key)),
+			Guard:      GuardConfig{MaxPerMinute: 3},
+			Audit:      AuditConfig{Sink: NewJSONAuditSink(&records)},
+		})
+		require.NoError(t, err)
+
+		_, err = SignHashes(ctx, signer, [][]byte{digest(1), digest(2)})
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(records.String(), "\n"))
+
+		_, err = SignHashes(ctx, signer, [][]byte{digest(3), digest(4)})
+		var guardErr *GuardError
+		assert.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, 4, guardErr.Anomaly.Count)
+
+		// The refused batch used none of the quota.
+		_, err = signer.SignHash(ctx, digest(5))
+		assert.NoError(t, err)
+		_, err = signer.SignHash(ctx, digest(6))
+		assert.ErrorAs(t, err, &guardErr)
+	})
+}
+
+func TestSignerHealth(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wrappers delegate to every usable key version", func(t *testing.T) {
+		newKey := func() string {
+			key, err := crypto.GenerateKey()
+			require.NoError(t, err)
+			return hex.EncodeToString(crypto.FromECDSA(key))
+		}
+		signer, err := NewSigner(ctx, Config{
+			PrivateKey: newKey(),
+			Previous:   []PreviousKey{{Config: Config{PrivateKey: newKey()}, RetireAt: time.Now().Add(time.Hour)}},
+			Guard:      GuardConfig{MaxPerMinute: 10},
+			Audit:      AuditConfig{Sink: NewJSONAuditSink(io.Discard)},
+			Batch:      BatchConfig{Concurrency: 1},
+		})
+		require.NoError(t, err)
+		assert.NoError(t, signer.Health(ctx))
+	})
+
+	t.Run("fireblocks", func(t *testing.T) {
+		srv, _, _ := fakeFireblocks(t, "COMPLETED")
+		signer, err := NewFireblocksSigner(ctx, newFireblocksConfig(t, srv.URL))
+		require.NoError(t, err)
+		assert.NoError(t, signer.Health(ctx))
+
+		srv.Close()
+		assert.ErrorContains(t, signer.Health(ctx), "unreachable")
+	})
+
+	t.Run("mpc requires a quorum of co-signers", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		a, b, c := newFakeCoSigner(t, "p1", key), newFakeCoSigner(t, "p2", key), newFakeCoSigner(t, "p3", key)
+		signer, err := NewMPCSigner(ctx, MPCConfig{
+			CoSigners: []string{a.srv.URL, b.srv.URL, c.srv.URL},
+			KeyID:     "payout",
+			Quorum:    2,
+			AuthToken: "token",
+		})
+		require.NoError(t, err)
+		assert.NoError(t, signer.Health(ctx))
+
+		c.srv.Close()
+		assert.NoError(t, signer.Health(ctx))
+		b.srv.Close()
+		assert.ErrorContains(t, signer.Health(ctx), "only 1 of 3")
+	})
+}
+
+// slowSigner records the peak number of concurrent SignHash calls.
+type slowSigner struct {
+	Signer
+	inFlight, peak int32
+}
+
+func (s *slowSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return s.Signer.SignHash(ctx, hash)
+}
+
+// fakeCoSigner emulates one MPC co-signer. The test key stands in for the
+// result of the parties' signing rounds.
+type fakeCoSigner struct {
+	srv      *httptest.Server
+	partyID  string
+	key      *ecdsa.PrivateKey
+	fail     bool
+	highS    bool // return the equivalent high-S signature
+	sessions []mpcSignRequest
+}
+
+func newFakeCoSigner(t *testing.T, partyID string, key *ecdsa.PrivateKey) *fakeCoSigner {
+	c := &fakeCoSigner{partyID: partyID, key: key}
+	c.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/keys/payout":
+			json.NewEncoder(w).Encode(map[string]string{
+				"party_id":   c.partyID,
+				"public_key": "0x" + hex.EncodeToString(crypto.CompressPubkey(&c.key.PublicKey)),
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/keys/payout/sign":
+			if c.fail {
+				http.Error(w, "share unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			var req mpcSignRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			c.sessions = append(c.sessions, req)
+			sig, err := crypto.Sign(common.FromHex(req.Digest), c.key)
+			require.NoError(t, err)
+			s := new(big.Int).SetBytes(sig[32:64])
+			if c.highS {
+				s.Sub(crypto.S256().Params().N, s)
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"r": hexutil.Encode(sig[:32]),
+				"s": hexutil.EncodeBig(s),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(c.srv.Close)
+	return c
+}
+
+func TestMPCSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	a, b, c := newFakeCoSigner(t, "p1", key), newFakeCoSigner(t, "p2", key), newFakeCoSigner(t, "p3", key)
+	cfg := MPCConfig{
+		CoSigners:   []string{a.srv.URL, b.srv.URL, c.srv.URL},
+		KeyID:       "payout",
+		Quorum:      2,
+		AuthToken:   "token",
+		SignTimeout: 5 * time.Second,
+	}
+
+	signer, err := NewMPCSigner(ctx, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+	assert.Equal(t, ProviderMPC, signer.Provider())
+
+	// 同一会话发给 quorum 内的每一方
+	signed, err := signer.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+	require.Len(t, a.sessions, 1)
+	require.Len(t, b.sessions, 1)
+	assert.Empty(t, c.sessions)
+	assert.Equal(t, a.sessions[0].SessionID, b.sessions[0].SessionID)
+	assert.Equal(t, []string{"p1", "p2"}, a.sessions[0].Parties)
+
+	// 一方失败时换一个 quorum 重签；高 S 签名转为低 S
+	b.fail = true
+	a.highS, c.highS = true, true
+	signed, err = signer.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	require.NoError(t, err)
+	sender, err = types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+	require.Len(t, c.sessions, 1)
+	assert.Equal(t, []string{"p1", "p3"}, c.sessions[0].Parties)
+
+	// 可用方不足 quorum
+	c.fail = true
+	_, err = signer.SignHash(ctx, make([]byte, 32))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "quorum is 2")
+
+	// 各方签名不一致时拒绝
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	a.highS, c.highS = false, false
+	b.fail, c.fail = false, false
+	signer, err = NewMPCSigner(ctx, cfg)
+	require.NoError(t, err)
+	b.key = other
+	_, err = signer.SignHash(ctx, make([]byte, 32))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "different signatures")
+
+	t.Run("rejects co-signers with another key", func(t *testing.T) {
+		_, err := NewMPCSigner(ctx, cfg)
+		assert.ErrorIs(t, err, errMPCKeyMismatch)
+	})
+
+	t.Run("rejects a single-party quorum", func(t *testing.T) {
+		single := cfg
+		single.Quorum = 1
+		_, err := NewMPCSigner(ctx, single)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unexpected address", func(t *testing.T) {
+		b.key = key
+		wrong := cfg
+		wrong.Address = crypto.PubkeyToAddress(other.PublicKey).Hex()
+		_, err := NewMPCSigner(ctx, wrong)
+		assert.Error(t, err)
+	})
+}
+
+// fakeLedger emulates the Ledger Ethereum app behind the HID framing.
+type fakeLedger struct {
+	key      *ecdsa.PrivateKey
+	reject   bool
+	displays int // addresses shown on the screen
+
+	apdu    []byte // APDU being received
+	pending []byte // transaction being streamed
+	out     bytes.Buffer
+}
+
+func (l *fakeLedger) Write(packet []byte) (int, error) {
+	if binary.BigEndian.Uint16(packet[3:]) == 0 {
+		size := int(binary.BigEndian.Uint16(packet[5:]))
+		l.apdu = append(make([]byte, 0, size), packet[7:7+min(size, 57)]...)
+	} else {
+		l.apdu = append(l.apdu, packet[5:5+min(cap(l.apdu)-len(l.apdu), 59)]...)
+	}
+	if len(l.apdu) == cap(l.apdu) {
+		l.reply(l.handle(l.apdu[1], l.apdu[2], l.apdu[5:]))
+	}
+	return len(packet), nil
+}
+
+func (l *fakeLedger) Read(p []byte) (int, error) { return l.out.Read(p) }
+func (l *fakeLedger) Close() error               { return nil }
+
+func (l *fakeLedger) handle(ins, p1 byte, data []byte) []byte {
+	ok := []byte{0x90, 0x00}
+	switch ins {
+	case ledgerInsGetAddress:
+		if p1 == ledgerP1Display {
+			if l.reject {
+				return []byte{0x69, 0x85}
+			}
+			l.displays++
+		}
+		pub := crypto.FromECDSAPub(&l.key.PublicKey)
+		addr := hex.EncodeToString(crypto.PubkeyToAddress(l.key.PublicKey).Bytes())
+		reply := append([]byte{byte(len(pub))}, pub...)
+		reply = append(reply, byte(len(addr)))
+		return append(append(reply, addr...), ok...)
+	case ledgerInsSignTx:
+		if p1 == ledgerP1FirstTx {
+			l.pending = append([]byte{}, data[1+4*int(data[0]):]...)
+		} else {
+			l.pending = append(l.pending, data...)
+		}
+		body := l.pending
+		if body[0] < 0x7f {
+			body = body[1:]
+		}
+		if _, _, _, err := rlp.Split(body); err != nil {
+			return ok // 交易未传完
+		}
+		if l.reject {
+			return []byte{0x69, 0x85}
+		}
+		sig, _ := crypto.Sign(crypto.Keccak256(l.pending), l.key)
+		v := sig[64]
+		if l.pending[0] >= 0x7f { // legacy: chainID*2 + 35 的低字节
+			var fields []interface{}
+			rlp.DecodeBytes(l.pending, &fields)
+			v += byte(new(big.Int).SetBytes(fields[6].([]byte)).Uint64()*2 + 35)
+		}
+		return append(append([]byte{v}, sig[:64]...), ok...)
+	}
+	return []byte{0x6d, 0x00}
+}
+
+func (l *fakeLedger) reply(data []byte) {
+	msg := binary.BigEndian.AppendUint16(nil, uint16(len(data)))
+	msg = append(msg, data...)
+	for seq := 0; len(msg) > 0; seq++ {
+		packet := make([]byte, 64)
+		copy(packet, []byte{0x01, 0x01, 0x05})
+		binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+		n := copy(packet[5:], msg)
+		msg = msg[n:]
+		l.out.Write(packet)
+	}
+}
+
+func TestHardwareSignerLedger(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	ledger := &fakeLedger{key: key}
+
+	signer, err := newHardwareSigner(ctx, HardwareConfig{Wallet: WalletLedger, Address: address.Hex(), VerifyAddress: true}, &ledgerDevice{dev: ledger})
+	require.NoError(t, err)
+	assert.Equal(t, address, signer.Address())
+	assert.Equal(t, DefaultHardwarePath, signer.Path().String())
+	assert.Equal(t, 1, ledger.displays, "address shown on the device")
+
+	// 调用数据超过一个 APDU，分多块发送
+	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(30e9), Gas: 60000,
+		To: &to, Value: big.NewInt(0), Data: make([]byte, 600),
+	})
+	signed, err := signer.SignTransaction(ctx, tx, big.NewInt(1))
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, address, sender)
+
+	// legacy 交易，V 按 EIP-155 还原
+	legacy := types.NewTx(&types.LegacyTx{Nonce: 4, GasPrice: big.NewInt(50e9), Gas: 21000, To: &to, Value: big.NewInt(1e18)})
+	signed, err = signer.SignTransaction(ctx, legacy, big.NewInt(137))
+	require.NoError(t, err)
+	sender, err = types.Sender(types.LatestSignerForChainID(big.NewInt(137)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, address, sender)
+
+	_, err = signer.SignHash(ctx, make([]byte, 32))
+	assert.ErrorIs(t, err, errHardwareSignHash)
+
+	ledger.reject = true
+	_, err = signer.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	assert.ErrorContains(t, err, "rejected on device")
+	assert.ErrorContains(t, signer.VerifyAddress(ctx), "rejected on device")
+
+	_, err = newHardwareSigner(ctx, HardwareConfig{Wallet: WalletLedger, Address: to.Hex()}, &ledgerDevice{dev: &fakeLedger{key: key}})
+	assert.ErrorContains(t, err, "expected "+to.Hex())
+}
+
+// fakeTrezor emulates the Trezor Ethereum app behind the report framing.
+type fakeTrezor struct {
+	key      *ecdsa.PrivateKey
+	buttons  int // confirmations requested on the device
+	msg      []byte
+	kind     uint16
+	signTx   *trezor.EthereumSignTx
+	data     []byte
+	awaiting proto.Message // reply sent after the ButtonAck
+	out      bytes.Buffer
+}
+
+func (f *fakeTrezor) Write(report []byte) (int, error) {
+	if f.msg == nil {
+		f.kind = binary.BigEndian.Uint16(report[3:])
+		f.msg = make([]byte, 0, binary.BigEndian.Uint32(report[5:]))
+		report = report[9:]
+	} else {
+		report = report[1:]
+	}
+	f.msg = append(f.msg, report[:min(cap(f.msg)-len(f.msg), len(report))]...)
+	if len(f.msg) == cap(f.msg) {
+		f.handle(f.kind, f.msg)
+		f.msg = nil
+	}
+	return 64, nil
+}
+
+func (f *fakeTrezor) Read(p []byte) (int, error) { return f.out.Read(p) }
+func (f *fakeTrezor) Close() error               { return nil }
+
+func (f *fakeTrezor) handle(kind uint16, msg []byte) {
+	address := crypto.PubkeyToAddress(f.key.PublicKey).Hex()
+	switch kind {
+	case trezor.Type(&trezor.ButtonAck{}):
+		f.reply(f.awaiting)
+	case trezor.Type(&trezor.EthereumGetAddress{}):
+		req := new(trezor.EthereumGetAddress)
+		proto.Unmarshal(msg, req)
+		if !req.GetShowDisplay() {
+			f.reply(&trezor.EthereumAddress{AddressHex: &address})
+			return
+		}
+		f.buttons++
+		f.awaiting = &trezor.EthereumAddress{AddressHex: &address}
+		f.reply(&trezor.ButtonRequest{})
+	case trezor.Type(&trezor.EthereumSignTx{}):
+		f.signTx = new(trezor.EthereumSignTx)
+		proto.Unmarshal(msg, f.signTx)
+		f.data = f.signTx.DataInitialChunk
+		f.nextChunk()
+	case trezor.Type(&trezor.EthereumTxAck{}):
+		ack := new(trezor.EthereumTxAck)
+		proto.Unmarshal(msg, ack)
+		f.data = append(f.data, ack.DataChunk...)
+		f.nextChunk()
+	}
+}
+
+func (f *fakeTrezor) nextChunk() {
+	if left := f.signTx.GetDataLength() - uint32(len(f.data)); left > 0 {
+		n := min(left, 1024)
+		f.reply(&trezor.EthereumTxRequest{DataLength: &n})
+		return
+	}
+	req := f.signTx
+	to := common.HexToAddress(req.GetToHex())
+	chainID := big.NewInt(int64(req.GetChainId()))
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    new(big.Int).SetBytes(req.Nonce).Uint64(),
+		GasPrice: new(big.Int).SetBytes(req.GasPrice),
+		Gas:      new(big.Int).SetBytes(req.GasLimit).Uint64(),
+		To:       &to,
+		Value:    new(big.Int).SetBytes(req.Value),
+		Data:     f.data,
+	})
+	sig, _ := crypto.Sign(types.NewEIP155Signer(chainID).Hash(tx).Bytes(), f.key)
+	v := uint32(sig[64]) + req.GetChainId()*2 + 35
+	f.buttons++
+	f.awaiting = &trezor.EthereumTxRequest{SignatureV: &v, SignatureR: sig[:32], SignatureS: sig[32:64]}
+	f.reply(&trezor.ButtonRequest{})
+}
+
+func (f *fakeTrezor) reply(msg proto.Message) {
+	data, _ := proto.Marshal(msg)
+	payload := []byte{'#', '#'}
+	payload = binary.BigEndian.AppendUint16(payload, trezor.Type(msg))
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(data)))
+	payload = append(payload, data...)
+	for len(payload) > 0 {
+		report := make([]byte, 64)
+		report[0] = '?'
+		n := copy(report[1:], payload)
+		payload = payload[n:]
+		f.out.Write(report)
+	}
+}
+
+func TestHardwareSignerTrezor(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fake := &fakeTrezor{key: key}
+
+	signer, err := newHardwareSigner(ctx, HardwareConfig{Wallet: WalletTrezor, VerifyAddress: true}, &trezorDevice{dev: fake})
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+	assert.Equal(t, 1, fake.buttons)
+
+	// 调用数据超过首块 1024 字节时按设备请求继续发送
+	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	tx := types.NewTx(&types.LegacyTx{Nonce: 9, GasPrice: big.NewInt(30e9), Gas: 90000, To: &to, Value: big.NewInt(5), Data: make([]byte, 1500)})
+	signed, err := signer.SignTransaction(ctx, tx, big.NewInt(8453))
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(8453)), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+	assert.Len(t, fake.data, 1500)
+	assert.Equal(t, 2, fake.buttons)
+
+	_, err = signer.SignTransaction(ctx, newTestTx(), big.NewInt(1))
+	assert.ErrorContains(t, err, "only legacy transactions")
+}
@@ -0,0 +1,194 @@
+package kms
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/karalabe/hid"
+)
+
+// Ledger Ethereum app APDUs (CLA 0xe0).
+const (
+	ledgerInsGetAddress = 0x02
+	ledgerInsSignTx     = 0x04
+
+	ledgerP1NoDisplay = 0x00 // return the address directly
+	ledgerP1Display   = 0x01 // show the address and wait for the user to approve it
+	ledgerP1FirstTx   = 0x00 // first transaction chunk
+	ledgerP1MoreTx    = 0x80 // subsequent transaction chunks
+
+	ledgerMaxChunk = 255
+)
+
+// Ledger status words.
+const (
+	ledgerSWOK       = 0x9000
+	ledgerSWRejected = 0x6985
+	ledgerSWLocked   = 0x5515
+)
+
+var errLedgerInvalidReply = errors.New("ledger: invalid reply")
+
+// ledgerDevice speaks the Ledger Ethereum app protocol over HID.
+type ledgerDevice struct {
+	dev io.ReadWriteCloser
+}
+
+func openLedger(dev hid.Device, _ func(string) (string, error)) (hardwareDevice, error) {
+	return &ledgerDevice{dev: dev}, nil
+}
+
+// address retrieves the public key and address at path. The reply is
+// [pubkey length | pubkey | address length | hex address].
+func (l *ledgerDevice) address(path accounts.DerivationPath, display bool) (common.Address, error) {
+	p1 := byte(ledgerP1NoDisplay)
+	if display {
+		p1 = ledgerP1Display
+	}
+	reply, err := l.exchange(ledgerInsGetAddress, p1, 0, ledgerPath(path))
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(reply) < 1 || len(reply) < 1+int(reply[0]) {
+		return common.Address{}, errLedgerInvalidReply
+	}
+	reply = reply[1+int(reply[0]):]
+	if len(reply) < 1 || int(reply[0]) != 2*common.AddressLength || len(reply) < 1+int(reply[0]) {
+		return common.Address{}, errLedgerInvalidReply
+	}
+	var addr common.Address
+	if _, err := hex.Decode(addr[:], reply[1:1+2*common.AddressLength]); err != nil {
+		return common.Address{}, fmt.Errorf("ledger: invalid address: %w", err)
+	}
+	return addr, nil
+}
+
+// signTx streams the path and the unsigned RLP transaction in chunks of at
+// most 255 bytes; the last reply is [V | R | S].
+func (l *ledgerDevice) signTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var fields []interface{}
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		fields = []interface{}{chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList()}
+	case types.LegacyTxType:
+		fields = []interface{}{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0)}
+	default:
+		return nil, fmt.Errorf("ledger: unsupported transaction type %d", tx.Type())
+	}
+	unsigned, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type() != types.LegacyTxType {
+		unsigned = append([]byte{tx.Type()}, unsigned...)
+	}
+	payload := append(ledgerPath(path), unsigned...)
+
+	// The app fails to parse a legacy transaction whose last chunk holds only
+	// the trailing EIP-155 fields (chain ID, 0, 0), so shrink the chunk size
+	// until the remainder is larger than that.
+	chunk := ledgerMaxChunk
+	if tx.Type() == types.LegacyTxType {
+		for len(payload)%chunk <= 3 && chunk > 16 {
+			chunk--
+		}
+	}
+	var reply []byte
+	p1 := byte(ledgerP1FirstTx)
+	for len(payload) > 0 {
+		n := min(chunk, len(payload))
+		if reply, err = l.exchange(ledgerInsSignTx, p1, 0, payload[:n]); err != nil {
+			return nil, err
+		}
+		payload, p1 = payload[n:], ledgerP1MoreTx
+	}
+	if len(reply) != crypto.SignatureLength {
+		return nil, errLedgerInvalidReply
+	}
+	sig := append(append([]byte{}, reply[1:]...), reply[0])
+	if tx.Type() == types.LegacyTxType {
+		// The app returns the low byte of chainID*2 + 35 + parity.
+		sig[64] -= byte(chainID.Uint64()*2 + 35)
+	}
+	return tx.WithSignature(types.LatestSignerForChainID(chainID), sig)
+}
+
+func (l *ledgerDevice) Close() error {
+	return l.dev.Close()
+}
+
+// exchange sends one APDU and returns the reply data without the status word.
+// APDUs are framed into 64-byte HID packets of
+// [channel 0x0101 | tag 0x05 | sequence (2 bytes) | payload], where the payload
+// of the first packet starts with the 2-byte APDU length.
+func (l *ledgerDevice) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	apdu := make([]byte, 2, 7+len(data))
+	binary.BigEndian.PutUint16(apdu, uint16(5+len(data)))
+	apdu = append(apdu, 0xe0, ins, p1, p2, byte(len(data)))
+	apdu = append(apdu, data...)
+
+	packet := make([]byte, 64)
+	for seq := 0; len(apdu) > 0; seq++ {
+		clear(packet)
+		copy(packet, []byte{0x01, 0x01, 0x05})
+		binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+		n := copy(packet[5:], apdu)
+		apdu = apdu[n:]
+		if _, err := l.dev.Write(packet); err != nil {
+			return nil, err
+		}
+	}
+
+	var reply []byte
+	for seq := 0; ; seq++ {
+		if _, err := io.ReadFull(l.dev, packet); err != nil {
+			return nil, err
+		}
+		if packet[0] != 0x01 || packet[1] != 0x01 || packet[2] != 0x05 || int(binary.BigEndian.Uint16(packet[3:])) != seq {
+			return nil, errLedgerInvalidReply
+		}
+		payload := packet[5:]
+		if seq == 0 {
+			reply = make([]byte, 0, binary.BigEndian.Uint16(packet[5:]))
+			payload = packet[7:]
+		}
+		left := cap(reply) - len(reply)
+		if left <= len(payload) {
+			reply = append(reply, payload[:left]...)
+			break
+		}
+		reply = append(reply, payload...)
+	}
+	if len(reply) < 2 {
+		return nil, errLedgerInvalidReply
+	}
+	switch sw := binary.BigEndian.Uint16(reply[len(reply)-2:]); sw {
+	case ledgerSWOK:
+		return reply[:len(reply)-2], nil
+	case ledgerSWRejected:
+		return nil, errors.New("ledger: rejected on device")
+	case ledgerSWLocked:
+		return nil, errors.New("ledger: device is locked")
+	default:
+		return nil, fmt.Errorf("ledger: status %#04x (is the Ethereum app open?)", sw)
+	}
+}
+
+// ledgerPath encodes a derivation path as [count | index (4 bytes big endian)...].
+func ledgerPath(path accounts.DerivationPath) []byte {
+	out := make([]byte, 1+4*len(path))
+	out[0] = byte(len(path))
+	for i, index := range path {
+		binary.BigEndian.PutUint32(out[1+4*i:], index)
+	}
+	return out
+}
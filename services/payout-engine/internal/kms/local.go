@@ -0,0 +1,66 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// LocalSigner signs with an in-memory private key (PAYOUT_PRIVATE_KEY).
+// Intended for development and low-value wallets only.
+type LocalSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner parses a hex private key (with or without 0x prefix).
+func NewLocalSigner(privateKeyHex string) (*LocalSigner, error) {
+	if privateKeyHex == "" {
+		return nil, fmt.Errorf("critical: payment processing private key is missing")
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key configuration: %w", err)
+	}
+
+	return &LocalSigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+// Address implements Signer.
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash implements Signer.
+func (s *LocalSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+// SignTransaction implements Signer.
+func (s *LocalSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return signedTx, nil
+}
+
+// Health implements Signer. The key is in memory.
+func (s *LocalSigner) Health(context.Context) error {
+	return nil
+}
+
+// Provider implements Signer.
+func (s *LocalSigner) Provider() string {
+	return ProviderLocal
+}
@@ -0,0 +1,423 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// mpcCooldown is how long a co-signer that failed a signing session is left
+// out of new quorums while others are available.
+const mpcCooldown = 30 * time.Second
+
+// errMPCKeyMismatch means co-signers disagree on the key's public key.
+var errMPCKeyMismatch = errors.New("mpc co-signers report different public keys")
+
+// MPCSigner signs digests with a threshold ECDSA key. The key was generated by
+// distributed key generation across the co-signers, each holding one share;
+// any Quorum of them jointly produce a signature and no machine, including
+// this one, ever holds the full key.
+//
+// Co-signers expose (Authorization: Bearer <AuthToken>):
+//
+//	GET  /v1/keys/{key_id}       -> {"party_id": "...", "public_key": "0x04..."}
+//	POST /v1/keys/{key_id}/sign  {"session_id", "digest", "parties": [party_id...]}
+//	                             -> {"r": "0x...", "s": "0x..."}
+//
+// The signing request goes to every party of the quorum with the same session
+// ID; the parties run the signing rounds among themselves and each returns the
+// resulting signature. The signer requires all of them to agree and checks the
+// signature against the public key before returning it.
+type MPCSigner struct {
+	cfg        MPCConfig
+	address    common.Address
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	parties []*mpcParty
+}
+
+// mpcParty is one co-signer holding a key share.
+type mpcParty struct {
+	url       string
+	id        string    // empty until the co-signer has been reached
+	downUntil time.Time // excluded from quorums until then
+}
+
+type mpcKeyInfo struct {
+	PartyID   string `json:"party_id"`
+	PublicKey string `json:"public_key"`
+}
+
+type mpcSignRequest struct {
+	SessionID string   `json:"session_id"`
+	Digest    string   `json:"digest"`
+	Parties   []string `json:"parties"`
+}
+
+type mpcSignature struct {
+	R string `json:"r"`
+	S string `json:"s"`
+}
+
+// NewMPCSigner creates a threshold signer and resolves the key's address from
+// the co-signers. At least Quorum co-signers must be reachable and all
+// reachable co-signers must report the same public key.
+func NewMPCSigner(ctx context.Context, cfg MPCConfig) (*MPCSigner, error) {
+	if len(cfg.CoSigners) == 0 || cfg.KeyID == "" {
+		return nil, fmt.Errorf("mpc co-signer urls and key id are required")
+	}
+	if cfg.Quorum < 2 || cfg.Quorum > len(cfg.CoSigners) {
+		return nil, fmt.Errorf("mpc quorum must be between 2 and the number of co-signers (%d), got %d", len(cfg.CoSigners), cfg.Quorum)
+	}
+	if cfg.SignTimeout <= 0 {
+		cfg.SignTimeout = 2 * time.Minute
+	}
+
+	s := &MPCSigner{cfg: cfg, httpClient: &http.Client{Timeout: cfg.SignTimeout}}
+	seen := make(map[string]bool)
+	for _, raw := range cfg.CoSigners {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid mpc co-signer url: %s", raw)
+		}
+		if seen[raw] {
+			return nil, fmt.Errorf("duplicate mpc co-signer url: %s", raw)
+		}
+		seen[raw] = true
+		s.parties = append(s.parties, &mpcParty{url: strings.TrimRight(raw, "/")})
+	}
+
+	reachable := 0
+	for _, p := range s.parties {
+		if err := s.resolve(ctx, p); err != nil {
+			if errors.Is(err, errMPCKeyMismatch) {
+				return nil, err
+			}
+			log.Warn().Err(err).Str("co_signer", p.url).Msg("MPC co-signer unreachable")
+			continue
+		}
+		reachable++
+	}
+	if reachable < cfg.Quorum {
+		return nil, fmt.Errorf("only %d of %d mpc co-signers reachable, quorum is %d", reachable, len(s.parties), cfg.Quorum)
+	}
+	if err := s.checkPartyIDs(); err != nil {
+		return nil, err
+	}
+	if cfg.Address != "" && !strings.EqualFold(cfg.Address, s.address.Hex()) {
+		return nil, fmt.Errorf("mpc key %s controls %s, expected %s", cfg.KeyID, s.address.Hex(), cfg.Address)
+	}
+
+	log.Info().
+		Str("key_id", cfg.KeyID).
+		Int("co_signers", len(s.parties)).
+		Int("quorum", cfg.Quorum).
+		Str("address", s.address.Hex()).
+		Msg("MPC signer initialized")
+
+	return s, nil
+}
+
+// Address implements Signer.
+func (s *MPCSigner) Address() common.Address {
+	return s.address
+}
+
+// Provider implements Signer.
+func (s *MPCSigner) Provider() string {
+	return ProviderMPC
+}
+
+// Health implements Signer. Every co-signer is asked for its key again; the
+// key is healthy while a quorum answers with the same public key.
+func (s *MPCSigner) Health(ctx context.Context) error {
+	reachable := 0
+	var errs []error
+	for _, p := range s.parties {
+		if err := s.resolve(ctx, p); err != nil {
+			if errors.Is(err, errMPCKeyMismatch) {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		reachable++
+	}
+	if err := s.checkPartyIDs(); err != nil {
+		return err
+	}
+	if reachable < s.cfg.Quorum {
+		return fmt.Errorf("only %d of %d mpc co-signers reachable, quorum is %d: %w", reachable, len(s.parties), s.cfg.Quorum, errors.Join(errs...))
+	}
+	return nil
+}
+
+// SignTransaction implements Signer by threshold-signing the transaction sighash.
+func (s *MPCSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return signTxWithHash(ctx, s, tx, chainID)
+}
+
+// SignHash implements Signer. Co-signers that fail a session are excluded and
+// the digest is signed again by another quorum while enough remain.
+func (s *MPCSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.SignTimeout)
+	defer cancel()
+
+	excluded := make(map[*mpcParty]bool)
+	for {
+		quorum, ids, err := s.quorum(ctx, excluded)
+		if err != nil {
+			return nil, err
+		}
+		sig, failed, err := s.signSession(ctx, quorum, ids, hash)
+		if err == nil {
+			return sig, nil
+		}
+		if len(failed) == 0 || ctx.Err() != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		for _, p := range failed {
+			excluded[p] = true
+			p.downUntil = time.Now().Add(mpcCooldown)
+		}
+		s.mu.Unlock()
+		log.Warn().Err(err).Int("failed_co_signers", len(failed)).Msg("MPC signing session failed, retrying with another quorum")
+	}
+}
+
+// quorum picks Quorum co-signers and their party IDs for a session, preferring
+// those not in cooldown. Co-signers not reached yet are contacted first.
+func (s *MPCSigner) quorum(ctx context.Context, excluded map[*mpcParty]bool) ([]*mpcParty, []string, error) {
+	s.mu.Lock()
+	var unresolved []*mpcParty
+	for _, p := range s.parties {
+		if p.id == "" && !excluded[p] {
+			unresolved = append(unresolved, p)
+		}
+	}
+	s.mu.Unlock()
+	for _, p := range unresolved {
+		if err := s.resolve(ctx, p); err != nil {
+			log.Warn().Err(err).Str("co_signer", p.url).Msg("MPC co-signer unavailable")
+		}
+	}
+	if err := s.checkPartyIDs(); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var ready, cooling []*mpcParty
+	for _, p := range s.parties {
+		switch {
+		case p.id == "" || excluded[p]:
+		case now.Before(p.downUntil):
+			cooling = append(cooling, p)
+		default:
+			ready = append(ready, p)
+		}
+	}
+	candidates := append(ready, cooling...)
+	if len(candidates) < s.cfg.Quorum {
+		return nil, nil, fmt.Errorf("only %d of %d mpc co-signers available, quorum is %d", len(candidates), len(s.parties), s.cfg.Quorum)
+	}
+	quorum := candidates[:s.cfg.Quorum]
+	ids := make([]string, len(quorum))
+	for i, p := range quorum {
+		ids[i] = p.id
+	}
+	return quorum, ids, nil
+}
+
+// signSession runs one signing session and returns the co-signers that failed.
+func (s *MPCSigner) signSession(ctx context.Context, quorum []*mpcParty, ids []string, hash []byte) ([]byte, []*mpcParty, error) {
+	sessionID := make([]byte, 16)
+	if _, err := rand.Read(sessionID); err != nil {
+		return nil, nil, err
+	}
+	req := mpcSignRequest{
+		SessionID: hex.EncodeToString(sessionID),
+		Digest:    "0x" + hex.EncodeToString(hash),
+		Parties:   ids,
+	}
+
+	sigs := make([]*mpcSignature, len(quorum))
+	errs := make([]error, len(quorum))
+	var wg sync.WaitGroup
+	for i, p := range quorum {
+		wg.Add(1)
+		go func(i int, p *mpcParty) {
+			defer wg.Done()
+			var sig mpcSignature
+			errs[i] = s.do(ctx, http.MethodPost, p.url+"/v1/keys/"+url.PathEscape(s.cfg.KeyID)+"/sign", req, &sig)
+			sigs[i] = &sig
+		}(i, p)
+	}
+	wg.Wait()
+
+	var failed []*mpcParty
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, quorum[i])
+			if firstErr == nil {
+				firstErr = fmt.Errorf("mpc co-signer %s: %w", quorum[i].url, err)
+			}
+		}
+	}
+	if firstErr != nil {
+		return nil, failed, firstErr
+	}
+
+	// Diverging signatures mean a misbehaving party; do not retry.
+	for _, sig := range sigs[1:] {
+		if !strings.EqualFold(sig.R, sigs[0].R) || !strings.EqualFold(sig.S, sigs[0].S) {
+			return nil, nil, fmt.Errorf("mpc session %s: co-signers returned different signatures", req.SessionID)
+		}
+	}
+	sig, err := s.recoverable(hash, sigs[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("mpc session %s: %w", req.SessionID, err)
+	}
+	return sig, nil, nil
+}
+
+// recoverable converts a co-signer signature to 65-byte [R || S || V] form
+// (see recoverableSignature).
+func (s *MPCSigner) recoverable(hash []byte, sig *mpcSignature) ([]byte, error) {
+	r, okR := new(big.Int).SetString(strings.TrimPrefix(sig.R, "0x"), 16)
+	sv, okS := new(big.Int).SetString(strings.TrimPrefix(sig.S, "0x"), 16)
+	if !okR || !okS {
+		return nil, errors.New("malformed signature")
+	}
+	return recoverableSignature(hash, r, sv, s.address)
+}
+
+// resolve fetches a co-signer's party ID and checks it holds a share of the
+// same key as the others.
+func (s *MPCSigner) resolve(ctx context.Context, p *mpcParty) error {
+	var info mpcKeyInfo
+	if err := s.do(ctx, http.MethodGet, p.url+"/v1/keys/"+url.PathEscape(s.cfg.KeyID), nil, &info); err != nil {
+		return err
+	}
+	if info.PartyID == "" {
+		return fmt.Errorf("mpc co-signer %s returned no party id", p.url)
+	}
+	pub, err := hex.DecodeString(strings.TrimPrefix(info.PublicKey, "0x"))
+	if err != nil {
+		return fmt.Errorf("mpc co-signer %s returned a malformed public key", p.url)
+	}
+	address, err := publicKeyAddress(pub)
+	if err != nil {
+		return fmt.Errorf("mpc co-signer %s: %w", p.url, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The address is set by the first co-signer reached during construction.
+	if s.address == (common.Address{}) {
+		s.address = address
+	} else if address != s.address {
+		return fmt.Errorf("%w: %s for key %s", errMPCKeyMismatch, p.url, s.cfg.KeyID)
+	}
+	p.id = info.PartyID
+	return nil
+}
+
+// checkPartyIDs rejects co-signers reporting the same party ID (the same share
+// behind two URLs would let fewer machines than Quorum sign).
+func (s *MPCSigner) checkPartyIDs() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make(map[string]string)
+	for _, p := range s.parties {
+		if p.id == "" {
+			continue
+		}
+		if other, ok := ids[p.id]; ok {
+			return fmt.Errorf("mpc co-signers %s and %s report the same party id %s", other, p.url, p.id)
+		}
+		ids[p.id] = p.url
+	}
+	return nil
+}
+
+// do performs an authenticated co-signer API call.
+func (s *MPCSigner) do(ctx context.Context, method, endpoint string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if s.cfg.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.AuthToken)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: status %d: %s", method, endpoint, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode co-signer response: %w", err)
+	}
+	return nil
+}
+
+// publicKeyAddress derives the address of a compressed or uncompressed
+// secp256k1 public key.
+func publicKeyAddress(pub []byte) (common.Address, error) {
+	if len(pub) == 33 {
+		key, err := crypto.DecompressPubkey(pub)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid mpc public key: %w", err)
+		}
+		return crypto.PubkeyToAddress(*key), nil
+	}
+	key, err := crypto.UnmarshalPubkey(pub)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid mpc public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
@@ -0,0 +1,198 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// PreviousKey is a key rotated out of use. During its transition window it
+// still signs for its own address so in-flight transactions can be replaced
+// and its remaining balance drained to the active key.
+type PreviousKey struct {
+	Config
+	RetireAt time.Time // end of the transition window; zero keeps the key indefinitely
+}
+
+// KeyVersion describes one version of a rotating key.
+type KeyVersion struct {
+	Version  string         `json:"version"`
+	Address  common.Address `json:"address"`
+	Provider string         `json:"provider"`
+	Active   bool           `json:"active"`              // new transactions are signed with this version
+	RetireAt *time.Time     `json:"retire_at,omitempty"` // previous versions: end of the transition window
+}
+
+// Versioned is implemented by signers whose key can be rotated.
+type Versioned interface {
+	// KeyVersions lists the active version first, then previous versions
+	// that are still within their transition window at now.
+	KeyVersions(now time.Time) []KeyVersion
+	// ForAddress returns the signer of the version controlling addr, if it is
+	// the active version or a previous version within its window at now.
+	ForAddress(addr common.Address, now time.Time) (Signer, bool)
+}
+
+// RotatingSigner signs with the active key version and keeps previous
+// versions available for their own addresses until they retire.
+type RotatingSigner struct {
+	active   rotatedKey
+	previous []rotatedKey
+}
+
+type rotatedKey struct {
+	version  string
+	signer   Signer
+	retireAt time.Time
+}
+
+// NewRotatingSigner creates the active signer from cfg and one signer per
+// cfg.Previous entry. Previous keys past their window are skipped.
+func NewRotatingSigner(ctx context.Context, cfg Config) (*RotatingSigner, error) {
+	active, err := newKeySigner(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &RotatingSigner{active: rotatedKey{version: keyVersion(cfg.Version, 0, len(cfg.Previous)), signer: active}}
+	seen := map[common.Address]bool{active.Address(): true}
+	for i, prev := range cfg.Previous {
+		version := keyVersion(prev.Version, i+1, len(cfg.Previous))
+		if !prev.RetireAt.IsZero() && !time.Now().Before(prev.RetireAt) {
+			continue
+		}
+		signer, err := newKeySigner(ctx, prev.Config)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %s: %w", version, err)
+		}
+		if seen[signer.Address()] {
+			return nil, fmt.Errorf("previous key %s has the same address %s as another version", version, signer.Address().Hex())
+		}
+		seen[signer.Address()] = true
+		s.previous = append(s.previous, rotatedKey{version: version, signer: signer, retireAt: prev.RetireAt})
+	}
+	return s, nil
+}
+
+// keyVersion defaults the label of version i (0 = active) when unset: with
+// one previous key the versions are "previous" and "current".
+func keyVersion(label string, i, previous int) string {
+	if label != "" {
+		return label
+	}
+	if i == 0 {
+		return "current"
+	}
+	if previous == 1 {
+		return "previous"
+	}
+	return fmt.Sprintf("previous-%d", i)
+}
+
+// KeyVersions implements Versioned.
+func (s *RotatingSigner) KeyVersions(now time.Time) []KeyVersion {
+	versions := []KeyVersion{{
+		Version:  s.active.version,
+		Address:  s.active.signer.Address(),
+		Provider: s.active.signer.Provider(),
+		Active:   true,
+	}}
+	for _, k := range s.previous {
+		if !k.usable(now) {
+			continue
+		}
+		v := KeyVersion{Version: k.version, Address: k.signer.Address(), Provider: k.signer.Provider()}
+		if !k.retireAt.IsZero() {
+			retireAt := k.retireAt
+			v.RetireAt = &retireAt
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// ForAddress implements Versioned.
+func (s *RotatingSigner) ForAddress(addr common.Address, now time.Time) (Signer, bool) {
+	if s.active.signer.Address() == addr {
+		return s.active.signer, true
+	}
+	for _, k := range s.previous {
+		if k.signer.Address() == addr && k.usable(now) {
+			return k.signer, true
+		}
+	}
+	return nil, false
+}
+
+func (k rotatedKey) usable(now time.Time) bool {
+	return k.retireAt.IsZero() || now.Before(k.retireAt)
+}
+
+// Address implements Signer.
+func (s *RotatingSigner) Address() common.Address {
+	return s.active.signer.Address()
+}
+
+// SignHash implements Signer.
+func (s *RotatingSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return s.active.signer.SignHash(ctx, hash)
+}
+
+// SignHashes implements BatchSigner.
+func (s *RotatingSigner) SignHashes(ctx context.Context, hashes [][]byte) ([][]byte, error) {
+	return SignHashes(ctx, s.active.signer, hashes)
+}
+
+// SignTransaction implements Signer.
+func (s *RotatingSigner) SignTransaction(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.active.signer.SignTransaction(ctx, tx, chainID)
+}
+
+// Health implements Signer. Previous keys within their transition window
+// are checked too, since they still sign for their own addresses.
+func (s *RotatingSigner) Health(ctx context.Context) error {
+	var errs []error
+	if err := s.active.signer.Health(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("key %s: %w", s.active.version, err))
+	}
+	now := time.Now()
+	for _, k := range s.previous {
+		if !k.usable(now) {
+			continue
+		}
+		if err := k.signer.Health(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("key %s: %w", k.version, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Provider implements Signer.
+func (s *RotatingSigner) Provider() string {
+	return s.active.signer.Provider()
+}
+
+// KeyVersions returns the versions of s: those of a Versioned signer, or
+// s itself as the only, active version.
+func KeyVersions(s Signer, now time.Time) []KeyVersion {
+	if v, ok := s.(Versioned); ok {
+		return v.KeyVersions(now)
+	}
+	return []KeyVersion{{Version: "current", Address: s.Address(), Provider: s.Provider(), Active: true}}
+}
+
+// SignerForAddress returns the signer controlling addr: s itself, or one of
+// its previous key versions within the transition window.
+func SignerForAddress(s Signer, addr common.Address, now time.Time) (Signer, bool) {
+	if v, ok := s.(Versioned); ok {
+		return v.ForAddress(addr, now)
+	}
+	if s.Address() == addr {
+		return s, true
+	}
+	return nil, false
+}
@@ -0,0 +1,226 @@
+package kms
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet/trezor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/karalabe/hid"
+	"google.golang.org/protobuf/proto"
+)
+
+var errTrezorInvalidReply = errors.New("trezor: invalid reply")
+
+// trezorDevice speaks the Trezor protobuf protocol over HID or WebUSB.
+type trezorDevice struct {
+	dev    io.ReadWriteCloser
+	prompt func(kind string) (string, error)
+}
+
+func openTrezor(dev hid.Device, prompt func(string) (string, error)) (hardwareDevice, error) {
+	t := &trezorDevice{dev: dev, prompt: prompt}
+	if _, err := t.exchange(&trezor.Initialize{}, new(trezor.Features)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *trezorDevice) address(path accounts.DerivationPath, display bool) (common.Address, error) {
+	reply := new(trezor.EthereumAddress)
+	if _, err := t.exchange(&trezor.EthereumGetAddress{AddressN: path, ShowDisplay: &display}, reply); err != nil {
+		return common.Address{}, err
+	}
+	if addr := reply.GetAddressHex(); common.IsHexAddress(addr) {
+		return common.HexToAddress(addr), nil
+	}
+	if addr := reply.GetAddressBin(); len(addr) == common.AddressLength { // firmware before 1.8
+		return common.BytesToAddress(addr), nil
+	}
+	return common.Address{}, errTrezorInvalidReply
+}
+
+// signTx signs an EIP-155 legacy transaction; the protocol messages of the
+// Ethereum app do not cover typed transactions. Calldata beyond the first
+// 1024 bytes is streamed on the device's request.
+func (t *trezorDevice) signTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if tx.Type() != types.LegacyTxType {
+		return nil, fmt.Errorf("trezor: only legacy transactions are supported, got type %d", tx.Type())
+	}
+	if !chainID.IsUint64() || chainID.Uint64() > math.MaxUint32 {
+		return nil, fmt.Errorf("trezor: chain ID %s does not fit in 32 bits", chainID)
+	}
+	data := tx.Data()
+	length := uint32(len(data))
+	id := uint32(chainID.Uint64())
+	req := &trezor.EthereumSignTx{
+		AddressN:   path,
+		Nonce:      new(big.Int).SetUint64(tx.Nonce()).Bytes(),
+		GasPrice:   tx.GasPrice().Bytes(),
+		GasLimit:   new(big.Int).SetUint64(tx.Gas()).Bytes(),
+		Value:      tx.Value().Bytes(),
+		DataLength: &length,
+		ChainId:    &id,
+	}
+	if to := tx.To(); to != nil {
+		hex := to.Hex()
+		req.ToHex, req.ToBin = &hex, to.Bytes()
+	}
+	n := min(len(data), 1024)
+	req.DataInitialChunk, data = data[:n], data[n:]
+
+	reply := new(trezor.EthereumTxRequest)
+	if _, err := t.exchange(req, reply); err != nil {
+		return nil, err
+	}
+	for reply.DataLength != nil {
+		n := int(reply.GetDataLength())
+		if n > len(data) {
+			return nil, errTrezorInvalidReply
+		}
+		var chunk []byte
+		chunk, data = data[:n], data[n:]
+		reply = new(trezor.EthereumTxRequest)
+		if _, err := t.exchange(&trezor.EthereumTxAck{DataChunk: chunk}, reply); err != nil {
+			return nil, err
+		}
+	}
+	if len(reply.GetSignatureR()) == 0 || len(reply.GetSignatureS()) == 0 {
+		return nil, errTrezorInvalidReply
+	}
+	sig := make([]byte, 65)
+	new(big.Int).SetBytes(reply.GetSignatureR()).FillBytes(sig[:32])
+	new(big.Int).SetBytes(reply.GetSignatureS()).FillBytes(sig[32:64])
+	// Firmware returns either the EIP-155 V or the bare recovery id.
+	v := uint64(reply.GetSignatureV())
+	switch eip155 := chainID.Uint64()*2 + 35; {
+	case v >= eip155:
+		v -= eip155
+	case v >= 27:
+		v -= 27
+	}
+	if v > 1 {
+		return nil, errTrezorInvalidReply
+	}
+	sig[64] = byte(v)
+	return tx.WithSignature(types.LatestSignerForChainID(chainID), sig)
+}
+
+func (t *trezorDevice) Close() error {
+	return t.dev.Close()
+}
+
+// exchange sends req and returns the index of the reply message in results.
+// Button, PIN and passphrase requests are answered along the way.
+func (t *trezorDevice) exchange(req proto.Message, results ...proto.Message) (int, error) {
+	for {
+		kind, reply, err := t.roundTrip(req)
+		if err != nil {
+			return 0, err
+		}
+		switch kind {
+		case trezor.Type(&trezor.Failure{}):
+			failure := new(trezor.Failure)
+			if err := proto.Unmarshal(reply, failure); err != nil {
+				return 0, err
+			}
+			return 0, errors.New("trezor: " + failure.GetMessage())
+		case trezor.Type(&trezor.ButtonRequest{}):
+			req = &trezor.ButtonAck{}
+			continue
+		case trezor.Type(&trezor.PinMatrixRequest{}):
+			pin, err := t.ask("pin")
+			if err != nil {
+				return 0, err
+			}
+			req = &trezor.PinMatrixAck{Pin: &pin}
+			continue
+		case trezor.Type(&trezor.PassphraseRequest{}):
+			request := new(trezor.PassphraseRequest)
+			if err := proto.Unmarshal(reply, request); err != nil {
+				return 0, err
+			}
+			if request.GetOnDevice() {
+				req = &trezor.PassphraseAck{}
+				continue
+			}
+			passphrase, err := t.ask("passphrase")
+			if err != nil {
+				return 0, err
+			}
+			req = &trezor.PassphraseAck{Passphrase: &passphrase}
+			continue
+		}
+		for i, res := range results {
+			if trezor.Type(res) == kind {
+				return i, proto.Unmarshal(reply, res)
+			}
+		}
+		return 0, fmt.Errorf("trezor: unexpected reply %s", trezor.Name(kind))
+	}
+}
+
+func (t *trezorDevice) ask(kind string) (string, error) {
+	if t.prompt == nil {
+		return "", fmt.Errorf("trezor: device requests a %s but no prompt is configured", kind)
+	}
+	return t.prompt(kind)
+}
+
+// roundTrip frames one message into 64-byte reports of
+// [0x3f | payload], where the payload of the first report starts with
+// "##", the message type (2 bytes) and the message length (4 bytes).
+func (t *trezorDevice) roundTrip(req proto.Message) (uint16, []byte, error) {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, 8, 8+len(data))
+	payload[0], payload[1] = '#', '#'
+	binary.BigEndian.PutUint16(payload[2:], trezor.Type(req))
+	binary.BigEndian.PutUint32(payload[4:], uint32(len(data)))
+	payload = append(payload, data...)
+
+	report := make([]byte, 64)
+	for len(payload) > 0 {
+		clear(report)
+		report[0] = '?'
+		n := copy(report[1:], payload)
+		payload = payload[n:]
+		if _, err := t.dev.Write(report); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var kind uint16
+	var reply []byte
+	for first := true; ; first = false {
+		if _, err := io.ReadFull(t.dev, report); err != nil {
+			return 0, nil, err
+		}
+		if report[0] != '?' || (first && (report[1] != '#' || report[2] != '#')) {
+			return 0, nil, errTrezorInvalidReply
+		}
+		chunk := report[1:]
+		if first {
+			kind = binary.BigEndian.Uint16(report[3:])
+			size := binary.BigEndian.Uint32(report[5:])
+			if size > 1<<20 {
+				return 0, nil, errTrezorInvalidReply
+			}
+			reply = make([]byte, 0, size)
+			chunk = report[9:]
+		}
+		left := cap(reply) - len(reply)
+		if left <= len(chunk) {
+			return kind, append(reply, chunk[:left]...), nil
+		}
+		reply = append(reply, chunk...)
+	}
+}
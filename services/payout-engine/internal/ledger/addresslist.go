@@ -0,0 +1,93 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// 收款地址名单类型
+const (
+	ListAllow = "allow"
+	ListDeny  = "deny"
+)
+
+// AddressListEntry 收款地址名单条目。UserID 为空表示所有用户，ChainID 为 0 表示所有链。
+type AddressListEntry struct {
+	List      string    `json:"list"`
+	UserID    string    `json:"user_id"`
+	ChainID   uint64    `json:"chain_id"`
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddressListQuery 名单查询条件 (零值不过滤)
+type AddressListQuery struct {
+	List    string
+	UserID  string
+	ChainID uint64
+	Address string
+}
+
+// AddAddressListEntry 添加名单条目，已存在时更新原因和创建人
+func (s *Store) AddAddressListEntry(ctx context.Context, e *AddressListEntry) error {
+	return s.db.QueryRowContext(ctx, `
+INSERT INTO payout_address_lists (list, user_id, chain_id, address, reason, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (list, user_id, chain_id, address) DO UPDATE SET
+    reason     = EXCLUDED.reason,
+    created_by = EXCLUDED.created_by
+RETURNING created_at`, e.List, e.UserID, int64(e.ChainID), e.Address, e.Reason, e.CreatedBy).Scan(&e.CreatedAt)
+}
+
+// RemoveAddressListEntry 删除名单条目，返回是否存在
+func (s *Store) RemoveAddressListEntry(ctx context.Context, list, userID string, chainID uint64, address string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+DELETE FROM payout_address_lists
+WHERE list = $1 AND user_id = $2 AND chain_id = $3 AND address = $4`, list, userID, int64(chainID), address)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// AddressListEntries 按条件列出名单条目
+func (s *Store) AddressListEntries(ctx context.Context, q AddressListQuery) ([]AddressListEntry, error) {
+	return s.queryAddressLists(ctx, `
+SELECT list, user_id, chain_id, address, reason, created_by, created_at
+FROM payout_address_lists
+WHERE ($1 = '' OR list = $1) AND ($2 = '' OR user_id = $2) AND ($3 = 0 OR chain_id = $3) AND ($4 = '' OR address = $4)
+ORDER BY created_at DESC, address`, q.List, q.UserID, int64(q.ChainID), q.Address)
+}
+
+// MatchAddressLists 返回适用于该用户和链、且包含任一地址的名单条目 (含全局条目)
+func (s *Store) MatchAddressLists(ctx context.Context, userID string, chainID uint64, addresses []string) ([]AddressListEntry, error) {
+	return s.queryAddressLists(ctx, `
+SELECT list, user_id, chain_id, address, reason, created_by, created_at
+FROM payout_address_lists
+WHERE user_id IN ('', $1) AND chain_id IN (0, $2) AND address = ANY($3)`, userID, int64(chainID), pq.Array(addresses))
+}
+
+func (s *Store) queryAddressLists(ctx context.Context, query string, args ...interface{}) ([]AddressListEntry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AddressListEntry{}
+	for rows.Next() {
+		var e AddressListEntry
+		var chainID int64
+		if err := rows.Scan(&e.List, &e.UserID, &chainID, &e.Address, &e.Reason, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.ChainID = uint64(chainID)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
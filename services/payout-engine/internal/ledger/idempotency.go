@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+)
+
+// SaveIdempotentResponse 保存幂等键的原始响应，同一键再次使用 (保留期已过) 时覆盖
+func (s *Store) SaveIdempotentResponse(ctx context.Context, userID, key, requestHash string, response json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO payout_idempotency_keys (user_id, idempotency_key, request_hash, response)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    response     = EXCLUDED.response,
+    created_at   = EXCLUDED.created_at`, userID, key, requestHash, []byte(response))
+	return err
+}
+
+// IdempotentResponse 幂等键在 since 之后保存的响应，不存在时返回 nil
+func (s *Store) IdempotentResponse(ctx context.Context, userID, key string, since time.Time) (*queue.IdempotencyRecord, error) {
+	var record queue.IdempotencyRecord
+	var response []byte
+	err := s.db.QueryRowContext(ctx, `
+SELECT request_hash, response, created_at FROM payout_idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 AND created_at > $3`, userID, key, since.UTC()).
+		Scan(&record.RequestHash, &response, &record.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.Response = response
+	return &record, nil
+}
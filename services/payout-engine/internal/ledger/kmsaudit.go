@@ -0,0 +1,31 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/kms"
+)
+
+// KMSAuditSink 将签名服务调用写入 kms_signing_audit 表
+func (s *Store) KMSAuditSink() kms.AuditSink {
+	return kmsAuditSink{s}
+}
+
+type kmsAuditSink struct {
+	store *Store
+}
+
+// RecordAudit 实现 kms.AuditSink
+func (k kmsAuditSink) RecordAudit(ctx context.Context, r kms.AuditRecord) error {
+	_, err := k.store.db.ExecContext(ctx, `
+INSERT INTO kms_signing_audit (
+    provider, key_path, address, method, digest, chain_id, requestor, user_id, batch_id, job_id,
+    purpose, correlation_id, started_at, latency_ms, success, error
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		r.Provider, r.KeyPath, r.Address.Hex(), r.Method, r.Digest, int64(r.ChainID), r.Caller.Requestor, r.Caller.UserID,
+		r.Caller.BatchID, r.Caller.JobID, r.Caller.Purpose, r.Caller.CorrelationID, r.StartedAt.UTC(),
+		float64(r.Latency)/float64(time.Millisecond), r.Success, r.Error,
+	)
+	return err
+}
@@ -0,0 +1,220 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Store Postgres 任务账本: 任务最新状态 (payout_jobs) 和状态变更历史 (payout_job_attempts)。
+// Redis 中的任务状态有保留期，账本用于长期查询、报表和对账。
+type Store struct {
+	db *sql.DB
+}
+
+// Attempt 一条状态变更记录
+type Attempt struct {
+	UserID     string         `json:"user_id"`
+	BatchID    string         `json:"batch_id"`
+	Attempt    int            `json:"attempt"`
+	State      queue.JobState `json:"state"`
+	TxHash     string         `json:"tx_hash,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	GasFee     string         `json:"gas_fee,omitempty"`
+	RecordedAt time.Time      `json:"recorded_at"`
+}
+
+// Open 连接数据库并建表
+func Open(ctx context.Context, dbURL string) (*Store, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	db.SetMaxOpenConns(5)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to apply ledger schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close 关闭连接
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+const upsertJob = `
+INSERT INTO payout_jobs (
+    user_id, batch_id, job_id, chain_id, to_address, amount, token_address, token_symbol,
+    state, tx_hash, error, retry_count, gas_fee, created_at, updated_at, finalized_at, correlation_id,
+    reversal_tx_hash, reversed_amount, reversed_at
+) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::numeric, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12, NULLIF($13, '')::numeric, $14, $15, $16, NULLIF($17, ''),
+    NULLIF($18, ''), NULLIF($19, '')::numeric, $20)
+ON CONFLICT (user_id, batch_id, job_id) DO UPDATE SET
+    state            = EXCLUDED.state,
+    tx_hash          = COALESCE(EXCLUDED.tx_hash, payout_jobs.tx_hash),
+    error            = EXCLUDED.error,
+    retry_count      = EXCLUDED.retry_count,
+    gas_fee          = COALESCE(EXCLUDED.gas_fee, payout_jobs.gas_fee),
+    updated_at       = EXCLUDED.updated_at,
+    finalized_at     = COALESCE(payout_jobs.finalized_at, EXCLUDED.finalized_at),
+    correlation_id   = COALESCE(payout_jobs.correlation_id, EXCLUDED.correlation_id),
+    reversal_tx_hash = COALESCE(EXCLUDED.reversal_tx_hash, payout_jobs.reversal_tx_hash),
+    reversed_amount  = COALESCE(EXCLUDED.reversed_amount, payout_jobs.reversed_amount),
+    reversed_at      = COALESCE(EXCLUDED.reversed_at, payout_jobs.reversed_at)
+WHERE payout_jobs.updated_at <= EXCLUDED.updated_at`
+
+const insertAttempt = `
+INSERT INTO payout_job_attempts (entry_id, user_id, batch_id, job_id, attempt, state, tx_hash, error, gas_fee, recorded_at)
+VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, '')::numeric, $10)
+ON CONFLICT (entry_id) DO NOTHING`
+
+// Record 在一个事务中写入一组记录。记录按 entry ID 去重，重复写入无副作用；
+// 乱序到达的旧状态不会覆盖较新的状态。
+func (s *Store) Record(ctx context.Context, entries []*queue.LedgerEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	jobStmt, err := tx.PrepareContext(ctx, upsertJob)
+	if err != nil {
+		return err
+	}
+	attemptStmt, err := tx.PrepareContext(ctx, insertAttempt)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		st := e.Status
+		var finalizedAt sql.NullTime
+		if st.State.Terminal() {
+			finalizedAt = sql.NullTime{Time: st.UpdatedAt, Valid: true}
+		}
+		createdAt := st.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = e.RecordedAt
+		}
+		var reversalTx, reversedAmount string
+		var reversedAt sql.NullTime
+		if r := st.Reversal; r != nil {
+			reversalTx, reversedAmount = r.TxHash, r.Amount
+			reversedAt = sql.NullTime{Time: r.RecordedAt, Valid: true}
+		}
+		if _, err := jobStmt.ExecContext(ctx,
+			st.UserID, st.BatchID, st.ID, int64(st.ChainID), st.ToAddress, st.Amount, st.Asset(), st.TokenSymbol,
+			string(st.State), st.TxHash, st.Error, st.RetryCount, st.GasFee, createdAt, st.UpdatedAt, finalizedAt, st.CorrelationID,
+			reversalTx, reversedAmount, reversedAt,
+		); err != nil {
+			return fmt.Errorf("failed to record job %s: %w", st.ID, err)
+		}
+		if _, err := attemptStmt.ExecContext(ctx,
+			e.ID, st.UserID, st.BatchID, st.ID, st.RetryCount, string(st.State), st.TxHash, st.Error, st.GasFee, e.RecordedAt,
+		); err != nil {
+			return fmt.Errorf("failed to record job %s attempt: %w", st.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Attempts 返回任务的状态变更历史 (按时间顺序)，userID 为空时不按用户过滤
+func (s *Store) Attempts(ctx context.Context, userID, jobID string) ([]Attempt, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT user_id, batch_id, attempt, state, COALESCE(tx_hash, ''), COALESCE(error, ''), COALESCE(gas_fee::text, ''), recorded_at
+FROM payout_job_attempts
+WHERE job_id = $1 AND ($2 = '' OR user_id = $2)
+ORDER BY recorded_at, entry_id`, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []Attempt{}
+	for rows.Next() {
+		var a Attempt
+		var state string
+		if err := rows.Scan(&a.UserID, &a.BatchID, &a.Attempt, &state, &a.TxHash, &a.Error, &a.GasFee, &a.RecordedAt); err != nil {
+			return nil, err
+		}
+		a.State = queue.JobState(state)
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// GasUsageQuery 网络费统计条件 (UserID、ChainID 为零值时不过滤)
+type GasUsageQuery struct {
+	UserID   string
+	ChainID  uint64
+	From     time.Time // 上链时间下限 (含)
+	To       time.Time // 上链时间上限 (不含)
+	Interval string    // 统计周期: day, week, month (UTC)
+}
+
+// GasUsage 一条链在一个统计周期内的网络费。按交易去重: 一笔交易包含多个任务时网络费只计一次。
+type GasUsage struct {
+	ChainID        uint64
+	Period         time.Time
+	Payouts        int64  // 已上链且有网络费记录的任务数
+	Transactions   int64  // 交易数
+	Fee            string // 网络费合计 (原生代币最小单位)
+	SingleTxs      int64  // 只包含一个任务的交易数
+	SingleFee      string
+	BatchedPayouts int64 // 与其他任务合并在同一交易中的任务数 (智能账户批量、multicall)
+	BatchedFee     string
+}
+
+// GasUsage 按链和周期汇总已上链任务的网络费
+func (s *Store) GasUsage(ctx context.Context, q GasUsageQuery) ([]GasUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+WITH txs AS (
+    SELECT chain_id,
+           date_trunc($5, MIN(finalized_at) AT TIME ZONE 'UTC') AS period,
+           COUNT(*) AS payouts,
+           MAX(gas_fee) AS fee
+    FROM payout_jobs
+    WHERE state = 'confirmed' AND tx_hash IS NOT NULL AND gas_fee IS NOT NULL
+      AND finalized_at >= $1 AND finalized_at < $2
+      AND ($3 = 0 OR chain_id = $3)
+      AND ($4 = '' OR user_id = $4)
+    GROUP BY chain_id, tx_hash
+)
+SELECT chain_id, period, SUM(payouts), COUNT(*), SUM(fee)::text,
+       COUNT(*) FILTER (WHERE payouts = 1), COALESCE(SUM(fee) FILTER (WHERE payouts = 1), 0)::text,
+       COALESCE(SUM(payouts) FILTER (WHERE payouts > 1), 0), COALESCE(SUM(fee) FILTER (WHERE payouts > 1), 0)::text
+FROM txs
+GROUP BY chain_id, period
+ORDER BY chain_id, period`, q.From, q.To, int64(q.ChainID), q.UserID, q.Interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []GasUsage
+	for rows.Next() {
+		var u GasUsage
+		var chainID int64
+		if err := rows.Scan(&chainID, &u.Period, &u.Payouts, &u.Transactions, &u.Fee,
+			&u.SingleTxs, &u.SingleFee, &u.BatchedPayouts, &u.BatchedFee); err != nil {
+			return nil, err
+		}
+		u.ChainID = uint64(chainID)
+		u.Period = time.Date(u.Period.Year(), u.Period.Month(), u.Period.Day(), 0, 0, 0, 0, time.UTC)
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,145 @@
+-- payout-engine 任务账本 (启动时执行，可重复执行)
+
+-- 任务最新状态
+CREATE TABLE IF NOT EXISTS payout_jobs (
+    user_id       TEXT NOT NULL,
+    batch_id      TEXT NOT NULL,
+    job_id        TEXT NOT NULL,
+    chain_id      BIGINT NOT NULL,
+    to_address    TEXT NOT NULL,
+    amount        NUMERIC(78, 0),          -- 最小单位
+    token_address TEXT NOT NULL DEFAULT '', -- 原生代币为 ''
+    token_symbol  TEXT NOT NULL DEFAULT '',
+    state         TEXT NOT NULL,           -- pending, processing, retrying, confirmed, failed, cancelled
+    tx_hash       TEXT,
+    error         TEXT,
+    retry_count   INTEGER NOT NULL DEFAULT 0,
+    gas_fee       NUMERIC(78, 0),          -- 上链后实际网络费 (仅 EVM 转账)
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    finalized_at  TIMESTAMPTZ,             -- 进入终态的时间
+    PRIMARY KEY (user_id, batch_id, job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_updated_at ON payout_jobs (updated_at);
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_state ON payout_jobs (state);
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_tx_hash ON payout_jobs (tx_hash) WHERE tx_hash IS NOT NULL;
+
+-- 任务状态变更历史 (每次尝试、交易哈希和错误)
+CREATE TABLE IF NOT EXISTS payout_job_attempts (
+    entry_id    TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL,
+    batch_id    TEXT NOT NULL,
+    job_id      TEXT NOT NULL,
+    attempt     INTEGER NOT NULL, -- 记录时的重试次数
+    state       TEXT NOT NULL,
+    tx_hash     TEXT,
+    error       TEXT,
+    gas_fee     NUMERIC(78, 0),
+    recorded_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_job_attempts_job ON payout_job_attempts (user_id, batch_id, job_id, recorded_at);
+
+-- 网络费统计按上链时间查询
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_finalized_at ON payout_jobs (finalized_at) WHERE state = 'confirmed';
+
+-- 收款地址名单: deny 拒绝付款，allow 用于大额批次的白名单要求
+CREATE TABLE IF NOT EXISTS payout_address_lists (
+    list       TEXT NOT NULL,             -- allow, deny
+    user_id    TEXT NOT NULL DEFAULT '',  -- '' = 所有用户
+    chain_id   BIGINT NOT NULL DEFAULT 0, -- 0 = 所有链
+    address    TEXT NOT NULL,             -- EVM 地址小写，TRON 保持原样
+    reason     TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (list, user_id, chain_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_address_lists_address ON payout_address_lists (address);
+
+-- 跨服务关联 ID: 按提交请求检索任务
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS correlation_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_correlation_id ON payout_jobs (correlation_id) WHERE correlation_id IS NOT NULL;
+
+-- 签名审计日志: 每次签名操作 (含失败) 一条记录，只追加。
+-- hash = sha256(上一条 hash + 本条内容)，篡改或删除任一条都会使之后的链校验失败。
+CREATE TABLE IF NOT EXISTS payout_signing_log (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        TEXT NOT NULL DEFAULT '', -- 发起签名的租户 ('' = 引擎自身，如 gas 补充)
+    batch_id       TEXT NOT NULL DEFAULT '',
+    job_id         TEXT NOT NULL DEFAULT '',
+    chain_id       BIGINT NOT NULL,
+    purpose        TEXT NOT NULL,            -- payout, replacement, nonce_fill, canary, unwrap, user_operation, manifest, gas_tank, permit
+    digest         TEXT NOT NULL,            -- 被签名的 32 字节摘要 (hex)
+    provider       TEXT NOT NULL,            -- local, fireblocks
+    key_id         TEXT NOT NULL,            -- 签名地址
+    requestor      TEXT NOT NULL,
+    result         TEXT NOT NULL,            -- signed, failed
+    error          TEXT NOT NULL DEFAULT '',
+    correlation_id TEXT NOT NULL DEFAULT '',
+    signed_at      TIMESTAMPTZ NOT NULL,
+    prev_hash      TEXT NOT NULL,
+    hash           TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_signing_log_job ON payout_signing_log (job_id) WHERE job_id <> '';
+CREATE INDEX IF NOT EXISTS idx_payout_signing_log_signed_at ON payout_signing_log (signed_at);
+
+CREATE OR REPLACE FUNCTION payout_signing_log_append_only() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'payout_signing_log is append-only';
+END
+$$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'payout_signing_log_append_only') THEN
+        CREATE TRIGGER payout_signing_log_append_only
+            BEFORE UPDATE OR DELETE OR TRUNCATE ON payout_signing_log
+            FOR EACH STATEMENT EXECUTE PROCEDURE payout_signing_log_append_only();
+    END IF;
+END
+$$;
+
+-- 收款方退回款项: 关联的退回交易和金额
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS reversal_tx_hash TEXT;
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS reversed_amount NUMERIC(78, 0);
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_reversal_tx_hash ON payout_jobs (reversal_tx_hash) WHERE reversal_tx_hash IS NOT NULL;
+
+-- KMS 签名调用审计: 每次调用签名服务一条 (含被限速拒绝和失败的调用)
+CREATE TABLE IF NOT EXISTS kms_signing_audit (
+    id             BIGSERIAL PRIMARY KEY,
+    provider       TEXT NOT NULL,
+    key_path       TEXT NOT NULL,            -- 签名服务中的密钥 (fireblocks:vault/<id>/<asset>、mpc:<key_id>、local:<address>)
+    address        TEXT NOT NULL,
+    method         TEXT NOT NULL,            -- sign_hash, sign_transaction
+    digest         TEXT NOT NULL,
+    chain_id       BIGINT NOT NULL DEFAULT 0,
+    requestor      TEXT NOT NULL DEFAULT '',
+    user_id        TEXT NOT NULL DEFAULT '',
+    batch_id       TEXT NOT NULL DEFAULT '',
+    job_id         TEXT NOT NULL DEFAULT '',
+    purpose        TEXT NOT NULL DEFAULT '',
+    correlation_id TEXT NOT NULL DEFAULT '',
+    started_at     TIMESTAMPTZ NOT NULL,
+    latency_ms     DOUBLE PRECISION NOT NULL,
+    success        BOOLEAN NOT NULL,
+    error          TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_kms_signing_audit_started_at ON kms_signing_audit (started_at);
+CREATE INDEX IF NOT EXISTS idx_kms_signing_audit_address ON kms_signing_audit (lower(address), started_at);
+
+-- 已完成请求的幂等响应: Redis 中的记录被淘汰后仍按保留期返回原始响应
+CREATE TABLE IF NOT EXISTS payout_idempotency_keys (
+    user_id         TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash    TEXT NOT NULL,
+    response        JSONB NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, idempotency_key)
+);
@@ -0,0 +1,173 @@
+package ledger
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 签名结果
+const (
+	SigningResultSigned = "signed"
+	SigningResultFailed = "failed"
+)
+
+// signingLogLock 串行写入签名日志的 advisory lock (多实例共享一条哈希链)
+const signingLogLock = 0x7061796f7574 // "payout"
+
+// SigningRecord 签名审计日志的一条记录
+type SigningRecord struct {
+	ID            int64     `json:"id"`
+	UserID        string    `json:"user_id,omitempty"`
+	BatchID       string    `json:"batch_id,omitempty"`
+	JobID         string    `json:"job_id,omitempty"`
+	ChainID       uint64    `json:"chain_id"`
+	Purpose       string    `json:"purpose"`
+	Digest        string    `json:"digest"`
+	Provider      string    `json:"provider"`
+	KeyID         string    `json:"key_id"`
+	Requestor     string    `json:"requestor"`
+	Result        string    `json:"result"`
+	Error         string    `json:"error,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	SignedAt      time.Time `json:"signed_at"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+// ComputeHash 记录哈希: sha256(prev_hash + 各字段的 JSON)，时间按微秒 UTC 计算 (与 Postgres 精度一致)
+func (r *SigningRecord) ComputeHash() string {
+	body, _ := json.Marshal([]interface{}{
+		r.UserID, r.BatchID, r.JobID, r.ChainID, r.Purpose, r.Digest, r.Provider, r.KeyID,
+		r.Requestor, r.Result, r.Error, r.CorrelationID, r.SignedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(append([]byte(r.PrevHash), body...))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordSigning 追加一条签名记录，填写 ID、PrevHash 和 Hash
+func (s *Store) RecordSigning(ctx context.Context, r *SigningRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(signingLogLock)); err != nil {
+		return err
+	}
+	var prev string
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM payout_signing_log ORDER BY id DESC LIMIT 1`).Scan(&prev)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	r.SignedAt = r.SignedAt.UTC().Truncate(time.Microsecond)
+	r.PrevHash = prev
+	r.Hash = r.ComputeHash()
+	if err := tx.QueryRowContext(ctx, `
+INSERT INTO payout_signing_log (
+    user_id, batch_id, job_id, chain_id, purpose, digest, provider, key_id,
+    requestor, result, error, correlation_id, signed_at, prev_hash, hash
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id`,
+		r.UserID, r.BatchID, r.JobID, int64(r.ChainID), r.Purpose, r.Digest, r.Provider, r.KeyID,
+		r.Requestor, r.Result, r.Error, r.CorrelationID, r.SignedAt, r.PrevHash, r.Hash,
+	).Scan(&r.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SigningQuery 签名日志查询条件 (零值不过滤)
+type SigningQuery struct {
+	UserID  string
+	JobID   string
+	ChainID uint64
+	KeyID   string
+	From    time.Time // signed_at 下限 (含)
+	To      time.Time // signed_at 上限 (不含)
+	AfterID int64     // 分页: 返回 ID 大于该值的记录
+	Limit   int
+}
+
+// SigningRecords 按 ID 顺序返回签名记录
+func (s *Store) SigningRecords(ctx context.Context, q SigningQuery) ([]SigningRecord, error) {
+	var from, to sql.NullTime
+	if !q.From.IsZero() {
+		from = sql.NullTime{Time: q.From, Valid: true}
+	}
+	if !q.To.IsZero() {
+		to = sql.NullTime{Time: q.To, Valid: true}
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, user_id, batch_id, job_id, chain_id, purpose, digest, provider, key_id,
+       requestor, result, error, correlation_id, signed_at, prev_hash, hash
+FROM payout_signing_log
+WHERE id > $1
+  AND ($2 = '' OR user_id = $2)
+  AND ($3 = '' OR job_id = $3)
+  AND ($4 = 0 OR chain_id = $4)
+  AND ($5 = '' OR lower(key_id) = lower($5))
+  AND ($6::timestamptz IS NULL OR signed_at >= $6)
+  AND ($7::timestamptz IS NULL OR signed_at < $7)
+ORDER BY id
+LIMIT $8`, q.AfterID, q.UserID, q.JobID, int64(q.ChainID), q.KeyID, from, to, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []SigningRecord{}
+	for rows.Next() {
+		var r SigningRecord
+		var chainID int64
+		if err := rows.Scan(&r.ID, &r.UserID, &r.BatchID, &r.JobID, &chainID, &r.Purpose, &r.Digest, &r.Provider, &r.KeyID,
+			&r.Requestor, &r.Result, &r.Error, &r.CorrelationID, &r.SignedAt, &r.PrevHash, &r.Hash); err != nil {
+			return nil, err
+		}
+		r.ChainID = uint64(chainID)
+		r.SignedAt = r.SignedAt.UTC()
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// SigningChainCheck 哈希链校验结果
+type SigningChainCheck struct {
+	Records  int64  `json:"records"`
+	Valid    bool   `json:"valid"`
+	BrokenAt int64  `json:"broken_at,omitempty"` // 第一条哈希不匹配或与上一条断开的记录
+	LastHash string `json:"last_hash,omitempty"`
+}
+
+// VerifySigningChain 从头校验整条哈希链
+func (s *Store) VerifySigningChain(ctx context.Context) (*SigningChainCheck, error) {
+	const page = 1000
+	check := &SigningChainCheck{Valid: true}
+	var after int64
+	for {
+		records, err := s.SigningRecords(ctx, SigningQuery{AfterID: after, Limit: page})
+		if err != nil {
+			return nil, err
+		}
+		for i := range records {
+			r := &records[i]
+			check.Records++
+			if r.PrevHash != check.LastHash || r.ComputeHash() != r.Hash {
+				check.Valid = false
+				check.BrokenAt = r.ID
+				return check, nil
+			}
+			check.LastHash = r.Hash
+			after = r.ID
+		}
+		if len(records) < page {
+			return check, nil
+		}
+	}
+}
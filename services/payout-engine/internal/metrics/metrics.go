@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 出款流水线指标 (GET /metrics 以 Prometheus 文本格式导出，用于对卡住的流水线告警)
+var (
+	JobsProcessed = NewCounter("payout_jobs_processed_total",
+		"Payout jobs processed by a worker, by outcome (success, failed or error).", "chain_id", "outcome")
+	BroadcastFailures = NewCounter("payout_broadcast_failures_total",
+		"Transactions rejected by the node on broadcast, by error class.", "chain_id", "reason")
+	SigningLatency = NewHistogram("payout_signing_duration_seconds",
+		"Time to sign a transaction, per chain and KMS provider.", DefaultLatencyBuckets, "chain_id", "provider", "result")
+	GasUsed = NewCounter("payout_gas_used_total",
+		"Gas used by mined transactions, per chain.", "chain_id")
+	GasSpent = NewCounter("payout_gas_spent_wei_total",
+		"Network fees paid by mined transactions (gasUsed * effectiveGasPrice) in wei, per chain.", "chain_id")
+	NonceResets = NewCounter("payout_nonce_resets_total",
+		"Cached nonces dropped so the next transaction uses the chain nonce, per chain.", "chain_id")
+)
+
+// DefaultLatencyBuckets 签名耗时分桶 (秒): 本地私钥为毫秒级，Fireblocks / MPC 需要数秒
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	registryMu sync.Mutex
+	registry   []metric
+)
+
+type metric interface {
+	write(w io.Writer)
+}
+
+func register(m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, m)
+}
+
+// Write 按注册顺序以 Prometheus 文本格式 (0.0.4) 写出所有指标
+func Write(w io.Writer) {
+	registryMu.Lock()
+	metrics := append([]metric(nil), registry...)
+	registryMu.Unlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Label 将数字 (如链 ID) 格式化为标签值
+func Label(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+// 文本格式的转义: 标签值转义 \、" 和换行，HELP 只转义 \ 和换行
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// EscapeLabel 按 Prometheus 文本格式转义标签值 (其余字符，包括非 ASCII，原样输出)
+func EscapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+// series 一组标签值对应的时间序列
+type series struct {
+	labels []string
+	key    string
+}
+
+func newSeries(names []string, values []string) series {
+	if len(values) != len(names) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(names), len(values)))
+	}
+	return series{labels: values, key: strings.Join(values, "\xff")}
+}
+
+// format 如 {chain_id="1",outcome="success"}，extra 为附加标签 (如直方图的 le)
+func (s series) format(names []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+len(extra)/2)
+	for i, name := range names {
+		parts = append(parts, name+`="`+EscapeLabel(s.labels[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+EscapeLabel(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+// Counter 按标签区分的单调递增计数器
+type Counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]series
+	values map[string]float64
+}
+
+// NewCounter 创建并注册计数器
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, series: make(map[string]series), values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// Inc 计数加 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 v (v 不能为负)
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	s := newSeries(c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series[s.key] = s
+	c.values[s.key] += v
+}
+
+// Value 当前计数 (用于测试)
+func (c *Counter) Value(labelValues ...string) float64 {
+	s := newSeries(c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[s.key]
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, helpEscaper.Replace(c.help), c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.series[key].format(c.labels), formatFloat(c.values[key]))
+	}
+}
+
+// Histogram 按标签区分的累积分桶直方图
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]series
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64 // 每个桶 (不含 +Inf) 的累积计数
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram 创建并注册直方图 (buckets 为升序的桶上界)
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]series), values: make(map[string]*histogramValue)}
+	register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	s := newSeries(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[s.key]
+	if !ok {
+		hv = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.series[s.key] = s
+		h.values[s.key] = hv
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// ObserveDuration 记录从 start 起经过的秒数
+func (h *Histogram) ObserveDuration(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count 观测次数 (用于测试)
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	s := newSeries(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hv, ok := h.values[s.key]; ok {
+		return hv.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, helpEscaper.Replace(h.help), h.name)
+	for _, key := range sortedKeys(h.values) {
+		s, hv := h.series[key], h.values[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, s.format(h.labels, "le", formatFloat(upper)), hv.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, s.format(h.labels, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, s.format(h.labels), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, s.format(h.labels), hv.count)
+	}
+}
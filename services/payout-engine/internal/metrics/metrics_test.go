@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	c := &Counter{name: "test_total", help: "Test counter.", labels: []string{"chain_id", "outcome"},
+		series: make(map[string]series), values: make(map[string]float64)}
+	c.Inc("1", "success")
+	c.Inc("1", "success")
+	c.Add(3, "56", "failed")
+	c.Add(-1, "56", "failed") // 计数器不能减少
+
+	assert.Equal(t, 2.0, c.Value("1", "success"))
+	assert.Equal(t, 3.0, c.Value("56", "failed"))
+	assert.Zero(t, c.Value("1", "failed"))
+	assert.Panics(t, func() { c.Inc("1") })
+
+	var buf bytes.Buffer
+	c.write(&buf)
+	assert.Equal(t, `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{chain_id="1",outcome="success"} 2
+test_total{chain_id="56",outcome="failed"} 3
+`, buf.String())
+}
+
+func TestEscapeLabel(t *testing.T) {
+	c := &Counter{name: "test_total", help: "Test counter with a \\ and\na newline.", labels: []string{"chain"},
+		series: make(map[string]series), values: make(map[string]float64)}
+	c.Inc("BNB \"Smart\" Chain\\测试\n\t")
+
+	var buf bytes.Buffer
+	c.write(&buf)
+	// 只转义 \、" 和换行，非 ASCII 和其他控制字符原样输出
+	assert.Equal(t, "# HELP test_total Test counter with a \\\\ and\\na newline.\n# TYPE test_total counter\n"+
+		"test_total{chain=\"BNB \\\"Smart\\\" Chain\\\\测试\\n\t\"} 1\n", buf.String())
+}
+
+func TestHistogram(t *testing.T) {
+	h := &Histogram{name: "test_seconds", help: "Test histogram.", labels: []string{"provider"}, buckets: []float64{0.1, 1},
+		series: make(map[string]series), values: make(map[string]*histogramValue)}
+	h.Observe(0.05, "local")
+	h.Observe(0.5, "local")
+	h.Observe(5, "local")
+
+	assert.EqualValues(t, 3, h.Count("local"))
+	assert.Zero(t, h.Count("fireblocks"))
+
+	var buf bytes.Buffer
+	h.write(&buf)
+	assert.Equal(t, `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{provider="local",le="0.1"} 1
+test_seconds_bucket{provider="local",le="1"} 2
+test_seconds_bucket{provider="local",le="+Inf"} 3
+test_seconds_sum{provider="local"} 5.55
+test_seconds_count{provider="local"} 3
+`, buf.String())
+}
+
+func TestWrite(t *testing.T) {
+	NonceResets.Inc(Label(1))
+
+	var buf bytes.Buffer
+	Write(&buf)
+	out := buf.String()
+	for _, name := range []string{
+		"payout_jobs_processed_total", "payout_broadcast_failures_total", "payout_signing_duration_seconds",
+		"payout_gas_used_total", "payout_gas_spent_wei_total", "payout_nonce_resets_total",
+	} {
+		assert.Contains(t, out, "# TYPE "+name+" ")
+	}
+	assert.True(t, strings.Contains(out, `payout_nonce_resets_total{chain_id="1"} `))
+}
@@ -4,21 +4,40 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"math/big"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-redis/redis/v8"
 	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
 	"github.com/rs/zerolog/log"
 )
 
+// ChainClient 查询链上 pending nonce 和已上链 nonce (*ethclient.Client、*rpcpool.Pool 满足)
+type ChainClient interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// InFlightError 地址已分配 nonce 但尚未上链的交易达到上限，暂不分配新的 nonce
+type InFlightError struct {
+	ChainID  uint64
+	Address  common.Address
+	InFlight uint64
+	Limit    int
+}
+
+func (e *InFlightError) Error() string {
+	return fmt.Sprintf("%s has %d in-flight transactions on chain %d (limit %d)", e.Address.Hex(), e.InFlight, e.ChainID, e.Limit)
+}
+
 // Manager 管理多链多地址的 Nonce
 type Manager struct {
 	redis       *redis.Client
-	clients     map[uint64]*ethclient.Client
+	clients     map[uint64]ChainClient
 	localNonces map[string]uint64 // key: chainID:address
 	mu          sync.RWMutex
 	lockTTL     time.Duration
@@ -54,21 +73,34 @@ func NewManager(ctx context.Context, cfg config.RedisConfig) (*Manager, error) {
 
 	return &Manager{
 		redis:       rdb,
-		clients:     make(map[uint64]*ethclient.Client),
+		clients:     make(map[uint64]ChainClient),
 		localNonces: make(map[string]uint64),
 		lockTTL:     30 * time.Second,
 	}, nil
 }
 
 // AddChainClient 添加链客户端
-func (m *Manager) AddChainClient(chainID uint64, client *ethclient.Client) {
+func (m *Manager) AddChainClient(chainID uint64, client ChainClient) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.clients[chainID] = client
 }
 
+// RemoveChainClient 移除链客户端 (链配置被移除时)
+func (m *Manager) RemoveChainClient(chainID uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, chainID)
+}
+
 // GetNonce 获取下一个可用的 Nonce（带分布式锁）
 func (m *Manager) GetNonce(ctx context.Context, chainID uint64, address common.Address) (uint64, func(), error) {
+	return m.GetNonceLimited(ctx, chainID, address, 0)
+}
+
+// GetNonceLimited 同 GetNonce，但地址在途交易 (下一个 nonce 与已上链 nonce 之差) 达到 maxInFlight 时
+// 返回 *InFlightError，不分配 nonce。maxInFlight <= 0 时不限制。
+func (m *Manager) GetNonceLimited(ctx context.Context, chainID uint64, address common.Address, maxInFlight int) (uint64, func(), error) {
 	key := fmt.Sprintf("nonce:%d:%s", chainID, address.Hex())
 	lockKey := fmt.Sprintf("lock:%s", key)
 
@@ -92,12 +124,25 @@ func (m *Manager) GetNonce(ctx context.Context, chainID uint64, address common.A
 		return 0, nil, err
 	}
 
+	// 在途交易上限 (持有锁时检查，并发任务不会同时越过上限)
+	if maxInFlight > 0 {
+		if err := m.checkInFlight(ctx, chainID, address, nonce, maxInFlight); err != nil {
+			releaseFn()
+			return 0, nil, err
+		}
+	}
+
 	// 预增加 Nonce
 	m.incrementNonce(ctx, key)
 
 	return nonce, releaseFn, nil
 }
 
+// Advance 持有 GetNonce 的锁时再占用下一个 Nonce (同一任务连续发送多笔交易)
+func (m *Manager) Advance(ctx context.Context, chainID uint64, address common.Address) {
+	m.incrementNonce(ctx, fmt.Sprintf("nonce:%d:%s", chainID, address.Hex()))
+}
+
 // getNonceValue 获取 Nonce 值
 func (m *Manager) getNonceValue(ctx context.Context, chainID uint64, address common.Address, key string) (uint64, error) {
 	// 先检查 Redis 缓存
@@ -126,6 +171,25 @@ func (m *Manager) getNonceValue(ctx context.Context, chainID uint64, address com
 	return onchainNonce, nil
 }
 
+// checkInFlight 下一个 nonce 与已上链 nonce 之差达到上限时返回 *InFlightError
+func (m *Manager) checkInFlight(ctx context.Context, chainID uint64, address common.Address, next uint64, maxInFlight int) error {
+	m.mu.RLock()
+	client, ok := m.clients[chainID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no client for chain %d", chainID)
+	}
+
+	mined, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get mined nonce: %w", err)
+	}
+	if next > mined && next-mined >= uint64(maxInFlight) {
+		return &InFlightError{ChainID: chainID, Address: address, InFlight: next - mined, Limit: maxInFlight}
+	}
+	return nil
+}
+
 // incrementNonce 增加 Nonce
 func (m *Manager) incrementNonce(ctx context.Context, key string) {
 	m.redis.Incr(ctx, key)
@@ -134,6 +198,7 @@ func (m *Manager) incrementNonce(ctx context.Context, key string) {
 // ResetNonce 重置 Nonce（交易失败时使用）
 func (m *Manager) ResetNonce(ctx context.Context, chainID uint64, address common.Address) error {
 	key := fmt.Sprintf("nonce:%d:%s", chainID, address.Hex())
+	metrics.NonceResets.Inc(metrics.Label(chainID))
 	return m.redis.Del(ctx, key).Err()
 }
 
@@ -3,13 +3,13 @@ package nonce
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,7 +28,7 @@ func newTestManager(t *testing.T) (*Manager, func()) {
 
 	m := &Manager{
 		redis:       client,
-		clients:     make(map[uint64]*ethclient.Client),
+		clients:     make(map[uint64]ChainClient),
 		localNonces: make(map[string]uint64),
 		lockTTL:     30 * time.Second,
 	}
@@ -189,3 +189,50 @@ func TestNonceManager_ConcurrentIncrement(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, uint64(numGoroutines), val)
 }
+
+// fakeChain reports fixed pending and mined nonces.
+type fakeChain struct{ pending, mined uint64 }
+
+func (f *fakeChain) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return f.pending, nil
+}
+
+func (f *fakeChain) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return f.mined, nil
+}
+
+func TestNonceManager_GetNonceLimited(t *testing.T) {
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	chain := &fakeChain{pending: 10, mined: 10}
+	nm.AddChainClient(1, chain)
+
+	// 3 笔在途后达到上限，不再分配 nonce
+	for want := uint64(10); want < 13; want++ {
+		n, release, err := nm.GetNonceLimited(ctx, 1, addr, 3)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+		release()
+	}
+	_, _, err := nm.GetNonceLimited(ctx, 1, addr, 3)
+	var inFlight *InFlightError
+	require.ErrorAs(t, err, &inFlight)
+	assert.Equal(t, uint64(3), inFlight.InFlight)
+	assert.Equal(t, 3, inFlight.Limit)
+
+	// 锁已释放，未限制时仍可分配
+	n, release, err := nm.GetNonce(ctx, 1, addr)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), n)
+	release()
+
+	// 在途交易上链后释放名额
+	chain.mined = 12
+	n, release, err = nm.GetNonceLimited(ctx, 1, addr, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(14), n)
+	release()
+}
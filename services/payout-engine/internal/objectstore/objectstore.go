@@ -0,0 +1,195 @@
+// Package objectstore 以 S3 接口上传对象 (AWS S3，或 GCS 的 S3 兼容接口配合 HMAC 密钥)，
+// 请求按 AWS Signature Version 4 签名。
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 支持的存储
+const (
+	ProviderS3  = "s3"
+	ProviderGCS = "gcs"
+)
+
+// Config 对象存储配置 (Provider 为空时不上传)
+type Config struct {
+	Provider  string // s3 / gcs
+	Bucket    string
+	Region    string // S3 区域 (GCS 为 auto)
+	Endpoint  string // 自定义 S3 兼容地址 (如 MinIO)，为空时使用 Provider 的公共地址
+	AccessKey string // S3 access key / GCS HMAC access id
+	SecretKey string
+	Prefix    string // 对象键前缀
+}
+
+// Enabled 是否配置了对象存储
+func (c Config) Enabled() bool {
+	return c.Provider != ""
+}
+
+// Store 对象存储客户端
+type Store struct {
+	cfg      Config
+	endpoint *url.URL
+	virtual  bool // 虚拟主机风格 (bucket 在域名中)，否则 bucket 在路径中
+	client   *http.Client
+	now      func() time.Time
+}
+
+// New 创建客户端
+func New(cfg Config) (*Store, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("object store requires bucket, access key and secret key")
+	}
+
+	s := &Store{cfg: cfg, client: &http.Client{Timeout: 60 * time.Second}, now: time.Now}
+	endpoint := cfg.Endpoint
+	switch cfg.Provider {
+	case ProviderS3:
+		if s.cfg.Region == "" {
+			return nil, fmt.Errorf("s3 object store requires a region")
+		}
+		if endpoint == "" {
+			endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+			s.virtual = true
+		}
+	case ProviderGCS:
+		if s.cfg.Region == "" {
+			s.cfg.Region = "auto"
+		}
+		if endpoint == "" {
+			endpoint = "https://storage.googleapis.com"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported object store provider: %s (expected s3 or gcs)", cfg.Provider)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid object store endpoint: %s", endpoint)
+	}
+	s.endpoint = u
+	return s, nil
+}
+
+// Put 上传对象，返回对象位置 (s3://bucket/key 或 gs://bucket/key)
+func (s *Store) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	key = s.cfg.Prefix + key
+	u := *s.endpoint
+	if s.virtual {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+	}
+	u.RawPath = uriEncodePath(u.Path) // 发送的路径与签名的路径一致
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, body, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("object upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("object upload returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	scheme := "s3"
+	if s.cfg.Provider == ProviderGCS {
+		scheme = "gs"
+	}
+	return scheme + "://" + s.cfg.Bucket + "/" + key, nil
+}
+
+// sign 按 SigV4 签名请求 (签名 Host 和请求中已设置的全部头)
+func (s *Store) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncodePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// uriEncodePath 按 SigV4 规则编码路径: 除 A-Z a-z 0-9 - _ . ~ 和 / 外均编码为 %XX
+func uriEncodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Code is the error code recorded on jobs blocked by a spending policy.
+const Code = "POLICY_VIOLATION"
+
+// Rules reported in Violation.Rule
+const (
+	RuleToken           = "token"
+	RuleMaxPayout       = "max_payout"
+	RuleDaily           = "daily"
+	RuleWeekly          = "weekly"
+	RuleRecipientDaily  = "recipient_daily"
+	RuleRecipientWeekly = "recipient_weekly"
+)
+
+// NativeToken 在 tokens / limits 中表示链的原生代币
+const NativeToken = "native"
+
+// Policy 一个付款地址 (或全部地址) 在一条链 (或全部链) 上的支出限制
+type Policy struct {
+	ChainID     uint64   `json:"chain_id"`     // 0 = 所有链
+	FromAddress string   `json:"from_address"` // "" 或 "*" = 所有付款地址
+	Tokens      []string `json:"tokens"`       // 允许支付的代币 (为空时不限制)
+	Limits      []Limit  `json:"limits"`
+}
+
+// Limit 一种代币的金额上限 (最小单位，为空时不限制)。日/周额度按 UTC 自然日滚动计算 (周 = 最近 7 天)。
+type Limit struct {
+	Token           string `json:"token"`
+	MaxPayout       string `json:"max_payout"`
+	Daily           string `json:"daily"`
+	Weekly          string `json:"weekly"`
+	RecipientDaily  string `json:"recipient_daily"`
+	RecipientWeekly string `json:"recipient_weekly"`
+
+	maxPayout, daily, weekly, recipientDaily, recipientWeekly *big.Int
+}
+
+// Set 已加载的支出策略。一笔支付需满足所有匹配的策略。
+type Set struct {
+	policies []Policy
+}
+
+// file is the on-disk JSON format:
+//
+//	{"policies": [
+//	  {"chain_id": 8453, "from_address": "0xabc...", "tokens": ["0x8335..."],
+//	   "limits": [{"token": "0x8335...", "max_payout": "5000000000", "daily": "50000000000", "recipient_daily": "10000000000"}]},
+//	  {"limits": [{"token": "native", "max_payout": "1000000000000000000"}]}
+//	]}
+type file struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Load reads a policy file. An empty path returns a disabled set.
+func Load(path string) (*Set, error) {
+	if path == "" {
+		return &Set{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spending policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse builds a policy set from JSON.
+func Parse(data []byte) (*Set, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid spending policy: %w", err)
+	}
+	for i := range f.Policies {
+		p := &f.Policies[i]
+		if p.FromAddress == "*" {
+			p.FromAddress = ""
+		}
+		p.FromAddress = normalize(p.FromAddress)
+		for j, token := range p.Tokens {
+			p.Tokens[j] = normalizeToken(token)
+		}
+		for j := range p.Limits {
+			l := &p.Limits[j]
+			l.Token = normalizeToken(l.Token)
+			for _, field := range []struct {
+				name  string
+				value string
+				dst   **big.Int
+			}{
+				{RuleMaxPayout, l.MaxPayout, &l.maxPayout},
+				{RuleDaily, l.Daily, &l.daily},
+				{RuleWeekly, l.Weekly, &l.weekly},
+				{RuleRecipientDaily, l.RecipientDaily, &l.recipientDaily},
+				{RuleRecipientWeekly, l.RecipientWeekly, &l.recipientWeekly},
+			} {
+				if field.value == "" {
+					continue
+				}
+				v, ok := new(big.Int).SetString(field.value, 10)
+				if !ok || v.Sign() < 0 {
+					return nil, fmt.Errorf("policies[%d].limits[%d]: invalid %s: %s", i, j, field.name, field.value)
+				}
+				*field.dst = v
+			}
+		}
+	}
+	return &Set{policies: f.Policies}, nil
+}
+
+// Enabled reports whether any policy is configured.
+func (s *Set) Enabled() bool {
+	return s != nil && len(s.policies) > 0
+}
+
+// Payout 待检查的支付
+type Payout struct {
+	ChainID     uint64
+	FromAddress string
+	ToAddress   string
+	Token       string // 原生代币为 ""
+	Amount      *big.Int
+}
+
+// Usage 付款地址该代币已预留的支出 (不含本笔)
+type Usage struct {
+	Daily           *big.Int
+	Weekly          *big.Int
+	RecipientDaily  *big.Int // 付给同一收款地址的部分
+	RecipientWeekly *big.Int
+}
+
+// Violation 支付违反支出策略
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+func (v *Violation) Error() string { return "spending policy violation: " + v.Message }
+
+// ErrorCode 写入任务状态和死信的错误码
+func (v *Violation) ErrorCode() string { return Code }
+
+// HasVolumeLimits 是否有日/周额度需要按已预留支出检查
+func (s *Set) HasVolumeLimits(p Payout) bool {
+	for _, l := range s.limits(p) {
+		if l.daily != nil || l.weekly != nil || l.recipientDaily != nil || l.recipientWeekly != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 检查支付是否满足所有匹配的策略。usage 为 nil 时只检查代币和单笔上限。
+func (s *Set) Check(p Payout, usage *Usage) *Violation {
+	token := normalizeToken(p.Token)
+	for _, policy := range s.match(p) {
+		if len(policy.Tokens) > 0 && !contains(policy.Tokens, token) {
+			return &Violation{Rule: RuleToken, Message: fmt.Sprintf("token %s is not allowed for %s on chain %d", displayToken(token), p.FromAddress, p.ChainID)}
+		}
+	}
+	for _, l := range s.limits(p) {
+		if l.maxPayout != nil && p.Amount.Cmp(l.maxPayout) > 0 {
+			return &Violation{Rule: RuleMaxPayout, Message: fmt.Sprintf("amount %s exceeds the single payout limit %s", p.Amount, l.maxPayout)}
+		}
+		if usage == nil {
+			continue
+		}
+		for _, c := range []struct {
+			rule  string
+			used  *big.Int
+			limit *big.Int
+		}{
+			{RuleDaily, usage.Daily, l.daily},
+			{RuleWeekly, usage.Weekly, l.weekly},
+			{RuleRecipientDaily, usage.RecipientDaily, l.recipientDaily},
+			{RuleRecipientWeekly, usage.RecipientWeekly, l.recipientWeekly},
+		} {
+			if c.limit == nil {
+				continue
+			}
+			total := new(big.Int).Add(p.Amount, c.used)
+			if total.Cmp(c.limit) > 0 {
+				return &Violation{Rule: c.rule, Message: fmt.Sprintf("%s limit %s exceeded (%s already used, payout %s)", c.rule, c.limit, c.used, p.Amount)}
+			}
+		}
+	}
+	return nil
+}
+
+// match 适用于该支付的策略
+func (s *Set) match(p Payout) []*Policy {
+	if s == nil {
+		return nil
+	}
+	from := normalize(p.FromAddress)
+	var out []*Policy
+	for i := range s.policies {
+		policy := &s.policies[i]
+		if policy.ChainID != 0 && policy.ChainID != p.ChainID {
+			continue
+		}
+		if policy.FromAddress != "" && policy.FromAddress != from {
+			continue
+		}
+		out = append(out, policy)
+	}
+	return out
+}
+
+// limits 适用于该支付代币的额度
+func (s *Set) limits(p Payout) []*Limit {
+	token := normalizeToken(p.Token)
+	var out []*Limit
+	for _, policy := range s.match(p) {
+		for i := range policy.Limits {
+			if policy.Limits[i].Token == token {
+				out = append(out, &policy.Limits[i])
+			}
+		}
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize EVM 地址小写化，TRON Base58 地址区分大小写保持原样
+func normalize(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// normalizeToken 原生代币 ("", "native", 零地址) 统一为 ""
+func normalizeToken(token string) string {
+	token = normalize(token)
+	if token == NativeToken || token == "0x0000000000000000000000000000000000000000" {
+		return ""
+	}
+	return token
+}
+
+func displayToken(token string) string {
+	if token == "" {
+		return NativeToken
+	}
+	return token
+}
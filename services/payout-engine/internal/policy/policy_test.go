@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usdc = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+
+const testPolicies = `{
+  "policies": [
+    {"chain_id": 8453, "from_address": "0xAbC0000000000000000000000000000000000001", "tokens": ["0x833589fcd6edb6e08f4c7c32d4f71b54bda02913"],
+     "limits": [{"token": "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "max_payout": "5000", "daily": "10000", "weekly": "30000", "recipient_daily": "6000"}]},
+    {"from_address": "*", "limits": [{"token": "native", "max_payout": "100"}]}
+  ]
+}`
+
+func TestPolicyCheck(t *testing.T) {
+	s, err := Parse([]byte(testPolicies))
+	require.NoError(t, err)
+	assert.True(t, s.Enabled())
+
+	payout := func(token string, amount int64) Payout {
+		return Payout{ChainID: 8453, FromAddress: "0xabc0000000000000000000000000000000000001", ToAddress: "0x2", Token: token, Amount: big.NewInt(amount)}
+	}
+	usage := func(daily, weekly, recipientDaily int64) *Usage {
+		return &Usage{Daily: big.NewInt(daily), Weekly: big.NewInt(weekly), RecipientDaily: big.NewInt(recipientDaily), RecipientWeekly: big.NewInt(0)}
+	}
+
+	tests := []struct {
+		name   string
+		payout Payout
+		usage  *Usage
+		rule   string
+	}{
+		{"within limits", payout(usdc, 5000), usage(5000, 25000, 1000), ""},
+		{"token not allowed", payout("0xdAC17F958D2ee523a2206206994597C13D831ec7", 1), nil, RuleToken},
+		{"native not in token list", payout("", 1), nil, RuleToken},
+		{"single payout cap", payout(usdc, 5001), nil, RuleMaxPayout},
+		{"daily cap", payout(usdc, 2000), usage(8001, 8001, 0), RuleDaily},
+		{"weekly cap", payout(usdc, 2000), usage(0, 28001, 0), RuleWeekly},
+		{"recipient cap", payout(usdc, 2000), usage(4000, 4000, 4001), RuleRecipientDaily},
+		{"other chains only see the wildcard policy", Payout{ChainID: 1, FromAddress: "0x3", Token: "", Amount: big.NewInt(101)}, nil, RuleMaxPayout},
+		{"wildcard policy has no token list", Payout{ChainID: 1, FromAddress: "0x3", Token: usdc, Amount: big.NewInt(1_000_000)}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := s.Check(tt.payout, tt.usage)
+			if tt.rule == "" {
+				assert.Nil(t, v)
+				return
+			}
+			require.NotNil(t, v)
+			assert.Equal(t, tt.rule, v.Rule)
+			assert.Equal(t, Code, v.ErrorCode())
+		})
+	}
+
+	assert.True(t, s.HasVolumeLimits(payout(usdc, 1)))
+	assert.False(t, s.HasVolumeLimits(Payout{ChainID: 1, Token: "", Amount: big.NewInt(1)}))
+}
+
+func TestPolicyParseErrors(t *testing.T) {
+	_, err := Parse([]byte(`{"policies": [{"limits": [{"token": "native", "daily": "1e18"}]}]}`))
+	assert.Error(t, err)
+
+	s, err := Load("")
+	require.NoError(t, err)
+	assert.False(t, s.Enabled())
+	assert.Nil(t, s.Check(Payout{ChainID: 1, Amount: big.NewInt(1)}, nil))
+}
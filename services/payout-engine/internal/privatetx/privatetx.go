@@ -0,0 +1,67 @@
+// Package privatetx submits signed transactions to private mempool RPCs
+// (Flashbots Protect, MEV Blocker) so they are not visible to searchers
+// before inclusion.
+package privatetx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Submission methods
+const (
+	MethodSendRaw     = "eth_sendRawTransaction"     // Flashbots Protect RPC, MEV Blocker
+	MethodSendPrivate = "eth_sendPrivateTransaction" // Flashbots-style private transaction API
+)
+
+// Sender submits transactions to one private RPC endpoint.
+type Sender struct {
+	client *rpc.Client
+	method string
+}
+
+// Dial creates a Sender. HTTP endpoints connect on first use.
+func Dial(ctx context.Context, url, method string) (*Sender, error) {
+	if method == "" {
+		method = MethodSendRaw
+	}
+	if method != MethodSendRaw && method != MethodSendPrivate {
+		return nil, fmt.Errorf("unsupported private tx method: %s", method)
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{client: client, method: method}, nil
+}
+
+// Send submits a signed transaction.
+func (s *Sender) Send(ctx context.Context, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var hash string
+	if s.method == MethodSendPrivate {
+		params := map[string]interface{}{
+			"tx":          hexutil.Encode(raw),
+			"preferences": map[string]bool{"fast": true},
+		}
+		err = s.client.CallContext(ctx, &hash, s.method, params)
+	} else {
+		err = s.client.CallContext(ctx, &hash, s.method, hexutil.Encode(raw))
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", s.method, err)
+	}
+	return nil
+}
+
+// Close closes the RPC connection.
+func (s *Sender) Close() {
+	s.client.Close()
+}
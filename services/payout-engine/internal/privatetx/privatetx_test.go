@@ -0,0 +1,83 @@
+package privatetx
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func newServer(t *testing.T, requests *[]rpcRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*requests = append(*requests, req)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x01"})
+	}))
+}
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	return types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 65000, To: &to, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Value: big.NewInt(0)})
+}
+
+func TestSend(t *testing.T) {
+	tx := testTx()
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("raw transaction", func(t *testing.T) {
+		var requests []rpcRequest
+		srv := newServer(t, &requests)
+		defer srv.Close()
+
+		sender, err := Dial(context.Background(), srv.URL, "")
+		require.NoError(t, err)
+		defer sender.Close()
+		require.NoError(t, sender.Send(context.Background(), tx))
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, MethodSendRaw, requests[0].Method)
+		var param string
+		require.NoError(t, json.Unmarshal(requests[0].Params[0], &param))
+		assert.Equal(t, hexutil.Encode(raw), param)
+	})
+
+	t.Run("private transaction", func(t *testing.T) {
+		var requests []rpcRequest
+		srv := newServer(t, &requests)
+		defer srv.Close()
+
+		sender, err := Dial(context.Background(), srv.URL, MethodSendPrivate)
+		require.NoError(t, err)
+		defer sender.Close()
+		require.NoError(t, sender.Send(context.Background(), tx))
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, MethodSendPrivate, requests[0].Method)
+		var param struct {
+			Tx          string          `json:"tx"`
+			Preferences map[string]bool `json:"preferences"`
+		}
+		require.NoError(t, json.Unmarshal(requests[0].Params[0], &param))
+		assert.Equal(t, hexutil.Encode(raw), param.Tx)
+		assert.True(t, param.Preferences["fast"])
+	})
+
+	_, err = Dial(context.Background(), "http://localhost", "eth_sendBundle")
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Approval keys
+const (
+	// PayoutApprovalsKey 待审批批次索引 (zset: BatchRef JSON, score 为提交时间)
+	PayoutApprovalsKey = "payout:approvals"
+	// PayoutApprovalKeyPrefix 待审批批次记录 (payout:approval:<user_id>:<batch_id>)
+	PayoutApprovalKeyPrefix = "payout:approval:"
+)
+
+// approveAttempts 并发审批冲突时的重试次数
+const approveAttempts = 5
+
+var (
+	// ErrApprovalNotPending 批次不在待审批状态 (已放行、已取消或不存在)
+	ErrApprovalNotPending = errors.New("batch is not pending approval")
+	// ErrAlreadyApproved 同一审批人重复审批
+	ErrAlreadyApproved = errors.New("operator has already approved this batch")
+)
+
+// ApprovalState 待审批批次状态
+type ApprovalState string
+
+const (
+	ApprovalStatePending   ApprovalState = "pending_approval" // 等待审批人签名
+	ApprovalStateCancelled ApprovalState = "cancelled"        // 放行前已取消
+	ApprovalStateFailed    ApprovalState = "failed"           // 放行后未能入队 (如余额不足)
+)
+
+// Approval 一位审批人的签名
+type Approval struct {
+	Operator   string    `json:"operator"`
+	Signature  string    `json:"signature"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// PendingApproval 超过审批阈值、尚未入队的批次。放行后记录即删除，之后按任务状态查询。
+type PendingApproval struct {
+	UserID        string          `json:"user_id"`
+	BatchID       string          `json:"batch_id"`
+	Request       json.RawMessage `json:"request"`      // 提交请求，放行后按该请求入队
+	RequestHash   string          `json:"request_hash"` // 审批人签名绑定的请求哈希
+	Items         int             `json:"items"`
+	ValueUSD      string          `json:"value_usd"`          // 批次合计美元价值 (含未定价代币时为已定价部分)
+	Unpriced      []string        `json:"unpriced,omitempty"` // 未定价的代币
+	Approvals     []Approval      `json:"approvals,omitempty"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	State         ApprovalState   `json:"state"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// ApprovedBy 已签名的审批人
+func (a *PendingApproval) ApprovedBy() []string {
+	out := make([]string, 0, len(a.Approvals))
+	for _, approval := range a.Approvals {
+		out = append(out, approval.Operator)
+	}
+	return out
+}
+
+func approvalKey(userID, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", PayoutApprovalKeyPrefix, userID, batchID)
+}
+
+// HoldForApproval 保存待审批批次并加入索引 (已存在时整体替换)。等待审批期间记录不过期。
+func (c *Consumer) HoldForApproval(ctx context.Context, batch *PendingApproval) error {
+	batch.State = ApprovalStatePending
+	batch.UpdatedAt = time.Now()
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	ref := BatchRef{UserID: batch.UserID, BatchID: batch.BatchID}
+
+	pipe := c.redis.TxPipeline()
+	pipe.Set(ctx, approvalKey(batch.UserID, batch.BatchID), data, 0)
+	pipe.ZAdd(ctx, PayoutApprovalsKey, &redis.Z{Score: float64(batch.CreatedAt.Unix()), Member: ref.member()})
+	// 运维按批次 ID 查询/审批/取消
+	pipe.SAdd(ctx, PayoutBatchRefKeyPrefix+batch.BatchID, batch.UserID)
+	pipe.Expire(ctx, PayoutBatchRefKeyPrefix+batch.BatchID, BatchStatusTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetPendingApproval 返回待审批批次记录 (不存在时为 nil)
+func (c *Consumer) GetPendingApproval(ctx context.Context, userID, batchID string) (*PendingApproval, error) {
+	data, err := c.redis.Get(ctx, approvalKey(userID, batchID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var batch PendingApproval
+	if err := json.Unmarshal([]byte(data), &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// AddApproval 记录审批人签名并返回更新后的记录。批次不在待审批状态时返回 ErrApprovalNotPending，
+// 同一审批人 (地址不区分大小写) 重复签名时返回 ErrAlreadyApproved。
+func (c *Consumer) AddApproval(ctx context.Context, userID, batchID string, approval Approval) (*PendingApproval, error) {
+	key := approvalKey(userID, batchID)
+	var updated *PendingApproval
+	add := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return ErrApprovalNotPending
+		}
+		if err != nil {
+			return err
+		}
+		var batch PendingApproval
+		if err := json.Unmarshal([]byte(data), &batch); err != nil {
+			return err
+		}
+		if batch.State != ApprovalStatePending {
+			return ErrApprovalNotPending
+		}
+		for _, existing := range batch.Approvals {
+			if strings.EqualFold(existing.Operator, approval.Operator) {
+				return ErrAlreadyApproved
+			}
+		}
+		batch.Approvals = append(batch.Approvals, approval)
+		batch.UpdatedAt = time.Now()
+		out, err := json.Marshal(&batch)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, out, 0)
+			return nil
+		})
+		if err == nil {
+			updated = &batch
+		}
+		return err
+	}
+
+	for i := 0; i < approveAttempts; i++ {
+		err := c.redis.Watch(ctx, add, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return updated, err
+		}
+	}
+	return nil, fmt.Errorf("failed to approve batch %s: too much contention", batchID)
+}
+
+// ClaimPendingApproval 将批次移出待审批索引并返回其记录。
+// 放行和取消都先认领，多实例下只有一方成功；批次不在索引中时返回 nil。
+func (c *Consumer) ClaimPendingApproval(ctx context.Context, userID, batchID string) (*PendingApproval, error) {
+	ref := BatchRef{UserID: userID, BatchID: batchID}
+	removed, err := c.redis.ZRem(ctx, PayoutApprovalsKey, ref.member()).Result()
+	if err != nil || removed == 0 {
+		return nil, err
+	}
+	batch, err := c.GetPendingApproval(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, fmt.Errorf("pending approval %s has no record", batchID)
+	}
+	return batch, nil
+}
+
+// PendingApprovals 返回等待审批的批次 (最早提交在前)
+func (c *Consumer) PendingApprovals(ctx context.Context) ([]*PendingApproval, error) {
+	members, err := c.redis.ZRange(ctx, PayoutApprovalsKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*PendingApproval, 0, len(members))
+	for _, m := range members {
+		ref, ok := parseBatchRef(m)
+		if !ok {
+			continue
+		}
+		batch, err := c.GetPendingApproval(ctx, ref.UserID, ref.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		if batch != nil {
+			out = append(out, batch)
+		}
+	}
+	return out, nil
+}
+
+// FinishPendingApproval 记录已认领批次的终态 (取消或放行后入队失败)，保留 BatchStatusTTL 供查询
+func (c *Consumer) FinishPendingApproval(ctx context.Context, batch *PendingApproval, state ApprovalState, cause error) error {
+	batch.State = state
+	batch.Error = ""
+	if cause != nil {
+		batch.Error = cause.Error()
+	}
+	batch.UpdatedAt = time.Now()
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, approvalKey(batch.UserID, batch.BatchID), data, BatchStatusTTL).Err()
+}
+
+// DeletePendingApproval 删除已放行批次的审批记录
+func (c *Consumer) DeletePendingApproval(ctx context.Context, userID, batchID string) error {
+	return c.redis.Del(ctx, approvalKey(userID, batchID)).Err()
+}
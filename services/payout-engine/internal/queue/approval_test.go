@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingApproval(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	now := time.Now()
+
+	batch := &PendingApproval{UserID: "user-1", BatchID: "payroll-1", Request: []byte(`{}`), RequestHash: "abc", Items: 3, ValueUSD: "250000", CreatedAt: now}
+	require.NoError(t, c.HoldForApproval(ctx, batch))
+
+	pending, err := c.PendingApprovals(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, ApprovalStatePending, pending[0].State)
+
+	owners, err := c.BatchOwners(ctx, "payroll-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1"}, owners)
+
+	// 同一审批人不能重复签名
+	updated, err := c.AddApproval(ctx, "user-1", "payroll-1", Approval{Operator: "0xAbC", Signature: "0x01", ApprovedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xAbC"}, updated.ApprovedBy())
+	_, err = c.AddApproval(ctx, "user-1", "payroll-1", Approval{Operator: "0xabc", Signature: "0x02", ApprovedAt: now})
+	assert.ErrorIs(t, err, ErrAlreadyApproved)
+	updated, err = c.AddApproval(ctx, "user-1", "payroll-1", Approval{Operator: "0xdef", Signature: "0x03", ApprovedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xAbC", "0xdef"}, updated.ApprovedBy())
+
+	_, err = c.AddApproval(ctx, "user-1", "unknown", Approval{Operator: "0xabc"})
+	assert.ErrorIs(t, err, ErrApprovalNotPending)
+
+	// 认领后不能再次认领
+	claimed, err := c.ClaimPendingApproval(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Len(t, claimed.Approvals, 2)
+	again, err := c.ClaimPendingApproval(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	require.NoError(t, c.FinishPendingApproval(ctx, claimed, ApprovalStateFailed, errors.New("insufficient balance")))
+	got, err := c.GetPendingApproval(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	assert.Equal(t, ApprovalStateFailed, got.State)
+	assert.Equal(t, "insufficient balance", got.Error)
+	_, err = c.AddApproval(ctx, "user-1", "payroll-1", Approval{Operator: "0x123"})
+	assert.ErrorIs(t, err, ErrApprovalNotPending)
+
+	pending, err = c.PendingApprovals(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	require.NoError(t, c.DeletePendingApproval(ctx, "user-1", "payroll-1"))
+	got, err = c.GetPendingApproval(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
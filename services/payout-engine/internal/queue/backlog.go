@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PayoutBacklogAlertPrefix 正在告警的积压条件 (多实例只发送一次告警和恢复通知)
+const PayoutBacklogAlertPrefix = "payout:backlog:alert:"
+
+// ChainBacklog 一条链在队列中的任务
+type ChainBacklog struct {
+	ChainID      uint64        `json:"chain_id"`
+	Queued       int64         `json:"queued"`     // 待投递
+	Processing   int64         `json:"processing"` // 已投递未确认
+	OldestJobAge time.Duration `json:"oldest_job_age_ns"`
+}
+
+// Backlog 队列积压快照: 待处理任务数、最久任务的等待时间 (自提交起) 和按链统计
+type Backlog struct {
+	Queued       int64           `json:"queued"`
+	Processing   int64           `json:"processing"`
+	DeadLetters  int64           `json:"dead_letters"`
+	OldestJobAge time.Duration   `json:"oldest_job_age_ns"`
+	Chains       []*ChainBacklog `json:"chains"` // 按 chain_id 排序
+	SampledAt    time.Time       `json:"sampled_at"`
+}
+
+// Chain 返回一条链的积压 (无任务时为 nil)
+func (b *Backlog) Chain(chainID uint64) *ChainBacklog {
+	for _, c := range b.Chains {
+		if c.ChainID == chainID {
+			return c
+		}
+	}
+	return nil
+}
+
+// Backlog 统计全部优先级通道中尚未完成的任务
+func (c *Consumer) Backlog(ctx context.Context) (*Backlog, error) {
+	now := time.Now()
+	b := &Backlog{SampledAt: now}
+	chains := make(map[uint64]*ChainBacklog)
+
+	for _, lane := range priorityLanes {
+		msgs, err := c.redis.XRange(ctx, lane, "-", "+").Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		pending, err := c.redis.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: lane,
+			Group:  PayoutConsumerGroup,
+			Start:  "-",
+			End:    "+",
+			Count:  int64(len(msgs)),
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		processing := make(map[string]bool, len(pending))
+		for _, p := range pending {
+			processing[p.ID] = true
+		}
+
+		for _, msg := range msgs {
+			raw, _ := msg.Values[streamJobField].(string)
+			var job Job
+			if err := json.Unmarshal([]byte(raw), &job); err != nil {
+				continue
+			}
+			chain := chains[job.ChainID]
+			if chain == nil {
+				chain = &ChainBacklog{ChainID: job.ChainID}
+				chains[job.ChainID] = chain
+			}
+			if processing[msg.ID] {
+				chain.Processing++
+				b.Processing++
+			} else {
+				chain.Queued++
+				b.Queued++
+			}
+
+			submitted := job.CreatedAt
+			if submitted.IsZero() {
+				submitted = job.QueuedAt
+			}
+			if submitted.IsZero() {
+				continue
+			}
+			age := now.Sub(submitted)
+			if age > chain.OldestJobAge {
+				chain.OldestJobAge = age
+			}
+			if age > b.OldestJobAge {
+				b.OldestJobAge = age
+			}
+		}
+	}
+
+	dead, err := c.GetDeadLetterCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.DeadLetters = dead
+
+	b.Chains = make([]*ChainBacklog, 0, len(chains))
+	for _, chain := range chains {
+		b.Chains = append(b.Chains, chain)
+	}
+	sort.Slice(b.Chains, func(i, j int) bool { return b.Chains[i].ChainID < b.Chains[j].ChainID })
+	return b, nil
+}
+
+// SetBacklogAlert 记录积压条件是否正在告警，返回状态是否改变 (多实例同时检查时只有一个返回 true)
+func (c *Consumer) SetBacklogAlert(ctx context.Context, condition string, firing bool) (bool, error) {
+	key := PayoutBacklogAlertPrefix + condition
+	if firing {
+		return c.redis.SetNX(ctx, key, time.Now().Unix(), 0).Result()
+	}
+	n, err := c.redis.Del(ctx, key).Result()
+	return n > 0, err
+}
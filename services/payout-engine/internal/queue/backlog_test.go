@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacklog(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	empty, err := c.Backlog(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, empty.Queued)
+	assert.Empty(t, empty.Chains)
+
+	now := time.Now()
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-1", ChainID: 1, CreatedAt: now.Add(-time.Hour)},
+		{ID: "job-2", ChainID: 1, CreatedAt: now.Add(-time.Minute)},
+		{ID: "job-3", ChainID: 8453, CreatedAt: now.Add(-10 * time.Minute), Priority: "URGENT"},
+	}))
+	d := deliver(t, c)
+	require.NotNil(t, d)
+
+	b, err := c.Backlog(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, b.Queued)
+	assert.EqualValues(t, 1, b.Processing, "the urgent job was delivered first")
+	assert.InDelta(t, time.Hour.Seconds(), b.OldestJobAge.Seconds(), 5)
+
+	require.Len(t, b.Chains, 2)
+	assert.Equal(t, &ChainBacklog{ChainID: 1, Queued: 2, OldestJobAge: b.OldestJobAge}, b.Chain(1))
+	base := b.Chain(8453)
+	assert.EqualValues(t, 0, base.Queued)
+	assert.EqualValues(t, 1, base.Processing)
+	assert.Nil(t, b.Chain(137))
+}
+
+func TestSetBacklogAlert(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	changed, err := c.SetBacklogAlert(ctx, "depth", true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = c.SetBacklogAlert(ctx, "depth", true)
+	require.NoError(t, err)
+	assert.False(t, changed, "already firing")
+
+	changed, err = c.SetBacklogAlert(ctx, "depth", false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = c.SetBacklogAlert(ctx, "depth", false)
+	require.NoError(t, err)
+	assert.False(t, changed, "already resolved")
+}
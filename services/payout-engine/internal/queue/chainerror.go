@@ -0,0 +1,49 @@
+package queue
+
+import "errors"
+
+// ChainErrorCode 链上失败的类别，调用方据此做重试和告警判断，无需匹配错误信息
+type ChainErrorCode string
+
+const (
+	ChainErrInsufficientFunds ChainErrorCode = "INSUFFICIENT_FUNDS" // 付款地址余额不足以支付金额或网络费
+	ChainErrNonceConflict     ChainErrorCode = "NONCE_CONFLICT"     // nonce 与链上不一致 (已使用或有空缺)
+	ChainErrRPCTimeout        ChainErrorCode = "RPC_TIMEOUT"        // 节点请求超时或连接失败
+	ChainErrReverted          ChainErrorCode = "REVERTED"           // 交易执行回滚 (分叉模拟或估算 gas 时)
+	ChainErrRejectedByNode    ChainErrorCode = "REJECTED_BY_NODE"   // 节点拒绝交易 (费用过低、校验失败等)
+	// 已上链的交易被重组移出，其 nonce 又被其他交易消耗，付款未发生
+	ChainErrReorgedNonceConsumed ChainErrorCode = "REORGED_NONCE_CONSUMED"
+)
+
+// ChainError 链上失败的类别和机器可读的详情 (如 revert_reason、rpc_code)，
+// 写入任务状态、死信和 webhook 事件 (chain_error 字段)
+type ChainError struct {
+	Code    ChainErrorCode    `json:"code"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// chainFailure 附带链上错误类别的失败原因 (保留原错误链中的错误码、重试策略和不可重试标记)
+type chainFailure struct {
+	chain *ChainError
+	err   error
+}
+
+func (e *chainFailure) Error() string { return e.err.Error() }
+func (e *chainFailure) Unwrap() error { return e.err }
+
+// WithChainError 为失败原因附带链上错误类别 (chain 为 nil 时原样返回)
+func WithChainError(err error, chain *ChainError) error {
+	if err == nil || chain == nil {
+		return err
+	}
+	return &chainFailure{chain: chain, err: err}
+}
+
+// ChainErrorOf 返回错误链中的链上错误类别 (无则为 nil)
+func ChainErrorOf(err error) *ChainError {
+	var failure *chainFailure
+	if errors.As(err, &failure) {
+		return failure.chain
+	}
+	return nil
+}
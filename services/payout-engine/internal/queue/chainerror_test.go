@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChainError(t *testing.T) {
+	chain := &ChainError{Code: ChainErrReverted, Details: map[string]string{"revert_reason": "ERC20: transfer amount exceeds balance"}}
+	err := WithChainError(Permanent(fmt.Errorf("failed to build transaction: %w", &codedError{code: "FEE_CAP_EXCEEDED"})), chain)
+
+	assert.Equal(t, "failed to build transaction: blocked", err.Error())
+	assert.Same(t, chain, ChainErrorOf(fmt.Errorf("wrapped: %w", err)))
+	assert.True(t, IsPermanent(err), "keeps the permanent marker")
+	assert.Equal(t, "FEE_CAP_EXCEEDED", ErrorCode(err), "keeps the error code")
+
+	assert.Nil(t, ChainErrorOf(errors.New("plain")))
+	assert.Nil(t, WithChainError(nil, chain))
+	plain := errors.New("plain")
+	assert.Same(t, plain, WithChainError(plain, nil))
+}
+
+func TestChainErrorRecorded(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	require.NoError(t, c.PushBatch(ctx, []*Job{{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1}}))
+
+	fail := func(ctx context.Context, job *Job) (*JobResult, error) {
+		return &JobResult{
+			JobID:      job.ID,
+			Error:      errors.New("failed to send transaction: nonce too low"),
+			ChainError: &ChainError{Code: ChainErrNonceConflict, Details: map[string]string{"class": "nonce_too_low"}},
+		}, nil
+	}
+	c.process(ctx, 0, deliver(t, c), fail)
+
+	status, err := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStateRetrying, status.State)
+	assert.Equal(t, "failed to send transaction: nonce too low", status.Error)
+	assert.Equal(t, &ChainError{Code: ChainErrNonceConflict, Details: map[string]string{"class": "nonce_too_low"}}, status.ChainError)
+
+	c.process(ctx, 0, deliver(t, c), fail)
+	entries, _, err := c.ListDeadLetters(ctx, "batch-1", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ChainErrNonceConflict, entries[0].ChainError.Code)
+
+	// 成功后清除失败信息
+	_, err = c.RequeueDeadLetter(ctx, "job-1")
+	require.NoError(t, err)
+	c.process(ctx, 0, deliver(t, c), func(ctx context.Context, job *Job) (*JobResult, error) {
+		return &JobResult{JobID: job.ID, Success: true, TxHash: "0xabc"}, nil
+	})
+	status, err = c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStateConfirmed, status.State)
+	assert.Nil(t, status.ChainError)
+}
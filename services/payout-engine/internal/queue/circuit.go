@@ -0,0 +1,282 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// 链熔断: 失败率超过阈值时暂停该链的任务，探测恢复后放回队列
+const (
+	PayoutCircuitsKey          = "payout:circuits"          // set: 熔断中的 chain_id
+	PayoutCircuitKeyPrefix     = "payout:circuit:"          // string: chain_id -> Circuit
+	PayoutCircuitOutcomePrefix = "payout:circuit:outcomes:" // zset: 窗口内的处理结果
+	PayoutCircuitFailurePrefix = "payout:circuit:failures:" // zset: 窗口内的失败
+	PayoutCircuitProbePrefix   = "payout:circuit:probe:"    // string: 探测锁
+	PayoutHeldKeyPrefix        = "payout:held:"             // list: 熔断期间暂存的任务
+)
+
+// CircuitPolicy 熔断策略
+type CircuitPolicy struct {
+	FailureRate float64       // 窗口内失败率达到该值时熔断
+	MinSamples  int           // 窗口内至少这么多结果才计算失败率
+	Window      time.Duration // 统计窗口
+	Cooldown    time.Duration // 熔断后 (或探测失败后) 等待多久再探测
+}
+
+// DefaultCircuitPolicy 默认策略: 5 分钟内至少 10 个结果且一半失败时熔断，2 分钟后探测
+var DefaultCircuitPolicy = CircuitPolicy{
+	FailureRate: 0.5,
+	MinSamples:  10,
+	Window:      5 * time.Minute,
+	Cooldown:    2 * time.Minute,
+}
+
+// CircuitPolicyFromConfig 由配置构建策略，未设置的字段使用默认值
+func CircuitPolicyFromConfig(cfg config.CircuitConfig) CircuitPolicy {
+	p := DefaultCircuitPolicy
+	if cfg.FailureRate > 0 && cfg.FailureRate <= 1 {
+		p.FailureRate = cfg.FailureRate
+	}
+	if cfg.MinSamples > 0 {
+		p.MinSamples = cfg.MinSamples
+	}
+	if cfg.Window > 0 {
+		p.Window = cfg.Window
+	}
+	if cfg.Cooldown > 0 {
+		p.Cooldown = cfg.Cooldown
+	}
+	return p
+}
+
+// Circuit 一条链的熔断记录 (存在即表示该链已暂停)
+type Circuit struct {
+	ChainID     uint64    `json:"chain_id"`
+	Reason      string    `json:"reason"`
+	FailureRate float64   `json:"failure_rate,omitempty"`
+	Samples     int64     `json:"samples,omitempty"`
+	OpenedAt    time.Time `json:"opened_at"`
+	NextProbeAt time.Time `json:"next_probe_at"`
+	Probes      int       `json:"probes"`
+	LastProbe   string    `json:"last_probe_error,omitempty"`
+	Manual      bool      `json:"manual,omitempty"` // 运维手动暂停，不自动探测恢复
+	Alerted     bool      `json:"alerted"`
+	Held        int64     `json:"held"` // 查询时填充
+}
+
+func circuitKey(chainID uint64) string {
+	return PayoutCircuitKeyPrefix + strconv.FormatUint(chainID, 10)
+}
+
+func heldKey(chainID uint64) string {
+	return PayoutHeldKeyPrefix + strconv.FormatUint(chainID, 10)
+}
+
+// SetCircuitPolicy 设置熔断策略 (须在 Start 之前调用)
+func (c *Consumer) SetCircuitPolicy(p CircuitPolicy) {
+	c.circuit = p
+}
+
+// RecordChainOutcome 记录一次链上处理结果 (id 用于去重)。
+// 窗口内失败率达到阈值时熔断该链，返回新打开的熔断记录。
+func (c *Consumer) RecordChainOutcome(ctx context.Context, chainID uint64, id string, failed bool) (*Circuit, error) {
+	now := time.Now()
+	chain := strconv.FormatUint(chainID, 10)
+	outcomes := PayoutCircuitOutcomePrefix + chain
+	failures := PayoutCircuitFailurePrefix + chain
+	member := strconv.FormatInt(now.UnixNano(), 10) + ":" + id
+	cutoff := strconv.FormatInt(now.Add(-c.circuit.Window).UnixMilli(), 10)
+
+	pipe := c.redis.TxPipeline()
+	pipe.ZAdd(ctx, outcomes, &redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	if failed {
+		pipe.ZAdd(ctx, failures, &redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	}
+	pipe.ZRemRangeByScore(ctx, outcomes, "-inf", "("+cutoff)
+	pipe.ZRemRangeByScore(ctx, failures, "-inf", "("+cutoff)
+	total := pipe.ZCard(ctx, outcomes)
+	failCount := pipe.ZCard(ctx, failures)
+	pipe.Expire(ctx, outcomes, c.circuit.Window)
+	pipe.Expire(ctx, failures, c.circuit.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	if !failed || total.Val() < int64(c.circuit.MinSamples) {
+		return nil, nil
+	}
+	rate := float64(failCount.Val()) / float64(total.Val())
+	if rate < c.circuit.FailureRate {
+		return nil, nil
+	}
+	circuit := &Circuit{
+		ChainID:     chainID,
+		Reason:      fmt.Sprintf("failure rate %.0f%% over %d jobs in %s", rate*100, total.Val(), c.circuit.Window),
+		FailureRate: rate,
+		Samples:     total.Val(),
+	}
+	opened, err := c.OpenCircuit(ctx, circuit)
+	if err != nil || !opened {
+		return nil, err
+	}
+	return circuit, nil
+}
+
+// OpenCircuit 熔断一条链，已熔断时返回 false
+func (c *Consumer) OpenCircuit(ctx context.Context, circuit *Circuit) (bool, error) {
+	now := time.Now()
+	circuit.OpenedAt = now
+	circuit.NextProbeAt = now.Add(c.circuit.Cooldown)
+	data, err := json.Marshal(circuit)
+	if err != nil {
+		return false, err
+	}
+	opened, err := c.redis.SetNX(ctx, circuitKey(circuit.ChainID), data, 0).Result()
+	if err != nil || !opened {
+		return false, err
+	}
+	if err := c.redis.SAdd(ctx, PayoutCircuitsKey, circuit.ChainID).Err(); err != nil {
+		return true, err
+	}
+	log.Error().Uint64("chain_id", circuit.ChainID).Str("reason", circuit.Reason).Msg("Chain circuit opened, pausing payouts")
+	return true, nil
+}
+
+// SaveCircuit 更新熔断记录 (探测结果、告警状态)。熔断已关闭时不写入。
+func (c *Consumer) SaveCircuit(ctx context.Context, circuit *Circuit) error {
+	data, err := json.Marshal(circuit)
+	if err != nil {
+		return err
+	}
+	return c.redis.SetXX(ctx, circuitKey(circuit.ChainID), data, redis.KeepTTL).Err()
+}
+
+// GetCircuit 返回链的熔断记录，未熔断时返回 nil
+func (c *Consumer) GetCircuit(ctx context.Context, chainID uint64) (*Circuit, error) {
+	data, err := c.redis.Get(ctx, circuitKey(chainID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var circuit Circuit
+	if err := json.Unmarshal(data, &circuit); err != nil {
+		return nil, err
+	}
+	circuit.Held, _ = c.redis.LLen(ctx, heldKey(chainID)).Result()
+	return &circuit, nil
+}
+
+// OpenCircuits 所有熔断中的链 (按 chain_id)
+func (c *Consumer) OpenCircuits(ctx context.Context) ([]*Circuit, error) {
+	members, err := c.redis.SMembers(ctx, PayoutCircuitsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var out []*Circuit
+	for _, m := range members {
+		chainID, err := strconv.ParseUint(m, 10, 64)
+		if err != nil {
+			continue
+		}
+		circuit, err := c.GetCircuit(ctx, chainID)
+		if err != nil {
+			return nil, err
+		}
+		if circuit == nil {
+			c.redis.SRem(ctx, PayoutCircuitsKey, m)
+			continue
+		}
+		out = append(out, circuit)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out, nil
+}
+
+// ClaimCircuitProbe 取得探测锁，避免多个实例同时发送探测交易
+func (c *Consumer) ClaimCircuitProbe(ctx context.Context, chainID uint64, ttl time.Duration) (bool, error) {
+	return c.redis.SetNX(ctx, PayoutCircuitProbePrefix+strconv.FormatUint(chainID, 10), 1, ttl).Result()
+}
+
+// CloseCircuit 恢复一条链: 清除熔断和统计窗口，暂存的任务放回队列。返回放回的任务数。
+func (c *Consumer) CloseCircuit(ctx context.Context, chainID uint64) (int, error) {
+	chain := strconv.FormatUint(chainID, 10)
+	pipe := c.redis.TxPipeline()
+	pipe.Del(ctx, circuitKey(chainID), PayoutCircuitOutcomePrefix+chain, PayoutCircuitFailurePrefix+chain, PayoutCircuitProbePrefix+chain)
+	pipe.SRem(ctx, PayoutCircuitsKey, chain)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	// 按原优先级放回 (并发放回时每条任务只移动一次)
+	released, err := c.drainList(ctx, heldKey(chainID), false)
+	if err != nil {
+		return released, err
+	}
+	log.Info().Uint64("chain_id", chainID).Int("released", released).Msg("Chain circuit closed, resuming payouts")
+	return released, nil
+}
+
+// holdIfCircuitOpen 链已熔断时将任务移入暂存列表并确认条目，返回是否已暂存
+func (c *Consumer) holdIfCircuitOpen(ctx context.Context, job *Job, d *delivery) bool {
+	open, err := c.redis.Exists(ctx, circuitKey(job.ChainID)).Result()
+	if err != nil || open == 0 {
+		return false
+	}
+	pipe := c.redis.TxPipeline()
+	pipe.LPush(ctx, heldKey(job.ChainID), d.raw)
+	c.ack(ctx, pipe, d)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to hold job for open circuit")
+		return false
+	}
+	// 熔断恰好在此期间关闭时立即放回 (CloseCircuit 已放回的不重复入队)
+	if open, _ := c.redis.Exists(ctx, circuitKey(job.ChainID)).Result(); open == 0 {
+		if removed, _ := c.redis.LRem(ctx, heldKey(job.ChainID), 1, d.raw).Result(); removed > 0 {
+			c.redis.XAdd(ctx, &redis.XAddArgs{Stream: laneKey(job.Priority), Values: []interface{}{streamJobField, d.raw}})
+		}
+	}
+	return true
+}
+
+// ThrottledError 发送方主动限流 (如付款地址在途交易达到上限)，按重试策略稍后重试
+type ThrottledError struct {
+	Err error
+}
+
+func (e *ThrottledError) Error() string { return e.Err.Error() }
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// Throttled 将错误标记为限流: 与链健康无关，不计入熔断失败率
+func Throttled(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ThrottledError{Err: err}
+}
+
+// IsThrottled 判断错误是否为限流
+func IsThrottled(err error) bool {
+	var t *ThrottledError
+	return errors.As(err, &t)
+}
+
+// recordOutcome 记录任务处理结果。不可重试的失败 (参数、白名单等) 和限流与链健康无关，不计入。
+func (c *Consumer) recordOutcome(ctx context.Context, job *Job, cause error) {
+	if cause != nil && (IsPermanent(cause) || IsThrottled(cause)) {
+		return
+	}
+	if _, err := c.RecordChainOutcome(ctx, job.ChainID, job.ID, cause != nil); err != nil {
+		log.Warn().Err(err).Uint64("chain_id", job.ChainID).Msg("Failed to record chain outcome")
+	}
+}
@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainCircuit(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.SetCircuitPolicy(CircuitPolicy{FailureRate: 0.5, MinSamples: 4, Window: time.Minute, Cooldown: time.Minute})
+
+	// 不可重试的失败和限流与链健康无关
+	for i := 0; i < 4; i++ {
+		c.recordOutcome(ctx, &Job{ID: fmt.Sprintf("bad-%d", i), ChainID: 1}, Permanent(errors.New("token not allowed")))
+		c.recordOutcome(ctx, &Job{ID: fmt.Sprintf("busy-%d", i), ChainID: 1}, Throttled(errors.New("too many in-flight transactions")))
+	}
+	circuit, err := c.GetCircuit(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, circuit)
+
+	var opened *Circuit
+	for i, failed := range []bool{false, true, false, true} {
+		opened, err = c.RecordChainOutcome(ctx, 1, fmt.Sprintf("job-%d", i), failed)
+		require.NoError(t, err)
+	}
+	require.NotNil(t, opened, "2 of 4 failed")
+	assert.Equal(t, 0.5, opened.FailureRate)
+
+	// 已熔断时不重复打开
+	again, err := c.RecordChainOutcome(ctx, 1, "job-5", true)
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	t.Run("jobs for the chain are held", func(t *testing.T) {
+		held := &Job{ID: "held-1", ChainID: 1}
+		other := &Job{ID: "other-1", ChainID: 2}
+		for _, job := range []*Job{held, other} {
+			require.NoError(t, c.Push(ctx, job))
+			assert.Equal(t, job == held, c.holdIfCircuitOpen(ctx, job, deliver(t, c)))
+		}
+
+		circuits, err := c.OpenCircuits(ctx)
+		require.NoError(t, err)
+		require.Len(t, circuits, 1)
+		assert.EqualValues(t, 1, circuits[0].Held)
+
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.EqualValues(t, 1, processing, "only the held job was acked")
+	})
+
+	t.Run("closing releases held jobs", func(t *testing.T) {
+		released, err := c.CloseCircuit(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, released)
+
+		queued, _ := c.GetQueueLength(ctx)
+		assert.EqualValues(t, 1, queued)
+		circuits, err := c.OpenCircuits(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, circuits)
+
+		// 统计窗口已清空
+		opened, err := c.RecordChainOutcome(ctx, 1, "job-6", true)
+		require.NoError(t, err)
+		assert.Nil(t, opened)
+	})
+
+	t.Run("probe lock is exclusive", func(t *testing.T) {
+		ok, err := c.ClaimCircuitProbe(ctx, 1, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = c.ClaimCircuitProbe(ctx, 1, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
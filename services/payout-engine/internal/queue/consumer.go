@@ -5,16 +5,19 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	PayoutQueueKey      = "payout:queue"
-	PayoutProcessingKey = "payout:processing"
+	PayoutQueueKey      = "payout:jobs"
 	PayoutDeadLetterKey = "payout:deadletter"
 	MaxRetries          = 3
 )
@@ -30,18 +33,146 @@ type Job struct {
 	TokenAddress  string          `json:"token_address"`
 	TokenSymbol   string          `json:"token_symbol"`
 	TokenDecimals uint32          `json:"token_decimals"`
+	TokenID       string          `json:"token_id,omitempty"` // TRC10 资产 ID (TokenAddress 为空)
+	MaxFee        string          `json:"max_fee,omitempty"`  // 网络费上限 (原生代币最小单位)，预计超过时不发送
 	ChainID       uint64          `json:"chain_id"`
+	SmartAccount  bool            `json:"smart_account,omitempty"` // ERC-4337 UserOperation 支付
+	Priority      string          `json:"priority,omitempty"`      // 费用优先级和队列通道 (LOW/MEDIUM/HIGH/URGENT)
+	Testnet       bool            `json:"testnet,omitempty"`       // 测试网任务
 	RetryCount    int             `json:"retry_count"`
 	CreatedAt     time.Time       `json:"created_at"`
+	QueuedAt      time.Time       `json:"queued_at"` // 最近一次进入待处理通道的时间 (防饿死)
 	Metadata      json.RawMessage `json:"metadata,omitempty"`
+
+	// 提交请求的 trace context (W3C traceparent)，消费时恢复以串联 span
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+
+	// 跨服务关联 ID (调用方生成，随任务状态、日志和 webhook 事件传递)
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// 收款方承担手续费时的扣费明细 (Amount 为扣费后净额)
+	FeeMode     string `json:"fee_mode,omitempty"`
+	GrossAmount string `json:"gross_amount,omitempty"`
+	NetworkFee  string `json:"network_fee,omitempty"`
+	ServiceFee  string `json:"service_fee,omitempty"`
+
+	// 人工批准越过支出策略 (见 OverrideDeadLetter)
+	PolicyOverride *PolicyOverride `json:"policy_override,omitempty"`
+
+	// 批次越过收款地址筛查的批准理由 (为空时正常筛查)
+	ScreeningOverride string `json:"screening_override,omitempty"`
+
+	// 人工确认收款地址 (越过活跃度检查，见 ApproveRecipientDeadLetter)
+	RecipientApproval *PolicyOverride `json:"recipient_approval,omitempty"`
+
+	// 热钱包归集到运维配置的冷钱包 (不做租户白名单、收款地址检查和支出策略)
+	Sweep bool `json:"sweep,omitempty"`
+
+	// 代付: 以 transferFrom 从 Permit.Owner 转出 (FromAddress 为发送交易的付款地址)
+	Permit *Permit `json:"permit,omitempty"`
+
+	// 金库出款: 以 transferFrom 从中央金库合约转出 (合约对 FromAddress 授权额度，见 config.ChainConfig.Treasury)
+	Treasury string `json:"treasury,omitempty"`
+
+	// 转账前兑换: 以 FromAddress 持有的 Swap.SellToken 经 DEX 聚合器换出 Amount 的 TokenAddress
+	Swap *Swap `json:"swap,omitempty"`
+
+	// 跨链路由: 资金在 Route.SourceChainID 上，先经跨链桥转到 ChainID 上的 FromAddress 再支付 (进度见 JobStatus.Route)
+	Route *Route `json:"route,omitempty"`
+
+	// x402 中继: 以 transferWithAuthorization 执行 Authorization.From 签名的 EIP-3009 转账
+	// (FromAddress 为支付 Gas 的付款地址，ToAddress/Amount 为授权的收款方和金额)
+	Authorization *Authorization `json:"authorization,omitempty"`
+
+	// x402 中继: 经 ERC-2771 可信转发合约执行 Forward.From 签名的代币 transfer(ToAddress, Amount)
+	Forward *ForwardRequest `json:"forward,omitempty"`
+}
+
+// Asset 转出的资产: TRC10 资产 ID、代币合约地址，原生代币为空
+func (j *Job) Asset() string {
+	if j.TokenID != "" {
+		return j.TokenID
+	}
+	return j.TokenAddress
+}
+
+// Source 转出资金的地址 (代付为源钱包，金库出款为金库合约，x402 中继为授权方，否则为付款地址)
+func (j *Job) Source() string {
+	if j.Permit != nil {
+		return j.Permit.Owner
+	}
+	if j.Treasury != "" {
+		return j.Treasury
+	}
+	if j.Authorization != nil {
+		return j.Authorization.From
+	}
+	if j.Forward != nil {
+		return j.Forward.From
+	}
+	return j.FromAddress
+}
+
+// Permit 源钱包签名的 EIP-2612 授权 (spender 为付款地址)
+type Permit struct {
+	Owner    string `json:"owner"`
+	Value    string `json:"value"`    // 授权额度 (代币最小单位)
+	Deadline int64  `json:"deadline"` // Unix 秒
+	V        uint8  `json:"v"`
+	R        string `json:"r"` // 0x 前缀 32 字节
+	S        string `json:"s"`
+}
+
+// Swap 支付前将付款地址持有的代币兑换为支付代币
+type Swap struct {
+	SellToken      string `json:"sell_token"`       // 付款地址持有的代币合约
+	MaxSlippageBps int    `json:"max_slippage_bps"` // 批次允许的最大滑点 (基点)
+}
+
+// Authorization 付款方签名的 EIP-3009 TransferWithAuthorization
+type Authorization struct {
+	From        string `json:"from"`
+	ValidAfter  int64  `json:"valid_after"`  // Unix 秒
+	ValidBefore int64  `json:"valid_before"` // Unix 秒
+	Nonce       string `json:"nonce"`        // 0x 前缀 32 字节
+	Signature   string `json:"signature"`    // 0x 前缀 65 字节 (r, s, v)
+}
+
+// ForwardRequest 付款方签名的 ERC-2771 转发请求 (目标为 TokenAddress，value 为 0)
+type ForwardRequest struct {
+	Forwarder string `json:"forwarder"`
+	From      string `json:"from"`
+	Gas       uint64 `json:"gas"`       // 转发调用的 gas 上限
+	Deadline  int64  `json:"deadline"`  // Unix 秒
+	Nonce     string `json:"nonce"`     // 提交时转发合约中 From 的 nonce
+	Data      string `json:"data"`      // 签名的 transfer 调用数据 (原样转发)
+	Signature string `json:"signature"` // 0x 前缀 65 字节
+}
+
+// PolicyOverride 越过支出策略的批准记录
+type PolicyOverride struct {
+	ApprovedBy string    `json:"approved_by"`
+	Reason     string    `json:"reason"`
+	ApprovedAt time.Time `json:"approved_at"`
 }
 
 // JobResult 任务结果
 type JobResult struct {
-	JobID   string
-	Success bool
-	TxHash  string
-	Error   error
+	JobID        string
+	Success      bool
+	TxHash       string
+	BlockNumber  uint64   // 交易所在区块 (处理时已等待确认的链，否则为 0)
+	GasCost      *GasCost // 处理时已取得的实际网络费 (TRON；EVM 由卡单检测按回执记录)
+	Error        error
+	RevertReason string // 签名前分叉模拟回滚的原因
+
+	// 失败时链上错误的类别和详情 (无法归类时为 nil)
+	ChainError *ChainError
+}
+
+// failure 失败原因，附带链上错误类别 (见 ChainErrorOf)
+func (r *JobResult) failure() error {
+	return WithChainError(r.Error, r.ChainError)
 }
 
 // ProcessFunc 任务处理函数
@@ -51,6 +182,19 @@ type ProcessFunc func(ctx context.Context, job *Job) (*JobResult, error)
 type Consumer struct {
 	redis      *redis.Client
 	workerPool int
+	retry      RetryPolicy
+	ledger     bool // 状态变更写入账本 outbox (见 EnableLedger)
+	reports    bool // 批次结束时排队生成报告 (见 EnableReports)
+	circuit    CircuitPolicy
+	priority   PriorityPolicy
+	lease      LeasePolicy
+	name       string // 消费组中的消费者名前缀 (每个 worker 加编号)
+
+	claimMu   sync.Mutex
+	nextClaim time.Time // 下次检查过期租约的时间
+
+	chainSlots map[uint64]chan struct{} // 并发处理的链及其名额 (见 SetChainConcurrency)
+	turns      sendTurns
 }
 
 // NewConsumer 创建队列消费者
@@ -83,31 +227,54 @@ func NewConsumer(ctx context.Context, cfg config.RedisConfig) (*Consumer, error)
 		return nil, fmt.Errorf("redis connection failed: %w", err)
 	}
 
-	return &Consumer{
+	c := &Consumer{
 		redis:      rdb,
 		workerPool: 10, // 并发工作线程数
-	}, nil
+		retry:      DefaultRetryPolicy,
+		circuit:    DefaultCircuitPolicy,
+		priority:   DefaultPriorityPolicy,
+		lease:      DefaultLeasePolicy,
+		name:       consumerName(),
+	}
+	if err := c.ensureStreams(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetRetryPolicy 设置失败重试策略 (须在 Start 之前调用)
+func (c *Consumer) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
 }
 
 // Push 添加任务到队列
 func (c *Consumer) Push(ctx context.Context, job *Job) error {
-	data, err := json.Marshal(job)
-	if err != nil {
-		return fmt.Errorf("failed to marshal job: %w", err)
-	}
-
-	return c.redis.LPush(ctx, PayoutQueueKey, data).Err()
+	return c.PushBatch(ctx, []*Job{job})
 }
 
-// PushBatch 批量添加任务
+// PushBatch 批量添加任务到各自优先级的通道，同时记录任务状态
 func (c *Consumer) PushBatch(ctx context.Context, jobs []*Job) error {
-	pipe := c.redis.Pipeline()
+	pipe := c.redis.TxPipeline()
+	traceContext := tracing.Inject(ctx)
+	correlationID := correlation.FromContext(ctx)
+	now := time.Now()
 	for _, job := range jobs {
+		job.QueuedAt = now
+		if job.TraceContext == nil {
+			job.TraceContext = traceContext
+		}
+		if job.CorrelationID == "" {
+			job.CorrelationID = correlationID
+		}
 		data, err := json.Marshal(job)
 		if err != nil {
 			return fmt.Errorf("failed to marshal job: %w", err)
 		}
-		pipe.LPush(ctx, PayoutQueueKey, data)
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: laneKey(job.Priority), Values: []interface{}{streamJobField, data}})
+		if err := trackQueued(ctx, pipe, job); err != nil {
+			return fmt.Errorf("failed to marshal job status: %w", err)
+		}
+		c.queueLedger(ctx, pipe, newJobStatus(job, JobStatePending))
 	}
 	_, err := pipe.Exec(ctx)
 	return err
@@ -123,9 +290,10 @@ func (c *Consumer) Start(ctx context.Context, processFn ProcessFunc) {
 	}
 }
 
-// worker 工作协程
+// worker 工作协程，在消费组中以 <实例名>-<编号> 作为消费者
 func (c *Consumer) worker(ctx context.Context, id int, processFn ProcessFunc) {
-	log.Info().Int("worker_id", id).Msg("Worker started")
+	consumer := fmt.Sprintf("%s-%d", c.name, id)
+	log.Info().Int("worker_id", id).Str("consumer", consumer).Msg("Worker started")
 
 	for {
 		select {
@@ -133,97 +301,234 @@ func (c *Consumer) worker(ctx context.Context, id int, processFn ProcessFunc) {
 			log.Info().Int("worker_id", id).Msg("Worker stopped")
 			return
 		default:
-			// 从队列获取任务（阻塞等待 5 秒）
-			result, err := c.redis.BRPopLPush(ctx, PayoutQueueKey, PayoutProcessingKey, 5*time.Second).Result()
+			// 按优先级从各通道获取任务，均为空时等待后重试
+			d, err := c.next(ctx, consumer)
 			if err == redis.Nil {
-				continue // 超时，继续等待
+				select {
+				case <-ctx.Done():
+				case <-time.After(c.priority.PollInterval):
+				}
+				continue
 			}
 			if err != nil {
-				log.Error().Err(err).Int("worker_id", id).Msg("Failed to pop from queue")
+				log.Error().Err(err).Int("worker_id", id).Msg("Failed to read from queue")
+				select {
+				case <-ctx.Done():
+				case <-time.After(c.priority.PollInterval):
+				}
 				continue
 			}
 
-			// 解析任务
-			var job Job
-			if err := json.Unmarshal([]byte(result), &job); err != nil {
-				log.Error().Err(err).Str("data", result).Msg("Failed to unmarshal job")
-				c.removeFromProcessing(ctx, result)
+			// 并发链交给独立协程处理
+			if c.dispatch(ctx, id, consumer, d, processFn) {
 				continue
 			}
 
-			log.Info().
-				Str("job_id", job.ID).
-				Str("batch_id", job.BatchID).
-				Int("worker_id", id).
-				Msg("Processing job")
+			// 处理期间续约，避免被其他 worker 认领
+			leaseCtx, stop := c.keepLease(ctx, consumer, d)
+			c.process(leaseCtx, id, d, processFn)
+			stop()
+		}
+	}
+}
 
-			// 处理任务
-			jobResult, err := processFn(ctx, &job)
-			if err != nil {
-				c.handleFailure(ctx, &job, result, err)
-			} else if !jobResult.Success {
-				c.handleFailure(ctx, &job, result, jobResult.Error)
-			} else {
-				c.handleSuccess(ctx, &job, result, jobResult.TxHash)
+// process 处理一个条目，完成后确认 (重试时作为新条目重新入队)
+func (c *Consumer) process(ctx context.Context, workerID int, d *delivery, processFn ProcessFunc) {
+	// 解析任务
+	var job Job
+	if err := json.Unmarshal([]byte(d.raw), &job); err != nil {
+		log.Error().Err(err).Str("data", d.raw).Msg("Failed to unmarshal job")
+		c.finish(ctx, d)
+		return
+	}
+
+	// 认领的条目: 之前的 worker 可能已发送交易
+	if d.recovered && c.settleRecovered(ctx, &job, d) {
+		return
+	}
+
+	// 批次已取消: 不发送
+	if c.isCancelled(ctx, &job) {
+		log.Info().Str("job_id", job.ID).Str("batch_id", job.BatchID).Msg("Batch cancelled, skipping job")
+		c.updateState(ctx, &job, JobStateCancelled, "", nil)
+		c.finish(ctx, d)
+		return
+	}
+
+	// 链已熔断: 暂存，恢复后放回队列
+	if c.holdIfCircuitOpen(ctx, &job, d) {
+		log.Info().Str("job_id", job.ID).Uint64("chain_id", job.ChainID).Msg("Chain circuit open, holding job")
+		return
+	}
+
+	log.Info().
+		Str("job_id", job.ID).
+		Str("batch_id", job.BatchID).
+		Str(correlation.LogField, job.CorrelationID).
+		Int("worker_id", workerID).
+		Msg("Processing job")
+	c.updateState(ctx, &job, JobStateProcessing, "", nil)
+
+	// 处理任务
+	jobCtx, span := tracing.Start(correlation.WithID(tracing.Extract(ctx, job.TraceContext), job.CorrelationID), "payout.process_job",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			tracing.AttrJobID.String(job.ID),
+			tracing.AttrBatchID.String(job.BatchID),
+			tracing.AttrCorrelationID.String(job.CorrelationID),
+			tracing.AttrChainID.Int64(int64(job.ChainID)),
+			tracing.AttrRetry.Int(job.RetryCount),
+		))
+	jobResult, err := processFn(jobCtx, &job)
+	if err == nil && !jobResult.Success {
+		tracing.End(span, jobResult.Error)
+	} else {
+		if err == nil {
+			span.SetAttributes(tracing.AttrTxHash.String(jobResult.TxHash))
+		}
+		tracing.End(span, err)
+	}
+
+	// 租约已被其他 worker 认领: 由其核对链上结果，不再更新状态或确认条目
+	if leaseLost(ctx) {
+		log.Warn().Str("job_id", job.ID).Str("batch_id", job.BatchID).Msg("Job lease lost while processing, leaving the job to the worker that claimed it")
+		return
+	}
+
+	// 执行失败的交易同样消耗网络费 (如 TRON 能量耗尽)
+	if err == nil && jobResult.GasCost != nil {
+		if err := c.RecordGasFee(ctx, BatchRef{UserID: job.UserID, BatchID: job.BatchID}, job.ID, *jobResult.GasCost); err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record gas fee")
+		}
+	}
+	// 网络费超过上限: 暂存到费用回落 (与链健康无关，不计入重试)
+	if cause := err; cause != nil || !jobResult.Success {
+		if cause == nil {
+			cause = jobResult.failure()
+		}
+		if IsGasHold(cause) && c.holdForGas(ctx, &job, d, cause) {
+			return
+		}
+	}
+	if err != nil {
+		c.recordOutcome(ctx, &job, err)
+		c.handleFailure(ctx, &job, d, err)
+	} else if !jobResult.Success {
+		c.recordOutcome(ctx, &job, jobResult.Error)
+		c.handleFailure(ctx, &job, d, jobResult.failure())
+	} else {
+		c.recordOutcome(ctx, &job, nil)
+		if jobResult.BlockNumber > 0 {
+			if err := c.recordBlockNumber(ctx, &job, jobResult.BlockNumber); err != nil {
+				log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record block number")
 			}
 		}
+		c.handleSuccess(ctx, &job, d, jobResult.TxHash)
 	}
 }
 
 // handleSuccess 处理成功
-func (c *Consumer) handleSuccess(ctx context.Context, job *Job, rawData string, txHash string) {
+func (c *Consumer) handleSuccess(ctx context.Context, job *Job, d *delivery, txHash string) {
 	log.Info().
 		Str("job_id", job.ID).
+		Str(correlation.LogField, job.CorrelationID).
 		Str("tx_hash", txHash).
 		Msg("Job completed successfully")
 
-	c.removeFromProcessing(ctx, rawData)
+	if err := c.recordOutflow(ctx, job); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record outflow")
+	}
+	c.updateState(ctx, job, JobStateConfirmed, txHash, nil)
+	c.finish(ctx, d)
 }
 
-// handleFailure 处理失败
-func (c *Consumer) handleFailure(ctx context.Context, job *Job, rawData string, err error) {
+// handleFailure 处理失败: 按重试策略退避后重新入队，超过次数或不可重试时进入死信队列
+func (c *Consumer) handleFailure(ctx context.Context, job *Job, d *delivery, err error) {
 	job.RetryCount++
+	policy := c.retryPolicyFor(err)
 
-	if job.RetryCount >= MaxRetries {
+	if job.RetryCount >= policy.MaxRetries || IsPermanent(err) {
 		log.Error().
 			Str("job_id", job.ID).
+			Str(correlation.LogField, job.CorrelationID).
 			Int("retries", job.RetryCount).
+			Bool("permanent", IsPermanent(err)).
 			Err(err).
-			Msg("Job exceeded max retries, moving to dead letter queue")
+			Msg("Job failed permanently, moving to dead letter queue")
 
-		// 移到死信队列
-		data, _ := json.Marshal(job)
-		c.redis.LPush(ctx, PayoutDeadLetterKey, data)
-		c.removeFromProcessing(ctx, rawData)
+		if dlqErr := c.moveToDeadLetter(ctx, job, err); dlqErr != nil {
+			// 不确认条目，租约过期后重新认领，避免任务丢失
+			log.Error().Err(dlqErr).Str("job_id", job.ID).Msg("Failed to write dead letter")
+			return
+		}
+		c.updateState(ctx, job, JobStateFailed, "", err)
+		c.finish(ctx, d)
 		return
 	}
 
+	backoff := policy.Delay(job.RetryCount)
 	log.Warn().
 		Str("job_id", job.ID).
 		Int("retry_count", job.RetryCount).
+		Dur("backoff", backoff).
 		Err(err).
 		Msg("Job failed, requeueing")
 
-	// 重新入队（延迟重试）
-	time.Sleep(time.Duration(job.RetryCount) * 5 * time.Second)
-	data, _ := json.Marshal(job)
-	c.redis.LPush(ctx, PayoutQueueKey, data)
-	c.removeFromProcessing(ctx, rawData)
+	c.updateState(ctx, job, JobStateRetrying, "", err)
+
+	// 重新入队（延迟重试，期间仍持有租约）
+	time.Sleep(backoff)
+	if c.isCancelled(ctx, job) {
+		c.updateState(ctx, job, JobStateCancelled, "", err)
+		c.finish(ctx, d)
+		return
+	}
+	if err := c.requeue(ctx, job, d); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to requeue job")
+	}
 }
 
-// removeFromProcessing 从处理中列表移除
-func (c *Consumer) removeFromProcessing(ctx context.Context, rawData string) {
-	c.redis.LRem(ctx, PayoutProcessingKey, 1, rawData)
+// updateState 更新任务状态，失败只记录日志
+func (c *Consumer) updateState(ctx context.Context, job *Job, state JobState, txHash string, cause error) {
+	if err := c.setJobState(ctx, job, state, txHash, cause); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Str("state", string(state)).Msg("Failed to update job status")
+		return
+	}
+	c.notifyState(ctx, job, state)
 }
 
-// GetQueueLength 获取队列长度
+// GetQueueLength 获取队列长度 (全部优先级通道中尚未投递的任务)
 func (c *Consumer) GetQueueLength(ctx context.Context) (int64, error) {
-	return c.redis.LLen(ctx, PayoutQueueKey).Result()
+	total, pending, err := c.streamCounts(ctx)
+	return total - pending, err
 }
 
-// GetProcessingCount 获取处理中数量
+// GetProcessingCount 获取处理中数量 (已投递未确认)
 func (c *Consumer) GetProcessingCount(ctx context.Context) (int64, error) {
-	return c.redis.LLen(ctx, PayoutProcessingKey).Result()
+	_, pending, err := c.streamCounts(ctx)
+	return pending, err
+}
+
+// streamCounts 各通道条目总数和待确认数之和
+func (c *Consumer) streamCounts(ctx context.Context) (int64, int64, error) {
+	pipe := c.redis.Pipeline()
+	lens := make([]*redis.IntCmd, len(priorityLanes))
+	pending := make([]*redis.XPendingCmd, len(priorityLanes))
+	for i, key := range priorityLanes {
+		lens[i] = pipe.XLen(ctx, key)
+		pending[i] = pipe.XPending(ctx, key, PayoutConsumerGroup)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	var total, inFlight int64
+	for i := range priorityLanes {
+		total += lens[i].Val()
+		if p := pending[i].Val(); p != nil {
+			inFlight += p.Count
+		}
+	}
+	return total, inFlight, nil
 }
 
 // GetDeadLetterCount 获取死信队列数量
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	_, err := tracing.Setup(context.Background(), tracing.Config{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newTestConsumer(t)
+
+	submitCtx, submit := tracing.Start(ctx, "payout.submit_batch")
+	require.NoError(t, c.PushBatch(submitCtx, []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()},
+	}))
+	submit.End()
+
+	seen := make(chan trace.SpanContext, 1)
+	go c.worker(ctx, 0, func(ctx context.Context, job *Job) (*JobResult, error) {
+		seen <- trace.SpanContextFromContext(ctx)
+		return &JobResult{JobID: job.ID, Success: true, TxHash: "0xabc"}, nil
+	})
+
+	select {
+	case sc := <-seen:
+		assert.Equal(t, submit.SpanContext().TraceID(), sc.TraceID(), "job span joins the submit trace")
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not processed")
+	}
+
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 2 }, time.Second, 10*time.Millisecond)
+	span := recorder.Ended()[1]
+	assert.Equal(t, "payout.process_job", span.Name())
+	assert.Equal(t, submit.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), tracing.AttrTxHash.String("0xabc"))
+}
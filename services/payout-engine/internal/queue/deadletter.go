@@ -0,0 +1,295 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+)
+
+// RetryPolicy 任务失败重试策略
+type RetryPolicy struct {
+	MaxRetries     int           // 超过后进入死信队列
+	InitialBackoff time.Duration // 第一次重试前的等待
+	MaxBackoff     time.Duration // 退避上限
+	Multiplier     float64       // 指数退避倍数 (1 = 线性不变)
+	Jitter         float64       // 等待时间随机浮动的比例 (0.2 = ±20%)，避免同时失败的任务同时重试
+}
+
+// DefaultRetryPolicy 默认策略: 3 次，5s 起指数退避，最长 1 分钟，±10% 浮动
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     MaxRetries,
+	InitialBackoff: 5 * time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     2,
+	Jitter:         0.1,
+}
+
+// RetryPolicyFromConfig 由配置构建策略，未设置的字段使用默认值
+func RetryPolicyFromConfig(cfg config.RetryConfig) RetryPolicy {
+	p := DefaultRetryPolicy
+	if cfg.MaxRetries > 0 {
+		p.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.InitialBackoff > 0 {
+		p.InitialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff > 0 {
+		p.MaxBackoff = cfg.MaxBackoff
+	}
+	if cfg.Multiplier >= 1 {
+		p.Multiplier = cfg.Multiplier
+	}
+	if cfg.Jitter > 0 && cfg.Jitter < 1 {
+		p.Jitter = cfg.Jitter
+	}
+	return p
+}
+
+// Merge 用 override 中已设置的字段覆盖 p
+func (p RetryPolicy) Merge(override RetryPolicy) RetryPolicy {
+	if override.MaxRetries > 0 {
+		p.MaxRetries = override.MaxRetries
+	}
+	if override.InitialBackoff > 0 {
+		p.InitialBackoff = override.InitialBackoff
+	}
+	if override.MaxBackoff > 0 {
+		p.MaxBackoff = override.MaxBackoff
+	}
+	if override.Multiplier >= 1 {
+		p.Multiplier = override.Multiplier
+	}
+	if override.Jitter > 0 && override.Jitter < 1 {
+		p.Jitter = override.Jitter
+	}
+	return p
+}
+
+// Backoff 返回第 attempt 次重试 (从 1 开始) 前的等待时间
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+		if p.MaxBackoff > 0 && time.Duration(d) >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(d)
+}
+
+// Delay 第 attempt 次重试前实际等待的时间 (Backoff 加随机浮动)
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.Backoff(attempt)
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+// RetryHint 自带重试策略的失败 (如按 RPC 错误类别)，未设置的字段使用 Consumer 的策略
+type RetryHint interface {
+	error
+	RetryPolicy() RetryPolicy
+}
+
+// retryPolicyFor 错误链中有 RetryHint 时合并其策略
+func (c *Consumer) retryPolicyFor(err error) RetryPolicy {
+	var hint RetryHint
+	if errors.As(err, &hint) {
+		return c.retry.Merge(hint.RetryPolicy())
+	}
+	return c.retry
+}
+
+// PermanentError 不可重试的失败 (参数错误、重复支付保护等)，直接进入死信队列
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent 将错误标记为不可重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent 判断错误是否不可重试
+func IsPermanent(err error) bool {
+	var p *PermanentError
+	return errors.As(err, &p)
+}
+
+// CodedError 带错误码的失败 (如支出策略拦截)，错误码写入任务状态和死信
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// ErrorCode 返回错误链中的错误码 (无则为空)
+func ErrorCode(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return ""
+}
+
+// DeadLetter 死信队列条目
+type DeadLetter struct {
+	Job       *Job      `json:"job"`
+	Error     string    `json:"error"`
+	ErrorCode string    `json:"error_code,omitempty"`
+	Attempts  int       `json:"attempts"`
+	Permanent bool      `json:"permanent,omitempty"`
+	FailedAt  time.Time `json:"failed_at"`
+
+	// 链上失败的类别和详情 (见 ChainError)
+	ChainError *ChainError `json:"chain_error,omitempty"`
+}
+
+// ErrDeadLetterNotFound 死信队列中没有该任务
+var ErrDeadLetterNotFound = errors.New("job not found in dead letter queue")
+
+// moveToDeadLetter 写入死信队列
+func (c *Consumer) moveToDeadLetter(ctx context.Context, job *Job, cause error) error {
+	entry := DeadLetter{
+		Job:       job,
+		Attempts:  job.RetryCount,
+		Permanent: IsPermanent(cause),
+		FailedAt:  time.Now(),
+	}
+	if cause != nil {
+		entry.Error = cause.Error()
+		entry.ErrorCode = ErrorCode(cause)
+		entry.ChainError = ChainErrorOf(cause)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	return c.redis.LPush(ctx, PayoutDeadLetterKey, data).Err()
+}
+
+// parseDeadLetter 解析条目 (兼容旧版直接存储 Job 的格式)
+func parseDeadLetter(raw string) (*DeadLetter, error) {
+	var entry DeadLetter
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return nil, err
+	}
+	if entry.Job == nil {
+		var job Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil || job.ID == "" {
+			return nil, fmt.Errorf("unrecognized dead letter entry")
+		}
+		entry = DeadLetter{Job: &job, Attempts: job.RetryCount}
+	}
+	return &entry, nil
+}
+
+// ListDeadLetters 分页列出死信 (最新在前)，batchID 非空时只返回该批次
+func (c *Consumer) ListDeadLetters(ctx context.Context, batchID string, offset, limit int) ([]*DeadLetter, int, error) {
+	raws, err := c.redis.LRange(ctx, PayoutDeadLetterKey, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []*DeadLetter
+	for _, raw := range raws {
+		entry, err := parseDeadLetter(raw)
+		if err != nil {
+			continue
+		}
+		if batchID != "" && entry.Job.BatchID != batchID {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	total := len(matched)
+	if offset >= total {
+		return []*DeadLetter{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return matched[offset:end], total, nil
+}
+
+// RequeueDeadLetter 将死信任务重置重试次数后放回队列
+func (c *Consumer) RequeueDeadLetter(ctx context.Context, jobID string) (*Job, error) {
+	return c.requeueDeadLetter(ctx, jobID, nil)
+}
+
+// ErrOverrideNotApplicable 死信不是因该错误码失败，不能覆盖
+var ErrOverrideNotApplicable = errors.New("job did not fail with an overridable error")
+
+// OverrideDeadLetter 人工批准后重新入队因 code 失败的任务 (如支出策略拦截)，
+// 任务带上批准记录，处理时跳过对应检查。
+func (c *Consumer) OverrideDeadLetter(ctx context.Context, jobID, code string, override PolicyOverride) (*Job, error) {
+	return c.requeueDeadLetter(ctx, jobID, func(entry *DeadLetter) error {
+		if entry.ErrorCode != code {
+			return ErrOverrideNotApplicable
+		}
+		entry.Job.PolicyOverride = &override
+		return nil
+	})
+}
+
+// ApproveRecipientDeadLetter 人工确认收款地址后重新入队因 code 失败的任务 (如收款地址活跃度检查)
+func (c *Consumer) ApproveRecipientDeadLetter(ctx context.Context, jobID, code string, approval PolicyOverride) (*Job, error) {
+	return c.requeueDeadLetter(ctx, jobID, func(entry *DeadLetter) error {
+		if entry.ErrorCode != code {
+			return ErrOverrideNotApplicable
+		}
+		entry.Job.RecipientApproval = &approval
+		return nil
+	})
+}
+
+func (c *Consumer) requeueDeadLetter(ctx context.Context, jobID string, prepare func(*DeadLetter) error) (*Job, error) {
+	raws, err := c.redis.LRange(ctx, PayoutDeadLetterKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range raws {
+		entry, err := parseDeadLetter(raw)
+		if err != nil || entry.Job.ID != jobID {
+			continue
+		}
+		if prepare != nil {
+			if err := prepare(entry); err != nil {
+				return nil, err
+			}
+		}
+
+		// 先移除再入队：并发 requeue 时只有一个成功
+		removed, err := c.redis.LRem(ctx, PayoutDeadLetterKey, 1, raw).Result()
+		if err != nil {
+			return nil, err
+		}
+		if removed == 0 {
+			return nil, ErrDeadLetterNotFound
+		}
+
+		job := entry.Job
+		job.RetryCount = 0
+		if err := c.Push(ctx, job); err != nil {
+			// 入队失败时放回死信队列，避免任务丢失
+			c.redis.LPush(ctx, PayoutDeadLetterKey, raw)
+			return nil, fmt.Errorf("failed to requeue job: %w", err)
+		}
+		return job, nil
+	}
+	return nil, ErrDeadLetterNotFound
+}
@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestConsumer creates a Consumer backed by miniredis for testing.
+func newTestConsumer(t *testing.T) *Consumer {
+	c, _ := newTestConsumerWithServer(t)
+	return c
+}
+
+// newTestConsumerWithServer also returns the Redis server, e.g. to move its clock.
+func newTestConsumerWithServer(t *testing.T) (*Consumer, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+		mr.Close()
+	})
+	c := &Consumer{redis: client, workerPool: 1, retry: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, Multiplier: 1}, circuit: DefaultCircuitPolicy,
+		priority: PriorityPolicy{MaxWait: DefaultPriorityPolicy.MaxWait, PollInterval: time.Millisecond}, lease: DefaultLeasePolicy, name: "test"}
+	require.NoError(t, c.ensureStreams(context.Background()))
+	return c, mr
+}
+
+// deliver reads the next entry as a worker would; nil when every lane is empty.
+func deliver(t *testing.T, c *Consumer) *delivery {
+	t.Helper()
+	d, err := c.next(context.Background(), "test-0")
+	if err == redis.Nil {
+		return nil
+	}
+	require.NoError(t, err)
+	return d
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 5 * time.Second, MaxBackoff: 30 * time.Second, Multiplier: 2}
+	assert.Equal(t, 5*time.Second, p.Backoff(1))
+	assert.Equal(t, 10*time.Second, p.Backoff(2))
+	assert.Equal(t, 20*time.Second, p.Backoff(3))
+	assert.Equal(t, 30*time.Second, p.Backoff(4))
+
+	p.Jitter = 0.2
+	for i := 0; i < 20; i++ {
+		d := p.Delay(2)
+		assert.GreaterOrEqual(t, d, 8*time.Second)
+		assert.LessOrEqual(t, d, 12*time.Second)
+	}
+
+	merged := p.Merge(RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second})
+	assert.Equal(t, RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second, Multiplier: 2, Jitter: 0.2}, merged)
+}
+
+type hintedError struct{ policy RetryPolicy }
+
+func (e *hintedError) Error() string            { return "nonce too low" }
+func (e *hintedError) RetryPolicy() RetryPolicy { return e.policy }
+
+func TestHandleFailureMovesToDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	t.Run("retries until policy is exhausted", func(t *testing.T) {
+		job := &Job{ID: "job-1", BatchID: "batch-1"}
+		require.NoError(t, c.Push(ctx, job))
+
+		c.handleFailure(ctx, job, deliver(t, c), errors.New("rpc timeout"))
+		n, _ := c.GetQueueLength(ctx)
+		assert.Equal(t, int64(1), n)
+
+		c.handleFailure(ctx, job, deliver(t, c), errors.New("rpc timeout"))
+		dlq, _ := c.GetDeadLetterCount(ctx)
+		assert.Equal(t, int64(1), dlq)
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.Zero(t, processing)
+	})
+
+	t.Run("errors carry their own retry policy", func(t *testing.T) {
+		job := &Job{ID: "job-3", BatchID: "batch-3"}
+		require.NoError(t, c.Push(ctx, job))
+		hinted := fmt.Errorf("failed to send transaction: %w", &hintedError{policy: RetryPolicy{MaxRetries: 3}})
+
+		for i := 0; i < 2; i++ {
+			c.handleFailure(ctx, job, deliver(t, c), hinted)
+		}
+		n, _ := c.GetQueueLength(ctx)
+		assert.Equal(t, int64(1), n, "default policy would have given up after 2 attempts")
+		c.handleFailure(ctx, job, deliver(t, c), hinted)
+		_, total, err := c.ListDeadLetters(ctx, "batch-3", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+	})
+
+	t.Run("permanent errors skip retries", func(t *testing.T) {
+		job := &Job{ID: "job-2", BatchID: "batch-2"}
+		require.NoError(t, c.Push(ctx, job))
+		c.handleFailure(ctx, job, deliver(t, c), Permanent(errors.New("token not allowlisted")))
+
+		entries, total, err := c.ListDeadLetters(ctx, "batch-2", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.True(t, entries[0].Permanent)
+		assert.Equal(t, 1, entries[0].Attempts)
+		assert.Equal(t, "token not allowlisted", entries[0].Error)
+	})
+}
+
+func TestRequeueDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	require.NoError(t, c.moveToDeadLetter(ctx, &Job{ID: "job-1", BatchID: "batch-1", RetryCount: 3}, errors.New("reverted")))
+	// 旧格式: 直接存储 Job
+	legacy, _ := json.Marshal(&Job{ID: "job-legacy", BatchID: "batch-1", RetryCount: 3})
+	c.redis.LPush(ctx, PayoutDeadLetterKey, legacy)
+
+	entries, total, err := c.ListDeadLetters(ctx, "batch-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "job-legacy", entries[0].Job.ID)
+
+	job, err := c.RequeueDeadLetter(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, job.RetryCount)
+
+	n, _ := c.GetQueueLength(ctx)
+	assert.Equal(t, int64(1), n)
+	dlq, _ := c.GetDeadLetterCount(ctx)
+	assert.Equal(t, int64(1), dlq)
+
+	_, err = c.RequeueDeadLetter(ctx, "job-1")
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+}
+
+func TestOverrideDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	blocked := Permanent(&codedError{code: "POLICY_VIOLATION"})
+	require.NoError(t, c.moveToDeadLetter(ctx, &Job{ID: "job-1", BatchID: "batch-1"}, blocked))
+	require.NoError(t, c.moveToDeadLetter(ctx, &Job{ID: "job-2", BatchID: "batch-1"}, errors.New("reverted")))
+
+	entries, _, err := c.ListDeadLetters(ctx, "batch-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "POLICY_VIOLATION", entries[1].ErrorCode)
+
+	_, err = c.OverrideDeadLetter(ctx, "job-2", "POLICY_VIOLATION", PolicyOverride{ApprovedBy: "ops"})
+	assert.ErrorIs(t, err, ErrOverrideNotApplicable)
+
+	job, err := c.OverrideDeadLetter(ctx, "job-1", "POLICY_VIOLATION", PolicyOverride{ApprovedBy: "ops", Reason: "quarterly payroll"})
+	require.NoError(t, err)
+	require.NotNil(t, job.PolicyOverride)
+	assert.Equal(t, "ops", job.PolicyOverride.ApprovedBy)
+
+	d := deliver(t, c)
+	require.NotNil(t, d)
+	assert.Contains(t, d.raw, "quarterly payroll")
+}
+
+func TestApproveRecipientDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	require.NoError(t, c.moveToDeadLetter(ctx, &Job{ID: "job-1", BatchID: "batch-1"}, Permanent(&codedError{code: "RECIPIENT_REVIEW_REQUIRED"})))
+	require.NoError(t, c.moveToDeadLetter(ctx, &Job{ID: "job-2", BatchID: "batch-1"}, Permanent(&codedError{code: "POLICY_VIOLATION"})))
+
+	_, err := c.ApproveRecipientDeadLetter(ctx, "job-2", "RECIPIENT_REVIEW_REQUIRED", PolicyOverride{ApprovedBy: "ops"})
+	assert.ErrorIs(t, err, ErrOverrideNotApplicable)
+
+	job, err := c.ApproveRecipientDeadLetter(ctx, "job-1", "RECIPIENT_REVIEW_REQUIRED", PolicyOverride{ApprovedBy: "ops", Reason: "confirmed by phone"})
+	require.NoError(t, err)
+	require.NotNil(t, job.RecipientApproval)
+	assert.Nil(t, job.PolicyOverride)
+	assert.Equal(t, "confirmed by phone", job.RecipientApproval.Reason)
+}
+
+type codedError struct{ code string }
+
+func (e *codedError) Error() string     { return "blocked" }
+func (e *codedError) ErrorCode() string { return e.code }
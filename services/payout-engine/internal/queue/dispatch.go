@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 并发链: worker 取出任务后交给独立协程处理，不等待上链确认即继续取下一个任务。
+// 每条链同时处理的任务数有上限，同一付款地址的任务按出队顺序发送 (见 AwaitSendTurn)。
+
+// SetChainConcurrency 该链的任务并发处理，最多同时 limit 个 (须在 Start 之前调用，limit <= 0 时逐个处理)
+func (c *Consumer) SetChainConcurrency(chainID uint64, limit int) {
+	if c.chainSlots == nil {
+		c.chainSlots = make(map[uint64]chan struct{})
+	}
+	if limit <= 0 {
+		delete(c.chainSlots, chainID)
+		return
+	}
+	c.chainSlots[chainID] = make(chan struct{}, limit)
+}
+
+// ChainInFlight 该链正在并发处理的任务数 (非并发链返回 0)
+func (c *Consumer) ChainInFlight(chainID uint64) int {
+	return len(c.chainSlots[chainID])
+}
+
+// dispatch 并发链的任务在独立协程中处理，返回 false 时由 worker 直接处理
+func (c *Consumer) dispatch(ctx context.Context, workerID int, consumer string, d *delivery, processFn ProcessFunc) bool {
+	if len(c.chainSlots) == 0 {
+		return false
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(d.raw), &job); err != nil {
+		return false
+	}
+	slots, ok := c.chainSlots[job.ChainID]
+	if !ok {
+		return false
+	}
+
+	// 占用名额 (已满时 worker 在此等待)，并按出队顺序排入付款地址的发送队列
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return true
+	}
+	turn := c.turns.take(fmt.Sprintf("%d:%s", job.ChainID, strings.ToLower(job.Source())))
+
+	leaseCtx, stop := c.keepLease(ctx, consumer, d)
+	go func() {
+		defer func() {
+			stop()
+			turn.release()
+			<-slots
+		}()
+		c.process(context.WithValue(leaseCtx, sendTurnKey{}, turn), workerID, d, processFn)
+	}()
+	return true
+}
+
+type sendTurnKey struct{}
+
+// AwaitSendTurn 等待同一付款地址先出队的任务发送完毕，返回的函数在本任务广播后调用
+// (任务结束时也会自动调用)。非并发处理的任务立即返回。
+func AwaitSendTurn(ctx context.Context) (func(), error) {
+	turn, ok := ctx.Value(sendTurnKey{}).(*sendTurn)
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case <-turn.prev:
+		return turn.release, nil
+	case <-ctx.Done():
+		return turn.release, ctx.Err()
+	}
+}
+
+// sendTurns 按付款地址排队的发送顺序: 每个任务等待前一个任务的 done 关闭
+type sendTurns struct {
+	mu    sync.Mutex
+	tails map[string]*sendTurn
+}
+
+type sendTurn struct {
+	owner *sendTurns
+	key   string
+	prev  <-chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// closedTurn 队列为空时的前驱
+var closedTurn = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// take 在 key 的队尾排入一个任务
+func (q *sendTurns) take(key string) *sendTurn {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.tails == nil {
+		q.tails = make(map[string]*sendTurn)
+	}
+	t := &sendTurn{owner: q, key: key, prev: closedTurn, done: make(chan struct{})}
+	if tail, ok := q.tails[key]; ok {
+		t.prev = tail.done
+	}
+	q.tails[key] = t
+	return t
+}
+
+// release 放行下一个任务，可重复调用。未等到发送顺序即结束的任务 (如批次已取消)
+// 在前一个任务发送后才放行，保证后续任务仍按顺序发送。
+func (t *sendTurn) release() {
+	t.once.Do(func() {
+		select {
+		case <-t.prev:
+			t.pass()
+		default:
+			go func() {
+				<-t.prev
+				t.pass()
+			}()
+		}
+	})
+}
+
+func (t *sendTurn) pass() {
+	close(t.done)
+	t.owner.mu.Lock()
+	if t.owner.tails[t.key] == t {
+		delete(t.owner.tails, t.key)
+	}
+	t.owner.mu.Unlock()
+}
@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passed(t *sendTurn) bool {
+	select {
+	case <-t.prev:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSendTurns(t *testing.T) {
+	var q sendTurns
+	a1, a2, a3 := q.take("728126428:ta"), q.take("728126428:ta"), q.take("728126428:ta")
+	b1 := q.take("728126428:tb")
+
+	assert.True(t, passed(a1))
+	assert.False(t, passed(a2))
+	assert.True(t, passed(b1), "other accounts don't wait")
+
+	// a2 结束时未轮到发送，a3 仍须等 a1 发送完
+	a2.release()
+	assert.False(t, passed(a3))
+
+	a1.release()
+	a1.release()
+	assert.True(t, passed(a2))
+	assert.Eventually(t, func() bool { return passed(a3) }, time.Second, time.Millisecond)
+
+	a3.release()
+	b1.release()
+	assert.Empty(t, q.tails)
+
+	release, err := AwaitSendTurn(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestChainConcurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newTestConsumer(t)
+	c.SetChainConcurrency(728126428, 2)
+
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 728126428, FromAddress: "TA"},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", ChainID: 728126428, FromAddress: "ta"},
+		{ID: "job-3", BatchID: "batch-1", UserID: "user-1", ChainID: 728126428, FromAddress: "TB"},
+	}))
+
+	// 广播后等待确认: 单个 worker 同时处理两个任务，同一地址按出队顺序发送
+	var mu sync.Mutex
+	var sent []string
+	confirm := make(chan struct{})
+	c.Start(ctx, func(ctx context.Context, job *Job) (*JobResult, error) {
+		release, err := AwaitSendTurn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		sent = append(sent, job.ID)
+		mu.Unlock()
+		release()
+		<-confirm
+		return &JobResult{JobID: job.ID, Success: true, TxHash: "tx-" + job.ID}, nil
+	})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"job-1", "job-2"}, sent)
+	assert.Equal(t, 2, c.ChainInFlight(728126428))
+
+	// 名额已满: 第三个任务等前面的任务确认后处理
+	close(confirm)
+	assert.Eventually(t, func() bool {
+		status, err := c.GetJobStatus(ctx, BatchRef{UserID: "user-1", BatchID: "batch-1"}, "job-3")
+		return err == nil && status.State == JobStateConfirmed
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"job-1", "job-2", "job-3"}, sent)
+}
@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PayoutGasHeldKeyPrefix list: 基础费超过链的上限、等待回落的任务
+const PayoutGasHeldKeyPrefix = "payout:gas:held:"
+
+func gasHeldKey(chainID uint64) string {
+	return PayoutGasHeldKeyPrefix + strconv.FormatUint(chainID, 10)
+}
+
+// GasHoldError 网络费暂时过高，任务暂存到费用回落后再处理 (不计入重试次数)
+type GasHoldError struct {
+	Err error
+}
+
+func (e *GasHoldError) Error() string { return e.Err.Error() }
+func (e *GasHoldError) Unwrap() error { return e.Err }
+
+// GasHold 将错误标记为等待网络费回落
+func GasHold(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &GasHoldError{Err: err}
+}
+
+// IsGasHold 判断错误是否为等待网络费回落
+func IsGasHold(err error) bool {
+	var h *GasHoldError
+	return errors.As(err, &h)
+}
+
+// holdForGas 将任务移入该链的等待列表并确认条目，失败时返回 false (由调用方按普通失败处理)
+func (c *Consumer) holdForGas(ctx context.Context, job *Job, d *delivery, cause error) bool {
+	pipe := c.redis.TxPipeline()
+	pipe.LPush(ctx, gasHeldKey(job.ChainID), d.raw)
+	c.ack(ctx, pipe, d)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to hold job for gas price")
+		return false
+	}
+	log.Info().Str("job_id", job.ID).Uint64("chain_id", job.ChainID).Err(cause).Msg("Gas price above ceiling, holding job")
+	c.updateState(ctx, job, JobStateRetrying, "", cause)
+	return true
+}
+
+// GasHeldCount 该链等待网络费回落的任务数
+func (c *Consumer) GasHeldCount(ctx context.Context, chainID uint64) (int64, error) {
+	return c.redis.LLen(ctx, gasHeldKey(chainID)).Result()
+}
+
+// ReleaseGasHeld 将该链等待网络费回落的任务按原优先级放回队列，返回放回的任务数
+func (c *Consumer) ReleaseGasHeld(ctx context.Context, chainID uint64) (int, error) {
+	released, err := c.drainList(ctx, gasHeldKey(chainID), false)
+	if released > 0 {
+		log.Info().Uint64("chain_id", chainID).Int("released", released).Msg("Gas price back under ceiling, releasing held jobs")
+	}
+	return released, err
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasHold(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	require.NoError(t, c.PushBatch(ctx, []*Job{{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1}}))
+
+	c.process(ctx, 0, deliver(t, c), func(context.Context, *Job) (*JobResult, error) {
+		return nil, GasHold(errors.New("base fee above ceiling"))
+	})
+
+	held, err := c.GasHeldCount(ctx, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, held)
+	processing, _ := c.GetProcessingCount(ctx)
+	assert.Zero(t, processing)
+	status, _ := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+	assert.Equal(t, JobStateRetrying, status.State)
+	assert.Zero(t, status.RetryCount, "waiting for gas is not a retry")
+
+	released, err := c.ReleaseGasHeld(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	held, _ = c.GasHeldCount(ctx, 1)
+	assert.Zero(t, held)
+
+	sent := 0
+	c.process(ctx, 0, deliver(t, c), func(ctx context.Context, job *Job) (*JobResult, error) {
+		sent++
+		return &JobResult{JobID: job.ID, Success: true, TxHash: "0xabc"}, nil
+	})
+	assert.Equal(t, 1, sent)
+	status, _ = c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+	assert.Equal(t, JobStateConfirmed, status.State)
+}
@@ -0,0 +1,17 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PayoutGasTankKeyPrefix 运营地址补充标记 (string: chain_id:address)，过期前不再补充该地址
+const PayoutGasTankKeyPrefix = "payout:gastank:"
+
+// ClaimGasTankTopUp 获取地址的补充权。多实例下同一地址只有一个实例补充，
+// 补充后 ttl 内不再补充 (等待到账，失败时避免反复重试)。
+func (c *Consumer) ClaimGasTankTopUp(ctx context.Context, chainID uint64, address string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%d:%s", PayoutGasTankKeyPrefix, chainID, address)
+	return c.redis.SetNX(ctx, key, time.Now().Unix(), ttl).Result()
+}
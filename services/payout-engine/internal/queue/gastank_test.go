@@ -0,0 +1,30 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimGasTankTopUp(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	claimed, err := c.ClaimGasTankTopUp(ctx, 1, "0xabc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// 冷却期内不重复补充，其他链和地址不受影响
+	claimed, err = c.ClaimGasTankTopUp(ctx, 1, "0xabc", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	claimed, err = c.ClaimGasTankTopUp(ctx, 137, "0xabc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = c.ClaimGasTankTopUp(ctx, 1, "0xdef", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+// IdempotencyKeyPrefix 幂等键前缀 (payout:idempotency:<user_id>:<key>)
+const IdempotencyKeyPrefix = "payout:idempotency:"
+
+// DefaultIdempotencyTTL 幂等记录保留时间
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyPendingTTL 处理中占位的保留时间: 处理期间定期续期 (见 KeepIdempotencyClaim)，
+// 进程在入队前崩溃时，客户端可在此之后重试
+const idempotencyPendingTTL = 5 * time.Minute
+
+// IdempotencyRecord 幂等键对应的请求指纹和原始响应
+type IdempotencyRecord struct {
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response,omitempty"` // 为空表示请求仍在处理中
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ErrIdempotencyInProgress 相同幂等键的请求正在处理
+var ErrIdempotencyInProgress = errors.New("a request with this idempotency key is already in progress")
+
+func idempotencyKey(userID, key string) string {
+	return fmt.Sprintf("%s%s:%s", IdempotencyKeyPrefix, userID, key)
+}
+
+// ClaimIdempotencyKey 占用幂等键。首次占用返回 (nil, nil)；
+// 已有记录时返回该记录，由调用方比较请求指纹并返回原始响应。
+func (c *Consumer) ClaimIdempotencyKey(ctx context.Context, userID, key, requestHash string) (*IdempotencyRecord, error) {
+	placeholder, err := json.Marshal(IdempotencyRecord{RequestHash: requestHash, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	redisKey := idempotencyKey(userID, key)
+	claimed, err := c.redis.SetNX(ctx, redisKey, placeholder, idempotencyPendingTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	data, err := c.redis.Get(ctx, redisKey).Result()
+	if err == redis.Nil {
+		// 记录恰好过期，重新占用
+		return c.ClaimIdempotencyKey(ctx, userID, key, requestHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record IdempotencyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("corrupt idempotency record: %w", err)
+	}
+	return &record, nil
+}
+
+// CompleteIdempotencyKey 保存原始响应，后续重试直接返回
+func (c *Consumer) CompleteIdempotencyKey(ctx context.Context, userID, key, requestHash string, response interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	resp, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(IdempotencyRecord{RequestHash: requestHash, Response: resp, CreatedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, idempotencyKey(userID, key), data, ttl).Err()
+}
+
+// renewClaimScript 占位仍属于该请求 (尚无响应且指纹为 ARGV[1]) 时续期 ARGV[2] 毫秒
+var renewClaimScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return 0
+end
+local record = cjson.decode(data)
+if record.response ~= nil or record.request_hash ~= ARGV[1] then
+	return 0
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// renewIdempotencyClaim 续期处理中的占位，返回 false 表示占位已完成、释放或过期
+func (c *Consumer) renewIdempotencyClaim(ctx context.Context, userID, key, requestHash string) (bool, error) {
+	n, err := renewClaimScript.Run(ctx, c.redis, []string{idempotencyKey(userID, key)},
+		requestHash, idempotencyPendingTTL.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// KeepIdempotencyClaim 请求处理期间定期续期占位，避免耗时的提交 (如签名审批) 期间过期后
+// 被重复提交。返回停止续期的函数，在完成或释放幂等键之前调用。
+func (c *Consumer) KeepIdempotencyClaim(ctx context.Context, userID, key, requestHash string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(idempotencyPendingTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := c.renewIdempotencyClaim(ctx, userID, key, requestHash)
+				if err != nil {
+					log.Warn().Err(err).Str("user_id", userID).Str("idempotency_key", key).Msg("Failed to renew idempotency claim")
+					continue
+				}
+				if !held {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// ReleaseIdempotencyKey 请求失败 (未入队) 时释放幂等键，允许客户端重试
+func (c *Consumer) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	return c.redis.Del(ctx, idempotencyKey(userID, key)).Err()
+}
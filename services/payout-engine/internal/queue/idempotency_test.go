@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	record, err := c.ClaimIdempotencyKey(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	assert.Nil(t, record, "first claim wins")
+
+	record, err = c.ClaimIdempotencyKey(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Empty(t, record.Response, "still in progress")
+
+	// 不同用户的相同键互不影响
+	record, err = c.ClaimIdempotencyKey(ctx, "user-2", "batch-1", "hash-a")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	require.NoError(t, c.CompleteIdempotencyKey(ctx, "user-1", "batch-1", "hash-a", map[string]string{"BatchID": "batch-1"}, 0))
+	record, err = c.ClaimIdempotencyKey(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "hash-a", record.RequestHash)
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(record.Response, &resp))
+	assert.Equal(t, "batch-1", resp["BatchID"])
+
+	require.NoError(t, c.ReleaseIdempotencyKey(ctx, "user-2", "batch-1"))
+	record, err = c.ClaimIdempotencyKey(ctx, "user-2", "batch-1", "hash-b")
+	require.NoError(t, err)
+	assert.Nil(t, record, "released key can be claimed again")
+}
+
+func TestKeepIdempotencyClaim(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestConsumerWithServer(t)
+
+	record, err := c.ClaimIdempotencyKey(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	// 提交超过占位的保留时间时，续期使占位一直有效
+	for i := 0; i < 3; i++ {
+		mr.FastForward(idempotencyPendingTTL / 2)
+		held, err := c.renewIdempotencyClaim(ctx, "user-1", "batch-1", "hash-a")
+		require.NoError(t, err)
+		assert.True(t, held)
+	}
+	record, err = c.ClaimIdempotencyKey(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	require.NotNil(t, record, "claim still held")
+
+	held, err := c.renewIdempotencyClaim(ctx, "user-1", "batch-1", "hash-b")
+	require.NoError(t, err)
+	assert.False(t, held, "another request's claim is not renewed")
+
+	require.NoError(t, c.CompleteIdempotencyKey(ctx, "user-1", "batch-1", "hash-a", map[string]string{"BatchID": "batch-1"}, 0))
+	held, err = c.renewIdempotencyClaim(ctx, "user-1", "batch-1", "hash-a")
+	require.NoError(t, err)
+	assert.False(t, held, "completed")
+	assert.Equal(t, DefaultIdempotencyTTL, mr.TTL(idempotencyKey("user-1", "batch-1")), "completed record keeps its TTL")
+}
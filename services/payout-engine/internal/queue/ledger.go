@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+// 账本 outbox: 状态变更先写入 Redis，由 ledger writer 批量落库 Postgres
+const (
+	PayoutLedgerEntriesKey = "payout:ledger:entries" // hash: entry_id -> LedgerEntry
+	PayoutLedgerOutboxKey  = "payout:ledger:outbox"  // zset: entry_id -> 可写入时间 (unix ms)
+)
+
+// ledgerLease 写入中的记录在 outbox 中顺延的时间，写库失败或实例崩溃后重新写入
+const ledgerLease = time.Minute
+
+// LedgerEntry 一次任务状态变更 (或上链后的网络费更新) 的快照
+type LedgerEntry struct {
+	ID         string    `json:"id"`
+	Status     JobStatus `json:"status"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// EnableLedger 开启账本记录 (须在 Start 之前调用)，未开启时不写 outbox
+func (c *Consumer) EnableLedger() {
+	c.ledger = true
+}
+
+// queueLedger 在 pipeline 中写入账本记录
+func (c *Consumer) queueLedger(ctx context.Context, pipe redis.Pipeliner, status *JobStatus) {
+	if !c.ledger {
+		return
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Warn().Err(err).Str("job_id", status.ID).Msg("Failed to generate ledger entry id")
+		return
+	}
+	entry := LedgerEntry{ID: hex.EncodeToString(id), Status: *status, RecordedAt: time.Now()}
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", status.ID).Msg("Failed to marshal ledger entry")
+		return
+	}
+	pipe.HSet(ctx, PayoutLedgerEntriesKey, entry.ID, data)
+	pipe.ZAdd(ctx, PayoutLedgerOutboxKey, &redis.Z{Score: float64(entry.RecordedAt.UnixMilli()), Member: entry.ID})
+}
+
+// ClaimLedgerEntries 取出待落库的记录 (按记录时间)，并以租期重新排期，落库成功后由 AckLedgerEntries 删除
+func (c *Consumer) ClaimLedgerEntries(ctx context.Context, now time.Time, limit int64) ([]*LedgerEntry, error) {
+	ids, err := c.redis.ZRangeByScore(ctx, PayoutLedgerOutboxKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*LedgerEntry
+	for _, id := range ids {
+		removed, err := c.redis.ZRem(ctx, PayoutLedgerOutboxKey, id).Result()
+		if err != nil || removed == 0 {
+			continue // 已被其他实例取走
+		}
+		c.redis.ZAdd(ctx, PayoutLedgerOutboxKey, &redis.Z{Score: float64(now.Add(ledgerLease).UnixMilli()), Member: id})
+		data, err := c.redis.HGet(ctx, PayoutLedgerEntriesKey, id).Result()
+		if err != nil {
+			c.redis.ZRem(ctx, PayoutLedgerOutboxKey, id)
+			continue
+		}
+		var entry LedgerEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			c.AckLedgerEntries(ctx, []string{id})
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	// outbox 分数为毫秒，同一毫秒内按记录时间排序
+	sort.Slice(entries, func(i, j int) bool { return entries[i].RecordedAt.Before(entries[j].RecordedAt) })
+	return entries, nil
+}
+
+// AckLedgerEntries 落库成功后删除记录
+func (c *Consumer) AckLedgerEntries(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	pipe := c.redis.TxPipeline()
+	pipe.ZRem(ctx, PayoutLedgerOutboxKey, members...)
+	pipe.HDel(ctx, PayoutLedgerEntriesKey, ids...)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LedgerBacklog 待落库记录数
+func (c *Consumer) LedgerBacklog(ctx context.Context) (int64, error) {
+	return c.redis.ZCard(ctx, PayoutLedgerOutboxKey).Result()
+}
@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerOutbox(t *testing.T) {
+	ctx := context.Background()
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := newTestConsumer(t)
+		require.NoError(t, c.PushBatch(ctx, []*Job{job}))
+		n, err := c.LedgerBacklog(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("records every state change", func(t *testing.T) {
+		c := newTestConsumer(t)
+		c.EnableLedger()
+		c.SetRetryPolicy(RetryPolicy{MaxRetries: 3})
+		j := *job
+		require.NoError(t, c.PushBatch(ctx, []*Job{&j}))
+
+		c.handleFailure(ctx, &j, deliver(t, c), errors.New("rpc timeout"))
+		c.handleSuccess(ctx, &j, deliver(t, c), "0xabc")
+		require.NoError(t, c.RecordGasFee(ctx, BatchRef{UserID: "user-1", BatchID: "batch-1"}, "job-1", GasCost{Fee: "21000", GasUsed: 21000, GasPrice: "1"}))
+
+		entries, err := c.ClaimLedgerEntries(ctx, time.Now(), 100)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		var states []JobState
+		for _, e := range entries {
+			states = append(states, e.Status.State)
+		}
+		assert.Equal(t, []JobState{JobStatePending, JobStateRetrying, JobStateConfirmed, JobStateConfirmed}, states)
+		assert.Equal(t, "rpc timeout", entries[1].Status.Error)
+		assert.Equal(t, 1, entries[1].Status.RetryCount)
+		assert.Equal(t, "0xabc", entries[3].Status.TxHash)
+		assert.Equal(t, "21000", entries[3].Status.GasFee)
+		assert.EqualValues(t, 21000, entries[3].Status.GasUsed)
+		assert.Equal(t, "1", entries[3].Status.GasPrice)
+
+		// 租期内不会被再次取出
+		again, err := c.ClaimLedgerEntries(ctx, time.Now(), 100)
+		require.NoError(t, err)
+		assert.Empty(t, again)
+
+		// 未确认的记录在租期结束后重新取出
+		again, err = c.ClaimLedgerEntries(ctx, time.Now().Add(2*ledgerLease), 100)
+		require.NoError(t, err)
+		assert.Len(t, again, 4)
+
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			ids[i] = e.ID
+		}
+		require.NoError(t, c.AckLedgerEntries(ctx, ids))
+		n, err := c.LedgerBacklog(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
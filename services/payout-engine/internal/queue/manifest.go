@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PayoutManifestKeyPrefix 批次任务清单 (payout:manifest:<user>:<batch>)
+const PayoutManifestKeyPrefix = "payout:manifest:"
+
+// ManifestVersion 清单规范版本，哈希算法或字段变化时递增
+const ManifestVersion = "v1"
+
+// Manifest 入队前生成的批次任务清单。
+// Hash = sha256(JSON(ManifestBody))，字段顺序固定、items 按 id 排序，双方可独立计算并比对。
+type Manifest struct {
+	ManifestBody
+	Hash      string    `json:"hash"`                // 0x 前缀 hex
+	Signature string    `json:"signature,omitempty"` // EIP-191 personal_sign(hash bytes)，可用 Signer 地址验证
+	Signer    string    `json:"signer,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ManifestBody 参与哈希的字段
+type ManifestBody struct {
+	Version      string         `json:"version"`
+	BatchID      string         `json:"batch_id"`
+	UserID       string         `json:"user_id"`
+	ChainID      uint64         `json:"chain_id"`
+	FromAddress  string         `json:"from_address"`
+	SmartAccount bool           `json:"smart_account"`
+	Items        []ManifestItem `json:"items"`
+}
+
+// ManifestItem 单个任务的执行参数
+type ManifestItem struct {
+	ID            string `json:"id"`
+	ToAddress     string `json:"to_address"`
+	Amount        string `json:"amount"` // 实际转出金额 (收款方承担手续费时为净额)
+	TokenAddress  string `json:"token_address"`
+	TokenDecimals uint32 `json:"token_decimals"`
+	TokenID       string `json:"token_id,omitempty"`
+	Source        string `json:"source,omitempty"` // 代付的源钱包或金库合约 (transferFrom)
+	FeeMode       string `json:"fee_mode,omitempty"`
+	GrossAmount   string `json:"gross_amount,omitempty"`
+	NetworkFee    string `json:"network_fee,omitempty"`
+	ServiceFee    string `json:"service_fee,omitempty"`
+}
+
+// NewManifestBody 由待入队任务构造清单 (同一批次的任务)
+func NewManifestBody(jobs []*Job) ManifestBody {
+	body := ManifestBody{Version: ManifestVersion, Items: make([]ManifestItem, 0, len(jobs))}
+	for _, job := range jobs {
+		body.BatchID, body.UserID, body.ChainID = job.BatchID, job.UserID, job.ChainID
+		body.FromAddress, body.SmartAccount = job.FromAddress, job.SmartAccount
+		var source string
+		if job.Permit != nil || job.Treasury != "" {
+			source = job.Source()
+		}
+		body.Items = append(body.Items, ManifestItem{
+			ID:            job.ID,
+			ToAddress:     job.ToAddress,
+			Amount:        job.Amount,
+			TokenAddress:  job.TokenAddress,
+			TokenDecimals: job.TokenDecimals,
+			TokenID:       job.TokenID,
+			Source:        source,
+			FeeMode:       job.FeeMode,
+			GrossAmount:   job.GrossAmount,
+			NetworkFee:    job.NetworkFee,
+			ServiceFee:    job.ServiceFee,
+		})
+	}
+	sort.Slice(body.Items, func(i, j int) bool { return body.Items[i].ID < body.Items[j].ID })
+	return body
+}
+
+// Digest 计算清单哈希
+func (b ManifestBody) Digest() ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+func manifestKey(userID, batchID string) string {
+	return PayoutManifestKeyPrefix + userID + ":" + batchID
+}
+
+// SaveManifest 保存批次清单 (与批次状态相同保留期)
+func (c *Consumer) SaveManifest(ctx context.Context, m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return c.redis.Set(ctx, manifestKey(m.UserID, m.BatchID), data, BatchStatusTTL).Err()
+}
+
+// GetManifest 返回批次清单，不存在时返回 nil
+func (c *Consumer) GetManifest(ctx context.Context, userID, batchID string) (*Manifest, error) {
+	data, err := c.redis.Get(ctx, manifestKey(userID, batchID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, fmt.Errorf("corrupt manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// manifestHash 批次清单哈希 (无清单时为空)
+func (c *Consumer) manifestHash(ctx context.Context, userID, batchID string) string {
+	m, err := c.GetManifest(ctx, userID, batchID)
+	if err != nil || m == nil {
+		return ""
+	}
+	return m.Hash
+}
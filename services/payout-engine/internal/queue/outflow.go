@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PayoutOutflowKeyPrefix 每日已完成支付的流出统计 (hash: "<chain>:<from>:<token>" -> amount)
+const PayoutOutflowKeyPrefix = "payout:outflow:"
+
+// outflowRetention 流出统计保留天数 (runway 按最近 7 天平均计算)
+const outflowRetention = 8 * 24 * time.Hour
+
+// OutflowKey 流出统计的维度
+type OutflowKey struct {
+	ChainID     uint64
+	FromAddress string
+	Token       string // 原生代币为 ""
+}
+
+func (k OutflowKey) field() string {
+	return fmt.Sprintf("%d:%s:%s", k.ChainID, normalizeAddress(k.FromAddress), normalizeAddress(k.Token))
+}
+
+// normalizeAddress EVM 地址小写化，TRON Base58 地址区分大小写保持原样
+func normalizeAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+func parseOutflowField(field string) (OutflowKey, bool) {
+	parts := strings.SplitN(field, ":", 3)
+	if len(parts) != 3 {
+		return OutflowKey{}, false
+	}
+	chainID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return OutflowKey{}, false
+	}
+	return OutflowKey{ChainID: chainID, FromAddress: parts[1], Token: parts[2]}, true
+}
+
+func outflowDayKey(t time.Time) string {
+	return PayoutOutflowKeyPrefix + t.UTC().Format("20060102")
+}
+
+// recordOutflow 记录已完成支付的金额。
+// 统计仅用于余额预测，使用浮点累加 (HINCRBYFLOAT) 可接受精度损失。
+func (c *Consumer) recordOutflow(ctx context.Context, job *Job) error {
+	amount, err := strconv.ParseFloat(job.Amount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q: %w", job.Amount, err)
+	}
+	key := outflowDayKey(time.Now())
+	field := OutflowKey{ChainID: job.ChainID, FromAddress: job.Source(), Token: job.Asset()}.field()
+
+	pipe := c.redis.Pipeline()
+	pipe.HIncrByFloat(ctx, key, field, amount)
+	pipe.Expire(ctx, key, outflowRetention)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// OutflowTotals 返回最近 days 天 (含今天) 的流出总额
+func (c *Consumer) OutflowTotals(ctx context.Context, days int) (map[OutflowKey]float64, error) {
+	totals := make(map[OutflowKey]float64)
+	now := time.Now()
+	for d := 0; d < days; d++ {
+		entries, err := c.redis.HGetAll(ctx, outflowDayKey(now.AddDate(0, 0, -d))).Result()
+		if err != nil {
+			return nil, err
+		}
+		for field, value := range entries {
+			key, ok := parseOutflowField(field)
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			totals[key] += v
+		}
+	}
+	return totals, nil
+}
+
+// PendingJobs 返回排队中和处理中的任务 (尚未完成的支出承诺；Stream 中的条目在确认后才删除)
+func (c *Consumer) PendingJobs(ctx context.Context) ([]*Job, error) {
+	var jobs []*Job
+	for _, key := range priorityLanes {
+		msgs, err := c.redis.XRange(ctx, key, "-", "+").Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			raw, _ := msg.Values[streamJobField].(string)
+			var job Job
+			if err := json.Unmarshal([]byte(raw), &job); err != nil {
+				continue
+			}
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutflowTotals(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	usdt := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	jobs := []*Job{
+		{ID: "job-1", ChainID: 1, FromAddress: "0xAbC", Amount: "100"},
+		{ID: "job-2", ChainID: 1, FromAddress: "0xabc", Amount: "50"},
+		{ID: "job-3", ChainID: 728126428, FromAddress: "TXYZ", TokenAddress: usdt, Amount: "7"},
+	}
+	for _, job := range jobs {
+		require.NoError(t, c.recordOutflow(ctx, job))
+	}
+
+	totals, err := c.OutflowTotals(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, totals[OutflowKey{ChainID: 1, FromAddress: "0xabc"}])
+	// TRON Base58 地址保持大小写
+	assert.Equal(t, 7.0, totals[OutflowKey{ChainID: 728126428, FromAddress: "TXYZ", Token: usdt}])
+}
+
+func TestPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	// job-1 已被 worker 读取 (未确认)，job-2 仍在排队
+	require.NoError(t, c.Push(ctx, &Job{ID: "job-1"}))
+	require.NoError(t, c.Push(ctx, &Job{ID: "job-2"}))
+	require.NotNil(t, deliver(t, c))
+
+	jobs, err := c.PendingJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "job-1", jobs[0].ID)
+	assert.Equal(t, "job-2", jobs[1].ID)
+}
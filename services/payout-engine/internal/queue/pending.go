@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PayoutPendingTxKey 已广播但未确认的交易 (hash: PendingTx.Key() -> PendingTx)
+const PayoutPendingTxKey = "payout:pending_tx"
+
+// PendingTx 已广播待确认的 EVM 交易，用于卡单检测和替换 (replace-by-fee)
+type PendingTx struct {
+	JobID        string    `json:"job_id"`
+	BatchID      string    `json:"batch_id"`
+	UserID       string    `json:"user_id,omitempty"`
+	ChainID      uint64    `json:"chain_id"`
+	FromAddress  string    `json:"from_address"`
+	Nonce        uint64    `json:"nonce"`
+	TxHash       string    `json:"tx_hash"`
+	PrevHashes   []string  `json:"prev_hashes,omitempty"` // 被替换的旧交易哈希 (仍可能上链)
+	RawTx        string    `json:"raw_tx"`                // 当前交易的 RLP 编码 (hex)
+	SentAt       time.Time `json:"sent_at"`
+	Replacements int       `json:"replacements"`
+	Unwrap       bool      `json:"unwrap,omitempty"` // 任务前置的包装代币解包交易
+	Permit       bool      `json:"permit,omitempty"` // 任务前置的 EIP-2612 授权交易
+	Swap         string    `json:"swap,omitempty"`   // 任务前置的兑换步骤 (SwapStepApprove / SwapStepSwap)
+	Bridge       string    `json:"bridge,omitempty"` // 任务前置的跨链步骤 (BridgeStepApprove / BridgeStepBurn / BridgeStepMint)
+	// 任务的网络费上限 (见 Job.MaxFee)，替换交易不超过该值
+	MaxFee string `json:"max_fee,omitempty"`
+	// 经私有交易池广播，到该时间仍未上链则改为公共交易池广播 (nil 表示已公开)
+	PrivateUntil *time.Time `json:"private_until,omitempty"`
+	// RawTx 的编码格式 (见 config.ChainConfig.TxFormat)，为空时为标准以太坊交易
+	TxFormat string `json:"tx_format,omitempty"`
+}
+
+// 兑换前置交易的步骤 (见 PendingTx.Swap)
+const (
+	SwapStepApprove = "approve" // 对聚合器授权卖出代币
+	SwapStepSwap    = "swap"    // 聚合器兑换交易
+)
+
+// Key 待确认交易在哈希表中的字段 (前置交易与任务的转账交易分开记录)
+func (p *PendingTx) Key() string {
+	switch {
+	case p.Unwrap:
+		return p.JobID + ":unwrap"
+	case p.Permit:
+		return p.JobID + ":permit"
+	case p.Swap != "":
+		return p.JobID + ":swap_" + p.Swap
+	case p.Bridge != "":
+		return p.JobID + ":bridge_" + p.Bridge
+	}
+	return p.JobID
+}
+
+// Prerequisite 任务转账前的前置交易 (解包、授权、兑换、跨链)，不决定任务结果
+func (p *PendingTx) Prerequisite() bool {
+	return p.Unwrap || p.Permit || p.Swap != "" || p.Bridge != ""
+}
+
+// TrackPendingTx 记录或更新待确认交易
+func (c *Consumer) TrackPendingTx(ctx context.Context, p *PendingTx) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending tx: %w", err)
+	}
+	return c.redis.HSet(ctx, PayoutPendingTxKey, p.Key(), data).Err()
+}
+
+// ListPendingTxs 列出所有待确认交易
+func (c *Consumer) ListPendingTxs(ctx context.Context) ([]*PendingTx, error) {
+	entries, err := c.redis.HGetAll(ctx, PayoutPendingTxKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]*PendingTx, 0, len(entries))
+	for key, data := range entries {
+		var p PendingTx
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			c.redis.HDel(ctx, PayoutPendingTxKey, key)
+			continue
+		}
+		pending = append(pending, &p)
+	}
+	return pending, nil
+}
+
+// RemovePendingTx 交易确认或放弃后移除
+func (c *Consumer) RemovePendingTx(ctx context.Context, key string) error {
+	return c.redis.HDel(ctx, PayoutPendingTxKey, key).Err()
+}
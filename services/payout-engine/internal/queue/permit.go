@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// permitSubmissionTTL 授权交易广播后，同批次其他任务在此期间不再重复提交
+// (同一付款地址的交易按 nonce 顺序上链，后续 transferFrom 总在授权之后执行)
+const permitSubmissionTTL = 30 * time.Minute
+
+func permitKey(userID, batchID string) string {
+	return fmt.Sprintf("payout:permit:%s:%s", userID, batchID)
+}
+
+// ClaimPermitSubmission 占用批次的授权提交。已有任务提交过时返回 false 和其交易哈希。
+func (c *Consumer) ClaimPermitSubmission(ctx context.Context, ref BatchRef, jobID string) (bool, string, error) {
+	key := permitKey(ref.UserID, ref.BatchID)
+	claimed, err := c.redis.SetNX(ctx, key, "claimed:"+jobID, permitSubmissionTTL).Result()
+	if err != nil || claimed {
+		return claimed, "", err
+	}
+	txHash, err := c.redis.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return c.ClaimPermitSubmission(ctx, ref, jobID) // 恰好过期，重新占用
+	}
+	return false, txHash, err
+}
+
+// RecordPermitSubmission 记录已广播的授权交易
+func (c *Consumer) RecordPermitSubmission(ctx context.Context, ref BatchRef, txHash string) error {
+	return c.redis.Set(ctx, permitKey(ref.UserID, ref.BatchID), txHash, permitSubmissionTTL).Err()
+}
+
+// ReleasePermitSubmission 授权交易未能广播时释放占用，由下一个任务重试
+func (c *Consumer) ReleasePermitSubmission(ctx context.Context, ref BatchRef) error {
+	return c.redis.Del(ctx, permitKey(ref.UserID, ref.BatchID)).Err()
+}
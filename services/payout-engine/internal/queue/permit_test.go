@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermitSubmission(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	claimed, _, err := c.ClaimPermitSubmission(ctx, ref, "job-1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, c.RecordPermitSubmission(ctx, ref, "0xabc"))
+	claimed, txHash, err := c.ClaimPermitSubmission(ctx, ref, "job-2")
+	require.NoError(t, err)
+	assert.False(t, claimed, "already submitted by job-1")
+	assert.Equal(t, "0xabc", txHash)
+
+	// 其他批次互不影响
+	claimed, _, err = c.ClaimPermitSubmission(ctx, BatchRef{UserID: "user-1", BatchID: "batch-2"}, "job-3")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, c.ReleasePermitSubmission(ctx, ref))
+	claimed, _, err = c.ClaimPermitSubmission(ctx, ref, "job-2")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
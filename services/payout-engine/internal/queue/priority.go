@@ -0,0 +1,125 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+)
+
+// Priority lanes: 每个优先级一个 Stream，MEDIUM (及未指定) 使用 PayoutQueueKey
+const (
+	PayoutQueueUrgentKey = "payout:jobs:urgent"
+	PayoutQueueHighKey   = "payout:jobs:high"
+	PayoutQueueLowKey    = "payout:jobs:low"
+)
+
+// priorityLanes 按优先级从高到低
+var priorityLanes = []string{PayoutQueueUrgentKey, PayoutQueueHighKey, PayoutQueueKey, PayoutQueueLowKey}
+
+// laneKey 任务优先级对应的 Stream
+func laneKey(priority string) string {
+	switch strings.ToUpper(priority) {
+	case "URGENT":
+		return PayoutQueueUrgentKey
+	case "HIGH":
+		return PayoutQueueHighKey
+	case "LOW":
+		return PayoutQueueLowKey
+	}
+	return PayoutQueueKey
+}
+
+// rawLaneKey 由任务 JSON 确定所在通道 (无法解析时放入默认通道)
+func rawLaneKey(raw string) string {
+	var job struct {
+		Priority string `json:"priority"`
+	}
+	json.Unmarshal([]byte(raw), &job)
+	return laneKey(job.Priority)
+}
+
+// PriorityPolicy 优先级通道策略
+type PriorityPolicy struct {
+	MaxWait      time.Duration // 低优先级通道最早的任务等待超过该时间时先处理该通道 (0 = 严格按优先级)
+	PollInterval time.Duration // 各通道均为空时的轮询间隔
+}
+
+// DefaultPriorityPolicy 默认策略: 等待超过 10 分钟的低优先级任务插队，空闲时每 250ms 轮询
+var DefaultPriorityPolicy = PriorityPolicy{
+	MaxWait:      10 * time.Minute,
+	PollInterval: 250 * time.Millisecond,
+}
+
+// PriorityPolicyFromConfig 由配置构建策略，未设置的字段使用默认值
+func PriorityPolicyFromConfig(cfg config.PriorityConfig) PriorityPolicy {
+	p := DefaultPriorityPolicy
+	if cfg.MaxWait > 0 {
+		p.MaxWait = cfg.MaxWait
+	}
+	if cfg.PollInterval > 0 {
+		p.PollInterval = cfg.PollInterval
+	}
+	return p
+}
+
+// SetPriorityPolicy 设置优先级通道策略 (须在 Start 之前调用)
+func (c *Consumer) SetPriorityPolicy(p PriorityPolicy) {
+	c.priority = p
+}
+
+// laneOrder 本次取任务的通道顺序: 最早未投递的任务等待超过 MaxWait 的非 URGENT 通道排在最前 (等待最久的在前)，
+// 其余按优先级从高到低。持续有紧急任务时，积压的低优先级任务仍能按 MaxWait 得到处理。
+func (c *Consumer) laneOrder(ctx context.Context, now time.Time) []string {
+	if c.priority.MaxWait <= 0 {
+		return priorityLanes
+	}
+
+	lower := priorityLanes[1:]
+	last, err := c.lastDelivered(ctx, lower)
+	if err != nil {
+		return priorityLanes
+	}
+
+	type starved struct {
+		key  string
+		wait time.Duration
+	}
+	var aged []starved
+	for _, key := range lower {
+		msgs, err := c.undelivered(ctx, key, last[key], 1)
+		if err != nil || len(msgs) == 0 {
+			continue
+		}
+		raw, _ := msgs[0].Values[streamJobField].(string)
+		var job struct {
+			QueuedAt time.Time `json:"queued_at"`
+		}
+		if json.Unmarshal([]byte(raw), &job) != nil || job.QueuedAt.IsZero() {
+			continue
+		}
+		if wait := now.Sub(job.QueuedAt); wait > c.priority.MaxWait {
+			aged = append(aged, starved{key: key, wait: wait})
+		}
+	}
+	if len(aged) == 0 {
+		return priorityLanes
+	}
+	sort.SliceStable(aged, func(i, j int) bool { return aged[i].wait > aged[j].wait })
+
+	order := make([]string, 0, len(priorityLanes))
+	seen := make(map[string]bool, len(aged))
+	for _, a := range aged {
+		order = append(order, a.key)
+		seen[a.key] = true
+	}
+	for _, key := range priorityLanes {
+		if !seen[key] {
+			order = append(order, key)
+		}
+	}
+	return order
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func popJob(t *testing.T, c *Consumer) *Job {
+	t.Helper()
+	d := deliver(t, c)
+	if d == nil {
+		return nil
+	}
+	var job Job
+	require.NoError(t, json.Unmarshal([]byte(d.raw), &job))
+	return &job
+}
+
+func addRaw(t *testing.T, c *Consumer, lane string, raw []byte) {
+	t.Helper()
+	require.NoError(t, c.redis.XAdd(context.Background(), &redis.XAddArgs{Stream: lane, Values: []interface{}{streamJobField, raw}}).Err())
+}
+
+func TestPriorityLanes(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	// 发薪批次先入队，紧急任务后入队仍先处理
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "payroll-1", BatchID: "payroll", UserID: "user-1", Priority: "LOW"},
+		{ID: "payroll-2", BatchID: "payroll", UserID: "user-1", Priority: "LOW"},
+		{ID: "invoice-1", BatchID: "invoices", UserID: "user-1"},
+	}))
+	require.NoError(t, c.Push(ctx, &Job{ID: "refund-1", BatchID: "refunds", UserID: "user-1", Priority: "urgent"}))
+	require.NoError(t, c.Push(ctx, &Job{ID: "vendor-1", BatchID: "vendors", UserID: "user-1", Priority: "HIGH"}))
+
+	n, err := c.GetQueueLength(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+
+	var order []string
+	for job := popJob(t, c); job != nil; job = popJob(t, c) {
+		order = append(order, job.ID)
+	}
+	assert.Equal(t, []string{"refund-1", "vendor-1", "invoice-1", "payroll-1", "payroll-2"}, order)
+
+	processing, _ := c.GetProcessingCount(ctx)
+	assert.EqualValues(t, 5, processing)
+}
+
+func TestPriorityStarvation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.priority.MaxWait = time.Minute
+
+	// 低优先级任务已等待超过 MaxWait，先于持续到来的紧急任务处理
+	stale, _ := json.Marshal(&Job{ID: "payroll-1", Priority: "LOW", QueuedAt: time.Now().Add(-2 * time.Minute)})
+	addRaw(t, c, PayoutQueueLowKey, stale)
+	require.NoError(t, c.Push(ctx, &Job{ID: "refund-1", BatchID: "refunds", UserID: "user-1", Priority: "URGENT"}))
+	require.NoError(t, c.Push(ctx, &Job{ID: "payroll-2", BatchID: "payroll", UserID: "user-1", Priority: "LOW"}))
+
+	assert.Equal(t, "payroll-1", popJob(t, c).ID)
+	assert.Equal(t, "refund-1", popJob(t, c).ID)
+	assert.Equal(t, "payroll-2", popJob(t, c).ID)
+
+	// MaxWait 为 0 时严格按优先级
+	c.priority.MaxWait = 0
+	addRaw(t, c, PayoutQueueLowKey, stale)
+	require.NoError(t, c.Push(ctx, &Job{ID: "refund-2", BatchID: "refunds", UserID: "user-1", Priority: "URGENT"}))
+	assert.Equal(t, "refund-2", popJob(t, c).ID)
+}
+
+func TestPriorityLanesCancelAndRelease(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Priority: "URGENT"},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Priority: "LOW"},
+		{ID: "job-3", BatchID: "batch-2", UserID: "user-1", Priority: "LOW"},
+	}))
+	cancelled, _, err := c.CancelBatch(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cancelled)
+	n, _ := c.GetQueueLength(ctx)
+	assert.EqualValues(t, 1, n, "cancelled jobs removed from every lane")
+
+	// 熔断暂存的任务恢复后回到原优先级通道
+	_, err = c.OpenCircuit(ctx, &Circuit{ChainID: 1, Reason: "test"})
+	require.NoError(t, err)
+	urgent := &Job{ID: "held-1", ChainID: 1, Priority: "URGENT"}
+	require.NoError(t, c.Push(ctx, urgent))
+	d := deliver(t, c)
+	require.Equal(t, PayoutQueueUrgentKey, d.lane)
+	require.True(t, c.holdIfCircuitOpen(ctx, urgent, d))
+	_, err = c.CloseCircuit(ctx, 1)
+	require.NoError(t, err)
+	urgentLen, _ := c.redis.XLen(ctx, PayoutQueueUrgentKey).Result()
+	assert.EqualValues(t, 1, urgentLen)
+}
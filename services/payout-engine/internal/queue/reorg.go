@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PayoutMinedTxKey 已上链但未达到重组深度的交易 (hash: PendingTx.Key() -> MinedTx)
+const PayoutMinedTxKey = "payout:mined_tx"
+
+// MinedTx 已上链的交易及其所在区块，区块被重组移出时重新核对
+type MinedTx struct {
+	PendingTx
+	MinedHash   string `json:"mined_hash"` // 上链的版本 (可能是被替换的旧交易)
+	BlockNumber uint64 `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+}
+
+// TrackMinedTx 记录或更新已上链交易
+func (c *Consumer) TrackMinedTx(ctx context.Context, m *MinedTx) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mined tx: %w", err)
+	}
+	return c.redis.HSet(ctx, PayoutMinedTxKey, m.Key(), data).Err()
+}
+
+// ListMinedTxs 列出所有待核对的已上链交易
+func (c *Consumer) ListMinedTxs(ctx context.Context) ([]*MinedTx, error) {
+	entries, err := c.redis.HGetAll(ctx, PayoutMinedTxKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	mined := make([]*MinedTx, 0, len(entries))
+	for key, data := range entries {
+		var m MinedTx
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			c.redis.HDel(ctx, PayoutMinedTxKey, key)
+			continue
+		}
+		mined = append(mined, &m)
+	}
+	return mined, nil
+}
+
+// RemoveMinedTx 交易达到重组深度或重新进入待确认后移除
+func (c *Consumer) RemoveMinedTx(ctx context.Context, key string) error {
+	return c.redis.HDel(ctx, PayoutMinedTxKey, key).Err()
+}
+
+// RecordBlock 记录任务交易所在区块
+func (c *Consumer) RecordBlock(ctx context.Context, ref BatchRef, jobID string, block uint64) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil || status == nil {
+		return err
+	}
+	status.BlockNumber = block
+	return c.saveJobStatus(ctx, status)
+}
+
+// FlagReorged 任务交易所在区块被重组移出: 标记任务、清除区块号，并发出 job.reorged。
+// 状态变更同时写入账本，下游据此冲正该笔记录，直到交易重新上链。
+func (c *Consumer) FlagReorged(ctx context.Context, ref BatchRef, jobID string) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil || status == nil {
+		return err
+	}
+	status.Reorged = true
+	status.BlockNumber = 0
+	if err := c.saveJobStatus(ctx, status); err != nil {
+		return err
+	}
+
+	if target, err := c.WebhookFor(ctx, ref.UserID, ref.BatchID); err == nil && target != nil {
+		c.enqueueEvent(ctx, WebhookEvent{Type: EventJobReorged, UserID: ref.UserID, BatchID: ref.BatchID, Job: status, CorrelationID: status.CorrelationID})
+	}
+	return nil
+}
+
+// FailReorged 被重组移出的交易未能重新上链 (nonce 已被其他交易消耗): 已确认的任务改为失败，
+// 记录失败原因和链上错误类别并发出 job.failed。状态变更同时写入账本，下游据此撤销已付款记录。
+func (c *Consumer) FailReorged(ctx context.Context, ref BatchRef, jobID string, cause error) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil || status == nil {
+		return err
+	}
+	status.State = JobStateFailed
+	status.Reorged = true
+	status.BlockNumber = 0
+	status.Error = cause.Error()
+	status.ErrorCode = ErrorCode(cause)
+	status.ChainError = ChainErrorOf(cause)
+	status.UpdatedAt = time.Now()
+	if err := c.saveJobStatus(ctx, status); err != nil {
+		return err
+	}
+
+	if target, err := c.WebhookFor(ctx, ref.UserID, ref.BatchID); err == nil && target != nil {
+		c.enqueueEvent(ctx, WebhookEvent{Type: EventJobFailed, UserID: ref.UserID, BatchID: ref.BatchID, Job: status, CorrelationID: status.CorrelationID})
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagReorged(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.EnableLedger()
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()}
+	require.NoError(t, c.Push(ctx, job))
+	c.handleSuccess(ctx, job, deliver(t, c), "0xabc")
+	require.NoError(t, c.RecordBlock(ctx, ref, "job-1", 100))
+
+	mined := &MinedTx{PendingTx: PendingTx{JobID: "job-1", BatchID: "batch-1", UserID: "user-1", TxHash: "0xabc"}, MinedHash: "0xabc", BlockNumber: 100, BlockHash: "0x01"}
+	require.NoError(t, c.TrackMinedTx(ctx, mined))
+	list, err := c.ListMinedTxs(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, mined, list[0])
+
+	require.NoError(t, c.FlagReorged(ctx, ref, "job-1"))
+	status, err := c.GetJobStatus(ctx, ref, "job-1")
+	require.NoError(t, err)
+	assert.True(t, status.Reorged)
+	assert.Zero(t, status.BlockNumber)
+	assert.Equal(t, JobStateConfirmed, status.State)
+
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	var reorged *WebhookEvent
+	for _, d := range deliveries {
+		if d.Event.Type == EventJobReorged {
+			reorged = &d.Event
+		}
+	}
+	require.NotNil(t, reorged)
+	assert.True(t, reorged.Job.Reorged)
+
+	// 标记在后续状态更新中保留，账本记录了冲正
+	require.NoError(t, c.RecordBlock(ctx, ref, "job-1", 102))
+	status, _ = c.GetJobStatus(ctx, ref, "job-1")
+	assert.True(t, status.Reorged)
+	assert.EqualValues(t, 102, status.BlockNumber)
+	entries, err := c.ClaimLedgerEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	flagged := false
+	for _, e := range entries {
+		flagged = flagged || (e.Status.Reorged && e.Status.BlockNumber == 0)
+	}
+	assert.True(t, flagged)
+
+	require.NoError(t, c.RemoveMinedTx(ctx, mined.Key()))
+	list, _ = c.ListMinedTxs(ctx)
+	assert.Empty(t, list)
+}
+
+func TestFailReorged(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.EnableLedger()
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()}
+	require.NoError(t, c.Push(ctx, job))
+	c.handleSuccess(ctx, job, deliver(t, c), "0xabc")
+	require.NoError(t, c.RecordBlock(ctx, ref, "job-1", 100))
+
+	cause := WithChainError(errors.New("nonce consumed"), &ChainError{Code: ChainErrReorgedNonceConsumed})
+	require.NoError(t, c.FailReorged(ctx, ref, "job-1", cause))
+	status, err := c.GetJobStatus(ctx, ref, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStateFailed, status.State)
+	assert.True(t, status.Reorged)
+	assert.Zero(t, status.BlockNumber)
+	assert.Equal(t, "0xabc", status.TxHash)
+	assert.Equal(t, "nonce consumed", status.Error)
+	require.NotNil(t, status.ChainError)
+	assert.Equal(t, ChainErrReorgedNonceConsumed, status.ChainError.Code)
+
+	// 下游收到 job.failed，账本记录改为失败
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	var failed *WebhookEvent
+	for _, d := range deliveries {
+		if d.Event.Type == EventJobFailed {
+			failed = &d.Event
+		}
+	}
+	require.NotNil(t, failed)
+	assert.Equal(t, ChainErrReorgedNonceConsumed, failed.Job.ChainError.Code)
+	entries, err := c.ClaimLedgerEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, JobStateFailed, entries[len(entries)-1].Status.State)
+}
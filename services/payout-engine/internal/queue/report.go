@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+// 批次报告: 批次结束时写入 outbox，由 report writer 生成并上传到对象存储
+const (
+	PayoutReportOutboxKey = "payout:reports:outbox" // zset: BatchRef -> 可生成时间 (unix ms)
+	PayoutReportKeyPrefix = "payout:report:"        // string: user_id:batch_id -> ReportLocation
+)
+
+// reportLease 生成中的报告在 outbox 中顺延的时间，上传失败或实例崩溃后重新生成
+const reportLease = 5 * time.Minute
+
+// ReportLocation 已上传的批次报告
+type ReportLocation struct {
+	CSV         string    `json:"csv"`
+	JSON        string    `json:"json"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// EnableReports 开启批次报告 (须在 Start 之前调用)，未开启时批次结束不写 outbox
+func (c *Consumer) EnableReports() {
+	c.reports = true
+}
+
+// queueReport 批次结束后排队生成报告，写入失败仅记录日志
+func (c *Consumer) queueReport(ctx context.Context, userID, batchID string) {
+	if !c.reports {
+		return
+	}
+	ref := BatchRef{UserID: userID, BatchID: batchID}
+	if err := c.redis.ZAdd(ctx, PayoutReportOutboxKey, &redis.Z{Score: float64(time.Now().UnixMilli()), Member: ref.member()}).Err(); err != nil {
+		log.Warn().Err(err).Str("batch_id", batchID).Msg("Failed to queue batch report")
+	}
+}
+
+// ClaimReports 取出待生成报告的批次，并以租期重新排期，上传成功后由 AckReport 删除
+func (c *Consumer) ClaimReports(ctx context.Context, now time.Time, limit int64) ([]BatchRef, error) {
+	members, err := c.redis.ZRangeByScore(ctx, PayoutReportOutboxKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []BatchRef
+	for _, member := range members {
+		removed, err := c.redis.ZRem(ctx, PayoutReportOutboxKey, member).Result()
+		if err != nil || removed == 0 {
+			continue // 已被其他实例取走
+		}
+		var ref BatchRef
+		if err := json.Unmarshal([]byte(member), &ref); err != nil {
+			continue
+		}
+		c.redis.ZAdd(ctx, PayoutReportOutboxKey, &redis.Z{Score: float64(now.Add(reportLease).UnixMilli()), Member: member})
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// AckReport 报告已上传 (或批次已过期) 后移出 outbox
+func (c *Consumer) AckReport(ctx context.Context, ref BatchRef) error {
+	return c.redis.ZRem(ctx, PayoutReportOutboxKey, ref.member()).Err()
+}
+
+// SaveReportLocation 记录报告上传位置 (与批次状态同样保留 BatchStatusTTL)
+func (c *Consumer) SaveReportLocation(ctx context.Context, userID, batchID string, loc *ReportLocation) error {
+	data, err := json.Marshal(loc)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, PayoutReportKeyPrefix+userID+":"+batchID, data, BatchStatusTTL).Err()
+}
+
+// GetReportLocation 报告上传位置 (未上传时为 nil)
+func (c *Consumer) GetReportLocation(ctx context.Context, userID, batchID string) (*ReportLocation, error) {
+	data, err := c.redis.Get(ctx, PayoutReportKeyPrefix+userID+":"+batchID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loc ReportLocation
+	if err := json.Unmarshal([]byte(data), &loc); err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReportQueue(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.EnableReports()
+
+	// 未注册 webhook 的批次结束时也排队生成报告，且不发 batch.completed
+	jobs := []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", CreatedAt: time.Now()},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", CreatedAt: time.Now()},
+	}
+	require.NoError(t, c.PushBatch(ctx, jobs))
+	c.handleSuccess(ctx, jobs[0], deliver(t, c), "0xabc")
+
+	refs, err := c.ClaimReports(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, refs, "batch still running")
+
+	_, _, err = c.CancelBatch(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	refs, err = c.ClaimReports(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{{UserID: "user-1", BatchID: "batch-1"}}, refs)
+
+	events, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// 租期内不重复取出，租期结束后重新生成，确认后删除
+	refs, err = c.ClaimReports(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+	refs, err = c.ClaimReports(ctx, time.Now().Add(reportLease+time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	require.NoError(t, c.AckReport(ctx, refs[0]))
+	refs, err = c.ClaimReports(ctx, time.Now().Add(2*reportLease), 10)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	loc, err := c.GetReportLocation(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Nil(t, loc)
+	saved := &ReportLocation{CSV: "s3://reports/user-1/batch-1.csv", JSON: "s3://reports/user-1/batch-1.json", GeneratedAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, c.SaveReportLocation(ctx, "user-1", "batch-1", saved))
+	loc, err = c.GetReportLocation(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, saved, loc)
+}
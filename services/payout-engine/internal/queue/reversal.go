@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PayoutReversalTxKeyPrefix 退回交易到任务的索引 (payout:reversal:tx:<chain_id>:<tx_hash> -> 任务 ID)，
+// 同一笔退回交易只能关联一个任务
+const PayoutReversalTxKeyPrefix = "payout:reversal:tx:"
+
+// ErrReversalConflict 任务已关联其他退回交易，或该退回交易已关联其他任务
+var ErrReversalConflict = errors.New("reversal already recorded")
+
+// ErrReversalNotApplicable 任务未成功付款，不能记录退回
+var ErrReversalNotApplicable = errors.New("job was not paid out")
+
+// Reversal 收款方将款项退回的链上交易
+type Reversal struct {
+	TxHash      string    `json:"tx_hash"`
+	From        string    `json:"from"` // 退款地址 (原收款地址)
+	To          string    `json:"to"`
+	Amount      string    `json:"amount"`            // 退回金额 (付款资产的最小单位)
+	Partial     bool      `json:"partial,omitempty"` // 退回金额小于付款金额
+	BlockNumber uint64    `json:"block_number"`
+	RecordedBy  string    `json:"recorded_by"`
+	Note        string    `json:"note,omitempty"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+func reversalTxKey(chainID uint64, txHash string) string {
+	return PayoutReversalTxKeyPrefix + strconv.FormatUint(chainID, 10) + ":" + strings.ToLower(txHash)
+}
+
+// RecordReversal 将退回交易关联到已付款的任务: 写入任务状态 (同时写入账本)，发出 job.reversed，
+// 已生成的批次报告重新生成。重复记录同一笔退回交易返回已有状态。
+func (c *Consumer) RecordReversal(ctx context.Context, ref BatchRef, jobID string, r *Reversal) (*JobStatus, error) {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrBatchNotFound
+	}
+	if existing := status.Reversal; existing != nil {
+		if strings.EqualFold(existing.TxHash, r.TxHash) {
+			return status, nil
+		}
+		return nil, ErrReversalConflict
+	}
+	if status.State != JobStateConfirmed {
+		return nil, ErrReversalNotApplicable
+	}
+
+	key := reversalTxKey(status.ChainID, r.TxHash)
+	claimed, err := c.redis.SetNX(ctx, key, jobID, BatchStatusTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrReversalConflict
+	}
+
+	status.Reversal = r
+	if err := c.saveJobStatus(ctx, status); err != nil {
+		c.redis.Del(ctx, key)
+		return nil, err
+	}
+
+	if target, err := c.WebhookFor(ctx, ref.UserID, ref.BatchID); err == nil && target != nil {
+		c.enqueueEvent(ctx, WebhookEvent{Type: EventJobReversed, UserID: ref.UserID, BatchID: ref.BatchID, Job: status, CorrelationID: status.CorrelationID})
+	}
+	if loc, err := c.GetReportLocation(ctx, ref.UserID, ref.BatchID); err == nil && loc != nil {
+		c.queueReport(ctx, ref.UserID, ref.BatchID)
+	}
+	return status, nil
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReversal(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	c.EnableLedger()
+	c.EnableReports()
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1, Amount: "100", CreatedAt: time.Now()}
+	require.NoError(t, c.Push(ctx, job))
+	require.NoError(t, c.Push(ctx, &Job{ID: "job-2", BatchID: "batch-1", UserID: "user-1", ChainID: 1, Amount: "100", CreatedAt: time.Now()}))
+	c.handleSuccess(ctx, job, deliver(t, c), "0xabc")
+
+	// 未付款的任务不能记录退回
+	_, err := c.RecordReversal(ctx, ref, "job-2", &Reversal{TxHash: "0xdef"})
+	assert.ErrorIs(t, err, ErrReversalNotApplicable)
+
+	require.NoError(t, c.SaveReportLocation(ctx, "user-1", "batch-1", &ReportLocation{CSV: "s3://reports/batch-1.csv"}))
+	reversal := &Reversal{TxHash: "0xDEF", Amount: "40", Partial: true, RecordedBy: "ops"}
+	status, err := c.RecordReversal(ctx, ref, "job-1", reversal)
+	require.NoError(t, err)
+	assert.Equal(t, reversal, status.Reversal)
+	assert.Equal(t, JobStateConfirmed, status.State)
+
+	// 重复记录同一交易返回已有状态；其他交易或其他任务冲突
+	_, err = c.RecordReversal(ctx, ref, "job-1", &Reversal{TxHash: "0xdef"})
+	assert.NoError(t, err)
+	_, err = c.RecordReversal(ctx, ref, "job-1", &Reversal{TxHash: "0x123"})
+	assert.ErrorIs(t, err, ErrReversalConflict)
+
+	// 后续状态更新保留退回记录
+	require.NoError(t, c.RecordBlock(ctx, ref, "job-1", 100))
+	status, err = c.GetJobStatus(ctx, ref, "job-1")
+	require.NoError(t, err)
+	require.NotNil(t, status.Reversal)
+	assert.Equal(t, "40", status.Reversal.Amount)
+
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	reversed := 0
+	for _, d := range deliveries {
+		if d.Event.Type == EventJobReversed {
+			reversed++
+			assert.Equal(t, "0xDEF", d.Event.Job.Reversal.TxHash)
+		}
+	}
+	assert.Equal(t, 1, reversed)
+
+	entries, err := c.ClaimLedgerEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	recorded := false
+	for _, e := range entries {
+		recorded = recorded || e.Status.Reversal != nil
+	}
+	assert.True(t, recorded)
+
+	// 已上传的报告重新生成
+	refs, err := c.ClaimReports(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{ref}, refs)
+}
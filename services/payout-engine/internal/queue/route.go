@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"context"
+	"math/big"
+)
+
+// Supported bridges for cross-chain routing
+const BridgeCCTP = "cctp"
+
+// 跨链前置交易的步骤 (见 PendingTx.Bridge)。approve 和 burn 在源链上，mint 在目标链上。
+const (
+	BridgeStepApprove = "approve" // 对跨链合约授权 USDC (源链)
+	BridgeStepBurn    = "burn"    // 销毁 USDC (源链)
+	BridgeStepMint    = "mint"    // 凭证明铸造 USDC (目标链)
+)
+
+// 跨链路由的环节 (RouteStatus.Leg)，铸造之后按任务状态跟踪转账
+const (
+	RouteLegBurnSent = "burn_sent" // 源链销毁交易已广播，等待上链
+	RouteLegBurned   = "burned"    // 销毁已上链，等待跨链证明
+	RouteLegAttested = "attested"  // 已取得证明，等待在目标链铸造
+	RouteLegMintSent = "mint_sent" // 目标链铸造交易已广播，转账随后发送
+)
+
+// Route 任务的跨链路由
+type Route struct {
+	Bridge        string `json:"bridge"`          // 目前仅 BridgeCCTP
+	SourceChainID uint64 `json:"source_chain_id"` // 资金所在的链
+}
+
+// RouteStatus 跨链路由各环节的进度 (记录在任务状态上，任务重试时从中断处继续)
+type RouteStatus struct {
+	Bridge        string `json:"bridge"`
+	SourceChainID uint64 `json:"source_chain_id"`
+	Leg           string `json:"leg"`
+	BurnTxHash    string `json:"burn_tx_hash,omitempty"`
+	BurnGasFee    string `json:"burn_gas_fee,omitempty"` // 源链上 approve 和销毁交易的网络费 (源链原生代币)
+	Message       string `json:"message,omitempty"`      // 跨链消息 (hex)
+	MessageHash   string `json:"message_hash,omitempty"`
+	Attestation   string `json:"attestation,omitempty"` // 跨链证明 (hex)
+	MintTxHash    string `json:"mint_tx_hash,omitempty"`
+	MintGasFee    string `json:"mint_gas_fee,omitempty"`
+}
+
+// SaveRoute 记录任务的跨链路由进度
+func (c *Consumer) SaveRoute(ctx context.Context, ref BatchRef, jobID string, route *RouteStatus) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil // 状态已过期
+	}
+	status.Route = route
+	return c.saveJobStatus(ctx, status)
+}
+
+// RecordBridgeFee 记录跨链前置交易上链后的网络费: 源链交易 (approve、burn) 累加到 BurnGasFee，铸造交易记为 MintGasFee
+func (c *Consumer) RecordBridgeFee(ctx context.Context, ref BatchRef, jobID, step, fee string) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil || status.Route == nil {
+		return nil // 状态已过期
+	}
+	if step == BridgeStepMint {
+		status.Route.MintGasFee = fee
+		return c.saveJobStatus(ctx, status)
+	}
+	total, ok := new(big.Int).SetString(status.Route.BurnGasFee, 10)
+	if !ok {
+		total = new(big.Int)
+	}
+	if add, ok := new(big.Int).SetString(fee, 10); ok {
+		total.Add(total, add)
+	}
+	status.Route.BurnGasFee = total.String()
+	return c.saveJobStatus(ctx, status)
+}
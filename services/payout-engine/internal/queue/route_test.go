@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteStatus(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", ChainID: 8453, CreatedAt: time.Now(),
+		Route: &Route{Bridge: BridgeCCTP, SourceChainID: 1}}
+	require.NoError(t, c.PushBatch(ctx, []*Job{job}))
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	route := &RouteStatus{Bridge: BridgeCCTP, SourceChainID: 1, Leg: RouteLegBurnSent, BurnTxHash: "0xburn"}
+	require.NoError(t, c.SaveRoute(ctx, ref, job.ID, route))
+
+	// 源链 approve 与销毁的网络费累加，铸造费计入任务的网络费合计
+	require.NoError(t, c.RecordBridgeFee(ctx, ref, job.ID, BridgeStepApprove, "100"))
+	require.NoError(t, c.RecordBridgeFee(ctx, ref, job.ID, BridgeStepBurn, "250"))
+	require.NoError(t, c.RecordBridgeFee(ctx, ref, job.ID, BridgeStepMint, "40"))
+	require.NoError(t, c.RecordGasFee(ctx, ref, job.ID, GasCost{Fee: "60"}))
+
+	// 任务状态变化时保留路由进度
+	require.NoError(t, c.setJobState(ctx, job, JobStateRetrying, "", nil))
+
+	status, err := c.GetJobStatus(ctx, ref, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, status.Route)
+	assert.Equal(t, "0xburn", status.Route.BurnTxHash)
+	assert.Equal(t, "350", status.Route.BurnGasFee)
+	assert.Equal(t, "40", status.Route.MintGasFee)
+	assert.Equal(t, "100", status.TotalGasFee().String())
+
+	p := &PendingTx{JobID: job.ID, Bridge: BridgeStepBurn}
+	assert.Equal(t, "job-1:bridge_burn", p.Key())
+	assert.True(t, p.Prerequisite())
+}
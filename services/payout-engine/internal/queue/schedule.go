@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Scheduled batch keys
+const (
+	// PayoutScheduleKey 定时批次索引 (zset: BatchRef JSON, score 为执行时间)
+	PayoutScheduleKey = "payout:schedule"
+	// PayoutScheduledKeyPrefix 定时批次记录 (payout:scheduled:<user_id>:<batch_id>)
+	PayoutScheduledKeyPrefix = "payout:scheduled:"
+)
+
+// ScheduleState 定时批次状态
+type ScheduleState string
+
+const (
+	ScheduleStatePending   ScheduleState = "scheduled" // 等待执行时间
+	ScheduleStateCancelled ScheduleState = "cancelled" // 执行前已取消
+	ScheduleStateFailed    ScheduleState = "failed"    // 到期后未能入队 (如余额不足)
+)
+
+// ScheduledBatch 尚未入队的定时批次。到期入队后记录即删除，之后按任务状态查询。
+type ScheduledBatch struct {
+	UserID        string          `json:"user_id"`
+	BatchID       string          `json:"batch_id"`
+	ExecuteAt     time.Time       `json:"execute_at"`
+	Request       json.RawMessage `json:"request"` // 提交请求，到期后按该请求入队
+	Items         int             `json:"items"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	State         ScheduleState   `json:"state"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+func scheduledKey(userID, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", PayoutScheduledKeyPrefix, userID, batchID)
+}
+
+// ScheduleBatch 保存定时批次并在执行时间加入索引 (已存在时整体替换)
+func (c *Consumer) ScheduleBatch(ctx context.Context, batch *ScheduledBatch) error {
+	return c.schedule(ctx, batch, batch.ExecuteAt)
+}
+
+// RetryScheduledBatch 到期入队暂时失败 (如 RPC 不可用) 时在 at 重新尝试，执行时间不变
+func (c *Consumer) RetryScheduledBatch(ctx context.Context, batch *ScheduledBatch, at time.Time) error {
+	return c.schedule(ctx, batch, at)
+}
+
+func (c *Consumer) schedule(ctx context.Context, batch *ScheduledBatch, at time.Time) error {
+	batch.State = ScheduleStatePending
+	batch.UpdatedAt = time.Now()
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(at) + BatchStatusTTL
+	ref := BatchRef{UserID: batch.UserID, BatchID: batch.BatchID}
+
+	pipe := c.redis.TxPipeline()
+	pipe.Set(ctx, scheduledKey(batch.UserID, batch.BatchID), data, ttl)
+	pipe.ZAdd(ctx, PayoutScheduleKey, &redis.Z{Score: float64(at.Unix()), Member: ref.member()})
+	// 运维按批次 ID 查询/取消
+	pipe.SAdd(ctx, PayoutBatchRefKeyPrefix+batch.BatchID, batch.UserID)
+	pipe.Expire(ctx, PayoutBatchRefKeyPrefix+batch.BatchID, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetScheduledBatch 返回定时批次记录 (不存在时为 nil)
+func (c *Consumer) GetScheduledBatch(ctx context.Context, userID, batchID string) (*ScheduledBatch, error) {
+	data, err := c.redis.Get(ctx, scheduledKey(userID, batchID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var batch ScheduledBatch
+	if err := json.Unmarshal([]byte(data), &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ClaimScheduledBatch 将批次移出定时索引并返回其记录。
+// 入队、取消和修改都先认领，多实例下只有一方成功；批次不在索引中 (已入队或已取消) 时返回 nil。
+func (c *Consumer) ClaimScheduledBatch(ctx context.Context, userID, batchID string) (*ScheduledBatch, error) {
+	ref := BatchRef{UserID: userID, BatchID: batchID}
+	removed, err := c.redis.ZRem(ctx, PayoutScheduleKey, ref.member()).Result()
+	if err != nil || removed == 0 {
+		return nil, err
+	}
+	batch, err := c.GetScheduledBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, fmt.Errorf("scheduled batch %s has no record", batchID)
+	}
+	return batch, nil
+}
+
+// DueScheduledBatches 返回执行时间不晚于 now 的定时批次 (最早在前)
+func (c *Consumer) DueScheduledBatches(ctx context.Context, now time.Time) ([]BatchRef, error) {
+	members, err := c.redis.ZRangeByScore(ctx, PayoutScheduleKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]BatchRef, 0, len(members))
+	for _, m := range members {
+		if ref, ok := parseBatchRef(m); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// FinishScheduledBatch 记录已认领批次的终态 (取消或入队失败)，保留 BatchStatusTTL 供查询
+func (c *Consumer) FinishScheduledBatch(ctx context.Context, batch *ScheduledBatch, state ScheduleState, cause error) error {
+	batch.State = state
+	batch.Error = ""
+	if cause != nil {
+		batch.Error = cause.Error()
+	}
+	batch.UpdatedAt = time.Now()
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, scheduledKey(batch.UserID, batch.BatchID), data, BatchStatusTTL).Err()
+}
+
+// DeleteScheduledBatch 删除已入队批次的定时记录
+func (c *Consumer) DeleteScheduledBatch(ctx context.Context, userID, batchID string) error {
+	return c.redis.Del(ctx, scheduledKey(userID, batchID)).Err()
+}
@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledBatch(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	now := time.Now()
+
+	payroll := &ScheduledBatch{UserID: "user-1", BatchID: "payroll-1", ExecuteAt: now.Add(time.Hour), Request: []byte(`{}`), Items: 2, CreatedAt: now}
+	bonus := &ScheduledBatch{UserID: "user-1", BatchID: "bonus-1", ExecuteAt: now.Add(-time.Minute), Request: []byte(`{}`), Items: 1, CreatedAt: now}
+	require.NoError(t, c.ScheduleBatch(ctx, payroll))
+	require.NoError(t, c.ScheduleBatch(ctx, bonus))
+
+	// 只返回已到期的批次
+	due, err := c.DueScheduledBatches(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{{UserID: "user-1", BatchID: "bonus-1"}}, due)
+
+	owners, err := c.BatchOwners(ctx, "payroll-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1"}, owners)
+
+	// 认领后不能再次认领
+	claimed, err := c.ClaimScheduledBatch(ctx, "user-1", "bonus-1")
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 1, claimed.Items)
+	again, err := c.ClaimScheduledBatch(ctx, "user-1", "bonus-1")
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	require.NoError(t, c.FinishScheduledBatch(ctx, claimed, ScheduleStateFailed, errors.New("insufficient balance")))
+	got, err := c.GetScheduledBatch(ctx, "user-1", "bonus-1")
+	require.NoError(t, err)
+	assert.Equal(t, ScheduleStateFailed, got.State)
+	assert.Equal(t, "insufficient balance", got.Error)
+
+	// 暂时失败后按新时间重试，执行时间不变
+	claimed, err = c.ClaimScheduledBatch(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	require.NoError(t, c.RetryScheduledBatch(ctx, claimed, now.Add(-time.Second)))
+	due, err = c.DueScheduledBatches(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{{UserID: "user-1", BatchID: "payroll-1"}}, due)
+	got, err = c.GetScheduledBatch(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	assert.Equal(t, ScheduleStatePending, got.State)
+	assert.True(t, got.ExecuteAt.Equal(payroll.ExecuteAt))
+
+	require.NoError(t, c.DeleteScheduledBatch(ctx, "user-1", "payroll-1"))
+	got, err = c.GetScheduledBatch(ctx, "user-1", "payroll-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,26 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// PayoutSettlementKeyPrefix 结算汇总投递标记 (payout:settlement:<tenant>:<day>:<channel>)
+const PayoutSettlementKeyPrefix = "payout:settlement:"
+
+// settlementClaimTTL 标记保留时间，覆盖重启和多实例下的重复投递窗口
+const settlementClaimTTL = 8 * 24 * time.Hour
+
+func settlementKey(tenant, day, channel string) string {
+	return PayoutSettlementKeyPrefix + tenant + ":" + day + ":" + channel
+}
+
+// ClaimSettlement 占用某租户某日某渠道的汇总投递。已被占用 (已发送或其他实例发送中) 时返回 false。
+func (c *Consumer) ClaimSettlement(ctx context.Context, tenant, day, channel string) (bool, error) {
+	return c.redis.SetNX(ctx, settlementKey(tenant, day, channel), time.Now().Unix(), settlementClaimTTL).Result()
+}
+
+// ReleaseSettlement 投递失败时释放占用，下次检查时重试
+func (c *Consumer) ReleaseSettlement(ctx context.Context, tenant, day, channel string) error {
+	return c.redis.Del(ctx, settlementKey(tenant, day, channel)).Err()
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PayoutSpendKeyPrefix 支出策略的额度预留 (hash per day: job_id -> "<to>|<amount>")
+const PayoutSpendKeyPrefix = "payout:spend:"
+
+// spendWindowDays 周额度按最近 7 个 UTC 自然日计算
+const spendWindowDays = 7
+
+// spendRetention 预留记录保留时间 (覆盖周额度窗口)
+const spendRetention = 8 * 24 * time.Hour
+
+// spendReserveAttempts 并发预留冲突时的重试次数
+const spendReserveAttempts = 10
+
+// SpendUsage 付款地址某代币已预留的支出 (最小单位)
+type SpendUsage struct {
+	Daily           *big.Int
+	Weekly          *big.Int
+	RecipientDaily  *big.Int
+	RecipientWeekly *big.Int
+}
+
+func spendDayKey(job *Job, t time.Time) string {
+	return fmt.Sprintf("%s%s:%d:%s:%s", PayoutSpendKeyPrefix, t.UTC().Format("20060102"),
+		job.ChainID, normalizeAddress(job.FromAddress), normalizeAddress(job.Asset()))
+}
+
+func spendDayKeys(job *Job, now time.Time) []string {
+	keys := make([]string, spendWindowDays)
+	for d := range keys {
+		keys[d] = spendDayKey(job, now.AddDate(0, 0, -d))
+	}
+	return keys
+}
+
+// ReserveSpend 检查并预留任务金额。check 收到最近 1 天和 7 天内已预留的支出 (不含本任务)，
+// 返回错误时不预留。任务已有预留 (重试) 时直接返回，不再检查。
+// 同一付款地址和代币的并发预留通过 WATCH 事务串行化。
+func (c *Consumer) ReserveSpend(ctx context.Context, job *Job, check func(SpendUsage) error) error {
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", job.Amount)
+	}
+	now := time.Now()
+	keys := spendDayKeys(job, now)
+	to := normalizeAddress(job.ToAddress)
+
+	reserve := func(tx *redis.Tx) error {
+		usage := SpendUsage{Daily: new(big.Int), Weekly: new(big.Int), RecipientDaily: new(big.Int), RecipientWeekly: new(big.Int)}
+		for d, key := range keys {
+			entries, err := tx.HGetAll(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			if _, ok := entries[job.ID]; ok {
+				return nil
+			}
+			for _, entry := range entries {
+				recipient, value, _ := strings.Cut(entry, "|")
+				v, ok := new(big.Int).SetString(value, 10)
+				if !ok {
+					continue
+				}
+				usage.Weekly.Add(usage.Weekly, v)
+				if recipient == to {
+					usage.RecipientWeekly.Add(usage.RecipientWeekly, v)
+				}
+				if d == 0 {
+					usage.Daily.Add(usage.Daily, v)
+					if recipient == to {
+						usage.RecipientDaily.Add(usage.RecipientDaily, v)
+					}
+				}
+			}
+		}
+		if check != nil {
+			if err := check(usage); err != nil {
+				return err
+			}
+		}
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, keys[0], job.ID, to+"|"+amount.String())
+			pipe.Expire(ctx, keys[0], spendRetention)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < spendReserveAttempts; i++ {
+		err := c.redis.Watch(ctx, reserve, keys...)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to reserve spend for job %s: too much contention", job.ID)
+}
+
+// ReleaseSpend 释放任务的预留 (任务未发出交易时调用)
+func (c *Consumer) ReleaseSpend(ctx context.Context, job *Job) error {
+	pipe := c.redis.Pipeline()
+	for _, key := range spendDayKeys(job, time.Now()) {
+		pipe.HDel(ctx, key, job.ID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveSpend(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	job := func(id, to, amount string) *Job {
+		return &Job{ID: id, ChainID: 8453, FromAddress: "0xABC", ToAddress: to, TokenAddress: "0xUSDC", Amount: amount}
+	}
+	var seen SpendUsage
+	record := func(u SpendUsage) error {
+		seen = u
+		return nil
+	}
+
+	require.NoError(t, c.ReserveSpend(ctx, job("job-1", "0x1", "100"), record))
+	require.NoError(t, c.ReserveSpend(ctx, job("job-2", "0x2", "50"), record))
+	assert.Equal(t, big.NewInt(100), seen.Daily)
+	assert.Equal(t, big.NewInt(0), seen.RecipientDaily)
+
+	require.NoError(t, c.ReserveSpend(ctx, job("job-3", "0X1", "10"), record))
+	assert.Equal(t, big.NewInt(150), seen.Weekly)
+	assert.Equal(t, big.NewInt(100), seen.RecipientDaily, "recipient addresses compared case-insensitively")
+
+	t.Run("retried job is not checked again", func(t *testing.T) {
+		err := c.ReserveSpend(ctx, job("job-1", "0x1", "100"), func(SpendUsage) error { return errors.New("should not be called") })
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected job is not reserved", func(t *testing.T) {
+		limit := errors.New("daily limit")
+		assert.ErrorIs(t, c.ReserveSpend(ctx, job("job-4", "0x4", "1000"), func(SpendUsage) error { return limit }), limit)
+		require.NoError(t, c.ReserveSpend(ctx, job("job-5", "0x5", "1"), record))
+		assert.Equal(t, big.NewInt(160), seen.Daily)
+	})
+
+	t.Run("release frees the amount", func(t *testing.T) {
+		require.NoError(t, c.ReleaseSpend(ctx, job("job-1", "0x1", "100")))
+		require.NoError(t, c.ReserveSpend(ctx, job("job-6", "0x6", "1"), record))
+		assert.Equal(t, big.NewInt(61), seen.Daily)
+	})
+}
@@ -0,0 +1,598 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Job status keys
+const (
+	// PayoutBatchKeyPrefix 批次任务状态 (hash: payout:batch:<user_id>:<batch_id>, job_id -> JobStatus)
+	PayoutBatchKeyPrefix = "payout:batch:"
+	// PayoutUserBatchesKeyPrefix 用户的批次索引 (zset: payout:user:<user_id>:batches, score 为创建时间)
+	PayoutUserBatchesKeyPrefix = "payout:user:"
+	// PayoutCancelledKeyPrefix 已取消批次标记 (payout:cancelled:<user_id>:<batch_id>)
+	PayoutCancelledKeyPrefix = "payout:cancelled:"
+	// PayoutCancelledJobsKeyPrefix 批次中单独取消的任务 (set: payout:cancelled_jobs:<user_id>:<batch_id>)
+	PayoutCancelledJobsKeyPrefix = "payout:cancelled_jobs:"
+	// PayoutBatchIndexKey 全部批次索引 (zset: BatchRef JSON, score 为创建时间)，供运维查询
+	PayoutBatchIndexKey = "payout:batches"
+	// PayoutBatchRefKeyPrefix 批次 ID 到所属用户的索引 (set: payout:batchref:<batch_id>)
+	PayoutBatchRefKeyPrefix = "payout:batchref:"
+	// PayoutJobRefKeyPrefix 任务 ID 到所属批次的索引 (set: payout:jobref:<job_id>, BatchRef JSON)
+	PayoutJobRefKeyPrefix = "payout:jobref:"
+)
+
+// BatchStatusTTL 批次状态保留时间
+const BatchStatusTTL = 30 * 24 * time.Hour
+
+// JobState 任务状态
+type JobState string
+
+const (
+	JobStatePending    JobState = "pending"    // 排队中
+	JobStateProcessing JobState = "processing" // 已取出，正在提交/确认
+	JobStateRetrying   JobState = "retrying"   // 失败后等待重试
+	JobStateConfirmed  JobState = "confirmed"  // 已上链确认
+	JobStateFailed     JobState = "failed"     // 进入死信队列
+	JobStateCancelled  JobState = "cancelled"  // 批次或任务被取消，未发送
+)
+
+// Terminal 是否为终态
+func (s JobState) Terminal() bool {
+	return s == JobStateConfirmed || s == JobStateFailed || s == JobStateCancelled
+}
+
+// JobStatus 任务当前状态
+type JobStatus struct {
+	ID            string    `json:"id"`
+	BatchID       string    `json:"batch_id"`
+	UserID        string    `json:"user_id"`
+	ChainID       uint64    `json:"chain_id"`
+	ToAddress     string    `json:"to_address"`
+	Amount        string    `json:"amount"`
+	TokenAddress  string    `json:"token_address"`
+	TokenSymbol   string    `json:"token_symbol,omitempty"`
+	TokenID       string    `json:"token_id,omitempty"`       // TRC10 资产 ID
+	CorrelationID string    `json:"correlation_id,omitempty"` // 跨服务关联 ID
+	State         JobState  `json:"state"`
+	TxHash        string    `json:"tx_hash,omitempty"`
+	BlockNumber   uint64    `json:"block_number,omitempty"` // 已确认交易所在区块
+	Reorged       bool      `json:"reorged,omitempty"`      // 交易曾被链重组移出 (见 FlagReorged)
+	Error         string    `json:"error,omitempty"`
+	ErrorCode     string    `json:"error_code,omitempty"` // 如 POLICY_VIOLATION
+	RetryCount    int       `json:"retry_count"`
+	GasFee        string    `json:"gas_fee,omitempty"`        // 交易上链后实际支付的网络费 (原生代币最小单位，见 GasCost)
+	GasUsed       uint64    `json:"gas_used,omitempty"`       // EVM 回执的 gasUsed
+	GasPrice      string    `json:"gas_price,omitempty"`      // EVM 回执的 effectiveGasPrice (wei)
+	UnwrapTxHash  string    `json:"unwrap_tx_hash,omitempty"` // 转账前解包 WETH/WMATIC 的交易
+	UnwrapGasFee  string    `json:"unwrap_gas_fee,omitempty"` // 解包交易的网络费
+	PermitTxHash  string    `json:"permit_tx_hash,omitempty"` // 代付前提交 EIP-2612 授权的交易
+	PermitGasFee  string    `json:"permit_gas_fee,omitempty"` // 授权交易的网络费
+	SwapTxHash    string    `json:"swap_tx_hash,omitempty"`   // 转账前经 DEX 聚合器兑换的交易
+	SwapGasFee    string    `json:"swap_gas_fee,omitempty"`   // 兑换交易 (含 approve) 的网络费合计
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// 跨链路由各环节的状态 (见 Job.Route)
+	Route *RouteStatus `json:"route,omitempty"`
+
+	// 链上失败的类别和详情 (如 NONCE_CONFLICT，见 ChainError)
+	ChainError *ChainError `json:"chain_error,omitempty"`
+
+	// 收款方退回款项的交易 (见 RecordReversal)
+	Reversal *Reversal `json:"reversal,omitempty"`
+}
+
+// Asset 转出的资产 (见 Job.Asset)
+func (s *JobStatus) Asset() string {
+	if s.TokenID != "" {
+		return s.TokenID
+	}
+	return s.TokenAddress
+}
+
+// ErrBatchNotFound 批次不存在或不属于该用户
+var ErrBatchNotFound = errors.New("batch not found")
+
+// BatchRef 批次定位 (批次 ID 由客户端生成，仅在用户内唯一)
+type BatchRef struct {
+	UserID  string `json:"user_id"`
+	BatchID string `json:"batch_id"`
+}
+
+func (r BatchRef) member() string {
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+func parseBatchRef(member string) (BatchRef, bool) {
+	var ref BatchRef
+	if err := json.Unmarshal([]byte(member), &ref); err != nil || ref.BatchID == "" {
+		return BatchRef{}, false
+	}
+	return ref, true
+}
+
+func batchKey(userID, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", PayoutBatchKeyPrefix, userID, batchID)
+}
+
+func userBatchesKey(userID string) string {
+	return PayoutUserBatchesKeyPrefix + userID + ":batches"
+}
+
+func cancelledKey(userID, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", PayoutCancelledKeyPrefix, userID, batchID)
+}
+
+func cancelledJobsKey(userID, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", PayoutCancelledJobsKeyPrefix, userID, batchID)
+}
+
+// newJobStatus 由任务构造状态记录
+func newJobStatus(job *Job, state JobState) *JobStatus {
+	return &JobStatus{
+		ID:            job.ID,
+		BatchID:       job.BatchID,
+		UserID:        job.UserID,
+		ChainID:       job.ChainID,
+		ToAddress:     job.ToAddress,
+		Amount:        job.Amount,
+		TokenAddress:  job.TokenAddress,
+		TokenSymbol:   job.TokenSymbol,
+		TokenID:       job.TokenID,
+		CorrelationID: job.CorrelationID,
+		State:         state,
+		RetryCount:    job.RetryCount,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// trackQueued 在入队的同一 pipeline 中记录待处理状态和用户批次索引
+func trackQueued(ctx context.Context, pipe redis.Pipeliner, job *Job) error {
+	data, err := json.Marshal(newJobStatus(job, JobStatePending))
+	if err != nil {
+		return err
+	}
+	key := batchKey(job.UserID, job.BatchID)
+	pipe.HSet(ctx, key, job.ID, data)
+	pipe.Expire(ctx, key, BatchStatusTTL)
+
+	createdAt := job.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	pipe.ZAddNX(ctx, userBatchesKey(job.UserID), &redis.Z{Score: float64(createdAt.Unix()), Member: job.BatchID})
+	pipe.Expire(ctx, userBatchesKey(job.UserID), BatchStatusTTL)
+
+	// 运维索引
+	ref := BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+	pipe.ZAddNX(ctx, PayoutBatchIndexKey, &redis.Z{Score: float64(createdAt.Unix()), Member: ref.member()})
+	pipe.SAdd(ctx, PayoutBatchRefKeyPrefix+job.BatchID, job.UserID)
+	pipe.Expire(ctx, PayoutBatchRefKeyPrefix+job.BatchID, BatchStatusTTL)
+	pipe.SAdd(ctx, PayoutJobRefKeyPrefix+job.ID, ref.member())
+	pipe.Expire(ctx, PayoutJobRefKeyPrefix+job.ID, BatchStatusTTL)
+	return nil
+}
+
+// setJobState 更新任务状态。状态仅用于查询，写入失败不影响任务处理。
+func (c *Consumer) setJobState(ctx context.Context, job *Job, state JobState, txHash string, cause error) error {
+	status := newJobStatus(job, state)
+	status.TxHash = txHash
+	if cause != nil {
+		status.Error = cause.Error()
+		status.ErrorCode = ErrorCode(cause)
+		status.ChainError = ChainErrorOf(cause)
+	}
+	if existing, err := c.getJobStatus(ctx, job.UserID, job.BatchID, job.ID); err == nil && existing != nil {
+		status.CreatedAt = existing.CreatedAt
+		if status.TxHash == "" {
+			status.TxHash = existing.TxHash
+		}
+		status.BlockNumber = existing.BlockNumber
+		status.Reorged = existing.Reorged
+		status.GasFee = existing.GasFee
+		status.GasUsed = existing.GasUsed
+		status.GasPrice = existing.GasPrice
+		status.UnwrapTxHash = existing.UnwrapTxHash
+		status.UnwrapGasFee = existing.UnwrapGasFee
+		status.PermitTxHash = existing.PermitTxHash
+		status.PermitGasFee = existing.PermitGasFee
+		status.SwapTxHash = existing.SwapTxHash
+		status.SwapGasFee = existing.SwapGasFee
+		status.Route = existing.Route
+		status.Reversal = existing.Reversal
+	}
+	return c.saveJobStatus(ctx, status)
+}
+
+// saveJobStatus 写入任务状态，开启账本时同时写入账本 outbox
+func (c *Consumer) saveJobStatus(ctx context.Context, status *JobStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, batchKey(status.UserID, status.BatchID), status.ID, data)
+	c.queueLedger(ctx, pipe, status)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *Consumer) getJobStatus(ctx context.Context, userID, batchID, jobID string) (*JobStatus, error) {
+	data, err := c.redis.HGet(ctx, batchKey(userID, batchID), jobID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var status JobStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// BatchJobs 返回批次内全部任务状态 (按创建时间、ID 排序)
+func (c *Consumer) BatchJobs(ctx context.Context, userID, batchID string) ([]*JobStatus, error) {
+	entries, err := c.redis.HGetAll(ctx, batchKey(userID, batchID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrBatchNotFound
+	}
+
+	statuses := make([]*JobStatus, 0, len(entries))
+	for _, data := range entries {
+		var status JobStatus
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			continue
+		}
+		statuses = append(statuses, &status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if !statuses[i].CreatedAt.Equal(statuses[j].CreatedAt) {
+			return statuses[i].CreatedAt.Before(statuses[j].CreatedAt)
+		}
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses, nil
+}
+
+// ListBatches 按创建时间范围列出批次 (最新在前)。userID 为空时列出全部用户；from/to 为零值时不限制。
+// 全局索引中超过 BatchStatusTTL 的条目会被顺带清理。
+func (c *Consumer) ListBatches(ctx context.Context, userID string, from, to time.Time) ([]BatchRef, error) {
+	rng := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if !from.IsZero() {
+		rng.Min = strconv.FormatInt(from.Unix(), 10)
+	}
+	if !to.IsZero() {
+		rng.Max = strconv.FormatInt(to.Unix(), 10)
+	}
+
+	if userID != "" {
+		ids, err := c.redis.ZRevRangeByScore(ctx, userBatchesKey(userID), rng).Result()
+		if err != nil {
+			return nil, err
+		}
+		refs := make([]BatchRef, len(ids))
+		for i, id := range ids {
+			refs[i] = BatchRef{UserID: userID, BatchID: id}
+		}
+		return refs, nil
+	}
+
+	expired := strconv.FormatInt(time.Now().Add(-BatchStatusTTL).Unix(), 10)
+	c.redis.ZRemRangeByScore(ctx, PayoutBatchIndexKey, "-inf", "("+expired)
+
+	members, err := c.redis.ZRevRangeByScore(ctx, PayoutBatchIndexKey, rng).Result()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]BatchRef, 0, len(members))
+	for _, m := range members {
+		if ref, ok := parseBatchRef(m); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// BatchOwners 返回使用该批次 ID 的用户
+func (c *Consumer) BatchOwners(ctx context.Context, batchID string) ([]string, error) {
+	return c.redis.SMembers(ctx, PayoutBatchRefKeyPrefix+batchID).Result()
+}
+
+// JobRefs 返回包含该任务 ID 的批次
+func (c *Consumer) JobRefs(ctx context.Context, jobID string) ([]BatchRef, error) {
+	members, err := c.redis.SMembers(ctx, PayoutJobRefKeyPrefix+jobID).Result()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]BatchRef, 0, len(members))
+	for _, m := range members {
+		if ref, ok := parseBatchRef(m); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// GetJobStatus 返回单个任务状态
+func (c *Consumer) GetJobStatus(ctx context.Context, ref BatchRef, jobID string) (*JobStatus, error) {
+	return c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+}
+
+// GasCost 交易上链后的实际网络费
+type GasCost struct {
+	Fee      string // 原生代币最小单位 (EVM: gasUsed × effectiveGasPrice；TRON: 燃烧的 TRX，单位 sun)
+	GasUsed  uint64 // 仅 EVM
+	GasPrice string // 仅 EVM
+}
+
+// TotalGasFee 支付交易与前置交易 (解包、授权、兑换、跨链铸造) 的网络费合计 (未记录时为 0)。
+// 跨链路由在源链上的销毁费用以源链原生代币计，不计入 (见 RouteStatus.BurnGasFee)。
+func (s *JobStatus) TotalGasFee() *big.Int {
+	total := new(big.Int)
+	fees := []string{s.GasFee, s.UnwrapGasFee, s.PermitGasFee, s.SwapGasFee}
+	if s.Route != nil {
+		fees = append(fees, s.Route.MintGasFee)
+	}
+	for _, fee := range fees {
+		if n, ok := new(big.Int).SetString(fee, 10); ok {
+			total.Add(total, n)
+		}
+	}
+	return total
+}
+
+// RecordGasFee 记录任务交易上链后的实际网络费
+func (c *Consumer) RecordGasFee(ctx context.Context, ref BatchRef, jobID string, cost GasCost) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil // 状态已过期
+	}
+	status.GasFee = cost.Fee
+	status.GasUsed = cost.GasUsed
+	status.GasPrice = cost.GasPrice
+	return c.saveJobStatus(ctx, status)
+}
+
+// recordBlockNumber 记录交易所在区块，随后的状态更新保留该值
+func (c *Consumer) recordBlockNumber(ctx context.Context, job *Job, block uint64) error {
+	status, err := c.getJobStatus(ctx, job.UserID, job.BatchID, job.ID)
+	if err != nil || status == nil {
+		return err
+	}
+	status.BlockNumber = block
+	return c.saveJobStatus(ctx, status)
+}
+
+// RecordUnwrap 记录任务的解包交易哈希或其上链后的网络费 (空值不覆盖)
+func (c *Consumer) RecordUnwrap(ctx context.Context, ref BatchRef, jobID, txHash, fee string) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil // 状态已过期
+	}
+	if txHash != "" {
+		status.UnwrapTxHash = txHash
+	}
+	if fee != "" {
+		status.UnwrapGasFee = fee
+	}
+	return c.saveJobStatus(ctx, status)
+}
+
+// RecordSwap 记录任务的兑换交易哈希 (空值不覆盖)，fee 为上链后的网络费 (兑换和 approve 交易分别累加)
+func (c *Consumer) RecordSwap(ctx context.Context, ref BatchRef, jobID, txHash, fee string) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil // 状态已过期
+	}
+	if txHash != "" {
+		status.SwapTxHash = txHash
+	}
+	if add, ok := new(big.Int).SetString(fee, 10); ok {
+		total, _ := new(big.Int).SetString(status.SwapGasFee, 10)
+		if total == nil {
+			total = new(big.Int)
+		}
+		status.SwapGasFee = total.Add(total, add).String()
+	}
+	return c.saveJobStatus(ctx, status)
+}
+
+// RecordPermit 记录任务的授权交易哈希或其上链后的网络费 (空值不覆盖)
+func (c *Consumer) RecordPermit(ctx context.Context, ref BatchRef, jobID, txHash, fee string) error {
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil // 状态已过期
+	}
+	if txHash != "" {
+		status.PermitTxHash = txHash
+	}
+	if fee != "" {
+		status.PermitGasFee = fee
+	}
+	return c.saveJobStatus(ctx, status)
+}
+
+// isCancelled 批次或该任务是否已取消
+func (c *Consumer) isCancelled(ctx context.Context, job *Job) bool {
+	pipe := c.redis.Pipeline()
+	batch := pipe.Exists(ctx, cancelledKey(job.UserID, job.BatchID))
+	single := pipe.SIsMember(ctx, cancelledJobsKey(job.UserID, job.BatchID), job.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false
+	}
+	return batch.Val() > 0 || single.Val()
+}
+
+// CancelBatch 取消批次中尚未发送的任务。
+// 先写入取消标记，worker 取出任务或重试前会检查该标记；已在处理中或已完成的任务不受影响。
+// 返回 (已取消数量, 已处理无法取消数量)。
+func (c *Consumer) CancelBatch(ctx context.Context, userID, batchID string) (int, int, error) {
+	statuses, err := c.BatchJobs(ctx, userID, batchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := c.redis.Set(ctx, cancelledKey(userID, batchID), time.Now().Unix(), BatchStatusTTL).Err(); err != nil {
+		return 0, 0, err
+	}
+	if err := c.removeUndelivered(ctx, func(job *Job) bool {
+		return job.UserID == userID && job.BatchID == batchID
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	cancelled, processed := 0, 0
+	for _, status := range statuses {
+		switch status.State {
+		case JobStatePending, JobStateRetrying:
+			if err := c.setJobState(ctx, jobFromStatus(status), JobStateCancelled, "", nil); err != nil {
+				return cancelled, processed, err
+			}
+			cancelled++
+		case JobStateCancelled:
+			cancelled++
+		default:
+			processed++
+		}
+	}
+	if target, err := c.WebhookFor(ctx, userID, batchID); err == nil {
+		c.checkBatchCompleted(ctx, userID, batchID, target)
+	}
+	return cancelled, processed, nil
+}
+
+// CancelJobsResult 按任务取消的结果
+type CancelJobsResult struct {
+	Cancelled []string // 已取消 (含之前已取消的)
+	InFlight  []string // 已取出处理或已结束，无法取消
+	NotFound  []string // 批次中没有该任务
+}
+
+// CancelJobs 取消批次中指定的、尚未发送的任务，批次其余任务照常处理。
+// 与 CancelBatch 相同，先写入任务的取消标记，再删除尚未投递的条目并更新状态。
+// 有任务不在批次中时不做任何取消，只返回 NotFound。
+func (c *Consumer) CancelJobs(ctx context.Context, userID, batchID string, jobIDs []string) (*CancelJobsResult, error) {
+	statuses, err := c.BatchJobs(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*JobStatus, len(statuses))
+	for _, status := range statuses {
+		byID[status.ID] = status
+	}
+
+	result := &CancelJobsResult{}
+	var queued []*JobStatus
+	seen := make(map[string]bool, len(jobIDs))
+	for _, id := range jobIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		status, ok := byID[id]
+		if !ok {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+		switch status.State {
+		case JobStatePending, JobStateRetrying:
+			queued = append(queued, status)
+		case JobStateCancelled:
+			result.Cancelled = append(result.Cancelled, id)
+		default:
+			result.InFlight = append(result.InFlight, id)
+		}
+	}
+	if len(result.NotFound) > 0 {
+		return &CancelJobsResult{NotFound: result.NotFound}, nil
+	}
+	if len(queued) == 0 {
+		return result, nil
+	}
+
+	key := cancelledJobsKey(userID, batchID)
+	pipe := c.redis.TxPipeline()
+	for _, status := range queued {
+		pipe.SAdd(ctx, key, status.ID)
+	}
+	pipe.Expire(ctx, key, BatchStatusTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.removeUndelivered(ctx, func(job *Job) bool {
+		return job.UserID == userID && job.BatchID == batchID && seen[job.ID]
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, status := range queued {
+		if err := c.setJobState(ctx, jobFromStatus(status), JobStateCancelled, "", nil); err != nil {
+			return nil, err
+		}
+		result.Cancelled = append(result.Cancelled, status.ID)
+	}
+	if target, err := c.WebhookFor(ctx, userID, batchID); err == nil {
+		c.checkBatchCompleted(ctx, userID, batchID, target)
+	}
+	return result, nil
+}
+
+// removeUndelivered 从各优先级通道中删除匹配的尚未投递的任务 (已投递的由 worker 按取消标记跳过)
+func (c *Consumer) removeUndelivered(ctx context.Context, match func(*Job) bool) error {
+	last, err := c.lastDelivered(ctx, priorityLanes)
+	if err != nil {
+		return err
+	}
+	for _, key := range priorityLanes {
+		msgs, err := c.undelivered(ctx, key, last[key], 0)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			raw, _ := msg.Values[streamJobField].(string)
+			var job Job
+			if err := json.Unmarshal([]byte(raw), &job); err != nil {
+				continue
+			}
+			if match(&job) {
+				c.redis.XDel(ctx, key, msg.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// jobFromStatus 由状态记录还原任务 (用于更新状态)
+func jobFromStatus(status *JobStatus) *Job {
+	return &Job{ID: status.ID, BatchID: status.BatchID, UserID: status.UserID, ChainID: status.ChainID,
+		ToAddress: status.ToAddress, Amount: status.Amount, TokenAddress: status.TokenAddress, TokenSymbol: status.TokenSymbol,
+		TokenID: status.TokenID, CorrelationID: status.CorrelationID, RetryCount: status.RetryCount, CreatedAt: status.CreatedAt}
+}
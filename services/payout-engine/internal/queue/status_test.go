@@ -0,0 +1,220 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobStatusLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	jobs := []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Amount: "200", CreatedAt: time.Now()},
+	}
+	require.NoError(t, c.PushBatch(ctx, jobs))
+
+	statuses, err := c.BatchJobs(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, JobStatePending, statuses[0].State)
+
+	require.NoError(t, c.recordBlockNumber(ctx, jobs[0], 61_000_123))
+	c.handleSuccess(ctx, jobs[0], deliver(t, c), "0xabc")
+	c.handleFailure(ctx, jobs[1], deliver(t, c), Permanent(errors.New("reverted")))
+
+	statuses, err = c.BatchJobs(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStateConfirmed, statuses[0].State)
+	assert.Equal(t, "0xabc", statuses[0].TxHash)
+	assert.EqualValues(t, 61_000_123, statuses[0].BlockNumber, "kept when the job is marked confirmed")
+	assert.Equal(t, JobStateFailed, statuses[1].State)
+	assert.Equal(t, "reverted", statuses[1].Error)
+
+	batches, err := c.ListBatches(ctx, "user-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{{UserID: "user-1", BatchID: "batch-1"}}, batches)
+
+	_, err = c.BatchJobs(ctx, "user-2", "batch-1")
+	assert.ErrorIs(t, err, ErrBatchNotFound, "batches are scoped to their owner")
+}
+
+func TestRecordSwap(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()}
+	require.NoError(t, c.PushBatch(ctx, []*Job{job}))
+	ref := BatchRef{UserID: "user-1", BatchID: "batch-1"}
+
+	// approve 与兑换交易的网络费累加，兑换交易哈希不被空值覆盖
+	require.NoError(t, c.RecordSwap(ctx, ref, job.ID, "0xswap", ""))
+	require.NoError(t, c.RecordSwap(ctx, ref, job.ID, "", "3000"))
+	require.NoError(t, c.RecordSwap(ctx, ref, job.ID, "", "7000"))
+	require.NoError(t, c.RecordGasFee(ctx, ref, job.ID, GasCost{Fee: "500"}))
+
+	status, err := c.GetJobStatus(ctx, ref, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0xswap", status.SwapTxHash)
+	assert.Equal(t, "10000", status.SwapGasFee)
+	assert.Equal(t, "10500", status.TotalGasFee().String())
+
+	p := &PendingTx{JobID: job.ID, Swap: SwapStepApprove}
+	assert.Equal(t, "job-1:swap_approve", p.Key())
+	assert.True(t, p.Prerequisite())
+}
+
+func TestCancelBatch(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	jobs := []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Amount: "200", CreatedAt: time.Now()},
+		{ID: "job-3", BatchID: "batch-2", UserID: "user-1", Amount: "300", CreatedAt: time.Now()},
+	}
+	require.NoError(t, c.PushBatch(ctx, jobs))
+
+	// job-1 已被 worker 取出并确认
+	d := deliver(t, c)
+	require.NotNil(t, d)
+	c.handleSuccess(ctx, jobs[0], d, "0xabc")
+
+	cancelled, processed, err := c.CancelBatch(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	assert.Equal(t, 1, processed)
+
+	// 只剩其他批次的任务
+	pending, err := c.PendingJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "job-3", pending[0].ID)
+
+	statuses, err := c.BatchJobs(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStateConfirmed, statuses[0].State)
+	assert.Equal(t, JobStateCancelled, statuses[1].State)
+
+	// 已取出但未处理的任务在 worker 中被跳过
+	assert.True(t, c.isCancelled(ctx, jobs[1]))
+	assert.False(t, c.isCancelled(ctx, jobs[2]))
+
+	_, _, err = c.CancelBatch(ctx, "user-1", "missing")
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+}
+
+func TestCancelJobs(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	jobs := []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Amount: "200", CreatedAt: time.Now()},
+		{ID: "job-3", BatchID: "batch-1", UserID: "user-1", Amount: "300", CreatedAt: time.Now()},
+		{ID: "job-4", BatchID: "batch-1", UserID: "user-1", Amount: "400", CreatedAt: time.Now()},
+	}
+	require.NoError(t, c.PushBatch(ctx, jobs))
+
+	// job-1 已确认，job-2 已被 worker 取出 (仍为 pending)
+	c.handleSuccess(ctx, jobs[0], deliver(t, c), "0xabc")
+	d := deliver(t, c)
+	require.Contains(t, d.raw, `"id":"job-2"`)
+
+	// 有不在批次中的任务时不做任何取消
+	result, err := c.CancelJobs(ctx, "user-1", "batch-1", []string{"job-3", "job-9"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-9"}, result.NotFound)
+	assert.Empty(t, result.Cancelled)
+	assert.False(t, c.isCancelled(ctx, jobs[2]))
+
+	result, err = c.CancelJobs(ctx, "user-1", "batch-1", []string{"job-1", "job-2", "job-3", "job-3"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-2", "job-3"}, result.Cancelled)
+	assert.Equal(t, []string{"job-1"}, result.InFlight)
+	assert.Empty(t, result.NotFound)
+
+	// job-3 从队列中删除，job-4 照常处理 (已投递的 job-2 仍在流中，等待 worker 确认)
+	pending, err := c.PendingJobs(ctx)
+	require.NoError(t, err)
+	var ids []string
+	for _, job := range pending {
+		ids = append(ids, job.ID)
+	}
+	assert.ElementsMatch(t, []string{"job-2", "job-4"}, ids)
+
+	// 已取出的 job-2 在 worker 中被跳过
+	assert.True(t, c.isCancelled(ctx, jobs[1]))
+	assert.False(t, c.isCancelled(ctx, jobs[3]))
+
+	statuses, err := c.BatchJobs(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	states := make(map[string]JobState)
+	for _, status := range statuses {
+		states[status.ID] = status.State
+	}
+	assert.Equal(t, map[string]JobState{
+		"job-1": JobStateConfirmed,
+		"job-2": JobStateCancelled,
+		"job-3": JobStateCancelled,
+		"job-4": JobStatePending,
+	}, states)
+
+	// 重复取消返回已取消
+	result, err = c.CancelJobs(ctx, "user-1", "batch-1", []string{"job-3"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-3"}, result.Cancelled)
+}
+
+func TestBatchLookupIndexes(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-1", BatchID: "batch-old", UserID: "user-1", Amount: "100", CreatedAt: old},
+	}))
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: now},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Amount: "200", CreatedAt: now},
+	}))
+	// 批次 ID 由客户端生成，不同用户可能重复
+	require.NoError(t, c.PushBatch(ctx, []*Job{
+		{ID: "job-9", BatchID: "batch-1", UserID: "user-2", Amount: "900", CreatedAt: now},
+	}))
+
+	all, err := c.ListBatches(ctx, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, BatchRef{UserID: "user-1", BatchID: "batch-old"}, all[2], "newest first")
+
+	recent, err := c.ListBatches(ctx, "user-1", now.Add(-time.Hour), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []BatchRef{{UserID: "user-1", BatchID: "batch-1"}}, recent)
+
+	owners, err := c.BatchOwners(ctx, "batch-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user-1", "user-2"}, owners)
+
+	refs, err := c.JobRefs(ctx, "job-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []BatchRef{
+		{UserID: "user-1", BatchID: "batch-old"},
+		{UserID: "user-1", BatchID: "batch-1"},
+	}, refs)
+
+	job, err := c.GetJobStatus(ctx, BatchRef{UserID: "user-2", BatchID: "batch-1"}, "job-9")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "900", job.Amount)
+
+	job, err = c.GetJobStatus(ctx, BatchRef{UserID: "user-2", BatchID: "batch-1"}, "job-1")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+}
@@ -0,0 +1,431 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// 待处理任务按优先级写入 Redis Stream，所有实例的 worker 属于同一个消费组。
+// worker 读取的条目进入消费组的待确认列表 (PEL)，处理完成后 XACK 并删除；
+// worker 崩溃时条目留在 PEL 中，租约过期后由其他 worker 认领，任务不会丢失。
+const (
+	PayoutConsumerGroup = "payout-workers"
+
+	streamJobField       = "job"
+	streamRecoveredField = "recovered" // 由旧版处理中列表迁移 (可能已开始发送)
+)
+
+// 旧版基于列表的队列，启动时迁移到 Stream
+var (
+	legacyQueueKeys     = []string{"payout:queue:urgent", "payout:queue:high", "payout:queue", "payout:queue:low"}
+	legacyProcessingKey = "payout:processing"
+)
+
+// InterruptedCode 发送过程中 worker 中断的任务进入死信时的错误码
+const InterruptedCode = "PROCESSING_INTERRUPTED"
+
+// interruptedError 认领的任务此前已开始发送，无法确认交易是否已广播
+type interruptedError struct{}
+
+func (interruptedError) Error() string {
+	return "worker stopped while the job was being sent; verify the transaction on-chain before requeueing"
+}
+func (interruptedError) ErrorCode() string { return InterruptedCode }
+
+// LeasePolicy 任务租约: 处理中的 worker 定期续约，超过 Timeout 未续约的条目可被其他 worker 认领
+type LeasePolicy struct {
+	Timeout time.Duration
+}
+
+// DefaultLeasePolicy 默认 2 分钟未续约视为 worker 已崩溃
+var DefaultLeasePolicy = LeasePolicy{Timeout: 2 * time.Minute}
+
+// LeasePolicyFromConfig 由配置构建策略，未设置的字段使用默认值
+func LeasePolicyFromConfig(cfg config.LeaseConfig) LeasePolicy {
+	p := DefaultLeasePolicy
+	if cfg.Timeout > 0 {
+		p.Timeout = cfg.Timeout
+	}
+	return p
+}
+
+// SetLeasePolicy 设置任务租约 (须在 Start 之前调用)
+func (c *Consumer) SetLeasePolicy(p LeasePolicy) {
+	c.lease = p
+}
+
+// delivery 投递给 worker 的一个条目
+type delivery struct {
+	lane      string
+	id        string
+	raw       string
+	recovered bool // 租约过期后认领或由旧版处理中列表迁移 (之前的 worker 可能已开始处理)
+}
+
+// consumerName 实例名 (主机名-进程号)，各 worker 以 <实例名>-<编号> 作为消费者名
+func consumerName() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "payout-engine"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// ensureStreams 创建各通道的消费组，并迁移旧版列表中的任务
+func (c *Consumer) ensureStreams(ctx context.Context) error {
+	for _, lane := range priorityLanes {
+		err := c.redis.XGroupCreateMkStream(ctx, lane, PayoutConsumerGroup, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("failed to create consumer group on %s: %w", lane, err)
+		}
+	}
+	return c.migrateLegacyQueue(ctx)
+}
+
+// moveScript 列表末尾仍为 ARGV[1] 时将其移入 Stream (KEYS[1] 列表, KEYS[2] Stream)，
+// ARGV[2] 为 "1" 时标记为需核对的条目。并发移动时只有一个成功。
+var moveScript = redis.NewScript(`
+if redis.call('LINDEX', KEYS[1], -1) ~= ARGV[1] then
+	return 0
+end
+redis.call('RPOP', KEYS[1])
+if ARGV[2] == '1' then
+	redis.call('XADD', KEYS[2], '*', 'job', ARGV[1], 'recovered', '1')
+else
+	redis.call('XADD', KEYS[2], '*', 'job', ARGV[1])
+end
+return 1
+`)
+
+// drainList 将列表中的任务按原顺序移入各自优先级的 Stream，返回移动的数量
+func (c *Consumer) drainList(ctx context.Context, key string, recovered bool) (int, error) {
+	flag := "0"
+	if recovered {
+		flag = "1"
+	}
+	moved := 0
+	for {
+		raw, err := c.redis.LIndex(ctx, key, -1).Result()
+		if err == redis.Nil {
+			return moved, nil
+		}
+		if err != nil {
+			return moved, err
+		}
+		n, err := moveScript.Run(ctx, c.redis, []string{key, rawLaneKey(raw)}, raw, flag).Int()
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+	}
+}
+
+// migrateLegacyQueue 迁移旧版列表队列。处理中列表的任务可能已开始发送，按认领的条目核对后再处理。
+func (c *Consumer) migrateLegacyQueue(ctx context.Context) error {
+	total := 0
+	for _, key := range legacyQueueKeys {
+		n, err := c.drainList(ctx, key, false)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", key, err)
+		}
+		total += n
+	}
+	n, err := c.drainList(ctx, legacyProcessingKey, true)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", legacyProcessingKey, err)
+	}
+	if total+n > 0 {
+		log.Info().Int("queued", total).Int("processing", n).Msg("Migrated legacy list queue to streams")
+	}
+	return nil
+}
+
+// next 取下一个条目: 先认领租约过期的条目，再按通道顺序读取新条目。均为空时返回 redis.Nil。
+func (c *Consumer) next(ctx context.Context, consumer string) (*delivery, error) {
+	if d, err := c.claimStale(ctx, consumer); d != nil || err != nil {
+		return d, err
+	}
+	for _, lane := range c.laneOrder(ctx, time.Now()) {
+		streams, err := c.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    PayoutConsumerGroup,
+			Consumer: consumer,
+			Streams:  []string{lane, ">"},
+			Count:    1,
+			Block:    -1,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				return newDelivery(lane, msg), nil
+			}
+		}
+	}
+	return nil, redis.Nil
+}
+
+func newDelivery(lane string, msg redis.XMessage) *delivery {
+	raw, _ := msg.Values[streamJobField].(string)
+	_, recovered := msg.Values[streamRecoveredField]
+	return &delivery{lane: lane, id: msg.ID, raw: raw, recovered: recovered}
+}
+
+// claimStale 认领一个租约过期的条目 (其 worker 已崩溃)。没有过期条目时在半个租约内不再检查。
+func (c *Consumer) claimStale(ctx context.Context, consumer string) (*delivery, error) {
+	c.claimMu.Lock()
+	if time.Now().Before(c.nextClaim) {
+		c.claimMu.Unlock()
+		return nil, nil
+	}
+	c.nextClaim = time.Now().Add(c.lease.Timeout / 2)
+	c.claimMu.Unlock()
+
+	for _, lane := range priorityLanes {
+		pending, err := c.redis.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: lane,
+			Group:  PayoutConsumerGroup,
+			Start:  "-",
+			End:    "+",
+			Count:  100,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pending {
+			if p.Idle < c.lease.Timeout {
+				continue
+			}
+			// XCLAIM 重新检查空闲时间，与续约或其他 worker 的认领不会同时成功
+			ids, err := c.redis.XClaimJustID(ctx, &redis.XClaimArgs{
+				Stream:   lane,
+				Group:    PayoutConsumerGroup,
+				Consumer: consumer,
+				MinIdle:  c.lease.Timeout,
+				Messages: []string{p.ID},
+			}).Result()
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			msgs, err := c.redis.XRange(ctx, lane, p.ID, p.ID).Result()
+			if err != nil {
+				return nil, err
+			}
+			if len(msgs) == 0 {
+				// 条目已删除 (如批次取消)，只清理 PEL
+				c.redis.XAck(ctx, lane, PayoutConsumerGroup, p.ID)
+				continue
+			}
+			log.Warn().
+				Str("entry_id", p.ID).
+				Str("previous_consumer", p.Consumer).
+				Dur("idle", p.Idle).
+				Int64("deliveries", p.RetryCount).
+				Msg("Claimed job from a stalled worker")
+			c.claimMu.Lock()
+			c.nextClaim = time.Time{} // 可能还有其他过期条目
+			c.claimMu.Unlock()
+			d := newDelivery(lane, msgs[0])
+			d.recovered = true
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// errLeaseLost 租约已过期并被其他 worker 认领
+var errLeaseLost = errors.New("job lease lost to another worker")
+
+// renewScript 条目仍在 ARGV[2] 的待确认列表中时重置其空闲时间 (KEYS[1] Stream,
+// ARGV[1] 消费组, ARGV[3] 条目 ID)，返回 0 表示已被其他 worker 认领或已确认。
+var renewScript = redis.NewScript(`
+if #redis.call('XPENDING', KEYS[1], ARGV[1], ARGV[3], ARGV[3], 1, ARGV[2]) == 0 then
+	return 0
+end
+redis.call('XCLAIM', KEYS[1], ARGV[1], ARGV[2], 0, ARGV[3], 'JUSTID')
+return 1
+`)
+
+// keepLease 处理期间定期续约。返回的 context 在失去租约时取消 (原因为 errLeaseLost)，
+// 任务应停止处理并交给认领的 worker；另返回停止续约的函数。
+func (c *Consumer) keepLease(ctx context.Context, consumer string, d *delivery) (context.Context, func()) {
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.lease.Timeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+				held, err := renewScript.Run(leaseCtx, c.redis, []string{d.lane}, PayoutConsumerGroup, consumer, d.id).Int()
+				if err != nil {
+					log.Warn().Err(err).Str("entry_id", d.id).Msg("Failed to renew job lease")
+					continue
+				}
+				if held == 0 {
+					log.Error().Str("entry_id", d.id).Str("consumer", consumer).Msg("Job lease lost, stopping the job")
+					cancel(errLeaseLost)
+					return
+				}
+			}
+		}
+	}()
+	return leaseCtx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
+
+// leaseLost 处理期间租约已被其他 worker 认领
+func leaseLost(ctx context.Context) bool {
+	return context.Cause(ctx) == errLeaseLost
+}
+
+// ack 确认并删除条目
+func (c *Consumer) ack(ctx context.Context, pipe redis.Pipeliner, d *delivery) {
+	pipe.XAck(ctx, d.lane, PayoutConsumerGroup, d.id)
+	pipe.XDel(ctx, d.lane, d.id)
+}
+
+// finish 条目处理完毕，从 Stream 中移除
+func (c *Consumer) finish(ctx context.Context, d *delivery) {
+	pipe := c.redis.TxPipeline()
+	c.ack(ctx, pipe, d)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Str("entry_id", d.id).Msg("Failed to ack job")
+	}
+}
+
+// requeue 将任务作为新条目重新入队并移除原条目 (原子操作，不会丢失或重复)
+func (c *Consumer) requeue(ctx context.Context, job *Job, d *delivery) error {
+	job.QueuedAt = time.Now()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	pipe := c.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: laneKey(job.Priority), Values: []interface{}{streamJobField, data}})
+	c.ack(ctx, pipe, d)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// settleRecovered 核对认领的条目。之前的 worker 已开始发送时不能再次发送 (交易可能已广播):
+// 有已广播的交易记录时按成功处理，否则进入死信由人工核对链上后重新入队。
+// 返回 true 表示条目已处理完毕 (或暂时无法核对，留待之后再认领)。
+func (c *Consumer) settleRecovered(ctx context.Context, job *Job, d *delivery) bool {
+	status, err := c.getJobStatus(ctx, job.UserID, job.BatchID, job.ID)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to check recovered job, leaving it pending")
+		return true
+	}
+	switch {
+	case status == nil || status.State == JobStatePending || status.State == JobStateRetrying:
+		return false // 尚未开始发送，正常处理
+	case status.State.Terminal():
+		// 已完成，崩溃发生在确认条目之前
+		c.finish(ctx, d)
+		return true
+	}
+
+	data, err := c.redis.HGet(ctx, PayoutPendingTxKey, job.ID).Result()
+	if err != nil && err != redis.Nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to check recovered job, leaving it pending")
+		return true
+	}
+	if err == nil {
+		var p PendingTx
+		if json.Unmarshal([]byte(data), &p) == nil && p.TxHash != "" {
+			log.Warn().Str("job_id", job.ID).Str("tx_hash", p.TxHash).Msg("Recovered job was already broadcast")
+			c.handleSuccess(ctx, job, d, p.TxHash)
+			return true
+		}
+	}
+
+	cause := Permanent(interruptedError{})
+	log.Error().Str("job_id", job.ID).Str("batch_id", job.BatchID).Msg("Recovered job was interrupted while sending, moving to dead letter queue")
+	if err := c.moveToDeadLetter(ctx, job, cause); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to write dead letter")
+		return true
+	}
+	c.updateState(ctx, job, JobStateFailed, "", cause)
+	c.finish(ctx, d)
+	return true
+}
+
+// lastDelivered 各通道消费组最后投递的条目 ID (之后的条目尚未投递)。
+// 直接解析 XINFO GROUPS，兼容不同 Redis 版本的字段数。
+func (c *Consumer) lastDelivered(ctx context.Context, lanes []string) (map[string]string, error) {
+	pipe := c.redis.Pipeline()
+	cmds := make([]*redis.Cmd, len(lanes))
+	for i, lane := range lanes {
+		cmds[i] = pipe.Do(ctx, "XINFO", "GROUPS", lane)
+	}
+	pipe.Exec(ctx)
+
+	out := make(map[string]string, len(lanes))
+	for i, cmd := range cmds {
+		groups, err := cmd.Slice()
+		if err != nil {
+			if strings.Contains(err.Error(), "no such key") {
+				out[lanes[i]] = "0"
+				continue
+			}
+			return nil, err
+		}
+		out[lanes[i]] = "0"
+		for _, g := range groups {
+			fields, _ := g.([]interface{})
+			info := make(map[string]string, len(fields)/2)
+			for j := 0; j+1 < len(fields); j += 2 {
+				key, _ := fields[j].(string)
+				value, _ := fields[j+1].(string)
+				info[key] = value
+			}
+			if info["name"] == PayoutConsumerGroup && info["last-delivered-id"] != "" {
+				out[lanes[i]] = info["last-delivered-id"]
+			}
+		}
+	}
+	return out, nil
+}
+
+// undelivered 通道中尚未投递的条目 (最多 count 条，0 为全部)
+func (c *Consumer) undelivered(ctx context.Context, lane, last string, count int64) ([]redis.XMessage, error) {
+	var msgs []redis.XMessage
+	var err error
+	if count > 0 {
+		msgs, err = c.redis.XRangeN(ctx, lane, last, "+", count+1).Result()
+	} else {
+		msgs, err = c.redis.XRange(ctx, lane, last, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) > 0 && msgs[0].ID == last {
+		msgs = msgs[1:]
+	}
+	if count > 0 && int64(len(msgs)) > count {
+		msgs = msgs[:count]
+	}
+	return msgs, nil
+}
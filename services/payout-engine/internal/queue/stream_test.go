@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamRecovery(t *testing.T) {
+	ctx := context.Background()
+	lease := 60 * time.Millisecond
+
+	// crashed 模拟 worker 读取条目后崩溃，返回租约过期后由另一个 worker 认领的条目
+	crashed := func(t *testing.T, c *Consumer, job *Job, state JobState) *delivery {
+		require.NoError(t, c.Push(ctx, job))
+		first, err := c.next(ctx, "crashed-0")
+		require.NoError(t, err)
+		if state != JobStatePending {
+			require.NoError(t, c.setJobState(ctx, job, state, "", nil))
+		}
+
+		_, err = c.next(ctx, "healthy-0")
+		assert.Equal(t, redis.Nil, err, "lease still held")
+		time.Sleep(2 * lease)
+		d, err := c.next(ctx, "healthy-0")
+		require.NoError(t, err)
+		assert.Equal(t, first.id, d.id)
+		assert.True(t, d.recovered)
+		return d
+	}
+	newConsumer := func(t *testing.T) *Consumer {
+		c := newTestConsumer(t)
+		c.lease = LeasePolicy{Timeout: lease}
+		return c
+	}
+	mustNotSend := func(t *testing.T) ProcessFunc {
+		return func(context.Context, *Job) (*JobResult, error) {
+			t.Fatal("recovered job must not be sent again")
+			return nil, nil
+		}
+	}
+
+	t.Run("job not yet started is processed", func(t *testing.T) {
+		c := newConsumer(t)
+		job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}
+		d := crashed(t, c, job, JobStatePending)
+
+		sent := 0
+		c.process(ctx, 0, d, func(ctx context.Context, job *Job) (*JobResult, error) {
+			sent++
+			return &JobResult{JobID: job.ID, Success: true, TxHash: "0xabc"}, nil
+		})
+		assert.Equal(t, 1, sent)
+		status, _ := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+		assert.Equal(t, JobStateConfirmed, status.State)
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.Zero(t, processing)
+	})
+
+	t.Run("broadcast job is settled from its pending tx", func(t *testing.T) {
+		c := newConsumer(t)
+		job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}
+		d := crashed(t, c, job, JobStateProcessing)
+		require.NoError(t, c.TrackPendingTx(ctx, &PendingTx{JobID: "job-1", TxHash: "0xabc"}))
+
+		c.process(ctx, 0, d, mustNotSend(t))
+		status, _ := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+		assert.Equal(t, JobStateConfirmed, status.State)
+		assert.Equal(t, "0xabc", status.TxHash)
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.Zero(t, processing)
+	})
+
+	t.Run("interrupted job goes to dead letter", func(t *testing.T) {
+		c := newConsumer(t)
+		job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}
+		d := crashed(t, c, job, JobStateProcessing)
+
+		c.process(ctx, 0, d, mustNotSend(t))
+		entries, total, err := c.ListDeadLetters(ctx, "batch-1", 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		assert.Equal(t, InterruptedCode, entries[0].ErrorCode)
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.Zero(t, processing)
+	})
+
+	t.Run("finished job is only acked", func(t *testing.T) {
+		c := newConsumer(t)
+		job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}
+		d := crashed(t, c, job, JobStateConfirmed)
+
+		c.process(ctx, 0, d, mustNotSend(t))
+		processing, _ := c.GetProcessingCount(ctx)
+		assert.Zero(t, processing)
+	})
+
+	t.Run("live worker keeps its lease", func(t *testing.T) {
+		c := newConsumer(t)
+		require.NoError(t, c.Push(ctx, &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}))
+		d, err := c.next(ctx, "busy-0")
+		require.NoError(t, err)
+		leaseCtx, stop := c.keepLease(ctx, "busy-0", d)
+		defer stop()
+
+		time.Sleep(3 * lease)
+		c.nextClaim = time.Time{}
+		_, err = c.next(ctx, "healthy-0")
+		assert.Equal(t, redis.Nil, err)
+		assert.NoError(t, leaseCtx.Err())
+	})
+
+	t.Run("worker stops once its lease is claimed", func(t *testing.T) {
+		c := newConsumer(t)
+		job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1"}
+		require.NoError(t, c.Push(ctx, job))
+		d, err := c.next(ctx, "busy-0")
+		require.NoError(t, err)
+		leaseCtx, stop := c.keepLease(ctx, "busy-0", d)
+		defer stop()
+
+		// 另一个 worker 在续约之前认领了条目 (如 busy-0 曾长时间停顿)
+		require.NoError(t, c.redis.XClaim(ctx, &redis.XClaimArgs{
+			Stream: d.lane, Group: PayoutConsumerGroup, Consumer: "healthy-0", Messages: []string{d.id},
+		}).Err())
+
+		c.process(leaseCtx, 0, d, func(ctx context.Context, job *Job) (*JobResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		assert.ErrorIs(t, context.Cause(leaseCtx), errLeaseLost)
+
+		pending, err := c.redis.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: d.lane, Group: PayoutConsumerGroup, Start: "-", End: "+", Count: 10,
+		}).Result()
+		require.NoError(t, err)
+		require.Len(t, pending, 1, "left to the worker that claimed it")
+		assert.Equal(t, "healthy-0", pending[0].Consumer)
+		status, _ := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+		assert.Equal(t, JobStateProcessing, status.State)
+		_, total, err := c.ListDeadLetters(ctx, "batch-1", 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestMigrateLegacyQueue(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	queued, _ := json.Marshal(&Job{ID: "job-1", Priority: "LOW"})
+	inFlight, _ := json.Marshal(&Job{ID: "job-2"})
+	require.NoError(t, c.redis.LPush(ctx, "payout:queue:low", queued).Err())
+	require.NoError(t, c.redis.LPush(ctx, legacyProcessingKey, inFlight).Err())
+	require.NoError(t, c.ensureStreams(ctx))
+
+	n, err := c.GetQueueLength(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	legacy, _ := c.redis.Exists(ctx, "payout:queue:low", legacyProcessingKey).Result()
+	assert.Zero(t, legacy)
+
+	// 旧版处理中的任务需核对后才能处理
+	d := deliver(t, c)
+	assert.Equal(t, PayoutQueueKey, d.lane)
+	assert.True(t, d.recovered)
+	d = deliver(t, c)
+	assert.Equal(t, PayoutQueueLowKey, d.lane)
+	assert.False(t, d.recovered)
+}
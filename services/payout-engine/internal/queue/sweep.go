@@ -0,0 +1,16 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PayoutSweepKeyPrefix 归集标记 (string: chain_id:asset)，过期前不再归集该资产
+const PayoutSweepKeyPrefix = "payout:sweep:"
+
+// ClaimSweep 获取资产的归集权。多实例下同一资产每个周期只入队一笔归集任务。
+func (c *Consumer) ClaimSweep(ctx context.Context, chainID uint64, asset string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%d:%s", PayoutSweepKeyPrefix, chainID, asset)
+	return c.redis.SetNX(ctx, key, time.Now().Unix(), ttl).Result()
+}
@@ -0,0 +1,27 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimSweep(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	claimed, err := c.ClaimSweep(ctx, 1, "native", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = c.ClaimSweep(ctx, 1, "native", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, claimed, "one sweep per asset per interval")
+
+	claimed, err = c.ClaimSweep(ctx, 1, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultTokenMetadataTTL 代币元数据缓存时间 (合约的 symbol/decimals 通常不会变化)
+const DefaultTokenMetadataTTL = 24 * time.Hour
+
+// TokenMetadata 从链上读取的代币 symbol 和 decimals
+type TokenMetadata struct {
+	Symbol     string    `json:"symbol"`
+	Decimals   uint32    `json:"decimals"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// tokenMetadataKey payout:token:<chain_id>:<asset> (asset 由调用方规范化)
+func tokenMetadataKey(chainID uint64, asset string) string {
+	return fmt.Sprintf("payout:token:%d:%s", chainID, asset)
+}
+
+// GetTokenMetadata 返回缓存的代币元数据 (未缓存或已过期时为 nil)
+func (c *Consumer) GetTokenMetadata(ctx context.Context, chainID uint64, asset string) (*TokenMetadata, error) {
+	data, err := c.redis.Get(ctx, tokenMetadataKey(chainID, asset)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta TokenMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// SetTokenMetadata 缓存代币元数据 (ttl 为 0 时使用 DefaultTokenMetadataTTL)
+func (c *Consumer) SetTokenMetadata(ctx context.Context, chainID uint64, asset string, meta *TokenMetadata, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = DefaultTokenMetadataTTL
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, tokenMetadataKey(chainID, asset), data, ttl).Err()
+}
+
+// DeleteTokenMetadata 清除缓存的代币元数据 (合约升级后强制重新读取)
+func (c *Consumer) DeleteTokenMetadata(ctx context.Context, chainID uint64, asset string) error {
+	return c.redis.Del(ctx, tokenMetadataKey(chainID, asset)).Err()
+}
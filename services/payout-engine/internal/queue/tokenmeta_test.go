@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenMetadata(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	usdc := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+	meta, err := c.GetTokenMetadata(ctx, 1, usdc)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	require.NoError(t, c.SetTokenMetadata(ctx, 1, usdc, &TokenMetadata{Symbol: "USDC", Decimals: 6, ResolvedAt: time.Now()}, 0))
+	meta, err = c.GetTokenMetadata(ctx, 1, usdc)
+	require.NoError(t, err)
+	require.NotNil(t, meta)
+	assert.Equal(t, "USDC", meta.Symbol)
+	assert.EqualValues(t, 6, meta.Decimals)
+
+	ttl, err := c.redis.TTL(ctx, tokenMetadataKey(1, usdc)).Result()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTokenMetadataTTL, ttl)
+
+	// 按链隔离
+	meta, err = c.GetTokenMetadata(ctx, 56, usdc)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	require.NoError(t, c.DeleteTokenMetadata(ctx, 1, usdc))
+	meta, err = c.GetTokenMetadata(ctx, 1, usdc)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+}
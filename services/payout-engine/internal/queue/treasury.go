@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PayoutTreasuryApprovalsKey hash: approval id -> TreasuryApproval
+const PayoutTreasuryApprovalsKey = "payout:treasury:approvals"
+
+// TreasuryApprovalStatus 金库授权请求的状态
+type TreasuryApprovalStatus string
+
+const (
+	TreasuryApprovalPending    TreasuryApprovalStatus = "pending"    // 等待金库执行 approve
+	TreasuryApprovalFulfilled  TreasuryApprovalStatus = "fulfilled"  // 链上额度已达到请求的额度
+	TreasuryApprovalSuperseded TreasuryApprovalStatus = "superseded" // 同一代币有更新的请求 (approve 为设置额度，以最新的为准)
+)
+
+// TreasuryApproval 请金库对付款地址授权代币额度: 金库管理方以合约 (如多签) 执行 To 上的 Data 调用
+type TreasuryApproval struct {
+	ID          string                 `json:"id"`
+	ChainID     uint64                 `json:"chain_id"`
+	Treasury    string                 `json:"treasury"`
+	Token       string                 `json:"token"`
+	Spender     string                 `json:"spender"`
+	Amount      string                 `json:"amount"` // 请求设置的授权额度 (代币最小单位)
+	To          string                 `json:"to"`     // 调用目标 (代币合约)
+	Data        string                 `json:"data"`   // approve(spender, amount) 调用数据
+	Reason      string                 `json:"reason,omitempty"`
+	Status      TreasuryApprovalStatus `json:"status"`
+	CreatedAt   time.Time              `json:"created_at"`
+	FulfilledAt *time.Time             `json:"fulfilled_at,omitempty"`
+}
+
+// sameAllowance 两个请求针对同一授权额度 (金库、代币、付款地址)
+func (a *TreasuryApproval) sameAllowance(b *TreasuryApproval) bool {
+	return a.ChainID == b.ChainID && strings.EqualFold(a.Treasury, b.Treasury) &&
+		strings.EqualFold(a.Token, b.Token) && strings.EqualFold(a.Spender, b.Spender)
+}
+
+// CreateTreasuryApproval 记录新的授权请求，同一授权额度上仍在等待的旧请求标记为 superseded
+func (c *Consumer) CreateTreasuryApproval(ctx context.Context, approval *TreasuryApproval) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	approval.ID = "tap_" + hex.EncodeToString(id)
+	approval.Status = TreasuryApprovalPending
+	approval.CreatedAt = time.Now()
+
+	existing, err := c.ListTreasuryApprovals(ctx, approval.ChainID)
+	if err != nil {
+		return err
+	}
+	for _, old := range existing {
+		if old.Status == TreasuryApprovalPending && old.sameAllowance(approval) {
+			old.Status = TreasuryApprovalSuperseded
+			if err := c.SaveTreasuryApproval(ctx, old); err != nil {
+				return err
+			}
+		}
+	}
+	return c.SaveTreasuryApproval(ctx, approval)
+}
+
+// SaveTreasuryApproval 更新授权请求 (如标记为 fulfilled)
+func (c *Consumer) SaveTreasuryApproval(ctx context.Context, approval *TreasuryApproval) error {
+	data, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+	return c.redis.HSet(ctx, PayoutTreasuryApprovalsKey, approval.ID, data).Err()
+}
+
+// ListTreasuryApprovals 链的授权请求 (chainID 为 0 时返回所有链)，最新的在前
+func (c *Consumer) ListTreasuryApprovals(ctx context.Context, chainID uint64) ([]*TreasuryApproval, error) {
+	values, err := c.redis.HVals(ctx, PayoutTreasuryApprovalsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*TreasuryApproval, 0, len(values))
+	for _, v := range values {
+		var approval TreasuryApproval
+		if err := json.Unmarshal([]byte(v), &approval); err != nil {
+			continue
+		}
+		if chainID != 0 && approval.ChainID != chainID {
+			continue
+		}
+		out = append(out, &approval)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
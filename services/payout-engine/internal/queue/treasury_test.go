@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreasuryApprovals(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	usdc := func(amount string) *TreasuryApproval {
+		return &TreasuryApproval{ChainID: 8453, Treasury: "0xTreasury", Token: "0xUSDC", Spender: "0xPayer", Amount: amount}
+	}
+	first := usdc("1000")
+	require.NoError(t, c.CreateTreasuryApproval(ctx, first))
+	assert.NotEmpty(t, first.ID)
+	other := &TreasuryApproval{ChainID: 8453, Treasury: "0xTreasury", Token: "0xDAI", Spender: "0xPayer", Amount: "5"}
+	require.NoError(t, c.CreateTreasuryApproval(ctx, other))
+	require.NoError(t, c.CreateTreasuryApproval(ctx, &TreasuryApproval{ChainID: 1, Treasury: "0xTreasury", Token: "0xUSDC", Spender: "0xPayer", Amount: "7"}))
+
+	// approve 设置额度: 同一代币的新请求取代仍在等待的旧请求
+	second := usdc("2500")
+	second.Treasury, second.Token = "0xtreasury", "0xusdc"
+	require.NoError(t, c.CreateTreasuryApproval(ctx, second))
+
+	approvals, err := c.ListTreasuryApprovals(ctx, 8453)
+	require.NoError(t, err)
+	require.Len(t, approvals, 3)
+	assert.Equal(t, second.ID, approvals[0].ID)
+	status := map[string]TreasuryApprovalStatus{}
+	for _, a := range approvals {
+		status[a.ID] = a.Status
+	}
+	assert.Equal(t, TreasuryApprovalSuperseded, status[first.ID])
+	assert.Equal(t, TreasuryApprovalPending, status[second.ID])
+	assert.Equal(t, TreasuryApprovalPending, status[other.ID])
+
+	all, err := c.ListTreasuryApprovals(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+}
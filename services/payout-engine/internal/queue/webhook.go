@@ -0,0 +1,336 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/protocol-bank/payout-engine/internal/eventschema"
+	"github.com/rs/zerolog/log"
+)
+
+// Webhook 相关 Redis 键
+const (
+	PayoutWebhookKeyPrefix     = "payout:webhook:batch:" // 批次 webhook 注册 (hash: url, secret)
+	PayoutWebhookDoneKeyPrefix = "payout:webhook_done:"  // 批次结束已处理标记 (batch.completed、报告)
+	PayoutWebhookEventsKey     = "payout:webhook:events" // hash: event_id -> WebhookDelivery
+	PayoutWebhookOutboxKey     = "payout:webhook:outbox" // zset: event_id -> 下次投递时间 (unix ms)
+)
+
+// Webhook 事件类型
+const (
+	EventJobSent        = "job.sent"        // 交易已广播
+	EventJobConfirmed   = "job.confirmed"   // 交易已上链
+	EventJobFailed      = "job.failed"      // 任务最终失败 (进入死信队列)
+	EventJobReorged     = "job.reorged"     // 交易所在区块被重组移出，等待重新上链
+	EventJobReversed    = "job.reversed"    // 收款方退回了款项 (见 RecordReversal)
+	EventBatchCompleted = "batch.completed" // 批次内所有任务已结束
+)
+
+// WebhookRetryPolicy 投递失败的退避策略，超过次数后丢弃
+var WebhookRetryPolicy = RetryPolicy{
+	MaxRetries:     10,
+	InitialBackoff: 5 * time.Second,
+	MaxBackoff:     30 * time.Minute,
+	Multiplier:     2,
+}
+
+// WebhookEvent 出站事件
+type WebhookEvent struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	CreatedAt time.Time    `json:"created_at"`
+	UserID    string       `json:"user_id"`
+	BatchID   string       `json:"batch_id"`
+	Job       *JobStatus   `json:"job,omitempty"`
+	Batch     *BatchTotals `json:"batch,omitempty"`
+
+	// ManifestHash 提交时生成的任务清单哈希 (batch.completed)，供提交方核对执行内容
+	ManifestHash string `json:"manifest_hash,omitempty"`
+
+	// CorrelationID 提交请求的关联 ID，投递时同时写入 X-Correlation-ID 请求头
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// BatchTotals batch.completed 事件的任务统计
+type BatchTotals struct {
+	Total     int `json:"total"`
+	Sent      int `json:"sent"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// WebhookDelivery 待投递事件
+type WebhookDelivery struct {
+	Event    WebhookEvent `json:"event"`
+	Attempts int          `json:"attempts"`
+	LastErr  string       `json:"last_error,omitempty"`
+}
+
+// WebhookTarget 批次的 webhook 注册
+type WebhookTarget struct {
+	URL           string
+	Secret        string
+	SchemaVersion int // 事件 payload 版本 (eventschema.V1 / V2)
+}
+
+// webhookEventV1 schema v1: 原有扁平结构，附带版本号
+type webhookEventV1 struct {
+	WebhookEvent
+	SchemaVersion int `json:"schema_version"`
+}
+
+// webhookEventV2 schema v2: 事件信封，业务字段在 data 中
+type webhookEventV2 struct {
+	ID            string           `json:"id"`
+	Type          string           `json:"type"`
+	SchemaVersion int              `json:"schema_version"`
+	CreatedAt     time.Time        `json:"created_at"`
+	CorrelationID string           `json:"correlation_id,omitempty"`
+	Data          webhookEventData `json:"data"`
+}
+
+type webhookEventData struct {
+	UserID       string       `json:"user_id"`
+	BatchID      string       `json:"batch_id"`
+	Job          *JobStatus   `json:"job,omitempty"`
+	Batch        *BatchTotals `json:"batch,omitempty"`
+	ManifestHash string       `json:"manifest_hash,omitempty"`
+}
+
+// EncodeWebhookEvent 按注册时选定的 schema 版本序列化事件
+func EncodeWebhookEvent(event WebhookEvent, version int) ([]byte, error) {
+	switch version {
+	case eventschema.V1:
+		return json.Marshal(webhookEventV1{WebhookEvent: event, SchemaVersion: version})
+	case eventschema.V2:
+		return json.Marshal(webhookEventV2{
+			ID:            event.ID,
+			Type:          event.Type,
+			SchemaVersion: version,
+			CreatedAt:     event.CreatedAt,
+			CorrelationID: event.CorrelationID,
+			Data: webhookEventData{
+				UserID:       event.UserID,
+				BatchID:      event.BatchID,
+				Job:          event.Job,
+				Batch:        event.Batch,
+				ManifestHash: event.ManifestHash,
+			},
+		})
+	}
+	return nil, fmt.Errorf("unsupported webhook schema version %d", version)
+}
+
+func webhookKey(userID, batchID string) string {
+	return PayoutWebhookKeyPrefix + userID + ":" + batchID
+}
+
+// RegisterWebhook 为批次注册状态回调
+func (c *Consumer) RegisterWebhook(ctx context.Context, userID, batchID string, target WebhookTarget) error {
+	key := webhookKey(userID, batchID)
+	pipe := c.redis.TxPipeline()
+	version := target.SchemaVersion
+	if version == 0 {
+		version = eventschema.V1
+	}
+	pipe.HSet(ctx, key, "url", target.URL, "secret", target.Secret, "schema_version", version)
+	pipe.Expire(ctx, key, BatchStatusTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// WebhookFor 返回批次的 webhook 注册，未注册时返回 nil
+func (c *Consumer) WebhookFor(ctx context.Context, userID, batchID string) (*WebhookTarget, error) {
+	values, err := c.redis.HGetAll(ctx, webhookKey(userID, batchID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if values["url"] == "" {
+		return nil, nil
+	}
+	// 版本化之前的注册没有 schema_version，按 v1 投递
+	version, err := strconv.Atoi(values["schema_version"])
+	if err != nil || !eventschema.Supported(version) {
+		version = eventschema.V1
+	}
+	return &WebhookTarget{URL: values["url"], Secret: values["secret"], SchemaVersion: version}, nil
+}
+
+// notifyState 任务进入终态时为已注册 webhook 的批次写入事件，并检查批次是否结束
+func (c *Consumer) notifyState(ctx context.Context, job *Job, state JobState) {
+	var eventType string
+	switch state {
+	case JobStateConfirmed:
+		eventType = EventJobSent
+	case JobStateFailed:
+		eventType = EventJobFailed
+	case JobStateCancelled:
+	default:
+		return
+	}
+
+	target, err := c.WebhookFor(ctx, job.UserID, job.BatchID)
+	if err != nil {
+		return
+	}
+	if target != nil && eventType != "" {
+		if status, err := c.getJobStatus(ctx, job.UserID, job.BatchID, job.ID); err == nil && status != nil {
+			c.enqueueEvent(ctx, WebhookEvent{Type: eventType, UserID: job.UserID, BatchID: job.BatchID, Job: status, CorrelationID: status.CorrelationID})
+		}
+	}
+	c.checkBatchCompleted(ctx, job.UserID, job.BatchID, target)
+}
+
+// EmitJobConfirmed 交易上链后发出 job.confirmed
+func (c *Consumer) EmitJobConfirmed(ctx context.Context, ref BatchRef, jobID string) {
+	target, err := c.WebhookFor(ctx, ref.UserID, ref.BatchID)
+	if err != nil || target == nil {
+		return
+	}
+	status, err := c.getJobStatus(ctx, ref.UserID, ref.BatchID, jobID)
+	if err != nil || status == nil {
+		return
+	}
+	c.enqueueEvent(ctx, WebhookEvent{Type: EventJobConfirmed, UserID: ref.UserID, BatchID: ref.BatchID, Job: status, CorrelationID: status.CorrelationID})
+}
+
+// checkBatchCompleted 批次内所有任务结束时发出一次 batch.completed (已注册 webhook 时) 并排队生成报告
+func (c *Consumer) checkBatchCompleted(ctx context.Context, userID, batchID string, target *WebhookTarget) {
+	if target == nil && !c.reports {
+		return
+	}
+	jobs, err := c.BatchJobs(ctx, userID, batchID)
+	if err != nil {
+		return
+	}
+
+	totals := &BatchTotals{Total: len(jobs)}
+	correlationID := ""
+	for _, job := range jobs {
+		if correlationID == "" {
+			correlationID = job.CorrelationID // 同一批次的任务由同一请求提交
+		}
+		switch job.State {
+		case JobStateConfirmed:
+			totals.Sent++
+		case JobStateFailed:
+			totals.Failed++
+		case JobStateCancelled:
+			totals.Cancelled++
+		default:
+			return // 仍有未结束任务
+		}
+	}
+
+	first, err := c.redis.SetNX(ctx, PayoutWebhookDoneKeyPrefix+userID+":"+batchID, time.Now().Unix(), BatchStatusTTL).Result()
+	if err != nil || !first {
+		return
+	}
+	c.queueReport(ctx, userID, batchID)
+	if target == nil {
+		return
+	}
+	c.enqueueEvent(ctx, WebhookEvent{
+		Type:          EventBatchCompleted,
+		UserID:        userID,
+		BatchID:       batchID,
+		Batch:         totals,
+		ManifestHash:  c.manifestHash(ctx, userID, batchID),
+		CorrelationID: correlationID,
+	})
+}
+
+// enqueueEvent 写入 outbox，由 dispatcher 投递。写入失败仅记录日志，不影响任务处理。
+func (c *Consumer) enqueueEvent(ctx context.Context, event WebhookEvent) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Warn().Err(err).Msg("Failed to generate webhook event id")
+		return
+	}
+	event.ID = "evt_" + hex.EncodeToString(id)
+	event.CreatedAt = time.Now()
+
+	data, err := json.Marshal(&WebhookDelivery{Event: event})
+	if err != nil {
+		log.Warn().Err(err).Str("batch_id", event.BatchID).Msg("Failed to marshal webhook event")
+		return
+	}
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, PayoutWebhookEventsKey, event.ID, data)
+	pipe.ZAdd(ctx, PayoutWebhookOutboxKey, &redis.Z{Score: float64(time.Now().UnixMilli()), Member: event.ID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Warn().Err(err).Str("batch_id", event.BatchID).Str("type", event.Type).Msg("Failed to enqueue webhook event")
+	}
+}
+
+// webhookLease 投递中的事件在 outbox 中顺延的时间，实例崩溃后事件会在租期结束后重新投递
+const webhookLease = 2 * time.Minute
+
+// ClaimDueWebhooks 取出已到投递时间的事件。ZRem 成功者获得该事件 (多实例下不会同时投递)，
+// 随后以租期重新排期，投递结果由 AckWebhook / RetryWebhook 落定。
+func (c *Consumer) ClaimDueWebhooks(ctx context.Context, now time.Time, limit int64) ([]*WebhookDelivery, error) {
+	ids, err := c.redis.ZRangeByScore(ctx, PayoutWebhookOutboxKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*WebhookDelivery
+	for _, id := range ids {
+		removed, err := c.redis.ZRem(ctx, PayoutWebhookOutboxKey, id).Result()
+		if err != nil || removed == 0 {
+			continue // 已被其他实例取走
+		}
+		c.redis.ZAdd(ctx, PayoutWebhookOutboxKey, &redis.Z{Score: float64(now.Add(webhookLease).UnixMilli()), Member: id})
+		data, err := c.redis.HGet(ctx, PayoutWebhookEventsKey, id).Result()
+		if err != nil {
+			c.redis.ZRem(ctx, PayoutWebhookOutboxKey, id)
+			continue
+		}
+		var d WebhookDelivery
+		if err := json.Unmarshal([]byte(data), &d); err != nil {
+			c.AckWebhook(ctx, id)
+			continue
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, nil
+}
+
+// AckWebhook 投递成功或放弃后删除事件
+func (c *Consumer) AckWebhook(ctx context.Context, eventID string) error {
+	pipe := c.redis.TxPipeline()
+	pipe.ZRem(ctx, PayoutWebhookOutboxKey, eventID)
+	pipe.HDel(ctx, PayoutWebhookEventsKey, eventID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RetryWebhook 投递失败后按退避重新排期，超过次数时删除并返回 false
+func (c *Consumer) RetryWebhook(ctx context.Context, d *WebhookDelivery, cause error) (bool, error) {
+	d.Attempts++
+	d.LastErr = cause.Error()
+	if d.Attempts >= WebhookRetryPolicy.MaxRetries {
+		return false, c.AckWebhook(ctx, d.Event.ID)
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+	due := time.Now().Add(WebhookRetryPolicy.Backoff(d.Attempts))
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, PayoutWebhookEventsKey, d.Event.ID, data)
+	pipe.ZAdd(ctx, PayoutWebhookOutboxKey, &redis.Z{Score: float64(due.UnixMilli()), Member: d.Event.ID})
+	_, err = pipe.Exec(ctx)
+	return true, err
+}
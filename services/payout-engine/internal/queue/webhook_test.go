@@ -0,0 +1,188 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/eventschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookEvents(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	jobs := []*Job{
+		{ID: "job-1", BatchID: "batch-1", UserID: "user-1", Amount: "100", CreatedAt: time.Now()},
+		{ID: "job-2", BatchID: "batch-1", UserID: "user-1", Amount: "200", CreatedAt: time.Now()},
+		{ID: "job-3", BatchID: "batch-2", UserID: "user-1", Amount: "300", CreatedAt: time.Now()},
+	}
+	require.NoError(t, c.PushBatch(ctx, jobs))
+
+	c.handleSuccess(ctx, jobs[0], deliver(t, c), "0xabc")
+	c.handleFailure(ctx, jobs[1], deliver(t, c), Permanent(errors.New("reverted")))
+	c.handleSuccess(ctx, jobs[2], deliver(t, c), "0xdef") // 未注册 webhook 的批次
+	c.EmitJobConfirmed(ctx, BatchRef{UserID: "user-1", BatchID: "batch-1"}, "job-1")
+
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	var types []string
+	for _, d := range deliveries {
+		types = append(types, d.Event.Type)
+		assert.Equal(t, "batch-1", d.Event.BatchID)
+	}
+	assert.ElementsMatch(t, []string{EventJobSent, EventJobFailed, EventBatchCompleted, EventJobConfirmed}, types)
+
+	for _, d := range deliveries {
+		switch d.Event.Type {
+		case EventJobSent:
+			assert.Equal(t, "0xabc", d.Event.Job.TxHash)
+		case EventJobFailed:
+			assert.Equal(t, "reverted", d.Event.Job.Error)
+		case EventBatchCompleted:
+			assert.Equal(t, &BatchTotals{Total: 2, Sent: 1, Failed: 1}, d.Event.Batch)
+		}
+	}
+
+	// 已取走的事件在租期内不会重复取出
+	again, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// batch.completed 只发一次
+	c.checkBatchCompleted(ctx, "user-1", "batch-1", &WebhookTarget{URL: "https://example.com/hook"})
+	again, err = c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestWebhookRetry(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	c.enqueueEvent(ctx, WebhookEvent{Type: EventBatchCompleted, UserID: "user-1", BatchID: "batch-1"})
+
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	d := deliveries[0]
+
+	retrying, err := c.RetryWebhook(ctx, d, errors.New("503"))
+	require.NoError(t, err)
+	assert.True(t, retrying)
+
+	due, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due, "waits for the backoff")
+
+	due, err = c.ClaimDueWebhooks(ctx, time.Now().Add(WebhookRetryPolicy.Backoff(1)+time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "503", due[0].LastErr)
+
+	// 超过次数后丢弃
+	due[0].Attempts = WebhookRetryPolicy.MaxRetries - 1
+	retrying, err = c.RetryWebhook(ctx, due[0], errors.New("503"))
+	require.NoError(t, err)
+	assert.False(t, retrying)
+	n, err := c.redis.HLen(ctx, PayoutWebhookEventsKey).Result()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestEncodeWebhookEvent(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	registry, err := eventschema.NewRegistry()
+	require.NoError(t, err)
+
+	now := time.Now()
+	job := &JobStatus{
+		ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1,
+		ToAddress: "0x0000000000000000000000000000000000000001", Amount: "1000000",
+		TokenAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", TokenSymbol: "USDC",
+		State: JobStateConfirmed, TxHash: "0xabc", RetryCount: 1, GasFee: "21000",
+		UnwrapTxHash: "0xdef", UnwrapGasFee: "30000", CreatedAt: now, UpdatedAt: now,
+	}
+	events := []WebhookEvent{
+		{ID: "evt_01", Type: EventJobSent, CreatedAt: now, UserID: "user-1", BatchID: "batch-1", Job: job},
+		{ID: "evt_02", Type: EventJobConfirmed, CreatedAt: now, UserID: "user-1", BatchID: "batch-1", Job: job},
+		{ID: "evt_03", Type: EventJobFailed, CreatedAt: now, UserID: "user-1", BatchID: "batch-1", Job: &JobStatus{
+			ID: "job-2", BatchID: "batch-1", UserID: "user-1", ChainID: 1, ToAddress: "0x1", Amount: "5",
+			State: JobStateFailed, Error: "reverted", ErrorCode: "SIMULATION_REVERTED", CreatedAt: now, UpdatedAt: now,
+		}},
+		{ID: "evt_04", Type: EventBatchCompleted, CreatedAt: now, UserID: "user-1", BatchID: "batch-1",
+			Batch: &BatchTotals{Total: 2, Sent: 1, Failed: 1}, ManifestHash: "0x1234"},
+	}
+	for version := eventschema.V1; version <= eventschema.Latest; version++ {
+		for _, event := range events {
+			body, err := EncodeWebhookEvent(event, version)
+			require.NoError(t, err)
+			assert.NoError(t, registry.Validate(event.Type, version, body), "%s v%d", event.Type, version)
+		}
+	}
+
+	// v1 保持原有扁平结构
+	body, err := EncodeWebhookEvent(events[3], eventschema.V1)
+	require.NoError(t, err)
+	var flat map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &flat))
+	assert.Equal(t, "batch-1", flat["batch_id"])
+	assert.EqualValues(t, 1, flat["schema_version"])
+
+	_, err = EncodeWebhookEvent(events[0], eventschema.Latest+1)
+	assert.Error(t, err)
+
+	// 版本化之前的注册按 v1 投递
+	require.NoError(t, c.redis.HSet(ctx, webhookKey("user-1", "old-batch"), "url", "https://example.com/hook", "secret", "s").Err())
+	target, err := c.WebhookFor(ctx, "user-1", "old-batch")
+	require.NoError(t, err)
+	assert.Equal(t, eventschema.V1, target.SchemaVersion)
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s", SchemaVersion: eventschema.V2}))
+	target, err = c.WebhookFor(ctx, "user-1", "batch-1")
+	require.NoError(t, err)
+	assert.Equal(t, eventschema.V2, target.SchemaVersion)
+}
+
+func TestWebhookCorrelationID(t *testing.T) {
+	ctx := correlation.WithID(context.Background(), "sdk-req-1")
+	c := newTestConsumer(t)
+	registry, err := eventschema.NewRegistry()
+	require.NoError(t, err)
+
+	require.NoError(t, c.RegisterWebhook(ctx, "user-1", "batch-1", WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	job := &Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1, ToAddress: "0x1", Amount: "100", CreatedAt: time.Now()}
+	require.NoError(t, c.PushBatch(ctx, []*Job{job}))
+	assert.Equal(t, "sdk-req-1", job.CorrelationID)
+
+	status, err := c.getJobStatus(ctx, "user-1", "batch-1", "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sdk-req-1", status.CorrelationID)
+
+	// 消费侧不依赖 context，以任务上记录的 ID 为准
+	c.handleSuccess(context.Background(), job, deliver(t, c), "0xabc")
+
+	deliveries, err := c.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2) // job.sent, batch.completed
+	for _, d := range deliveries {
+		assert.Equal(t, "sdk-req-1", d.Event.CorrelationID, d.Event.Type)
+		for version := eventschema.V1; version <= eventschema.Latest; version++ {
+			body, err := EncodeWebhookEvent(d.Event, version)
+			require.NoError(t, err)
+			assert.NoError(t, registry.Validate(d.Event.Type, version, body), "%s v%d", d.Event.Type, version)
+			var payload map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, "sdk-req-1", payload["correlation_id"])
+		}
+	}
+}
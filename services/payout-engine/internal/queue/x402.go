@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// x402NonceKey 中继授权 nonce 的占用标记 (nonce 按合约和签名方唯一: EIP-3009 为代币，ERC-2771 为转发合约)
+func x402NonceKey(chainID uint64, contract, from, nonce string) string {
+	return fmt.Sprintf("payout:x402:nonce:%d:%s:%s:%s", chainID, strings.ToLower(contract), strings.ToLower(from), strings.ToLower(nonce))
+}
+
+// ClaimAuthorizationNonce 占用授权 nonce，保证同一授权只中继一次。
+// 已被占用时返回 false 和占用它的任务 ID。ttl 应覆盖授权的有效期 (过期后链上也无法执行)。
+func (c *Consumer) ClaimAuthorizationNonce(ctx context.Context, chainID uint64, contract, from, nonce, jobID string, ttl time.Duration) (bool, string, error) {
+	key := x402NonceKey(chainID, contract, from, nonce)
+	claimed, err := c.redis.SetNX(ctx, key, jobID, ttl).Result()
+	if err != nil || claimed {
+		return claimed, "", err
+	}
+	owner, err := c.redis.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return c.ClaimAuthorizationNonce(ctx, chainID, contract, from, nonce, jobID, ttl) // 恰好过期，重新占用
+	}
+	return false, owner, err
+}
+
+// ReleaseAuthorizationNonce 任务未能入队时释放 nonce
+func (c *Consumer) ReleaseAuthorizationNonce(ctx context.Context, chainID uint64, contract, from, nonce string) error {
+	return c.redis.Del(ctx, x402NonceKey(chainID, contract, from, nonce)).Err()
+}
@@ -0,0 +1,38 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationNonce(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConsumer(t)
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	from := "0x1111111111111111111111111111111111111111"
+	nonce := "0x" + "ab"
+
+	claimed, _, err := c.ClaimAuthorizationNonce(ctx, 8453, usdc, from, nonce, "x402-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// 大小写不影响唯一性
+	claimed, owner, err := c.ClaimAuthorizationNonce(ctx, 8453, "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", from, "0xAB", "x402-2", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, "x402-1", owner)
+
+	// 其他链互不影响
+	claimed, _, err = c.ClaimAuthorizationNonce(ctx, 1, usdc, from, nonce, "x402-3", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, c.ReleaseAuthorizationNonce(ctx, 8453, usdc, from, nonce))
+	claimed, _, err = c.ClaimAuthorizationNonce(ctx, 8453, usdc, from, nonce, "x402-2", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,717 @@
+// Package rpcpool spreads a chain's RPC traffic over several endpoints.
+// Calls go to the healthy endpoint with the lowest observed latency and fail
+// over to the next one on transport errors. An endpoint that exceeds its
+// error budget, or fails several times in a row, is taken out of rotation
+// for a cooldown period. A background health check keeps latency and head
+// height current and marks endpoints that fall behind as degraded.
+// Gas estimation and broadcasts are additionally paced by a per-endpoint
+// token bucket so large batches stay under public endpoints' rate limits.
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+// 默认错误预算: 1 分钟内 5 次失败后下线 30 秒
+const (
+	DefaultErrorBudget = 5
+	DefaultErrorWindow = time.Minute
+	DefaultCooldown    = 30 * time.Second
+
+	DefaultProbeInterval       = 30 * time.Second
+	DefaultConsecutiveFailures = 3
+	DefaultMaxBlockLag         = 10
+)
+
+// latencyAlpha 延迟 EWMA 的平滑系数
+const latencyAlpha = 0.3
+
+// Config 节点池参数 (零值字段使用默认值)
+type Config struct {
+	ErrorBudget int           // 窗口内允许的失败次数
+	ErrorWindow time.Duration // 失败计数窗口
+	Cooldown    time.Duration // 超出预算后的下线时长
+
+	ProbeInterval       time.Duration // 后台探测间隔 (延迟测量和故障恢复)
+	ConsecutiveFailures int           // 连续失败多少次后下线 (不等窗口预算耗尽)
+	MaxBlockLag         uint64        // 区块高度落后最高节点超过该值时视为降级
+
+	RateLimit RateLimit            // 每个节点默认的限速 (零值不限速)
+	Endpoints map[string]RateLimit // 按节点覆盖限速，键为完整 URL 或脱敏后的地址 (见 Redact)
+}
+
+// RateLimit 节点的令牌桶参数，作用于 rateLimited 中的方法
+type RateLimit struct {
+	RPS   float64 `json:"rps"`   // 每秒请求数，0 表示不限速
+	Burst int     `json:"burst"` // 桶容量，0 时取 RPS 向上取整 (至少为 1)
+}
+
+// rateLimited 受限速的方法: 大批量付款时调用最密集，公共节点通常对其限流
+var rateLimited = map[string]bool{
+	"eth_estimateGas":        true,
+	"eth_sendRawTransaction": true,
+}
+
+// limiter 令牌桶，不限速时为 nil
+func (r RateLimit) limiter() *rate.Limiter {
+	if r.RPS <= 0 {
+		return nil
+	}
+	burst := r.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(r.RPS))
+	}
+	return rate.NewLimiter(rate.Limit(r.RPS), burst)
+}
+
+// rateLimit 节点的限速配置，按 keys 顺序查找覆盖项，没有时使用默认值
+func (c Config) rateLimit(keys ...string) RateLimit {
+	for _, k := range keys {
+		if r, ok := c.Endpoints[k]; ok {
+			return r
+		}
+	}
+	return c.RateLimit
+}
+
+func (c Config) withDefaults() Config {
+	if c.ErrorBudget <= 0 {
+		c.ErrorBudget = DefaultErrorBudget
+	}
+	if c.ErrorWindow <= 0 {
+		c.ErrorWindow = DefaultErrorWindow
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = DefaultCooldown
+	}
+	if c.ProbeInterval <= 0 {
+		c.ProbeInterval = DefaultProbeInterval
+	}
+	if c.ConsecutiveFailures <= 0 {
+		c.ConsecutiveFailures = DefaultConsecutiveFailures
+	}
+	if c.MaxBlockLag == 0 {
+		c.MaxBlockLag = DefaultMaxBlockLag
+	}
+	return c
+}
+
+// Backend 单个节点所需的 RPC 接口 (*ethclient.Client 满足，Pool 本身也满足)
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+type endpoint struct {
+	name    string // 脱敏后的地址，用于日志和状态
+	backend Backend
+
+	mu        sync.Mutex
+	latency   time.Duration // EWMA，0 表示尚未测量
+	failures  []time.Time   // 窗口内的失败时间
+	streak    int           // 连续失败次数
+	downUntil time.Time
+	lastError string
+	height    uint64 // 最近一次探测的区块高度
+	degraded  bool   // 区块高度落后
+
+	limit   RateLimit
+	limiter *rate.Limiter // 不限速时为 nil
+
+	// 链 ID 核对: Dial 时不可达的节点在首次使用前核对，不符的节点永久停用
+	unverified bool
+	mismatch   *ChainIDMismatchError
+}
+
+func (ep *endpoint) setRateLimit(r RateLimit) {
+	ep.limit = r
+	ep.limiter = r.limiter()
+}
+
+// hasToken 当前是否可以立即发出受限速的请求
+func (ep *endpoint) hasToken() bool {
+	return ep.limiter == nil || ep.limiter.Tokens() >= 1
+}
+
+// EndpointStatus 节点当前状态
+type EndpointStatus struct {
+	URL       string        `json:"url"`
+	Latency   time.Duration `json:"latency_ns"`
+	Failures  int           `json:"failures"`
+	Height    uint64        `json:"height,omitempty"`
+	Available bool          `json:"available"`
+	Degraded  bool          `json:"degraded,omitempty"`
+	DownUntil *time.Time    `json:"down_until,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+	RateLimit float64       `json:"rate_limit,omitempty"`
+	RateBurst int           `json:"rate_burst,omitempty"`
+}
+
+// verifyTimeout Dial 时核对每个节点链 ID 的超时
+const verifyTimeout = 10 * time.Second
+
+// ChainIDMismatchError 节点返回的链 ID 与配置不符。节点地址配置错误时继续使用会向错误的网络签名和广播交易。
+type ChainIDMismatchError struct {
+	ChainID  uint64 // 配置的链 ID
+	Endpoint string // 脱敏后的节点地址
+	Actual   *big.Int
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("rpc %s reports chain id %s, expected %d", e.Endpoint, e.Actual, e.ChainID)
+}
+
+// IsChainIDMismatch 是否为节点链 ID 不符
+func IsChainIDMismatch(err error) bool {
+	var e *ChainIDMismatchError
+	return errors.As(err, &e)
+}
+
+// Pool 一条链的节点池
+type Pool struct {
+	chainID   uint64
+	cfg       Config
+	endpoints []*endpoint
+	now       func() time.Time
+}
+
+// Dial 连接所有节点并核对链 ID，部分节点连接失败时仍可使用其余节点。
+// 任一节点的链 ID 与配置不符时关闭所有连接并返回 ChainIDMismatchError；
+// 暂时无法查询链 ID 的节点在首次使用前 (或下一次探测时) 核对。
+func Dial(ctx context.Context, chainID uint64, urls []string, cfg Config) (*Pool, error) {
+	var (
+		backends []Backend
+		names    []string
+		lastErr  error
+	)
+	var limits []RateLimit
+	for _, u := range urls {
+		client, err := ethclient.DialContext(ctx, u)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("rpc", Redact(u)).Msg("Failed to dial RPC endpoint")
+			lastErr = err
+			continue
+		}
+		backends = append(backends, client)
+		names = append(names, Redact(u))
+		limits = append(limits, cfg.rateLimit(u, Redact(u)))
+	}
+	if len(backends) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no rpc endpoints configured")
+		}
+		return nil, lastErr
+	}
+	p := New(chainID, names, backends, cfg)
+	for i, ep := range p.endpoints {
+		ep.setRateLimit(limits[i])
+		ep.unverified = true
+	}
+	if err := p.verifyAll(ctx); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// verifyAll 并发核对所有节点的链 ID，返回第一个不符的节点
+func (p *Pool) verifyAll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			if err := p.verify(ctx, ep); err != nil {
+				if !IsChainIDMismatch(err) {
+					log.Warn().Err(err).Uint64("chain_id", p.chainID).Str("rpc", ep.name).Msg("Could not verify RPC chain id, will verify before first use")
+				}
+				errs[i] = err
+			}
+		}(i, ep)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if IsChainIDMismatch(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify 核对节点的链 ID (已核对的节点直接返回)。不符时停用该节点。
+func (p *Pool) verify(ctx context.Context, ep *endpoint) error {
+	ep.mu.Lock()
+	unverified, mismatch := ep.unverified, ep.mismatch
+	ep.mu.Unlock()
+	if mismatch != nil {
+		return mismatch
+	}
+	if !unverified {
+		return nil
+	}
+
+	id, err := ep.backend.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if !id.IsUint64() || id.Uint64() != p.chainID {
+		ep.mismatch = &ChainIDMismatchError{ChainID: p.chainID, Endpoint: ep.name, Actual: id}
+		ep.lastError = ep.mismatch.Error()
+		log.Error().Uint64("chain_id", p.chainID).Str("rpc", ep.name).Str("actual_chain_id", id.String()).Msg("RPC endpoint reports a different chain id, endpoint disabled")
+		return ep.mismatch
+	}
+	ep.unverified = false
+	return nil
+}
+
+// New 使用已建立的连接创建节点池 (names 与 backends 一一对应，限速按 names 查找覆盖项)
+func New(chainID uint64, names []string, backends []Backend, cfg Config) *Pool {
+	p := &Pool{chainID: chainID, cfg: cfg.withDefaults(), now: time.Now}
+	for i, b := range backends {
+		ep := &endpoint{name: names[i], backend: b}
+		ep.setRateLimit(cfg.rateLimit(names[i]))
+		p.endpoints = append(p.endpoints, ep)
+	}
+	return p
+}
+
+// Close 关闭所有节点连接
+func (p *Pool) Close() {
+	for _, ep := range p.endpoints {
+		if c, ok := ep.backend.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
+
+// Status 各节点状态 (按配置顺序)
+func (p *Pool) Status() []EndpointStatus {
+	now := p.now()
+	out := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		st := EndpointStatus{
+			URL:       ep.name,
+			Latency:   ep.latency,
+			Failures:  len(ep.recentFailures(now, p.cfg.ErrorWindow)),
+			Height:    ep.height,
+			Available: !now.Before(ep.downUntil) && ep.mismatch == nil,
+			Degraded:  ep.degraded,
+			LastError: ep.lastError,
+		}
+		if ep.limiter != nil {
+			st.RateLimit = ep.limit.RPS
+			st.RateBurst = ep.limiter.Burst()
+		}
+		if !st.Available {
+			until := ep.downUntil
+			st.DownUntil = &until
+		}
+		ep.mu.Unlock()
+		out = append(out, st)
+	}
+	return out
+}
+
+// Available 是否至少有一个节点在线
+func (p *Pool) Available() bool {
+	now := p.now()
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		up := !now.Before(ep.downUntil) && ep.mismatch == nil
+		ep.mu.Unlock()
+		if up {
+			return true
+		}
+	}
+	return false
+}
+
+// Probe 对所有节点调用 eth_blockNumber，更新延迟、错误预算和区块高度 (包括下线中的节点)。
+// 高度落后最高节点超过 MaxBlockLag 的节点标记为降级，排在正常节点之后。
+func (p *Pool) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			start := p.now()
+			if err := p.verify(ctx, ep); err != nil {
+				if !IsChainIDMismatch(err) {
+					p.observe(ctx, ep, start, err)
+				}
+				return
+			}
+			height, err := ep.backend.BlockNumber(ctx)
+			p.observe(ctx, ep, start, err)
+			if err == nil {
+				ep.mu.Lock()
+				ep.height = height
+				ep.mu.Unlock()
+			}
+		}(ep)
+	}
+	wg.Wait()
+
+	var head uint64
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.height > head {
+			head = ep.height
+		}
+		ep.mu.Unlock()
+	}
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		degraded := ep.height+p.cfg.MaxBlockLag < head
+		if degraded && !ep.degraded {
+			log.Warn().Uint64("chain_id", p.chainID).Str("rpc", ep.name).Uint64("height", ep.height).Uint64("head", head).Msg("RPC endpoint is lagging behind")
+		}
+		ep.degraded = degraded
+		ep.mu.Unlock()
+	}
+}
+
+// ordered 候选节点: 可用节点按延迟升序 (未测量的优先，相同时按配置顺序，降级节点在后)，
+// 随后是下线中的节点 (按恢复时间)，所有节点都下线时仍会尝试。链 ID 不符的节点不参与。
+func (p *Pool) ordered() []*endpoint {
+	now := p.now()
+	type candidate struct {
+		ep        *endpoint
+		latency   time.Duration
+		degraded  bool
+		downUntil time.Time
+	}
+	var up, down []candidate
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		c := candidate{ep: ep, latency: ep.latency, degraded: ep.degraded, downUntil: ep.downUntil}
+		disabled := ep.mismatch != nil
+		ep.mu.Unlock()
+		if disabled {
+			continue
+		}
+		if now.Before(c.downUntil) {
+			down = append(down, c)
+		} else {
+			up = append(up, c)
+		}
+	}
+	sort.SliceStable(up, func(i, j int) bool {
+		if up[i].degraded != up[j].degraded {
+			return !up[i].degraded
+		}
+		return up[i].latency < up[j].latency
+	})
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+
+	out := make([]*endpoint, 0, len(p.endpoints))
+	for _, c := range append(up, down...) {
+		out = append(out, c.ep)
+	}
+	return out
+}
+
+// observe 记录一次调用结果，返回是否应切换到下一个节点
+func (p *Pool) observe(ctx context.Context, ep *endpoint, start time.Time, err error) bool {
+	now := p.now()
+	failed := isEndpointError(ctx, err)
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if !failed {
+		if ctx.Err() == nil {
+			ep.streak = 0
+			// 节点有应答 (包括 JSON-RPC 错误) 时计入延迟
+			elapsed := now.Sub(start)
+			if ep.latency == 0 {
+				ep.latency = elapsed
+			} else {
+				ep.latency = time.Duration(latencyAlpha*float64(elapsed) + (1-latencyAlpha)*float64(ep.latency))
+			}
+		}
+		return false
+	}
+
+	ep.lastError = err.Error()
+	ep.failures = append(ep.recentFailures(now, p.cfg.ErrorWindow), now)
+	ep.streak++
+	if (len(ep.failures) >= p.cfg.ErrorBudget || ep.streak >= p.cfg.ConsecutiveFailures) && !now.Before(ep.downUntil) {
+		ep.downUntil = now.Add(p.cfg.Cooldown)
+		ep.failures = nil
+		ep.streak = 0
+		log.Warn().
+			Err(err).
+			Uint64("chain_id", p.chainID).
+			Str("rpc", ep.name).
+			Dur("cooldown", p.cfg.Cooldown).
+			Msg("RPC endpoint taken out of rotation")
+	}
+	return true
+}
+
+// recentFailures 窗口内的失败记录 (调用方持有锁)
+func (ep *endpoint) recentFailures(now time.Time, window time.Duration) []time.Time {
+	i := 0
+	for i < len(ep.failures) && now.Sub(ep.failures[i]) > window {
+		i++
+	}
+	return ep.failures[i:]
+}
+
+// isEndpointError 是否为节点故障 (网络错误、HTTP 错误、限流)。
+// 节点正常返回的 JSON-RPC 错误 (如 revert、nonce too low) 和未找到结果不切换节点。
+func isEndpointError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == -32005 // limit exceeded
+	}
+	return true
+}
+
+// byCapacity 受限速的调用优先使用当前有令牌的可用节点 (保持原有顺序)，
+// 令牌耗尽的可用节点在后，下线中的节点仍在最后
+func (p *Pool) byCapacity(eps []*endpoint) []*endpoint {
+	now := p.now()
+	var ready, waiting, down []*endpoint
+	for _, ep := range eps {
+		ep.mu.Lock()
+		up := !now.Before(ep.downUntil)
+		ep.mu.Unlock()
+		switch {
+		case !up:
+			down = append(down, ep)
+		case ep.hasToken():
+			ready = append(ready, ep)
+		default:
+			waiting = append(waiting, ep)
+		}
+	}
+	return append(append(ready, waiting...), down...)
+}
+
+// call 按顺序尝试节点，直到节点给出应答。
+// 受限速的方法在发出请求前等待节点的令牌，ctx 截止前拿不到令牌时返回错误。
+func (p *Pool) call(ctx context.Context, method string, fn func(Backend) error) error {
+	eps := p.ordered()
+	limited := rateLimited[method]
+	if limited {
+		eps = p.byCapacity(eps)
+	}
+
+	if len(eps) == 0 {
+		return fmt.Errorf("rpc %s: no usable endpoints for chain %d", method, p.chainID)
+	}
+	var err error
+	for i, ep := range eps {
+		if err = p.verify(ctx, ep); err != nil {
+			if !IsChainIDMismatch(err) {
+				p.observe(ctx, ep, p.now(), err)
+			}
+			continue
+		}
+		if limited && ep.limiter != nil {
+			if werr := ep.limiter.Wait(ctx); werr != nil {
+				return fmt.Errorf("rpc %s rate limit for %s: %w", ep.name, method, werr)
+			}
+		}
+		start := p.now()
+		err = fn(ep.backend)
+		if !p.observe(ctx, ep, start, err) {
+			return err
+		}
+		if i < len(p.endpoints)-1 {
+			log.Warn().Err(err).Uint64("chain_id", p.chainID).Str("rpc", ep.name).Str("method", method).Msg("RPC call failed, trying next endpoint")
+		}
+	}
+	return err
+}
+
+// ChainID 配置的链 ID (各节点已核对或在使用前核对)
+func (p *Pool) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).SetUint64(p.chainID), nil
+}
+
+func (p *Pool) BlockNumber(ctx context.Context) (n uint64, err error) {
+	err = p.call(ctx, "eth_blockNumber", func(b Backend) error {
+		n, err = b.BlockNumber(ctx)
+		return err
+	})
+	return n, err
+}
+
+func (p *Pool) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.call(ctx, "eth_getBlockByNumber", func(b Backend) error {
+		header, err = b.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (p *Pool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (balance *big.Int, err error) {
+	err = p.call(ctx, "eth_getBalance", func(b Backend) error {
+		balance, err = b.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+func (p *Pool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = p.call(ctx, "eth_getCode", func(b Backend) error {
+		code, err = b.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (p *Pool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = p.call(ctx, "eth_getTransactionCount", func(b Backend) error {
+		nonce, err = b.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return nonce, err
+}
+
+func (p *Pool) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = p.call(ctx, "eth_getTransactionCount", func(b Backend) error {
+		nonce, err = b.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (p *Pool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	err = p.call(ctx, "eth_call", func(b Backend) error {
+		out, err = b.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return out, err
+}
+
+func (p *Pool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = p.call(ctx, "eth_estimateGas", func(b Backend) error {
+		gas, err = b.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+func (p *Pool) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = p.call(ctx, "eth_gasPrice", func(b Backend) error {
+		price, err = b.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (p *Pool) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (history *ethereum.FeeHistory, err error) {
+	err = p.call(ctx, "eth_feeHistory", func(b Backend) error {
+		history, err = b.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return history, err
+}
+
+func (p *Pool) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = p.call(ctx, "eth_getTransactionReceipt", func(b Backend) error {
+		receipt, err = b.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (p *Pool) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = p.call(ctx, "eth_getTransactionByHash", func(b Backend) error {
+		tx, isPending, err = b.TransactionByHash(ctx, txHash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+// SendTransaction 广播交易。前一个节点可能已收到交易后才断开，
+// 因此切换节点后返回的 "already known" 视为成功。
+func (p *Pool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempt := 0
+	return p.call(ctx, "eth_sendRawTransaction", func(b Backend) error {
+		err := b.SendTransaction(ctx, tx)
+		if err != nil && attempt > 0 && IsAlreadyKnown(err) {
+			err = nil
+		}
+		attempt++
+		return err
+	})
+}
+
+// SendRawTransaction 广播已编码的交易 (go-ethereum 无法解析的交易类型，如 zkSync EIP-712 交易)，
+// 重试时的处理同 SendTransaction
+func (p *Pool) SendRawTransaction(ctx context.Context, raw []byte) error {
+	attempt := 0
+	return p.call(ctx, "eth_sendRawTransaction", func(b Backend) error {
+		rc, ok := b.(interface{ Client() *rpc.Client })
+		if !ok {
+			return fmt.Errorf("backend does not support raw transactions")
+		}
+		err := rc.Client().CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(raw))
+		if err != nil && attempt > 0 && IsAlreadyKnown(err) {
+			err = nil
+		}
+		attempt++
+		return err
+	})
+}
+
+// Redact 去掉 URL 中的路径、查询参数和账号信息 (节点地址通常包含 API key)。
+// 无 scheme 的 host:port (TRON gRPC) 只保留地址部分。
+func Redact(raw string) string {
+	if !strings.Contains(raw, "://") {
+		host, _, _ := strings.Cut(raw, "/")
+		return host
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid-url"
+	}
+	return u.Scheme + "://" + u.Host
+}
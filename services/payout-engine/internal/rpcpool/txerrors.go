@@ -0,0 +1,53 @@
+package rpcpool
+
+import "strings"
+
+// TxErrorClass 广播交易时节点拒绝的原因
+type TxErrorClass string
+
+const (
+	TxErrorUnknown           TxErrorClass = ""
+	TxErrorNonceTooLow       TxErrorClass = "nonce_too_low"      // nonce 已被使用 (另一笔交易已上链或本地缓存落后)
+	TxErrorNonceTooHigh      TxErrorClass = "nonce_too_high"     // nonce 超前于链上 (中间有空缺)
+	TxErrorUnderpriced       TxErrorClass = "underpriced"        // 费用低于节点门槛、base fee 或被替换交易
+	TxErrorInsufficientFunds TxErrorClass = "insufficient_funds" // 余额不足以支付 value + gas
+	TxErrorAlreadyKnown      TxErrorClass = "already_known"      // 相同交易已在节点交易池
+)
+
+// txErrorPatterns 各客户端 (geth/erigon/reth、Nethermind、Besu) 返回的错误信息，按顺序匹配小写后的信息
+var txErrorPatterns = []struct {
+	class    TxErrorClass
+	patterns []string
+}{
+	{TxErrorAlreadyKnown, []string{"already known", "known transaction", "alreadyknown", "transaction_already_known", "already exists", "already imported"}},
+	{TxErrorNonceTooLow, []string{"nonce too low", "oldnonce", "nonce_too_low", "nonce has already been used"}},
+	{TxErrorNonceTooHigh, []string{"nonce too high", "nonce_too_far_in_future", "nonce gap"}},
+	{TxErrorUnderpriced, []string{"underpriced", "fee too low", "feetoolow", "max fee per gas less than block base fee", "gas price too low", "gas_price_below_current_base_fee", "tip too low"}},
+	{TxErrorInsufficientFunds, []string{"insufficient funds", "insufficientfunds", "upfront_cost_exceeds_balance"}},
+}
+
+// ClassifyTxError 按节点返回的错误信息分类 (JSON-RPC 只返回文本，错误码因客户端而异)
+func ClassifyTxError(err error) TxErrorClass {
+	if err == nil {
+		return TxErrorUnknown
+	}
+	msg := strings.ToLower(err.Error())
+	for _, p := range txErrorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(msg, pattern) {
+				return p.class
+			}
+		}
+	}
+	return TxErrorUnknown
+}
+
+// IsNonceError nonce 与链上不一致，本地 nonce 缓存需要重置
+func (c TxErrorClass) IsNonceError() bool {
+	return c == TxErrorNonceTooLow || c == TxErrorNonceTooHigh
+}
+
+// IsAlreadyKnown 节点已收到相同交易 (重新广播时视为成功)
+func IsAlreadyKnown(err error) bool {
+	return ClassifyTxError(err) == TxErrorAlreadyKnown
+}
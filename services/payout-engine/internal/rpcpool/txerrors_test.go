@@ -0,0 +1,36 @@
+package rpcpool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyTxError(t *testing.T) {
+	cases := map[string]TxErrorClass{
+		"nonce too low: next nonce 12, tx nonce 11":             TxErrorNonceTooLow,
+		"OldNonce, Current nonce: 12, nonce of rejected tx: 11": TxErrorNonceTooLow,
+		"NONCE_TOO_LOW":                       TxErrorNonceTooLow,
+		"nonce too high":                      TxErrorNonceTooHigh,
+		"replacement transaction underpriced": TxErrorUnderpriced,
+		"max fee per gas less than block base fee: address 0x1":      TxErrorUnderpriced,
+		"FeeTooLow, MaxFeePerGas too low":                            TxErrorUnderpriced,
+		"insufficient funds for gas * price + value: have 1 want 2":  TxErrorInsufficientFunds,
+		"UPFRONT_COST_EXCEEDS_BALANCE":                               TxErrorInsufficientFunds,
+		"already known":                                              TxErrorAlreadyKnown,
+		"AlreadyKnown":                                               TxErrorAlreadyKnown,
+		"execution reverted: ERC20: transfer amount exceeds balance": TxErrorUnknown,
+		"Post \"https://rpc.example\": context deadline exceeded":    TxErrorUnknown,
+	}
+	for msg, want := range cases {
+		assert.Equal(t, want, ClassifyTxError(errors.New(msg)), msg)
+	}
+
+	wrapped := fmt.Errorf("failed to submit permit: %w", errors.New("nonce too low"))
+	assert.True(t, ClassifyTxError(wrapped).IsNonceError())
+	assert.False(t, TxErrorUnderpriced.IsNonceError())
+	assert.Equal(t, TxErrorUnknown, ClassifyTxError(nil))
+	assert.True(t, IsAlreadyKnown(errors.New("known transaction: 0xabc")))
+}
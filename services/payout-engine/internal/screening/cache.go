@@ -0,0 +1,67 @@
+package screening
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cacheMaxEntries bounds the cache; expired entries are dropped when it is full.
+const cacheMaxEntries = 100_000
+
+// Cache reuses screening results for a TTL so repeat recipients do not hit the provider.
+// Provider errors are not cached.
+type Cache struct {
+	screener Screener
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result  *Result
+	expires time.Time
+}
+
+// NewCache wraps a screener with a result cache.
+func NewCache(s Screener, ttl time.Duration) *Cache {
+	return &Cache{screener: s, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Provider implements Screener.
+func (c *Cache) Provider() string { return c.screener.Provider() }
+
+// Screen implements Screener.
+func (c *Cache) Screen(ctx context.Context, chainID uint64, address string) (*Result, error) {
+	key := fmt.Sprintf("%d:%s", chainID, normalize(address))
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.result, nil
+	}
+
+	result, err := c.screener.Screen(ctx, chainID, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= cacheMaxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= cacheMaxEntries {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	c.entries[key] = cacheEntry{result: result, expires: now.Add(c.ttl)}
+	return result, nil
+}
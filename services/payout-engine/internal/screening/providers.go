@@ -0,0 +1,170 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultChainalysisURL = "https://public.chainalysis.com"
+	defaultTRMURL         = "https://api.trmlabs.com"
+)
+
+// Chainalysis screens addresses with the Chainalysis sanctions API.
+// Any identification returned for an address means it is sanctioned.
+type Chainalysis struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewChainalysis creates a Chainalysis sanctions screener.
+func NewChainalysis(apiKey, baseURL string) *Chainalysis {
+	if baseURL == "" {
+		baseURL = defaultChainalysisURL
+	}
+	return &Chainalysis{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Provider implements Screener.
+func (c *Chainalysis) Provider() string { return ProviderChainalysis }
+
+// Screen implements Screener.
+func (c *Chainalysis) Screen(ctx context.Context, chainID uint64, address string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/address/"+url.PathEscape(address), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		Identifications []struct {
+			Category string `json:"category"`
+			Name     string `json:"name"`
+		} `json:"identifications"`
+	}
+	if err := do(c.httpClient, req, &resp); err != nil {
+		return nil, fmt.Errorf("chainalysis screening failed: %w", err)
+	}
+
+	result := &Result{Address: address, Risk: RiskLow, Provider: ProviderChainalysis, CheckedAt: time.Now()}
+	for _, id := range resp.Identifications {
+		result.Sanctioned = true
+		result.Risk = RiskSevere
+		result.Categories = append(result.Categories, id.Category)
+	}
+	return result, nil
+}
+
+// TRM screens addresses with the TRM Labs address screening API.
+type TRM struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTRM creates a TRM Labs screener.
+func NewTRM(apiKey, baseURL string) *TRM {
+	if baseURL == "" {
+		baseURL = defaultTRMURL
+	}
+	return &TRM{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// trmChains maps chain IDs to TRM chain names.
+var trmChains = map[uint64]string{
+	1:          "ethereum",
+	10:         "optimism",
+	56:         "binance_smart_chain",
+	137:        "polygon",
+	8453:       "base",
+	42161:      "arbitrum",
+	43114:      "avalanche_c_chain",
+	728126428:  "tron",
+	11155111:   "ethereum",
+	84532:      "base",
+	3448148188: "tron",
+}
+
+// Provider implements Screener.
+func (t *TRM) Provider() string { return ProviderTRM }
+
+// Screen implements Screener.
+func (t *TRM) Screen(ctx context.Context, chainID uint64, address string) (*Result, error) {
+	chain, ok := trmChains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("trm screening does not support chain %d", chainID)
+	}
+	body, _ := json.Marshal([]map[string]string{{"address": address, "chain": chain}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/public/v2/screening/addresses", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(t.apiKey, t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var resp []struct {
+		Address               string         `json:"address"`
+		AddressRiskIndicators []trmIndicator `json:"addressRiskIndicators"`
+		Entities              []trmEntity    `json:"entities"`
+	}
+	if err := do(t.httpClient, req, &resp); err != nil {
+		return nil, fmt.Errorf("trm screening failed: %w", err)
+	}
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("trm screening returned no result for %s", address)
+	}
+
+	result := &Result{Address: address, Risk: RiskLow, Provider: ProviderTRM, CheckedAt: time.Now()}
+	for _, ind := range resp[0].AddressRiskIndicators {
+		result.Risk = higherRisk(result.Risk, strings.ToLower(ind.CategoryRiskScoreLevelLabel))
+		result.Categories = append(result.Categories, ind.Category)
+		if strings.EqualFold(ind.Category, "sanctions") {
+			result.Sanctioned = true
+		}
+	}
+	for _, e := range resp[0].Entities {
+		result.Risk = higherRisk(result.Risk, strings.ToLower(e.RiskScoreLevelLabel))
+		if strings.EqualFold(e.Category, "sanctions") {
+			result.Sanctioned = true
+		}
+	}
+	return result, nil
+}
+
+type trmIndicator struct {
+	Category                    string `json:"category"`
+	CategoryRiskScoreLevelLabel string `json:"categoryRiskScoreLevelLabel"`
+}
+
+type trmEntity struct {
+	Category            string `json:"category"`
+	RiskScoreLevelLabel string `json:"riskScoreLevelLabel"`
+}
+
+// do sends the request and decodes a JSON response.
+func do(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,127 @@
+package screening
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported screening providers
+const (
+	ProviderChainalysis = "chainalysis"
+	ProviderTRM         = "trm"
+)
+
+// Code is the error code recorded on jobs blocked by screening.
+const Code = "SCREENING_BLOCKED"
+
+// Risk levels, ordered from lowest to highest.
+const (
+	RiskUnknown = "unknown"
+	RiskLow     = "low"
+	RiskMedium  = "medium"
+	RiskHigh    = "high"
+	RiskSevere  = "severe"
+)
+
+var riskOrder = map[string]int{RiskUnknown: 0, RiskLow: 1, RiskMedium: 2, RiskHigh: 3, RiskSevere: 4}
+
+// Result is the outcome of screening one address.
+type Result struct {
+	Address    string    `json:"address"`
+	Sanctioned bool      `json:"sanctioned"`
+	Risk       string    `json:"risk"`
+	Categories []string  `json:"categories,omitempty"`
+	Provider   string    `json:"provider"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Screener checks recipient addresses against sanctions lists and risk data.
+type Screener interface {
+	// Screen returns the screening result for an address on a chain.
+	Screen(ctx context.Context, chainID uint64, address string) (*Result, error)
+	// Provider returns the provider name, used for logging.
+	Provider() string
+}
+
+// Config selects and configures the screening provider.
+type Config struct {
+	Provider  string // "" (disabled), "chainalysis" or "trm"
+	APIKey    string
+	BaseURL   string        // Optional: provider API base URL
+	BlockRisk string        // Block addresses at or above this risk level (default high)
+	CacheTTL  time.Duration // How long results are reused (default 24h)
+}
+
+// NewScreener creates the screener selected by cfg.Provider, wrapped in a cache.
+// An empty provider disables screening and returns nil.
+func NewScreener(cfg Config) (Screener, error) {
+	var s Screener
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderChainalysis:
+		s = NewChainalysis(cfg.APIKey, cfg.BaseURL)
+	case ProviderTRM:
+		s = NewTRM(cfg.APIKey, cfg.BaseURL)
+	default:
+		return nil, fmt.Errorf("unknown screening provider: %s", cfg.Provider)
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("screening api key is required for %s", cfg.Provider)
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return NewCache(s, ttl), nil
+}
+
+// Blocked reports whether a result should stop the payment.
+// Sanctioned addresses are always blocked; otherwise the risk is compared to blockRisk.
+func (r *Result) Blocked(blockRisk string) bool {
+	if r.Sanctioned {
+		return true
+	}
+	threshold, ok := riskOrder[strings.ToLower(blockRisk)]
+	if !ok || threshold == 0 {
+		threshold = riskOrder[RiskHigh]
+	}
+	return riskOrder[r.Risk] >= threshold
+}
+
+// BlockedError is returned when a recipient fails screening.
+type BlockedError struct {
+	Result *Result
+}
+
+func (e *BlockedError) Error() string {
+	reason := "risk " + e.Result.Risk
+	if e.Result.Sanctioned {
+		reason = "sanctioned"
+	}
+	if len(e.Result.Categories) > 0 {
+		reason += " (" + strings.Join(e.Result.Categories, ", ") + ")"
+	}
+	return fmt.Sprintf("recipient %s blocked by %s screening: %s", e.Result.Address, e.Result.Provider, reason)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *BlockedError) ErrorCode() string { return Code }
+
+// higherRisk returns the higher of two risk levels.
+func higherRisk(a, b string) string {
+	if riskOrder[b] > riskOrder[a] {
+		return b
+	}
+	return a
+}
+
+// normalize lowercases EVM addresses; TRON Base58 addresses are case-sensitive.
+func normalize(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -0,0 +1,115 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sanctioned = "0x7F367cC41522cE07553e823bf3be79A889DEbe1B"
+
+func TestChainalysis(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api-key", r.Header.Get("X-API-Key"))
+		if r.URL.Path == "/api/v1/address/"+sanctioned {
+			w.Write([]byte(`{"identifications":[{"category":"sanctions","name":"SANCTIONS: OFAC SDN"}]}`))
+			return
+		}
+		w.Write([]byte(`{"identifications":[]}`))
+	}))
+	defer srv.Close()
+	c := NewChainalysis("api-key", srv.URL)
+
+	result, err := c.Screen(context.Background(), 1, sanctioned)
+	require.NoError(t, err)
+	assert.True(t, result.Sanctioned)
+	assert.True(t, result.Blocked(RiskHigh))
+	assert.Equal(t, []string{"sanctions"}, result.Categories)
+
+	result, err = c.Screen(context.Background(), 1, "0x0000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	assert.False(t, result.Blocked(RiskHigh))
+}
+
+func TestTRM(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api-key", user)
+		assert.Equal(t, "api-key", pass)
+		var body []map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "base", body[0]["chain"])
+		w.Write([]byte(`[{"address":"0x1","addressRiskIndicators":[
+			{"category":"Gambling","categoryRiskScoreLevelLabel":"Medium"},
+			{"category":"Mixer","categoryRiskScoreLevelLabel":"High"}],"entities":[]}]`))
+	}))
+	defer srv.Close()
+
+	result, err := NewTRM("api-key", srv.URL).Screen(context.Background(), 8453, "0x1")
+	require.NoError(t, err)
+	assert.False(t, result.Sanctioned)
+	assert.Equal(t, RiskHigh, result.Risk)
+	assert.True(t, result.Blocked(RiskHigh))
+	assert.False(t, result.Blocked(RiskSevere))
+
+	_, err = NewTRM("api-key", srv.URL).Screen(context.Background(), 999, "0x1")
+	assert.Error(t, err)
+}
+
+type countingScreener struct {
+	calls atomic.Int32
+	fail  bool
+}
+
+func (s *countingScreener) Provider() string { return "test" }
+
+func (s *countingScreener) Screen(ctx context.Context, chainID uint64, address string) (*Result, error) {
+	s.calls.Add(1)
+	if s.fail {
+		return nil, assert.AnError
+	}
+	return &Result{Address: address, Risk: RiskLow}, nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingScreener{}
+	c := NewCache(inner, time.Hour)
+
+	for _, addr := range []string{"0xAbC", "0xabc"} {
+		_, err := c.Screen(ctx, 1, addr)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, inner.calls.Load(), "EVM addresses share a cache entry regardless of case")
+
+	_, err := c.Screen(ctx, 137, "0xabc")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, inner.calls.Load(), "cached per chain")
+
+	inner.fail = true
+	for i := 0; i < 2; i++ {
+		_, err = c.Screen(ctx, 1, "0xdef")
+		assert.Error(t, err)
+	}
+	assert.EqualValues(t, 4, inner.calls.Load(), "errors are not cached")
+}
+
+func TestNewScreener(t *testing.T) {
+	s, err := NewScreener(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = NewScreener(Config{Provider: ProviderTRM})
+	assert.Error(t, err, "api key required")
+
+	_, err = NewScreener(Config{Provider: "acme", APIKey: "k"})
+	assert.Error(t, err)
+}
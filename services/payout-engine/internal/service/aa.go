@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/protocol-bank/payout-engine/internal/aa"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// userOpReceiptTimeout 等待 bundler 打包 UserOperation 的时间
+const userOpReceiptTimeout = 60 * time.Second
+
+// processUserOpJob 通过 ERC-4337 智能账户执行支付:
+// build callData → nonce (per-job lane) → gas/paymaster → sign → bundler.
+func (s *PayoutService) processUserOpJob(ctx context.Context, client *rpcpool.Pool, aaClient *aa.Client, job *queue.Job) (*queue.JobResult, error) {
+	fail := func(err error) (*queue.JobResult, error) {
+		return &queue.JobResult{JobID: job.ID, Success: false, Error: err}, nil
+	}
+
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return fail(fmt.Errorf("invalid amount: %s", job.Amount))
+	}
+
+	// 构建 SimpleAccount.execute 调用
+	toAddr := common.HexToAddress(job.ToAddress)
+	var callData []byte
+	var err error
+	if isNativeToken(job.TokenAddress) {
+		callData, err = aaClient.ExecuteCallData(toAddr, amount, nil)
+	} else {
+		transferData, packErr := s.erc20ABI.Pack("transfer", toAddr, amount)
+		if packErr != nil {
+			return fail(fmt.Errorf("failed to pack transfer data: %w", packErr))
+		}
+		callData, err = aaClient.ExecuteCallData(common.HexToAddress(job.TokenAddress), big.NewInt(0), transferData)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to build callData: %w", err))
+	}
+
+	// 每个任务使用独立的 nonce key，避免并发冲突
+	nonceVal, err := aaClient.GetNonce(ctx, aa.NonceKey(job.ID))
+	if err != nil {
+		return fail(err)
+	}
+	// 该任务的 nonce lane 已被消耗说明之前的尝试已上链，禁止重复支付
+	if aa.NonceSequence(nonceVal) > 0 {
+		return fail(queue.Permanent(fmt.Errorf("user operation for job %s already executed (nonce lane consumed)", job.ID)))
+	}
+
+	// EIP-1559 费用
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return fail(err)
+	}
+
+	op := &aa.UserOperation{
+		Sender:               aaClient.SmartAccount(),
+		Nonce:                (*hexutil.Big)(nonceVal),
+		InitCode:             hexutil.Bytes{},
+		CallData:             callData,
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(0)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(0)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(0)),
+		MaxFeePerGas:         (*hexutil.Big)(fees.FeeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(fees.TipCap),
+		PaymasterAndData:     hexutil.Bytes{},
+		Signature:            aa.DummySignature,
+	}
+
+	// Gas 估算 (有 paymaster 时由 paymaster 给出并赞助)
+	var est *aa.GasEstimate
+	if aaClient.Sponsored() {
+		est, err = aaClient.Sponsor(ctx, op)
+	} else {
+		est, err = aaClient.EstimateGas(ctx, op)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	op.CallGasLimit = est.CallGasLimit
+	op.VerificationGasLimit = est.VerificationGasLimit
+	op.PreVerificationGas = est.PreVerificationGas
+	if len(est.PaymasterAndData) > 0 {
+		op.PaymasterAndData = est.PaymasterAndData
+	}
+
+	// 智能账户 owner 签名 (EIP-191 包装的 userOpHash)
+	chainID := new(big.Int).SetUint64(job.ChainID)
+	signer := s.signerFor(job.ChainID)
+	signCtx, signSpan := tracing.Start(ctx, "kms.sign", trace.WithAttributes(
+		tracing.AttrChainID.Int64(int64(job.ChainID)),
+		attribute.String("kms.provider", signer.Provider()),
+	))
+	digest := op.SigningHash(aaClient.EntryPoint(), chainID)
+	signOp := jobSigningOp(job, signPurposeUserOp)
+	sig, err := signer.SignHash(signingContext(signCtx, signOp), digest)
+	tracing.End(signSpan, err)
+	if auditErr := s.auditSigning(ctx, job.ChainID, signOp, digest, signer.Provider(), signer.Address().Hex(), err); auditErr != nil {
+		return fail(auditErr)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to sign user operation: %w", err))
+	}
+	sig[64] += 27
+	op.Signature = sig
+
+	sendCtx, sendSpan := tracing.Start(ctx, "bundler.send", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(tracing.AttrChainID.Int64(int64(job.ChainID))))
+	userOpHash, err := aaClient.Send(sendCtx, op)
+	if err == nil {
+		sendSpan.SetAttributes(attribute.String("payout.user_op_hash", userOpHash.Hex()))
+	}
+	tracing.End(sendSpan, err)
+	if err != nil {
+		return fail(err)
+	}
+
+	log.Info().
+		Str("job_id", job.ID).
+		Str("user_op_hash", userOpHash.Hex()).
+		Bool("sponsored", aaClient.Sponsored()).
+		Msg("UserOperation submitted to bundler")
+
+	// 尝试获取实际交易哈希
+	receipt, err := aaClient.WaitForReceipt(ctx, userOpHash, userOpReceiptTimeout)
+	if err != nil {
+		return fail(fmt.Errorf("failed to wait for user operation: %w", err))
+	}
+	if receipt == nil {
+		log.Warn().Str("job_id", job.ID).Str("user_op_hash", userOpHash.Hex()).Msg("UserOperation not yet included, reporting userOpHash")
+		return &queue.JobResult{JobID: job.ID, Success: true, TxHash: userOpHash.Hex()}, nil
+	}
+	if !receipt.Success {
+		return fail(fmt.Errorf("user operation reverted: %s", receipt.Reason))
+	}
+
+	return &queue.JobResult{
+		JobID:   job.ID,
+		Success: true,
+		TxHash:  receipt.Receipt.TransactionHash.Hex(),
+	}, nil
+}
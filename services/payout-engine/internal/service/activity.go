@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/activity"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// evmHistoryClient 读取收款地址 nonce 和余额所需的 RPC 接口
+type evmHistoryClient interface {
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// activityRequired 任务金额 (整币) 是否达到活跃度检查门槛
+func (s *PayoutService) activityRequired(job *queue.Job) bool {
+	if s.activityThreshold == nil {
+		return true
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return false
+	}
+	return s.wholeUnits(job.ChainID, job.Asset(), job.TokenDecimals, amount).Cmp(s.activityThreshold) >= 0
+}
+
+// checkRecipientActivity 签名前检查大额支付的收款地址是否为全新、新近启用或长期休眠的地址
+// (防止地址输错或被替换)。命中时返回不可重试的 activity.FlaggedError (错误码 RECIPIENT_REVIEW_REQUIRED)，
+// 人工确认后重新入队；链上查询失败时返回可重试的错误，不放行。
+func (s *PayoutService) checkRecipientActivity(ctx context.Context, job *queue.Job) error {
+	cfg := s.cfg.RecipientActivity
+	if !cfg.Enabled || job.Testnet || !s.activityRequired(job) {
+		return nil
+	}
+	if approval := job.RecipientApproval; approval != nil {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("to", job.ToAddress).
+			Str("approved_by", approval.ApprovedBy).
+			Str("reason", approval.Reason).
+			Msg("Recipient activity check approved")
+		return nil
+	}
+
+	history, err := s.recipientHistory(ctx, job.ChainID, job.ToAddress)
+	if err != nil {
+		return fmt.Errorf("recipient activity check failed: %w", err)
+	}
+	if flagged := cfg.Check(job.ToAddress, history, time.Now()); flagged != nil {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("to", job.ToAddress).
+			Strs("reasons", flagged.Reasons).
+			Uint64("tx_count", history.TxCount).
+			Time("first_seen", history.FirstSeen).
+			Time("last_seen", history.LastSeen).
+			Msg("Recipient held for review")
+		return queue.Permanent(flagged)
+	}
+	return nil
+}
+
+// recipientHistory 读取收款地址的链上活跃记录
+func (s *PayoutService) recipientHistory(ctx context.Context, chainID uint64, address string) (activity.History, error) {
+	if tronClient, ok := s.tronClient(chainID); ok {
+		return tronHistory(tronClient, address)
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return activity.History{}, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	return evmHistory(ctx, client, s.explorer, chainID, address)
+}
+
+// evmHistory 由 nonce 和余额判断地址是否使用过；配置了浏览器 API 时补充首次/最近交易时间
+func evmHistory(ctx context.Context, client evmHistoryClient, explorer *activity.Explorer, chainID uint64, address string) (activity.History, error) {
+	addr := common.HexToAddress(address)
+	nonce, err := client.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return activity.History{}, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	balance, err := client.BalanceAt(ctx, addr, nil)
+	if err != nil {
+		return activity.History{}, fmt.Errorf("failed to read balance: %w", err)
+	}
+	history := activity.History{TxCount: nonce, Balance: balance}
+	if explorer.Supports(chainID) {
+		history.FirstSeen, history.LastSeen, err = explorer.Activity(ctx, chainID, address)
+		if err != nil {
+			return activity.History{}, err
+		}
+	}
+	return history, nil
+}
+
+// tronHistory TRON 账户记录创建时间和最近操作时间；未激活的账户查询不到，视为全新地址
+func tronHistory(client tronAccountClient, address string) (activity.History, error) {
+	account, err := client.GetAccount(address)
+	if err != nil {
+		if err.Error() == "account not found" {
+			return activity.History{Balance: big.NewInt(0)}, nil
+		}
+		return activity.History{}, fmt.Errorf("failed to read account: %w", err)
+	}
+	history := activity.History{Balance: big.NewInt(account.GetBalance())}
+	if created := account.GetCreateTime(); created > 0 {
+		history.FirstSeen = time.UnixMilli(created)
+	}
+	if latest := max(account.GetLatestOprationTime(), account.GetLatestConsumeTime(), account.GetLatestConsumeFreeTime()); latest > 0 {
+		history.LastSeen = time.UnixMilli(latest)
+	}
+	return history, nil
+}
+
+// ApproveRecipient 人工确认被活跃度检查拦截的收款地址并重新入队。批准人和理由随任务记录。
+func (s *PayoutService) ApproveRecipient(ctx context.Context, jobID, approvedBy, reason string) (*queue.Job, error) {
+	if approvedBy == "" || reason == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("approved_by and reason are required")}
+	}
+	job, err := s.queue.ApproveRecipientDeadLetter(ctx, jobID, activity.Code, queue.PolicyOverride{
+		ApprovedBy: approvedBy,
+		Reason:     reason,
+		ApprovedAt: time.Now().UTC(),
+	})
+	switch {
+	case errors.Is(err, queue.ErrDeadLetterNotFound):
+		return nil, ErrJobNotFound
+	case errors.Is(err, queue.ErrOverrideNotApplicable):
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("job %s was not held for recipient review", jobID)}
+	case err != nil:
+		return nil, err
+	}
+	log.Warn().
+		Str("job_id", jobID).
+		Str("batch_id", job.BatchID).
+		Str("to", job.ToAddress).
+		Str("approved_by", approvedBy).
+		Str("reason", reason).
+		Msg("Recipient approved, job requeued")
+	return job, nil
+}
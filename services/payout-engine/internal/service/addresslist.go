@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+)
+
+// ErrAddressListEntryNotFound 名单中没有该地址
+var ErrAddressListEntryNotFound = errors.New("address list entry not found")
+
+// checkRecipientLists 拒绝名单 (deny) 中的收款地址；批次中某代币合计金额达到
+// RECIPIENT_ALLOWLIST_THRESHOLD 时，所有收款地址须在白名单 (allow) 中。未配置任务账本时不检查。
+func (s *PayoutService) checkRecipientLists(ctx context.Context, req *BatchPayoutRequest) error {
+	if s.ledger == nil {
+		return nil
+	}
+	addresses := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		addresses[i] = normalizeListAddress(item.RecipientAddress)
+	}
+	entries, err := s.ledger.MatchAddressLists(ctx, req.UserID, req.ChainID, addresses)
+	if err != nil {
+		return &UnavailableError{Err: fmt.Errorf("failed to check recipient lists: %w", err)}
+	}
+	return recipientListViolation(req, entries, s.allowlistRequired(req))
+}
+
+// recipientListViolation 按已匹配的名单条目检查批次
+func recipientListViolation(req *BatchPayoutRequest, entries []ledger.AddressListEntry, requireAllow bool) error {
+	denied := make(map[string]ledger.AddressListEntry)
+	allowed := make(map[string]bool)
+	for _, e := range entries {
+		switch e.List {
+		case ledger.ListDeny:
+			denied[e.Address] = e
+		case ledger.ListAllow:
+			allowed[e.Address] = true
+		}
+	}
+	for i, item := range req.Items {
+		address := normalizeListAddress(item.RecipientAddress)
+		if e, ok := denied[address]; ok {
+			msg := fmt.Sprintf("item[%d]: recipient %s is on the deny list", i, item.RecipientAddress)
+			if e.Reason != "" {
+				msg += ": " + e.Reason
+			}
+			return errors.New(msg)
+		}
+		if requireAllow && !allowed[address] {
+			return fmt.Errorf("item[%d]: recipient %s is not on the allow list (required for batches of this size)", i, item.RecipientAddress)
+		}
+	}
+	return nil
+}
+
+// allowlistRequired 批次中任一代币的合计金额 (按精度换算为整币) 是否达到白名单门槛
+func (s *PayoutService) allowlistRequired(req *BatchPayoutRequest) bool {
+	if s.allowlistThreshold == nil {
+		return false
+	}
+	totals := make(map[string]*big.Rat)
+	for _, item := range req.Items {
+		amount, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok {
+			continue
+		}
+		key := normalizeTokenKey(item.asset())
+		if totals[key] == nil {
+			totals[key] = new(big.Rat)
+		}
+		totals[key].Add(totals[key], s.wholeUnits(req.ChainID, item.asset(), item.TokenDecimals, amount))
+	}
+	for _, total := range totals {
+		if total.Cmp(s.allowlistThreshold) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wholeUnits 按代币精度 (原生代币按链精度) 将最小单位金额换算为整币
+func (s *PayoutService) wholeUnits(chainID uint64, tokenAddress string, tokenDecimals uint32, amount *big.Int) *big.Rat {
+	decimals := int(tokenDecimals)
+	if isNativeToken(tokenAddress) {
+		decimals = s.chainConfig(chainID).Decimals
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(amount, scale)
+}
+
+// AddAddressListEntry 添加收款地址名单条目 (已存在时更新原因)
+func (s *PayoutService) AddAddressListEntry(ctx context.Context, entry ledger.AddressListEntry) (*ledger.AddressListEntry, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	if entry.List != ledger.ListAllow && entry.List != ledger.ListDeny {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("list must be %q or %q", ledger.ListAllow, ledger.ListDeny)}
+	}
+	if !common.IsHexAddress(entry.Address) && !isTronAddress(entry.Address) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid address: %s", entry.Address)}
+	}
+	entry.Address = normalizeListAddress(entry.Address)
+	if err := s.ledger.AddAddressListEntry(ctx, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RemoveAddressListEntry 删除收款地址名单条目
+func (s *PayoutService) RemoveAddressListEntry(ctx context.Context, list, userID string, chainID uint64, address string) error {
+	if s.ledger == nil {
+		return &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	found, err := s.ledger.RemoveAddressListEntry(ctx, list, userID, chainID, normalizeListAddress(address))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrAddressListEntryNotFound
+	}
+	return nil
+}
+
+// AddressListEntries 查询收款地址名单
+func (s *PayoutService) AddressListEntries(ctx context.Context, q ledger.AddressListQuery) ([]ledger.AddressListEntry, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	q.Address = normalizeListAddress(q.Address)
+	return s.ledger.AddressListEntries(ctx, q)
+}
+
+// normalizeListAddress EVM 地址小写化，TRON Base58 地址区分大小写保持原样
+func normalizeListAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// checkAllowlist 入队前检查代币 (合约地址或 TRC10 资产 ID) 是否在租户白名单内 (原生代币和测试网始终允许)
+func (s *PayoutService) checkAllowlist(tenant string, chainID uint64, tokenAddress string) error {
+	if !s.allowlist.Enabled() || isNativeToken(tokenAddress) || s.isTestnetChain(chainID) {
+		return nil
+	}
+	if _, ok := s.allowlist.Lookup(tenant, chainID, tokenAddress); !ok {
+		return fmt.Errorf("token %s is not allowlisted on chain %d", tokenAddress, chainID)
+	}
+	return nil
+}
+
+// verifyToken 构建交易前在链上核对白名单代币的 symbol/decimals (TRC10 为简称和精度)，
+// 防止配置错误或地址指向非预期合约。结果按 (chain, token) 缓存。
+func (s *PayoutService) verifyToken(ctx context.Context, job *queue.Job) error {
+	asset := job.Asset()
+	if !s.allowlist.Enabled() || isNativeToken(asset) || s.isTestnetChain(job.ChainID) {
+		return nil
+	}
+	token, ok := s.allowlist.Lookup(job.UserID, job.ChainID, asset)
+	if !ok {
+		return fmt.Errorf("token %s is not allowlisted on chain %d", asset, job.ChainID)
+	}
+
+	cacheKey := fmt.Sprintf("%d:%s", job.ChainID, strings.ToLower(token.Address))
+	if _, ok := s.verifiedTokens.Load(cacheKey); ok {
+		return nil
+	}
+
+	symbol, decimals, err := s.tokenMetadata(ctx, job.ChainID, token.Address)
+	if err != nil {
+		return err
+	}
+
+	if symbol != token.Symbol || decimals != uint64(token.Decimals) {
+		log.Error().
+			Uint64("chain_id", job.ChainID).
+			Str("token_address", token.Address).
+			Str("expected_symbol", token.Symbol).
+			Str("onchain_symbol", symbol).
+			Uint64("onchain_decimals", decimals).
+			Msg("Allowlisted token does not match on-chain contract")
+		return fmt.Errorf("token %s does not match allowlist (on-chain %s/%d, expected %s/%d)",
+			token.Address, symbol, decimals, token.Symbol, token.Decimals)
+	}
+
+	s.verifiedTokens.Store(cacheKey, struct{}{})
+	return nil
+}
+
+// tokenMetadata 读取代币的 symbol 和 decimals (ERC20、TRC20 合约地址或 TRC10 资产 ID)
+func (s *PayoutService) tokenMetadata(ctx context.Context, chainID uint64, asset string) (string, uint64, error) {
+	if tronClient, ok := s.tronClient(chainID); ok {
+		if isTRC10Asset(asset) {
+			return trc10Metadata(tronClient, asset)
+		}
+		symbol, err := tronClient.TRC20GetSymbol(asset)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read token symbol: %w", err)
+		}
+		decimals, err := tronClient.TRC20GetDecimals(asset)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read token decimals: %w", err)
+		}
+		return symbol, decimals.Uint64(), nil
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	return s.readERC20Metadata(ctx, client, common.HexToAddress(asset))
+}
+
+// readERC20Metadata 读取 ERC20 合约的 symbol 和 decimals
+func (s *PayoutService) readERC20Metadata(ctx context.Context, client ethCaller, tokenAddr common.Address) (string, uint64, error) {
+	code, err := client.CodeAt(ctx, tokenAddr, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token code: %w", err)
+	}
+	if len(code) == 0 {
+		return "", 0, fmt.Errorf("%w: no contract deployed at %s", errNotTokenContract, tokenAddr.Hex())
+	}
+
+	call := func(method string) ([]interface{}, error) {
+		data, err := s.erc20ABI.Pack(method)
+		if err != nil {
+			return nil, err
+		}
+		out, err := client.CallContract(ctx, ethereum.CallMsg{To: &tokenAddr, Data: data}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s call failed: %w", method, err)
+		}
+		values, err := s.erc20ABI.Unpack(method, out)
+		if err != nil || len(values) != 1 {
+			return nil, fmt.Errorf("%w: failed to decode %s: %v", errNotTokenContract, method, err)
+		}
+		return values, nil
+	}
+
+	symbolOut, err := call("symbol")
+	if err != nil {
+		return "", 0, err
+	}
+	decimalsOut, err := call("decimals")
+	if err != nil {
+		return "", 0, err
+	}
+	return symbolOut[0].(string), uint64(decimalsOut[0].(uint8)), nil
+}
+
+// ethCaller 读取合约所需的最小 RPC 接口
+type ethCaller interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
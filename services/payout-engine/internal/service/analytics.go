@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+)
+
+// 网络费统计周期
+const (
+	GasIntervalDay   = "day"
+	GasIntervalWeek  = "week"
+	GasIntervalMonth = "month"
+)
+
+// defaultGasRange 未指定起始时间时统计最近 30 天
+const defaultGasRange = 30 * 24 * time.Hour
+
+// GasAnalyticsQuery 网络费统计条件
+type GasAnalyticsQuery struct {
+	UserID   string
+	ChainID  uint64
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// GasReport 各链网络费随时间的变化 (金额为原生代币最小单位)
+type GasReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Interval string           `json:"interval"`
+	Chains   []ChainGasReport `json:"chains"`
+}
+
+// ChainGasReport 一条链的网络费统计
+type ChainGasReport struct {
+	ChainID     uint64 `json:"chain_id"`
+	Name        string `json:"name"`
+	NativeToken string `json:"native_token"`
+	Decimals    int    `json:"decimals"`
+	GasPeriod
+	Periods []GasPeriod `json:"periods"`
+}
+
+// GasPeriod 一个统计周期的网络费。
+// BatchingSavings 为合并交易中的任务按同期单笔交易的平均网络费估算的节省额 (为负表示合并反而更贵)。
+type GasPeriod struct {
+	Start            *time.Time `json:"start,omitempty"`
+	Payouts          int64      `json:"payouts"`
+	Transactions     int64      `json:"transactions"`
+	GasSpent         string     `json:"gas_spent"`
+	AvgCostPerPayout string     `json:"avg_cost_per_payout"`
+	BatchedPayouts   int64      `json:"batched_payouts"`
+	BatchingSavings  string     `json:"batching_savings"`
+}
+
+// GasAnalytics 从任务账本统计各链网络费、每笔支付的平均成本和合并交易的节省额。
+// 仅包含已取得回执的交易 (EVM 为 gasUsed × effectiveGasPrice，TRON 为燃烧的 TRX)。
+func (s *PayoutService) GasAnalytics(ctx context.Context, q GasAnalyticsQuery) (*GasReport, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	switch q.Interval {
+	case "":
+		q.Interval = GasIntervalDay
+	case GasIntervalDay, GasIntervalWeek, GasIntervalMonth:
+	default:
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid interval: %s (expected day, week or month)", q.Interval)}
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultGasRange)
+	}
+	if !q.From.Before(q.To) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("from must be before to")}
+	}
+
+	usage, err := s.ledger.GasUsage(ctx, ledger.GasUsageQuery{
+		UserID:   q.UserID,
+		ChainID:  q.ChainID,
+		From:     q.From,
+		To:       q.To,
+		Interval: q.Interval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GasReport{
+		From:     q.From.UTC(),
+		To:       q.To.UTC(),
+		Interval: q.Interval,
+		Chains:   buildGasReport(usage, s.chainConfigs()),
+	}, nil
+}
+
+// gasTotals 累计一个周期或整条链的网络费
+type gasTotals struct {
+	payouts, txs, batchedPayouts int64
+	fee, savings                 *big.Int
+}
+
+func newGasTotals() *gasTotals {
+	return &gasTotals{fee: new(big.Int), savings: new(big.Int)}
+}
+
+func (t *gasTotals) period(start *time.Time) GasPeriod {
+	avg := new(big.Int)
+	if t.payouts > 0 {
+		avg.Quo(t.fee, big.NewInt(t.payouts))
+	}
+	return GasPeriod{
+		Start:            start,
+		Payouts:          t.payouts,
+		Transactions:     t.txs,
+		GasSpent:         t.fee.String(),
+		AvgCostPerPayout: avg.String(),
+		BatchedPayouts:   t.batchedPayouts,
+		BatchingSavings:  t.savings.String(),
+	}
+}
+
+// buildGasReport 按链汇总账本统计。合并交易的节省额以同一周期单笔交易的平均网络费为基准，
+// 该周期没有单笔交易时使用整个统计范围内的平均值。
+func buildGasReport(usage []ledger.GasUsage, chains map[uint64]config.ChainConfig) []ChainGasReport {
+	byChain := make(map[uint64][]ledger.GasUsage)
+	var order []uint64
+	for _, u := range usage {
+		if _, ok := byChain[u.ChainID]; !ok {
+			order = append(order, u.ChainID)
+		}
+		byChain[u.ChainID] = append(byChain[u.ChainID], u)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	reports := make([]ChainGasReport, 0, len(order))
+	for _, chainID := range order {
+		rows := byChain[chainID]
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Period.Before(rows[j].Period) })
+
+		// 整个范围内单笔交易的平均网络费
+		singleFee, singleTxs := new(big.Int), int64(0)
+		for _, u := range rows {
+			singleFee.Add(singleFee, parseAmount(u.SingleFee))
+			singleTxs += u.SingleTxs
+		}
+
+		chain := chains[chainID]
+		report := ChainGasReport{ChainID: chainID, Name: chain.Name, NativeToken: chain.NativeToken, Decimals: chain.Decimals}
+		total := newGasTotals()
+		for _, u := range rows {
+			p := newGasTotals()
+			p.payouts, p.txs, p.batchedPayouts = u.Payouts, u.Transactions, u.BatchedPayouts
+			p.fee.Set(parseAmount(u.Fee))
+
+			baseFee, baseTxs := parseAmount(u.SingleFee), u.SingleTxs
+			if baseTxs == 0 {
+				baseFee, baseTxs = singleFee, singleTxs
+			}
+			if u.BatchedPayouts > 0 && baseTxs > 0 {
+				p.savings.Mul(baseFee, big.NewInt(u.BatchedPayouts))
+				p.savings.Quo(p.savings, big.NewInt(baseTxs))
+				p.savings.Sub(p.savings, parseAmount(u.BatchedFee))
+			}
+
+			total.payouts += p.payouts
+			total.txs += p.txs
+			total.batchedPayouts += p.batchedPayouts
+			total.fee.Add(total.fee, p.fee)
+			total.savings.Add(total.savings, p.savings)
+
+			start := u.Period
+			report.Periods = append(report.Periods, p.period(&start))
+		}
+		report.GasPeriod = total.period(nil)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// parseAmount 解析账本中的整数金额 (无效时为 0)
+func parseAmount(v string) *big.Int {
+	n, ok := new(big.Int).SetString(v, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
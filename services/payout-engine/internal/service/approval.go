@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/approval"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// BatchApprovalResult 审批人签名后的批次状态
+type BatchApprovalResult struct {
+	BatchID    string
+	Operator   string   // 本次签名的审批人
+	ApprovedBy []string // 已签名的审批人
+	Required   int      // 放行所需的审批人数
+	Released   bool     // 已达到人数并放行 (入队或进入定时)
+	Response   *BatchPayoutResponse
+}
+
+// batchValueUSD 批次合计美元价值 (按提交金额计算)，返回未定价的代币
+func (s *PayoutService) batchValueUSD(req *BatchPayoutRequest) (*big.Rat, []string) {
+	total := new(big.Rat)
+	seen := make(map[string]bool)
+	var unpriced []string
+	for _, item := range req.Items {
+		amount, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok {
+			continue
+		}
+		price, ok := s.approval.Price(req.ChainID, item.asset())
+		if !ok {
+			token := item.asset()
+			if isNativeToken(token) {
+				token = approval.NativeToken
+			}
+			if !seen[token] {
+				seen[token] = true
+				unpriced = append(unpriced, token)
+			}
+			continue
+		}
+		value := s.wholeUnits(req.ChainID, item.TokenAddress, item.TokenDecimals, amount)
+		total.Add(total, value.Mul(value, price))
+	}
+	sort.Strings(unpriced)
+	return total, unpriced
+}
+
+// requiresApproval 批次是否须审批后才入队 (测试网支付无真实价值，不审批)
+func (s *PayoutService) requiresApproval(req *BatchPayoutRequest) bool {
+	if s.approval == nil || s.isTestnetChain(req.ChainID) {
+		return false
+	}
+	value, unpriced := s.batchValueUSD(req)
+	return s.approval.Requires(value, len(unpriced) > 0)
+}
+
+// holdForApproval 保存待审批批次，达到审批人数后由 ApproveBatch 放行
+func (s *PayoutService) holdForApproval(ctx context.Context, req *BatchPayoutRequest, requestHash string) (*BatchPayoutResponse, error) {
+	existing, err := s.queue.GetPendingApproval(ctx, req.UserID, req.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.State == queue.ApprovalStatePending {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is already pending approval", req.BatchID)}
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pending approval: %w", err)
+	}
+	value, unpriced := s.batchValueUSD(req)
+	batch := &queue.PendingApproval{
+		UserID:        req.UserID,
+		BatchID:       req.BatchID,
+		Request:       data,
+		RequestHash:   requestHash,
+		Items:         len(req.Items),
+		ValueUSD:      value.FloatString(2),
+		Unpriced:      unpriced,
+		CorrelationID: correlation.FromContext(ctx),
+		CreatedAt:     time.Now(),
+	}
+	if err := s.queue.HoldForApproval(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to hold batch for approval: %w", err)
+	}
+	log.Warn().
+		Str("batch_id", req.BatchID).
+		Str("user_id", req.UserID).
+		Str(correlation.LogField, batch.CorrelationID).
+		Int("items", batch.Items).
+		Str("value_usd", batch.ValueUSD).
+		Strs("unpriced", unpriced).
+		Msg("Batch held for approval")
+
+	return &BatchPayoutResponse{
+		BatchID:   req.BatchID,
+		Status:    BatchStatusPendingApproval,
+		Message:   fmt.Sprintf("Batch of %d payments (%s USD) requires %d operator approval(s) before it is queued", batch.Items, batch.ValueUSD, s.approval.Quorum()),
+		ExecuteAt: req.ExecuteAt,
+	}, nil
+}
+
+// ApproveBatch 审批人对待审批批次签名 (EIP-191 personal_sign，签名文本见 approval.Message)。
+// 达到审批人数后放行: execute_at 在未来的批次进入定时，否则立即预检余额并入队。
+// 放行时链暂时不可用则批次保持待审批，任一已签名的审批人再次提交签名即重试放行。
+func (s *PayoutService) ApproveBatch(ctx context.Context, userID, batchID, signature string) (*BatchApprovalResult, error) {
+	if s.approval == nil {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch approval is not enabled")}
+	}
+	if userID == "" || batchID == "" || signature == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("user_id, batch_id and signature are required")}
+	}
+	batch, err := s.queue.GetPendingApproval(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil || batch.State != queue.ApprovalStatePending {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is not pending approval", batchID)}
+	}
+	operator, err := s.approval.Verify(approval.Message(userID, batchID, batch.RequestHash), signature)
+	if err != nil {
+		return nil, &InvalidArgumentError{Err: err}
+	}
+
+	updated, err := s.queue.AddApproval(ctx, userID, batchID, queue.Approval{
+		Operator:   operator.Hex(),
+		Signature:  signature,
+		ApprovedAt: time.Now(),
+	})
+	switch {
+	case errors.Is(err, queue.ErrAlreadyApproved) && len(batch.Approvals) >= s.approval.Quorum():
+		updated = batch // 已达到人数但上次放行未成功，重试放行
+	case errors.Is(err, queue.ErrAlreadyApproved), errors.Is(err, queue.ErrApprovalNotPending):
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s: %w", batchID, err)}
+	case err != nil:
+		return nil, err
+	}
+	log.Info().
+		Str("batch_id", batchID).
+		Str("user_id", userID).
+		Str(correlation.LogField, batch.CorrelationID).
+		Str("operator", operator.Hex()).
+		Int("approvals", len(updated.Approvals)).
+		Int("required", s.approval.Quorum()).
+		Msg("Batch approved by operator")
+
+	result := &BatchApprovalResult{
+		BatchID:    batchID,
+		Operator:   operator.Hex(),
+		ApprovedBy: updated.ApprovedBy(),
+		Required:   s.approval.Quorum(),
+	}
+	if len(updated.Approvals) < s.approval.Quorum() {
+		return result, nil
+	}
+	resp, err := s.releaseApprovedBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	result.Released = resp != nil
+	result.Response = resp
+	return result, nil
+}
+
+// releaseApprovedBatch 认领并放行已达到审批人数的批次 (其他审批人已在放行时返回 nil)
+func (s *PayoutService) releaseApprovedBatch(ctx context.Context, userID, batchID string) (*BatchPayoutResponse, error) {
+	batch, err := s.queue.ClaimPendingApproval(ctx, userID, batchID)
+	if err != nil || batch == nil {
+		return nil, err
+	}
+	ctx = correlation.WithID(ctx, batch.CorrelationID)
+
+	var req BatchPayoutRequest
+	err = json.Unmarshal(batch.Request, &req)
+	if err == nil {
+		err = s.validateRequest(ctx, &req)
+	}
+	var resp *BatchPayoutResponse
+	if err == nil {
+		if deferred(&req) {
+			resp, err = s.scheduleBatch(ctx, &req)
+		} else {
+			resp, err = s.submitBatch(ctx, &req)
+		}
+	}
+
+	switch {
+	case IsUnavailable(err):
+		if herr := s.queue.HoldForApproval(ctx, batch); herr != nil {
+			log.Error().Err(herr).Str("batch_id", batchID).Msg("Failed to restore pending approval")
+		}
+		return nil, err
+	case err != nil:
+		log.Error().
+			Err(err).
+			Str("batch_id", batchID).
+			Str("user_id", userID).
+			Str(correlation.LogField, batch.CorrelationID).
+			Msg("Approved batch failed to queue")
+		if ferr := s.queue.FinishPendingApproval(ctx, batch, queue.ApprovalStateFailed, err); ferr != nil {
+			log.Error().Err(ferr).Str("batch_id", batchID).Msg("Failed to record approved batch failure")
+		}
+		return nil, err
+	}
+	if err := s.queue.DeletePendingApproval(ctx, userID, batchID); err != nil {
+		log.Warn().Err(err).Str("batch_id", batchID).Msg("Failed to delete pending approval record")
+	}
+	log.Info().
+		Str("batch_id", batchID).
+		Str("user_id", userID).
+		Str(correlation.LogField, batch.CorrelationID).
+		Strs("approved_by", batch.ApprovedBy()).
+		Str("status", string(resp.Status)).
+		Msg("Approved batch released")
+	return resp, nil
+}
+
+// ApproveBatchByID 运维审批批次 (userID 可为空)
+func (s *PayoutService) ApproveBatchByID(ctx context.Context, userID, batchID, signature string) (*BatchApprovalResult, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ApproveBatch(ctx, owner, batchID, signature)
+}
+
+// PendingApprovals 等待审批的批次 (最早提交在前)
+func (s *PayoutService) PendingApprovals(ctx context.Context) ([]*queue.PendingApproval, error) {
+	return s.queue.PendingApprovals(ctx)
+}
+
+// ApprovalRequirement 放行所需的审批人数 (未启用审批时为 0)
+func (s *PayoutService) ApprovalRequirement() int {
+	if s.approval == nil {
+		return 0
+	}
+	return s.approval.Quorum()
+}
+
+// cancelPendingApproval 取消尚未放行的待审批批次。批次不在待审批索引中时返回 ok=false。
+func (s *PayoutService) cancelPendingApproval(ctx context.Context, userID, batchID string) (int, bool, error) {
+	batch, err := s.queue.ClaimPendingApproval(ctx, userID, batchID)
+	if err != nil || batch == nil {
+		return 0, false, err
+	}
+	if err := s.queue.FinishPendingApproval(ctx, batch, queue.ApprovalStateCancelled, nil); err != nil {
+		return 0, false, err
+	}
+	return batch.Items, true, nil
+}
+
+// pendingApprovalStatus 尚未放行的待审批批次状态 (没有审批记录时为 nil)
+func (s *PayoutService) pendingApprovalStatus(ctx context.Context, userID, batchID string) (*BatchStatusResult, error) {
+	batch, err := s.queue.GetPendingApproval(ctx, userID, batchID)
+	if err != nil || batch == nil {
+		return nil, err
+	}
+	result := &BatchStatusResult{
+		BatchID:           batchID,
+		TotalCount:        batch.Items,
+		CreatedAt:         batch.CreatedAt,
+		UpdatedAt:         batch.UpdatedAt,
+		Error:             batch.Error,
+		ApprovedBy:        batch.ApprovedBy(),
+		ApprovalsRequired: s.ApprovalRequirement(),
+		ApprovalMessage:   approval.Message(userID, batchID, batch.RequestHash),
+		ValueUSD:          batch.ValueUSD,
+	}
+	switch batch.State {
+	case queue.ApprovalStateCancelled:
+		result.Status = BatchStatusCancelled
+		result.CancelledCount = batch.Items
+	case queue.ApprovalStateFailed:
+		result.Status = BatchStatusFailed
+		result.FailedCount = batch.Items
+	default:
+		result.Status = BatchStatusPendingApproval
+		result.PendingCount = batch.Items
+	}
+	return result, nil
+}
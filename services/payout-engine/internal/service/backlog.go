@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// 队列积压告警事件
+const (
+	QueueEventBacklog  = "queue.backlog_alert"
+	QueueEventResolved = "queue.backlog_resolved"
+)
+
+// 积压告警条件
+const (
+	BacklogDepth      = "depth"       // 全部通道待投递任务数
+	BacklogJobAge     = "job_age"     // 最久的待处理任务等待秒数
+	BacklogChainDepth = "chain_depth" // 单链待投递任务数
+)
+
+// QueueAlert 队列积压告警/恢复 (POST 到 QUEUE_ALERT_WEBHOOK_URL，签名方式同批次回调)
+type QueueAlert struct {
+	Event     string         `json:"event"`
+	Condition string         `json:"condition"`
+	ChainID   uint64         `json:"chain_id,omitempty"` // chain_depth
+	Chain     string         `json:"chain,omitempty"`
+	Value     int64          `json:"value"` // 任务数或等待秒数
+	Threshold int64          `json:"threshold"`
+	Summary   string         `json:"summary"`
+	Backlog   *queue.Backlog `json:"backlog"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// backlogCheck 一个积压条件的检查结果
+type backlogCheck struct {
+	key    string // 告警状态键 (chain_depth 按链区分)
+	firing bool
+	alert  QueueAlert
+}
+
+// QueueBacklog 队列积压快照 (GET /queue/backlog)
+func (s *PayoutService) QueueBacklog(ctx context.Context) (*queue.Backlog, error) {
+	return s.queue.Backlog(ctx)
+}
+
+// RunQueueMonitor 定期检查队列积压，超过阈值时告警 (webhook/PagerDuty)，恢复后再通知一次
+func (s *PayoutService) RunQueueMonitor(ctx context.Context, interval time.Duration) {
+	cfg := s.cfg.QueueAlert
+	if cfg.MaxDepth <= 0 && cfg.MaxChainDepth <= 0 && cfg.MaxJobAge <= 0 {
+		log.Info().Msg("Queue backlog alerting disabled (no thresholds configured)")
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	log.Info().
+		Dur("interval", interval).
+		Int64("max_depth", cfg.MaxDepth).
+		Int64("max_chain_depth", cfg.MaxChainDepth).
+		Dur("max_job_age", cfg.MaxJobAge).
+		Msg("Queue backlog monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkBacklog(ctx)
+		}
+	}
+}
+
+func (s *PayoutService) checkBacklog(ctx context.Context) {
+	b, err := s.queue.Backlog(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read queue backlog")
+		return
+	}
+	for _, check := range s.backlogChecks(b) {
+		changed, err := s.queue.SetBacklogAlert(ctx, check.key, check.firing)
+		if err != nil {
+			log.Warn().Err(err).Str("condition", check.key).Msg("Failed to save queue backlog alert")
+			continue
+		}
+		if !changed {
+			continue
+		}
+		event := QueueEventResolved
+		if check.firing {
+			event = QueueEventBacklog
+		}
+		s.sendQueueAlert(ctx, event, check.key, check.alert)
+	}
+}
+
+// backlogChecks 按配置的阈值检查积压快照 (阈值为 0 的条件不检查)
+func (s *PayoutService) backlogChecks(b *queue.Backlog) []backlogCheck {
+	cfg := s.cfg.QueueAlert
+	var checks []backlogCheck
+
+	if cfg.MaxDepth > 0 {
+		checks = append(checks, backlogCheck{
+			key:    BacklogDepth,
+			firing: b.Queued > cfg.MaxDepth,
+			alert: QueueAlert{
+				Condition: BacklogDepth,
+				Value:     b.Queued,
+				Threshold: cfg.MaxDepth,
+				Summary:   fmt.Sprintf("%d payout jobs queued (threshold %d)", b.Queued, cfg.MaxDepth),
+			},
+		})
+	}
+	if cfg.MaxJobAge > 0 {
+		checks = append(checks, backlogCheck{
+			key:    BacklogJobAge,
+			firing: b.OldestJobAge > cfg.MaxJobAge,
+			alert: QueueAlert{
+				Condition: BacklogJobAge,
+				Value:     int64(b.OldestJobAge.Seconds()),
+				Threshold: int64(cfg.MaxJobAge.Seconds()),
+				Summary: fmt.Sprintf("oldest payout job waiting %s (threshold %s)",
+					b.OldestJobAge.Truncate(time.Second), cfg.MaxJobAge),
+			},
+		})
+	}
+	if cfg.MaxChainDepth > 0 {
+		// 已配置的链和队列中出现的链 (队列清空的链也要检查，以便发送恢复通知)
+		chainIDs := make(map[uint64]bool)
+		for id := range s.chainConfigs() {
+			chainIDs[id] = true
+		}
+		for _, c := range b.Chains {
+			chainIDs[c.ChainID] = true
+		}
+		ids := make([]uint64, 0, len(chainIDs))
+		for id := range chainIDs {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for _, id := range ids {
+			var queued int64
+			if c := b.Chain(id); c != nil {
+				queued = c.Queued
+			}
+			name := s.chainConfig(id).Name
+			checks = append(checks, backlogCheck{
+				key:    BacklogChainDepth + ":" + strconv.FormatUint(id, 10),
+				firing: queued > cfg.MaxChainDepth,
+				alert: QueueAlert{
+					Condition: BacklogChainDepth,
+					ChainID:   id,
+					Chain:     name,
+					Value:     queued,
+					Threshold: cfg.MaxChainDepth,
+					Summary:   fmt.Sprintf("%d payout jobs queued on %s (%d) (threshold %d)", queued, name, id, cfg.MaxChainDepth),
+				},
+			})
+		}
+	}
+
+	for i := range checks {
+		checks[i].alert.Backlog = b
+	}
+	return checks
+}
+
+// sendQueueAlert 记录并投递告警 (未配置告警地址和 PagerDuty 时只记录日志)
+func (s *PayoutService) sendQueueAlert(ctx context.Context, event, key string, alert QueueAlert) {
+	alert.Event = event
+	alert.Timestamp = time.Now().Unix()
+	entry := log.Warn()
+	if event == QueueEventBacklog {
+		entry = log.Error()
+	}
+	entry.
+		Str("event", event).
+		Str("condition", key).
+		Int64("value", alert.Value).
+		Int64("threshold", alert.Threshold).
+		Msg("Queue backlog alert: " + alert.Summary)
+
+	cfg := s.cfg.QueueAlert
+	if cfg.AlertURL != "" {
+		body, err := json.Marshal(alert)
+		if err == nil {
+			eventID := fmt.Sprintf("%s:%s:%d", event, key, alert.Timestamp)
+			if err := s.webhookSender.Post(ctx, cfg.AlertURL, cfg.AlertSecret, eventID, body); err != nil {
+				log.Warn().Err(err).Str("event", event).Str("condition", key).Msg("Failed to deliver queue backlog alert")
+			}
+		}
+	}
+	if cfg.PagerDutyKey != "" {
+		if err := s.sendPagerDuty(ctx, event, key, alert); err != nil {
+			log.Warn().Err(err).Str("event", event).Str("condition", key).Msg("Failed to deliver queue backlog alert to PagerDuty")
+		}
+	}
+}
+
+// pagerDutyEvent PagerDuty Events API v2 事件 (同一 dedup_key 的 trigger 和 resolve 对应同一个 incident)
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger / resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string      `json:"summary"`
+	Source        string      `json:"source"`
+	Severity      string      `json:"severity"`
+	Component     string      `json:"component"`
+	CustomDetails interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutyRequest 积压告警对应的 PagerDuty 事件
+func (s *PayoutService) pagerDutyRequest(event, key string, alert QueueAlert) pagerDutyEvent {
+	pd := pagerDutyEvent{
+		RoutingKey:  s.cfg.QueueAlert.PagerDutyKey,
+		EventAction: "resolve",
+		DedupKey:    "payout-engine:queue:" + key,
+	}
+	if event == QueueEventBacklog {
+		source, _ := os.Hostname()
+		if source == "" {
+			source = "payout-engine"
+		}
+		pd.EventAction = "trigger"
+		pd.Payload = &pagerDutyPayload{
+			Summary:       alert.Summary,
+			Source:        source,
+			Severity:      "error",
+			Component:     "payout-queue",
+			CustomDetails: alert,
+		}
+	}
+	return pd
+}
+
+// sendPagerDuty 投递到 PagerDuty (不校验签名头，只认 routing_key)
+func (s *PayoutService) sendPagerDuty(ctx context.Context, event, key string, alert QueueAlert) error {
+	body, err := json.Marshal(s.pagerDutyRequest(event, key, alert))
+	if err != nil {
+		return err
+	}
+	url := s.cfg.QueueAlert.PagerDutyURL
+	if url == "" {
+		url = config.DefaultPagerDutyURL
+	}
+	return s.webhookSender.Post(ctx, url, "", fmt.Sprintf("%s:%s:%d", event, key, alert.Timestamp), body)
+}
+
+// WriteQueueMetrics 以 Prometheus 文本格式输出队列积压指标 (GET /metrics)。
+// 读取积压失败 (如 Redis 不可用) 时只输出 payout_queue_metrics_up 0 并返回错误
+func (s *PayoutService) WriteQueueMetrics(ctx context.Context, w io.Writer) error {
+	b, err := s.queue.Backlog(ctx)
+	if err != nil {
+		b = nil
+	}
+	writeBacklogMetrics(w, b, func(chainID uint64) string { return s.chainConfig(chainID).Name })
+	return err
+}
+
+func writeBacklogMetrics(w io.Writer, b *queue.Backlog, chainName func(uint64) string) {
+	gauge := func(name, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	labels := func(c *queue.ChainBacklog) string {
+		return fmt.Sprintf(`{chain_id="%d",chain="%s"}`, c.ChainID, metrics.EscapeLabel(chainName(c.ChainID)))
+	}
+
+	gauge("payout_queue_metrics_up", "Whether the queue backlog could be read for this scrape.")
+	if b == nil {
+		fmt.Fprintln(w, "payout_queue_metrics_up 0")
+		return
+	}
+	fmt.Fprintln(w, "payout_queue_metrics_up 1")
+
+	gauge("payout_queue_depth", "Payout jobs waiting to be delivered to a worker.")
+	fmt.Fprintf(w, "payout_queue_depth %d\n", b.Queued)
+	gauge("payout_queue_processing", "Payout jobs delivered to a worker and not yet acknowledged.")
+	fmt.Fprintf(w, "payout_queue_processing %d\n", b.Processing)
+	gauge("payout_queue_oldest_job_age_seconds", "Time since the oldest unfinished payout job was submitted.")
+	fmt.Fprintf(w, "payout_queue_oldest_job_age_seconds %g\n", b.OldestJobAge.Seconds())
+	gauge("payout_dead_letter_jobs", "Payout jobs in the dead letter queue.")
+	fmt.Fprintf(w, "payout_dead_letter_jobs %d\n", b.DeadLetters)
+
+	gauge("payout_queue_chain_depth", "Payout jobs waiting to be delivered, per chain.")
+	for _, c := range b.Chains {
+		fmt.Fprintf(w, "payout_queue_chain_depth%s %d\n", labels(c), c.Queued)
+	}
+	gauge("payout_queue_chain_processing", "Payout jobs being processed, per chain.")
+	for _, c := range b.Chains {
+		fmt.Fprintf(w, "payout_queue_chain_processing%s %d\n", labels(c), c.Processing)
+	}
+	gauge("payout_queue_chain_oldest_job_age_seconds", "Time since the oldest unfinished payout job was submitted, per chain.")
+	for _, c := range b.Chains {
+		fmt.Fprintf(w, "payout_queue_chain_oldest_job_age_seconds%s %g\n", labels(c), c.OldestJobAge.Seconds())
+	}
+}
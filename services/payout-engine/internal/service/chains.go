@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
+	"github.com/protocol-bank/payout-engine/internal/aa"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// chainDrainDelay 重新加载后旧连接延迟关闭，等待进行中的请求结束
+const chainDrainDelay = 2 * time.Minute
+
+// chainConn 一条链的客户端 (EVM 节点池或 TRON 节点)
+type chainConn struct {
+	pool   *rpcpool.Pool
+	oracle *gas.Oracle
+	aa     *aa.Client
+	tron   *tronclient.GrpcClient
+	health *nodeHealth
+}
+
+func (c *chainConn) close() {
+	if c.pool != nil {
+		c.pool.Close()
+	}
+	if c.tron != nil {
+		c.tron.Stop()
+	}
+}
+
+// chainMaps 链客户端索引。重新加载时复制后整体替换，不原地修改已发布的 map。
+type chainMaps struct {
+	clients     map[uint64]*rpcpool.Pool
+	tronClients map[uint64]*tronclient.GrpcClient
+	tronHealth  map[uint64]*nodeHealth
+	aaClients   map[uint64]*aa.Client
+	feeOracles  map[uint64]*gas.Oracle
+}
+
+func newChainMaps() chainMaps {
+	return chainMaps{
+		clients:     make(map[uint64]*rpcpool.Pool),
+		tronClients: make(map[uint64]*tronclient.GrpcClient),
+		tronHealth:  make(map[uint64]*nodeHealth),
+		aaClients:   make(map[uint64]*aa.Client),
+		feeOracles:  make(map[uint64]*gas.Oracle),
+	}
+}
+
+func (m chainMaps) put(chainID uint64, c *chainConn) {
+	m.remove(chainID)
+	if c.tron != nil {
+		m.tronClients[chainID] = c.tron
+		m.tronHealth[chainID] = c.health
+		return
+	}
+	m.clients[chainID] = c.pool
+	m.feeOracles[chainID] = c.oracle
+	if c.aa != nil {
+		m.aaClients[chainID] = c.aa
+	}
+}
+
+// get 当前连接 (用于延迟关闭)
+func (m chainMaps) get(chainID uint64) *chainConn {
+	if tron, ok := m.tronClients[chainID]; ok {
+		return &chainConn{tron: tron}
+	}
+	if pool, ok := m.clients[chainID]; ok {
+		return &chainConn{pool: pool}
+	}
+	return nil
+}
+
+func (m chainMaps) remove(chainID uint64) {
+	delete(m.clients, chainID)
+	delete(m.tronClients, chainID)
+	delete(m.tronHealth, chainID)
+	delete(m.aaClients, chainID)
+	delete(m.feeOracles, chainID)
+}
+
+// dialChain 连接一条链。TRON 使用第一个可连接且链 ID 相符的节点；EVM 连接所有节点组成节点池。
+// 节点链 ID 与配置不符时返回 rpcpool.ChainIDMismatchError。
+func dialChain(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, rpcCfg rpcpool.Config) (*chainConn, error) {
+	if chainCfg.Type == "tron" {
+		var lastErr error
+		for _, endpoint := range chainCfg.RPCEndpoints() {
+			client := tronclient.NewGrpcClient(endpoint)
+			if err := client.Start(); err != nil {
+				log.Warn().Err(err).Uint64("chain_id", chainID).Str("rpc", rpcpool.Redact(endpoint)).Msg("Failed to connect to Tron chain")
+				lastErr = err
+				continue
+			}
+			if err := verifyTronChainID(chainID, client, endpoint); err != nil {
+				client.Stop()
+				if rpcpool.IsChainIDMismatch(err) {
+					return nil, err
+				}
+				log.Warn().Err(err).Uint64("chain_id", chainID).Str("rpc", rpcpool.Redact(endpoint)).Msg("Failed to verify Tron chain id")
+				lastErr = err
+				continue
+			}
+			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("Connected to Tron chain")
+			return &chainConn{tron: client, health: &nodeHealth{url: endpoint}}, nil
+		}
+		return nil, lastErr
+	}
+
+	endpoints := chainCfg.RPCEndpoints()
+	rpcCfg.Endpoints = chainCfg.RPCRateLimits
+	client, err := rpcpool.Dial(ctx, chainID, endpoints, rpcCfg)
+	if err != nil {
+		return nil, err
+	}
+	conn := &chainConn{pool: client, oracle: gas.NewOracle(client, gas.DefaultHistoryBlocks)}
+	log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Int("endpoints", len(endpoints)).Msg("Connected to chain")
+
+	if chainCfg.AA.BundlerURL != "" {
+		aaClient, err := aa.NewClient(ctx, chainCfg.AA, client)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to initialize ERC-4337 bundler client")
+			return conn, nil
+		}
+		conn.aa = aaClient
+		log.Info().
+			Uint64("chain_id", chainID).
+			Str("smart_account", chainCfg.AA.SmartAccount).
+			Bool("paymaster", aaClient.Sponsored()).
+			Msg("Smart-account payouts enabled")
+	}
+	return conn, nil
+}
+
+// verifyTronChainID 核对 TRON 节点所在网络。TRON 的链 ID 为创世区块 ID 的后 4 字节 (主网 0x2b6653dc)。
+func verifyTronChainID(chainID uint64, client *tronclient.GrpcClient, endpoint string) error {
+	genesis, err := client.GetBlockByNum(0)
+	if err != nil {
+		return fmt.Errorf("failed to get genesis block: %w", err)
+	}
+	actual, err := tronChainID(genesis.GetBlockid())
+	if err != nil {
+		return err
+	}
+	if actual != chainID {
+		return &rpcpool.ChainIDMismatchError{ChainID: chainID, Endpoint: rpcpool.Redact(endpoint), Actual: new(big.Int).SetUint64(actual)}
+	}
+	return nil
+}
+
+// tronChainID 由创世区块 ID 计算链 ID
+func tronChainID(genesisID []byte) (uint64, error) {
+	if len(genesisID) != 32 {
+		return 0, fmt.Errorf("invalid genesis block id: %x", genesisID)
+	}
+	return uint64(binary.BigEndian.Uint32(genesisID[28:])), nil
+}
+
+// ChainReload 重新加载链配置的结果
+type ChainReload struct {
+	Added   []uint64          `json:"added"`
+	Updated []uint64          `json:"updated"`
+	Removed []uint64          `json:"removed"`
+	Failed  map[uint64]string `json:"failed,omitempty"` // 连接失败的链 (已有的链保留原配置)
+}
+
+// ReloadChains 重新读取链配置 (内置链 + CHAINS_FILE)。
+// 节点地址、类型或 ERC-4337 配置变化的链重新连接，其余变更直接生效；
+// 连接失败时保留原有连接和配置。被替换的连接延迟关闭。
+func (s *PayoutService) ReloadChains(ctx context.Context) (*ChainReload, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	chains, err := config.LoadChains(s.cfg.ChainsFile, s.cfg.Network, s.cfg.Shadow.Enabled)
+	if err != nil {
+		return nil, &FailedPreconditionError{Err: err}
+	}
+
+	s.chainMu.RLock()
+	old := s.cfg.Chains
+	next := newChainMaps()
+	maps.Copy(next.clients, s.clients)
+	maps.Copy(next.tronClients, s.tronClients)
+	maps.Copy(next.tronHealth, s.tronHealth)
+	maps.Copy(next.aaClients, s.aaClients)
+	maps.Copy(next.feeOracles, s.feeOracles)
+	s.chainMu.RUnlock()
+
+	result := &ChainReload{}
+	var retired []*chainConn
+	for chainID, chainCfg := range chains {
+		prev, exists := old[chainID]
+		current := next.get(chainID)
+		if exists && current != nil && !needsRedial(prev, chainCfg) {
+			if !chainConfigEqual(prev, chainCfg) {
+				result.Updated = append(result.Updated, chainID)
+			}
+			continue
+		}
+
+		conn, err := dialChain(ctx, chainID, chainCfg, s.cfg.RPC)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to connect to reloaded chain")
+			if result.Failed == nil {
+				result.Failed = make(map[uint64]string)
+			}
+			result.Failed[chainID] = err.Error()
+			if exists {
+				chains[chainID] = prev
+			} else {
+				delete(chains, chainID)
+			}
+			continue
+		}
+		if current != nil {
+			retired = append(retired, current)
+		}
+		next.put(chainID, conn)
+		if conn.pool != nil && s.nonceManager != nil {
+			s.nonceManager.AddChainClient(chainID, conn.pool)
+		}
+		if exists {
+			result.Updated = append(result.Updated, chainID)
+		} else {
+			result.Added = append(result.Added, chainID)
+		}
+	}
+	for chainID := range old {
+		if _, ok := chains[chainID]; ok {
+			continue
+		}
+		if current := next.get(chainID); current != nil {
+			retired = append(retired, current)
+		}
+		next.remove(chainID)
+		if s.nonceManager != nil {
+			s.nonceManager.RemoveChainClient(chainID)
+		}
+		result.Removed = append(result.Removed, chainID)
+	}
+
+	s.chainMu.Lock()
+	s.cfg.Chains = chains
+	s.clients = next.clients
+	s.tronClients = next.tronClients
+	s.tronHealth = next.tronHealth
+	s.aaClients = next.aaClients
+	s.feeOracles = next.feeOracles
+	s.chainMu.Unlock()
+
+	if len(retired) > 0 {
+		time.AfterFunc(chainDrainDelay, func() {
+			for _, c := range retired {
+				c.close()
+			}
+		})
+	}
+
+	for _, ids := range [][]uint64{result.Added, result.Updated, result.Removed} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	log.Info().
+		Uints64("added", result.Added).
+		Uints64("updated", result.Updated).
+		Uints64("removed", result.Removed).
+		Int("failed", len(result.Failed)).
+		Msg("Chain configuration reloaded")
+	return result, nil
+}
+
+// needsRedial 节点、类型或 ERC-4337 配置变化时须重新连接
+func needsRedial(prev, next config.ChainConfig) bool {
+	return prev.Type != next.Type ||
+		!slices.Equal(prev.RPCEndpoints(), next.RPCEndpoints()) ||
+		prev.AA != next.AA
+}
+
+func chainConfigEqual(a, b config.ChainConfig) bool {
+	return !needsRedial(a, b) &&
+		a.Name == b.Name &&
+		a.ExplorerURL == b.ExplorerURL &&
+		a.NativeToken == b.NativeToken &&
+		a.Decimals == b.Decimals &&
+		a.StuckTxTimeout == b.StuckTxTimeout &&
+		a.GasBumpPercent == b.GasBumpPercent &&
+		a.MaxReplacements == b.MaxReplacements &&
+		a.Confirmations == b.Confirmations &&
+		a.ReorgDepth == b.ReorgDepth &&
+		a.Testnet == b.Testnet &&
+		a.Faucet == b.Faucet &&
+		a.WrappedNative == b.WrappedNative &&
+		a.UnwrapNative == b.UnwrapNative &&
+		a.PrivateTx == b.PrivateTx &&
+		a.CCTP == b.CCTP &&
+		a.MaxInFlight == b.MaxInFlight &&
+		a.TxFormat == b.TxFormat
+}
+
+// RunChainWatcher 定期检查 CHAINS_FILE，内容变化时重新加载 (未配置文件时不运行)
+func (s *PayoutService) RunChainWatcher(ctx context.Context, interval time.Duration) {
+	path := s.cfg.ChainsFile
+	if path == "" || interval <= 0 {
+		return
+	}
+	log.Info().Str("file", path).Dur("interval", interval).Msg("Chain config watcher started")
+
+	last, _ := fileVersion(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			version, err := fileVersion(path)
+			if err != nil || version == last {
+				continue
+			}
+			if _, err := s.ReloadChains(ctx); err != nil {
+				log.Error().Err(err).Str("file", path).Msg("Failed to reload chain configuration")
+				continue
+			}
+			last = version
+		}
+	}
+}
+
+// fileVersion 以修改时间和大小判断文件是否变化
+func fileVersion(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// chainConfig 链配置 (未加载的链返回零值)
+func (s *PayoutService) chainConfig(chainID uint64) config.ChainConfig {
+	return s.chainConfigs()[chainID]
+}
+
+// chainConfigs 当前加载的链。返回的 map 不会被修改，重新加载时整体替换。
+func (s *PayoutService) chainConfigs() map[uint64]config.ChainConfig {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	return s.cfg.Chains
+}
+
+func (s *PayoutService) evmClient(chainID uint64) (*rpcpool.Pool, bool) {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	client, ok := s.clients[chainID]
+	return client, ok
+}
+
+func (s *PayoutService) tronClient(chainID uint64) (*tronclient.GrpcClient, bool) {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	client, ok := s.tronClients[chainID]
+	return client, ok
+}
+
+func (s *PayoutService) aaClient(chainID uint64) (*aa.Client, bool) {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	client, ok := s.aaClients[chainID]
+	return client, ok
+}
+
+func (s *PayoutService) feeOracle(chainID uint64) (*gas.Oracle, bool) {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	oracle, ok := s.feeOracles[chainID]
+	return oracle, ok
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// 熔断告警事件
+const (
+	CircuitEventOpened = "chain.circuit_opened"
+	CircuitEventClosed = "chain.circuit_closed"
+)
+
+// CircuitAlert 熔断/恢复告警 (POST 到 CIRCUIT_ALERT_WEBHOOK_URL，签名方式同批次回调)
+type CircuitAlert struct {
+	Event     string         `json:"event"`
+	ChainID   uint64         `json:"chain_id"`
+	Chain     string         `json:"chain"`
+	Circuit   *queue.Circuit `json:"circuit"`
+	Released  int            `json:"released,omitempty"` // 恢复时放回队列的任务数
+	Timestamp int64          `json:"timestamp"`
+}
+
+// RunCircuitMonitor 处理熔断中的链: 发送告警，冷却结束后发送探测交易，成功则恢复该链
+func (s *PayoutService) RunCircuitMonitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Chain circuit monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkCircuits(ctx)
+		}
+	}
+}
+
+func (s *PayoutService) checkCircuits(ctx context.Context) {
+	circuits, err := s.queue.OpenCircuits(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list chain circuits")
+		return
+	}
+	for _, c := range circuits {
+		if !c.Alerted {
+			s.sendCircuitAlert(ctx, CircuitEventOpened, c, 0)
+			c.Alerted = true
+			if err := s.queue.SaveCircuit(ctx, c); err != nil {
+				log.Warn().Err(err).Uint64("chain_id", c.ChainID).Msg("Failed to save chain circuit")
+			}
+		}
+		if c.Manual || time.Now().Before(c.NextProbeAt) {
+			continue
+		}
+		claimed, err := s.queue.ClaimCircuitProbe(ctx, c.ChainID, s.canaryTimeout()+time.Minute)
+		if err != nil || !claimed {
+			continue
+		}
+		s.probeCircuit(ctx, c)
+	}
+}
+
+// probeCircuit 发送探测交易，成功则恢复该链，失败则推迟下一次探测
+func (s *PayoutService) probeCircuit(ctx context.Context, c *queue.Circuit) {
+	log.Info().Uint64("chain_id", c.ChainID).Int("probes", c.Probes).Msg("Probing chain before resuming payouts")
+
+	if err := s.probeChain(ctx, c.ChainID); err != nil {
+		c.Probes++
+		c.LastProbe = err.Error()
+		c.NextProbeAt = time.Now().Add(queue.CircuitPolicyFromConfig(s.cfg.Circuit).Cooldown)
+		log.Warn().Err(err).Uint64("chain_id", c.ChainID).Time("next_probe_at", c.NextProbeAt).Msg("Chain probe failed, circuit stays open")
+		if err := s.queue.SaveCircuit(ctx, c); err != nil {
+			log.Warn().Err(err).Uint64("chain_id", c.ChainID).Msg("Failed to save chain circuit")
+		}
+		return
+	}
+
+	released, err := s.queue.CloseCircuit(ctx, c.ChainID)
+	if err != nil {
+		log.Error().Err(err).Uint64("chain_id", c.ChainID).Msg("Failed to close chain circuit")
+		return
+	}
+	s.sendCircuitAlert(ctx, CircuitEventClosed, c, released)
+}
+
+// probeChain EVM 链发送 0 值自转账并等待上链；TRON 不允许向自身转账，改为查询最新区块
+func (s *PayoutService) probeChain(ctx context.Context, chainID uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.canaryTimeout())
+	defer cancel()
+
+	if tronClient, ok := s.tronClient(chainID); ok {
+		block, err := tronClient.GetNowBlock()
+		if err != nil {
+			return fmt.Errorf("tron node unavailable: %w", err)
+		}
+		if block.GetBlockHeader().GetRawData().GetNumber() == 0 {
+			return fmt.Errorf("tron node returned no block")
+		}
+		return nil
+	}
+	return s.sendCanary(ctx, chainID)
+}
+
+// sendCanary 从付款钱包向自身发送 0 值交易，验证签名、广播和上链全流程
+func (s *PayoutService) sendCanary(ctx context.Context, chainID uint64) error {
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	signer := s.signerFor(chainID)
+	if signer == nil {
+		return fmt.Errorf("signer is not configured")
+	}
+	from := signer.Address()
+
+	fees, err := s.suggestFees(ctx, chainID, string(gas.PriorityHigh))
+	if err != nil {
+		return fmt.Errorf("failed to get fees: %w", err)
+	}
+	nonceVal, releaseFn, err := s.nonceManager.GetNonceLimited(ctx, chainID, from, s.maxInFlight(chainID))
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", inFlightError(err))
+	}
+	defer releaseFn()
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonceVal,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       nativeTransferGas,
+		To:        &from,
+		Value:     big.NewInt(0),
+	})
+	signedTx, err := s.signTransaction(ctx, tx, chainID, signingOp{purpose: signPurposeCanary})
+	if err != nil {
+		return fmt.Errorf("failed to sign canary: %w", err)
+	}
+	if err := s.broadcastTransaction(ctx, client, chainID, signedTx); err != nil {
+		return fmt.Errorf("failed to send canary: %w", s.classifySendError(ctx, chainID, from, err))
+	}
+	// 未及时上链时由卡单检测接手替换，避免阻塞后续 nonce
+	canary := &queue.Job{ID: fmt.Sprintf("canary:%d:%d", chainID, nonceVal), ChainID: chainID, FromAddress: from.Hex()}
+	s.trackPendingTx(ctx, canary, signedTx)
+
+	receipt, err := waitMined(ctx, client, signedTx.Hash())
+	if err != nil {
+		return fmt.Errorf("canary %s not mined: %w", signedTx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("canary %s reverted", signedTx.Hash().Hex())
+	}
+	log.Info().Uint64("chain_id", chainID).Str("tx_hash", signedTx.Hash().Hex()).Msg("Canary transaction mined")
+	return nil
+}
+
+// receiptReader 查询交易回执所需的 RPC 接口
+type receiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// waitMined 轮询交易回执直到上链或 ctx 结束
+func waitMined(ctx context.Context, client receiptReader, hash common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			receipt, err := client.TransactionReceipt(ctx, hash)
+			if err != nil || receipt == nil {
+				continue
+			}
+			return receipt, nil
+		}
+	}
+}
+
+func (s *PayoutService) canaryTimeout() time.Duration {
+	if s.cfg.Circuit.CanaryTimeout > 0 {
+		return s.cfg.Circuit.CanaryTimeout
+	}
+	return 2 * time.Minute
+}
+
+// sendCircuitAlert 记录并投递告警 (未配置告警地址时只记录日志)
+func (s *PayoutService) sendCircuitAlert(ctx context.Context, event string, c *queue.Circuit, released int) {
+	chain := s.chainConfig(c.ChainID).Name
+	log.Error().
+		Str("event", event).
+		Uint64("chain_id", c.ChainID).
+		Str("chain", chain).
+		Str("reason", c.Reason).
+		Int64("held", c.Held).
+		Int("released", released).
+		Msg("Chain circuit alert")
+
+	if s.cfg.Circuit.AlertURL == "" {
+		return
+	}
+	body, err := json.Marshal(CircuitAlert{
+		Event:     event,
+		ChainID:   c.ChainID,
+		Chain:     chain,
+		Circuit:   c,
+		Released:  released,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+	eventID := fmt.Sprintf("%s:%d:%d", event, c.ChainID, c.OpenedAt.UnixMilli())
+	if err := s.webhookSender.Post(ctx, s.cfg.Circuit.AlertURL, s.cfg.Circuit.AlertSecret, eventID, body); err != nil {
+		log.Warn().Err(err).Str("event", event).Uint64("chain_id", c.ChainID).Msg("Failed to deliver circuit alert")
+	}
+}
+
+// recordReceiptOutcome 上链失败 (revert) 的交易计入链的失败率
+func (s *PayoutService) recordReceiptOutcome(ctx context.Context, p *queue.PendingTx, receipt *types.Receipt) {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return
+	}
+	if _, err := s.queue.RecordChainOutcome(ctx, p.ChainID, p.JobID+":receipt", true); err != nil {
+		log.Warn().Err(err).Uint64("chain_id", p.ChainID).Msg("Failed to record chain outcome")
+	}
+}
+
+// ChainCircuits 熔断中的链
+func (s *PayoutService) ChainCircuits(ctx context.Context) ([]*queue.Circuit, error) {
+	return s.queue.OpenCircuits(ctx)
+}
+
+// PauseChain 手动暂停一条链 (不自动探测，须调用 ResumeChain 恢复)
+func (s *PayoutService) PauseChain(ctx context.Context, chainID uint64, reason string) (*queue.Circuit, error) {
+	if _, ok := s.chainConfigs()[chainID]; !ok {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("unsupported chain_id: %d", chainID)}
+	}
+	if reason == "" {
+		reason = "paused by operator"
+	}
+	c := &queue.Circuit{ChainID: chainID, Reason: reason, Manual: true}
+	opened, err := s.queue.OpenCircuit(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if !opened {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("chain %d is already paused", chainID)}
+	}
+	return c, nil
+}
+
+// ResumeChain 手动恢复一条链，返回放回队列的任务数
+func (s *PayoutService) ResumeChain(ctx context.Context, chainID uint64) (int, error) {
+	c, err := s.queue.GetCircuit(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	if c == nil {
+		return 0, &FailedPreconditionError{Err: fmt.Errorf("chain %d is not paused", chainID)}
+	}
+	released, err := s.queue.CloseCircuit(ctx, chainID)
+	if err != nil {
+		return released, err
+	}
+	s.sendCircuitAlert(ctx, CircuitEventClosed, c, released)
+	return released, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// ListFailedPayouts 列出死信队列中的失败支付 (batchID 为空时列出全部)
+func (s *PayoutService) ListFailedPayouts(ctx context.Context, batchID string, offset, limit int) ([]*queue.DeadLetter, int, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.queue.ListDeadLetters(ctx, batchID, offset, limit)
+}
+
+// RetryFailedPayouts 将失败支付重新入队。itemIDs 为空时重试该批次全部失败项。
+// 返回成功重新入队的数量。
+func (s *PayoutService) RetryFailedPayouts(ctx context.Context, batchID string, itemIDs []string) (int, error) {
+	if batchID == "" && len(itemIDs) == 0 {
+		return 0, &InvalidArgumentError{Err: fmt.Errorf("batch_id or item_ids is required")}
+	}
+
+	// 限定在批次内: 未指定 itemIDs 时重试全部，否则只重试属于该批次的项
+	if batchID != "" {
+		entries, _, err := s.queue.ListDeadLetters(ctx, batchID, 0, 0)
+		if err != nil {
+			return 0, err
+		}
+		inBatch := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			inBatch[entry.Job.ID] = true
+		}
+		if len(itemIDs) == 0 {
+			for id := range inBatch {
+				itemIDs = append(itemIDs, id)
+			}
+		} else {
+			filtered := itemIDs[:0:0]
+			for _, id := range itemIDs {
+				if inBatch[id] {
+					filtered = append(filtered, id)
+				}
+			}
+			itemIDs = filtered
+		}
+	}
+
+	requeued := 0
+	for _, id := range itemIDs {
+		if _, err := s.queue.RequeueDeadLetter(ctx, id); errors.Is(err, queue.ErrDeadLetterNotFound) {
+			log.Warn().Str("job_id", id).Msg("Retry requested for job not in dead letter queue")
+			continue
+		} else if err != nil {
+			return requeued, fmt.Errorf("failed to requeue %s: %w", id, err)
+		}
+		requeued++
+	}
+
+	log.Info().Str("batch_id", batchID).Int("requeued", requeued).Msg("Failed payouts requeued")
+	return requeued, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+)
+
+// DuplicateMode 同一收款地址、代币和金额的重复支付项 (常见于 CSV 重复粘贴) 的处理方式
+type DuplicateMode string
+
+const (
+	// DuplicateModeFlag 保留首个支付项，其余重复项标记后不入队
+	DuplicateModeFlag DuplicateMode = "flag"
+	// DuplicateModeMerge 重复项合并到首个支付项 (金额相加)，以一笔交易支付
+	DuplicateModeMerge DuplicateMode = "merge"
+)
+
+// DuplicateDecision 重复支付项的处理结果
+type DuplicateDecision struct {
+	ItemID      string
+	DuplicateOf string        // 保留的首个支付项
+	Decision    DuplicateMode // flag: 未入队; merge: 金额已并入 DuplicateOf
+}
+
+// duplicateKey 支付项的 (收款地址, 代币, 金额)，EVM 地址不区分大小写
+func duplicateKey(item PayoutItem) string {
+	recipient := item.RecipientAddress
+	if strings.HasPrefix(strings.ToLower(recipient), "0x") {
+		recipient = strings.ToLower(recipient)
+	}
+	amount := item.Amount
+	if n, ok := new(big.Int).SetString(item.Amount, 10); ok {
+		amount = n.String()
+	}
+	return recipient + "|" + normalizeTokenKey(item.asset()) + "|" + amount
+}
+
+// resolveDuplicates 按 DuplicateMode 处理重复支付项，返回处理后的请求 (不修改 req) 和各重复项的处理结果。
+// 未设置 DuplicateMode 或没有重复项时原样返回 req。
+func resolveDuplicates(req *BatchPayoutRequest) (*BatchPayoutRequest, []DuplicateDecision) {
+	if req.DuplicateMode == "" {
+		return req, nil
+	}
+
+	first := make(map[string]int, len(req.Items)) // duplicateKey → items 中的下标
+	items := make([]PayoutItem, 0, len(req.Items))
+	var decisions []DuplicateDecision
+	for _, item := range req.Items {
+		key := duplicateKey(item)
+		i, seen := first[key]
+		if !seen {
+			first[key] = len(items)
+			items = append(items, item)
+			continue
+		}
+		decisions = append(decisions, DuplicateDecision{ItemID: item.ID, DuplicateOf: items[i].ID, Decision: req.DuplicateMode})
+		if req.DuplicateMode == DuplicateModeMerge {
+			total, ok1 := new(big.Int).SetString(items[i].Amount, 10)
+			amount, ok2 := new(big.Int).SetString(item.Amount, 10)
+			if ok1 && ok2 {
+				items[i].Amount = total.Add(total, amount).String()
+			}
+		}
+	}
+	if len(decisions) == 0 {
+		return req, nil
+	}
+
+	out := *req
+	out.Items = items
+	return &out, decisions
+}
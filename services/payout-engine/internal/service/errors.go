@@ -0,0 +1,45 @@
+package service
+
+import "errors"
+
+// InvalidArgumentError 调用方可修正的请求错误 (参数校验失败等)
+type InvalidArgumentError struct {
+	Err error
+}
+
+func (e *InvalidArgumentError) Error() string { return e.Err.Error() }
+func (e *InvalidArgumentError) Unwrap() error { return e.Err }
+
+// FailedPreconditionError 请求合法但当前状态不允许执行 (如余额不足)
+type FailedPreconditionError struct {
+	Err error
+}
+
+func (e *FailedPreconditionError) Error() string { return e.Err.Error() }
+func (e *FailedPreconditionError) Unwrap() error { return e.Err }
+
+// UnavailableError 依赖暂时不可用 (如链的所有节点故障)，稍后重试
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string { return e.Err.Error() }
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
+// IsInvalidArgument 是否为请求参数错误
+func IsInvalidArgument(err error) bool {
+	var e *InvalidArgumentError
+	return errors.As(err, &e)
+}
+
+// IsFailedPrecondition 是否为前置条件不满足
+func IsFailedPrecondition(err error) bool {
+	var e *FailedPreconditionError
+	return errors.As(err, &e)
+}
+
+// IsUnavailable 是否为暂时不可用
+func IsUnavailable(err error) bool {
+	var e *UnavailableError
+	return errors.As(err, &e)
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// FeeMode 手续费承担方式
+type FeeMode string
+
+const (
+	// FeeModePayer 付款方承担 Gas，收款方收到全额 (默认)
+	FeeModePayer FeeMode = "payer"
+	// FeeModeRecipient 从每笔收款金额中扣除预估网络费和服务费
+	FeeModeRecipient FeeMode = "recipient"
+)
+
+// Default gas limits used for network fee estimation.
+const (
+	nativeTransferGas = 21000
+	tronNativeFeeSun  = 1_100_000 // ~1.1 TRX when bandwidth is exhausted
+)
+
+// ItemFee 单笔手续费明细 (all amounts in the item's smallest unit)
+type ItemFee struct {
+	ItemID      string
+	GrossAmount string
+	NetworkFee  string
+	ServiceFee  string
+	NetAmount   string
+}
+
+// FeePolicy 收款方承担手续费时的费用配置
+type FeePolicy struct {
+	// ServiceFeeBps 服务费 (基点, 100 = 1%)，向下取整
+	ServiceFeeBps uint32
+	// TokenNetworkFee 代币转账的网络费 (以代币最小单位计)。
+	// The engine has no price oracle, so gas for token transfers cannot be
+	// converted automatically; callers supply the token-denominated charge.
+	TokenNetworkFee string
+}
+
+// applyRecipientFees computes the fees deducted from each item.
+// Rounding is deterministic: the service fee is floor(amount * bps / 10000)
+// and every item is charged the full per-transfer network fee estimate.
+func (s *PayoutService) applyRecipientFees(ctx context.Context, req *BatchPayoutRequest) ([]ItemFee, error) {
+	tokenNetworkFee := big.NewInt(0)
+	if req.FeePolicy.TokenNetworkFee != "" {
+		v, ok := new(big.Int).SetString(req.FeePolicy.TokenNetworkFee, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("invalid token_network_fee: %s", req.FeePolicy.TokenNetworkFee)
+		}
+		tokenNetworkFee = v
+	}
+	if req.FeePolicy.ServiceFeeBps > 10_000 {
+		return nil, fmt.Errorf("service_fee_bps must be <= 10000")
+	}
+
+	var nativeNetworkFee *big.Int
+	fees := make([]ItemFee, len(req.Items))
+	for i, item := range req.Items {
+		gross, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok || gross.Sign() <= 0 {
+			return nil, fmt.Errorf("item[%d]: invalid amount: %s", i, item.Amount)
+		}
+
+		networkFee := tokenNetworkFee
+		if isNativeToken(item.asset()) {
+			if nativeNetworkFee == nil {
+				fee, err := s.estimateNativeTransferFee(ctx, req.ChainID, req.Priority)
+				if err != nil {
+					return nil, fmt.Errorf("failed to estimate network fee: %w", err)
+				}
+				nativeNetworkFee = fee
+			}
+			networkFee = nativeNetworkFee
+		}
+
+		serviceFee := new(big.Int).Mul(gross, big.NewInt(int64(req.FeePolicy.ServiceFeeBps)))
+		serviceFee.Div(serviceFee, big.NewInt(10_000))
+
+		net := new(big.Int).Sub(gross, networkFee)
+		net.Sub(net, serviceFee)
+		if net.Sign() <= 0 {
+			return nil, fmt.Errorf("item[%d]: fees (%s network + %s service) exceed amount %s", i, networkFee, serviceFee, gross)
+		}
+
+		fees[i] = ItemFee{
+			ItemID:      item.ID,
+			GrossAmount: gross.String(),
+			NetworkFee:  networkFee.String(),
+			ServiceFee:  serviceFee.String(),
+			NetAmount:   net.String(),
+		}
+	}
+
+	return fees, nil
+}
+
+// estimateNativeTransferFee 预估原生代币转账的网络费
+func (s *PayoutService) estimateNativeTransferFee(ctx context.Context, chainID uint64, priority string) (*big.Int, error) {
+	if _, ok := s.tronClient(chainID); ok {
+		return big.NewInt(tronNativeFeeSun), nil
+	}
+
+	fees, err := s.suggestFees(ctx, chainID, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	l1, err := s.l1TransferCost(ctx, chainID, fees, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按实际支付价格 (base fee + tip) 计费，gas limit 与 buildNativeTransfer 使用相同缓冲
+	price := new(big.Int).Add(fees.BaseFee, fees.TipCap)
+	return transferCost(price, nativeTransferGas, l1, priority), nil
+}
+
+// transferCost 按 price 计费的网络费: gas limit (含 Arbitrum 的 L1 部分) 按优先级缓冲，加上 OP Stack 的 L1 数据费
+func transferCost(price *big.Int, gasLimit uint64, l1 *gas.L1Cost, priority string) *big.Int {
+	cost := new(big.Int).Mul(price, new(big.Int).SetUint64(calculateGasBuffer(gasLimit+l1.Gas, priority)))
+	return cost.Add(cost, l1.Fee)
+}
+
+// l1TransferCost 一笔原生代币或代币转账的 L1 数据成本 (未配置 L1Fee 的链为零)。
+// 金额按全部非零字节的最坏情况估算。
+func (s *PayoutService) l1TransferCost(ctx context.Context, chainID uint64, fees *gas.Fees, token bool) (*gas.L1Cost, error) {
+	model := s.chainConfig(chainID).L1Fee
+	if model == "" {
+		return &gas.L1Cost{Fee: big.NewInt(0)}, nil
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+
+	to := common.MaxAddress
+	amount := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	tx := &types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     math.MaxUint32,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       nativeTransferGas,
+		To:        &to,
+		Value:     amount,
+	}
+	if token {
+		data, err := s.erc20ABI.Pack("transfer", to, amount)
+		if err != nil {
+			return nil, err
+		}
+		tx.Gas, tx.Value, tx.Data = erc20TransferGas, big.NewInt(0), data
+	}
+	cost, err := gas.EstimateL1Cost(ctx, client, model, types.NewTx(tx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate l1 data fee: %w", err)
+	}
+	return cost, nil
+}
+
+// FeeCapCode 预计网络费超过任务的上限 (PayoutItem.MaxFee)
+const FeeCapCode = "FEE_CAP_EXCEEDED"
+
+// FeeCapError 预计网络费超过任务的上限，不发送。按重试策略稍后重新估算，仍超过时进入死信队列。
+type FeeCapError struct {
+	Estimated *big.Int
+	MaxFee    *big.Int
+}
+
+func (e *FeeCapError) Error() string {
+	return fmt.Sprintf("estimated network fee %s exceeds the job's max fee %s", e.Estimated, e.MaxFee)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *FeeCapError) ErrorCode() string { return FeeCapCode }
+
+// parseMaxFee 解析网络费上限 (原生代币最小单位)，为空时返回 nil
+func parseMaxFee(maxFee string) (*big.Int, error) {
+	if maxFee == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(maxFee, 10)
+	if !ok || v.Sign() <= 0 {
+		return nil, fmt.Errorf("max_fee must be a positive integer: %s", maxFee)
+	}
+	return v, nil
+}
+
+// applyFeeCap 任务设置了 MaxFee 时，按 (base fee + tip) × gas limit 加 L1 数据费预计网络费，超过上限则不发送；
+// 否则必要时降低 maxFeePerGas，使交易即使用尽 gas limit 也不超过上限。
+func (s *PayoutService) applyFeeCap(ctx context.Context, client gas.Caller, job *queue.Job, tx *types.Transaction, baseFee *big.Int) (*types.Transaction, error) {
+	maxFee, err := parseMaxFee(job.MaxFee)
+	if err != nil {
+		return nil, queue.Permanent(err)
+	}
+	if maxFee == nil {
+		return tx, nil
+	}
+	l1, err := gas.EstimateL1Cost(ctx, client, s.chainConfig(job.ChainID).L1Fee, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate l1 data fee: %w", err)
+	}
+	capped, err := capTxFees(tx, baseFee, l1.Fee, maxFee)
+	if err != nil {
+		return nil, err
+	}
+	if capped != tx {
+		log.Info().
+			Str("job_id", job.ID).
+			Str("gas_fee_cap", capped.GasFeeCap().String()).
+			Str("max_fee", maxFee.String()).
+			Msg("Lowered max fee per gas to the job's max fee")
+	}
+	return capped, nil
+}
+
+// capTxFees 见 applyFeeCap，未调整时返回原交易
+func capTxFees(tx *types.Transaction, baseFee, l1Fee, maxFee *big.Int) (*types.Transaction, error) {
+	gasLimit := new(big.Int).SetUint64(tx.Gas())
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price = tx.GasFeeCap()
+	}
+	estimated := new(big.Int).Mul(price, gasLimit)
+	estimated.Add(estimated, l1Fee)
+	if estimated.Cmp(maxFee) > 0 {
+		return nil, &FeeCapError{Estimated: estimated, MaxFee: maxFee}
+	}
+
+	allowed := new(big.Int).Sub(maxFee, l1Fee)
+	allowed.Quo(allowed, gasLimit)
+	if tx.GasFeeCap().Cmp(allowed) <= 0 {
+		return tx, nil
+	}
+	tipCap := tx.GasTipCap()
+	if tipCap.Cmp(allowed) > 0 {
+		tipCap = allowed
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: tipCap,
+		GasFeeCap: allowed,
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}), nil
+}
+
+// isNativeToken 判断是否为原生代币
+func isNativeToken(tokenAddress string) bool {
+	return tokenAddress == "" || tokenAddress == "0x0000000000000000000000000000000000000000"
+}
+
+// suggestFees 通过链的费用预言机获取 EIP-1559 费用
+func (s *PayoutService) suggestFees(ctx context.Context, chainID uint64, priority string) (*gas.Fees, error) {
+	oracle, ok := s.feeOracle(chainID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	p, err := gas.ParsePriority(priority)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := oracle.Suggest(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee suggestion: %w", err)
+	}
+	return fees, nil
+}
+
+// calculateGasBuffer 按优先级放大 gas 估算 (LOW 10%, MEDIUM 20%, HIGH 30%, URGENT 50%)
+func calculateGasBuffer(estimatedGas uint64, priority string) uint64 {
+	p, err := gas.ParsePriority(priority)
+	if err != nil {
+		p = gas.DefaultPriority
+	}
+	return gas.StrategyFor(p).BufferGas(estimatedGas)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/forksim"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// forkSimRequired 任务金额 (整币) 是否达到分叉模拟门槛
+func (s *PayoutService) forkSimRequired(job *queue.Job) bool {
+	if s.forkSimThreshold == nil {
+		return true
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return false
+	}
+	return s.wholeUnits(job.ChainID, job.TokenAddress, job.TokenDecimals, amount).Cmp(s.forkSimThreshold) >= 0
+}
+
+// forkSimulate 签名前在分叉上执行已构建的交易。
+// 交易回滚时返回不可重试的 forksim.RevertedError (记录回滚原因)；模拟服务不可用时返回可重试错误。
+// 未配置分叉的链不模拟。
+func (s *PayoutService) forkSimulate(ctx context.Context, job *queue.Job, tx *types.Transaction) error {
+	if s.simulator == nil || !s.forkSimRequired(job) {
+		return nil
+	}
+
+	result, err := s.simulator.Simulate(ctx, job.ChainID, common.HexToAddress(job.FromAddress), tx)
+	var unsupported *forksim.UnsupportedChainError
+	if errors.As(err, &unsupported) {
+		log.Debug().Str("job_id", job.ID).Uint64("chain_id", job.ChainID).Msg("No fork configured, skipping simulation")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fork simulation unavailable: %w", err)
+	}
+	if result.Reverted {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("provider", s.simulator.Provider()).
+			Str("reason", result.RevertReason).
+			Msg("Transaction reverted in fork simulation, aborting job")
+		return queue.Permanent(&forksim.RevertedError{Provider: s.simulator.Provider(), Result: result})
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// forwarderABI OpenZeppelin ERC2771Forwarder (v5) 中继所需的方法
+const forwarderABI = `[{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"deadline","type":"uint48"},{"name":"data","type":"bytes"},{"name":"signature","type":"bytes"}],"name":"request","type":"tuple"}],"name":"execute","outputs":[],"stateMutability":"payable","type":"function"},{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"deadline","type":"uint48"},{"name":"data","type":"bytes"},{"name":"signature","type":"bytes"}],"name":"request","type":"tuple"}],"name":"verify","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// maxForwardGas 中继的转发调用 gas 上限 (Gas 由付款地址承担，只中继代币转账)
+const maxForwardGas = 300000
+
+// 转发请求校验失败 (其余错误为 RPC 不可用)
+var (
+	errForwarderUnsupported = errors.New("forwarder does not implement ERC2771Forwarder")
+	errForwardRejected      = errors.New("forward request rejected by forwarder (signature, nonce, deadline or untrusted target)")
+)
+
+// forwardRequestData ERC2771Forwarder.ForwardRequestData
+type forwardRequestData struct {
+	From      common.Address
+	To        common.Address
+	Value     *big.Int
+	Gas       *big.Int
+	Deadline  *big.Int
+	Data      []byte
+	Signature []byte
+}
+
+// RelayForwardRequest x402 中继请求: 付款方签名、调用代币 transfer 的 ERC-2771 转发请求
+type RelayForwardRequest struct {
+	ChainID   uint64
+	Forwarder string
+	From      string
+	Token     string // 转发调用的目标合约
+	Value     string // 须为 0
+	Gas       uint64
+	Deadline  int64
+	Data      string // transfer(address,uint256) 调用数据
+	Signature string
+}
+
+// forwarderTrusted 转发合约是否在链配置的可信列表中
+func (s *PayoutService) forwarderTrusted(chainID uint64, forwarder string) bool {
+	for _, f := range s.chainConfig(chainID).Forwarders {
+		if strings.EqualFold(f, forwarder) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeTransferCall 解码 ERC20 transfer(address,uint256) 调用数据
+func (s *PayoutService) decodeTransferCall(data []byte) (common.Address, *big.Int, error) {
+	method := s.erc20ABI.Methods["transfer"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, fmt.Errorf("forward request data must be an ERC20 transfer call")
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(args) != 2 {
+		return common.Address{}, nil, fmt.Errorf("invalid ERC20 transfer call data")
+	}
+	to, amount := args[0].(common.Address), args[1].(*big.Int)
+	if to == (common.Address{}) || amount.Sign() <= 0 {
+		return common.Address{}, nil, fmt.Errorf("transfer recipient and amount are required")
+	}
+	return to, amount, nil
+}
+
+// parseForwardRequest 校验并组装转发请求 (只接受对代币的 transfer 调用)
+func (s *PayoutService) parseForwardRequest(req *RelayForwardRequest) (*forwardRequestData, common.Address, *big.Int, error) {
+	var to common.Address
+	if !common.IsHexAddress(req.Forwarder) || !common.IsHexAddress(req.From) || !common.IsHexAddress(req.Token) {
+		return nil, to, nil, fmt.Errorf("forwarder, from and token must be valid addresses")
+	}
+	if req.Value != "" && req.Value != "0" {
+		return nil, to, nil, fmt.Errorf("forward requests with native value are not relayed")
+	}
+	if req.Gas == 0 || req.Gas > maxForwardGas {
+		return nil, to, nil, fmt.Errorf("gas must be between 1 and %d", maxForwardGas)
+	}
+	if req.Deadline <= 0 || req.Deadline >= 1<<48 {
+		return nil, to, nil, fmt.Errorf("invalid deadline: %d", req.Deadline)
+	}
+	data, err := hexutil.Decode(req.Data)
+	if err != nil {
+		return nil, to, nil, fmt.Errorf("invalid data: %w", err)
+	}
+	sig, err := hexutil.Decode(req.Signature)
+	if err != nil || len(sig) != 65 {
+		return nil, to, nil, fmt.Errorf("invalid signature: must be 65 bytes hex")
+	}
+	to, amount, err := s.decodeTransferCall(data)
+	if err != nil {
+		return nil, to, nil, err
+	}
+	return &forwardRequestData{
+		From:      common.HexToAddress(req.From),
+		To:        common.HexToAddress(req.Token),
+		Value:     new(big.Int),
+		Gas:       new(big.Int).SetUint64(req.Gas),
+		Deadline:  big.NewInt(req.Deadline),
+		Data:      data,
+		Signature: sig,
+	}, to, amount, nil
+}
+
+// verifyForwardRequest 由转发合约核对签名、nonce、期限和目标合约对转发合约的信任
+func (s *PayoutService) verifyForwardRequest(ctx context.Context, client ethCaller, forwarder common.Address, req *forwardRequestData) error {
+	ok, err := callTokenView(ctx, client, s.forwarderABI, forwarder, errForwarderUnsupported, "verify", *req)
+	if err != nil {
+		return err
+	}
+	if !ok.(bool) {
+		return errForwardRejected
+	}
+	return nil
+}
+
+// forwardNonce 转发合约中签名方的当前 nonce
+func (s *PayoutService) forwardNonce(ctx context.Context, client ethCaller, forwarder, from common.Address) (*big.Int, error) {
+	nonce, err := callTokenView(ctx, client, s.forwarderABI, forwarder, errForwarderUnsupported, "nonces", from)
+	if err != nil {
+		return nil, err
+	}
+	return nonce.(*big.Int), nil
+}
+
+// forwardJobID 由 (链, 转发合约, 签名方, nonce, 签名) 确定的任务 ID
+func forwardJobID(chainID uint64, forwarder common.Address, req *forwardRequestData, nonce *big.Int) string {
+	h := crypto.Keccak256(new(big.Int).SetUint64(chainID).Bytes(), forwarder.Bytes(), req.From.Bytes(), nonce.Bytes(), req.Signature)
+	return fmt.Sprintf("fwd-%d-%x", chainID, h[:12])
+}
+
+// RelayForward 校验付款方签名的 ERC-2771 转发请求并作为任务入队，由付款地址代付 Gas 调用
+// 可信转发合约的 execute。用于不支持 EIP-3009 但信任转发合约的代币。
+func (s *PayoutService) RelayForward(ctx context.Context, req *RelayForwardRequest) (*RelayAuthorizationResult, error) {
+	if !s.cfg.X402Relayer {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("x402 relayer is disabled")}
+	}
+	client, ok := s.evmClient(req.ChainID)
+	if !ok {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("x402 relaying is not supported on chain %d", req.ChainID)}
+	}
+	if !s.forwarderTrusted(req.ChainID, req.Forwarder) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("forwarder %s is not trusted on chain %d", req.Forwarder, req.ChainID)}
+	}
+	fwd, to, amount, err := s.parseForwardRequest(req)
+	if err != nil {
+		return nil, &InvalidArgumentError{Err: err}
+	}
+	if time.Now().Unix() >= req.Deadline {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("forward request expired at %d", req.Deadline)}
+	}
+	if err := s.checkAllowlist(x402UserID, req.ChainID, req.Token); err != nil {
+		return nil, &InvalidArgumentError{Err: err}
+	}
+
+	forwarder := common.HexToAddress(req.Forwarder)
+	if err := s.verifyForwardRequest(ctx, client, forwarder, fwd); err != nil {
+		return nil, forwardError(err)
+	}
+	nonce, err := s.forwardNonce(ctx, client, forwarder, fwd.From)
+	if err != nil {
+		return nil, forwardError(err)
+	}
+
+	// 同一请求只入队一次 (重复提交返回已有任务，同一 nonce 的其他请求拒绝)
+	jobID := forwardJobID(req.ChainID, forwarder, fwd, nonce)
+	ttl := time.Until(time.Unix(req.Deadline, 0)) + time.Minute
+	claimed, existing, err := s.queue.ClaimAuthorizationNonce(ctx, req.ChainID, forwarder.Hex(), fwd.From.Hex(), nonce.String(), jobID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		if existing != jobID {
+			return nil, &FailedPreconditionError{Err: fmt.Errorf("forwarder nonce %s of %s is already being relayed by %s", nonce, fwd.From.Hex(), existing)}
+		}
+		job, err := s.AuthorizationStatus(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+		return &RelayAuthorizationResult{Job: job, Replayed: true}, nil
+	}
+
+	job, err := s.forwardJob(ctx, req, jobID, nonce, to, amount)
+	if err == nil {
+		err = s.queue.Push(ctx, job)
+	}
+	if err != nil {
+		if rerr := s.queue.ReleaseAuthorizationNonce(ctx, req.ChainID, forwarder.Hex(), fwd.From.Hex(), nonce.String()); rerr != nil {
+			log.Warn().Err(rerr).Str("job_id", jobID).Msg("Failed to release forwarder nonce")
+		}
+		return nil, err
+	}
+
+	log.Info().
+		Uint64("chain_id", req.ChainID).
+		Str("job_id", jobID).
+		Str("forwarder", forwarder.Hex()).
+		Str("token", job.TokenSymbol).
+		Str("from", fwd.From.Hex()).
+		Str("to", to.Hex()).
+		Str("value", amount.String()).
+		Msg("Forward request queued for relay")
+
+	status, err := s.AuthorizationStatus(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayAuthorizationResult{Job: status}, nil
+}
+
+// forwardJob 构建转发中继任务
+func (s *PayoutService) forwardJob(ctx context.Context, req *RelayForwardRequest, jobID string, nonce *big.Int, to common.Address, amount *big.Int) (*queue.Job, error) {
+	token := common.HexToAddress(req.Token)
+	meta, err := s.resolveTokenMetadata(ctx, req.ChainID, token.Hex())
+	if errors.Is(err, errNotTokenContract) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("token %s: %w", token.Hex(), err)}
+	}
+	if err != nil {
+		return nil, &UnavailableError{Err: fmt.Errorf("failed to read metadata of token %s: %w", token.Hex(), err)}
+	}
+	relayer, err := s.payoutAddress(req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	return &queue.Job{
+		ID:            jobID,
+		BatchID:       jobID,
+		UserID:        x402UserID,
+		FromAddress:   relayer,
+		ToAddress:     to.Hex(),
+		Amount:        amount.String(),
+		TokenAddress:  token.Hex(),
+		TokenSymbol:   meta.Symbol,
+		TokenDecimals: meta.Decimals,
+		ChainID:       req.ChainID,
+		Testnet:       s.isTestnetChain(req.ChainID),
+		Priority:      string(gas.PriorityHigh), // x402 付款方在等待资源放行
+		CreatedAt:     time.Now(),
+		Forward: &queue.ForwardRequest{
+			Forwarder: common.HexToAddress(req.Forwarder).Hex(),
+			From:      common.HexToAddress(req.From).Hex(),
+			Gas:       req.Gas,
+			Deadline:  req.Deadline,
+			Nonce:     nonce.String(),
+			Data:      req.Data,
+			Signature: req.Signature,
+		},
+	}, nil
+}
+
+// forwardError 转发请求校验失败为调用方错误，其余为 RPC 不可用
+func forwardError(err error) error {
+	if errors.Is(err, errForwarderUnsupported) || errors.Is(err, errForwardRejected) {
+		return &InvalidArgumentError{Err: err}
+	}
+	return &UnavailableError{Err: fmt.Errorf("failed to verify forward request: %w", err)}
+}
+
+// jobForwardRequest 由任务还原签名的转发请求
+func (s *PayoutService) jobForwardRequest(job *queue.Job) (*forwardRequestData, error) {
+	data, err := hexutil.Decode(job.Forward.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward data: %w", err)
+	}
+	sig, err := hexutil.Decode(job.Forward.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward signature: %w", err)
+	}
+	return &forwardRequestData{
+		From:      common.HexToAddress(job.Forward.From),
+		To:        common.HexToAddress(job.TokenAddress),
+		Value:     new(big.Int),
+		Gas:       new(big.Int).SetUint64(job.Forward.Gas),
+		Deadline:  big.NewInt(job.Forward.Deadline),
+		Data:      data,
+		Signature: sig,
+	}, nil
+}
+
+// checkForwardExecutable 发送前确认转发请求仍有效 (已执行、已过期或签名失效时永久失败)
+func (s *PayoutService) checkForwardExecutable(ctx context.Context, client ethCaller, job *queue.Job) error {
+	req, err := s.jobForwardRequest(job)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	if time.Now().Unix() >= job.Forward.Deadline {
+		return queue.Permanent(fmt.Errorf("forward request expired at %d", job.Forward.Deadline))
+	}
+	err = s.verifyForwardRequest(ctx, client, common.HexToAddress(job.Forward.Forwarder), req)
+	if errors.Is(err, errForwardRejected) || errors.Is(err, errForwarderUnsupported) {
+		return queue.Permanent(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify forward request: %w", err)
+	}
+	return nil
+}
+
+// packForward 编码转发合约 execute 调用数据
+func (s *PayoutService) packForward(job *queue.Job) ([]byte, error) {
+	req, err := s.jobForwardRequest(job)
+	if err != nil {
+		return nil, err
+	}
+	return s.forwarderABI.Pack("execute", *req)
+}
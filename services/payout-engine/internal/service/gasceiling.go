@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// GasCeilingCode 链的基础费超过配置的上限 (config.ChainGasCeiling)
+const GasCeilingCode = "GAS_PRICE_CEILING"
+
+// GasCeilingError 基础费超过链的上限，不发送。Wait 模式下任务暂存到费用回落，
+// 否则按重试策略稍后重新检查。
+type GasCeilingError struct {
+	BaseFee *big.Int
+	Ceiling *big.Int
+}
+
+func (e *GasCeilingError) Error() string {
+	return fmt.Sprintf("base fee %s wei exceeds the chain's ceiling %s wei", e.BaseFee, e.Ceiling)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *GasCeilingError) ErrorCode() string { return GasCeilingCode }
+
+// exceedsGasCeiling 基础费超过上限时返回 GasCeilingError。不支持 EIP-1559 的链按 gas price 比较。
+func exceedsGasCeiling(fees *gas.Fees, ceiling *big.Int) error {
+	baseFee := fees.BaseFee
+	if baseFee == nil || baseFee.Sign() == 0 {
+		baseFee = fees.FeeCap
+	}
+	if baseFee.Cmp(ceiling) > 0 {
+		return &GasCeilingError{BaseFee: baseFee, Ceiling: ceiling}
+	}
+	return nil
+}
+
+// checkGasCeiling 链配置了基础费上限时，超过则不发送 (Wait 模式下标记为等待费用回落)
+func (s *PayoutService) checkGasCeiling(ctx context.Context, job *queue.Job) error {
+	cfg := s.chainConfig(job.ChainID).GasCeiling
+	ceiling, err := cfg.MaxBaseFee()
+	if err != nil || ceiling == nil {
+		return nil
+	}
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return err
+	}
+	if err := exceedsGasCeiling(fees, ceiling); err != nil {
+		if cfg.Wait {
+			return queue.GasHold(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RunGasCeilingMonitor 定期检查等待费用回落的任务，基础费不超过上限 (或已取消上限、Wait 模式) 时放回队列
+func (s *PayoutService) RunGasCeilingMonitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Gas ceiling monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.releaseGasHeld(ctx)
+		}
+	}
+}
+
+func (s *PayoutService) releaseGasHeld(ctx context.Context) {
+	for chainID, chain := range s.chainConfigs() {
+		held, err := s.queue.GasHeldCount(ctx, chainID)
+		if err != nil || held == 0 {
+			continue
+		}
+		if ceiling, _ := chain.GasCeiling.MaxBaseFee(); ceiling != nil && chain.GasCeiling.Wait {
+			fees, err := s.suggestFees(ctx, chainID, "")
+			if err != nil {
+				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to check gas price for held jobs")
+				continue
+			}
+			if err := exceedsGasCeiling(fees, ceiling); err != nil {
+				log.Debug().Uint64("chain_id", chainID).Int64("held", held).Err(err).Msg("Gas price still above ceiling")
+				continue
+			}
+		}
+		if _, err := s.queue.ReleaseGasHeld(ctx, chainID); err != nil {
+			log.Error().Err(err).Uint64("chain_id", chainID).Msg("Failed to release jobs held for gas price")
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/rs/zerolog/log"
+)
+
+// gas 补充告警事件
+const (
+	GasTankEventRefillFailed        = "gas_tank.refill_failed"        // 补充交易构建、广播或上链失败
+	GasTankEventFundingInsufficient = "gas_tank.funding_insufficient" // 资金钱包余额不足以补充
+)
+
+// GasTankAlert gas 补充告警 (POST 到 GAS_TANK_ALERT_WEBHOOK_URL，签名方式同批次回调)
+type GasTankAlert struct {
+	Event          string `json:"event"`
+	ChainID        uint64 `json:"chain_id"`
+	Chain          string `json:"chain"`
+	Address        string `json:"address"`         // 待补充的运营地址
+	Balance        string `json:"balance"`         // 运营地址当前余额
+	MinBalance     string `json:"min_balance"`     // 补充阈值
+	Amount         string `json:"amount"`          // 计划补充金额
+	Funding        string `json:"funding"`         // 资金钱包地址
+	FundingBalance string `json:"funding_balance"` // 资金钱包余额
+	TxHash         string `json:"tx_hash,omitempty"`
+	Error          string `json:"error,omitempty"`
+	Timestamp      int64  `json:"timestamp"`
+}
+
+// SetGasTankSigner 设置 EVM 资金钱包签名器 (须在 RunGasTankMonitor 之前调用)
+func (s *PayoutService) SetGasTankSigner(signer kms.Signer) {
+	s.gasTankSigner = signer
+}
+
+// SetTronGasTankSigner 设置 TRON 资金钱包签名器 (须在 RunGasTankMonitor 之前调用)
+func (s *PayoutService) SetTronGasTankSigner(signer kms.Signer) {
+	s.tronGasTankSigner = signer
+}
+
+// RunGasTankMonitor 定期检查各链运营地址的原生代币余额，低于阈值时由资金钱包补充，失败时告警
+func (s *PayoutService) RunGasTankMonitor(ctx context.Context, interval time.Duration) {
+	if !s.cfg.GasTank.Enabled() {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	log.Info().Dur("interval", interval).Msg("Gas tank monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkGasTanks(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Gas tank monitor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkGasTanks 检查一轮所有配置了阈值的链
+func (s *PayoutService) checkGasTanks(ctx context.Context) {
+	for chainID, chainCfg := range s.chainConfigs() {
+		if chainCfg.GasTank.MinBalance == "" {
+			continue
+		}
+		funding, err := s.gasTankFunding(chainID)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Gas tank skipped")
+			continue
+		}
+		payout, _ := s.payoutAddress(chainID)
+		for _, address := range gasTankAddresses(payout, funding, chainCfg.GasTank.Addresses) {
+			s.refillIfLow(ctx, chainID, chainCfg, funding, address)
+		}
+	}
+}
+
+// gasTankAddresses 需要监控的运营地址: 付款钱包和配置的其他地址 (去重，排除资金钱包自身)
+func gasTankAddresses(payout, funding string, extra []string) []string {
+	seen := map[string]bool{strings.ToLower(funding): true}
+	var out []string
+	for _, address := range append([]string{payout}, extra...) {
+		key := strings.ToLower(address)
+		if address == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, address)
+	}
+	return out
+}
+
+// gasTankRefill 余额低于 min 时返回补充到 target 的金额，否则为 nil
+func gasTankRefill(balance, min, target *big.Int) *big.Int {
+	if balance.Cmp(min) >= 0 {
+		return nil
+	}
+	return new(big.Int).Sub(target, balance)
+}
+
+// gasTankFunding 链上资金钱包地址
+func (s *PayoutService) gasTankFunding(chainID uint64) (string, error) {
+	if _, ok := s.tronClient(chainID); ok {
+		if s.tronGasTankSigner == nil {
+			return "", fmt.Errorf("TRON funding wallet is not configured (set GAS_TANK_TRON_FUNDING_PRIVATE_KEY)")
+		}
+		return tronSignerAddress(s.tronGasTankSigner), nil
+	}
+	if s.gasTankSigner == nil {
+		return "", fmt.Errorf("EVM funding wallet is not configured (set GAS_TANK_FUNDING_PRIVATE_KEY or GAS_TANK_FUNDING_VAULT_ACCOUNT_ID)")
+	}
+	return s.gasTankSigner.Address().Hex(), nil
+}
+
+// refillIfLow 地址余额低于阈值时从资金钱包补充。同一地址在冷却期内只补充一次 (多实例共享)。
+func (s *PayoutService) refillIfLow(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, funding, address string) {
+	min, target, err := chainCfg.GasTank.Thresholds()
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Invalid gas tank thresholds")
+		return
+	}
+	balance, err := s.nativeBalance(ctx, chainID, address)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Str("address", address).Msg("Gas tank balance check failed")
+		return
+	}
+	amount := gasTankRefill(balance, min, target)
+	if amount == nil {
+		return
+	}
+	claimed, err := s.queue.ClaimGasTankTopUp(ctx, chainID, address, s.gasTankCooldown())
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Str("address", address).Msg("Failed to claim gas tank top-up")
+		return
+	}
+	if !claimed {
+		log.Debug().Uint64("chain_id", chainID).Str("address", address).Msg("Gas tank cooldown active, skipping top-up")
+		return
+	}
+
+	alert := GasTankAlert{
+		ChainID:    chainID,
+		Chain:      chainCfg.Name,
+		Address:    address,
+		Balance:    balance.String(),
+		MinBalance: min.String(),
+		Amount:     amount.String(),
+		Funding:    funding,
+	}
+	fundingBalance, err := s.nativeBalance(ctx, chainID, funding)
+	if err != nil {
+		alert.Error = fmt.Sprintf("failed to read funding balance: %v", err)
+		s.sendGasTankAlert(ctx, GasTankEventRefillFailed, alert)
+		return
+	}
+	alert.FundingBalance = fundingBalance.String()
+	if fundingBalance.Cmp(amount) <= 0 {
+		s.sendGasTankAlert(ctx, GasTankEventFundingInsufficient, alert)
+		return
+	}
+
+	log.Info().
+		Uint64("chain_id", chainID).
+		Str("address", address).
+		Str("balance", balance.String()).
+		Str("min_balance", min.String()).
+		Str("amount", amount.String()).
+		Str("funding", funding).
+		Msg("Operational address below gas threshold, topping up")
+
+	confirmCtx, cancel := context.WithTimeout(ctx, s.gasTankConfirmTimeout())
+	defer cancel()
+	var txHash string
+	if client, ok := s.tronClient(chainID); ok {
+		txHash, err = s.sendTronTopUp(confirmCtx, client, chainID, funding, address, amount)
+	} else {
+		txHash, err = s.sendEVMTopUp(confirmCtx, chainID, address, amount)
+	}
+	alert.TxHash = txHash
+	if err != nil {
+		alert.Error = err.Error()
+		s.sendGasTankAlert(ctx, GasTankEventRefillFailed, alert)
+		return
+	}
+	log.Info().
+		Uint64("chain_id", chainID).
+		Str("address", address).
+		Str("amount", amount.String()).
+		Str("tx_hash", txHash).
+		Msg("Gas tank top-up confirmed")
+}
+
+// sendEVMTopUp 资金钱包向 to 转入原生代币并等待上链
+func (s *PayoutService) sendEVMTopUp(ctx context.Context, chainID uint64, to string, amount *big.Int) (string, error) {
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return "", fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	from := s.gasTankSigner.Address()
+
+	fees, err := s.suggestFees(ctx, chainID, string(gas.PriorityHigh))
+	if err != nil {
+		return "", fmt.Errorf("failed to get fees: %w", err)
+	}
+	nonceVal, releaseFn, err := s.nonceManager.GetNonceLimited(ctx, chainID, from, s.maxInFlight(chainID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", inFlightError(err))
+	}
+	recipient := common.HexToAddress(to)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonceVal,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       nativeTransferGas,
+		To:        &recipient,
+		Value:     amount,
+	})
+	cid := new(big.Int).SetUint64(chainID)
+	op := signingOp{purpose: signPurposeGasTank}
+	signedTx, err := s.gasTankSigner.SignTransaction(signingContext(ctx, op), tx, cid)
+	auditErr := s.auditSigning(ctx, chainID, op, types.LatestSignerForChainID(cid).Hash(tx).Bytes(),
+		s.gasTankSigner.Provider(), s.gasTankSigner.Address().Hex(), err)
+	if err == nil {
+		err = auditErr
+	}
+	if err == nil {
+		err = s.broadcastTransaction(ctx, client, chainID, signedTx)
+	}
+	if err != nil {
+		// nonce 已预占，未发出时重新从链上读取
+		s.nonceManager.ResetNonce(ctx, chainID, from)
+		releaseFn()
+		return "", fmt.Errorf("failed to send top-up: %w", err)
+	}
+	releaseFn()
+
+	txHash := signedTx.Hash().Hex()
+	receipt, err := waitMined(ctx, client, signedTx.Hash())
+	if err != nil {
+		return txHash, fmt.Errorf("top-up not mined: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return txHash, fmt.Errorf("top-up reverted")
+	}
+	return txHash, nil
+}
+
+// tronTransferClient TRX 转账所需的节点接口 (*tronclient.GrpcClient)
+type tronTransferClient interface {
+	tronTxInfoClient
+	Transfer(from, toAddress string, amount int64) (*tronapi.TransactionExtention, error)
+	Broadcast(tx *troncore.Transaction) (*tronapi.Return, error)
+}
+
+// sendTronTopUp 资金钱包向 to 转入 TRX 并等待上链
+func (s *PayoutService) sendTronTopUp(ctx context.Context, client tronTransferClient, chainID uint64, from, to string, amount *big.Int) (string, error) {
+	if !amount.IsInt64() {
+		return "", fmt.Errorf("top-up amount %s out of range", amount)
+	}
+	txExt, err := client.Transfer(from, to, amount.Int64())
+	if err != nil {
+		return "", fmt.Errorf("failed to build top-up: %w", err)
+	}
+	if txExt.GetTransaction() == nil || (txExt.GetResult() != nil && txExt.GetResult().GetCode() != tronapi.Return_SUCCESS) {
+		return "", fmt.Errorf("TRON node rejected top-up: %s", string(txExt.GetResult().GetMessage()))
+	}
+	signedTx, err := s.signTronTransaction(ctx, txExt.GetTransaction(), txExt.GetTxid(), s.tronGasTankSigner, chainID, signingOp{purpose: signPurposeGasTank})
+	if err != nil {
+		return "", err
+	}
+	result, err := client.Broadcast(signedTx)
+	recordTronBroadcastFailure(chainID, result, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast top-up: %w", err)
+	}
+	if !result.GetResult() {
+		return "", fmt.Errorf("TRON broadcast rejected (code=%v): %s", result.GetCode(), string(result.GetMessage()))
+	}
+
+	txHash := hex.EncodeToString(txExt.GetTxid())
+	deadline, _ := ctx.Deadline()
+	info, err := s.waitForTronConfirmation(ctx, client, txHash, time.Until(deadline))
+	if err != nil {
+		return txHash, fmt.Errorf("top-up not confirmed: %w", err)
+	}
+	if info == nil {
+		return txHash, fmt.Errorf("top-up not confirmed within %s", s.gasTankConfirmTimeout())
+	}
+	return txHash, tronExecutionError(txHash, info)
+}
+
+func (s *PayoutService) gasTankCooldown() time.Duration {
+	if s.cfg.GasTank.Cooldown > 0 {
+		return s.cfg.GasTank.Cooldown
+	}
+	return 10 * time.Minute
+}
+
+func (s *PayoutService) gasTankConfirmTimeout() time.Duration {
+	if s.cfg.GasTank.ConfirmTimeout > 0 {
+		return s.cfg.GasTank.ConfirmTimeout
+	}
+	return 2 * time.Minute
+}
+
+// sendGasTankAlert 记录并投递告警 (未配置告警地址时只记录日志)
+func (s *PayoutService) sendGasTankAlert(ctx context.Context, event string, alert GasTankAlert) {
+	log.Error().
+		Str("event", event).
+		Uint64("chain_id", alert.ChainID).
+		Str("address", alert.Address).
+		Str("balance", alert.Balance).
+		Str("amount", alert.Amount).
+		Str("funding", alert.Funding).
+		Str("funding_balance", alert.FundingBalance).
+		Str("tx_hash", alert.TxHash).
+		Str("error", alert.Error).
+		Msg("Gas tank alert")
+
+	if s.cfg.GasTank.AlertURL == "" {
+		return
+	}
+	alert.Event = event
+	alert.Timestamp = time.Now().Unix()
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	eventID := fmt.Sprintf("%s:%d:%s:%d", event, alert.ChainID, alert.Address, alert.Timestamp)
+	if err := s.webhookSender.Post(ctx, s.cfg.GasTank.AlertURL, s.cfg.GasTank.AlertSecret, eventID, body); err != nil {
+		log.Warn().Err(err).Str("event", event).Uint64("chain_id", alert.ChainID).Msg("Failed to deliver gas tank alert")
+	}
+}
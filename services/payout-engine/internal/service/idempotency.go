@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// hashRequest 请求指纹 (不含幂等键本身)，用于识别幂等键被复用到不同请求
+func hashRequest(req *BatchPayoutRequest) (string, error) {
+	r := *req
+	r.IdempotencyKey = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayResponse 根据已有幂等记录返回首次提交的响应
+func replayResponse(req *BatchPayoutRequest, key, requestHash string, record *queue.IdempotencyRecord) (*BatchPayoutResponse, error) {
+	if record.RequestHash != requestHash {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("idempotency key %q was already used with a different request", key)}
+	}
+	if len(record.Response) == 0 {
+		return nil, queue.ErrIdempotencyInProgress
+	}
+
+	var resp BatchPayoutResponse
+	if err := json.Unmarshal(record.Response, &resp); err != nil {
+		return nil, fmt.Errorf("corrupt idempotent response: %w", err)
+	}
+	resp.Replayed = true
+
+	log.Info().
+		Str("batch_id", req.BatchID).
+		Str("idempotency_key", key).
+		Msg("Duplicate batch submission, returning original response")
+	return &resp, nil
+}
+
+// storedIdempotencyRecord 账本中保留期内的幂等记录 (Redis 中的记录被淘汰时使用)，找到时写回 Redis。
+// 未配置账本时返回 nil。
+func (s *PayoutService) storedIdempotencyRecord(ctx context.Context, userID, key string) (*queue.IdempotencyRecord, error) {
+	if s.ledger == nil {
+		return nil, nil
+	}
+	record, err := s.ledger.IdempotentResponse(ctx, userID, key, time.Now().Add(-queue.DefaultIdempotencyTTL))
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if err := s.queue.CompleteIdempotencyKey(ctx, userID, key, record.RequestHash, record.Response, queue.DefaultIdempotencyTTL); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("idempotency_key", key).Msg("Failed to restore idempotent response")
+	}
+	return record, nil
+}
+
+// saveIdempotentResponse 将原始响应写入账本，失败时仅记录错误 (Redis 中仍有记录)
+func (s *PayoutService) saveIdempotentResponse(ctx context.Context, userID, key, requestHash string, resp *BatchPayoutResponse) {
+	if s.ledger == nil {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = s.ledger.SaveIdempotentResponse(ctx, userID, key, requestHash, data)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("idempotency_key", key).Msg("Failed to store idempotent response in ledger")
+	}
+}
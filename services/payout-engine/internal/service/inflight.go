@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/nonce"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+)
+
+// InFlightLimitCode 付款地址在途交易达到上限 (见 Config.MaxInFlightTxs)
+const InFlightLimitCode = "IN_FLIGHT_LIMIT"
+
+// inFlightRetry 等待在途交易上链: 出块即可释放名额，重试次数足以覆盖拥堵时段
+var inFlightRetry = queue.RetryPolicy{MaxRetries: 120, InitialBackoff: 5 * time.Second, MaxBackoff: 30 * time.Second, Multiplier: 1.5, Jitter: 0.3}
+
+// InFlightLimitError 付款地址在途交易过多，暂不分配 nonce，稍后重试 (不计入链熔断)
+type InFlightLimitError struct {
+	Err *nonce.InFlightError
+}
+
+func (e *InFlightLimitError) Error() string { return e.Err.Error() }
+func (e *InFlightLimitError) Unwrap() error { return e.Err }
+
+// ErrorCode implements queue.CodedError.
+func (e *InFlightLimitError) ErrorCode() string { return InFlightLimitCode }
+
+// RetryPolicy implements queue.RetryHint.
+func (e *InFlightLimitError) RetryPolicy() queue.RetryPolicy { return inFlightRetry }
+
+// maxInFlight 链的付款地址在途交易上限 (链配置优先，0 表示不限制)
+func (s *PayoutService) maxInFlight(chainID uint64) int {
+	if n := s.chainConfig(chainID).MaxInFlight; n > 0 {
+		return n
+	}
+	return s.cfg.MaxInFlightTxs
+}
+
+// inFlightError 在途交易达到上限时转换为限流错误，其余原样返回
+func inFlightError(err error) error {
+	var inFlight *nonce.InFlightError
+	if errors.As(err, &inFlight) {
+		return queue.Throttled(&InFlightLimitError{Err: inFlight})
+	}
+	return err
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// inventoryOutflowDays 日均流出按最近 7 天计算
+const inventoryOutflowDays = 7
+
+// WalletInventory 付款钱包在某条链上某种代币的库存和预测
+type WalletInventory struct {
+	ChainID         uint64
+	ChainName       string
+	Address         string
+	Token           string // 原生代币为 ""
+	Symbol          string
+	Balance         string   // 链上余额 (最小单位)
+	PendingOutflow  string   // 排队中/处理中任务的待支出金额 (原生代币含预留网络费)
+	PendingJobs     int      // 涉及该代币的未完成任务数
+	Available       string   // Balance - PendingOutflow，可能为负
+	AvgDailyOutflow string   // 最近 7 天日均已完成支出
+	RunwayDays      *float64 // Available / AvgDailyOutflow，无历史支出时为 nil
+	Error           string   // 读取余额失败时的错误
+}
+
+// inventoryKey 库存聚合维度
+type inventoryKey struct {
+	chainID uint64
+	address string
+	token   string
+}
+
+type inventoryEntry struct {
+	pending     *big.Int
+	pendingJobs int
+	outflow     float64
+}
+
+// GetWalletInventory 返回付款钱包的余额、待支出承诺和按近期支出速度预测的可用天数。
+// chainID 为 0 时返回全部链。
+func (s *PayoutService) GetWalletInventory(ctx context.Context, chainID uint64) ([]*WalletInventory, error) {
+	entries := make(map[inventoryKey]*inventoryEntry)
+	entry := func(chain uint64, address, token string) *inventoryEntry {
+		key := inventoryKey{chainID: chain, address: normalizeTokenKey(address), token: normalizeTokenKey(token)}
+		e, ok := entries[key]
+		if !ok {
+			e = &inventoryEntry{pending: new(big.Int)}
+			entries[key] = e
+		}
+		return e
+	}
+	wanted := func(chain uint64) bool {
+		if chainID != 0 && chain != chainID {
+			return false
+		}
+		_, evm := s.evmClient(chain)
+		_, tron := s.tronClient(chain)
+		return evm || tron
+	}
+
+	// 付款钱包: 签名器 / TRON 地址、智能账户，以及白名单代币
+	for chain := range s.chainConfigs() {
+		if !wanted(chain) {
+			continue
+		}
+		address, err := s.payoutAddress(chain)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chain).Msg("Failed to resolve payout address")
+			continue
+		}
+		addresses := []string{address}
+		if aaClient, ok := s.aaClient(chain); ok {
+			addresses = append(addresses, aaClient.SmartAccount().Hex())
+		}
+		for _, addr := range addresses {
+			entry(chain, addr, "")
+			for _, token := range s.allowlist.Tokens(chain) {
+				entry(chain, addr, token.Address)
+			}
+		}
+	}
+
+	// 待支出: 排队中和处理中的任务
+	jobs, err := s.queue.PendingJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	feeCache := make(map[string][2]*big.Int)
+	for _, job := range jobs {
+		if !wanted(job.ChainID) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(job.Amount, 10)
+		if !ok {
+			continue
+		}
+		e := entry(job.ChainID, job.Source(), job.Asset()) // 代付任务计入源钱包
+		e.pending.Add(e.pending, amount)
+		e.pendingJobs++
+
+		gasFee := s.pendingJobGas(ctx, job, feeCache)
+		if gasFee.Sign() > 0 {
+			native := entry(job.ChainID, job.FromAddress, "")
+			native.pending.Add(native.pending, gasFee)
+		}
+	}
+
+	// 近期支出
+	totals, err := s.queue.OutflowTotals(ctx, inventoryOutflowDays)
+	if err != nil {
+		return nil, err
+	}
+	for key, total := range totals {
+		if !wanted(key.ChainID) {
+			continue
+		}
+		entry(key.ChainID, key.FromAddress, key.Token).outflow += total
+	}
+
+	inventory := make([]*WalletInventory, 0, len(entries))
+	for key, e := range entries {
+		inventory = append(inventory, s.buildInventory(ctx, key, e))
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Token < b.Token
+	})
+	return inventory, nil
+}
+
+// pendingJobGas 未完成任务预留的原生代币网络费 (按链和优先级缓存)
+func (s *PayoutService) pendingJobGas(ctx context.Context, job *queue.Job, cache map[string][2]*big.Int) *big.Int {
+	sponsored := false
+	if job.SmartAccount {
+		if aaClient, ok := s.aaClient(job.ChainID); ok {
+			sponsored = aaClient.Sponsored()
+		}
+	}
+	cacheKey := fmt.Sprintf("%d:%s:%t", job.ChainID, job.Priority, sponsored)
+	fees, ok := cache[cacheKey]
+	if !ok {
+		nativeGas, tokenGas, err := s.transferFeeReservations(ctx, job.ChainID, job.Priority, sponsored)
+		if err != nil {
+			nativeGas, tokenGas = big.NewInt(0), big.NewInt(0)
+		}
+		fees = [2]*big.Int{nativeGas, tokenGas}
+		cache[cacheKey] = fees
+	}
+	// TRC10 与 TRX 转账一样只消耗带宽
+	if isNativeToken(job.TokenAddress) {
+		return fees[0]
+	}
+	return fees[1]
+}
+
+// buildInventory 读取链上余额并计算可用余额和 runway
+func (s *PayoutService) buildInventory(ctx context.Context, key inventoryKey, e *inventoryEntry) *WalletInventory {
+	chainCfg := s.chainConfig(key.chainID)
+	inv := &WalletInventory{
+		ChainID:         key.chainID,
+		ChainName:       chainCfg.Name,
+		Address:         key.address,
+		Token:           key.token,
+		Symbol:          chainCfg.NativeToken,
+		PendingOutflow:  e.pending.String(),
+		PendingJobs:     e.pendingJobs,
+		AvgDailyOutflow: new(big.Float).SetFloat64(e.outflow/inventoryOutflowDays).Text('f', 0),
+	}
+	if key.token != "" {
+		inv.Symbol = ""
+		for _, token := range s.allowlist.Tokens(key.chainID) {
+			if normalizeTokenKey(token.Address) == key.token {
+				inv.Symbol = token.Symbol
+				break
+			}
+		}
+	}
+
+	var balance *big.Int
+	var err error
+	if key.token == "" {
+		balance, err = s.nativeBalance(ctx, key.chainID, key.address)
+	} else {
+		balance, err = s.tokenBalance(ctx, key.chainID, key.address, key.token)
+	}
+	if err != nil {
+		inv.Error = err.Error()
+		return inv
+	}
+
+	available := new(big.Int).Sub(balance, e.pending)
+	inv.Balance = balance.String()
+	inv.Available = available.String()
+	inv.RunwayDays = projectRunway(available, e.outflow/inventoryOutflowDays)
+	return inv
+}
+
+// projectRunway 按日均支出估算可用余额还能支撑的天数
+func projectRunway(available *big.Int, avgDaily float64) *float64 {
+	if avgDaily <= 0 {
+		return nil
+	}
+	days := 0.0
+	if available.Sign() > 0 {
+		f, _ := new(big.Float).SetInt(available).Float64()
+		days = f / avgDaily
+	}
+	return &days
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+	"github.com/rs/zerolog/log"
+)
+
+// ledgerBatchSize 每个事务最多写入的记录数
+const ledgerBatchSize = 500
+
+// ErrLedgerDisabled 未配置数据库
+var ErrLedgerDisabled = errors.New("job ledger is not configured")
+
+// RunLedgerWriter 将 Redis outbox 中的任务状态变更写入 Postgres 账本。
+// 写库失败时记录保留在 outbox，租期结束后重试。
+func (s *PayoutService) RunLedgerWriter(ctx context.Context, interval time.Duration) {
+	if s.ledger == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Job ledger writer started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushLedger(ctx)
+		}
+	}
+}
+
+// flushLedger 写入所有到期记录
+func (s *PayoutService) flushLedger(ctx context.Context) {
+	for {
+		entries, err := s.queue.ClaimLedgerEntries(ctx, time.Now(), ledgerBatchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to claim ledger entries")
+			return
+		}
+		if len(entries) == 0 {
+			return
+		}
+
+		if err := s.ledger.Record(ctx, entries); err != nil {
+			log.Error().Err(err).Int("entries", len(entries)).Msg("Failed to write job ledger")
+			return
+		}
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			ids[i] = e.ID
+		}
+		if err := s.queue.AckLedgerEntries(ctx, ids); err != nil {
+			log.Warn().Err(err).Msg("Failed to ack ledger entries") // 重复写入按 entry ID 去重
+		}
+		if len(entries) < ledgerBatchSize {
+			return
+		}
+	}
+}
+
+// JobAttempts 运维查询任务的状态变更历史 (userID 可为空)，不受 Redis 状态保留期限制
+func (s *PayoutService) JobAttempts(ctx context.Context, userID, jobID string) ([]ledger.Attempt, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	attempts, err := s.ledger.Attempts(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		return nil, ErrJobNotFound
+	}
+	return attempts, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+)
+
+// signManifest 入队前生成批次任务清单并用付款签名器签名
+func (s *PayoutService) signManifest(ctx context.Context, jobs []*queue.Job) (*queue.Manifest, error) {
+	if s.signer == nil {
+		return nil, fmt.Errorf("critical: payment processing signer is not configured")
+	}
+	body := queue.NewManifestBody(jobs)
+	digest, err := body.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	signingHash := manifestSigningHash(digest)
+	op := signingOp{purpose: signPurposeManifest}
+	var chainID uint64
+	if len(jobs) > 0 {
+		op.userID, op.batchID, chainID = jobs[0].UserID, jobs[0].BatchID, jobs[0].ChainID
+	}
+	sig, err := s.signer.SignHash(signingContext(ctx, op), signingHash)
+	if auditErr := s.auditSigning(ctx, chainID, op, signingHash, s.signer.Provider(), s.signer.Address().Hex(), err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign manifest: %w", err)
+	}
+	sig = append([]byte(nil), sig...)
+	sig[64] += 27
+
+	return &queue.Manifest{
+		ManifestBody: body,
+		Hash:         hexutil.Encode(digest),
+		Signature:    hexutil.Encode(sig),
+		Signer:       s.signer.Address().Hex(),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// manifestSigningHash EIP-191 personal_sign 摘要，外部可用 ecrecover / ethers.verifyMessage(hash bytes) 验证
+func manifestSigningHash(digest []byte) []byte {
+	return crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), digest)
+}
+
+// VerifyManifest 重新计算清单哈希并校验签名来自 Signer
+func VerifyManifest(m *queue.Manifest) error {
+	digest, err := m.ManifestBody.Digest()
+	if err != nil {
+		return err
+	}
+	if hexutil.Encode(digest) != m.Hash {
+		return fmt.Errorf("manifest hash mismatch")
+	}
+
+	sig, err := hexutil.Decode(m.Signature)
+	if err != nil || len(sig) != 65 {
+		return fmt.Errorf("invalid manifest signature")
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(manifestSigningHash(digest), sig)
+	if err != nil {
+		return fmt.Errorf("invalid manifest signature: %w", err)
+	}
+	if !bytes.Equal(crypto.PubkeyToAddress(*pub).Bytes(), common.HexToAddress(m.Signer).Bytes()) {
+		return fmt.Errorf("manifest signed by %s, expected %s", crypto.PubkeyToAddress(*pub).Hex(), m.Signer)
+	}
+	return nil
+}
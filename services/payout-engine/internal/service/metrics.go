@@ -0,0 +1,53 @@
+package service
+
+import (
+	"strings"
+
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+)
+
+// jobOutcome 任务处理指标的 outcome 标签: success、failed (任务失败) 或 error (处理出错，任务将重试)
+func jobOutcome(result *queue.JobResult, err error) string {
+	switch {
+	case err != nil || result == nil:
+		return "error"
+	case result.Success:
+		return "success"
+	default:
+		return "failed"
+	}
+}
+
+// signingResult 签名耗时指标的 result 标签
+func signingResult(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// recordBroadcastFailure 按错误类别统计广播失败 (未识别的类别记为 other)
+func recordBroadcastFailure(chainID uint64, err error) {
+	if err == nil {
+		return
+	}
+	reason := string(rpcpool.ClassifyTxError(err))
+	if reason == "" {
+		reason = "other"
+	}
+	metrics.BroadcastFailures.Inc(metrics.Label(chainID), reason)
+}
+
+// recordTronBroadcastFailure 统计 TRON 广播失败: 节点拒绝时原因为结果码 (如 contract_validate_error)，
+// 请求失败时为 other
+func recordTronBroadcastFailure(chainID uint64, result *tronapi.Return, err error) {
+	switch {
+	case err != nil:
+		metrics.BroadcastFailures.Inc(metrics.Label(chainID), "other")
+	case !result.GetResult():
+		metrics.BroadcastFailures.Inc(metrics.Label(chainID), strings.ToLower(result.GetCode().String()))
+	}
+}
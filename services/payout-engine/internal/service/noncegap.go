@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// nonceRepair 一个地址的 nonce 空缺修复计划
+type nonceRepair struct {
+	rebroadcast []*queue.PendingTx // 节点未收到 (或已丢弃) 的已记录交易
+	fill        []uint64           // 没有任何交易记录的 nonce，以 0 值自转账填补
+	blocked     []*queue.PendingTx // 被空缺阻塞的交易 (本轮不做卡单替换)
+}
+
+// planNonceRepair 比较节点的 pending nonce 与已记录的待确认交易，找出空缺。
+// 节点的 pending nonce 停在第一个缺失的 nonce，其后的交易无法上链；
+// 只有空缺之后最早的交易已等待超过 grace 时才修复，避免与正在发送的任务抢占同一 nonce。
+func planNonceRepair(pending []*queue.PendingTx, confirmed, nodePending uint64, now time.Time, grace time.Duration) *nonceRepair {
+	byNonce := make(map[uint64]*queue.PendingTx)
+	var highest uint64
+	found := false
+	for _, p := range pending {
+		if p.Nonce < confirmed {
+			continue // 已上链，由卡单检测清理
+		}
+		byNonce[p.Nonce] = p
+		if !found || p.Nonce > highest {
+			highest, found = p.Nonce, true
+		}
+	}
+	if !found || highest < nodePending {
+		return nil
+	}
+
+	// 空缺之后的交易都在等待中，最早发送的已超过 grace 才视为空缺 (而不是刚发送的交易尚未传播)
+	start := nodePending
+	if start < confirmed {
+		start = confirmed
+	}
+	waiting := false
+	for n, p := range byNonce {
+		if n > start && now.Sub(p.SentAt) >= grace {
+			waiting = true
+		}
+	}
+	if !waiting {
+		return nil
+	}
+
+	plan := &nonceRepair{}
+	for n := start; n <= highest; n++ {
+		p, ok := byNonce[n]
+		switch {
+		case !ok:
+			plan.fill = append(plan.fill, n)
+		case p.PrivateUntil == nil || now.After(*p.PrivateUntil):
+			plan.rebroadcast = append(plan.rebroadcast, p)
+		}
+		if ok && n > start {
+			plan.blocked = append(plan.blocked, p)
+		}
+	}
+	if len(plan.fill) == 0 && len(plan.rebroadcast) == 0 {
+		return nil
+	}
+	return plan
+}
+
+// repairNonceGaps 按付款地址检查 nonce 空缺并修复: 重新广播节点缺失的交易，
+// 没有交易记录的 nonce (签名后广播失败、交易被丢弃且未记录等) 以 0 值自转账填补。
+// 返回被空缺阻塞的交易 (键为 PendingTx.Key())，本轮不对其做卡单替换。
+func (s *PayoutService) repairNonceGaps(ctx context.Context, pending []*queue.PendingTx) map[string]bool {
+	type account struct {
+		chainID uint64
+		from    string
+	}
+	groups := make(map[account][]*queue.PendingTx)
+	for _, p := range pending {
+		key := account{p.ChainID, strings.ToLower(p.FromAddress)}
+		groups[key] = append(groups[key], p)
+	}
+
+	blocked := make(map[string]bool)
+	for acct, txs := range groups {
+		client, ok := s.evmClient(acct.chainID)
+		if !ok || !common.IsHexAddress(acct.from) {
+			continue
+		}
+		from := common.HexToAddress(acct.from)
+		confirmed, err := client.NonceAt(ctx, from, nil)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", acct.chainID).Str("from", from.Hex()).Msg("Nonce gap check failed")
+			continue
+		}
+		nodePending, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", acct.chainID).Str("from", from.Hex()).Msg("Nonce gap check failed")
+			continue
+		}
+
+		plan := planNonceRepair(txs, confirmed, nodePending, time.Now(), s.chainConfig(acct.chainID).StuckTxTimeout)
+		if plan == nil {
+			continue
+		}
+		for _, p := range plan.blocked {
+			blocked[p.Key()] = true
+		}
+		log.Warn().
+			Uint64("chain_id", acct.chainID).
+			Str("from", from.Hex()).
+			Uint64("confirmed_nonce", confirmed).
+			Uint64("pending_nonce", nodePending).
+			Int("rebroadcast", len(plan.rebroadcast)).
+			Int("fill", len(plan.fill)).
+			Msg("Nonce gap detected, repairing")
+
+		for _, p := range plan.rebroadcast {
+			if err := s.rebroadcastTx(ctx, client, p); err != nil {
+				log.Error().Err(err).Str("job_id", p.JobID).Uint64("nonce", p.Nonce).Msg("Failed to rebroadcast transaction")
+			}
+		}
+		for _, n := range plan.fill {
+			if err := s.fillNonce(ctx, client, acct.chainID, from, n); err != nil {
+				log.Error().Err(err).Uint64("chain_id", acct.chainID).Str("from", from.Hex()).Uint64("nonce", n).Msg("Failed to fill nonce gap")
+				break // 后面的 nonce 仍被阻塞，下一轮重试
+			}
+		}
+	}
+	return blocked
+}
+
+// rebroadcastTx 重新广播已记录的交易 (节点已收到时返回 already known，视为成功)
+func (s *PayoutService) rebroadcastTx(ctx context.Context, client *rpcpool.Pool, p *queue.PendingTx) error {
+	stx, err := parsePendingTx(p)
+	if err != nil {
+		return err
+	}
+	if err := s.broadcastSigned(ctx, client, p.ChainID, stx); err != nil {
+		return err
+	}
+	log.Info().Str("job_id", p.JobID).Str("tx_hash", p.TxHash).Uint64("nonce", p.Nonce).Msg("Rebroadcast transaction for nonce gap")
+	return nil
+}
+
+// fillNonce 以 0 值自转账占用空缺的 nonce，交易记录后由卡单检测跟踪确认和替换
+func (s *PayoutService) fillNonce(ctx context.Context, client *rpcpool.Pool, chainID uint64, from common.Address, nonceVal uint64) error {
+	signer := s.signerForAddress(chainID, from)
+	if signer == nil || signer.Address() != from {
+		return fmt.Errorf("no signer for %s", from.Hex())
+	}
+	fees, err := s.suggestFees(ctx, chainID, string(gas.PriorityHigh))
+	if err != nil {
+		return fmt.Errorf("failed to get fees: %w", err)
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonceVal,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       nativeTransferGas,
+		To:        &from,
+		Value:     big.NewInt(0),
+	})
+	filler := &queue.Job{ID: fmt.Sprintf("noncegap:%d:%d", chainID, nonceVal), ChainID: chainID, FromAddress: from.Hex()}
+	signedTx, err := s.signTransaction(ctx, tx, chainID, signingOp{jobID: filler.ID, purpose: signPurposeNonceFill, from: from})
+	if err != nil {
+		return fmt.Errorf("failed to sign filler: %w", err)
+	}
+	if err := s.broadcastTransaction(ctx, client, chainID, signedTx); err != nil {
+		return fmt.Errorf("failed to send filler: %w", err)
+	}
+	s.trackPendingTx(ctx, filler, signedTx)
+	log.Warn().
+		Uint64("chain_id", chainID).
+		Str("from", from.Hex()).
+		Uint64("nonce", nonceVal).
+		Str("tx_hash", signedTx.Hash().Hex()).
+		Msg("Filled nonce gap with self-transfer")
+	return nil
+}
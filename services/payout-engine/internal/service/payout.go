@@ -4,38 +4,121 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	tronaddress "github.com/fbsobreira/gotron-sdk/pkg/address"
 	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
 	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
 	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/aa"
+	"github.com/protocol-bank/payout-engine/internal/activity"
+	"github.com/protocol-bank/payout-engine/internal/allowlist"
+	"github.com/protocol-bank/payout-engine/internal/approval"
+	"github.com/protocol-bank/payout-engine/internal/cctp"
 	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/eventschema"
+	"github.com/protocol-bank/payout-engine/internal/forksim"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
 	"github.com/protocol-bank/payout-engine/internal/nonce"
+	"github.com/protocol-bank/payout-engine/internal/objectstore"
+	"github.com/protocol-bank/payout-engine/internal/policy"
+	"github.com/protocol-bank/payout-engine/internal/privatetx"
 	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/screening"
+	"github.com/protocol-bank/payout-engine/internal/settlement"
+	"github.com/protocol-bank/payout-engine/internal/swap"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
+	"github.com/protocol-bank/payout-engine/internal/webhook"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 )
 
-// ERC20 ABI (只需要 transfer 函数)
-const erc20ABI = `[{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+// ERC20 ABI (transfer + 白名单校验用的 symbol/decimals + 预检用的 balanceOf)
+const erc20ABI = `[{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
 
 // PayoutService 支付服务
 type PayoutService struct {
 	cfg          *config.Config
 	nonceManager *nonce.Manager
 	queue        *queue.Consumer
-	clients      map[uint64]*ethclient.Client
-	tronClients  map[uint64]*tronclient.GrpcClient
+	signer       kms.Signer            // 默认签名器 (批次清单、未单独配置的链)
+	chainSigners map[uint64]kms.Signer // 按链配置的签名器
+	tronSigner   kms.Signer            // TRON 付款签名器 (未配置时 TRON 任务失败)
 	erc20ABI     abi.ABI
+	wrappedABI   abi.ABI // WETH / WMATIC withdraw
+	permitABI    abi.ABI // EIP-2612 permit / transferFrom (代付)
+	eip3009ABI   abi.ABI // EIP-3009 transferWithAuthorization (x402 中继)
+	forwarderABI abi.ABI // ERC-2771 转发合约 (x402 中继)
+	treasuryABI  abi.ABI // 金库授权请求、兑换前授权的 approve 调用
+
+	// 链客户端 (cfg.Chains 与下列 map 由 chainMu 保护，重新加载时整体替换)
+	chainMu     sync.RWMutex
+	reloadMu    sync.Mutex
+	clients     map[uint64]*rpcpool.Pool
+	tronClients map[uint64]*tronclient.GrpcClient
+	tronHealth  map[uint64]*nodeHealth // TRON 节点健康检查结果
+	aaClients   map[uint64]*aa.Client  // ERC-4337 bundler clients (optional per chain)
+	feeOracles  map[uint64]*gas.Oracle
+
+	allowlist      *allowlist.Allowlist // 租户代币白名单 (未配置时不限制)
+	policies       *policy.Set          // 付款地址支出策略 (未配置时不限制)
+	screener       screening.Screener   // 收款地址筛查 (未配置时不筛查)
+	verifiedTokens sync.Map             // "chainID:token" -> 已通过链上校验
+
+	faucetMu        sync.Mutex
+	faucetRequested map[uint64]time.Time // 测试网水龙头最近请求时间
+
+	gasTankSigner     kms.Signer // 运营地址 gas 补充的 EVM 资金钱包 (未配置时不补充 EVM 链)
+	tronGasTankSigner kms.Signer // TRON 资金钱包 (未配置时不补充 TRON 链)
+
+	signerHealthMu sync.RWMutex
+	signerHealth   []SignerHealth // 最近一次签名器健康检查结果
+
+	settlementDests  *settlement.Destinations // 每日结算汇总投递目标 (未配置时不发送)
+	settlementSender *settlement.Sender
+
+	webhookSender *webhook.Sender       // 批次状态回调
+	eventSchemas  *eventschema.Registry // 事件 payload 发出前按 schema 校验
+
+	ledger *ledger.Store // Postgres 任务账本 (未配置数据库时为 nil)
+
+	allowlistThreshold *big.Rat // 批次某代币合计达到该金额 (整币) 时收款地址须在白名单中
+
+	privateMu      sync.Mutex
+	privateSenders map[string]*privatetx.Sender // 私有交易池客户端 (按方法和地址)
+
+	tronBatches tronBatches // TRON 批次结果汇总 (GET /tron/batches)
+
+	simulator        forksim.Simulator // 签名前分叉模拟 (未配置时不模拟)
+	forkSimThreshold *big.Rat          // 单笔金额 (整币) 达到该值时模拟，nil 时模拟所有任务
+
+	activityThreshold *big.Rat           // 单笔金额 (整币) 达到该值时检查收款地址活跃度，nil 时检查所有任务
+	explorer          *activity.Explorer // EVM 收款地址首次/最近交易时间 (未配置的链只识别全新地址)
+
+	approval *approval.Policy // 大额批次审批 (未配置时不审批)
+
+	swapper swap.Aggregator // 转账前兑换的 DEX 聚合器 (未配置时不支持兑换批次)
+
+	attestor *cctp.Client // 跨链路由查询 CCTP 证明
+
+	reportStore *objectstore.Store // 批次报告上传 (未配置时不上传)
 }
 
 // NewPayoutService 创建支付服务
@@ -44,35 +127,172 @@ func NewPayoutService(
 	cfg *config.Config,
 	nonceManager *nonce.Manager,
 	queueConsumer *queue.Consumer,
+	signer kms.Signer,
+	chainSigners map[uint64]kms.Signer,
+	jobLedger *ledger.Store,
 ) (*PayoutService, error) {
 	// 解析 ERC20 ABI
 	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ERC20 ABI: %w", err)
 	}
+	wrappedABI, err := abi.JSON(strings.NewReader(wrappedNativeABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wrapped native ABI: %w", err)
+	}
+	permitABI, err := abi.JSON(strings.NewReader(permitABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse permit ABI: %w", err)
+	}
+	eip3009ABI, err := abi.JSON(strings.NewReader(authorizationABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EIP-3009 ABI: %w", err)
+	}
+	forwarderABI, err := abi.JSON(strings.NewReader(forwarderABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse forwarder ABI: %w", err)
+	}
+	treasuryABI, err := abi.JSON(strings.NewReader(treasuryApproveABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse treasury ABI: %w", err)
+	}
 
-	// 初始化链客户端
-	clients := make(map[uint64]*ethclient.Client)
-	tronClients := make(map[uint64]*tronclient.GrpcClient)
+	tokenAllowlist, err := allowlist.Load(cfg.TokenAllowlistFile)
+	if err != nil {
+		return nil, err
+	}
+	if tokenAllowlist.Enabled() {
+		log.Info().Str("file", cfg.TokenAllowlistFile).Msg("Token allowlist enabled")
+	}
 
-	for chainID, chainCfg := range cfg.Chains {
-		if chainCfg.Type == "tron" {
-			client := tronclient.NewGrpcClient(chainCfg.RPCURL)
-			if err := client.Start(); err != nil {
-				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to connect to Tron chain")
-				continue
+	spendingPolicies, err := policy.Load(cfg.SpendingPolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	if spendingPolicies.Enabled() {
+		log.Info().Str("file", cfg.SpendingPolicyFile).Msg("Spending policies enabled")
+	}
+
+	screener, err := screening.NewScreener(cfg.Screening)
+	if err != nil {
+		return nil, err
+	}
+	if screener != nil {
+		log.Info().Str("provider", screener.Provider()).Msg("Recipient screening enabled")
+	}
+
+	var allowlistThreshold *big.Rat
+	if cfg.RecipientAllowlistThreshold != "" {
+		threshold, ok := new(big.Rat).SetString(cfg.RecipientAllowlistThreshold)
+		if !ok || threshold.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid RECIPIENT_ALLOWLIST_THRESHOLD: %s", cfg.RecipientAllowlistThreshold)
+		}
+		if jobLedger == nil {
+			return nil, fmt.Errorf("RECIPIENT_ALLOWLIST_THRESHOLD requires DATABASE_URL (address lists are stored in the job ledger)")
+		}
+		allowlistThreshold = threshold
+	}
+
+	simulator, err := forksim.NewSimulator(cfg.ForkSimulation)
+	if err != nil {
+		return nil, err
+	}
+	var forkSimThreshold *big.Rat
+	if simulator != nil {
+		if cfg.ForkSimulation.Threshold != "" {
+			threshold, ok := new(big.Rat).SetString(cfg.ForkSimulation.Threshold)
+			if !ok || threshold.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid FORK_SIM_THRESHOLD: %s", cfg.ForkSimulation.Threshold)
 			}
-			tronClients[chainID] = client
-			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("Connected to Tron chain")
-		} else {
-			client, err := ethclient.Dial(chainCfg.RPCURL)
-			if err != nil {
-				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to connect to chain")
-				continue
+			forkSimThreshold = threshold
+		}
+		log.Info().Str("provider", simulator.Provider()).Str("threshold", cfg.ForkSimulation.Threshold).Msg("Fork simulation enabled")
+	}
+
+	var activityThreshold *big.Rat
+	if cfg.RecipientActivity.Enabled {
+		if cfg.RecipientActivity.Threshold != "" {
+			threshold, ok := new(big.Rat).SetString(cfg.RecipientActivity.Threshold)
+			if !ok || threshold.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid RECIPIENT_ACTIVITY_THRESHOLD: %s", cfg.RecipientActivity.Threshold)
 			}
-			clients[chainID] = client
-			nonceManager.AddChainClient(chainID, client)
-			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("Connected to chain")
+			activityThreshold = threshold
+		}
+		log.Info().
+			Str("threshold", cfg.RecipientActivity.Threshold).
+			Dur("min_age", cfg.RecipientActivity.MinAge).
+			Dur("dormant_after", cfg.RecipientActivity.DormantAfter).
+			Msg("Recipient activity check enabled")
+	}
+
+	approvalPolicy, err := approval.New(cfg.Approval)
+	if err != nil {
+		return nil, err
+	}
+	if approvalPolicy != nil {
+		log.Info().
+			Str("threshold_usd", cfg.Approval.ThresholdUSD).
+			Int("quorum", approvalPolicy.Quorum()).
+			Int("operators", len(cfg.Approval.Operators)).
+			Msg("Batch approval enabled")
+	}
+
+	swapper, err := swap.New(cfg.Swap)
+	if err != nil {
+		return nil, err
+	}
+	if swapper != nil {
+		if cfg.Swap.MaxSlippageBps <= 0 || cfg.Swap.MaxSlippageBps >= 10000 {
+			return nil, fmt.Errorf("invalid SWAP_MAX_SLIPPAGE_BPS: %d", cfg.Swap.MaxSlippageBps)
+		}
+		log.Info().Str("provider", swapper.Provider()).Int("max_slippage_bps", cfg.Swap.MaxSlippageBps).Msg("Pre-payout swaps enabled")
+	}
+
+	attestationURL := cfg.CCTPAttestationURL
+	if attestationURL == "" {
+		attestationURL = cctp.MainnetAttestationURL
+		if cfg.IsTestnet() {
+			attestationURL = cctp.SandboxAttestationURL
+		}
+	}
+
+	eventSchemas, err := eventschema.NewRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event schemas: %w", err)
+	}
+
+	settlementDests, err := settlement.Load(cfg.Settlement.DestinationsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var reportStore *objectstore.Store
+	if cfg.Reports.Store.Enabled() {
+		if reportStore, err = objectstore.New(cfg.Reports.Store); err != nil {
+			return nil, fmt.Errorf("invalid report store: %w", err)
+		}
+		log.Info().Str("provider", cfg.Reports.Store.Provider).Str("bucket", cfg.Reports.Store.Bucket).Msg("Batch report uploads enabled")
+	}
+
+	// 初始化链客户端
+	chains := newChainMaps()
+	for chainID, chainCfg := range cfg.Chains {
+		conn, err := dialChain(ctx, chainID, chainCfg, cfg.RPC)
+		if rpcpool.IsChainIDMismatch(err) {
+			// 节点地址配置错误，继续运行可能向错误的网络签名
+			if cfg.ChainIDMismatch != config.ChainIDMismatchDisable {
+				return nil, fmt.Errorf("chain %d (%s): %w", chainID, chainCfg.Name, err)
+			}
+			log.Error().Err(err).Uint64("chain_id", chainID).Msg("RPC chain id mismatch, chain disabled")
+			continue
+		}
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to connect to chain")
+			continue
+		}
+		chains.put(chainID, conn)
+		if conn.pool != nil {
+			nonceManager.AddChainClient(chainID, conn.pool)
 		}
 	}
 
@@ -80,29 +300,194 @@ func NewPayoutService(
 		cfg:          cfg,
 		nonceManager: nonceManager,
 		queue:        queueConsumer,
-		clients:      clients,
-		tronClients:  tronClients,
+		signer:       signer,
+		chainSigners: chainSigners,
+		clients:      chains.clients,
+		tronClients:  chains.tronClients,
+		tronHealth:   chains.tronHealth,
+		aaClients:    chains.aaClients,
+		feeOracles:   chains.feeOracles,
 		erc20ABI:     parsedABI,
+		wrappedABI:   wrappedABI,
+		permitABI:    permitABI,
+		eip3009ABI:   eip3009ABI,
+		forwarderABI: forwarderABI,
+		treasuryABI:  treasuryABI,
+		allowlist:    tokenAllowlist,
+		policies:     spendingPolicies,
+		screener:     screener,
+
+		faucetRequested: make(map[uint64]time.Time),
+
+		settlementDests:  settlementDests,
+		settlementSender: settlement.NewSender(cfg.Settlement.SMTP),
+
+		webhookSender: webhook.NewSender(10 * time.Second),
+		eventSchemas:  eventSchemas,
+
+		ledger: jobLedger,
+
+		allowlistThreshold: allowlistThreshold,
+		simulator:          simulator,
+		forkSimThreshold:   forkSimThreshold,
+
+		activityThreshold: activityThreshold,
+		explorer:          activity.NewExplorer(cfg.RecipientActivity.ExplorerURLs, cfg.RecipientActivity.ExplorerKey),
+
+		approval: approvalPolicy,
+
+		swapper: swapper,
+
+		attestor: cctp.NewClient(attestationURL),
+
+		reportStore: reportStore,
 	}, nil
 }
 
-// SubmitBatchPayout 提交批量支付
-func (s *PayoutService) SubmitBatchPayout(ctx context.Context, req *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+// SubmitBatchPayout 提交批量支付。
+// 相同用户的相同幂等键 (默认为 BatchID) 重复提交时返回首次的响应，不会重复入队。
+func (s *PayoutService) SubmitBatchPayout(ctx context.Context, req *BatchPayoutRequest) (resp *BatchPayoutResponse, err error) {
+	ctx, span := tracing.Start(ctx, "payout.submit_batch", trace.WithAttributes(
+		tracing.AttrBatchID.String(req.BatchID),
+		tracing.AttrUserID.String(req.UserID),
+		tracing.AttrChainID.Int64(int64(req.ChainID)),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	log.Info().
 		Str("batch_id", req.BatchID).
+		Str(correlation.LogField, correlation.FromContext(ctx)).
 		Int("items", len(req.Items)).
 		Uint64("chain_id", req.ChainID).
 		Msg("Submitting batch payout")
 
 	// 验证请求
-	if err := s.validateRequest(req); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	if err := s.validateRequest(ctx, req); err != nil {
+		if IsUnavailable(err) {
+			return nil, err
+		}
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("validation failed: %w", err)}
 	}
 
-	// 创建任务
-	jobs := make([]*queue.Job, len(req.Items))
+	// 重复支付项入队前标记剔除或合并
+	req, duplicates := resolveDuplicates(req)
+
+	// 试运行: 不签名、不入队
+	if req.Simulate {
+		resp, err := s.simulateBatch(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Duplicates = duplicates
+		return resp, nil
+	}
+
+	key := req.IdempotencyKey
+	if key == "" {
+		key = req.BatchID
+	}
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.queue.ClaimIdempotencyKey(ctx, req.UserID, key, requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if record == nil {
+		// Redis 中的记录可能已被淘汰，再查账本
+		if record, err = s.storedIdempotencyRecord(ctx, req.UserID, key); err != nil {
+			if relErr := s.queue.ReleaseIdempotencyKey(ctx, req.UserID, key); relErr != nil {
+				log.Error().Err(relErr).Str("batch_id", req.BatchID).Msg("Failed to release idempotency key")
+			}
+			return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+	}
+	if record != nil {
+		return replayResponse(req, key, requestHash, record)
+	}
+
+	stopClaim := s.queue.KeepIdempotencyClaim(ctx, req.UserID, key, requestHash)
+	switch {
+	case s.requiresApproval(req):
+		resp, err = s.holdForApproval(ctx, req, requestHash)
+	case deferred(req):
+		resp, err = s.scheduleBatch(ctx, req)
+	default:
+		resp, err = s.submitBatch(ctx, req)
+	}
+	stopClaim()
+	if err != nil {
+		// 未入队，释放幂等键以便客户端修正后重试
+		if relErr := s.queue.ReleaseIdempotencyKey(ctx, req.UserID, key); relErr != nil {
+			log.Error().Err(relErr).Str("batch_id", req.BatchID).Msg("Failed to release idempotency key")
+		}
+		return nil, err
+	}
+	resp.Duplicates = duplicates
+	if err := s.queue.CompleteIdempotencyKey(ctx, req.UserID, key, requestHash, resp, queue.DefaultIdempotencyTTL); err != nil {
+		// 任务已入队，仅记录错误；占位记录仍会阻止短时间内的重复提交
+		log.Error().Err(err).Str("batch_id", req.BatchID).Msg("Failed to store idempotent response")
+	}
+	s.saveIdempotentResponse(ctx, req.UserID, key, requestHash, resp)
+	s.mirrorToShadow(ctx, req, resp)
+	return resp, nil
+}
+
+// batchAmounts 每笔实际转出金额。收款方承担手续费时预先计算每笔扣费 (fees 非 nil)。
+func (s *PayoutService) batchAmounts(ctx context.Context, req *BatchPayoutRequest) ([]ItemFee, []*big.Int, error) {
+	var fees []ItemFee
+	if req.FeeMode == FeeModeRecipient {
+		var err error
+		fees, err = s.applyRecipientFees(ctx, req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fee calculation failed: %w", err)
+		}
+	}
+
+	amounts := make([]*big.Int, len(req.Items))
 	for i, item := range req.Items {
-		jobs[i] = &queue.Job{
+		amountStr := item.Amount
+		if fees != nil {
+			amountStr = fees[i].NetAmount
+		}
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, nil, &InvalidArgumentError{Err: fmt.Errorf("validation failed: item[%d]: invalid amount: %s", i, item.Amount)}
+		}
+		amounts[i] = amount
+	}
+	return fees, amounts, nil
+}
+
+// submitBatch 计算费用、预检余额并入队
+func (s *PayoutService) submitBatch(ctx context.Context, req *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	priority, _ := gas.ParsePriority(req.Priority)
+
+	fees, amounts, err := s.batchAmounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预检付款地址余额 (转出金额 + 预留网络费)
+	accepted, rejected, err := s.preflightBatch(ctx, req, amounts)
+	if err != nil {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("preflight check failed: %w", err)}
+	}
+	if len(rejected) > 0 {
+		log.Warn().
+			Str("batch_id", req.BatchID).
+			Int("accepted", len(accepted)).
+			Int("rejected", len(rejected)).
+			Msg("Batch partially accepted due to insufficient balance")
+	}
+
+	// 创建任务
+	jobs := make([]*queue.Job, len(accepted))
+	var acceptedFees []ItemFee
+	for j, i := range accepted {
+		item := req.Items[i]
+		jobs[j] = &queue.Job{
 			ID:            item.ID,
 			BatchID:       req.BatchID,
 			UserID:        req.UserID,
@@ -112,10 +497,50 @@ func (s *PayoutService) SubmitBatchPayout(ctx context.Context, req *BatchPayoutR
 			TokenAddress:  item.TokenAddress,
 			TokenSymbol:   item.TokenSymbol,
 			TokenDecimals: item.TokenDecimals,
+			TokenID:       item.TokenID,
+			MaxFee:        item.MaxFee,
 			ChainID:       req.ChainID,
+			SmartAccount:  req.UseSmartAccount,
+			Permit:        req.Permit,
+			Treasury:      s.batchTreasury(req),
+			Swap:          s.batchSwap(req),
+			Route:         s.batchRoute(req),
+			Priority:      string(priority),
+			Testnet:       s.isTestnetChain(req.ChainID),
 			RetryCount:    0,
 			CreatedAt:     time.Now(),
 		}
+		if req.ScreeningOverride {
+			jobs[j].ScreeningOverride = req.ScreeningOverrideReason
+		}
+		if fees != nil {
+			jobs[j].Amount = fees[i].NetAmount
+			jobs[j].FeeMode = string(FeeModeRecipient)
+			jobs[j].GrossAmount = fees[i].GrossAmount
+			jobs[j].NetworkFee = fees[i].NetworkFee
+			jobs[j].ServiceFee = fees[i].ServiceFee
+			acceptedFees = append(acceptedFees, fees[i])
+		}
+	}
+
+	// 任务清单: 提交方可据此核对实际执行的批次
+	manifest, err := s.signManifest(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.queue.SaveManifest(ctx, manifest); err != nil {
+		return nil, fmt.Errorf("failed to store manifest: %w", err)
+	}
+
+	// 状态回调须在入队前注册，避免错过早期事件
+	if req.WebhookURL != "" {
+		target := queue.WebhookTarget{URL: req.WebhookURL, Secret: req.WebhookSecret, SchemaVersion: req.WebhookSchemaVersion}
+		if target.SchemaVersion == 0 {
+			target.SchemaVersion = s.cfg.WebhookSchemaVersion
+		}
+		if err := s.queue.RegisterWebhook(ctx, req.UserID, req.BatchID, target); err != nil {
+			return nil, fmt.Errorf("failed to register webhook: %w", err)
+		}
 	}
 
 	// 批量入队
@@ -123,28 +548,112 @@ func (s *PayoutService) SubmitBatchPayout(ctx context.Context, req *BatchPayoutR
 		return nil, fmt.Errorf("failed to queue jobs: %w", err)
 	}
 
+	message := fmt.Sprintf("Queued %d payments for processing", len(jobs))
+	if len(rejected) > 0 {
+		message = fmt.Sprintf("Queued %d of %d payments for processing; %d rejected for insufficient balance", len(jobs), len(req.Items), len(rejected))
+	}
+	testnet := s.isTestnetChain(req.ChainID)
+	if testnet {
+		message = "[TESTNET] " + message
+	}
+
 	return &BatchPayoutResponse{
-		BatchID: req.BatchID,
-		Status:  BatchStatusQueued,
-		Message: fmt.Sprintf("Queued %d payments for processing", len(jobs)),
+		BatchID:           req.BatchID,
+		Status:            BatchStatusQueued,
+		Message:           message,
+		Fees:              acceptedFees,
+		Rejected:          rejected,
+		Testnet:           testnet,
+		ManifestHash:      manifest.Hash,
+		ManifestSignature: manifest.Signature,
+		ManifestSigner:    manifest.Signer,
 	}, nil
 }
 
-// ProcessJob 处理单个支付任务
+// ProcessJob 处理单个支付任务，失败时按链上错误类别标注结果 (见 queue.ChainError)
 func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.JobResult, error) {
+	result, err := s.processJob(ctx, job)
+	if err == nil && result != nil && !result.Success && result.ChainError == nil {
+		result.ChainError = chainErrorOf(result.Error)
+	}
+	metrics.JobsProcessed.Inc(metrics.Label(job.ChainID), jobOutcome(result, err))
+	return result, err
+}
+
+func (s *PayoutService) processJob(ctx context.Context, job *queue.Job) (*queue.JobResult, error) {
 	log.Info().
 		Str("job_id", job.ID).
+		Str(correlation.LogField, job.CorrelationID).
 		Str("to", job.ToAddress).
 		Str("amount", job.Amount).
+		Bool("testnet", job.Testnet).
 		Msg("Processing payout job")
 
+	// 归集任务的收款方是运维配置的冷钱包，不做租户白名单、收款地址筛查和支出策略检查
+	if job.Sweep {
+		return s.sendJob(ctx, job)
+	}
+
+	// 链上核对白名单代币合约
+	if err := s.verifyToken(ctx, job); err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   queue.Permanent(fmt.Errorf("token verification failed: %w", err)),
+		}, nil
+	}
+
+	// 收款地址制裁/风险筛查
+	if err := s.screenRecipient(ctx, job); err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	// x402 中继转出的是授权方的资金，付款地址只支付 Gas，不做活跃度和支出策略检查
+	if job.Authorization != nil || job.Forward != nil {
+		return s.sendJob(ctx, job)
+	}
+
+	// 大额支付的收款地址活跃度 (新地址/休眠地址须人工确认)
+	if err := s.checkRecipientActivity(ctx, job); err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	// 支出策略 (签名前检查并预留额度)
+	release, err := s.checkSpendingPolicy(ctx, job)
+	if err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+	result, err := s.sendJob(ctx, job)
+	if release != nil && (err != nil || (!result.Success && result.TxHash == "")) {
+		release()
+	}
+	return result, err
+}
+
+// sendJob 按链类型构建、签名并广播交易
+func (s *PayoutService) sendJob(ctx context.Context, job *queue.Job) (*queue.JobResult, error) {
 	// Check if this is a Tron chain
-	if tronClient, ok := s.tronClients[job.ChainID]; ok {
-		return s.processTronJob(ctx, tronClient, job)
+	if tronClient, ok := s.tronClient(job.ChainID); ok {
+		done := s.tronBatches.begin(job)
+		result, err := s.processTronJob(ctx, tronClient, job)
+		done(result, err)
+		return result, err
 	}
 
 	// 获取链客户端
-	client, ok := s.clients[job.ChainID]
+	client, ok := s.evmClient(job.ChainID)
 	if !ok {
 		return &queue.JobResult{
 			JobID:   job.ID,
@@ -153,28 +662,159 @@ func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.
 		}, nil
 	}
 
-	// 获取 Nonce
+	// 基础费超过链的上限: 不发送
+	if err := s.checkGasCeiling(ctx, job); err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	// ERC-4337 智能账户支付 (EntryPoint 管理 nonce)
+	if job.SmartAccount {
+		aaClient, ok := s.aaClient(job.ChainID)
+		if !ok {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   fmt.Errorf("smart-account payouts not enabled on chain %d", job.ChainID),
+			}, nil
+		}
+		return s.processUserOpJob(ctx, client, aaClient, job)
+	}
+
+	// 跨链路由: 源链销毁并取得跨链证明前不发送 (见 advanceRoute)
+	var route *queue.RouteStatus
+	if job.Route != nil {
+		var routeErr error
+		route, routeErr = s.advanceRoute(ctx, job)
+		if routeErr != nil {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   routeErr,
+			}, nil
+		}
+	}
+
+	// 密钥轮换: 旧地址的任务改由当前地址发送
+	s.rotateJobSender(job)
+
+	// 金库出款: 授权额度不足时等待金库补充授权 (见 RequestTreasuryApproval)
+	if job.Treasury != "" {
+		if err := s.checkTreasuryAllowance(ctx, client, job); err != nil {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   err,
+			}, nil
+		}
+	}
+
+	// 获取 Nonce (付款地址在途交易达到上限时稍后重试)
 	fromAddr := common.HexToAddress(job.FromAddress)
-	nonceVal, releaseFn, err := s.nonceManager.GetNonce(ctx, job.ChainID, fromAddr)
+	nonceVal, releaseFn, err := s.nonceManager.GetNonceLimited(ctx, job.ChainID, fromAddr, s.maxInFlight(job.ChainID))
 	if err != nil {
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
-			Error:   fmt.Errorf("failed to get nonce: %w", err),
+			Error:   fmt.Errorf("failed to get nonce: %w", inFlightError(err)),
 		}, nil
 	}
 	defer releaseFn()
 
 	// 构建交易
-	var tx *types.Transaction
-	if job.TokenAddress == "" || job.TokenAddress == "0x0000000000000000000000000000000000000000" {
+	var tx, unwrapTx, permitTx *types.Transaction
+	var swapTxs int
+	var minted bool
+	if isNativeToken(job.TokenAddress) {
+		// 原生代币不足时先解包 WETH / WMATIC，转账使用下一个 nonce
+		var unwrapErr error
+		unwrapTx, unwrapErr = s.unwrapForNative(ctx, job, nonceVal)
+		if unwrapErr != nil {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   fmt.Errorf("failed to unwrap native token: %w", s.classifySendError(ctx, job.ChainID, fromAddr, unwrapErr)),
+			}, nil
+		}
+		if unwrapTx != nil {
+			s.nonceManager.Advance(ctx, job.ChainID, fromAddr)
+			nonceVal++
+		}
 		// 原生代币转账
 		tx, err = s.buildNativeTransfer(ctx, client, job, nonceVal)
 	} else {
+		// 代付: 授权额度不足时先提交源钱包签名的 permit，transferFrom 使用下一个 nonce
+		if job.Permit != nil {
+			var permitErr error
+			permitTx, permitErr = s.submitPermit(ctx, client, job, nonceVal)
+			if permitErr != nil {
+				return &queue.JobResult{
+					JobID:   job.ID,
+					Success: false,
+					Error:   fmt.Errorf("failed to submit permit: %w", s.classifySendError(ctx, job.ChainID, fromAddr, permitErr)),
+				}, nil
+			}
+			if permitTx != nil {
+				s.nonceManager.Advance(ctx, job.ChainID, fromAddr)
+				nonceVal++
+			}
+		}
+		// 转账前兑换: 先广播 approve 和兑换交易，转账使用其后的 nonce
+		if job.Swap != nil {
+			var swapErr error
+			swapTxs, swapErr = s.swapForPayout(ctx, client, job, nonceVal)
+			if swapErr != nil {
+				return &queue.JobResult{
+					JobID:   job.ID,
+					Success: false,
+					Error:   fmt.Errorf("failed to swap for payout: %w", s.classifySendError(ctx, job.ChainID, fromAddr, swapErr)),
+				}, nil
+			}
+			for i := 0; i < swapTxs; i++ {
+				s.nonceManager.Advance(ctx, job.ChainID, fromAddr)
+				nonceVal++
+			}
+		}
+		// 跨链路由: 先在目标链铸造 USDC，转账使用下一个 nonce
+		if route != nil {
+			var mintErr error
+			minted, mintErr = s.mintForRoute(ctx, client, job, route, nonceVal)
+			if mintErr != nil {
+				return &queue.JobResult{
+					JobID:   job.ID,
+					Success: false,
+					Error:   fmt.Errorf("failed to mint bridged USDC: %w", s.classifySendError(ctx, job.ChainID, fromAddr, mintErr)),
+				}, nil
+			}
+			if minted {
+				s.nonceManager.Advance(ctx, job.ChainID, fromAddr)
+				nonceVal++
+			}
+		}
+		// x402 中继: 授权已在链上使用或已过期时不再发送
+		var relayErr error
+		switch {
+		case job.Authorization != nil:
+			relayErr = s.checkAuthorizationExecutable(ctx, client, job)
+		case job.Forward != nil:
+			relayErr = s.checkForwardExecutable(ctx, client, job)
+		}
+		if relayErr != nil {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   relayErr,
+			}, nil
+		}
 		// ERC20 转账
 		tx, err = s.buildERC20Transfer(ctx, client, job, nonceVal)
 	}
 	if err != nil {
+		// 未发送 (如超过网络费上限)，归还预占的 nonce
+		s.nonceManager.ResetNonce(ctx, job.ChainID, fromAddr)
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
@@ -182,40 +822,61 @@ func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.
 		}, nil
 	}
 
-	// 签名交易 (这里需要从安全存储获取私钥)
-	// 注意：生产环境应使用 HSM 或 KMS
-	signedTx, err := s.signTransaction(ctx, tx, job.ChainID)
-	if err != nil {
-		// Nonce 错误时重置
-		if strings.Contains(err.Error(), "nonce") {
+	// 大额交易签名前在分叉上模拟 (解包、授权、兑换或铸造交易尚未上链时分叉状态余额或额度不足，跳过)
+	if unwrapTx == nil && permitTx == nil && swapTxs == 0 && !minted {
+		if err := s.forkSimulate(ctx, job, tx); err != nil {
 			s.nonceManager.ResetNonce(ctx, job.ChainID, fromAddr)
+			result := &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				Error:   err,
+			}
+			var reverted *forksim.RevertedError
+			if errors.As(err, &reverted) {
+				result.RevertReason = reverted.Result.RevertReason
+			}
+			return result, nil
 		}
+	}
+
+	// 按链的交易格式签名
+	format := s.chainConfig(job.ChainID).TxFormat
+	signedTx, err := s.signChainTx(ctx, format, tx, job.ChainID, jobSigningOp(job, signPurposePayout))
+	if err != nil {
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
-			Error:   fmt.Errorf("failed to sign transaction: %w", err),
+			Error:   fmt.Errorf("failed to sign transaction: %w", s.classifySendError(ctx, job.ChainID, fromAddr, err)),
 		}, nil
 	}
 
-	// 发送交易
-	if err := client.SendTransaction(ctx, signedTx); err != nil {
-		// Nonce 错误时重置
-		if strings.Contains(err.Error(), "nonce") {
-			s.nonceManager.ResetNonce(ctx, job.ChainID, fromAddr)
-		}
+	// 发送交易 (大额 ERC20 支付先经私有交易池)
+	privateUntil, err := s.broadcastJobTx(ctx, client, job, signedTx)
+	if err != nil {
+		// 按节点返回的错误分类重试 (nonce 不一致时重置缓存)
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
-			Error:   fmt.Errorf("failed to send transaction: %w", err),
+			Error:   fmt.Errorf("failed to send transaction: %w", s.classifySendError(ctx, job.ChainID, fromAddr, err)),
 		}, nil
 	}
 
-	txHash := signedTx.Hash().Hex()
+	txHash := signedTx.hash.Hex()
 	log.Info().
 		Str("job_id", job.ID).
+		Str(correlation.LogField, job.CorrelationID).
 		Str("tx_hash", txHash).
+		Bool("testnet", job.Testnet).
+		Bool("private", !privateUntil.IsZero()).
 		Msg("Transaction sent successfully")
 
+	// 记录待确认交易，供卡单检测使用
+	pending := &queue.PendingTx{MaxFee: job.MaxFee, TxFormat: format}
+	if !privateUntil.IsZero() {
+		pending.PrivateUntil = &privateUntil
+	}
+	s.trackTx(ctx, job, signedTx, pending)
+
 	return &queue.JobResult{
 		JobID:   job.ID,
 		Success: true,
@@ -226,7 +887,7 @@ func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.
 // buildNativeTransfer 构建原生代币转账交易
 func (s *PayoutService) buildNativeTransfer(
 	ctx context.Context,
-	client *ethclient.Client,
+	client *rpcpool.Pool,
 	job *queue.Job,
 	nonceVal uint64,
 ) (*types.Transaction, error) {
@@ -236,16 +897,12 @@ func (s *PayoutService) buildNativeTransfer(
 		return nil, fmt.Errorf("invalid amount: %s", job.Amount)
 	}
 
-	// 获取 Gas 价格
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	// 按优先级获取 EIP-1559 费用
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+		return nil, err
 	}
 
-	// 增加 20% Gas 价格以加快确认
-	gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(120))
-	gasPrice = new(big.Int).Div(gasPrice, big.NewInt(100))
-
 	// 估算 Gas
 	msg := ethereum.CallMsg{
 		From:  common.HexToAddress(job.FromAddress),
@@ -257,27 +914,27 @@ func (s *PayoutService) buildNativeTransfer(
 		gasLimit = 21000 // 默认原生转账 Gas
 	}
 
-	// 增加 20% Gas Limit
-	gasLimit = gasLimit * 120 / 100
+	// 按优先级增加 Gas Limit 缓冲
+	gasLimit = calculateGasBuffer(gasLimit, job.Priority)
 
 	chainID := new(big.Int).SetUint64(job.ChainID)
 	tx := types.NewTx(&types.DynamicFeeTx{
 		ChainID:   chainID,
 		Nonce:     nonceVal,
-		GasTipCap: gasPrice,
-		GasFeeCap: new(big.Int).Mul(gasPrice, big.NewInt(2)),
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
 		Gas:       gasLimit,
 		To:        &toAddr,
 		Value:     value,
 	})
 
-	return tx, nil
+	return s.applyFeeCap(ctx, client, job, tx, fees.BaseFee)
 }
 
 // buildERC20Transfer 构建 ERC20 转账交易
 func (s *PayoutService) buildERC20Transfer(
 	ctx context.Context,
-	client *ethclient.Client,
+	client *rpcpool.Pool,
 	job *queue.Job,
 	nonceVal uint64,
 ) (*types.Transaction, error) {
@@ -288,22 +945,33 @@ func (s *PayoutService) buildERC20Transfer(
 		return nil, fmt.Errorf("invalid amount: %s", job.Amount)
 	}
 
-	// 编码 transfer 调用数据
-	data, err := s.erc20ABI.Pack("transfer", toAddr, amount)
+	// 编码 transfer 调用数据 (代付和金库出款为从源地址 transferFrom，x402 中继为 transferWithAuthorization)
+	var data []byte
+	var err error
+	switch {
+	case job.Permit != nil:
+		data, err = s.permitABI.Pack("transferFrom", common.HexToAddress(job.Permit.Owner), toAddr, amount)
+	case job.Treasury != "":
+		data, err = s.permitABI.Pack("transferFrom", common.HexToAddress(job.Treasury), toAddr, amount)
+	case job.Authorization != nil:
+		data, err = s.packAuthorization(job)
+	case job.Forward != nil:
+		// 交易发往转发合约，由其调用代币
+		data, err = s.packForward(job)
+		tokenAddr = common.HexToAddress(job.Forward.Forwarder)
+	default:
+		data, err = s.erc20ABI.Pack("transfer", toAddr, amount)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack transfer data: %w", err)
 	}
 
-	// 获取 Gas 价格
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	// 按优先级获取 EIP-1559 费用
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+		return nil, err
 	}
 
-	// 增加 20% Gas 价格
-	gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(120))
-	gasPrice = new(big.Int).Div(gasPrice, big.NewInt(100))
-
 	// 估算 Gas
 	msg := ethereum.CallMsg{
 		From: common.HexToAddress(job.FromAddress),
@@ -315,56 +983,59 @@ func (s *PayoutService) buildERC20Transfer(
 		gasLimit = 100000 // 默认 ERC20 转账 Gas
 	}
 
-	// 增加 20% Gas Limit
-	gasLimit = gasLimit * 120 / 100
+	// 按优先级增加 Gas Limit 缓冲
+	gasLimit = calculateGasBuffer(gasLimit, job.Priority)
 
 	chainID := new(big.Int).SetUint64(job.ChainID)
 	tx := types.NewTx(&types.DynamicFeeTx{
 		ChainID:   chainID,
 		Nonce:     nonceVal,
-		GasTipCap: gasPrice,
-		GasFeeCap: new(big.Int).Mul(gasPrice, big.NewInt(2)),
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
 		Gas:       gasLimit,
 		To:        &tokenAddr,
 		Value:     big.NewInt(0),
 		Data:      data,
 	})
 
-	return tx, nil
+	return s.applyFeeCap(ctx, client, job, tx, fees.BaseFee)
 }
 
-// signTransaction 签名交易
-// 注意：生产环境应使用 HSM/KMS，这里只是示例
-func (s *PayoutService) signTransaction(ctx context.Context, tx *types.Transaction, chainID uint64) (*types.Transaction, error) {
-	// Debt Fixed: Loaded from Config (formerly TODO)
-	// Note: For High-Value Production, recommend switching to AWS KMS or Fireblocks via an interface here.
-	privateKeyHex := s.cfg.PrivateKey // Now loaded from PAYOUT_PRIVATE_KEY env
-
-	if privateKeyHex == "" {
-		return nil, fmt.Errorf("critical: payment processing private key is missing")
-	}
-
-	// Sanitize hex string
-	if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
-		privateKeyHex = privateKeyHex[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+// signTransaction 签名标准以太坊交易 (见 signChainTx)
+func (s *PayoutService) signTransaction(ctx context.Context, tx *types.Transaction, chainID uint64, op signingOp) (*types.Transaction, error) {
+	signed, err := s.signChainTx(ctx, config.TxFormatEIP1559, tx, chainID, op)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key configuration: %w", err)
+		return nil, err
 	}
+	return signed.tx, nil
+}
 
-	signer := types.LatestSignerForChainID(new(big.Int).SetUint64(chainID))
-	signedTx, err := types.SignTx(tx, signer, privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign: %w", err)
+// signerFor 链的签名器 (未单独配置时使用默认签名器)
+func (s *PayoutService) signerFor(chainID uint64) kms.Signer {
+	if signer, ok := s.chainSigners[chainID]; ok {
+		return signer
 	}
+	return s.signer
+}
 
-	return signedTx, nil
+// broadcastTransaction 通过 RPC 广播已签名交易
+func (s *PayoutService) broadcastTransaction(ctx context.Context, client *rpcpool.Pool, chainID uint64, tx *types.Transaction) error {
+	ctx, span := tracing.Start(ctx, "rpc.broadcast",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tracing.AttrChainID.Int64(int64(chainID)), tracing.AttrTxHash.String(tx.Hash().Hex())),
+	)
+	err := client.SendTransaction(ctx, tx)
+	if rpcpool.IsAlreadyKnown(err) {
+		// 相同交易 (同一哈希) 已在交易池，视为已广播
+		err = nil
+	}
+	recordBroadcastFailure(chainID, err)
+	tracing.End(span, err)
+	return err
 }
 
 // validateRequest 验证请求
-func (s *PayoutService) validateRequest(req *BatchPayoutRequest) error {
+func (s *PayoutService) validateRequest(ctx context.Context, req *BatchPayoutRequest) error {
 	if req.BatchID == "" {
 		return fmt.Errorf("batch_id is required")
 	}
@@ -377,11 +1048,62 @@ func (s *PayoutService) validateRequest(req *BatchPayoutRequest) error {
 	if len(req.Items) == 0 {
 		return fmt.Errorf("at least one item is required")
 	}
-	_, evmOk := s.clients[req.ChainID]
-	_, tronOk := s.tronClients[req.ChainID]
+	if req.WebhookURL != "" {
+		u, err := url.Parse(req.WebhookURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("invalid webhook_url: %s", req.WebhookURL)
+		}
+		if u.Scheme == "http" && s.cfg.Environment != "development" {
+			return fmt.Errorf("webhook_url must use https")
+		}
+		if req.WebhookSecret == "" {
+			return fmt.Errorf("webhook_secret is required with webhook_url")
+		}
+	}
+	if req.WebhookSchemaVersion != 0 && !eventschema.Supported(req.WebhookSchemaVersion) {
+		return fmt.Errorf("unsupported webhook_schema_version: %d (latest is %d)", req.WebhookSchemaVersion, eventschema.Latest)
+	}
+	if req.ScreeningOverride && strings.TrimSpace(req.ScreeningOverrideReason) == "" {
+		return fmt.Errorf("screening_override_reason is required with screening_override")
+	}
+	switch req.FeeMode {
+	case "", FeeModePayer, FeeModeRecipient:
+	default:
+		return fmt.Errorf("invalid fee_mode: %s", req.FeeMode)
+	}
+	switch req.DuplicateMode {
+	case "", DuplicateModeFlag, DuplicateModeMerge:
+	default:
+		return fmt.Errorf("invalid duplicate_mode: %s (expected flag or merge)", req.DuplicateMode)
+	}
+	if _, err := gas.ParsePriority(req.Priority); err != nil {
+		return err
+	}
+	if ahead := s.cfg.ScheduleMaxAhead; ahead > 0 && time.Until(req.ExecuteAt) > ahead {
+		return fmt.Errorf("execute_at must be within %s", ahead)
+	}
+	_, evmOk := s.evmClient(req.ChainID)
+	_, tronOk := s.tronClient(req.ChainID)
 	if !evmOk && !tronOk {
 		return fmt.Errorf("unsupported chain_id: %d", req.ChainID)
 	}
+	if err := s.chainAvailable(req.ChainID); err != nil {
+		return err
+	}
+	if req.UseSmartAccount {
+		aaClient, ok := s.aaClient(req.ChainID)
+		if !ok {
+			return fmt.Errorf("smart-account payouts not enabled on chain_id: %d", req.ChainID)
+		}
+		if common.HexToAddress(req.FromAddress) != aaClient.SmartAccount() {
+			return fmt.Errorf("from_address must be the configured smart account %s", aaClient.SmartAccount().Hex())
+		}
+	} else if signer := s.signerFor(req.ChainID); evmOk && signer != nil {
+		// 轮换过渡期内仍接受旧版本密钥的地址，发送时改为当前地址 (见 rotateJobSender)
+		if !s.isSignerAddress(req.ChainID, common.HexToAddress(req.FromAddress)) {
+			return fmt.Errorf("from_address must be the payout address %s of chain_id %d", signer.Address().Hex(), req.ChainID)
+		}
+	}
 
 	for i, item := range req.Items {
 		if item.RecipientAddress == "" {
@@ -390,6 +1112,20 @@ func (s *PayoutService) validateRequest(req *BatchPayoutRequest) error {
 		if item.Amount == "" {
 			return fmt.Errorf("item[%d]: amount is required", i)
 		}
+		if _, err := parseMaxFee(item.MaxFee); err != nil {
+			return fmt.Errorf("item[%d]: %w", i, err)
+		}
+		if item.MaxFee != "" && req.UseSmartAccount {
+			return fmt.Errorf("item[%d]: max_fee is not supported for smart-account payouts", i)
+		}
+		if item.TokenID != "" {
+			if err := validateTRC10Item(item, tronOk); err != nil {
+				return fmt.Errorf("item[%d]: %w", i, err)
+			}
+		}
+		if err := s.checkAllowlist(req.UserID, req.ChainID, item.asset()); err != nil {
+			return fmt.Errorf("item[%d]: %w", i, err)
+		}
 		// Validate address format based on chain type
 		if tronOk {
 			if !isTronAddress(item.RecipientAddress) {
@@ -402,7 +1138,32 @@ func (s *PayoutService) validateRequest(req *BatchPayoutRequest) error {
 		}
 	}
 
-	return nil
+	if req.Permit != nil {
+		if err := s.validatePermit(ctx, req); err != nil {
+			return err
+		}
+	}
+	if req.UseTreasury {
+		if err := s.validateTreasury(req); err != nil {
+			return err
+		}
+	}
+	if req.Swap != nil {
+		if err := s.validateSwap(req); err != nil {
+			return err
+		}
+	}
+	if req.SourceChainID != 0 {
+		if err := s.validateRoute(req); err != nil {
+			return err
+		}
+	}
+	if err := s.checkTokenDecimals(ctx, req); err != nil {
+		return err
+	}
+
+	// 收款地址名单 (拒绝名单、大额批次白名单)
+	return s.checkRecipientLists(ctx, req)
 }
 
 // isTronAddress validates a TRON Base58Check address format.
@@ -424,15 +1185,28 @@ func isTronAddress(address string) bool {
 	return true
 }
 
-// processTronJob handles TRX native and TRC20 token transfers on the TRON network.
-// Flow: validate → build tx → sign → broadcast → return tx hash.
-func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.GrpcClient, job *queue.Job) (*queue.JobResult, error) {
+// tronJobClient TRON 付款任务所需的节点接口 (*tronclient.GrpcClient)
+type tronJobClient interface {
+	tronResourceClient
+	tronTxInfoClient
+	tronHeadClient
+	Transfer(from, toAddress string, amount int64) (*tronapi.TransactionExtention, error)
+	TransferAsset(from, toAddress, assetName string, amount int64) (*tronapi.TransactionExtention, error)
+	TRC20Send(from, to, contract string, amount *big.Int, feeLimit int64) (*tronapi.TransactionExtention, error)
+	Broadcast(tx *troncore.Transaction) (*tronapi.Return, error)
+}
+
+// processTronJob handles TRX native, TRC10 asset and TRC20 token transfers on the TRON network.
+// Flow: validate → build tx → sign → broadcast → wait for the block → return tx hash and block.
+func (s *PayoutService) processTronJob(ctx context.Context, client tronJobClient, job *queue.Job) (*queue.JobResult, error) {
 	log.Info().
 		Str("job_id", job.ID).
 		Str("to", job.ToAddress).
 		Str("amount", job.Amount).
 		Str("token", job.TokenSymbol).
 		Str("token_address", job.TokenAddress).
+		Str("token_id", job.TokenID).
+		Str(correlation.LogField, job.CorrelationID).
 		Msg("Processing TRON payout job")
 
 	if client == nil {
@@ -443,12 +1217,8 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 		}, nil
 	}
 
-	// Resolve TRON private key (prefer dedicated key, fallback to shared)
-	privateKeyHex := s.cfg.TronPrivateKey
-	if privateKeyHex == "" {
-		privateKeyHex = s.cfg.PrivateKey
-	}
-	if privateKeyHex == "" {
+	// The TRON signer goes through the same rate guard and audit as the EVM signers
+	if s.tronSigner == nil {
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
@@ -465,20 +1235,48 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 			Error:   fmt.Errorf("invalid TRON transfer amount: %s", job.Amount),
 		}, nil
 	}
+	// TRX and TRC10 amounts are int64 on the wire; Int64() would silently truncate larger values
+	if job.TokenAddress == "" && !amount.IsInt64() {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   queue.Permanent(fmt.Errorf("TRON transfer amount %s exceeds the int64 maximum", job.Amount)),
+		}, nil
+	}
+
+	// Jobs from the same account are estimated, built and broadcast in dequeue order when dispatched
+	// concurrently; the turn passes to the next job once this one is broadcast.
+	release, err := queue.AwaitSendTurn(ctx)
+	defer release()
+	if err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   fmt.Errorf("waiting for TRON send turn: %w", err),
+		}, nil
+	}
 
-	// Build transaction: native TRX or TRC20
+	// Estimate energy/bandwidth against the account's resources; fail fast when they can't be paid for
+	feeLimit, err := s.tronFeeLimit(client, job, amount)
+	if err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+		}, nil
+	}
+
+	// Build transaction: native TRX, TRC10 or TRC20
 	var txExt *tronapi.TransactionExtention
-	var err error
 
-	if job.TokenAddress == "" {
+	if job.TokenID != "" {
+		// TRC10 asset transfer (amount in the asset's smallest unit, bandwidth only)
+		txExt, err = client.TransferAsset(job.FromAddress, job.ToAddress, job.TokenID, amount.Int64())
+	} else if job.TokenAddress == "" {
 		// Native TRX transfer (amount is in SUN: 1 TRX = 1,000,000 SUN)
 		txExt, err = client.Transfer(job.FromAddress, job.ToAddress, amount.Int64())
 	} else {
-		// TRC20 token transfer (e.g. USDT, USDC)
-		feeLimit := s.cfg.TRC20FeeLimit
-		if feeLimit <= 0 {
-			feeLimit = 100_000_000 // 100 TRX default
-		}
+		// TRC20 token transfer (e.g. USDT, USDC) with the estimated fee limit
 		txExt, err = client.TRC20Send(job.FromAddress, job.ToAddress, job.TokenAddress, amount, feeLimit)
 	}
 	if err != nil {
@@ -498,15 +1296,18 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 		}, nil
 	}
 	if txExt.GetResult() != nil && txExt.GetResult().GetCode() != tronapi.Return_SUCCESS {
+		rejected := &TronRejectedError{Code: txExt.GetResult().GetCode().String(), Err: fmt.Errorf("TRON node rejected transaction: %s", string(txExt.GetResult().GetMessage()))}
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
-			Error:   fmt.Errorf("TRON node rejected transaction: %s", string(txExt.GetResult().GetMessage())),
+			Error:   rejected,
 		}, nil
 	}
 
 	// Sign the transaction
-	signedTx, err := s.signTronTransaction(txExt.GetTransaction(), txExt.GetTxid(), privateKeyHex)
+	_, signSpan := tracing.Start(ctx, "tron.sign", trace.WithAttributes(tracing.AttrChainID.Int64(int64(job.ChainID))))
+	signedTx, err := s.signTronTransaction(ctx, txExt.GetTransaction(), txExt.GetTxid(), s.tronSigner, job.ChainID, jobSigningOp(job, signPurposePayout))
+	tracing.End(signSpan, err)
 	if err != nil {
 		return &queue.JobResult{
 			JobID:   job.ID,
@@ -516,7 +1317,17 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 	}
 
 	// Broadcast to the TRON network
+	_, broadcastSpan := tracing.Start(ctx, "rpc.broadcast",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tracing.AttrChainID.Int64(int64(job.ChainID)), tracing.AttrTxHash.String(hex.EncodeToString(txExt.GetTxid()))),
+	)
 	broadcastResult, err := client.Broadcast(signedTx)
+	recordTronBroadcastFailure(job.ChainID, broadcastResult, err)
+	if err == nil && !broadcastResult.GetResult() {
+		tracing.End(broadcastSpan, fmt.Errorf("broadcast rejected (code=%v)", broadcastResult.GetCode()))
+	} else {
+		tracing.End(broadcastSpan, err)
+	}
 	if err != nil {
 		return &queue.JobResult{
 			JobID:   job.ID,
@@ -527,42 +1338,76 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 
 	// Check broadcast result
 	if !broadcastResult.GetResult() {
+		rejected := &TronRejectedError{Code: broadcastResult.GetCode().String(), Err: fmt.Errorf("TRON broadcast rejected (code=%v): %s", broadcastResult.GetCode(), string(broadcastResult.GetMessage()))}
 		return &queue.JobResult{
 			JobID:   job.ID,
 			Success: false,
-			Error:   fmt.Errorf("TRON broadcast rejected (code=%v): %s", broadcastResult.GetCode(), string(broadcastResult.GetMessage())),
+			Error:   rejected,
 		}, nil
 	}
 
+	release()
+
 	// Extract transaction hash
 	txHash := hex.EncodeToString(txExt.GetTxid())
 	log.Info().
 		Str("job_id", job.ID).
+		Str(correlation.LogField, job.CorrelationID).
 		Str("tx_hash", txHash).
 		Str("to", job.ToAddress).
 		Str("token", job.TokenSymbol).
 		Msg("TRON transaction broadcast successfully")
 
+	// Wait for the block so the job reports it and failed executions (TRC20 REVERT) fail the job.
+	// Already broadcast: cancellation or timeout still reports success, a retry could pay twice.
+	deadline := time.Now().Add(s.cfg.TronConfirmTimeout)
+	info, err := s.waitForTronConfirmation(ctx, client, txHash, s.cfg.TronConfirmTimeout)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Str("tx_hash", txHash).Msg("Stopped waiting for TRON confirmation")
+	}
+	if info == nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: true,
+			TxHash:  txHash,
+		}, nil
+	}
+	if err := tronExecutionError(txHash, info); err != nil {
+		return &queue.JobResult{
+			JobID:   job.ID,
+			Success: false,
+			Error:   err,
+			GasCost: tronGasCost(info),
+		}, nil
+	}
+	// 达到链的确认数后才报告区块 (最终状态)，超时仍视为成功，由 event-indexer 跟进
+	block := uint64(info.GetBlockNumber())
+	if !s.waitForTronDepth(ctx, client, block, s.chainConfig(job.ChainID).Confirmations, time.Until(deadline)) {
+		log.Warn().Str("job_id", job.ID).Str("tx_hash", txHash).Uint64("block", block).Msg("TRON transaction not yet final")
+		block = 0
+	}
 	return &queue.JobResult{
-		JobID:   job.ID,
-		Success: true,
-		TxHash:  txHash,
+		JobID:       job.ID,
+		Success:     true,
+		TxHash:      txHash,
+		BlockNumber: block,
+		GasCost:     tronGasCost(info),
 	}, nil
 }
 
-// signTronTransaction signs a TRON transaction using ECDSA (secp256k1).
-// TRON uses SHA256(raw_data) as the signing hash, same curve as Ethereum.
-func (s *PayoutService) signTronTransaction(tx *troncore.Transaction, txID []byte, privateKeyHex string) (*troncore.Transaction, error) {
-	// Sanitize hex prefix
-	if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
-		privateKeyHex = privateKeyHex[2:]
-	}
+// tronGasCost 交易实际燃烧的 TRX (带宽与能量费用合计，单位 sun)
+func tronGasCost(info *troncore.TransactionInfo) *queue.GasCost {
+	return &queue.GasCost{Fee: strconv.FormatInt(info.GetFee(), 10)}
+}
 
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("invalid TRON private key: %w", err)
-	}
+// SetTronSigner 设置 TRON 付款签名器 (须在处理任务之前调用)
+func (s *PayoutService) SetTronSigner(signer kms.Signer) {
+	s.tronSigner = signer
+}
 
+// signTronTransaction signs a TRON transaction through the signer and records it in the signing audit log.
+// TRON uses SHA256(raw_data) as the signing hash, same curve as Ethereum.
+func (s *PayoutService) signTronTransaction(ctx context.Context, tx *troncore.Transaction, txID []byte, signer kms.Signer, chainID uint64, op signingOp) (*troncore.Transaction, error) {
 	// Determine the hash to sign:
 	// If the node provided txID (SHA256 of raw_data), use it directly.
 	// Otherwise, compute it ourselves.
@@ -578,8 +1423,13 @@ func (s *PayoutService) signTronTransaction(tx *troncore.Transaction, txID []byt
 		hash = h[:]
 	}
 
-	// Sign with ECDSA (TRON uses same secp256k1 as Ethereum)
-	signature, err := crypto.Sign(hash, privateKey)
+	// Sign the digest (TRON uses same secp256k1 as Ethereum)
+	start := time.Now()
+	signature, err := signer.SignHash(signingContext(ctx, op), hash)
+	metrics.SigningLatency.ObserveDuration(start, metrics.Label(chainID), signer.Provider(), signingResult(err))
+	if auditErr := s.auditSigning(ctx, chainID, op, hash, signer.Provider(), tronSignerAddress(signer), err); auditErr != nil {
+		return nil, auditErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign TRON transaction: %w", err)
 	}
@@ -588,21 +1438,37 @@ func (s *PayoutService) signTronTransaction(tx *troncore.Transaction, txID []byt
 	return tx, nil
 }
 
-// waitForTronConfirmation polls the TRON node for transaction confirmation.
-// Returns nil if confirmed, error on timeout or failure.
-func (s *PayoutService) waitForTronConfirmation(ctx context.Context, client *tronclient.GrpcClient, txHash string, timeout time.Duration) error {
+// tronSignerAddress 签名器的 TRON 地址 (与 EVM 地址同一公钥，前缀 0x41)
+func tronSignerAddress(signer kms.Signer) string {
+	return tronaddress.Address(append([]byte{tronaddress.TronBytePrefix}, signer.Address().Bytes()...)).String()
+}
+
+// tronConfirmPollInterval TRON 约 3 秒出一个块
+var tronConfirmPollInterval = 3 * time.Second
+
+// tronTxInfoClient 查询交易执行结果 (*tronclient.GrpcClient)
+type tronTxInfoClient interface {
+	GetTransactionInfoByID(id string) (*troncore.TransactionInfo, error)
+}
+
+// waitForTronConfirmation polls the TRON node until the transaction is included in a block.
+// Returns nil info on timeout (or when timeout <= 0) — the tx may still confirm later via event-indexer.
+func (s *PayoutService) waitForTronConfirmation(ctx context.Context, client tronTxInfoClient, txHash string, timeout time.Duration) (*troncore.TransactionInfo, error) {
+	if timeout <= 0 {
+		return nil, nil
+	}
 	deadline := time.After(timeout)
-	ticker := time.NewTicker(3 * time.Second)
+	ticker := time.NewTicker(tronConfirmPollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		case <-deadline:
 			// Not necessarily an error — tx may confirm later via event-indexer
 			log.Warn().Str("tx_hash", txHash).Msg("TRON confirmation polling timed out")
-			return nil
+			return nil, nil
 		case <-ticker.C:
 			info, err := client.GetTransactionInfoByID(txHash)
 			if err != nil {
@@ -613,13 +1479,70 @@ func (s *PayoutService) waitForTronConfirmation(ctx context.Context, client *tro
 				log.Info().
 					Str("tx_hash", txHash).
 					Int64("block", info.GetBlockNumber()).
+					Str("result", info.GetReceipt().GetResult().String()).
 					Msg("TRON transaction confirmed")
-				return nil
+				return info, nil
 			}
 		}
 	}
 }
 
+// tronHeadClient 查询最新区块 (*tronclient.GrpcClient)
+type tronHeadClient interface {
+	GetNowBlock() (*tronapi.BlockExtention, error)
+}
+
+// waitForTronDepth 等待 block 之上 (含该区块) 达到 confirmations 个确认，超时返回 false
+func (s *PayoutService) waitForTronDepth(ctx context.Context, client tronHeadClient, block, confirmations uint64, timeout time.Duration) bool {
+	if confirmations <= 1 {
+		return true
+	}
+	target := block + confirmations - 1
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(tronConfirmPollInterval)
+	defer ticker.Stop()
+
+	for {
+		now, err := client.GetNowBlock()
+		if err == nil && uint64(now.GetBlockHeader().GetRawData().GetNumber()) >= target {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// tronExecutionError 已上链交易的执行结果。TRX 转账的 receipt 结果为 DEFAULT，合约调用成功为 SUCCESS；
+// 能量耗尽或超时可重试 (失败的交易未转出代币)，REVERT 等合约失败为永久错误。
+func tronExecutionError(txHash string, info *troncore.TransactionInfo) error {
+	result := info.GetReceipt().GetResult()
+	if info.GetResult() != troncore.TransactionInfo_FAILED &&
+		(result == troncore.Transaction_Result_DEFAULT || result == troncore.Transaction_Result_SUCCESS) {
+		return nil
+	}
+
+	err := fmt.Errorf("TRON transaction %s failed in block %d: %s", txHash, info.GetBlockNumber(), result)
+	if msg := info.GetResMessage(); len(msg) > 0 {
+		// REVERT 时为 Error(string) 的 ABI 编码
+		reason, unpackErr := abi.UnpackRevert(msg)
+		if unpackErr != nil {
+			reason = string(msg)
+		}
+		err = fmt.Errorf("%w: %s", err, reason)
+	}
+	switch result {
+	case troncore.Transaction_Result_OUT_OF_ENERGY, troncore.Transaction_Result_OUT_OF_TIME:
+		return err
+	default:
+		return queue.Permanent(err)
+	}
+}
+
 // 请求/响应类型
 type BatchPayoutRequest struct {
 	BatchID     string
@@ -627,6 +1550,52 @@ type BatchPayoutRequest struct {
 	FromAddress string
 	ChainID     uint64
 	Items       []PayoutItem
+	FeeMode     FeeMode   // 空值等同 FeeModePayer
+	FeePolicy   FeePolicy // 仅 FeeModeRecipient 生效
+	Priority    string    // LOW / MEDIUM / HIGH / URGENT (空值为 MEDIUM)
+
+	// UseSmartAccount 通过 ERC-4337 智能账户发送 (FromAddress 为智能账户地址)
+	UseSmartAccount bool
+
+	// UseTreasury 从链配置的中央金库合约以 transferFrom 出款 (金库对 FromAddress 授权，付款地址只支付网络费)
+	UseTreasury bool
+
+	// AllowPartial 余额不足时接受能覆盖的支付项，其余返回在 Rejected 中
+	AllowPartial bool
+
+	// IdempotencyKey 客户端重试时保持不变 (为空时使用 BatchID)
+	IdempotencyKey string
+
+	// WebhookURL 批次状态回调地址 (可选)，事件以 WebhookSecret 做 HMAC 签名
+	WebhookURL    string
+	WebhookSecret string
+
+	// WebhookSchemaVersion 事件 payload 版本 (0 时使用 WEBHOOK_SCHEMA_VERSION)
+	WebhookSchemaVersion int
+
+	// ScreeningOverride 越过收款地址筛查 (人工批准，须填写 ScreeningOverrideReason)
+	ScreeningOverride       bool
+	ScreeningOverrideReason string
+
+	// Simulate 试运行: 逐笔 eth_call / eth_estimateGas，返回预计失败和总网络费，不签名、不广播
+	Simulate bool
+
+	// ExecuteAt 定时执行 (零值或已过时立即执行)。到期后才预检余额并入队，之前可取消或修改。
+	ExecuteAt time.Time
+
+	// Permit 代付: 源钱包签名的 EIP-2612 授权 (spender 为 FromAddress)。
+	// 各支付项以 transferFrom 从 Permit.Owner 直接转给收款方，付款地址只支付网络费。
+	Permit *queue.Permit
+
+	// Swap 转账前兑换: 付款地址以 Swap.SellToken 经 DEX 聚合器换出各支付项金额的支付代币，
+	// 报价的最少输出按 Swap.MaxSlippageBps 计算 (0 时使用 SWAP_MAX_SLIPPAGE_BPS)
+	Swap *queue.Swap
+
+	// SourceChainID 跨链路由: 非 0 时资金在该链签名器地址上，各支付项先经 CCTP 将 USDC 跨到 ChainID 再转账
+	SourceChainID uint64
+
+	// DuplicateMode 重复支付项 (收款地址、代币、金额均相同) 入队前标记剔除或合并 (为空时不检查)
+	DuplicateMode DuplicateMode
 }
 
 type PayoutItem struct {
@@ -636,19 +1605,51 @@ type PayoutItem struct {
 	TokenAddress     string
 	TokenSymbol      string
 	TokenDecimals    uint32
+	TokenID          string // TRC10 资产 ID (仅 TRON，与 TokenAddress 互斥)
+	MaxFee           string // 网络费上限 (原生代币最小单位 wei / SUN)，预计网络费超过时不发送 (可选)
+}
+
+// asset 转出的资产 (见 queue.Job.Asset)
+func (i PayoutItem) asset() string {
+	if i.TokenID != "" {
+		return i.TokenID
+	}
+	return i.TokenAddress
 }
 
 type BatchPayoutResponse struct {
-	BatchID string
-	Status  BatchStatus
-	Message string
+	BatchID  string
+	Status   BatchStatus
+	Message  string
+	Fees     []ItemFee      // 仅 FeeModeRecipient 返回
+	Rejected []RejectedItem // 仅 AllowPartial 且余额不足时返回
+	Testnet  bool           // 测试网支付 (无真实价值)
+	Replayed bool           // 幂等重放: 返回首次提交的响应，未重复入队
+
+	// 按 DuplicateMode 处理的重复支付项
+	Duplicates []DuplicateDecision
+
+	// 入队任务清单哈希及付款签名器的签名 (见 queue.Manifest)
+	ManifestHash      string
+	ManifestSignature string
+	ManifestSigner    string
+	// 试运行结果 (Simulate): 预计失败的支付项同时列在 Rejected 中
+	Simulated        bool
+	Simulation       []SimulatedItem
+	EstimatedGasCost string // 预计总网络费 (原生代币最小单位)
+
+	ExecuteAt time.Time // 定时批次的执行时间
 }
 
 type BatchStatus string
 
 const (
-	BatchStatusQueued     BatchStatus = "queued"
-	BatchStatusProcessing BatchStatus = "processing"
-	BatchStatusCompleted  BatchStatus = "completed"
-	BatchStatusFailed     BatchStatus = "failed"
+	BatchStatusScheduled       BatchStatus = "scheduled"        // 等待定时执行，尚未入队
+	BatchStatusPendingApproval BatchStatus = "pending_approval" // 超过审批阈值，等待审批人签名
+	BatchStatusQueued          BatchStatus = "queued"
+	BatchStatusProcessing      BatchStatus = "processing"
+	BatchStatusCompleted       BatchStatus = "completed"
+	BatchStatusPartialFailed   BatchStatus = "partial_failed"
+	BatchStatusFailed          BatchStatus = "failed"
+	BatchStatusCancelled       BatchStatus = "cancelled"
 )
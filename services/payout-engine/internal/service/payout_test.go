@@ -3,10 +3,50 @@ package service
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	tronaddress "github.com/fbsobreira/gotron-sdk/pkg/address"
+	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/activity"
+	"github.com/protocol-bank/payout-engine/internal/allowlist"
+	"github.com/protocol-bank/payout-engine/internal/approval"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/forksim"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/nonce"
+	"github.com/protocol-bank/payout-engine/internal/objectstore"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/screening"
+	"github.com/protocol-bank/payout-engine/internal/swap"
+	"github.com/protocol-bank/payout-engine/internal/webhook"
+	"github.com/protocol-bank/payout-engine/internal/zksync"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -259,6 +299,85 @@ func TestTRC20FeeLimit(t *testing.T) {
 	})
 }
 
+// ============================================
+// Recipient-Pays Fee Tests
+// ============================================
+
+func TestApplyRecipientFees(t *testing.T) {
+	svc := &PayoutService{}
+	token := "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+
+	t.Run("deducts service and token network fee with floor rounding", func(t *testing.T) {
+		req := &BatchPayoutRequest{
+			FeeMode:   FeeModeRecipient,
+			FeePolicy: FeePolicy{ServiceFeeBps: 25, TokenNetworkFee: "1000"},
+			Items: []PayoutItem{
+				{ID: "a", Amount: "1000000", TokenAddress: token},
+				{ID: "b", Amount: "1999", TokenAddress: token},
+			},
+		}
+		fees, err := svc.applyRecipientFees(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, fees, 2)
+
+		assert.Equal(t, "2500", fees[0].ServiceFee)
+		assert.Equal(t, "1000", fees[0].NetworkFee)
+		assert.Equal(t, "996500", fees[0].NetAmount)
+
+		// 1999 * 25 / 10000 = 4.9975 -> 4
+		assert.Equal(t, "4", fees[1].ServiceFee)
+		assert.Equal(t, "995", fees[1].NetAmount)
+
+		// The request itself is not mutated
+		assert.Equal(t, "1000000", req.Items[0].Amount)
+	})
+
+	t.Run("rejects items whose fees exceed the amount", func(t *testing.T) {
+		req := &BatchPayoutRequest{
+			FeePolicy: FeePolicy{TokenNetworkFee: "500"},
+			Items:     []PayoutItem{{ID: "a", Amount: "500", TokenAddress: token}},
+		}
+		_, err := svc.applyRecipientFees(context.Background(), req)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects service fee above 100%", func(t *testing.T) {
+		req := &BatchPayoutRequest{
+			FeePolicy: FeePolicy{ServiceFeeBps: 10_001},
+			Items:     []PayoutItem{{ID: "a", Amount: "500", TokenAddress: token}},
+		}
+		_, err := svc.applyRecipientFees(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
+
+// ============================================
+// Replace-By-Fee Tests
+// ============================================
+
+func TestBumpFees(t *testing.T) {
+	tests := []struct {
+		name        string
+		tip, fee    int64
+		percent     int
+		expectedTip int64
+		expectedFee int64
+	}{
+		{"15% bump", 2_000_000_000, 40_000_000_000, 15, 2_300_000_000, 46_000_000_000},
+		{"below node minimum is raised to 10%", 1_000, 10_000, 5, 1_100, 11_000},
+		{"tiny values still increase", 1, 1, 10, 2, 2},
+		{"fee cap never below tip", 100, 50, 20, 120, 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tip, fee := bumpFees(big.NewInt(tt.tip), big.NewInt(tt.fee), tt.percent)
+			assert.Equal(t, tt.expectedTip, tip.Int64())
+			assert.Equal(t, tt.expectedFee, fee.Int64())
+		})
+	}
+}
+
 // ============================================
 // Helper functions for tests
 // ============================================
@@ -289,26 +408,2769 @@ func isValidAmount(amount string) bool {
 	return val.Sign() > 0
 }
 
-func calculateGasBuffer(estimatedGas uint64, priority string) uint64 {
-	var multiplier float64
-	switch priority {
-	case "LOW":
-		multiplier = 1.1
-	case "MEDIUM":
-		multiplier = 1.2
-	case "HIGH":
-		multiplier = 1.3
-	case "URGENT":
-		multiplier = 1.5
-	default:
-		multiplier = 1.2
-	}
-	return uint64(float64(estimatedGas) * multiplier)
-}
-
 func calculateBatches(numRecipients, maxBatchSize int) int {
 	if numRecipients == 0 {
 		return 0
 	}
 	return (numRecipients + maxBatchSize - 1) / maxBatchSize
 }
+
+// fakeTokenContract answers CodeAt/CallContract for a single ERC20
+type fakeTokenContract struct {
+	code     []byte
+	symbol   string
+	decimals uint8
+}
+
+func (f *fakeTokenContract) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return f.code, nil
+}
+
+func (f *fakeTokenContract) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed, _ := abi.JSON(strings.NewReader(erc20ABI))
+	method, err := parsed.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "symbol" {
+		return method.Outputs.Pack(f.symbol)
+	}
+	return method.Outputs.Pack(f.decimals)
+}
+
+func TestTokenAllowlist(t *testing.T) {
+	list, err := allowlist.Parse([]byte(`{"tenants": {"*": [{"chain_id": 1, "address": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "symbol": "USDT", "decimals": 6}]}}`))
+	require.NoError(t, err)
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	svc := &PayoutService{allowlist: list, erc20ABI: parsed}
+
+	t.Run("allowlisted and native tokens pass", func(t *testing.T) {
+		assert.NoError(t, svc.checkAllowlist("user-1", 1, "0xdac17f958d2ee523a2206206994597c13d831ec7"))
+		assert.NoError(t, svc.checkAllowlist("user-1", 1, ""))
+	})
+
+	t.Run("unknown token rejected", func(t *testing.T) {
+		assert.Error(t, svc.checkAllowlist("user-1", 1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+	})
+
+	t.Run("reads on-chain metadata", func(t *testing.T) {
+		token := &fakeTokenContract{code: []byte{0x60}, symbol: "USDT", decimals: 6}
+		symbol, decimals, err := svc.readERC20Metadata(context.Background(), token, common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"))
+		require.NoError(t, err)
+		assert.Equal(t, "USDT", symbol)
+		assert.Equal(t, uint64(6), decimals)
+	})
+
+	t.Run("address without code rejected", func(t *testing.T) {
+		_, _, err := svc.readERC20Metadata(context.Background(), &fakeTokenContract{}, common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"))
+		assert.ErrorIs(t, err, errNotTokenContract)
+	})
+}
+
+func TestTokenDecimalsMismatch(t *testing.T) {
+	usdt := "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+	onchain := map[string]*queue.TokenMetadata{usdt: {Symbol: "USDT", Decimals: 6}}
+
+	assert.NoError(t, tokenDecimalsMismatch([]PayoutItem{
+		{TokenAddress: usdt, TokenDecimals: 6},
+		{Amount: "1"}, // 原生代币使用链配置的精度
+	}, onchain))
+
+	err := tokenDecimalsMismatch([]PayoutItem{
+		{TokenAddress: usdt, TokenDecimals: 6},
+		{TokenAddress: usdt, TokenDecimals: 18},
+	}, onchain)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "item[1]")
+	assert.Contains(t, err.Error(), "on-chain decimals 6")
+
+	assert.Equal(t, "0xdac17f958d2ee523a2206206994597c13d831ec7", tokenCacheAsset(usdt))
+	assert.Equal(t, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", tokenCacheAsset("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"), "base58 is case-sensitive")
+}
+
+func TestRequestFaucet(t *testing.T) {
+	t.Run("posts wallet address with bearer key", func(t *testing.T) {
+		var got map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer faucet-key", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		faucet := config.FaucetConfig{URL: server.URL, APIKey: "faucet-key"}
+		require.NoError(t, requestFaucet(context.Background(), faucet, 11155111, "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"))
+		assert.Equal(t, "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", got["address"])
+		assert.Equal(t, float64(11155111), got["chain_id"])
+	})
+
+	t.Run("surfaces faucet rejection", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		err := requestFaucet(context.Background(), config.FaucetConfig{URL: server.URL}, 84532, "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "429")
+	})
+}
+
+func TestTestnetChainsSkipAllowlist(t *testing.T) {
+	list, err := allowlist.Parse([]byte(`{"tenants": {"*": [{"chain_id": 1, "address": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "symbol": "USDT", "decimals": 6}]}}`))
+	require.NoError(t, err)
+	svc := &PayoutService{
+		allowlist: list,
+		cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+			11155111: {ChainID: 11155111, Testnet: true},
+		}},
+	}
+
+	assert.NoError(t, svc.checkAllowlist("user-1", 11155111, "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"))
+	assert.Error(t, svc.checkAllowlist("user-1", 1, "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"))
+}
+
+func TestPlanBatch(t *testing.T) {
+	usdt := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	items := []preflightItem{
+		{id: "a", token: usdt, amount: big.NewInt(600), gas: big.NewInt(10)},
+		{id: "b", token: usdt, amount: big.NewInt(600), gas: big.NewInt(10)},
+		{id: "c", token: "", amount: big.NewInt(50), gas: big.NewInt(5)},
+	}
+
+	t.Run("accepts batch when balances cover everything", func(t *testing.T) {
+		balances := &preflightBalances{native: big.NewInt(100), tokens: map[string]*big.Int{usdt: big.NewInt(1200)}}
+		accepted, rejected, err := planBatch(items, balances, false, "ETH")
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 1, 2}, accepted)
+		assert.Empty(t, rejected)
+	})
+
+	t.Run("rejects whole batch without allow_partial", func(t *testing.T) {
+		balances := &preflightBalances{native: big.NewInt(100), tokens: map[string]*big.Int{usdt: big.NewInt(1000)}}
+		_, _, err := planBatch(items, balances, false, "ETH")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "needs 1200, available 1000")
+	})
+
+	t.Run("partially accepts in submission order", func(t *testing.T) {
+		balances := &preflightBalances{native: big.NewInt(100), tokens: map[string]*big.Int{usdt: big.NewInt(1000)}}
+		accepted, rejected, err := planBatch(items, balances, true, "ETH")
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 2}, accepted)
+		require.Len(t, rejected, 1)
+		assert.Equal(t, "b", rejected[0].ItemID)
+	})
+
+	t.Run("gas shortfall rejects items", func(t *testing.T) {
+		balances := &preflightBalances{native: big.NewInt(15), tokens: map[string]*big.Int{usdt: big.NewInt(1200)}}
+		accepted, rejected, err := planBatch(items, balances, true, "ETH")
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, accepted)
+		assert.Len(t, rejected, 2)
+	})
+}
+
+func TestReplayResponse(t *testing.T) {
+	req := &BatchPayoutRequest{BatchID: "batch-1", UserID: "user-1", ChainID: 1, IdempotencyKey: "key-1"}
+	hash, err := hashRequest(req)
+	require.NoError(t, err)
+
+	t.Run("idempotency key does not affect the hash", func(t *testing.T) {
+		other := *req
+		other.IdempotencyKey = "key-2"
+		otherHash, err := hashRequest(&other)
+		require.NoError(t, err)
+		assert.Equal(t, hash, otherHash)
+	})
+
+	t.Run("returns the original response", func(t *testing.T) {
+		original, _ := json.Marshal(&BatchPayoutResponse{BatchID: "batch-1", Status: BatchStatusQueued, Message: "Queued 2 payments for processing"})
+		resp, err := replayResponse(req, "key-1", hash, &queue.IdempotencyRecord{RequestHash: hash, Response: original})
+		require.NoError(t, err)
+		assert.True(t, resp.Replayed)
+		assert.Equal(t, "Queued 2 payments for processing", resp.Message)
+	})
+
+	t.Run("rejects key reuse with a different request", func(t *testing.T) {
+		_, err := replayResponse(req, "key-1", hash, &queue.IdempotencyRecord{RequestHash: "other"})
+		assert.Error(t, err)
+	})
+
+	t.Run("reports in-progress submissions", func(t *testing.T) {
+		_, err := replayResponse(req, "key-1", hash, &queue.IdempotencyRecord{RequestHash: hash})
+		assert.ErrorIs(t, err, queue.ErrIdempotencyInProgress)
+	})
+}
+
+func TestProjectRunway(t *testing.T) {
+	assert.Nil(t, projectRunway(big.NewInt(1000), 0), "no outflow history means no projection")
+
+	days := projectRunway(big.NewInt(1000), 250)
+	require.NotNil(t, days)
+	assert.InDelta(t, 4.0, *days, 1e-9)
+
+	days = projectRunway(big.NewInt(-50), 250)
+	require.NotNil(t, days)
+	assert.Equal(t, 0.0, *days, "overcommitted wallets have no runway")
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	job := func(state queue.JobState) *queue.JobStatus {
+		return &queue.JobStatus{State: state, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+
+	tests := []struct {
+		name   string
+		states []queue.JobState
+		want   BatchStatus
+	}{
+		{"all queued", []queue.JobState{queue.JobStatePending, queue.JobStatePending}, BatchStatusQueued},
+		{"in flight", []queue.JobState{queue.JobStatePending, queue.JobStateProcessing}, BatchStatusProcessing},
+		{"some done, some queued", []queue.JobState{queue.JobStateConfirmed, queue.JobStatePending}, BatchStatusProcessing},
+		{"completed", []queue.JobState{queue.JobStateConfirmed, queue.JobStateConfirmed}, BatchStatusCompleted},
+		{"partial failure", []queue.JobState{queue.JobStateConfirmed, queue.JobStateFailed}, BatchStatusPartialFailed},
+		{"all failed", []queue.JobState{queue.JobStateFailed}, BatchStatusFailed},
+		{"cancelled", []queue.JobState{queue.JobStateCancelled, queue.JobStateCancelled}, BatchStatusCancelled},
+		{"cancelled after some sent", []queue.JobState{queue.JobStateConfirmed, queue.JobStateCancelled}, BatchStatusCompleted},
+		{"some items cancelled, rest queued", []queue.JobState{queue.JobStateCancelled, queue.JobStatePending}, BatchStatusQueued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var jobs []*queue.JobStatus
+			for _, s := range tt.states {
+				jobs = append(jobs, job(s))
+			}
+			result := summarizeBatch("batch-1", jobs)
+			assert.Equal(t, tt.want, result.Status)
+			assert.Equal(t, len(tt.states), result.TotalCount)
+			assert.Equal(t, result.TotalCount, result.CompletedCount+result.FailedCount+result.PendingCount+result.CancelledCount)
+		})
+	}
+
+	t.Run("sums gas fees", func(t *testing.T) {
+		result := summarizeBatch("batch-1", []*queue.JobStatus{
+			{State: queue.JobStateConfirmed, GasFee: "21000000000000"},
+			{State: queue.JobStateConfirmed, GasFee: "50000000000000", UnwrapGasFee: "30000000000000"},
+			{State: queue.JobStateFailed, GasFee: "1000"}, // 执行失败的交易同样付费
+			{State: queue.JobStatePending},
+		})
+		assert.Equal(t, "101000000001000", result.GasFee)
+	})
+}
+
+func TestSettlementBuilder(t *testing.T) {
+	from := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+	b := newSettlementBuilder("user-1", from, to)
+	b.add(summarizeBatch("batch-1", []*queue.JobStatus{
+		{ID: "a", BatchID: "batch-1", ChainID: 1, Amount: "100", State: queue.JobStateConfirmed, GasFee: "21000"},
+		{ID: "b", BatchID: "batch-1", ChainID: 1, Amount: "250", TokenAddress: usdc, TokenSymbol: "USDC", State: queue.JobStateConfirmed, GasFee: "50000"},
+		{ID: "c", BatchID: "batch-1", ChainID: 1, Amount: "10", TokenAddress: usdc, State: queue.JobStateFailed, Error: "reverted"},
+	}))
+	b.add(summarizeBatch("batch-2", []*queue.JobStatus{
+		{ID: "d", BatchID: "batch-2", ChainID: 1, Amount: "50", TokenAddress: strings.ToLower(usdc), TokenSymbol: "USDC", State: queue.JobStateConfirmed},
+		{ID: "e", BatchID: "batch-2", ChainID: 1, Amount: "50", State: queue.JobStateCancelled},
+	}))
+	sum := b.build(map[uint64]config.ChainConfig{1: {NativeToken: "ETH"}})
+
+	assert.Equal(t, 2, sum.Batches.Total)
+	assert.Equal(t, 1, sum.Batches.PartialFailed)
+	assert.Equal(t, 1, sum.Batches.Completed)
+	assert.Equal(t, 5, sum.Jobs.Total)
+	assert.Equal(t, 3, sum.Jobs.Confirmed)
+	assert.Equal(t, 1, sum.Jobs.Failed)
+	assert.Equal(t, 1, sum.Jobs.Cancelled)
+
+	require.Len(t, sum.Totals, 2)
+	assert.Equal(t, "ETH", sum.Totals[0].Symbol)
+	assert.Equal(t, "100", sum.Totals[0].Amount)
+	assert.Equal(t, "USDC", sum.Totals[1].Symbol)
+	assert.Equal(t, "300", sum.Totals[1].Amount, "token addresses are case-insensitive")
+	assert.Equal(t, 2, sum.Totals[1].Count)
+
+	require.Len(t, sum.GasSpend, 1)
+	assert.Equal(t, "71000", sum.GasSpend[0].Amount)
+	assert.Equal(t, 2, sum.GasSpend[0].Count)
+
+	require.Len(t, sum.Failures, 1)
+	assert.Equal(t, "reverted", sum.Failures[0].Error)
+	assert.Equal(t, "settlement:user-1:2026-10-17", sum.EventID())
+}
+
+func TestSettlementPeriod(t *testing.T) {
+	start, end := settlementPeriod(time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC), time.Hour)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), start, "waits for the delay after midnight")
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), end)
+
+	start, _ = settlementPeriod(time.Date(2026, 10, 18, 1, 30, 0, 0, time.UTC), time.Hour)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), start)
+}
+
+func TestSignManifest(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	s := &PayoutService{signer: signer}
+
+	jobs := []*queue.Job{
+		{ID: "b", BatchID: "batch-1", UserID: "user-1", ChainID: 8453, ToAddress: "0x2", Amount: "200", TokenDecimals: 6},
+		{ID: "a", BatchID: "batch-1", UserID: "user-1", ChainID: 8453, ToAddress: "0x1", Amount: "100", TokenDecimals: 6},
+	}
+	m, err := s.signManifest(context.Background(), jobs)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address().Hex(), m.Signer)
+	assert.Equal(t, "a", m.Items[0].ID)
+	require.NoError(t, VerifyManifest(m))
+
+	// 任务顺序不影响哈希
+	reordered, err := s.signManifest(context.Background(), []*queue.Job{jobs[1], jobs[0]})
+	require.NoError(t, err)
+	assert.Equal(t, m.Hash, reordered.Hash)
+
+	// 篡改任意字段后校验失败
+	m.Items[1].Amount = "2000"
+	assert.Error(t, VerifyManifest(m))
+}
+
+func TestChainAvailable(t *testing.T) {
+	health := &nodeHealth{url: "grpc.trongrid.io:50051"}
+	svc := &PayoutService{
+		cfg:        &config.Config{Chains: map[uint64]config.ChainConfig{728126428: {ChainID: 728126428, Name: "TRON"}}},
+		tronHealth: map[uint64]*nodeHealth{728126428: health},
+	}
+	assert.NoError(t, svc.chainAvailable(728126428))
+
+	health.downUntil = time.Now().Add(time.Minute)
+	err := svc.chainAvailable(728126428)
+	require.Error(t, err)
+	assert.True(t, IsUnavailable(err))
+
+	st := svc.RPCStatus()
+	require.Len(t, st, 1)
+	assert.False(t, st[0].Available)
+	assert.Equal(t, "grpc.trongrid.io:50051", st[0].Endpoints[0].URL)
+}
+
+func TestReloadChains(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "chains.json")
+	writeChains := func(body string) {
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	}
+	writeChains(`{"chains": [
+		{"chain_id": 728126428, "disabled": true},
+		{"chain_id": 1, "rpc_url": "http://127.0.0.1:8545"}
+	]}`)
+	svc := &PayoutService{cfg: &config.Config{Network: config.NetworkMainnet, ChainsFile: path}}
+
+	result, err := svc.ReloadChains(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 10, 137, 8453, 42161}, result.Added)
+	eth := svc.chainConfig(1)
+	assert.Equal(t, "http://127.0.0.1:8545", eth.RPCURL)
+	assert.Equal(t, "Ethereum", eth.Name, "omitted fields keep built-in values")
+	pool, ok := svc.evmClient(1)
+	require.True(t, ok)
+
+	writeChains(`{"chains": [
+		{"chain_id": 728126428, "disabled": true},
+		{"chain_id": 10, "disabled": true},
+		{"chain_id": 1, "rpc_url": "http://127.0.0.1:8545", "stuck_tx_timeout": "5m"},
+		{"chain_id": 56, "name": "BNB Chain", "type": "evm", "rpc_url": "http://127.0.0.1:8546", "native_token": "BNB", "decimals": 18}
+	]}`)
+	result, err = svc.ReloadChains(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{56}, result.Added)
+	assert.Equal(t, []uint64{1}, result.Updated)
+	assert.Equal(t, []uint64{10}, result.Removed)
+	assert.Equal(t, 5*time.Minute, svc.chainConfig(1).StuckTxTimeout)
+	same, _ := svc.evmClient(1)
+	assert.Same(t, pool, same, "config-only changes keep the connection")
+	_, ok = svc.evmClient(10)
+	assert.False(t, ok)
+
+	t.Run("invalid file keeps current chains", func(t *testing.T) {
+		writeChains(`{"chains": [{"chain_id": 250, "rpc_url": "http://127.0.0.1:8547"}]}`)
+		_, err := svc.ReloadChains(ctx)
+		require.Error(t, err)
+		assert.True(t, IsFailedPrecondition(err))
+		_, ok := svc.evmClient(56)
+		assert.True(t, ok)
+	})
+}
+
+func TestChainSigners(t *testing.T) {
+	newSigner := func() kms.Signer {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+		require.NoError(t, err)
+		return signer
+	}
+	def, polygon := newSigner(), newSigner()
+
+	pool, err := rpcpool.Dial(context.Background(), 137, []string{"http://127.0.0.1:8545"}, rpcpool.Config{})
+	require.NoError(t, err)
+	defer pool.Close()
+	svc := &PayoutService{
+		cfg:          &config.Config{Chains: map[uint64]config.ChainConfig{137: {ChainID: 137, Name: "Polygon", Type: "evm"}}},
+		signer:       def,
+		chainSigners: map[uint64]kms.Signer{137: polygon},
+		clients:      map[uint64]*rpcpool.Pool{137: pool},
+	}
+
+	assert.Equal(t, polygon, svc.signerFor(137))
+	assert.Equal(t, def, svc.signerFor(8453), "chains without their own signer use the default")
+	address, err := svc.payoutAddress(137)
+	require.NoError(t, err)
+	assert.Equal(t, polygon.Address().Hex(), address)
+
+	req := &BatchPayoutRequest{
+		BatchID:     "batch-1",
+		UserID:      "user-1",
+		ChainID:     137,
+		FromAddress: def.Address().Hex(),
+		Items:       []PayoutItem{{RecipientAddress: "0x000000000000000000000000000000000000dEaD", Amount: "1"}},
+	}
+	err = svc.validateRequest(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), polygon.Address().Hex())
+
+	req.FromAddress = polygon.Address().Hex()
+	assert.NoError(t, svc.validateRequest(context.Background(), req))
+}
+
+func TestBuildGasReport(t *testing.T) {
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	usage := []ledger.GasUsage{
+		{ChainID: 8453, Period: day2, Payouts: 4, Transactions: 1, Fee: "300", BatchedPayouts: 4, BatchedFee: "300", SingleFee: "0"},
+		{ChainID: 8453, Period: day1, Payouts: 5, Transactions: 3, Fee: "500", SingleTxs: 2, SingleFee: "200", BatchedPayouts: 3, BatchedFee: "300"},
+		{ChainID: 1, Period: day1, Payouts: 1, Transactions: 1, Fee: "1000", SingleTxs: 1, SingleFee: "1000", BatchedFee: "0"},
+	}
+	chains := map[uint64]config.ChainConfig{8453: {Name: "Base", NativeToken: "ETH", Decimals: 18}}
+
+	reports := buildGasReport(usage, chains)
+	require.Len(t, reports, 2)
+	assert.EqualValues(t, 1, reports[0].ChainID)
+	assert.Equal(t, "0", reports[0].BatchingSavings)
+
+	base := reports[1]
+	assert.Equal(t, "Base", base.Name)
+	require.Len(t, base.Periods, 2)
+	assert.Equal(t, day1, *base.Periods[0].Start)
+	assert.Equal(t, "100", base.Periods[0].AvgCostPerPayout)
+	assert.Equal(t, "0", base.Periods[0].BatchingSavings, "3 payouts at 100 each vs 300 batched")
+	assert.Equal(t, "100", base.Periods[1].BatchingSavings, "no single transfers that day: uses the range average")
+
+	assert.EqualValues(t, 9, base.Payouts)
+	assert.Equal(t, "800", base.GasSpent)
+	assert.Equal(t, "88", base.AvgCostPerPayout)
+	assert.Equal(t, "100", base.BatchingSavings)
+	assert.Nil(t, base.Start)
+}
+
+type fakeScreener struct {
+	result *screening.Result
+	err    error
+	calls  int
+}
+
+func (f *fakeScreener) Screen(ctx context.Context, chainID uint64, address string) (*screening.Result, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func (f *fakeScreener) Provider() string { return "fake" }
+
+func TestScreenRecipient(t *testing.T) {
+	cfg := &config.Config{Screening: screening.Config{BlockRisk: screening.RiskHigh}}
+	job := &queue.Job{ID: "job-1", BatchID: "batch-1", ChainID: 1, ToAddress: "0x7F367cC41522cE07553e823bf3be79A889DEbe1B"}
+
+	t.Run("disabled", func(t *testing.T) {
+		svc := &PayoutService{cfg: cfg}
+		assert.NoError(t, svc.screenRecipient(context.Background(), job))
+	})
+
+	t.Run("low risk passes", func(t *testing.T) {
+		svc := &PayoutService{cfg: cfg, screener: &fakeScreener{result: &screening.Result{Risk: screening.RiskMedium}}}
+		assert.NoError(t, svc.screenRecipient(context.Background(), job))
+	})
+
+	t.Run("sanctioned address blocked permanently", func(t *testing.T) {
+		svc := &PayoutService{cfg: cfg, screener: &fakeScreener{result: &screening.Result{Address: job.ToAddress, Sanctioned: true, Risk: screening.RiskSevere, Provider: "fake"}}}
+		err := svc.screenRecipient(context.Background(), job)
+		require.Error(t, err)
+		assert.True(t, queue.IsPermanent(err))
+		assert.Equal(t, screening.Code, queue.ErrorCode(err))
+	})
+
+	t.Run("provider outage is retryable", func(t *testing.T) {
+		svc := &PayoutService{cfg: cfg, screener: &fakeScreener{err: assert.AnError}}
+		err := svc.screenRecipient(context.Background(), job)
+		require.Error(t, err)
+		assert.False(t, queue.IsPermanent(err))
+	})
+
+	t.Run("batch override skips screening", func(t *testing.T) {
+		screener := &fakeScreener{result: &screening.Result{Sanctioned: true}}
+		svc := &PayoutService{cfg: cfg, screener: screener}
+		overridden := *job
+		overridden.ScreeningOverride = "false positive, confirmed by compliance"
+		assert.NoError(t, svc.screenRecipient(context.Background(), &overridden))
+		assert.Zero(t, screener.calls)
+	})
+}
+
+func TestRecipientLists(t *testing.T) {
+	denied := "0x7F367cC41522cE07553e823bf3be79A889DEbe1B"
+	trusted := "0x8589427373D6D84E98730D7795D8f6f8731FDA16"
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	entries := []ledger.AddressListEntry{
+		{List: ledger.ListDeny, Address: strings.ToLower(denied), Reason: "phishing"},
+		{List: ledger.ListAllow, Address: strings.ToLower(trusted)},
+	}
+
+	t.Run("denied recipient rejected", func(t *testing.T) {
+		req := &BatchPayoutRequest{Items: []PayoutItem{{RecipientAddress: trusted}, {RecipientAddress: denied}}}
+		err := recipientListViolation(req, entries, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "item[1]")
+		assert.Contains(t, err.Error(), "phishing")
+	})
+
+	t.Run("allow list required above threshold", func(t *testing.T) {
+		svc := &PayoutService{cfg: &config.Config{}, allowlistThreshold: big.NewRat(10_000, 1)}
+		small := &BatchPayoutRequest{ChainID: 8453, Items: []PayoutItem{
+			{RecipientAddress: "0x0000000000000000000000000000000000000001", Amount: "5000000000", TokenAddress: usdc, TokenDecimals: 6},
+		}}
+		large := &BatchPayoutRequest{ChainID: 8453, Items: []PayoutItem{
+			{RecipientAddress: trusted, Amount: "6000000000", TokenAddress: usdc, TokenDecimals: 6},
+			{RecipientAddress: "0x0000000000000000000000000000000000000001", Amount: "4000000000", TokenAddress: strings.ToLower(usdc), TokenDecimals: 6},
+		}}
+		assert.False(t, svc.allowlistRequired(small))
+		require.True(t, svc.allowlistRequired(large), "token totals are summed across items")
+
+		assert.NoError(t, recipientListViolation(small, entries, false))
+		err := recipientListViolation(large, entries, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "item[1]")
+
+		large.Items = large.Items[:1]
+		assert.NoError(t, recipientListViolation(large, entries, true))
+	})
+
+	t.Run("threshold disabled", func(t *testing.T) {
+		svc := &PayoutService{cfg: &config.Config{}}
+		assert.False(t, svc.allowlistRequired(&BatchPayoutRequest{Items: []PayoutItem{{Amount: "1000000000000"}}}))
+	})
+}
+
+// fakeEstimator reverts transfers to revertTo and returns false for transfers to falseTo.
+// OP Stack GasPriceOracle calls return l1Fee.
+type fakeEstimator struct {
+	revertTo common.Address
+	falseTo  common.Address
+	l1Fee    *big.Int
+}
+
+func (f *fakeEstimator) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if *msg.To == common.HexToAddress("0x420000000000000000000000000000000000000F") {
+		return common.LeftPadBytes(f.l1Fee.Bytes(), 32), nil
+	}
+	parsed, _ := abi.JSON(strings.NewReader(erc20ABI))
+	args, err := parsed.Methods["transfer"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	switch args[0].(common.Address) {
+	case f.revertTo:
+		return nil, errors.New("execution reverted: blacklisted")
+	case f.falseTo:
+		return parsed.Methods["transfer"].Outputs.Pack(false)
+	}
+	return parsed.Methods["transfer"].Outputs.Pack(true)
+}
+
+func (f *fakeEstimator) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if len(msg.Data) == 0 {
+		return 21000, nil
+	}
+	return 50000, nil
+}
+
+func TestSimulateBatch(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	svc := &PayoutService{erc20ABI: parsed}
+
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	client := &fakeEstimator{
+		revertTo: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		falseTo:  common.HexToAddress("0x0000000000000000000000000000000000000003"),
+	}
+	req := &BatchPayoutRequest{Items: []PayoutItem{
+		{ID: "native", RecipientAddress: "0x0000000000000000000000000000000000000001", Amount: "1000"},
+		{ID: "revert", RecipientAddress: "0x0000000000000000000000000000000000000002", Amount: "1000", TokenAddress: usdc},
+		{ID: "false", RecipientAddress: "0x0000000000000000000000000000000000000003", Amount: "1000", TokenAddress: usdc},
+		{ID: "token", RecipientAddress: "0x0000000000000000000000000000000000000004", Amount: "1000", TokenAddress: usdc},
+		{ID: "short", RecipientAddress: "0x0000000000000000000000000000000000000005", Amount: "1000", TokenAddress: usdc},
+	}}
+	amounts := make([]*big.Int, len(req.Items))
+	for i := range amounts {
+		amounts[i] = big.NewInt(1000)
+	}
+	fees := &gas.Fees{BaseFee: big.NewInt(10), TipCap: big.NewInt(2), FeeCap: big.NewInt(100)}
+
+	results := svc.simulateEVM(context.Background(), client, common.HexToAddress("0x00000000000000000000000000000000000000aa"), req, amounts,
+		map[string]string{"short": "insufficient token balance"}, fees, "")
+	require.Len(t, results, 5)
+
+	assert.Empty(t, results[0].Error)
+	assert.EqualValues(t, 21000, results[0].GasLimit)
+	assert.Equal(t, "252000", results[0].GasCost, "priced at base fee + tip")
+	assert.Contains(t, results[1].Error, "blacklisted")
+	assert.Equal(t, "transfer returned false", results[2].Error)
+	assert.Empty(t, results[3].Error)
+	assert.Equal(t, "600000", results[3].GasCost)
+	assert.Equal(t, "insufficient token balance", results[4].Error)
+	assert.Zero(t, results[4].GasLimit, "items short on balance are not estimated")
+
+	// OP Stack 另外收取 L1 数据费
+	client.l1Fee = big.NewInt(40000)
+	results = svc.simulateEVM(context.Background(), client, common.HexToAddress("0x00000000000000000000000000000000000000aa"), req, amounts,
+		map[string]string{"short": "insufficient token balance"}, fees, gas.L1FeeOP)
+	assert.Equal(t, "292000", results[0].GasCost)
+	assert.Equal(t, "640000", results[3].GasCost)
+}
+
+// fakeBalances returns fixed native and ERC20 balances.
+type fakeBalances struct {
+	native  *big.Int
+	wrapped *big.Int
+}
+
+func (f *fakeBalances) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (f *fakeBalances) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed, _ := abi.JSON(strings.NewReader(erc20ABI))
+	return parsed.Methods["balanceOf"].Outputs.Pack(f.wrapped)
+}
+
+func (f *fakeBalances) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return f.native, nil
+}
+
+func TestUnwrapShortfall(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	wrappedABI, err := abi.JSON(strings.NewReader(wrappedNativeABI))
+	require.NoError(t, err)
+	svc := &PayoutService{erc20ABI: parsed, wrappedABI: wrappedABI}
+
+	ctx := context.Background()
+	weth := common.HexToAddress("0x4200000000000000000000000000000000000006")
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	need, unwrapFee := big.NewInt(1000), big.NewInt(50)
+
+	tests := []struct {
+		name    string
+		native  int64
+		wrapped int64
+		want    *big.Int
+	}{
+		{"native covers payout", 1000, 5000, nil},
+		{"unwraps shortfall plus unwrap fee", 400, 5000, big.NewInt(650)},
+		{"wrapped balance too low", 400, 600, nil},
+		{"cannot pay unwrap gas", 10, 5000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeBalances{native: big.NewInt(tt.native), wrapped: big.NewInt(tt.wrapped)}
+			got, err := svc.unwrapShortfall(ctx, client, weth, from, need, unwrapFee)
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want.String(), got.String())
+		})
+	}
+
+	fees := &gas.Fees{TipCap: big.NewInt(2), FeeCap: big.NewInt(100)}
+	tx, err := svc.buildUnwrap(8453, weth, big.NewInt(650), 7, fees, 55000)
+	require.NoError(t, err)
+	assert.Equal(t, weth, *tx.To())
+	assert.EqualValues(t, 7, tx.Nonce())
+	assert.Equal(t, wrappedABI.Methods["withdraw"].ID, tx.Data()[:4])
+	args, err := wrappedABI.Methods["withdraw"].Inputs.Unpack(tx.Data()[4:])
+	require.NoError(t, err)
+	assert.Equal(t, "650", args[0].(*big.Int).String())
+}
+
+type fakeSimulator struct {
+	result *forksim.Result
+	err    error
+	calls  int
+}
+
+func (f *fakeSimulator) Simulate(ctx context.Context, chainID uint64, from common.Address, tx *types.Transaction) (*forksim.Result, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func (f *fakeSimulator) Provider() string { return "fake" }
+
+func TestForkSimulate(t *testing.T) {
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	large := &queue.Job{ID: "job-1", ChainID: 8453, Amount: "25000000000", TokenAddress: usdc, TokenDecimals: 6}
+	small := &queue.Job{ID: "job-2", ChainID: 8453, Amount: "5000000", TokenAddress: usdc, TokenDecimals: 6}
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(8453), Gas: 65000})
+	threshold := big.NewRat(10_000, 1)
+
+	t.Run("below threshold skipped", func(t *testing.T) {
+		sim := &fakeSimulator{result: &forksim.Result{Reverted: true}}
+		svc := &PayoutService{simulator: sim, forkSimThreshold: threshold}
+		assert.NoError(t, svc.forkSimulate(context.Background(), small, tx))
+		assert.Zero(t, sim.calls)
+	})
+
+	t.Run("success passes", func(t *testing.T) {
+		sim := &fakeSimulator{result: &forksim.Result{}}
+		svc := &PayoutService{simulator: sim, forkSimThreshold: threshold}
+		assert.NoError(t, svc.forkSimulate(context.Background(), large, tx))
+		assert.Equal(t, 1, sim.calls)
+	})
+
+	t.Run("revert aborts permanently", func(t *testing.T) {
+		svc := &PayoutService{simulator: &fakeSimulator{result: &forksim.Result{Reverted: true, RevertReason: "blacklisted"}}}
+		err := svc.forkSimulate(context.Background(), small, tx)
+		require.Error(t, err)
+		assert.True(t, queue.IsPermanent(err))
+		assert.Equal(t, forksim.Code, queue.ErrorCode(err))
+		var reverted *forksim.RevertedError
+		require.True(t, errors.As(err, &reverted))
+		assert.Equal(t, "blacklisted", reverted.Result.RevertReason)
+	})
+
+	t.Run("provider outage is retryable", func(t *testing.T) {
+		svc := &PayoutService{simulator: &fakeSimulator{err: errors.New("connection refused")}}
+		err := svc.forkSimulate(context.Background(), large, tx)
+		require.Error(t, err)
+		assert.False(t, queue.IsPermanent(err))
+	})
+
+	t.Run("chain without fork skipped", func(t *testing.T) {
+		svc := &PayoutService{simulator: &fakeSimulator{err: &forksim.UnsupportedChainError{ChainID: 8453}}}
+		assert.NoError(t, svc.forkSimulate(context.Background(), large, tx))
+	})
+}
+
+func TestUsePrivateTx(t *testing.T) {
+	usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	svc := &PayoutService{cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+		1: {ChainID: 1, Name: "Ethereum", Type: "evm", Decimals: 18, PrivateTx: config.PrivateTxConfig{
+			RPCURL: "https://rpc.flashbots.net", MinAmount: "50000",
+		}},
+		8453: {ChainID: 8453, Name: "Base", Type: "evm", Decimals: 18},
+	}}}
+
+	large := &queue.Job{ChainID: 1, Amount: "75000000000", TokenAddress: usdc, TokenDecimals: 6}
+	assert.True(t, svc.usePrivateTx(large))
+
+	small := &queue.Job{ChainID: 1, Amount: "1000000", TokenAddress: usdc, TokenDecimals: 6}
+	assert.False(t, svc.usePrivateTx(small), "below min_amount")
+
+	native := &queue.Job{ChainID: 1, Amount: "100000000000000000000000"}
+	assert.False(t, svc.usePrivateTx(native), "native transfers cannot be sandwiched")
+
+	unconfigured := &queue.Job{ChainID: 8453, Amount: "75000000000", TokenAddress: usdc, TokenDecimals: 6}
+	assert.False(t, svc.usePrivateTx(unconfigured))
+}
+
+// fakeTronResources 固定的 TRON 账户资源与估算结果
+type fakeTronResources struct {
+	energyUsed int64
+	revert     bool
+	resources  *tronapi.AccountResourceMessage
+	balance    int64
+	assets     map[string]int64 // TRC10 余额
+	calls      []string
+}
+
+func (f *fakeTronResources) GetAccount(addr string) (*troncore.Account, error) {
+	return &troncore.Account{Balance: f.balance, AssetV2: f.assets}, nil
+}
+
+func (f *fakeTronResources) GetAccountResource(addr string) (*tronapi.AccountResourceMessage, error) {
+	return f.resources, nil
+}
+
+func (f *fakeTronResources) TRC20Call(from, contractAddress, data string, constant bool, feeLimit int64) (*tronapi.TransactionExtention, error) {
+	f.calls = append(f.calls, data)
+	result := &tronapi.TransactionExtention{EnergyUsed: f.energyUsed, Transaction: &troncore.Transaction{}}
+	if f.revert {
+		result.Transaction.Ret = []*troncore.Transaction_Result{{ContractRet: troncore.Transaction_Result_REVERT}}
+	}
+	return result, nil
+}
+
+func (f *fakeTronResources) GetEnergyPrices() (*tronapi.PricesResponseMessage, error) {
+	return &tronapi.PricesResponseMessage{Prices: "0:100,1575158400000:10,1729308600000:210"}, nil
+}
+
+func (f *fakeTronResources) GetBandwidthPrices() (*tronapi.PricesResponseMessage, error) {
+	return &tronapi.PricesResponseMessage{Prices: "0:10,1606537680000:40,1613833200000:1000"}, nil
+}
+
+func TestTronFeeLimit(t *testing.T) {
+	svc := &PayoutService{cfg: &config.Config{TRC20FeeLimit: 50_000_000}}
+	usdt := &queue.Job{
+		ID:           "job-1",
+		FromAddress:  "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+		ToAddress:    "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		TokenAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		Amount:       "1000000",
+	}
+	amount := big.NewInt(1_000_000)
+
+	// 无质押能量: 全部燃烧，fee_limit = 65000 * 1.2 * 210
+	client := &fakeTronResources{energyUsed: 65000, balance: 20_000_000, resources: &tronapi.AccountResourceMessage{FreeNetLimit: 600}}
+	feeLimit, err := svc.tronFeeLimit(client, usdt, amount)
+	require.NoError(t, err)
+	assert.EqualValues(t, 16_380_000, feeLimit)
+	require.Len(t, client.calls, 1)
+	assert.True(t, strings.HasPrefix(client.calls[0], "0xa9059cbb"))
+	assert.Len(t, client.calls[0], 2+8+64+64)
+
+	// 能量、带宽和余额都不足: 提示质押
+	client.balance = 5_000_000
+	client.resources = &tronapi.AccountResourceMessage{EnergyLimit: 10000, FreeNetLimit: 600, FreeNetUsed: 500}
+	_, err = svc.tronFeeLimit(client, usdt, amount)
+	require.Error(t, err)
+	assert.True(t, queue.IsPermanent(err))
+	assert.Equal(t, TronResourceCode, queue.ErrorCode(err))
+	assert.Contains(t, err.Error(), "needs 78000 energy (10000 available)")
+	assert.Contains(t, err.Error(), "Stake TRX")
+
+	// 质押能量足够时无需燃烧
+	client.resources = &tronapi.AccountResourceMessage{EnergyLimit: 100000, NetLimit: 5000}
+	_, err = svc.tronFeeLimit(client, usdt, amount)
+	assert.NoError(t, err)
+
+	// 估算超过 TRC20_FEE_LIMIT
+	client.energyUsed = 300000
+	client.balance = 100_000_000
+	_, err = svc.tronFeeLimit(client, usdt, amount)
+	assert.ErrorContains(t, err, "exceeds TRC20_FEE_LIMIT")
+
+	// 转账会回滚 (代币余额不足)
+	client.revert = true
+	_, err = svc.tronFeeLimit(client, usdt, amount)
+	assert.True(t, queue.IsPermanent(err))
+
+	// TRX 转账: 余额须覆盖金额和带宽燃烧
+	trx := &queue.Job{ID: "job-2", FromAddress: usdt.FromAddress, ToAddress: usdt.ToAddress, Amount: "1000000"}
+	client = &fakeTronResources{balance: 1_200_000, resources: &tronapi.AccountResourceMessage{}}
+	_, err = svc.tronFeeLimit(client, trx, big.NewInt(1_000_000))
+	assert.Equal(t, TronResourceCode, queue.ErrorCode(err))
+	client.resources = &tronapi.AccountResourceMessage{FreeNetLimit: 600}
+	feeLimit, err = svc.tronFeeLimit(client, trx, big.NewInt(1_000_000))
+	require.NoError(t, err)
+	assert.Zero(t, feeLimit)
+}
+
+func TestTRC10(t *testing.T) {
+	assert.True(t, isTRC10Asset("1002000"))
+	assert.False(t, isTRC10Asset("01002000"))
+	assert.False(t, isTRC10Asset("1000"))
+	assert.False(t, isTRC10Asset("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))
+
+	item := PayoutItem{RecipientAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Amount: "1000000", TokenID: "1002000"}
+	assert.NoError(t, validateTRC10Item(item, true))
+	assert.ErrorContains(t, validateTRC10Item(item, false), "only supported on TRON")
+	withContract := item
+	withContract.TokenAddress = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	assert.ErrorContains(t, validateTRC10Item(withContract, true), "mutually exclusive")
+	tooLarge := item
+	tooLarge.Amount = "9223372036854775808"
+	assert.ErrorContains(t, validateTRC10Item(tooLarge, true), "exceeds the TRC10 maximum")
+	invalid := item
+	invalid.Amount = "1e6"
+	assert.ErrorContains(t, validateTRC10Item(invalid, true), "invalid TRC10 amount")
+
+	// 请求校验阶段即拒绝超出 int64 的 TRC10 金额，不进入队列
+	tronSvc := &PayoutService{
+		cfg:         &config.Config{},
+		tronClients: map[uint64]*tronclient.GrpcClient{728126428: tronclient.NewGrpcClient("127.0.0.1:50051")},
+	}
+	req := &BatchPayoutRequest{
+		BatchID:     "batch-1",
+		UserID:      "user-1",
+		ChainID:     728126428,
+		FromAddress: "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+		Items:       []PayoutItem{tooLarge},
+	}
+	assert.ErrorContains(t, tronSvc.validateRequest(context.Background(), req), "item[0]: amount 9223372036854775808 exceeds the TRC10 maximum")
+
+	// 只消耗带宽: 无 fee_limit，不调用 TRC20 估算
+	svc := &PayoutService{cfg: &config.Config{TRC20FeeLimit: 50_000_000}}
+	job := &queue.Job{
+		ID:          "job-1",
+		FromAddress: "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+		ToAddress:   "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		TokenID:     "1002000",
+		Amount:      "1000000",
+	}
+	assert.Equal(t, "1002000", job.Asset())
+	client := &fakeTronResources{balance: 0, assets: map[string]int64{"1002000": 5_000_000}, resources: &tronapi.AccountResourceMessage{FreeNetLimit: 600}}
+	feeLimit, err := svc.tronFeeLimit(client, job, big.NewInt(1_000_000))
+	require.NoError(t, err)
+	assert.Zero(t, feeLimit)
+	assert.Empty(t, client.calls)
+
+	// 免费带宽用尽: 燃烧的 TRX 由余额支付，转账金额不计入 TRX
+	client.resources = &tronapi.AccountResourceMessage{}
+	_, err = svc.tronFeeLimit(client, job, big.NewInt(1_000_000))
+	assert.Equal(t, TronResourceCode, queue.ErrorCode(err))
+	client.balance = tronTRC10TxBytes * tronDefaultNetSun
+	_, err = svc.tronFeeLimit(client, job, big.NewInt(1_000_000))
+	assert.NoError(t, err)
+
+	// 资产余额不足
+	_, err = svc.tronFeeLimit(client, job, big.NewInt(6_000_000))
+	require.Error(t, err)
+	assert.True(t, queue.IsPermanent(err))
+	assert.Contains(t, err.Error(), "TRC10 asset 1002000")
+}
+
+// fakeTronTxInfo 第 pendingPolls 次查询之前交易未上链
+type fakeTronTxInfo struct {
+	pendingPolls int
+	info         *troncore.TransactionInfo
+}
+
+func (f *fakeTronTxInfo) GetTransactionInfoByID(id string) (*troncore.TransactionInfo, error) {
+	if f.pendingPolls > 0 {
+		f.pendingPolls--
+		return nil, errors.New("transaction info not found")
+	}
+	return f.info, nil
+}
+
+// fakeTronHead 每次查询最新区块前进一个块 (stalled 时不前进)
+type fakeTronHead struct {
+	head    int64
+	stalled bool
+}
+
+func (f *fakeTronHead) GetNowBlock() (*tronapi.BlockExtention, error) {
+	if !f.stalled {
+		f.head++
+	}
+	return &tronapi.BlockExtention{BlockHeader: &troncore.BlockHeader{RawData: &troncore.BlockHeaderRaw{Number: f.head}}}, nil
+}
+
+func TestConfirmationCount(t *testing.T) {
+	assert.EqualValues(t, 1, confirmationCount(100, 100))
+	assert.EqualValues(t, 12, confirmationCount(111, 100))
+	assert.Zero(t, confirmationCount(99, 100), "lagging node")
+}
+
+func TestTronConfirmation(t *testing.T) {
+	prev := tronConfirmPollInterval
+	tronConfirmPollInterval = time.Millisecond
+	defer func() { tronConfirmPollInterval = prev }()
+
+	svc := &PayoutService{cfg: &config.Config{}}
+	ctx := context.Background()
+
+	t.Run("waits for the block", func(t *testing.T) {
+		client := &fakeTronTxInfo{pendingPolls: 2, info: &troncore.TransactionInfo{BlockNumber: 61_000_123}}
+		info, err := svc.waitForTronConfirmation(ctx, client, "abc", time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.EqualValues(t, 61_000_123, info.GetBlockNumber())
+		assert.NoError(t, tronExecutionError("abc", info), "TRX transfers have a DEFAULT receipt result")
+	})
+
+	t.Run("timeout reports no info", func(t *testing.T) {
+		client := &fakeTronTxInfo{pendingPolls: 1 << 30}
+		info, err := svc.waitForTronConfirmation(ctx, client, "abc", 20*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Nil(t, info)
+
+		info, err = svc.waitForTronConfirmation(ctx, client, "abc", 0)
+		assert.NoError(t, err)
+		assert.Nil(t, info, "waiting disabled")
+	})
+
+	t.Run("waits for confirmation depth", func(t *testing.T) {
+		client := &fakeTronHead{head: 100}
+		assert.True(t, svc.waitForTronDepth(ctx, client, 100, 1, time.Second), "one confirmation is inclusion")
+		assert.True(t, svc.waitForTronDepth(ctx, client, 100, 19, time.Second))
+		assert.GreaterOrEqual(t, client.head, int64(118))
+
+		stalled := &fakeTronHead{head: 100, stalled: true}
+		assert.False(t, svc.waitForTronDepth(ctx, stalled, 100, 19, 20*time.Millisecond))
+	})
+
+	t.Run("execution results", func(t *testing.T) {
+		ok := &troncore.TransactionInfo{BlockNumber: 10, Receipt: &troncore.ResourceReceipt{Result: troncore.Transaction_Result_SUCCESS}}
+		assert.NoError(t, tronExecutionError("abc", ok))
+
+		stringType, _ := abi.NewType("string", "", nil)
+		data, err := abi.Arguments{{Type: stringType}}.Pack("ERC20: transfer amount exceeds balance")
+		require.NoError(t, err)
+		reverted := &troncore.TransactionInfo{
+			BlockNumber: 11,
+			Result:      troncore.TransactionInfo_FAILED,
+			ResMessage:  append(common.FromHex("0x08c379a0"), data...),
+			Receipt:     &troncore.ResourceReceipt{Result: troncore.Transaction_Result_REVERT},
+		}
+		err = tronExecutionError("abc", reverted)
+		require.Error(t, err)
+		assert.True(t, queue.IsPermanent(err))
+		assert.Contains(t, err.Error(), "failed in block 11: REVERT")
+		assert.Contains(t, err.Error(), "transfer amount exceeds balance")
+
+		outOfEnergy := &troncore.TransactionInfo{
+			BlockNumber: 12,
+			Result:      troncore.TransactionInfo_FAILED,
+			Receipt:     &troncore.ResourceReceipt{Result: troncore.Transaction_Result_OUT_OF_ENERGY},
+		}
+		err = tronExecutionError("abc", outOfEnergy)
+		require.Error(t, err)
+		assert.False(t, queue.IsPermanent(err), "retried with a fresh fee estimate")
+	})
+}
+
+// fakeHistoryClient 固定的收款地址 nonce 和余额
+type fakeHistoryClient struct {
+	nonce   uint64
+	balance *big.Int
+}
+
+func (f *fakeHistoryClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return f.nonce, nil
+}
+
+func (f *fakeHistoryClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return f.balance, nil
+}
+
+// fakeTronAccount GetAccount 返回固定账户或错误
+type fakeTronAccount struct {
+	account *troncore.Account
+	err     error
+}
+
+func (f *fakeTronAccount) GetAccount(addr string) (*troncore.Account, error) {
+	return f.account, f.err
+}
+
+func TestRecipientActivity(t *testing.T) {
+	ctx := context.Background()
+	cfg := activity.Config{Enabled: true, MinAge: 7 * 24 * time.Hour, DormantAfter: 365 * 24 * time.Hour}
+	now := time.Now()
+
+	// EVM 未配置浏览器: nonce 和余额均为 0 视为全新地址
+	h, err := evmHistory(ctx, &fakeHistoryClient{balance: big.NewInt(0)}, nil, 1, "0x000000000000000000000000000000000000dEaD")
+	require.NoError(t, err)
+	flagged := cfg.Check("0xdead", h, now)
+	require.NotNil(t, flagged)
+	assert.Equal(t, []string{activity.ReasonNew}, flagged.Reasons)
+
+	h, err = evmHistory(ctx, &fakeHistoryClient{nonce: 12, balance: big.NewInt(0)}, nil, 1, "0x000000000000000000000000000000000000dEaD")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Check("0xdead", h, now))
+
+	// TRON 未激活账户
+	h, err = tronHistory(&fakeTronAccount{err: errors.New("account not found")}, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Check("T", h, now))
+	_, err = tronHistory(&fakeTronAccount{err: errors.New("connection refused")}, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	assert.Error(t, err)
+
+	// TRON 账户两年未操作
+	account := &troncore.Account{
+		Balance:            5_000_000,
+		CreateTime:         now.AddDate(-3, 0, 0).UnixMilli(),
+		LatestOprationTime: now.AddDate(-2, 0, 0).UnixMilli(),
+	}
+	h, err = tronHistory(&fakeTronAccount{account: account}, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	require.NoError(t, err)
+	flagged = cfg.Check("T", h, now)
+	require.NotNil(t, flagged)
+	assert.Equal(t, []string{activity.ReasonDormant}, flagged.Reasons)
+	assert.Equal(t, activity.Code, queue.ErrorCode(queue.Permanent(flagged)))
+
+	// 门槛以下不检查；人工确认后放行
+	svc := &PayoutService{cfg: &config.Config{RecipientActivity: cfg}, activityThreshold: big.NewRat(1000, 1)}
+	small := &queue.Job{ID: "job-1", ChainID: 1, ToAddress: "0xdead", Amount: "999", TokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", TokenDecimals: 0}
+	assert.NoError(t, svc.checkRecipientActivity(ctx, small))
+	approved := &queue.Job{ID: "job-2", ChainID: 1, ToAddress: "0xdead", Amount: "5000", TokenAddress: small.TokenAddress,
+		RecipientApproval: &queue.PolicyOverride{ApprovedBy: "ops", Reason: "confirmed"}}
+	assert.NoError(t, svc.checkRecipientActivity(ctx, approved))
+}
+
+func TestGasTank(t *testing.T) {
+	min, target, err := config.ChainGasTank{MinBalance: "100"}.Thresholds()
+	require.NoError(t, err)
+	assert.Equal(t, "200", target.String(), "defaults to twice the minimum")
+
+	// 低于阈值时补充到目标余额
+	assert.Nil(t, gasTankRefill(big.NewInt(100), min, target))
+	assert.Equal(t, "160", gasTankRefill(big.NewInt(40), min, target).String())
+	assert.Equal(t, "200", gasTankRefill(big.NewInt(0), min, target).String())
+
+	_, _, err = config.ChainGasTank{MinBalance: "100", TargetBalance: "50"}.Thresholds()
+	assert.Error(t, err)
+	_, _, err = config.ChainGasTank{MinBalance: "0.1"}.Thresholds()
+	assert.Error(t, err)
+
+	// 付款钱包在前，去重并排除资金钱包自身
+	addresses := gasTankAddresses("0xAAAA", "0xFFFF", []string{"0xaaaa", "0xBBBB", "0xffff", ""})
+	assert.Equal(t, []string{"0xAAAA", "0xBBBB"}, addresses)
+	assert.Equal(t, []string{"0xBBBB"}, gasTankAddresses("", "0xFFFF", []string{"0xBBBB"}))
+}
+
+func TestSweep(t *testing.T) {
+	ceiling, target, err := config.SweepCeiling{Ceiling: "1000"}.Limits()
+	require.NoError(t, err)
+	assert.Equal(t, "1000", target.String(), "target defaults to the ceiling")
+
+	// 超过上限时转出高于保留余额的部分
+	assert.Nil(t, sweepAmount(big.NewInt(1000), ceiling, target))
+	assert.Equal(t, "500", sweepAmount(big.NewInt(1500), ceiling, target).String())
+
+	ceiling, target, err = config.SweepCeiling{Ceiling: "1000", Target: "200"}.Limits()
+	require.NoError(t, err)
+	assert.Nil(t, sweepAmount(big.NewInt(900), ceiling, target))
+	assert.Equal(t, "1300", sweepAmount(big.NewInt(1500), ceiling, target).String())
+
+	_, _, err = config.SweepCeiling{Ceiling: "1000", Target: "2000"}.Limits()
+	assert.Error(t, err)
+	_, _, err = config.SweepCeiling{Ceiling: "0"}.Limits()
+	assert.Error(t, err)
+
+	// 原生币归集任务
+	chainCfg := config.ChainConfig{Type: "evm", NativeToken: "ETH", Decimals: 18,
+		Sweep: config.ChainSweep{ColdAddress: "0x00000000000000000000000000000000000000c0"}}
+	svc := &PayoutService{cfg: &config.Config{}}
+	job, err := svc.sweepJob(context.Background(), 1, chainCfg, "0x00000000000000000000000000000000000000a0", config.SweepNative, big.NewInt(500))
+	require.NoError(t, err)
+	assert.True(t, job.Sweep)
+	assert.Equal(t, sweepUserID, job.UserID)
+	assert.Equal(t, chainCfg.Sweep.ColdAddress, job.ToAddress)
+	assert.Equal(t, "500", job.Amount)
+	assert.Equal(t, "ETH", job.TokenSymbol)
+	assert.Empty(t, job.TokenAddress)
+
+	assert.True(t, validChainAddress("evm", chainCfg.Sweep.ColdAddress))
+	assert.False(t, validChainAddress("tron", chainCfg.Sweep.ColdAddress))
+}
+
+func TestScheduledBatch(t *testing.T) {
+	executeAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	req := &BatchPayoutRequest{
+		BatchID: "payroll-2026-11", UserID: "user-1", FromAddress: "0xabc", ChainID: 1,
+		Items:     []PayoutItem{{ID: "p1", RecipientAddress: "0xdef", Amount: "1000"}, {ID: "p2", RecipientAddress: "0x123", Amount: "2000"}},
+		ExecuteAt: executeAt,
+	}
+	assert.True(t, deferred(req))
+	assert.False(t, deferred(&BatchPayoutRequest{ExecuteAt: time.Now().Add(-time.Minute)}), "past execute_at runs immediately")
+	assert.False(t, deferred(&BatchPayoutRequest{ExecuteAt: executeAt, Simulate: true}), "simulation never waits")
+
+	// 到期后按保存的请求入队
+	batch, err := newScheduledBatch(correlation.WithID(context.Background(), "sdk-req-1"), req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Items)
+	assert.Equal(t, "sdk-req-1", batch.CorrelationID)
+	var decoded BatchPayoutRequest
+	require.NoError(t, json.Unmarshal(batch.Request, &decoded))
+	assert.Equal(t, req.Items, decoded.Items)
+	assert.True(t, decoded.ExecuteAt.Equal(executeAt))
+
+	svc := &PayoutService{cfg: &config.Config{}}
+	resp := svc.scheduledResponse(req)
+	assert.Equal(t, BatchStatusScheduled, resp.Status)
+	assert.Contains(t, resp.Message, "Scheduled 2 payments")
+	assert.Empty(t, resp.ManifestHash, "manifest is signed when the batch is queued")
+}
+
+func TestPlanNonceRepair(t *testing.T) {
+	now := time.Now()
+	grace := 5 * time.Minute
+	old := now.Add(-10 * time.Minute)
+	tx := func(nonce uint64, sentAt time.Time) *queue.PendingTx {
+		return &queue.PendingTx{JobID: fmt.Sprintf("job-%d", nonce), Nonce: nonce, SentAt: sentAt}
+	}
+	nonces := func(txs []*queue.PendingTx) []uint64 {
+		var out []uint64
+		for _, p := range txs {
+			out = append(out, p.Nonce)
+		}
+		return out
+	}
+
+	t.Run("no gap", func(t *testing.T) {
+		// 节点已收到全部交易
+		assert.Nil(t, planNonceRepair([]*queue.PendingTx{tx(5, old), tx(6, old)}, 5, 7, now, grace))
+	})
+
+	t.Run("dropped tx is rebroadcast and missing nonce filled", func(t *testing.T) {
+		// 5 已上链，6 被丢弃，7 没有记录，8 卡在空缺之后
+		pending := []*queue.PendingTx{tx(4, old), tx(6, old), tx(8, old)}
+		plan := planNonceRepair(pending, 5, 6, now, grace)
+		require.NotNil(t, plan)
+		assert.Equal(t, []uint64{6, 8}, nonces(plan.rebroadcast))
+		assert.Equal(t, []uint64{7}, plan.fill)
+		assert.Equal(t, []uint64{8}, nonces(plan.blocked))
+	})
+
+	t.Run("recent txs are left alone", func(t *testing.T) {
+		// 刚发送的交易可能尚未传播到节点
+		assert.Nil(t, planNonceRepair([]*queue.PendingTx{tx(7, now)}, 5, 5, now, grace))
+	})
+
+	t.Run("private tx stays private", func(t *testing.T) {
+		until := now.Add(time.Minute)
+		private := tx(6, old)
+		private.PrivateUntil = &until
+		plan := planNonceRepair([]*queue.PendingTx{private, tx(7, old)}, 5, 5, now, grace)
+		require.NotNil(t, plan)
+		assert.Equal(t, []uint64{5}, plan.fill)
+		assert.Equal(t, []uint64{7}, nonces(plan.rebroadcast))
+	})
+}
+
+func TestBatchApproval(t *testing.T) {
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"prices": [{"token": "`+usdc+`", "usd": "1"}, {"token": "native", "usd": "2000"}]}`), 0o600))
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	policy, err := approval.New(approval.Config{ThresholdUSD: "100000", Operators: []string{crypto.PubkeyToAddress(key.PublicKey).Hex()}, PricesFile: path})
+	require.NoError(t, err)
+
+	svc := &PayoutService{cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+		8453:  {Decimals: 18},
+		84532: {Decimals: 18, Testnet: true},
+	}}, approval: policy}
+	batch := func(chainID uint64, items ...PayoutItem) *BatchPayoutRequest {
+		return &BatchPayoutRequest{BatchID: "b1", UserID: "u1", ChainID: chainID, Items: items}
+	}
+	stable := func(amount string) PayoutItem {
+		return PayoutItem{RecipientAddress: "0xdef", Amount: amount, TokenAddress: usdc, TokenDecimals: 6}
+	}
+	native := PayoutItem{RecipientAddress: "0xdef", Amount: "30000000000000000000"} // 30 ETH
+
+	value, unpriced := svc.batchValueUSD(batch(8453, stable("40000000000"), native))
+	assert.Equal(t, "100000.00", value.FloatString(2))
+	assert.Empty(t, unpriced)
+	assert.True(t, svc.requiresApproval(batch(8453, stable("40000000000"), native)))
+	assert.False(t, svc.requiresApproval(batch(8453, stable("99999000000"))))
+
+	// 未定价的代币无法估值，须审批
+	unknown := PayoutItem{RecipientAddress: "0xdef", Amount: "1", TokenAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7", TokenDecimals: 6}
+	_, unpriced = svc.batchValueUSD(batch(8453, unknown, unknown))
+	assert.Equal(t, []string{"0xdAC17F958D2ee523a2206206994597C13D831ec7"}, unpriced)
+	assert.True(t, svc.requiresApproval(batch(8453, unknown)))
+
+	// 测试网和未启用审批时不审批
+	assert.False(t, svc.requiresApproval(batch(84532, unknown)))
+	assert.False(t, (&PayoutService{cfg: &config.Config{}}).requiresApproval(batch(8453, unknown)))
+}
+
+// fakePermitToken answers the EIP-2612 read calls of a single token
+type fakePermitToken struct {
+	domain    common.Hash
+	nonce     *big.Int
+	allowance *big.Int
+}
+
+func (f *fakePermitToken) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (f *fakePermitToken) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed, _ := abi.JSON(strings.NewReader(permitABI))
+	method, err := parsed.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "DOMAIN_SEPARATOR":
+		if f.domain == (common.Hash{}) {
+			return nil, errors.New("execution reverted")
+		}
+		return method.Outputs.Pack([32]byte(f.domain))
+	case "nonces":
+		return method.Outputs.Pack(f.nonce)
+	default:
+		return method.Outputs.Pack(f.allowance)
+	}
+}
+
+func TestPermit(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(permitABI))
+	require.NoError(t, err)
+	svc := &PayoutService{permitABI: parsed}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	spender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	domain := crypto.Keccak256Hash([]byte("domain"))
+	deadline := time.Now().Add(time.Hour).Unix()
+
+	sign := func(nonce int64) *queue.Permit {
+		digest := permitDigest(domain, owner, spender, big.NewInt(1000), big.NewInt(nonce), big.NewInt(deadline))
+		sig, err := crypto.Sign(digest.Bytes(), key)
+		require.NoError(t, err)
+		return &queue.Permit{
+			Owner:    owner.Hex(),
+			Value:    "1000",
+			Deadline: deadline,
+			V:        sig[64] + 27,
+			R:        hexutil.Encode(sig[:32]),
+			S:        hexutil.Encode(sig[32:64]),
+		}
+	}
+
+	t.Run("parse", func(t *testing.T) {
+		p := sign(0)
+		p.V -= 27 // 0/1 同样接受
+		permit, err := parsePermit(p)
+		require.NoError(t, err)
+		assert.True(t, permit.v == 27 || permit.v == 28)
+
+		for _, bad := range []queue.Permit{
+			{Owner: "0x123", Value: "1", Deadline: deadline, R: p.R, S: p.S},
+			{Owner: owner.Hex(), Value: "0", Deadline: deadline, R: p.R, S: p.S},
+			{Owner: owner.Hex(), Value: "1", R: p.R, S: p.S},
+			{Owner: owner.Hex(), Value: "1", Deadline: deadline, V: 29, R: p.R, S: p.S},
+			{Owner: owner.Hex(), Value: "1", Deadline: deadline, R: "0x01", S: p.S},
+		} {
+			_, err := parsePermit(&bad)
+			assert.Error(t, err, "%+v", bad)
+		}
+	})
+
+	t.Run("signature from owner at current nonce", func(t *testing.T) {
+		permit, err := parsePermit(sign(3))
+		require.NoError(t, err)
+		contract := &fakePermitToken{domain: domain, nonce: big.NewInt(3), allowance: new(big.Int)}
+		assert.NoError(t, svc.verifyPermit(context.Background(), contract, token, spender, permit, big.NewInt(1000)))
+
+		// nonce 不符 (签名作废)
+		contract.nonce = big.NewInt(4)
+		assert.ErrorIs(t, svc.verifyPermit(context.Background(), contract, token, spender, permit, big.NewInt(1000)), errPermitSignature)
+
+		// 授权已提交，额度仍覆盖批次
+		contract.allowance = big.NewInt(1000)
+		assert.NoError(t, svc.verifyPermit(context.Background(), contract, token, spender, permit, big.NewInt(1000)))
+	})
+
+	t.Run("other spender rejected", func(t *testing.T) {
+		permit, err := parsePermit(sign(0))
+		require.NoError(t, err)
+		contract := &fakePermitToken{domain: domain, nonce: new(big.Int), allowance: new(big.Int)}
+		err = svc.verifyPermit(context.Background(), contract, token, common.HexToAddress("0x2222222222222222222222222222222222222222"), permit, big.NewInt(1000))
+		assert.ErrorIs(t, err, errPermitSignature)
+	})
+
+	t.Run("token without permit", func(t *testing.T) {
+		permit, err := parsePermit(sign(0))
+		require.NoError(t, err)
+		err = svc.verifyPermit(context.Background(), &fakePermitToken{}, token, spender, permit, big.NewInt(1000))
+		assert.ErrorIs(t, err, errPermitUnsupported)
+	})
+}
+
+// fakeAuthorizationToken answers the EIP-3009 read calls of a single token
+type fakeAuthorizationToken struct {
+	domain common.Hash
+	used   bool
+}
+
+func (f *fakeAuthorizationToken) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (f *fakeAuthorizationToken) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed, _ := abi.JSON(strings.NewReader(authorizationABI))
+	method, err := parsed.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, errors.New("execution reverted")
+	}
+	if method.Name == "DOMAIN_SEPARATOR" {
+		return method.Outputs.Pack([32]byte(f.domain))
+	}
+	return method.Outputs.Pack(f.used)
+}
+
+func TestRelayAuthorization(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(authorizationABI))
+	require.NoError(t, err)
+	svc := &PayoutService{cfg: &config.Config{}, eip3009ABI: parsed}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := "0x2222222222222222222222222222222222222222"
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	domain := crypto.Keccak256Hash([]byte("usdc-base"))
+	now := time.Now().Unix()
+
+	sign := func(value string) *queue.Authorization {
+		auth := &queue.Authorization{
+			From:        from.Hex(),
+			ValidAfter:  now - 60,
+			ValidBefore: now + 3600,
+			Nonce:       hexutil.Encode(crypto.Keccak256([]byte("nonce-1"))),
+			Signature:   hexutil.Encode(make([]byte, 65)),
+		}
+		p, err := parseAuthorization(usdc, to, value, auth)
+		require.NoError(t, err)
+		sig, err := crypto.Sign(authorizationDigest(domain, p).Bytes(), key)
+		require.NoError(t, err)
+		sig[64] += 27
+		auth.Signature = hexutil.Encode(sig)
+		return auth
+	}
+
+	t.Run("parse", func(t *testing.T) {
+		good := sign("1000")
+		for name, tc := range map[string]struct {
+			token, to, value string
+			mutate           func(a *queue.Authorization)
+		}{
+			"token":     {"usdc", to, "1", nil},
+			"to":        {usdc, "0x0000000000000000000000000000000000000000", "1", nil},
+			"value":     {usdc, to, "0", nil},
+			"window":    {usdc, to, "1", func(a *queue.Authorization) { a.ValidBefore = a.ValidAfter }},
+			"nonce":     {usdc, to, "1", func(a *queue.Authorization) { a.Nonce = "0x01" }},
+			"signature": {usdc, to, "1", func(a *queue.Authorization) { a.Signature = "0x01" }},
+		} {
+			auth := *good
+			if tc.mutate != nil {
+				tc.mutate(&auth)
+			}
+			_, err := parseAuthorization(tc.token, tc.to, tc.value, &auth)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("signature from authorizer", func(t *testing.T) {
+		contract := &fakeAuthorizationToken{domain: domain}
+		p, err := parseAuthorization(usdc, to, "1000", sign("1000"))
+		require.NoError(t, err)
+		assert.NoError(t, svc.verifyAuthorizationSignature(context.Background(), contract, common.HexToAddress(usdc), p))
+
+		// 金额被篡改
+		p, err = parseAuthorization(usdc, to, "2000", sign("1000"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, svc.verifyAuthorizationSignature(context.Background(), contract, common.HexToAddress(usdc), p), errAuthorizationSignature)
+
+		// 其他代币的 domain
+		p, err = parseAuthorization(usdc, to, "1000", sign("1000"))
+		require.NoError(t, err)
+		other := &fakeAuthorizationToken{domain: crypto.Keccak256Hash([]byte("other"))}
+		assert.ErrorIs(t, svc.verifyAuthorizationSignature(context.Background(), other, common.HexToAddress(usdc), p), errAuthorizationSignature)
+	})
+
+	t.Run("used nonce", func(t *testing.T) {
+		p, err := parseAuthorization(usdc, to, "1000", sign("1000"))
+		require.NoError(t, err)
+		used, err := svc.authorizationUsed(context.Background(), &fakeAuthorizationToken{used: true}, common.HexToAddress(usdc), p)
+		require.NoError(t, err)
+		assert.True(t, used)
+		assert.Equal(t, x402JobID(8453, common.HexToAddress(usdc), p), x402JobID(8453, common.HexToAddress(usdc), p))
+		assert.NotEqual(t, x402JobID(8453, common.HexToAddress(usdc), p), x402JobID(1, common.HexToAddress(usdc), p))
+	})
+
+	t.Run("calldata", func(t *testing.T) {
+		job := &queue.Job{ToAddress: to, Amount: "1000", TokenAddress: usdc, Authorization: sign("1000")}
+		data, err := svc.packAuthorization(job)
+		require.NoError(t, err)
+		assert.Equal(t, parsed.Methods["transferWithAuthorization"].ID, data[:4])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := svc.RelayAuthorization(context.Background(), &RelayAuthorizationRequest{ChainID: 8453})
+		assert.True(t, IsFailedPrecondition(err))
+	})
+}
+
+// fakeForwarder answers ERC2771Forwarder.verify / nonces
+type fakeForwarder struct {
+	valid bool
+	nonce *big.Int
+}
+
+func (f *fakeForwarder) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (f *fakeForwarder) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed, _ := abi.JSON(strings.NewReader(forwarderABI))
+	method, err := parsed.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, errors.New("execution reverted")
+	}
+	if method.Name == "verify" {
+		return method.Outputs.Pack(f.valid)
+	}
+	return method.Outputs.Pack(f.nonce)
+}
+
+func TestRelayForward(t *testing.T) {
+	erc20, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	forwarderParsed, err := abi.JSON(strings.NewReader(forwarderABI))
+	require.NoError(t, err)
+	forwarder := "0x3333333333333333333333333333333333333333"
+	svc := &PayoutService{
+		cfg:          &config.Config{Chains: map[uint64]config.ChainConfig{8453: {ChainID: 8453, Type: "evm", Forwarders: []string{forwarder}}}},
+		erc20ABI:     erc20,
+		forwarderABI: forwarderParsed,
+	}
+
+	recipient := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	data, err := erc20.Pack("transfer", recipient, big.NewInt(1000))
+	require.NoError(t, err)
+	valid := RelayForwardRequest{
+		ChainID:   8453,
+		Forwarder: forwarder,
+		From:      "0x1111111111111111111111111111111111111111",
+		Token:     "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb",
+		Gas:       100000,
+		Deadline:  time.Now().Add(time.Hour).Unix(),
+		Data:      hexutil.Encode(data),
+		Signature: hexutil.Encode(make([]byte, 65)),
+	}
+
+	t.Run("trusted forwarders per chain", func(t *testing.T) {
+		assert.True(t, svc.forwarderTrusted(8453, "0x3333333333333333333333333333333333333333"))
+		assert.False(t, svc.forwarderTrusted(1, forwarder))
+		assert.False(t, svc.forwarderTrusted(8453, "0x4444444444444444444444444444444444444444"))
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		req, to, amount, err := svc.parseForwardRequest(&valid)
+		require.NoError(t, err)
+		assert.Equal(t, recipient, to)
+		assert.Equal(t, "1000", amount.String())
+		assert.Equal(t, data, req.Data)
+
+		balanceOf, err := erc20.Pack("balanceOf", recipient)
+		require.NoError(t, err)
+		for name, mutate := range map[string]func(r *RelayForwardRequest){
+			"native value": func(r *RelayForwardRequest) { r.Value = "1" },
+			"gas":          func(r *RelayForwardRequest) { r.Gas = maxForwardGas + 1 },
+			"not transfer": func(r *RelayForwardRequest) { r.Data = hexutil.Encode(balanceOf) },
+			"signature":    func(r *RelayForwardRequest) { r.Signature = "0x01" },
+			"from":         func(r *RelayForwardRequest) { r.From = "0x1" },
+		} {
+			req := valid
+			mutate(&req)
+			_, _, _, err := svc.parseForwardRequest(&req)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("forwarder verifies request", func(t *testing.T) {
+		req, _, _, err := svc.parseForwardRequest(&valid)
+		require.NoError(t, err)
+		assert.NoError(t, svc.verifyForwardRequest(context.Background(), &fakeForwarder{valid: true}, common.HexToAddress(forwarder), req))
+		assert.ErrorIs(t, svc.verifyForwardRequest(context.Background(), &fakeForwarder{}, common.HexToAddress(forwarder), req), errForwardRejected)
+
+		nonce, err := svc.forwardNonce(context.Background(), &fakeForwarder{nonce: big.NewInt(7)}, common.HexToAddress(forwarder), req.From)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), nonce.Int64())
+
+		// 同一 nonce 的不同签名为不同任务
+		other := *req
+		other.Signature = append([]byte{1}, req.Signature[1:]...)
+		assert.NotEqual(t, forwardJobID(8453, common.HexToAddress(forwarder), req, nonce), forwardJobID(8453, common.HexToAddress(forwarder), &other, nonce))
+	})
+
+	t.Run("execute calldata forwards signed data", func(t *testing.T) {
+		job := &queue.Job{
+			ToAddress:    recipient.Hex(),
+			Amount:       "1000",
+			TokenAddress: valid.Token,
+			Forward: &queue.ForwardRequest{
+				Forwarder: forwarder,
+				From:      valid.From,
+				Gas:       valid.Gas,
+				Deadline:  valid.Deadline,
+				Data:      valid.Data,
+				Signature: valid.Signature,
+			},
+		}
+		calldata, err := svc.packForward(job)
+		require.NoError(t, err)
+		method := forwarderParsed.Methods["execute"]
+		assert.Equal(t, method.ID, calldata[:4])
+		args, err := method.Inputs.Unpack(calldata[4:])
+		require.NoError(t, err)
+		require.Len(t, args, 1)
+		assert.Contains(t, fmt.Sprintf("%x", calldata), fmt.Sprintf("%x", data))
+	})
+}
+
+func TestTronBatches(t *testing.T) {
+	var batches tronBatches
+	job := func(id, batchID string) *queue.Job {
+		return &queue.Job{ID: id, BatchID: batchID, UserID: "user-1", ChainID: 728126428}
+	}
+
+	done1 := batches.begin(job("job-1", "batch-1"))
+	done2 := batches.begin(job("job-2", "batch-1"))
+	done3 := batches.begin(job("job-3", "batch-1"))
+	batches.begin(job("job-4", "batch-2"))
+
+	reports := batches.reports()
+	require.Len(t, reports, 2)
+	assert.Equal(t, 3, reports[1].InFlight)
+
+	done1(&queue.JobResult{Success: true, TxHash: "a1", GasCost: &queue.GasCost{Fee: "345000"}}, nil)
+	done2(&queue.JobResult{Success: false, TxHash: "a2", GasCost: &queue.GasCost{Fee: "27000000"}, Error: errors.New("REVERT")}, nil)
+	done3(nil, errors.New("node unavailable"))
+
+	reports = batches.reports()
+	batch := reports[1]
+	assert.Equal(t, "batch-1", batch.BatchID)
+	assert.Equal(t, 0, batch.InFlight)
+	assert.Equal(t, 2, batch.Broadcast)
+	assert.Equal(t, 1, batch.Confirmed)
+	assert.Equal(t, 2, batch.Failed)
+	assert.Equal(t, "27345000", batch.Fee)
+	assert.Equal(t, 1, reports[0].InFlight)
+}
+
+func TestFeeCap(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(8453), Nonce: 7, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(32), Gas: 60000, To: &to})
+
+	// 预计 60000 × (10 + 2) + L1 数据费 80000 = 800000
+	_, err := capTxFees(tx, big.NewInt(10), big.NewInt(80000), big.NewInt(799_999))
+	var capErr *FeeCapError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "800000", capErr.Estimated.String())
+	assert.Equal(t, FeeCapCode, queue.ErrorCode(err))
+	assert.False(t, queue.IsPermanent(err), "retried later when fees drop")
+
+	// 最坏情况 60000 × 32 + 80000 超过上限: maxFeePerGas 降到 (1000000 - 80000) / 60000
+	capped, err := capTxFees(tx, big.NewInt(10), big.NewInt(80000), big.NewInt(1_000_000))
+	require.NoError(t, err)
+	assert.EqualValues(t, 15, capped.GasFeeCap().Int64())
+	assert.EqualValues(t, 2, capped.GasTipCap().Int64())
+	assert.Equal(t, tx.Nonce(), capped.Nonce())
+
+	// 上限足够时不调整
+	same, err := capTxFees(tx, big.NewInt(10), big.NewInt(0), big.NewInt(2_000_000))
+	require.NoError(t, err)
+	assert.Same(t, tx, same)
+
+	_, err = parseMaxFee("0")
+	assert.Error(t, err)
+	maxFee, err := parseMaxFee("")
+	assert.NoError(t, err)
+	assert.Nil(t, maxFee)
+
+	// TRON: 按需燃烧的 TRX 超过上限
+	svc := &PayoutService{cfg: &config.Config{TRC20FeeLimit: 50_000_000}}
+	usdt := &queue.Job{
+		ID:           "job-1",
+		FromAddress:  "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+		ToAddress:    "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		TokenAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		Amount:       "1000000",
+		MaxFee:       "10000000",
+	}
+	client := &fakeTronResources{energyUsed: 65000, balance: 20_000_000, resources: &tronapi.AccountResourceMessage{FreeNetLimit: 600}}
+	_, err = svc.tronFeeLimit(client, usdt, big.NewInt(1_000_000))
+	assert.Equal(t, FeeCapCode, queue.ErrorCode(err))
+
+	// 质押能量足够时不燃烧
+	client.resources = &tronapi.AccountResourceMessage{EnergyLimit: 100000, NetLimit: 5000}
+	_, err = svc.tronFeeLimit(client, usdt, big.NewInt(1_000_000))
+	assert.NoError(t, err)
+
+	// 无法估算时 fee_limit 不超过上限
+	client.energyUsed = 0
+	feeLimit, err := svc.tronFeeLimit(client, usdt, big.NewInt(1_000_000))
+	require.NoError(t, err)
+	assert.EqualValues(t, 10_000_000, feeLimit)
+}
+
+func TestGasCeiling(t *testing.T) {
+	ceiling, err := config.ChainGasCeiling{MaxBaseFeeGwei: "30.5"}.MaxBaseFee()
+	require.NoError(t, err)
+	assert.Equal(t, "30500000000", ceiling.String())
+	_, err = config.ChainGasCeiling{MaxBaseFeeGwei: "0"}.MaxBaseFee()
+	assert.Error(t, err)
+
+	err = exceedsGasCeiling(&gas.Fees{BaseFee: big.NewInt(30_500_000_001), FeeCap: big.NewInt(50_000_000_000)}, ceiling)
+	var ceilErr *GasCeilingError
+	require.ErrorAs(t, err, &ceilErr)
+	assert.Equal(t, GasCeilingCode, queue.ErrorCode(err))
+	assert.False(t, queue.IsGasHold(err))
+	assert.True(t, queue.IsGasHold(queue.GasHold(err)))
+	assert.Equal(t, GasCeilingCode, queue.ErrorCode(queue.GasHold(err)))
+
+	// 只比较基础费，maxFeePerGas 的余量不计入
+	assert.NoError(t, exceedsGasCeiling(&gas.Fees{BaseFee: big.NewInt(30_500_000_000), FeeCap: big.NewInt(80_000_000_000)}, ceiling))
+	// 无基础费的链按 gas price 比较
+	assert.Error(t, exceedsGasCeiling(&gas.Fees{BaseFee: big.NewInt(0), FeeCap: big.NewInt(31_000_000_000)}, ceiling))
+}
+
+func TestBroadcastErrorPolicies(t *testing.T) {
+	s := &PayoutService{}
+	err := s.classifySendError(context.Background(), 1, common.Address{}, errors.New("replacement transaction underpriced"))
+	var broadcastErr *BroadcastError
+	require.ErrorAs(t, err, &broadcastErr)
+	assert.Equal(t, "TX_UNDERPRICED", queue.ErrorCode(err))
+
+	var hint queue.RetryHint
+	require.ErrorAs(t, fmt.Errorf("failed to send transaction: %w", err), &hint)
+	policy := queue.DefaultRetryPolicy.Merge(hint.RetryPolicy())
+	assert.Equal(t, 5, policy.MaxRetries)
+	assert.Equal(t, 15*time.Second, policy.Backoff(1))
+
+	// 未知错误按默认策略处理
+	plain := errors.New("execution reverted")
+	assert.Same(t, plain, s.classifySendError(context.Background(), 1, common.Address{}, plain))
+	// 已在交易池的交易不是失败，不单独重试
+	assert.NotErrorAs(t, s.classifySendError(context.Background(), 1, common.Address{}, errors.New("already known")), &broadcastErr)
+}
+
+func TestTreasuryAllowance(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(permitABI))
+	require.NoError(t, err)
+	svc := &PayoutService{permitABI: parsed}
+	job := &queue.Job{
+		ID:           "job-1",
+		FromAddress:  "0x1111111111111111111111111111111111111111",
+		ToAddress:    "0x2222222222222222222222222222222222222222",
+		TokenAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Amount:       "1000",
+		Treasury:     "0x3333333333333333333333333333333333333333",
+	}
+	assert.Equal(t, job.Treasury, job.Source())
+
+	err = svc.checkTreasuryAllowance(context.Background(), &fakePermitToken{allowance: big.NewInt(999)}, job)
+	var allowanceErr *TreasuryAllowanceError
+	require.ErrorAs(t, err, &allowanceErr)
+	assert.Equal(t, TreasuryAllowanceCode, queue.ErrorCode(err))
+	assert.False(t, queue.IsPermanent(err))
+	var hint queue.RetryHint
+	require.ErrorAs(t, err, &hint)
+	assert.Equal(t, treasuryAllowanceRetry, hint.RetryPolicy())
+
+	assert.NoError(t, svc.checkTreasuryAllowance(context.Background(), &fakePermitToken{allowance: big.NewInt(1000)}, job))
+
+	// 清单记录金库为源地址
+	body := queue.NewManifestBody([]*queue.Job{job})
+	assert.Equal(t, job.Treasury, body.Items[0].Source)
+}
+
+// fakeAggregator prices every swap at a fixed rate (buy = sell × rate)
+type fakeAggregator struct{ rate int64 }
+
+func (f *fakeAggregator) Price(ctx context.Context, chainID uint64, sellToken, buyToken string, sellAmount *big.Int) (*big.Int, error) {
+	return new(big.Int).Mul(sellAmount, big.NewInt(f.rate)), nil
+}
+
+func (f *fakeAggregator) Quote(ctx context.Context, req swap.QuoteRequest) (*swap.Quote, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeAggregator) Provider() string { return "fake" }
+
+func TestSwapPayout(t *testing.T) {
+	const (
+		usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+		usdt = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+	)
+	svc := &PayoutService{
+		cfg:     &config.Config{Swap: swap.Config{MaxSlippageBps: 300}},
+		clients: map[uint64]*rpcpool.Pool{1: nil},
+		swapper: &fakeAggregator{rate: 1},
+	}
+	newReq := func() *BatchPayoutRequest {
+		return &BatchPayoutRequest{
+			ChainID:     1,
+			FromAddress: "0x1111111111111111111111111111111111111111",
+			Items: []PayoutItem{
+				{ID: "a", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "100", TokenAddress: usdt},
+				{ID: "b", RecipientAddress: "0x3333333333333333333333333333333333333333", Amount: "200", TokenAddress: usdt},
+			},
+			Swap: &queue.Swap{SellToken: usdc},
+		}
+	}
+
+	req := newReq()
+	require.NoError(t, svc.validateSwap(req))
+	assert.Equal(t, &queue.Swap{SellToken: usdc, MaxSlippageBps: 300}, svc.batchSwap(req), "unset slippage uses the configured limit")
+	req.Swap.MaxSlippageBps = 50
+	assert.Equal(t, 50, svc.batchSwap(req).MaxSlippageBps)
+
+	for name, mutate := range map[string]func(*BatchPayoutRequest){
+		"slippage above limit": func(r *BatchPayoutRequest) { r.Swap.MaxSlippageBps = 301 },
+		"native sell token":    func(r *BatchPayoutRequest) { r.Swap.SellToken = "" },
+		"sell payout token":    func(r *BatchPayoutRequest) { r.Swap.SellToken = usdt },
+		"mixed payout tokens":  func(r *BatchPayoutRequest) { r.Items[1].TokenAddress = usdc },
+		"native payout":        func(r *BatchPayoutRequest) { r.Items[1].TokenAddress = "" },
+		"with treasury":        func(r *BatchPayoutRequest) { r.UseTreasury = true },
+		"simulated":            func(r *BatchPayoutRequest) { r.Simulate = true },
+		"non-EVM chain":        func(r *BatchPayoutRequest) { r.ChainID = 728126428 },
+	} {
+		r := newReq()
+		mutate(r)
+		assert.Error(t, svc.validateSwap(r), name)
+	}
+
+	// 未配置聚合器
+	assert.Error(t, (&PayoutService{cfg: svc.cfg, clients: svc.clients}).validateSwap(newReq()))
+
+	// approve + 兑换的预留按代币转账的预留折算
+	assert.Equal(t, big.NewInt(swapApproveGas+swapGas), swapReservation(big.NewInt(erc20TransferGas)))
+}
+
+func TestCrossChainRoute(t *testing.T) {
+	const baseUSDC = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	clients := map[uint64]*rpcpool.Pool{}
+	for _, id := range []uint64{1, 8453, 56} {
+		pool, err := rpcpool.Dial(context.Background(), id, []string{"http://127.0.0.1:8545"}, rpcpool.Config{})
+		require.NoError(t, err)
+		defer pool.Close()
+		clients[id] = pool
+	}
+	svc := &PayoutService{
+		cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+			1: {ChainID: 1, Type: "evm", CCTP: config.ChainCCTP{Domain: 0, TokenMessenger: "0xBd3fa81B58Ba92a82136038B25aDec7066af3155",
+				MessageTransmitter: "0x0a992d191DEeC32aFe36203Ad87D7d289a738F81", USDC: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}},
+			8453: {ChainID: 8453, Type: "evm", CCTP: config.ChainCCTP{Domain: 6, TokenMessenger: "0x1682Ae6375C4E4A97e4B583BC394c861A46D8962",
+				MessageTransmitter: "0xAD09780d193884d503182aD4588450C416D6F9D4", USDC: baseUSDC}},
+			56: {ChainID: 56, Type: "evm"},
+		}},
+		signer:  signer,
+		clients: clients,
+	}
+	newReq := func() *BatchPayoutRequest {
+		return &BatchPayoutRequest{
+			ChainID:       8453,
+			SourceChainID: 1,
+			FromAddress:   signer.Address().Hex(),
+			Items: []PayoutItem{
+				{ID: "a", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "100", TokenAddress: strings.ToLower(baseUSDC)},
+			},
+		}
+	}
+
+	req := newReq()
+	require.NoError(t, svc.validateRoute(req))
+	assert.Equal(t, &queue.Route{Bridge: queue.BridgeCCTP, SourceChainID: 1}, svc.batchRoute(req))
+	assert.Nil(t, svc.batchRoute(&BatchPayoutRequest{ChainID: 8453}))
+
+	for name, mutate := range map[string]func(*BatchPayoutRequest){
+		"same chain":          func(r *BatchPayoutRequest) { r.SourceChainID = 8453 },
+		"unknown source":      func(r *BatchPayoutRequest) { r.SourceChainID = 10 },
+		"source without cctp": func(r *BatchPayoutRequest) { r.SourceChainID = 56 },
+		"non-USDC item":       func(r *BatchPayoutRequest) { r.Items[0].TokenAddress = "0xdAC17F958D2ee523a2206206994597C13D831ec7" },
+		"native item":         func(r *BatchPayoutRequest) { r.Items[0].TokenAddress = "" },
+		"with swap":           func(r *BatchPayoutRequest) { r.Swap = &queue.Swap{SellToken: baseUSDC} },
+		"simulated":           func(r *BatchPayoutRequest) { r.Simulate = true },
+	} {
+		r := newReq()
+		mutate(r)
+		assert.Error(t, svc.validateRoute(r), name)
+	}
+
+	// 等待源链确认和跨链证明时按等待策略重试
+	var pending error = &RoutePendingError{Leg: queue.RouteLegBurned, SourceChainID: 1}
+	var coded interface{ ErrorCode() string }
+	require.ErrorAs(t, pending, &coded)
+	assert.Equal(t, RoutePendingCode, coded.ErrorCode())
+	var hint queue.RetryHint
+	require.ErrorAs(t, pending, &hint)
+	assert.Equal(t, routeWaitRetry, hint.RetryPolicy())
+
+	// 铸造的预留按代币转账的预留折算
+	assert.Equal(t, big.NewInt(cctpMintGas), bridgeReservation(big.NewInt(erc20TransferGas)))
+}
+
+func TestShadowRequest(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	svc := &PayoutService{
+		cfg: &config.Config{
+			Shadow: config.ShadowConfig{Enabled: true, ScaleBps: 10},
+			Chains: map[uint64]config.ChainConfig{
+				1: {ChainID: 1, Type: "evm", Shadow: config.ChainShadow{ChainID: 11155111, Tokens: map[string]string{
+					"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238",
+				}}},
+				11155111: {ChainID: 11155111, Type: "evm", Testnet: true},
+				137:      {ChainID: 137, Type: "evm"},
+			},
+		},
+		signer: signer,
+	}
+	req := &BatchPayoutRequest{
+		BatchID:     "batch-1",
+		UserID:      "user-1",
+		ChainID:     1,
+		FromAddress: "0x1111111111111111111111111111111111111111",
+		FeeMode:     FeeModeRecipient,
+		Priority:    "HIGH",
+		WebhookURL:  "https://example.com/hook",
+		Items: []PayoutItem{
+			{ID: "usdc", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "5000000", TokenAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", TokenDecimals: 6},
+			{ID: "eth", RecipientAddress: "0x3333333333333333333333333333333333333333", Amount: "500", MaxFee: "1000"},
+			{ID: "usdt", RecipientAddress: "0x4444444444444444444444444444444444444444", Amount: "5000000", TokenAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7"},
+		},
+	}
+
+	shadow, err := svc.shadowRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "shadow-batch-1", shadow.BatchID)
+	assert.Equal(t, "shadow-user-1", shadow.UserID)
+	assert.Equal(t, uint64(11155111), shadow.ChainID)
+	assert.Equal(t, signer.Address().Hex(), shadow.FromAddress)
+	assert.Equal(t, "HIGH", shadow.Priority)
+	assert.True(t, shadow.AllowPartial)
+	assert.Empty(t, shadow.FeeMode)
+	assert.Empty(t, shadow.WebhookURL)
+
+	// USDC 映射到测试网 USDC，金额缩小到 0.1% (最小 1)，未映射的 USDT 不镜像
+	require.Len(t, shadow.Items, 2)
+	assert.Equal(t, PayoutItem{ID: "shadow-usdc", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "5000",
+		TokenAddress: "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238", TokenDecimals: 6}, shadow.Items[0])
+	assert.Equal(t, PayoutItem{ID: "shadow-eth", RecipientAddress: "0x3333333333333333333333333333333333333333", Amount: "1"}, shadow.Items[1])
+
+	_, err = svc.shadowRequest(&BatchPayoutRequest{ChainID: 137, Items: req.Items})
+	assert.Error(t, err, "chain without a shadow testnet")
+	_, err = svc.shadowRequest(&BatchPayoutRequest{ChainID: 1, Items: req.Items[2:]})
+	assert.Error(t, err, "no mappable items")
+}
+
+type testRPCError struct {
+	code int
+	msg  string
+	data interface{}
+}
+
+func (e *testRPCError) Error() string          { return e.msg }
+func (e *testRPCError) ErrorCode() int         { return e.code }
+func (e *testRPCError) ErrorData() interface{} { return e.data }
+
+func TestChainErrorOf(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		code    queue.ChainErrorCode
+		details map[string]string
+	}{
+		{"nonce too low", fmt.Errorf("failed to send transaction: %w", &BroadcastError{Class: rpcpool.TxErrorNonceTooLow, Err: errors.New("nonce too low")}),
+			queue.ChainErrNonceConflict, map[string]string{"class": "nonce_too_low"}},
+		{"underpriced", &BroadcastError{Class: rpcpool.TxErrorUnderpriced, Err: errors.New("replacement transaction underpriced")},
+			queue.ChainErrRejectedByNode, map[string]string{"class": "underpriced"}},
+		{"insufficient funds", &BroadcastError{Class: rpcpool.TxErrorInsufficientFunds, Err: errors.New("insufficient funds for gas * price + value")},
+			queue.ChainErrInsufficientFunds, map[string]string{"class": "insufficient_funds"}},
+		{"fork simulation revert", queue.Permanent(&forksim.RevertedError{Provider: "tenderly", Result: &forksim.Result{RevertReason: "paused"}}),
+			queue.ChainErrReverted, map[string]string{"provider": "tenderly", "revert_reason": "paused"}},
+		{"estimate gas revert", fmt.Errorf("failed to build transaction: %w", &testRPCError{code: 3, msg: "execution reverted", data: "0x08c379a0"}),
+			queue.ChainErrReverted, map[string]string{"rpc_code": "3", "revert_data": "0x08c379a0"}},
+		{"estimate gas without funds", &testRPCError{code: -32000, msg: "insufficient funds for transfer"},
+			queue.ChainErrInsufficientFunds, map[string]string{"rpc_code": "-32000", "class": "insufficient_funds"}},
+		{"other node error", &testRPCError{code: -32000, msg: "invalid sender"},
+			queue.ChainErrRejectedByNode, map[string]string{"rpc_code": "-32000"}},
+		{"tron rejected", &TronRejectedError{Code: "CONTRACT_VALIDATE_ERROR", Err: errors.New("TRON broadcast rejected: contract validate error")},
+			queue.ChainErrRejectedByNode, map[string]string{"node_code": "CONTRACT_VALIDATE_ERROR"}},
+		{"tron balance", &TronRejectedError{Code: "CONTRACT_VALIDATE_ERROR", Err: errors.New("Validate TransferContract error, balance is not sufficient.")},
+			queue.ChainErrInsufficientFunds, map[string]string{"node_code": "CONTRACT_VALIDATE_ERROR"}},
+		{"rpc deadline", fmt.Errorf("failed to get nonce: %w", context.DeadlineExceeded), queue.ChainErrRPCTimeout, nil},
+		{"already classified", queue.WithChainError(errors.New("TRC20 transfer would revert"), &queue.ChainError{Code: queue.ChainErrReverted}),
+			queue.ChainErrReverted, nil},
+	}
+	for _, tc := range cases {
+		chain := chainErrorOf(tc.err)
+		require.NotNil(t, chain, tc.name)
+		assert.Equal(t, tc.code, chain.Code, tc.name)
+		assert.Equal(t, tc.details, chain.Details, tc.name)
+	}
+
+	resource := chainErrorOf(&TronResourceError{Address: "TXYZ", RequiredSun: 30_000_000, BalanceSun: 1_000_000})
+	assert.Equal(t, queue.ChainErrInsufficientFunds, resource.Code)
+	assert.Equal(t, "30000000", resource.Details["required"])
+
+	assert.Nil(t, chainErrorOf(nil))
+	assert.Nil(t, chainErrorOf(&FeeCapError{}), "failures unrelated to the chain are not classified")
+}
+
+func TestResolveDuplicates(t *testing.T) {
+	const usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	newReq := func(mode DuplicateMode) *BatchPayoutRequest {
+		return &BatchPayoutRequest{
+			DuplicateMode: mode,
+			Items: []PayoutItem{
+				{ID: "a", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "100", TokenAddress: usdc},
+				{ID: "b", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "100", TokenAddress: strings.ToLower(usdc)},
+				{ID: "c", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "200", TokenAddress: usdc},
+				{ID: "d", RecipientAddress: "0x2222222222222222222222222222222222222222", Amount: "100"},
+				{ID: "e", RecipientAddress: "0X2222222222222222222222222222222222222222", Amount: "0100", TokenAddress: usdc},
+				{ID: "f", RecipientAddress: "0x3333333333333333333333333333333333333333", Amount: "100", TokenAddress: usdc},
+			},
+		}
+	}
+
+	req := newReq("")
+	out, decisions := resolveDuplicates(req)
+	assert.Same(t, req, out, "no check without a duplicate mode")
+	assert.Nil(t, decisions)
+
+	req = newReq(DuplicateModeFlag)
+	out, decisions = resolveDuplicates(req)
+	assert.Len(t, req.Items, 6, "the original request is not modified")
+	var ids []string
+	for _, item := range out.Items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"a", "c", "d", "f"}, ids)
+	assert.Equal(t, "100", out.Items[0].Amount)
+	assert.Equal(t, []DuplicateDecision{
+		{ItemID: "b", DuplicateOf: "a", Decision: DuplicateModeFlag},
+		{ItemID: "e", DuplicateOf: "a", Decision: DuplicateModeFlag},
+	}, decisions)
+
+	out, decisions = resolveDuplicates(newReq(DuplicateModeMerge))
+	require.Len(t, out.Items, 4)
+	assert.Equal(t, "300", out.Items[0].Amount, "merged rows are paid in one transfer")
+	assert.Len(t, decisions, 2)
+	assert.Equal(t, DuplicateModeMerge, decisions[0].Decision)
+
+	req = &BatchPayoutRequest{DuplicateMode: DuplicateModeMerge, Items: newReq("").Items[2:4]}
+	out, decisions = resolveDuplicates(req)
+	assert.Same(t, req, out, "nothing to merge")
+	assert.Nil(t, decisions)
+}
+
+func TestBacklogChecks(t *testing.T) {
+	svc := &PayoutService{cfg: &config.Config{
+		Chains: map[uint64]config.ChainConfig{
+			1:    {ChainID: 1, Name: "Ethereum"},
+			8453: {ChainID: 8453, Name: "Base"},
+		},
+		QueueAlert: config.QueueAlertConfig{MaxDepth: 100, MaxChainDepth: 50, MaxJobAge: 30 * time.Minute, PagerDutyKey: "pd-key"},
+	}}
+	b := &queue.Backlog{
+		Queued:       120,
+		OldestJobAge: 10 * time.Minute,
+		Chains:       []*queue.ChainBacklog{{ChainID: 1, Queued: 110, OldestJobAge: 10 * time.Minute}, {ChainID: 8453, Queued: 10}},
+	}
+
+	firing := map[string]bool{}
+	for _, check := range svc.backlogChecks(b) {
+		firing[check.key] = check.firing
+		assert.Same(t, b, check.alert.Backlog)
+	}
+	assert.Equal(t, map[string]bool{"depth": true, "job_age": false, "chain_depth:1": true, "chain_depth:8453": false}, firing)
+
+	// 队列中不存在的链仍然检查 (以便发送恢复通知)
+	checks := svc.backlogChecks(&queue.Backlog{})
+	require.Len(t, checks, 4)
+	assert.Equal(t, "chain_depth:1", checks[2].key)
+	assert.False(t, checks[2].firing)
+	assert.Equal(t, "Ethereum", checks[2].alert.Chain)
+
+	// 未配置阈值的条件不检查
+	assert.Empty(t, (&PayoutService{cfg: &config.Config{}}).backlogChecks(b))
+
+	trigger := svc.pagerDutyRequest(QueueEventBacklog, "chain_depth:1", checks[2].alert)
+	assert.Equal(t, "trigger", trigger.EventAction)
+	assert.Equal(t, "pd-key", trigger.RoutingKey)
+	assert.Equal(t, "payout-engine:queue:chain_depth:1", trigger.DedupKey)
+	require.NotNil(t, trigger.Payload)
+	assert.Equal(t, "0 payout jobs queued on Ethereum (1) (threshold 50)", trigger.Payload.Summary)
+	resolve := svc.pagerDutyRequest(QueueEventResolved, "chain_depth:1", checks[2].alert)
+	assert.Equal(t, "resolve", resolve.EventAction)
+	assert.Equal(t, trigger.DedupKey, resolve.DedupKey)
+	assert.Nil(t, resolve.Payload)
+}
+
+func TestBacklogMetrics(t *testing.T) {
+	var out strings.Builder
+	writeBacklogMetrics(&out, &queue.Backlog{
+		Queued:       3,
+		Processing:   1,
+		DeadLetters:  2,
+		OldestJobAge: 90 * time.Second,
+		Chains:       []*queue.ChainBacklog{{ChainID: 1, Queued: 3, Processing: 1, OldestJobAge: 90 * time.Second}},
+	}, func(uint64) string { return "Ethereum" })
+
+	metrics := out.String()
+	assert.Contains(t, metrics, "# TYPE payout_queue_depth gauge\npayout_queue_depth 3\n")
+	assert.Contains(t, metrics, "payout_queue_oldest_job_age_seconds 90\n")
+	assert.Contains(t, metrics, "payout_dead_letter_jobs 2\n")
+	assert.Contains(t, metrics, `payout_queue_chain_depth{chain_id="1",chain="Ethereum"} 3`+"\n")
+	assert.Contains(t, metrics, `payout_queue_chain_processing{chain_id="1",chain="Ethereum"} 1`+"\n")
+	assert.Contains(t, metrics, "payout_queue_metrics_up 1\n")
+
+	// 读取积压失败时只导出 up 指标
+	out.Reset()
+	writeBacklogMetrics(&out, nil, func(uint64) string { return "" })
+	assert.Equal(t, "# HELP payout_queue_metrics_up Whether the queue backlog could be read for this scrape.\n"+
+		"# TYPE payout_queue_metrics_up gauge\npayout_queue_metrics_up 0\n", out.String())
+}
+
+func TestBatchReport(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []*queue.JobStatus{
+		{ID: "inv-1", ChainID: 1, ToAddress: "0x2222222222222222222222222222222222222222", Amount: "1000000",
+			TokenAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", TokenSymbol: "USDC", State: queue.JobStateConfirmed,
+			TxHash: "0xabc", BlockNumber: 19000000, GasFee: "21000", UnwrapGasFee: "500", CreatedAt: updated.Add(-time.Hour), UpdatedAt: updated,
+			Reversal: &queue.Reversal{TxHash: "0xdef", Amount: "400000", Partial: true}},
+		{ID: "inv-2", ChainID: 1, ToAddress: "0x3333333333333333333333333333333333333333", Amount: "5", State: queue.JobStateFailed,
+			Error: "insufficient funds, for gas", ErrorCode: "INSUFFICIENT_FUNDS",
+			ChainError: &queue.ChainError{Code: queue.ChainErrInsufficientFunds}, UpdatedAt: updated},
+	}
+	report := newBatchReport("user-1", "batch-1", jobs, "0xmanifest")
+	assert.Equal(t, BatchStatusPartialFailed, report.Status)
+	assert.Equal(t, "21500", report.NetworkFee)
+	assert.Equal(t, updated, report.CompletedAt)
+	require.Len(t, report.Items, 2)
+	assert.Equal(t, "21500", report.Items[0].NetworkFee)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", report.Items[1].ChainErrorCode)
+	assert.Equal(t, 1, report.ReversedCount)
+	assert.Equal(t, ReversalPartial, report.Items[0].ReversalStatus)
+
+	body, contentType, err := report.Encode("CSV")
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", contentType)
+	assert.Equal(t, "item_id,chain_id,recipient,amount,token,token_symbol,status,tx_hash,block_number,network_fee,error_code,chain_error_code,error,updated_at,reversal_status,reversal_tx_hash,reversed_amount\n"+
+		"inv-1,1,0x2222222222222222222222222222222222222222,1000000,0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48,USDC,confirmed,0xabc,19000000,21500,,,,2026-03-01T12:00:00Z,partially_reversed,0xdef,400000\n"+
+		"inv-2,1,0x3333333333333333333333333333333333333333,5,,,failed,,,0,INSUFFICIENT_FUNDS,INSUFFICIENT_FUNDS,\"insufficient funds, for gas\",2026-03-01T12:00:00Z,,,\n", string(body))
+
+	body, contentType, err = report.Encode("")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	var decoded BatchReport
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "0xmanifest", decoded.ManifestHash)
+	assert.Equal(t, queue.JobStateFailed, decoded.Items[1].Status)
+
+	_, _, err = report.Encode("xlsx")
+	assert.True(t, IsInvalidArgument(err))
+
+	// 已发送的支付项等待回执记录确认区块，超过等待时间后按已有数据生成
+	assert.True(t, reportReady(jobs, updated.Add(time.Minute)))
+	jobs[0].BlockNumber = 0
+	assert.False(t, reportReady(jobs, updated.Add(time.Minute)))
+	assert.True(t, reportReady(jobs, updated.Add(reportReceiptWait+time.Minute)))
+	assert.Equal(t, 0, pendingItems(jobs))
+	assert.Equal(t, 1, pendingItems([]*queue.JobStatus{{State: queue.JobStateRetrying}, {State: queue.JobStateCancelled}}))
+
+	uploaded := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		uploaded[r.URL.Path] = r.Header.Get("Content-Type") + ":" + strconv.Itoa(len(data))
+	}))
+	defer srv.Close()
+	store, err := objectstore.New(objectstore.Config{Provider: objectstore.ProviderGCS, Bucket: "reports", Endpoint: srv.URL, AccessKey: "id", SecretKey: "secret", Prefix: "payouts/"})
+	require.NoError(t, err)
+	loc, err := (&PayoutService{reportStore: store}).uploadReport(context.Background(), report)
+	require.NoError(t, err)
+	assert.Equal(t, "gs://reports/payouts/user-1/batch-1.csv", loc.CSV)
+	assert.Equal(t, "gs://reports/payouts/user-1/batch-1.json", loc.JSON)
+	assert.Len(t, uploaded, 2)
+	assert.Contains(t, uploaded["/reports/payouts/user-1/batch-1.csv"], "text/csv:")
+}
+
+type fakeReturnChain struct {
+	receipts map[common.Hash]*types.Receipt
+	txs      map[common.Hash]*types.Transaction
+}
+
+func (f *fakeReturnChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if r, ok := f.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeReturnChain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if tx, ok := f.txs[hash]; ok {
+		return tx, false, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func TestVerifyReversal(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	recipient := crypto.PubkeyToAddress(key.PublicKey)
+	treasury := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	nativeTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 0, To: &treasury, Value: big.NewInt(1000), Gas: 21000,
+		GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1),
+	}), types.LatestSignerForChainID(big.NewInt(1)), key)
+	require.NoError(t, err)
+	tokenHash := common.HexToHash("0x02")
+	transfer := func(from common.Address, amount int64) *types.Log {
+		return &types.Log{
+			Address: usdc,
+			Topics:  []common.Hash{transferEventTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(treasury.Bytes())},
+			Data:    common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		}
+	}
+	chain := &fakeReturnChain{
+		receipts: map[common.Hash]*types.Receipt{
+			nativeTx.Hash(): {Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(120)},
+			tokenHash: {Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(130), Logs: []*types.Log{
+				transfer(recipient, 300), transfer(common.HexToAddress("0x4444444444444444444444444444444444444444"), 5000),
+			}},
+		},
+		txs: map[common.Hash]*types.Transaction{nativeTx.Hash(): nativeTx},
+	}
+
+	// 原生代币全额退回
+	job := &queue.JobStatus{ID: "job-1", ChainID: 1, ToAddress: recipient.Hex(), Amount: "1000", BlockNumber: 100}
+	r, err := verifyReversal(ctx, chain, job, nativeTx.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "1000", r.Amount)
+	assert.False(t, r.Partial)
+	assert.Equal(t, treasury.Hex(), r.To)
+	assert.Equal(t, uint64(120), r.BlockNumber)
+
+	// ERC20 部分退回，只计入原收款地址转出的金额
+	tokenJob := &queue.JobStatus{ID: "job-2", ChainID: 1, ToAddress: recipient.Hex(), Amount: "1000", TokenAddress: usdc.Hex(), BlockNumber: 100}
+	r, err = verifyReversal(ctx, chain, tokenJob, tokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, "300", r.Amount)
+	assert.True(t, r.Partial)
+
+	// 不是收款地址转出的付款资产
+	_, err = verifyReversal(ctx, chain, &queue.JobStatus{ToAddress: treasury.Hex(), Amount: "1000"}, nativeTx.Hash())
+	assert.True(t, IsFailedPrecondition(err))
+	_, err = verifyReversal(ctx, chain, &queue.JobStatus{ToAddress: recipient.Hex(), Amount: "1000", TokenAddress: treasury.Hex()}, tokenHash)
+	assert.True(t, IsFailedPrecondition(err))
+	// 早于付款、未上链
+	_, err = verifyReversal(ctx, chain, &queue.JobStatus{ToAddress: recipient.Hex(), Amount: "1000", BlockNumber: 125}, nativeTx.Hash())
+	assert.True(t, IsFailedPrecondition(err))
+	_, err = verifyReversal(ctx, chain, job, common.HexToHash("0x03"))
+	assert.True(t, IsFailedPrecondition(err))
+
+	assert.True(t, isTxHash(nativeTx.Hash().Hex()))
+	assert.False(t, isTxHash("0xabc"))
+}
+
+func TestTronChainID(t *testing.T) {
+	// 创世区块 ID 的后 4 字节与内置 TRON 链的链 ID 一致
+	for id, genesis := range map[uint64]string{
+		728126428:  "00000000000000001ebf88508a03865c71d452e25f4d51194196a1d22b6653dc", // 主网
+		3448148188: "0000000000000000d698d4192c56cb6be724a558448e2684802de4d6cd8690dc", // Nile
+	} {
+		actual, err := tronChainID(common.FromHex(genesis))
+		require.NoError(t, err)
+		assert.Equal(t, id, actual)
+	}
+	_, err := tronChainID([]byte{0x01})
+	assert.Error(t, err)
+}
+
+func TestTxFormats(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	svc := &PayoutService{signer: signer}
+	to := common.HexToAddress("0x1d17CBcF0D6D143135aE902365D2E5e2A16538D4")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(324),
+		Nonce:     3,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(45250000),
+		Gas:       900000,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      []byte{0xa9, 0x05, 0x9c, 0xbb},
+	})
+
+	// 标准交易: 与 go-ethereum 编码一致，可经私有交易池广播
+	std, err := svc.signChainTx(ctx, "", tx, 324, signingOp{purpose: signPurposePayout})
+	require.NoError(t, err)
+	require.NotNil(t, std.tx)
+	assert.Equal(t, std.tx.Hash(), std.hash)
+	parsed, err := parsePendingTx(&queue.PendingTx{RawTx: hex.EncodeToString(std.raw)})
+	require.NoError(t, err)
+	assert.Equal(t, std.hash, parsed.hash)
+
+	// zkSync: EIP-712 交易 (0x71)，只能按原始交易广播，替换时从原始交易取回 EIP-1559 字段
+	zk, err := svc.signChainTx(ctx, config.TxFormatZkSync, tx, 324, signingOp{purpose: signPurposePayout})
+	require.NoError(t, err)
+	assert.Nil(t, zk.tx)
+	assert.Equal(t, byte(zksync.TxType), zk.raw[0])
+	assert.NotEqual(t, std.hash, zk.hash)
+	parsed, err = parsePendingTx(&queue.PendingTx{RawTx: hex.EncodeToString(zk.raw), TxFormat: config.TxFormatZkSync})
+	require.NoError(t, err)
+	assert.Equal(t, zk.hash, parsed.hash)
+	assert.Nil(t, parsed.tx)
+	assert.Equal(t, tx.Nonce(), parsed.fields.Nonce())
+	assert.Equal(t, tx.GasFeeCap(), parsed.fields.GasFeeCap())
+	assert.Equal(t, tx.Gas(), parsed.fields.Gas())
+	assert.Equal(t, tx.Data(), parsed.fields.Data())
+	assert.Equal(t, &to, parsed.fields.To())
+
+	ztx, sig, err := zksync.Decode(zk.raw)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), ztx.From)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(ztx.SigningHash().Bytes(), sig)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pub))
+
+	// 按标准格式解析 zkSync 交易失败
+	_, err = parsePendingTx(&queue.PendingTx{RawTx: hex.EncodeToString(zk.raw)})
+	assert.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	newKey := func() (string, common.Address) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		return common.Bytes2Hex(crypto.FromECDSA(key)), crypto.PubkeyToAddress(key.PublicKey)
+	}
+	activeKey, active := newKey()
+	prevKey, prev := newKey()
+	_, stranger := newKey()
+	signer, err := kms.NewSigner(ctx, kms.Config{
+		PrivateKey: activeKey,
+		Previous:   []kms.PreviousKey{{Config: kms.Config{PrivateKey: prevKey}, RetireAt: time.Now().Add(time.Hour)}},
+	})
+	require.NoError(t, err)
+
+	pool, err := rpcpool.Dial(ctx, 137, []string{"http://127.0.0.1:8545"}, rpcpool.Config{})
+	require.NoError(t, err)
+	defer pool.Close()
+	svc := &PayoutService{
+		cfg:          &config.Config{Chains: map[uint64]config.ChainConfig{137: {ChainID: 137, Name: "Polygon", Type: "evm"}}},
+		chainSigners: map[uint64]kms.Signer{137: signer},
+		clients:      map[uint64]*rpcpool.Pool{137: pool},
+	}
+
+	keys := svc.SigningKeys()
+	require.Len(t, keys, 1)
+	require.Len(t, keys[0].Versions, 2)
+	assert.Equal(t, active, keys[0].Versions[0].Address)
+	assert.Equal(t, "previous", keys[0].Versions[1].Version)
+
+	// 过渡期内仍接受旧地址提交的批次
+	req := &BatchPayoutRequest{
+		BatchID:     "batch-1",
+		UserID:      "user-1",
+		ChainID:     137,
+		FromAddress: prev.Hex(),
+		Items:       []PayoutItem{{RecipientAddress: "0x000000000000000000000000000000000000dEaD", Amount: "1"}},
+	}
+	assert.NoError(t, svc.validateRequest(ctx, req))
+	req.FromAddress = stranger.Hex()
+	assert.Error(t, svc.validateRequest(ctx, req))
+
+	// 按发送地址选择密钥版本: 旧地址的在途交易替换由旧密钥签名
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(137), Nonce: 1, GasFeeCap: big.NewInt(1), Gas: 21000, To: &active, Value: big.NewInt(0)})
+	for from, want := range map[common.Address]common.Address{prev: prev, active: active, {}: active} {
+		signed, err := svc.signChainTx(ctx, "", tx, 137, signingOp{purpose: signPurposeReplacement, from: from})
+		require.NoError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(137)), signed.tx)
+		require.NoError(t, err)
+		assert.Equal(t, want, sender)
+	}
+
+	// 排队中的支付改由当前地址发送，旧地址余额转移和代付保持原地址
+	job := &queue.Job{ID: "job-1", ChainID: 137, FromAddress: prev.Hex()}
+	svc.rotateJobSender(job)
+	assert.Equal(t, active.Hex(), job.FromAddress)
+	assert.Equal(t, active, jobSigningOp(job, signPurposePayout).from)
+	drain := &queue.Job{ID: "drain-1", ChainID: 137, FromAddress: prev.Hex(), Sweep: true}
+	svc.rotateJobSender(drain)
+	assert.Equal(t, prev.Hex(), drain.FromAddress)
+	permit := &queue.Job{ID: "permit-1", ChainID: 137, FromAddress: prev.Hex(), Permit: &queue.Permit{}}
+	svc.rotateJobSender(permit)
+	assert.Equal(t, prev.Hex(), permit.FromAddress)
+
+	usdc := "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"
+	tokens := svc.drainTokens(137, config.ChainConfig{
+		WrappedNative: "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270",
+		Sweep:         config.ChainSweep{Ceilings: map[string]config.SweepCeiling{config.SweepNative: {}, strings.ToLower(usdc): {}}},
+	})
+	assert.ElementsMatch(t, []string{strings.ToLower(usdc), "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"}, tokens)
+}
+
+// flakySigner 健康检查结果可切换的签名器
+type flakySigner struct {
+	kms.Signer
+	err error
+}
+
+func (f *flakySigner) Health(context.Context) error { return f.err }
+
+func TestCheckSigners(t *testing.T) {
+	ctx := context.Background()
+	newSigner := func() kms.Signer {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		signer, err := kms.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+		require.NoError(t, err)
+		return signer
+	}
+
+	var events []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert SignerHealthAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		events = append(events, alert.Event+" "+alert.Signer)
+	}))
+	defer srv.Close()
+
+	flaky := &flakySigner{Signer: newSigner(), err: errors.New("fireblocks vault 0 unreachable")}
+	svc := &PayoutService{
+		cfg:           &config.Config{SigningAlertURL: srv.URL},
+		signer:        newSigner(),
+		chainSigners:  map[uint64]kms.Signer{137: flaky},
+		webhookSender: webhook.NewSender(time.Second),
+	}
+	assert.False(t, svc.SignersHealthy(), "not ready before the first check")
+
+	results := svc.CheckSigners(ctx)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Healthy)
+	assert.Equal(t, "chain:137", results[1].Signer)
+	assert.Contains(t, results[1].Error, "unreachable")
+	assert.False(t, svc.SignersHealthy())
+
+	// 持续不可用时不重复告警，恢复时告警一次
+	svc.CheckSigners(ctx)
+	flaky.err = nil
+	svc.CheckSigners(ctx)
+	assert.True(t, svc.SignersHealthy())
+	assert.Equal(t, []string{"signing.unhealthy chain:137", "signing.recovered chain:137"}, events)
+
+	// 就绪状态只在变化时回调
+	var states []bool
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		svc.RunSignerHealth(runCtx, 10*time.Millisecond, func(ready bool) { states = append(states, ready) })
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, []bool{true}, states)
+}
+
+func TestTronMetrics(t *testing.T) {
+	const chainID = 728126428
+	chain := metrics.Label(chainID)
+
+	rejected := metrics.BroadcastFailures.Value(chain, "contract_validate_error")
+	recordTronBroadcastFailure(chainID, &tronapi.Return{Result: false, Code: tronapi.Return_CONTRACT_VALIDATE_ERROR}, nil)
+	recordTronBroadcastFailure(chainID, &tronapi.Return{Result: true}, nil)
+	assert.Equal(t, rejected+1, metrics.BroadcastFailures.Value(chain, "contract_validate_error"))
+
+	other := metrics.BroadcastFailures.Value(chain, "other")
+	recordTronBroadcastFailure(chainID, nil, errors.New("connection refused"))
+	assert.Equal(t, other+1, metrics.BroadcastFailures.Value(chain, "other"))
+
+	// TRON 本地私钥签名计入签名耗时
+	signed := metrics.SigningLatency.Count(chain, kms.ProviderLocal, "success")
+	signer, err := kms.NewLocalSigner("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	s := &PayoutService{}
+	_, err = s.signTronTransaction(context.Background(), &troncore.Transaction{RawData: &troncore.TransactionRaw{}}, make([]byte, 32),
+		signer, chainID, signingOp{purpose: signPurposePayout})
+	require.NoError(t, err)
+	assert.Equal(t, signed+1, metrics.SigningLatency.Count(chain, kms.ProviderLocal, "success"))
+}
+
+// fakeTronNode 构建并广播 TRX 转账，交易立即返回 (不等待上链)
+type fakeTronNode struct {
+	*fakeTronResources
+	fakeTronHead
+	txIDs     [][]byte
+	broadcast []*troncore.Transaction
+}
+
+func (f *fakeTronNode) Transfer(from, toAddress string, amount int64) (*tronapi.TransactionExtention, error) {
+	txID := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d:%d", from, toAddress, amount, len(f.txIDs))))
+	f.txIDs = append(f.txIDs, txID[:])
+	return &tronapi.TransactionExtention{
+		Transaction: &troncore.Transaction{RawData: &troncore.TransactionRaw{}},
+		Txid:        txID[:],
+		Result:      &tronapi.Return{Result: true},
+	}, nil
+}
+
+func (f *fakeTronNode) TransferAsset(from, toAddress, assetName string, amount int64) (*tronapi.TransactionExtention, error) {
+	return f.Transfer(from, toAddress, amount)
+}
+
+func (f *fakeTronNode) TRC20Send(from, to, contract string, amount *big.Int, feeLimit int64) (*tronapi.TransactionExtention, error) {
+	return f.Transfer(from, to, amount.Int64())
+}
+
+func (f *fakeTronNode) Broadcast(tx *troncore.Transaction) (*tronapi.Return, error) {
+	f.broadcast = append(f.broadcast, tx)
+	return &tronapi.Return{Result: true}, nil
+}
+
+func (f *fakeTronNode) GetTransactionInfoByID(id string) (*troncore.TransactionInfo, error) {
+	return nil, errors.New("transaction info not found")
+}
+
+func TestTronSigningGuard(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewSigner(ctx, kms.Config{
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)),
+		Guard:      kms.GuardConfig{MaxPerMinute: 1},
+	})
+	require.NoError(t, err)
+
+	svc := &PayoutService{cfg: &config.Config{}, tronSigner: signer}
+	from := tronSignerAddress(signer)
+	address, err := svc.tronPayoutAddress()
+	require.NoError(t, err)
+	assert.Equal(t, tronaddress.PubkeyToAddress(key.PublicKey).String(), address)
+
+	client := &fakeTronNode{fakeTronResources: &fakeTronResources{balance: 10_000_000, resources: &tronapi.AccountResourceMessage{FreeNetLimit: 600}}}
+	newJob := func(id string) *queue.Job {
+		return &queue.Job{ID: id, ChainID: 728126428, FromAddress: from, ToAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Amount: "1000000"}
+	}
+	result, err := svc.processTronJob(ctx, client, newJob("job-1"))
+	require.NoError(t, err)
+	require.True(t, result.Success, "%v", result.Error)
+	require.Len(t, client.broadcast, 1)
+	// 签名针对节点返回的 txID，恢复出付款地址
+	pub, err := crypto.SigToPub(client.txIDs[0], client.broadcast[0].Signature[0])
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pub))
+
+	// 达到每分钟上限后 TRON 任务同样被拒绝，不广播
+	result, err = svc.processTronJob(ctx, client, newJob("job-2"))
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	var guardErr *kms.GuardError
+	assert.ErrorAs(t, result.Error, &guardErr)
+	assert.Len(t, client.broadcast, 1)
+}
+
+// recordingAuditSink 记录 KMS 审计记录，failing 时写入失败
+type recordingAuditSink struct {
+	records []kms.AuditRecord
+	failing bool
+}
+
+func (r *recordingAuditSink) RecordAudit(_ context.Context, record kms.AuditRecord) error {
+	if r.failing {
+		return errors.New("sink unavailable")
+	}
+	r.records = append(r.records, record)
+	return nil
+}
+
+func TestTronSigningAudit(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sink := &recordingAuditSink{}
+	signer, err := kms.NewSigner(ctx, kms.Config{
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)),
+		Audit:      kms.AuditConfig{Sink: sink, Required: true},
+	})
+	require.NoError(t, err)
+
+	svc := &PayoutService{cfg: &config.Config{}, tronSigner: signer}
+	client := &fakeTronNode{fakeTronResources: &fakeTronResources{balance: 10_000_000, resources: &tronapi.AccountResourceMessage{FreeNetLimit: 600}}}
+	job := &queue.Job{ID: "job-1", UserID: "user-1", BatchID: "batch-1", ChainID: 728126428, FromAddress: tronSignerAddress(signer), ToAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Amount: "1000000"}
+	result, err := svc.processTronJob(ctx, client, job)
+	require.NoError(t, err)
+	require.True(t, result.Success, "%v", result.Error)
+
+	// TRON 签名与 EVM 签名一样进入审计 sink，带任务信息
+	require.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, kms.AuditMethodSignHash, record.Method)
+	assert.Equal(t, hexutil.Encode(client.txIDs[0]), record.Digest)
+	assert.Equal(t, signer.Address(), record.Address)
+	assert.Equal(t, "job-1", record.Caller.JobID)
+	assert.Equal(t, "batch-1", record.Caller.BatchID)
+	assert.Equal(t, signPurposePayout, record.Caller.Purpose)
+	assert.True(t, record.Success)
+
+	// 必须审计时，记录写入失败的签名被丢弃，不广播
+	sink.failing = true
+	job.ID = "job-2"
+	result, err = svc.processTronJob(ctx, client, job)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.ErrorContains(t, result.Error, "audit record not stored")
+	assert.Len(t, client.broadcast, 1)
+}
+
+// reorgBackend 区块已被重组替换、交易不在新主链上的节点
+type reorgBackend struct {
+	rpcpool.Backend
+	header *types.Header
+	nonce  uint64
+}
+
+func (b *reorgBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return b.header, nil
+}
+
+func (b *reorgBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (b *reorgBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return b.nonce, nil
+}
+
+func TestCheckMinedTxNonceConsumed(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	consumer, err := queue.NewConsumer(ctx, config.RedisConfig{URL: mr.Addr()})
+	require.NoError(t, err)
+
+	ref := queue.BatchRef{UserID: "user-1", BatchID: "batch-1"}
+	require.NoError(t, consumer.RegisterWebhook(ctx, "user-1", "batch-1", queue.WebhookTarget{URL: "https://example.com/hook", Secret: "s"}))
+	require.NoError(t, consumer.Push(ctx, &queue.Job{ID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1, Amount: "100", CreatedAt: time.Now()}))
+
+	backend := &reorgBackend{header: &types.Header{Number: big.NewInt(100), Extra: []byte("new fork")}, nonce: 8}
+	pool := rpcpool.New(1, []string{"fake"}, []rpcpool.Backend{backend}, rpcpool.Config{})
+	defer pool.Close()
+	svc := &PayoutService{cfg: &config.Config{Chains: map[uint64]config.ChainConfig{1: {ChainID: 1, Type: "evm"}}}, queue: consumer}
+
+	mined := &queue.MinedTx{
+		PendingTx:   queue.PendingTx{JobID: "job-1", BatchID: "batch-1", UserID: "user-1", ChainID: 1, FromAddress: "0x000000000000000000000000000000000000bEEF", Nonce: 7, TxHash: "0xabc"},
+		MinedHash:   "0xabc",
+		BlockNumber: 100,
+		BlockHash:   "0x01",
+	}
+	require.NoError(t, consumer.TrackMinedTx(ctx, mined))
+
+	// 重组后 nonce 7 已被其他交易消耗: 付款未发生，任务改为失败并不再跟踪
+	require.NoError(t, svc.checkMinedTx(ctx, pool, mined, 101))
+	status, err := consumer.GetJobStatus(ctx, ref, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, queue.JobStateFailed, status.State)
+	assert.True(t, status.Reorged)
+	require.NotNil(t, status.ChainError)
+	assert.Equal(t, queue.ChainErrReorgedNonceConsumed, status.ChainError.Code)
+	assert.Equal(t, "7", status.ChainError.Details["nonce"])
+	remaining, err := consumer.ListMinedTxs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	deliveries, err := consumer.ClaimDueWebhooks(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	var events []string
+	for _, d := range deliveries {
+		events = append(events, d.Event.Type)
+	}
+	assert.Contains(t, events, queue.EventJobFailed)
+}
+
+// busyNodeBackend 付款地址已有多笔在途交易的节点
+type busyNodeBackend struct {
+	rpcpool.Backend
+	pending, mined uint64
+}
+
+func (b *busyNodeBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("not supported")
+}
+
+func (b *busyNodeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000_000_000), nil
+}
+
+func (b *busyNodeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.pending, nil
+}
+
+func (b *busyNodeBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return b.mined, nil
+}
+
+func TestGasTankTopUpInFlightLimit(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	manager, err := nonce.NewManager(ctx, config.RedisConfig{URL: mr.Addr()})
+	require.NoError(t, err)
+	pool := rpcpool.New(1, []string{"fake"}, []rpcpool.Backend{&busyNodeBackend{pending: 9, mined: 5}}, rpcpool.Config{})
+	defer pool.Close()
+	manager.AddChainClient(1, pool)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := kms.NewLocalSigner(hexutil.Encode(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+
+	svc := &PayoutService{
+		cfg:           &config.Config{MaxInFlightTxs: 4, Chains: map[uint64]config.ChainConfig{1: {ChainID: 1, Type: "evm"}}},
+		nonceManager:  manager,
+		clients:       map[uint64]*rpcpool.Pool{1: pool},
+		feeOracles:    map[uint64]*gas.Oracle{1: gas.NewOracle(pool, 0)},
+		gasTankSigner: signer,
+	}
+
+	// 资金钱包与付款任务共用在途交易上限: 已有 4 笔未上链时不再分配 nonce
+	_, err = svc.sendEVMTopUp(ctx, 1, "0x000000000000000000000000000000000000bEEF", big.NewInt(1))
+	var limitErr *InFlightLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.EqualValues(t, 4, limitErr.Err.InFlight)
+}
+
+func TestReorgDepth(t *testing.T) {
+	svc := &PayoutService{cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+		1:   {ChainID: 1, Confirmations: 12, ReorgDepth: 64},
+		137: {ChainID: 137, Confirmations: 128},
+		10:  {ChainID: 10, Confirmations: 100, ReorgDepth: 20},
+	}}}
+	assert.EqualValues(t, 64, svc.reorgDepth(1))
+	// 确认数更深时跟踪到交易报告最终状态
+	assert.EqualValues(t, 128, svc.reorgDepth(137))
+	assert.EqualValues(t, 100, svc.reorgDepth(10))
+	assert.EqualValues(t, defaultReorgDepth, svc.reorgDepth(8453))
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// permitABI EIP-2612 permit 及代付所需的 ERC20 方法
+const permitABI = `[{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"name":"permit","outputs":[],"type":"function"},{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":true,"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"type":"function"},{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transferFrom","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+
+// permitGas permit 预留的 Gas (实际约 50-80k)
+const permitGas = 100000
+
+// permitTypeHash EIP-2612 Permit 结构的类型哈希
+var permitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+// 授权校验失败 (其余错误为 RPC 不可用)
+var (
+	errPermitUnsupported = errors.New("token does not support EIP-2612 permit")
+	errPermitSignature   = errors.New("permit signature does not match")
+)
+
+// permitDigest 源钱包签名的 EIP-712 摘要
+func permitDigest(domainSeparator common.Hash, owner, spender common.Address, value, nonce, deadline *big.Int) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		permitTypeHash.Bytes(),
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		common.BigToHash(value).Bytes(),
+		common.BigToHash(nonce).Bytes(),
+		common.BigToHash(deadline).Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator.Bytes(), structHash.Bytes())
+}
+
+// parsedPermit 解码后的授权参数
+type parsedPermit struct {
+	owner    common.Address
+	value    *big.Int
+	deadline *big.Int
+	v        uint8
+	r, s     [32]byte
+}
+
+// parsePermit 校验并解码请求中的授权 (v 为 27/28，兼容 0/1)
+func parsePermit(p *queue.Permit) (*parsedPermit, error) {
+	if !common.IsHexAddress(p.Owner) {
+		return nil, fmt.Errorf("invalid permit owner: %s", p.Owner)
+	}
+	value, ok := new(big.Int).SetString(p.Value, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid permit value: %s", p.Value)
+	}
+	if p.Deadline <= 0 {
+		return nil, fmt.Errorf("permit deadline is required")
+	}
+	out := &parsedPermit{owner: common.HexToAddress(p.Owner), value: value, deadline: big.NewInt(p.Deadline), v: p.V}
+	if out.v < 27 {
+		out.v += 27
+	}
+	if out.v != 27 && out.v != 28 {
+		return nil, fmt.Errorf("invalid permit v: %d", p.V)
+	}
+	for _, part := range []struct {
+		name string
+		hex  string
+		dst  *[32]byte
+	}{{"r", p.R, &out.r}, {"s", p.S, &out.s}} {
+		b, err := hexutil.Decode(part.hex)
+		if err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid permit %s: must be 32 bytes hex", part.name)
+		}
+		copy(part.dst[:], b)
+	}
+	return out, nil
+}
+
+// recoverPermitSigner 从签名恢复签出授权的地址
+func recoverPermitSigner(digest common.Hash, p *parsedPermit) (common.Address, error) {
+	sig := make([]byte, 65)
+	copy(sig[:32], p.r[:])
+	copy(sig[32:64], p.s[:])
+	sig[64] = p.v - 27
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// callPermitToken 只读调用代币合约。合约回滚或返回值无法解码时返回 errPermitUnsupported。
+func (s *PayoutService) callPermitToken(ctx context.Context, client ethCaller, token common.Address, method string, args ...interface{}) (interface{}, error) {
+	return callTokenView(ctx, client, s.permitABI, token, errPermitUnsupported, method, args...)
+}
+
+// callTokenView 只读调用代币合约的单返回值方法。合约回滚或返回值无法解码时返回 unsupported。
+func callTokenView(ctx context.Context, client ethCaller, contractABI abi.ABI, token common.Address, unsupported error, method string, args ...interface{}) (interface{}, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return nil, fmt.Errorf("%w: %s reverted", unsupported, method)
+		}
+		return nil, fmt.Errorf("%s call failed: %w", method, err)
+	}
+	values, err := contractABI.Unpack(method, out)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("%w: failed to decode %s", unsupported, method)
+	}
+	return values[0], nil
+}
+
+// permitAllowance 源钱包对付款地址的当前授权额度
+func (s *PayoutService) permitAllowance(ctx context.Context, client ethCaller, token, owner, spender common.Address) (*big.Int, error) {
+	out, err := s.callPermitToken(ctx, client, token, "allowance", owner, spender)
+	if err != nil {
+		return nil, err
+	}
+	return out.(*big.Int), nil
+}
+
+// verifyPermit 核对授权由源钱包按代币当前的 nonce 签出；
+// 授权已被提交 (nonce 已消耗) 但额度仍覆盖 total 时同样接受。
+func (s *PayoutService) verifyPermit(ctx context.Context, client ethCaller, token, spender common.Address, p *parsedPermit, total *big.Int) error {
+	domain, err := s.callPermitToken(ctx, client, token, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return err
+	}
+	nonce, err := s.callPermitToken(ctx, client, token, "nonces", p.owner)
+	if err != nil {
+		return err
+	}
+	digest := permitDigest(common.Hash(domain.([32]byte)), p.owner, spender, p.value, nonce.(*big.Int), p.deadline)
+	if signer, err := recoverPermitSigner(digest, p); err == nil && signer == p.owner {
+		return nil
+	}
+
+	allowance, err := s.permitAllowance(ctx, client, token, p.owner, spender)
+	if err != nil {
+		return err
+	}
+	if allowance.Cmp(total) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: not signed by owner %s for spender %s", errPermitSignature, p.owner.Hex(), spender.Hex())
+}
+
+// validatePermit 校验代付批次: 仅 EVM 链同一 ERC20 代币，授权额度覆盖批次合计且在执行前有效，签名来自源钱包
+func (s *PayoutService) validatePermit(ctx context.Context, req *BatchPayoutRequest) error {
+	client, ok := s.evmClient(req.ChainID)
+	if !ok {
+		return fmt.Errorf("permit payouts are only supported on EVM chains")
+	}
+	if req.UseSmartAccount {
+		return fmt.Errorf("permit payouts cannot use a smart account")
+	}
+	if req.Simulate {
+		return fmt.Errorf("permit payouts cannot be simulated")
+	}
+	permit, err := parsePermit(req.Permit)
+	if err != nil {
+		return err
+	}
+	if deadline := time.Unix(req.Permit.Deadline, 0); !deadline.After(time.Now()) || (!req.ExecuteAt.IsZero() && !deadline.After(req.ExecuteAt)) {
+		return fmt.Errorf("permit deadline %s has passed or is before execution", deadline.UTC().Format(time.RFC3339))
+	}
+
+	token := req.Items[0].TokenAddress
+	total := new(big.Int)
+	for i, item := range req.Items {
+		if isNativeToken(item.asset()) || item.TokenID != "" || !strings.EqualFold(item.TokenAddress, token) {
+			return fmt.Errorf("item[%d]: permit payouts must all transfer the permitted ERC20 token", i)
+		}
+		amount, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok {
+			return fmt.Errorf("item[%d]: invalid amount: %s", i, item.Amount)
+		}
+		total.Add(total, amount)
+	}
+	if total.Cmp(permit.value) > 0 {
+		return fmt.Errorf("batch total %s exceeds permit value %s", total, permit.value)
+	}
+
+	signer := s.signerFor(req.ChainID)
+	if signer == nil {
+		return fmt.Errorf("payout signer is not configured")
+	}
+	err = s.verifyPermit(ctx, client, common.HexToAddress(token), signer.Address(), permit, total)
+	if err != nil && !errors.Is(err, errPermitUnsupported) && !errors.Is(err, errPermitSignature) {
+		return &UnavailableError{Err: fmt.Errorf("failed to verify permit: %w", err)}
+	}
+	return err
+}
+
+// submitPermit 代付任务的授权额度不足时，以 nonceVal 广播源钱包签名的授权交易。
+// 返回已广播的授权交易 (额度已足够或同批次其他任务已提交时为 nil)，调用方的 transferFrom 应使用下一个 nonce。
+func (s *PayoutService) submitPermit(ctx context.Context, client *rpcpool.Pool, job *queue.Job, nonceVal uint64) (*types.Transaction, error) {
+	permit, err := parsePermit(job.Permit)
+	if err != nil {
+		return nil, queue.Permanent(err)
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", job.Amount)
+	}
+	token := common.HexToAddress(job.TokenAddress)
+	spender := common.HexToAddress(job.FromAddress)
+	allowance, err := s.permitAllowance(ctx, client, token, permit.owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowance: %w", err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+
+	ref := queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+	claimed, pendingHash, err := s.queue.ClaimPermitSubmission(ctx, ref, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// 授权交易已由同批次任务以更小的 nonce 广播
+		log.Debug().Str("job_id", job.ID).Str("permit_tx", pendingHash).Msg("Permit already submitted for batch")
+		return nil, nil
+	}
+	if time.Now().Unix() >= job.Permit.Deadline {
+		s.queue.ReleasePermitSubmission(ctx, ref)
+		return nil, queue.Permanent(fmt.Errorf("permit expired and allowance %s is below %s", allowance, amount))
+	}
+
+	tx, err := s.buildPermit(ctx, job, permit, nonceVal)
+	if err == nil {
+		tx, err = s.signTransaction(ctx, tx, job.ChainID, jobSigningOp(job, signPurposePermit))
+	}
+	if err == nil {
+		err = s.broadcastTransaction(ctx, client, job.ChainID, tx)
+	}
+	if err != nil {
+		if rerr := s.queue.ReleasePermitSubmission(ctx, ref); rerr != nil {
+			log.Warn().Err(rerr).Str("job_id", job.ID).Msg("Failed to release permit submission")
+		}
+		return nil, err
+	}
+
+	txHash := tx.Hash().Hex()
+	log.Info().
+		Str("job_id", job.ID).
+		Str("tx_hash", txHash).
+		Str("owner", permit.owner.Hex()).
+		Str("value", permit.value.String()).
+		Msg("Submitted permit for pull payout")
+
+	if err := s.queue.RecordPermitSubmission(ctx, ref, txHash); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record permit submission")
+	}
+	s.trackPermitTx(ctx, job, tx)
+	if err := s.queue.RecordPermit(ctx, ref, job.ID, txHash, ""); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record permit tx")
+	}
+	return tx, nil
+}
+
+// buildPermit 构建 permit(owner, spender, value, deadline, v, r, s) 交易
+func (s *PayoutService) buildPermit(ctx context.Context, job *queue.Job, p *parsedPermit, nonceVal uint64) (*types.Transaction, error) {
+	data, err := s.permitABI.Pack("permit", p.owner, common.HexToAddress(job.FromAddress), p.value, p.deadline, p.v, p.r, p.s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack permit data: %w", err)
+	}
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return nil, err
+	}
+	token := common.HexToAddress(job.TokenAddress)
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(job.ChainID),
+		Nonce:     nonceVal,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       calculateGasBuffer(permitGas, job.Priority),
+		To:        &token,
+		Value:     big.NewInt(0),
+		Data:      data,
+	}), nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/policy"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// checkSpendingPolicy 签名前检查支出策略并预留日/周额度。
+// 违反策略时返回不可重试的错误 (错误码 POLICY_VIOLATION)；返回的 release 用于任务未发出交易时释放预留。
+// 人工批准过的任务跳过检查，但仍计入额度。
+func (s *PayoutService) checkSpendingPolicy(ctx context.Context, job *queue.Job) (release func(), err error) {
+	if !s.policies.Enabled() {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return nil, queue.Permanent(fmt.Errorf("invalid amount: %s", job.Amount))
+	}
+	payout := policy.Payout{
+		ChainID:     job.ChainID,
+		FromAddress: job.FromAddress,
+		ToAddress:   job.ToAddress,
+		Token:       job.Asset(),
+		Amount:      amount,
+	}
+
+	override := job.PolicyOverride
+	if override != nil {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("approved_by", override.ApprovedBy).
+			Str("reason", override.Reason).
+			Msg("Spending policy overridden")
+	} else if v := s.policies.Check(payout, nil); v != nil {
+		return nil, queue.Permanent(v)
+	}
+	if !s.policies.HasVolumeLimits(payout) {
+		return nil, nil
+	}
+
+	err = s.queue.ReserveSpend(ctx, job, func(u queue.SpendUsage) error {
+		if override != nil {
+			return nil
+		}
+		usage := policy.Usage{Daily: u.Daily, Weekly: u.Weekly, RecipientDaily: u.RecipientDaily, RecipientWeekly: u.RecipientWeekly}
+		if v := s.policies.Check(payout, &usage); v != nil {
+			return v
+		}
+		return nil
+	})
+	var violation *policy.Violation
+	if errors.As(err, &violation) {
+		return nil, queue.Permanent(violation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve spending limit: %w", err)
+	}
+	return func() {
+		if err := s.queue.ReleaseSpend(context.WithoutCancel(ctx), job); err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to release spending reservation")
+		}
+	}, nil
+}
+
+// OverrideSpendingPolicy 人工批准因支出策略失败的任务并重新入队。批准人和理由随任务记录。
+func (s *PayoutService) OverrideSpendingPolicy(ctx context.Context, jobID, approvedBy, reason string) (*queue.Job, error) {
+	if approvedBy == "" || reason == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("approved_by and reason are required")}
+	}
+	job, err := s.queue.OverrideDeadLetter(ctx, jobID, policy.Code, queue.PolicyOverride{
+		ApprovedBy: approvedBy,
+		Reason:     reason,
+		ApprovedAt: time.Now().UTC(),
+	})
+	switch {
+	case errors.Is(err, queue.ErrDeadLetterNotFound):
+		return nil, ErrJobNotFound
+	case errors.Is(err, queue.ErrOverrideNotApplicable):
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("job %s was not blocked by a spending policy", jobID)}
+	case err != nil:
+		return nil, err
+	}
+	log.Warn().
+		Str("job_id", jobID).
+		Str("batch_id", job.BatchID).
+		Str("approved_by", approvedBy).
+		Str("reason", reason).
+		Msg("Spending policy override approved, job requeued")
+	return job, nil
+}
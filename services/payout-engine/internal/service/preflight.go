@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Fee reservations used when checking balances before queueing.
+const (
+	erc20TransferGas = 65000
+	tronTRC20FeeSun  = 30_000_000 // ~30 TRX: 65k energy burned at 420 SUN
+)
+
+// RejectedItem 预检余额不足而未入队的支付项
+type RejectedItem struct {
+	ItemID string
+	Reason string
+}
+
+// preflightItem 单笔支付在预检中的开销
+type preflightItem struct {
+	id     string
+	token  string   // 归一化的代币地址，原生代币为 ""
+	amount *big.Int // 实际转出金额 (收款方承担手续费时为净额)
+	gas    *big.Int // 预留的原生代币网络费
+}
+
+// preflightBalances 批次开始前付款地址的余额
+type preflightBalances struct {
+	native *big.Int
+	tokens map[string]*big.Int
+}
+
+// preflightBatch 入队前检查付款地址的原生代币 (Gas) 与代币余额是否足以覆盖整个批次。
+// 余额不足时: AllowPartial 为 false 则拒绝整个批次; 为 true 则按顺序接受能覆盖的支付项。
+// 返回被接受的支付项下标。
+func (s *PayoutService) preflightBatch(ctx context.Context, req *BatchPayoutRequest, amounts []*big.Int) ([]int, []RejectedItem, error) {
+	items, err := s.preflightItems(ctx, req, amounts)
+	if err != nil {
+		return nil, nil, err
+	}
+	balances, err := s.preflightBalances(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read balances: %w", err)
+	}
+	return planBatch(items, balances, req.AllowPartial, s.chainConfig(req.ChainID).NativeToken)
+}
+
+// preflightItems 计算每笔支付的金额和预留网络费
+func (s *PayoutService) preflightItems(ctx context.Context, req *BatchPayoutRequest, amounts []*big.Int) ([]preflightItem, error) {
+	// 智能账户由 paymaster 赞助时不消耗原生代币
+	sponsored := false
+	if req.UseSmartAccount {
+		if aaClient, ok := s.aaClient(req.ChainID); ok {
+			sponsored = aaClient.Sponsored()
+		}
+	}
+
+	nativeGas, tokenGas, err := s.transferFeeReservations(ctx, req.ChainID, req.Priority, sponsored)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]preflightItem, len(req.Items))
+	for i, item := range req.Items {
+		it := preflightItem{id: item.ID, amount: amounts[i], gas: tokenGas}
+		switch {
+		case isNativeToken(item.asset()):
+			it.gas = nativeGas
+		case item.TokenID != "":
+			// TRC10 与 TRX 转账一样只消耗带宽
+			it.gas, it.token = nativeGas, item.TokenID
+		default:
+			it.token = normalizeTokenKey(item.TokenAddress)
+		}
+		items[i] = it
+	}
+	// 代付批次的第一笔另外预留提交授权的网络费 (按代币转账的预留折算 permitGas)
+	if req.Permit != nil && len(items) > 0 {
+		permitFee := new(big.Int).Mul(tokenGas, big.NewInt(permitGas))
+		permitFee.Quo(permitFee, big.NewInt(erc20TransferGas))
+		items[0].gas = new(big.Int).Add(items[0].gas, permitFee)
+	}
+	// 兑换批次每笔另外预留 approve 和兑换交易的网络费
+	if req.Swap != nil {
+		swapFee := swapReservation(tokenGas)
+		for i := range items {
+			items[i].gas = new(big.Int).Add(items[i].gas, swapFee)
+		}
+	}
+	// 跨链批次每笔另外预留目标链铸造交易的网络费
+	if req.SourceChainID != 0 {
+		mintFee := bridgeReservation(tokenGas)
+		for i := range items {
+			items[i].gas = new(big.Int).Add(items[i].gas, mintFee)
+		}
+	}
+	return items, nil
+}
+
+// transferFeeReservations 每笔原生代币转账和代币转账预留的网络费 (原生代币最小单位)
+func (s *PayoutService) transferFeeReservations(ctx context.Context, chainID uint64, priority string, sponsored bool) (*big.Int, *big.Int, error) {
+	if sponsored {
+		return big.NewInt(0), big.NewInt(0), nil
+	}
+	if _, ok := s.tronClient(chainID); ok {
+		return big.NewInt(tronNativeFeeSun), big.NewInt(tronTRC20FeeSun), nil
+	}
+	fees, err := s.suggestFees(ctx, chainID, priority)
+	if err != nil {
+		return nil, nil, err
+	}
+	// L2 上另外预留发布到 L1 的数据费
+	nativeL1, err := s.l1TransferCost(ctx, chainID, fees, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokenL1, err := s.l1TransferCost(ctx, chainID, fees, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transferCost(fees.FeeCap, nativeTransferGas, nativeL1, priority), transferCost(fees.FeeCap, erc20TransferGas, tokenL1, priority), nil
+}
+
+// preflightBalances 读取付款地址的原生代币和批次涉及代币的余额 (代付批次读取源钱包、金库出款读取金库的代币余额，兑换批次按卖出代币余额报价)
+func (s *PayoutService) preflightBalances(ctx context.Context, req *BatchPayoutRequest) (*preflightBalances, error) {
+	native, err := s.nativeBalance(ctx, req.ChainID, req.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	balances := &preflightBalances{native: native, tokens: make(map[string]*big.Int)}
+
+	// 开启即时解包时，包装代币余额可用于原生代币支付
+	for _, item := range req.Items {
+		if !isNativeToken(item.asset()) {
+			continue
+		}
+		wrapped, err := s.wrappedNativeBalance(ctx, req.ChainID, req.FromAddress)
+		if err != nil {
+			return nil, fmt.Errorf("wrapped native: %w", err)
+		}
+		balances.native = new(big.Int).Add(native, wrapped)
+		break
+	}
+
+	source := req.FromAddress
+	if req.Permit != nil {
+		source = req.Permit.Owner
+	}
+	if treasury := s.batchTreasury(req); treasury != "" {
+		source = treasury
+	}
+	for _, item := range req.Items {
+		key := normalizeTokenKey(item.asset())
+		if key == "" || balances.tokens[key] != nil {
+			continue
+		}
+		// 兑换批次: 支付代币由卖出代币换出，可用数量为卖出代币余额报价的最少输出
+		// 跨链批次: 可用数量为源链 USDC 余额
+		var bal *big.Int
+		switch {
+		case req.Swap != nil:
+			bal, err = s.swapAvailable(ctx, req, item.TokenAddress)
+		case req.SourceChainID != 0:
+			bal, err = s.routeAvailable(ctx, req)
+		default:
+			bal, err = s.tokenBalance(ctx, req.ChainID, source, item.asset())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", item.asset(), err)
+		}
+		// 金库出款: 可用额度为金库余额与对付款地址授权额度中较小的一个
+		if req.UseTreasury {
+			bal, err = s.treasuryAvailable(ctx, req.ChainID, source, req.FromAddress, item.TokenAddress, bal)
+			if err != nil {
+				return nil, fmt.Errorf("token %s: %w", item.asset(), err)
+			}
+		}
+		balances.tokens[key] = bal
+	}
+	return balances, nil
+}
+
+// nativeBalance 读取地址的原生代币余额 (wei / SUN)
+func (s *PayoutService) nativeBalance(ctx context.Context, chainID uint64, address string) (*big.Int, error) {
+	if tronClient, ok := s.tronClient(chainID); ok {
+		account, err := tronClient.GetAccount(address)
+		if err != nil {
+			// 未激活账户查询不到，余额视为 0
+			return big.NewInt(0), nil
+		}
+		return big.NewInt(account.GetBalance()), nil
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	return client.BalanceAt(ctx, common.HexToAddress(address), nil)
+}
+
+// tokenBalance 读取地址的 ERC20 / TRC20 / TRC10 余额 (token 为合约地址或 TRC10 资产 ID)
+func (s *PayoutService) tokenBalance(ctx context.Context, chainID uint64, address, token string) (*big.Int, error) {
+	if tronClient, ok := s.tronClient(chainID); ok {
+		if isTRC10Asset(token) {
+			return trc10Balance(tronClient, address, token), nil
+		}
+		return tronClient.TRC20ContractBalance(address, token)
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	return s.erc20BalanceOf(ctx, client, common.HexToAddress(token), common.HexToAddress(address))
+}
+
+// erc20BalanceOf 读取 ERC20 余额
+func (s *PayoutService) erc20BalanceOf(ctx context.Context, client ethCaller, token, owner common.Address) (*big.Int, error) {
+	data, err := s.erc20ABI.Pack("balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("balanceOf call failed: %w", err)
+	}
+	values, err := s.erc20ABI.Unpack("balanceOf", out)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("failed to decode balanceOf: %v", err)
+	}
+	return values[0].(*big.Int), nil
+}
+
+// planBatch 根据余额决定接受哪些支付项
+func planBatch(items []preflightItem, balances *preflightBalances, allowPartial bool, nativeSymbol string) ([]int, []RejectedItem, error) {
+	// 先计算全批次需求，足够时全部接受
+	needNative := new(big.Int)
+	needTokens := make(map[string]*big.Int)
+	for _, it := range items {
+		needNative.Add(needNative, it.gas)
+		if it.token == "" {
+			needNative.Add(needNative, it.amount)
+			continue
+		}
+		if needTokens[it.token] == nil {
+			needTokens[it.token] = new(big.Int)
+		}
+		needTokens[it.token].Add(needTokens[it.token], it.amount)
+	}
+
+	var shortfalls []string
+	if needNative.Cmp(balances.native) > 0 {
+		shortfalls = append(shortfalls, fmt.Sprintf("%s (amount + gas) needs %s, available %s", nativeSymbol, needNative, balances.native))
+	}
+	for token, need := range needTokens {
+		have := balanceOf(balances, token)
+		if need.Cmp(have) > 0 {
+			shortfalls = append(shortfalls, fmt.Sprintf("token %s needs %s, available %s", token, need, have))
+		}
+	}
+
+	if len(shortfalls) == 0 {
+		accepted := make([]int, len(items))
+		for i := range items {
+			accepted[i] = i
+		}
+		return accepted, nil, nil
+	}
+	if !allowPartial {
+		return nil, nil, fmt.Errorf("insufficient balance for batch: %s", strings.Join(shortfalls, "; "))
+	}
+
+	// 部分接受: 按提交顺序接受余额可覆盖的支付项
+	remainingNative := new(big.Int).Set(balances.native)
+	remainingTokens := make(map[string]*big.Int)
+	for token := range needTokens {
+		remainingTokens[token] = new(big.Int).Set(balanceOf(balances, token))
+	}
+
+	var accepted []int
+	var rejected []RejectedItem
+	for i, it := range items {
+		nativeCost := new(big.Int).Set(it.gas)
+		if it.token == "" {
+			nativeCost.Add(nativeCost, it.amount)
+		}
+		if nativeCost.Cmp(remainingNative) > 0 {
+			rejected = append(rejected, RejectedItem{ItemID: it.id, Reason: fmt.Sprintf("insufficient %s balance for amount and gas", nativeSymbol)})
+			continue
+		}
+		if it.token != "" && it.amount.Cmp(remainingTokens[it.token]) > 0 {
+			rejected = append(rejected, RejectedItem{ItemID: it.id, Reason: fmt.Sprintf("insufficient token balance for %s", it.token)})
+			continue
+		}
+		remainingNative.Sub(remainingNative, nativeCost)
+		if it.token != "" {
+			remainingTokens[it.token].Sub(remainingTokens[it.token], it.amount)
+		}
+		accepted = append(accepted, i)
+	}
+
+	if len(accepted) == 0 {
+		return nil, rejected, fmt.Errorf("insufficient balance for batch: %s", strings.Join(shortfalls, "; "))
+	}
+	return accepted, rejected, nil
+}
+
+func balanceOf(balances *preflightBalances, token string) *big.Int {
+	if bal, ok := balances.tokens[token]; ok {
+		return bal
+	}
+	return big.NewInt(0)
+}
+
+// normalizeTokenKey EVM 地址小写化，TRON Base58 地址区分大小写保持原样
+func normalizeTokenKey(tokenAddress string) string {
+	if isNativeToken(tokenAddress) {
+		return ""
+	}
+	if strings.HasPrefix(tokenAddress, "0x") {
+		return strings.ToLower(tokenAddress)
+	}
+	return tokenAddress
+}
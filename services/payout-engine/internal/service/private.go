@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/privatetx"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPrivateTxDeadline 私有交易池未配置截止时间时的默认值 (约 15 个以太坊区块)
+const defaultPrivateTxDeadline = 3 * time.Minute
+
+// usePrivateTx 任务是否经私有交易池广播: 链已配置私有 RPC 且为达到 MinAmount (整币) 的 ERC20 支付
+func (s *PayoutService) usePrivateTx(job *queue.Job) bool {
+	cfg := s.chainConfig(job.ChainID).PrivateTx
+	if cfg.RPCURL == "" || isNativeToken(job.TokenAddress) {
+		return false
+	}
+	if cfg.MinAmount == "" {
+		return true
+	}
+	minAmount, ok := new(big.Rat).SetString(cfg.MinAmount)
+	if !ok {
+		return false
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return false
+	}
+	return s.wholeUnits(job.ChainID, job.TokenAddress, job.TokenDecimals, amount).Cmp(minAmount) >= 0
+}
+
+// privateSender 私有交易池客户端，按方法和地址缓存 (链配置重新加载后使用新地址)
+func (s *PayoutService) privateSender(ctx context.Context, cfg config.PrivateTxConfig) (*privatetx.Sender, error) {
+	key := cfg.Method + " " + cfg.RPCURL
+	s.privateMu.Lock()
+	defer s.privateMu.Unlock()
+	if sender, ok := s.privateSenders[key]; ok {
+		return sender, nil
+	}
+	sender, err := privatetx.Dial(ctx, cfg.RPCURL, cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+	if s.privateSenders == nil {
+		s.privateSenders = make(map[string]*privatetx.Sender)
+	}
+	s.privateSenders[key] = sender
+	return sender, nil
+}
+
+// broadcastJobTx 广播任务交易。大额 ERC20 支付先经私有交易池广播，
+// 返回回退到公共交易池的截止时间；私有通道失败时立即改用公共交易池 (返回零值时间)。
+// 非标准格式的交易 (如 zkSync EIP-712 交易) 只经公共交易池广播。
+func (s *PayoutService) broadcastJobTx(ctx context.Context, client *rpcpool.Pool, job *queue.Job, stx *signedTx) (time.Time, error) {
+	if stx.tx != nil && s.usePrivateTx(job) {
+		cfg := s.chainConfig(job.ChainID).PrivateTx
+		err := s.sendPrivate(ctx, job.ChainID, cfg, stx.tx)
+		if err == nil {
+			deadline := cfg.Deadline
+			if deadline <= 0 {
+				deadline = defaultPrivateTxDeadline
+			}
+			return time.Now().Add(deadline), nil
+		}
+		log.Warn().
+			Err(err).
+			Str("job_id", job.ID).
+			Str("rpc", rpcpool.Redact(cfg.RPCURL)).
+			Msg("Private submission failed, falling back to public mempool")
+	}
+	return time.Time{}, s.broadcastSigned(ctx, client, job.ChainID, stx)
+}
+
+func (s *PayoutService) sendPrivate(ctx context.Context, chainID uint64, cfg config.PrivateTxConfig, tx *types.Transaction) (err error) {
+	ctx, span := tracing.Start(ctx, "rpc.broadcast_private",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tracing.AttrChainID.Int64(int64(chainID)), tracing.AttrTxHash.String(tx.Hash().Hex())),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	sender, err := s.privateSender(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, tx)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultReorgDepth 链未配置 ReorgDepth 时的重组检测深度 (区块数)
+const defaultReorgDepth = 12
+
+// reorgDepth 已上链交易需核对区块哈希的深度，不小于链的确认数: 报告最终状态之前一直检测重组
+func (s *PayoutService) reorgDepth(chainID uint64) uint64 {
+	chainCfg := s.chainConfig(chainID)
+	depth := chainCfg.ReorgDepth
+	if depth == 0 {
+		depth = defaultReorgDepth
+	}
+	return max(depth, chainCfg.Confirmations)
+}
+
+// trackMinedTx 任务交易上链后记录所在区块，并在达到重组深度前持续核对
+func (s *PayoutService) trackMinedTx(ctx context.Context, p *queue.PendingTx, receipt *types.Receipt, hash string) {
+	if p.UserID == "" || p.Prerequisite() {
+		return // 只跟踪付款任务的转账交易
+	}
+	if err := s.queue.RecordBlock(ctx, queue.BatchRef{UserID: p.UserID, BatchID: p.BatchID}, p.JobID, receipt.BlockNumber.Uint64()); err != nil {
+		log.Warn().Err(err).Str("job_id", p.JobID).Msg("Failed to record block number")
+	}
+	mined := &queue.MinedTx{PendingTx: *p, MinedHash: hash, BlockNumber: receipt.BlockNumber.Uint64(), BlockHash: receipt.BlockHash.Hex()}
+	if err := s.queue.TrackMinedTx(ctx, mined); err != nil {
+		log.Warn().Err(err).Str("job_id", p.JobID).Msg("Failed to track mined transaction")
+	}
+}
+
+// checkReorgs 核对所有未达到重组深度的已上链交易
+func (s *PayoutService) checkReorgs(ctx context.Context) {
+	mined, err := s.queue.ListMinedTxs(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list mined transactions")
+		return
+	}
+	heads := make(map[uint64]uint64)
+	for _, m := range mined {
+		client, ok := s.evmClient(m.ChainID)
+		if !ok {
+			s.queue.RemoveMinedTx(ctx, m.Key())
+			continue
+		}
+		head, ok := heads[m.ChainID]
+		if !ok {
+			if head, err = client.BlockNumber(ctx); err != nil {
+				log.Warn().Err(err).Uint64("chain_id", m.ChainID).Msg("Reorg check failed")
+				continue
+			}
+			heads[m.ChainID] = head
+		}
+		if err := s.checkMinedTx(ctx, client, m, head); err != nil {
+			log.Error().Err(err).Str("job_id", m.JobID).Str("tx_hash", m.MinedHash).Msg("Reorg check failed")
+		}
+	}
+}
+
+// checkMinedTx 核对交易所在区块仍在主链上。区块被重组移出时标记任务并重新核对交易是否已在新的主链上；
+// 不在时，nonce 未被其他交易消耗则原样重新广播 (同一 nonce，不会重复付款)，交由卡单检测跟踪确认；
+// 已被消耗则付款未发生，任务改为失败 (REORGED_NONCE_CONSUMED)。
+func (s *PayoutService) checkMinedTx(ctx context.Context, client *rpcpool.Pool, m *queue.MinedTx, head uint64) error {
+	if head >= m.BlockNumber+s.reorgDepth(m.ChainID) {
+		return s.queue.RemoveMinedTx(ctx, m.Key())
+	}
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(m.BlockNumber))
+	if err != nil {
+		return fmt.Errorf("failed to get block header: %w", err)
+	}
+	if header.Hash().Hex() == m.BlockHash {
+		return nil
+	}
+
+	ref := queue.BatchRef{UserID: m.UserID, BatchID: m.BatchID}
+	log.Warn().
+		Str("job_id", m.JobID).
+		Str("tx_hash", m.MinedHash).
+		Uint64("chain_id", m.ChainID).
+		Uint64("block", m.BlockNumber).
+		Str("block_hash", m.BlockHash).
+		Msg("Chain reorg dropped the block of a payout transaction")
+	if err := s.queue.FlagReorged(ctx, ref, m.JobID); err != nil {
+		log.Warn().Err(err).Str("job_id", m.JobID).Msg("Failed to flag reorged job")
+	}
+
+	// 交易可能已被打包进新主链的区块
+	for _, hash := range append([]string{m.TxHash}, m.PrevHashes...) {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if err != nil || receipt == nil {
+			continue
+		}
+		canonical, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil || canonical.Hash() != receipt.BlockHash {
+			continue
+		}
+		log.Info().Str("job_id", m.JobID).Str("tx_hash", hash).Uint64("block", receipt.BlockNumber.Uint64()).Msg("Reorged transaction re-included")
+		s.trackMinedTx(ctx, &m.PendingTx, receipt, hash)
+		s.queue.EmitJobConfirmed(ctx, ref, m.JobID)
+		return nil
+	}
+
+	confirmedNonce, err := client.NonceAt(ctx, common.HexToAddress(m.FromAddress), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get confirmed nonce: %w", err)
+	}
+	if confirmedNonce > m.Nonce {
+		// nonce 已被其他交易消耗，付款未发生: 任务改为失败并通知下游，由运维核对后重新提交
+		log.Error().
+			Str("job_id", m.JobID).
+			Str("tx_hash", m.MinedHash).
+			Uint64("nonce", m.Nonce).
+			Msg("Nonce of reorged transaction consumed by another transaction, payout failed")
+		cause := queue.WithChainError(
+			fmt.Errorf("transaction %s dropped by a chain reorg and nonce %d consumed by another transaction", m.MinedHash, m.Nonce),
+			&queue.ChainError{Code: queue.ChainErrReorgedNonceConsumed, Details: map[string]string{
+				"tx_hash": m.MinedHash,
+				"nonce":   strconv.FormatUint(m.Nonce, 10),
+			}},
+		)
+		if err := s.queue.FailReorged(ctx, ref, m.JobID, cause); err != nil {
+			return fmt.Errorf("failed to mark reorged job failed: %w", err)
+		}
+		return s.queue.RemoveMinedTx(ctx, m.Key())
+	}
+
+	if err := s.rebroadcastMined(ctx, client, m); err != nil {
+		return err
+	}
+	pending := m.PendingTx
+	pending.SentAt = time.Now()
+	pending.PrivateUntil = nil
+	if err := s.queue.TrackPendingTx(ctx, &pending); err != nil {
+		return fmt.Errorf("failed to track rebroadcast transaction: %w", err)
+	}
+	return s.queue.RemoveMinedTx(ctx, m.Key())
+}
+
+// rebroadcastMined 重新广播被重组移出的交易的最新版本 (节点已将其放回交易池时返回 already known)
+func (s *PayoutService) rebroadcastMined(ctx context.Context, client *rpcpool.Pool, m *queue.MinedTx) error {
+	stx, err := parsePendingTx(&m.PendingTx)
+	if err != nil {
+		return err
+	}
+	if err := s.broadcastSigned(ctx, client, m.ChainID, stx); err != nil {
+		return fmt.Errorf("failed to rebroadcast: %w", err)
+	}
+	log.Warn().Str("job_id", m.JobID).Str("tx_hash", m.TxHash).Uint64("nonce", m.Nonce).Msg("Rebroadcast reorged transaction")
+	return nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// reportBatchSize 每轮最多生成的报告数
+const reportBatchSize = 20
+
+// reportReceiptWait 批次结束后等待交易回执 (确认区块、实际网络费) 的最长时间，超过后按已有数据生成报告
+const reportReceiptWait = time.Hour
+
+// 报告格式
+const (
+	ReportFormatCSV  = "csv"
+	ReportFormatJSON = "json"
+)
+
+// reportColumns CSV 报告的列
+var reportColumns = []string{
+	"item_id", "chain_id", "recipient", "amount", "token", "token_symbol", "status",
+	"tx_hash", "block_number", "network_fee", "error_code", "chain_error_code", "error", "updated_at",
+	"reversal_status", "reversal_tx_hash", "reversed_amount",
+}
+
+// 支付项的退回状态 (见 queue.Reversal)
+const (
+	ReversalFull    = "reversed"
+	ReversalPartial = "partially_reversed"
+)
+
+// BatchReport 批次结束后的对账报告
+type BatchReport struct {
+	BatchID        string       `json:"batch_id"`
+	UserID         string       `json:"user_id"`
+	Status         BatchStatus  `json:"status"`
+	TotalCount     int          `json:"total_count"`
+	CompletedCount int          `json:"completed_count"`
+	FailedCount    int          `json:"failed_count"`
+	CancelledCount int          `json:"cancelled_count"`
+	NetworkFee     string       `json:"network_fee"` // 网络费合计 (原生代币最小单位)
+	ManifestHash   string       `json:"manifest_hash,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	CompletedAt    time.Time    `json:"completed_at"` // 最后一个支付项结束的时间
+	GeneratedAt    time.Time    `json:"generated_at"`
+	Items          []ReportItem `json:"items"`
+
+	// 收款方退回款项的支付项数
+	ReversedCount int `json:"reversed_count"`
+}
+
+// ReportItem 报告中的一个支付项
+type ReportItem struct {
+	ItemID         string         `json:"item_id"`
+	ChainID        uint64         `json:"chain_id"`
+	Recipient      string         `json:"recipient"`
+	Amount         string         `json:"amount"`
+	Token          string         `json:"token"` // 代币合约或 TRC10 资产 ID，原生代币为空
+	TokenSymbol    string         `json:"token_symbol,omitempty"`
+	Status         queue.JobState `json:"status"`
+	TxHash         string         `json:"tx_hash,omitempty"`
+	BlockNumber    uint64         `json:"block_number,omitempty"`
+	NetworkFee     string         `json:"network_fee"` // 支付交易及前置交易的网络费 (见 JobStatus.TotalGasFee)
+	ErrorCode      string         `json:"error_code,omitempty"`
+	ChainErrorCode string         `json:"chain_error_code,omitempty"`
+	Error          string         `json:"error,omitempty"` // 失败或取消原因
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	// 退回状态 (reversed / partially_reversed，未退回为空)、退回交易和金额
+	ReversalStatus string `json:"reversal_status,omitempty"`
+	ReversalTxHash string `json:"reversal_tx_hash,omitempty"`
+	ReversedAmount string `json:"reversed_amount,omitempty"`
+}
+
+// newBatchReport 由任务状态生成报告
+func newBatchReport(userID, batchID string, jobs []*queue.JobStatus, manifestHash string) *BatchReport {
+	summary := summarizeBatch(batchID, jobs)
+	report := &BatchReport{
+		BatchID:        batchID,
+		UserID:         userID,
+		Status:         summary.Status,
+		TotalCount:     summary.TotalCount,
+		CompletedCount: summary.CompletedCount,
+		FailedCount:    summary.FailedCount,
+		CancelledCount: summary.CancelledCount,
+		NetworkFee:     summary.GasFee,
+		ManifestHash:   manifestHash,
+		CreatedAt:      summary.CreatedAt,
+		CompletedAt:    summary.UpdatedAt,
+		GeneratedAt:    time.Now().UTC(),
+		Items:          make([]ReportItem, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		item := ReportItem{
+			ItemID:      job.ID,
+			ChainID:     job.ChainID,
+			Recipient:   job.ToAddress,
+			Amount:      job.Amount,
+			Token:       job.Asset(),
+			TokenSymbol: job.TokenSymbol,
+			Status:      job.State,
+			TxHash:      job.TxHash,
+			BlockNumber: job.BlockNumber,
+			NetworkFee:  job.TotalGasFee().String(),
+			ErrorCode:   job.ErrorCode,
+			Error:       job.Error,
+			UpdatedAt:   job.UpdatedAt,
+		}
+		if job.ChainError != nil {
+			item.ChainErrorCode = string(job.ChainError.Code)
+		}
+		if r := job.Reversal; r != nil {
+			item.ReversalStatus = ReversalFull
+			if r.Partial {
+				item.ReversalStatus = ReversalPartial
+			}
+			item.ReversalTxHash = r.TxHash
+			item.ReversedAmount = r.Amount
+			report.ReversedCount++
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report
+}
+
+// WriteCSV 以 CSV 输出支付项 (首行为列名)
+func (r *BatchReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportColumns); err != nil {
+		return err
+	}
+	for _, item := range r.Items {
+		block := ""
+		if item.BlockNumber > 0 {
+			block = strconv.FormatUint(item.BlockNumber, 10)
+		}
+		if err := cw.Write([]string{
+			item.ItemID,
+			strconv.FormatUint(item.ChainID, 10),
+			item.Recipient,
+			item.Amount,
+			item.Token,
+			item.TokenSymbol,
+			string(item.Status),
+			item.TxHash,
+			block,
+			item.NetworkFee,
+			item.ErrorCode,
+			item.ChainErrorCode,
+			item.Error,
+			item.UpdatedAt.UTC().Format(time.RFC3339),
+			item.ReversalStatus,
+			item.ReversalTxHash,
+			item.ReversedAmount,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Encode 按格式 (csv / json) 序列化报告，返回内容和 Content-Type
+func (r *BatchReport) Encode(format string) ([]byte, string, error) {
+	switch strings.ToLower(format) {
+	case ReportFormatCSV:
+		var b strings.Builder
+		if err := r.WriteCSV(&b); err != nil {
+			return nil, "", err
+		}
+		return []byte(b.String()), "text/csv", nil
+	case ReportFormatJSON, "":
+		data, err := json.MarshalIndent(r, "", "  ")
+		return data, "application/json", err
+	}
+	return nil, "", &InvalidArgumentError{Err: fmt.Errorf("unsupported report format: %s (expected csv or json)", format)}
+}
+
+// FindBatchReport 运维下载批次报告 (userID 可为空)。批次尚有未结束的支付项时返回 FailedPreconditionError。
+func (s *PayoutService) FindBatchReport(ctx context.Context, userID, batchID string) (*BatchReport, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.queue.BatchJobs(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if pending := pendingItems(jobs); pending > 0 {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s has %d items still in progress", batchID, pending)}
+	}
+	return s.batchReport(ctx, owner, batchID, jobs)
+}
+
+func (s *PayoutService) batchReport(ctx context.Context, userID, batchID string, jobs []*queue.JobStatus) (*BatchReport, error) {
+	manifest, err := s.queue.GetManifest(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	hash := ""
+	if manifest != nil {
+		hash = manifest.Hash
+	}
+	return newBatchReport(userID, batchID, jobs, hash), nil
+}
+
+// pendingItems 尚未结束的支付项数
+func pendingItems(jobs []*queue.JobStatus) int {
+	pending := 0
+	for _, job := range jobs {
+		switch job.State {
+		case queue.JobStateConfirmed, queue.JobStateFailed, queue.JobStateCancelled:
+		default:
+			pending++
+		}
+	}
+	return pending
+}
+
+// reportReady 已发送的支付项均已记录确认区块 (回执已处理)，或批次结束已超过 reportReceiptWait
+func reportReady(jobs []*queue.JobStatus, now time.Time) bool {
+	var finished time.Time
+	awaiting := false
+	for _, job := range jobs {
+		if job.UpdatedAt.After(finished) {
+			finished = job.UpdatedAt
+		}
+		if job.State == queue.JobStateConfirmed && job.TxHash != "" && job.BlockNumber == 0 {
+			awaiting = true
+		}
+	}
+	return !awaiting || now.Sub(finished) > reportReceiptWait
+}
+
+// RunReportWriter 为结束的批次生成报告并上传到对象存储 (未配置对象存储时不运行)
+func (s *PayoutService) RunReportWriter(ctx context.Context, interval time.Duration) {
+	if s.reportStore == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Batch report writer started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.writeReports(ctx)
+		}
+	}
+}
+
+// writeReports 处理一轮待生成的报告。未就绪或上传失败的报告在租期结束后重新处理。
+func (s *PayoutService) writeReports(ctx context.Context) {
+	refs, err := s.queue.ClaimReports(ctx, time.Now(), reportBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim batch reports")
+		return
+	}
+	for _, ref := range refs {
+		logger := log.With().Str("batch_id", ref.BatchID).Str("user_id", ref.UserID).Logger()
+
+		jobs, err := s.queue.BatchJobs(ctx, ref.UserID, ref.BatchID)
+		if errors.Is(err, queue.ErrBatchNotFound) {
+			s.queue.AckReport(ctx, ref) // 批次状态已过期
+			continue
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to load batch for report")
+			continue
+		}
+		// 死信任务重新入队后批次不会再次排队，保留在 outbox 中等其结束
+		if pendingItems(jobs) > 0 || !reportReady(jobs, time.Now()) {
+			continue
+		}
+
+		report, err := s.batchReport(ctx, ref.UserID, ref.BatchID, jobs)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to build batch report")
+			continue
+		}
+		loc, err := s.uploadReport(ctx, report)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to upload batch report")
+			continue
+		}
+		if err := s.queue.SaveReportLocation(ctx, ref.UserID, ref.BatchID, loc); err != nil {
+			logger.Warn().Err(err).Msg("Failed to save batch report location")
+			continue
+		}
+		if err := s.queue.AckReport(ctx, ref); err != nil {
+			logger.Warn().Err(err).Msg("Failed to ack batch report")
+		}
+		logger.Info().Str("csv", loc.CSV).Str("json", loc.JSON).Msg("Batch report uploaded")
+	}
+}
+
+// uploadReport 上传 CSV 和 JSON 报告 (对象键: <前缀><user_id>/<batch_id>.csv|json)
+func (s *PayoutService) uploadReport(ctx context.Context, report *BatchReport) (*queue.ReportLocation, error) {
+	loc := &queue.ReportLocation{GeneratedAt: report.GeneratedAt}
+	for _, format := range []string{ReportFormatCSV, ReportFormatJSON} {
+		body, contentType, err := report.Encode(format)
+		if err != nil {
+			return nil, err
+		}
+		location, err := s.reportStore.Put(ctx, report.UserID+"/"+report.BatchID+"."+format, contentType, body)
+		if err != nil {
+			return nil, err
+		}
+		if format == ReportFormatCSV {
+			loc.CSV = location
+		} else {
+			loc.JSON = location
+		}
+	}
+	return loc, nil
+}
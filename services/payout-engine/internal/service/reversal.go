@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// transferEventTopic ERC20 Transfer(address,address,uint256) 事件
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// ReversalRequest 运维登记收款方退回款项的交易 (POST /jobs/{id}/reversal)
+type ReversalRequest struct {
+	UserID     string `json:"user_id"` // 可为空
+	TxHash     string `json:"tx_hash"`
+	RecordedBy string `json:"recorded_by"`
+	Note       string `json:"note"`
+}
+
+// reversalChain 核对退回交易所需的链接口
+type reversalChain interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error)
+}
+
+// RecordReversal 核对退回交易并关联到已付款的任务，任务在账本和批次报告中标记为已退回。
+// 退回交易须已上链成功、由原收款地址转出付款资产 (目前支持 EVM 链的原生代币和 ERC20)。
+func (s *PayoutService) RecordReversal(ctx context.Context, jobID string, req ReversalRequest) (*queue.JobStatus, error) {
+	if req.RecordedBy == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("recorded_by is required")}
+	}
+	if !isTxHash(req.TxHash) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid tx_hash: %q", req.TxHash)}
+	}
+	job, err := s.GetJob(ctx, req.UserID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Reversal != nil && strings.EqualFold(job.Reversal.TxHash, req.TxHash) {
+		return job, nil
+	}
+	if job.State != queue.JobStateConfirmed {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("job %s is %s, only paid out jobs can be reversed", jobID, job.State)}
+	}
+	if job.Route != nil {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("reversals of cross-chain payouts are not supported")}
+	}
+	client, ok := s.evmClient(job.ChainID)
+	if !ok {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("reversals can only be verified on EVM chains (chain %d)", job.ChainID)}
+	}
+
+	reversal, err := verifyReversal(ctx, client, job, common.HexToHash(req.TxHash))
+	if err != nil {
+		return nil, err
+	}
+	reversal.RecordedBy = req.RecordedBy
+	reversal.Note = req.Note
+	reversal.RecordedAt = time.Now().UTC()
+
+	status, err := s.queue.RecordReversal(ctx, queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}, jobID, reversal)
+	switch {
+	case errors.Is(err, queue.ErrBatchNotFound):
+		return nil, ErrJobNotFound
+	case errors.Is(err, queue.ErrReversalConflict):
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("job %s or transaction %s is already linked to another reversal", jobID, req.TxHash)}
+	case errors.Is(err, queue.ErrReversalNotApplicable):
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("job %s was not paid out", jobID)}
+	case err != nil:
+		return nil, err
+	}
+	log.Warn().
+		Str("job_id", jobID).
+		Str("batch_id", job.BatchID).
+		Str("tx_hash", reversal.TxHash).
+		Str("amount", reversal.Amount).
+		Bool("partial", reversal.Partial).
+		Str("recorded_by", req.RecordedBy).
+		Msg("Payout reversal recorded")
+	return status, nil
+}
+
+// verifyReversal 核对退回交易: 已上链成功、晚于付款、由原收款地址转出付款资产
+func verifyReversal(ctx context.Context, client reversalChain, job *queue.JobStatus, hash common.Hash) (*queue.Reversal, error) {
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("return transaction %s not found or not yet mined", hash.Hex())}
+	}
+	if err != nil {
+		return nil, &UnavailableError{Err: fmt.Errorf("failed to get return transaction receipt: %w", err)}
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("return transaction %s failed on chain", hash.Hex())}
+	}
+	if job.BlockNumber > 0 && receipt.BlockNumber.Uint64() < job.BlockNumber {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("return transaction %s was mined before the payout", hash.Hex())}
+	}
+
+	recipient := common.HexToAddress(job.ToAddress)
+	amount := new(big.Int)
+	var to common.Address
+	if isNativeToken(job.TokenAddress) {
+		tx, _, err := client.TransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, &UnavailableError{Err: fmt.Errorf("failed to get return transaction: %w", err)}
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, &FailedPreconditionError{Err: fmt.Errorf("failed to recover return transaction sender: %w", err)}
+		}
+		if from == recipient && tx.To() != nil {
+			amount.Set(tx.Value())
+			to = *tx.To()
+		}
+	} else {
+		token := common.HexToAddress(job.TokenAddress)
+		for _, l := range receipt.Logs {
+			if l.Address != token || len(l.Topics) != 3 || l.Topics[0] != transferEventTopic {
+				continue
+			}
+			if common.BytesToAddress(l.Topics[1].Bytes()) != recipient {
+				continue
+			}
+			amount.Add(amount, new(big.Int).SetBytes(l.Data))
+			to = common.BytesToAddress(l.Topics[2].Bytes())
+		}
+	}
+	if amount.Sign() == 0 {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("transaction %s does not return the paid asset from recipient %s", hash.Hex(), job.ToAddress)}
+	}
+
+	paid, _ := new(big.Int).SetString(job.Amount, 10)
+	return &queue.Reversal{
+		TxHash:      hash.Hex(),
+		From:        recipient.Hex(),
+		To:          to.Hex(),
+		Amount:      amount.String(),
+		Partial:     paid != nil && amount.Cmp(paid) < 0,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+	}, nil
+}
+
+// isTxHash 是否为 0x 开头的 32 字节十六进制哈希
+func isTxHash(s string) bool {
+	if len(s) != 66 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	for _, c := range s[2:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
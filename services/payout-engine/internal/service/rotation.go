@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// drainGasLimit 旧地址原生代币转移的 gas 用量 (普通转账)
+const drainGasLimit = 21000
+
+// ChainKeys 链签名密钥的各版本 (当前版本在前，其后为过渡期内的旧版本)
+type ChainKeys struct {
+	ChainID  uint64           `json:"chain_id"`
+	Name     string           `json:"name"`
+	Versions []kms.KeyVersion `json:"versions"`
+}
+
+// SigningKeys 各 EVM 链签名密钥的版本
+func (s *PayoutService) SigningKeys() []ChainKeys {
+	s.chainMu.RLock()
+	pools := s.clients
+	s.chainMu.RUnlock()
+
+	now := time.Now()
+	out := make([]ChainKeys, 0, len(pools))
+	for chainID := range pools {
+		signer := s.signerFor(chainID)
+		if signer == nil {
+			continue
+		}
+		out = append(out, ChainKeys{
+			ChainID:  chainID,
+			Name:     s.chainConfig(chainID).Name,
+			Versions: kms.KeyVersions(signer, now),
+		})
+	}
+	return out
+}
+
+// signerForAddress 控制 from 的签名器: 当前密钥或过渡期内的旧版本密钥 (from 为空或不属于任何版本时为当前密钥)
+func (s *PayoutService) signerForAddress(chainID uint64, from common.Address) kms.Signer {
+	signer := s.signerFor(chainID)
+	if signer == nil || from == (common.Address{}) {
+		return signer
+	}
+	if versioned, ok := kms.SignerForAddress(signer, from, time.Now()); ok {
+		return versioned
+	}
+	return signer
+}
+
+// isSignerAddress 地址是否为链签名密钥的当前版本或过渡期内的旧版本
+func (s *PayoutService) isSignerAddress(chainID uint64, addr common.Address) bool {
+	signer := s.signerFor(chainID)
+	if signer == nil {
+		return false
+	}
+	_, ok := kms.SignerForAddress(signer, addr, time.Now())
+	return ok
+}
+
+// rotateJobSender 密钥轮换后，付款地址仍为旧地址的任务改由当前地址发送，nonce 随之按新地址分配。
+// 归集 (含旧地址余额转移)、代付和跨链路由任务的资金或授权绑定在原地址上，保持不变，由旧密钥在过渡期内签名。
+func (s *PayoutService) rotateJobSender(job *queue.Job) {
+	if job.Sweep || job.Permit != nil || job.Route != nil || !common.IsHexAddress(job.FromAddress) {
+		return
+	}
+	signer := s.signerFor(job.ChainID)
+	if signer == nil {
+		return
+	}
+	active := signer.Address()
+	if common.HexToAddress(job.FromAddress) == active {
+		return
+	}
+	log.Info().
+		Str("job_id", job.ID).
+		Uint64("chain_id", job.ChainID).
+		Str("from", job.FromAddress).
+		Str("to", active.Hex()).
+		Msg("Signing key rotated, job sender switched to the active address")
+	job.FromAddress = active.Hex()
+}
+
+// RunKeyRotation 定期将旧版本密钥地址上的余额转到当前地址。
+// 旧地址没有在途交易和待处理任务后先转出代币，代币转完后再转出扣除网络费的原生代币。
+func (s *PayoutService) RunKeyRotation(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	log.Info().Dur("interval", interval).Msg("Key rotation drain started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for chainID, chainCfg := range s.chainConfigs() {
+			if _, ok := s.evmClient(chainID); ok {
+				s.drainPreviousKeys(ctx, chainID, chainCfg, interval)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Key rotation drain stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainPreviousKeys 转移链上各旧版本密钥地址的余额
+func (s *PayoutService) drainPreviousKeys(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, interval time.Duration) {
+	signer := s.signerFor(chainID)
+	if signer == nil {
+		return
+	}
+	versions := kms.KeyVersions(signer, time.Now())
+	if len(versions) < 2 {
+		return
+	}
+	active := versions[0].Address
+	for _, v := range versions[1:] {
+		busy, err := s.addressBusy(ctx, chainID, v.Address)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("address", v.Address.Hex()).Msg("Previous key drain check failed")
+			continue
+		}
+		if busy {
+			continue
+		}
+		s.drainAddress(ctx, chainID, chainCfg, v, active, interval)
+	}
+}
+
+// addressBusy 地址是否还有待处理任务、在途交易或节点交易池中的交易
+func (s *PayoutService) addressBusy(ctx context.Context, chainID uint64, addr common.Address) (bool, error) {
+	jobs, err := s.queue.PendingJobs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if job.ChainID == chainID && strings.EqualFold(job.FromAddress, addr.Hex()) {
+			return true, nil
+		}
+	}
+	pending, err := s.queue.ListPendingTxs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range pending {
+		if p.ChainID == chainID && strings.EqualFold(p.FromAddress, addr.Hex()) {
+			return true, nil
+		}
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return false, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	confirmed, err := client.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return false, err
+	}
+	nodePending, err := client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return false, err
+	}
+	return nodePending > confirmed, nil
+}
+
+// drainAddress 旧地址余额转到当前地址，每种资产每个周期最多入队一笔
+func (s *PayoutService) drainAddress(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, old kms.KeyVersion, active common.Address, interval time.Duration) {
+	from := old.Address.Hex()
+	tokensLeft := false
+	for _, token := range s.drainTokens(chainID, chainCfg) {
+		balance, err := s.tokenBalance(ctx, chainID, from, token)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("token", token).Msg("Previous key balance check failed")
+			tokensLeft = true
+			continue
+		}
+		if balance.Sign() <= 0 {
+			continue
+		}
+		tokensLeft = true
+		s.queueDrain(ctx, chainID, chainCfg, old, active, token, balance, interval)
+	}
+	// 代币转出需要原生代币支付网络费，转完后再转原生代币
+	if tokensLeft {
+		return
+	}
+
+	balance, err := s.nativeBalance(ctx, chainID, from)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Previous key balance check failed")
+		return
+	}
+	fees, err := s.suggestFees(ctx, chainID, string(gas.PriorityLow))
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Previous key drain skipped")
+		return
+	}
+	// 预留两倍网络费，给卡单替换留出加价空间
+	reserve := new(big.Int).Mul(fees.FeeCap, big.NewInt(2*drainGasLimit))
+	amount := new(big.Int).Sub(balance, reserve)
+	if amount.Sign() <= 0 {
+		return
+	}
+	s.queueDrain(ctx, chainID, chainCfg, old, active, config.SweepNative, amount, interval)
+}
+
+// drainTokens 旧地址需要检查的代币: 白名单代币、归集上限中的代币和包装代币
+func (s *PayoutService) drainTokens(chainID uint64, chainCfg config.ChainConfig) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	add := func(token string) {
+		if !common.IsHexAddress(token) || seen[strings.ToLower(token)] {
+			return
+		}
+		seen[strings.ToLower(token)] = true
+		tokens = append(tokens, token)
+	}
+	for _, token := range s.allowlist.Tokens(chainID) {
+		add(token.Address)
+	}
+	for asset := range chainCfg.Sweep.Ceilings {
+		add(asset)
+	}
+	add(chainCfg.WrappedNative)
+	return tokens
+}
+
+// queueDrain 入队一笔从旧地址到当前地址的转移 (按归集任务处理，由旧密钥签名)
+func (s *PayoutService) queueDrain(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, old kms.KeyVersion, active common.Address, asset string, amount *big.Int, interval time.Duration) {
+	claimed, err := s.queue.ClaimSweep(ctx, chainID, "drain:"+strings.ToLower(old.Address.Hex())+":"+strings.ToLower(asset), interval)
+	if err != nil || !claimed {
+		return
+	}
+	job, err := s.sweepJob(ctx, chainID, chainCfg, old.Address.Hex(), asset, amount)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Failed to build previous key drain job")
+		return
+	}
+	id := fmt.Sprintf("drain-%d-%d", chainID, time.Now().UnixMilli())
+	job.ID = id + "-" + strings.ToLower(job.TokenSymbol)
+	job.BatchID = id
+	job.ToAddress = active.Hex()
+	if err := s.queue.Push(ctx, job); err != nil {
+		log.Error().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Failed to queue previous key drain job")
+		return
+	}
+	log.Info().
+		Uint64("chain_id", chainID).
+		Str("job_id", job.ID).
+		Str("version", old.Version).
+		Str("from", job.FromAddress).
+		Str("to", job.ToAddress).
+		Str("token", job.TokenSymbol).
+		Str("amount", amount.String()).
+		Msg("Previous key drain queued")
+}
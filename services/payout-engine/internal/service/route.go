@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/cctp"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// RoutePendingCode 跨链路由尚未完成 (源链销毁未上链或跨链证明未签发)，任务稍后重试
+const RoutePendingCode = "ROUTE_PENDING"
+
+// routeWaitRetry 等待源链确认和 Circle 签发证明 (通常数分钟，以太坊主网约 15-20 分钟)
+var routeWaitRetry = queue.RetryPolicy{MaxRetries: 60, InitialBackoff: 30 * time.Second, MaxBackoff: 2 * time.Minute, Multiplier: 1.5, Jitter: 0.1}
+
+// 跨链前置交易预留的 Gas
+const (
+	bridgeApproveGas = 60000
+	cctpBurnGas      = 150000
+	cctpMintGas      = 200000
+)
+
+// RoutePendingError 跨链路由停在 Leg 环节，转账暂不发送
+type RoutePendingError struct {
+	Leg           string
+	SourceChainID uint64
+}
+
+func (e *RoutePendingError) Error() string {
+	return fmt.Sprintf("cross-chain route from chain %d is pending at %s", e.SourceChainID, e.Leg)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *RoutePendingError) ErrorCode() string { return RoutePendingCode }
+
+// RetryPolicy implements queue.RetryHint.
+func (e *RoutePendingError) RetryPolicy() queue.RetryPolicy { return routeWaitRetry }
+
+// batchRoute 跨链批次写入任务的路由 (其他批次为空)
+func (s *PayoutService) batchRoute(req *BatchPayoutRequest) *queue.Route {
+	if req.SourceChainID == 0 {
+		return nil
+	}
+	return &queue.Route{Bridge: queue.BridgeCCTP, SourceChainID: req.SourceChainID}
+}
+
+// validateRoute 校验跨链批次: 源链和目标链均为配置了 CCTP 的 EVM 链，全部支付项为目标链 USDC。
+// 资金在源链签名器地址上，逐笔销毁后铸造到目标链付款地址再转账。
+func (s *PayoutService) validateRoute(req *BatchPayoutRequest) error {
+	source := req.SourceChainID
+	if source == req.ChainID {
+		return fmt.Errorf("source_chain_id must differ from chain_id")
+	}
+	if _, ok := s.evmClient(source); !ok {
+		return fmt.Errorf("unsupported source_chain_id: %d", source)
+	}
+	if _, ok := s.evmClient(req.ChainID); !ok {
+		return fmt.Errorf("cross-chain payouts are only supported on EVM chains")
+	}
+	if err := s.chainAvailable(source); err != nil {
+		return err
+	}
+	if !s.chainConfig(source).CCTP.Enabled() || !s.chainConfig(req.ChainID).CCTP.Enabled() {
+		return fmt.Errorf("CCTP is not configured between chain %d and chain %d", source, req.ChainID)
+	}
+	if s.signerFor(source) == nil {
+		return fmt.Errorf("no signer configured for source_chain_id: %d", source)
+	}
+	if req.UseSmartAccount || req.Permit != nil || req.UseTreasury || req.Swap != nil {
+		return fmt.Errorf("cross-chain payouts cannot use a smart account, permit, treasury or swap")
+	}
+	if req.Simulate {
+		return fmt.Errorf("cross-chain payouts cannot be simulated")
+	}
+
+	usdc := s.chainConfig(req.ChainID).CCTP.USDC
+	for i, item := range req.Items {
+		if item.TokenID != "" || !strings.EqualFold(item.TokenAddress, usdc) {
+			return fmt.Errorf("item[%d]: cross-chain payouts must transfer USDC %s", i, usdc)
+		}
+	}
+	return nil
+}
+
+// bridgeReservation 每笔跨链任务在目标链另外预留的铸造网络费 (按代币转账的预留折算)
+func bridgeReservation(tokenGas *big.Int) *big.Int {
+	fee := new(big.Int).Mul(tokenGas, big.NewInt(cctpMintGas))
+	return fee.Quo(fee, big.NewInt(erc20TransferGas))
+}
+
+// routeAvailable 跨链批次可支付的 USDC 数量: 源链签名器地址的 USDC 余额。
+// 源链原生代币不足以支付每笔 approve 和销毁的网络费时拒绝批次。
+func (s *PayoutService) routeAvailable(ctx context.Context, req *BatchPayoutRequest) (*big.Int, error) {
+	source := req.SourceChainID
+	burner := s.signerFor(source).Address().Hex()
+	_, tokenGas, err := s.transferFeeReservations(ctx, source, req.Priority, false)
+	if err != nil {
+		return nil, err
+	}
+	need := new(big.Int).Mul(tokenGas, big.NewInt(int64(len(req.Items))*(bridgeApproveGas+cctpBurnGas)))
+	need.Quo(need, big.NewInt(erc20TransferGas))
+	native, err := s.nativeBalance(ctx, source, burner)
+	if err != nil {
+		return nil, fmt.Errorf("source chain %d: %w", source, err)
+	}
+	if native.Cmp(need) < 0 {
+		return nil, fmt.Errorf("source chain %d native balance %s cannot cover bridge fees %s", source, native, need)
+	}
+	return s.tokenBalance(ctx, source, burner, s.chainConfig(source).CCTP.USDC)
+}
+
+// advanceRoute 推进跨链路由至取得证明: 未销毁时在源链销毁，之后等待待确认交易监控记录跨链消息
+// (见 recordRouteBurn) 并向 Circle 查询证明。未就绪时返回 RoutePendingError，任务按等待策略重试。
+func (s *PayoutService) advanceRoute(ctx context.Context, job *queue.Job) (*queue.RouteStatus, error) {
+	ref := queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+	status, err := s.queue.GetJobStatus(ctx, ref, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load route status: %w", err)
+	}
+	if status == nil {
+		return nil, fmt.Errorf("job status not found")
+	}
+	route := &queue.RouteStatus{Bridge: job.Route.Bridge, SourceChainID: job.Route.SourceChainID}
+	if status.Route != nil {
+		route = status.Route
+	}
+
+	switch {
+	case route.BurnTxHash == "":
+		if err := s.burnForRoute(ctx, job, route); err != nil {
+			return nil, err
+		}
+		return nil, &RoutePendingError{Leg: route.Leg, SourceChainID: route.SourceChainID}
+	case route.Message == "":
+		return nil, &RoutePendingError{Leg: route.Leg, SourceChainID: route.SourceChainID}
+	case route.Attestation == "":
+		attestation, err := s.attestor.Attestation(ctx, common.HexToHash(route.MessageHash))
+		if err != nil {
+			return nil, err
+		}
+		if attestation == nil {
+			return nil, &RoutePendingError{Leg: route.Leg, SourceChainID: route.SourceChainID}
+		}
+		route.Attestation = hexutil.Encode(attestation)
+		route.Leg = queue.RouteLegAttested
+		if err := s.queue.SaveRoute(ctx, ref, job.ID, route); err != nil {
+			return nil, fmt.Errorf("failed to save route: %w", err)
+		}
+		log.Info().Str("job_id", job.ID).Str("message_hash", route.MessageHash).Msg("CCTP attestation received")
+	}
+	return route, nil
+}
+
+// burnForRoute 在源链销毁任务金额的 USDC，铸造给目标链付款地址: 对 TokenMessenger 的授权不足时先 approve。
+// 源链交易按源链和源链签名器地址记录待确认交易。
+func (s *PayoutService) burnForRoute(ctx context.Context, job *queue.Job, route *queue.RouteStatus) error {
+	source := route.SourceChainID
+	client, ok := s.evmClient(source)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("unsupported source chain: %d", source))
+	}
+	signer := s.signerFor(source)
+	if signer == nil {
+		return queue.Permanent(fmt.Errorf("no signer configured for source chain %d", source))
+	}
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("invalid amount: %s", job.Amount))
+	}
+	srcCCTP := s.chainConfig(source).CCTP
+	dstCCTP := s.chainConfig(job.ChainID).CCTP
+	burner := signer.Address()
+	usdc := common.HexToAddress(srcCCTP.USDC)
+	messenger := common.HexToAddress(srcCCTP.TokenMessenger)
+
+	balance, err := s.erc20BalanceOf(ctx, client, usdc, burner)
+	if err != nil {
+		return fmt.Errorf("failed to read source USDC balance: %w", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("source USDC balance %s on chain %d is below %s", balance, source, amount)
+	}
+
+	burnJob := *job
+	burnJob.ChainID = source
+	burnJob.FromAddress = burner.Hex()
+
+	nonceVal, releaseFn, err := s.nonceManager.GetNonceLimited(ctx, source, burner, s.maxInFlight(source))
+	if err != nil {
+		return fmt.Errorf("failed to get source chain nonce: %w", inFlightError(err))
+	}
+	defer releaseFn()
+	fees, err := s.suggestFees(ctx, source, job.Priority)
+	if err != nil {
+		s.nonceManager.ResetNonce(ctx, source, burner)
+		return err
+	}
+
+	allowance, err := s.permitAllowance(ctx, client, usdc, burner, messenger)
+	if err != nil {
+		s.nonceManager.ResetNonce(ctx, source, burner)
+		return fmt.Errorf("failed to read bridge allowance: %w", err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		data, err := s.treasuryABI.Pack("approve", messenger, amount)
+		if err != nil {
+			return fmt.Errorf("failed to pack approve data: %w", err)
+		}
+		tx := newCallTx(source, nonceVal, fees.TipCap, fees.FeeCap, calculateGasBuffer(bridgeApproveGas, job.Priority), usdc, big.NewInt(0), data)
+		if _, err := s.sendBridgeTx(ctx, client, &burnJob, tx, queue.BridgeStepApprove); err != nil {
+			return fmt.Errorf("failed to send bridge approval: %w", s.classifySendError(ctx, source, burner, err))
+		}
+		s.nonceManager.Advance(ctx, source, burner)
+		nonceVal++
+	}
+
+	data, err := cctp.PackDepositForBurn(amount, dstCCTP.Domain, common.HexToAddress(job.FromAddress), usdc)
+	if err != nil {
+		return fmt.Errorf("failed to pack depositForBurn: %w", err)
+	}
+	tx := newCallTx(source, nonceVal, fees.TipCap, fees.FeeCap, calculateGasBuffer(cctpBurnGas, job.Priority), messenger, big.NewInt(0), data)
+	signedTx, err := s.sendBridgeTx(ctx, client, &burnJob, tx, queue.BridgeStepBurn)
+	if err != nil {
+		return fmt.Errorf("failed to send CCTP burn: %w", s.classifySendError(ctx, source, burner, err))
+	}
+
+	route.BurnTxHash = signedTx.Hash().Hex()
+	route.Leg = queue.RouteLegBurnSent
+	log.Info().
+		Str("job_id", job.ID).
+		Str("tx_hash", route.BurnTxHash).
+		Uint64("source_chain_id", source).
+		Uint64("chain_id", job.ChainID).
+		Str("amount", job.Amount).
+		Msg("CCTP burn sent")
+	ref := queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+	if err := s.queue.SaveRoute(ctx, ref, job.ID, route); err != nil {
+		return fmt.Errorf("failed to save route: %w", err)
+	}
+	return nil
+}
+
+// mintForRoute 转账前以 nonceVal 在目标链提交 receiveMessage 铸造 USDC，返回是否已广播 (调用方的转账应使用下一个 nonce)。
+// 已有未回滚的铸造交易 (重试) 时不再铸造。
+func (s *PayoutService) mintForRoute(ctx context.Context, client *rpcpool.Pool, job *queue.Job, route *queue.RouteStatus, nonceVal uint64) (bool, error) {
+	if route.MintTxHash != "" {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(route.MintTxHash))
+		if err != nil || receipt.Status == types.ReceiptStatusSuccessful {
+			log.Debug().Str("job_id", job.ID).Str("mint_tx", route.MintTxHash).Msg("Route USDC already minted")
+			return false, nil
+		}
+	}
+
+	message, err := hexutil.Decode(route.Message)
+	if err != nil {
+		return false, queue.Permanent(fmt.Errorf("invalid route message: %w", err))
+	}
+	attestation, err := hexutil.Decode(route.Attestation)
+	if err != nil {
+		return false, queue.Permanent(fmt.Errorf("invalid route attestation: %w", err))
+	}
+	data, err := cctp.PackReceiveMessage(message, attestation)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack receiveMessage: %w", err)
+	}
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return false, err
+	}
+	transmitter := common.HexToAddress(s.chainConfig(job.ChainID).CCTP.MessageTransmitter)
+	tx := newCallTx(job.ChainID, nonceVal, fees.TipCap, fees.FeeCap, calculateGasBuffer(cctpMintGas, job.Priority), transmitter, big.NewInt(0), data)
+	signedTx, err := s.sendBridgeTx(ctx, client, job, tx, queue.BridgeStepMint)
+	if err != nil {
+		return false, fmt.Errorf("failed to send CCTP mint: %w", err)
+	}
+
+	route.MintTxHash = signedTx.Hash().Hex()
+	route.Leg = queue.RouteLegMintSent
+	log.Info().Str("job_id", job.ID).Str("tx_hash", route.MintTxHash).Msg("CCTP mint sent")
+	if err := s.queue.SaveRoute(ctx, queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}, job.ID, route); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record mint tx")
+	}
+	return true, nil
+}
+
+// sendBridgeTx 签名、广播并记录跨链前置交易 (job 的链和付款地址为交易所在链)
+func (s *PayoutService) sendBridgeTx(ctx context.Context, client *rpcpool.Pool, job *queue.Job, tx *types.Transaction, step string) (*types.Transaction, error) {
+	signedTx, err := s.signTransaction(ctx, tx, job.ChainID, jobSigningOp(job, signPurposeBridge))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.broadcastTransaction(ctx, client, job.ChainID, signedTx); err != nil {
+		return nil, err
+	}
+	s.trackBridgeTx(ctx, job, signedTx, step)
+	return signedTx, nil
+}
+
+// recordRouteBurn 源链销毁交易达到确认数后记录跨链消息 (替换后的交易哈希以上链的为准)。
+// 销毁回滚时清除销毁记录，任务重试时重新销毁。
+func (s *PayoutService) recordRouteBurn(ctx context.Context, p *queue.PendingTx, receipt *types.Receipt, hash string) {
+	ref := queue.BatchRef{UserID: p.UserID, BatchID: p.BatchID}
+	status, err := s.queue.GetJobStatus(ctx, ref, p.JobID)
+	if err != nil || status == nil || status.Route == nil {
+		return
+	}
+	route := status.Route
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		route.BurnTxHash = ""
+		route.Leg = ""
+	} else {
+		transmitter := common.HexToAddress(s.chainConfig(p.ChainID).CCTP.MessageTransmitter)
+		message, err := cctp.MessageFromLogs(receipt.Logs, transmitter)
+		if err != nil {
+			log.Error().Err(err).Str("job_id", p.JobID).Str("tx_hash", hash).Msg("Failed to read CCTP message from burn")
+			return
+		}
+		route.BurnTxHash = hash
+		route.Message = hexutil.Encode(message)
+		route.MessageHash = cctp.MessageHash(message).Hex()
+		route.Leg = queue.RouteLegBurned
+	}
+	if err := s.queue.SaveRoute(ctx, ref, p.JobID, route); err != nil {
+		log.Warn().Err(err).Str("job_id", p.JobID).Msg("Failed to record CCTP burn")
+	}
+}
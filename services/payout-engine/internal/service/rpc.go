@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// ChainRPCStatus 一条链的节点状态
+type ChainRPCStatus struct {
+	ChainID   uint64                   `json:"chain_id"`
+	Name      string                   `json:"name"`
+	Available bool                     `json:"available"`
+	Endpoints []rpcpool.EndpointStatus `json:"endpoints"`
+}
+
+// nodeHealth TRON 节点健康状态 (EVM 链由 rpcpool 跟踪)
+type nodeHealth struct {
+	mu        sync.Mutex
+	streak    int // 连续失败次数
+	downUntil time.Time
+	url       string
+	latency   time.Duration
+	lastError string
+}
+
+// RunRPCProbes 定期检查各链节点 (EVM: eth_blockNumber，TRON: 节点信息)，更新延迟和可用性。
+// 每轮按当前加载的链探测，重新加载后新增的链自动纳入。
+func (s *PayoutService) RunRPCProbes(ctx context.Context) {
+	interval := s.cfg.RPC.ProbeInterval
+	if interval <= 0 {
+		interval = rpcpool.DefaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.probeChains(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PayoutService) probeChains(ctx context.Context) {
+	s.chainMu.RLock()
+	pools := s.clients
+	tronClients := s.tronClients
+	tronHealth := s.tronHealth
+	s.chainMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, pool := range pools {
+		wg.Add(1)
+		go func(pool *rpcpool.Pool) {
+			defer wg.Done()
+			pool.Probe(ctx)
+		}(pool)
+	}
+	for chainID, client := range tronClients {
+		wg.Add(1)
+		go func(chainID uint64, client *tronclient.GrpcClient) {
+			defer wg.Done()
+			s.probeTron(chainID, client, tronHealth[chainID])
+		}(chainID, client)
+	}
+	wg.Wait()
+}
+
+// probeTron 查询节点信息，连续失败达到阈值时将该链标记为不可用一个冷却期
+func (s *PayoutService) probeTron(chainID uint64, client *tronclient.GrpcClient, h *nodeHealth) {
+	start := time.Now()
+	_, err := client.GetNodeInfo()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.streak = 0
+		h.latency = time.Since(start)
+		h.lastError = ""
+		return
+	}
+	h.streak++
+	h.lastError = err.Error()
+	threshold := s.cfg.RPC.ConsecutiveFailures
+	if threshold <= 0 {
+		threshold = rpcpool.DefaultConsecutiveFailures
+	}
+	if h.streak >= threshold && !time.Now().Before(h.downUntil) {
+		cooldown := s.cfg.RPC.Cooldown
+		if cooldown <= 0 {
+			cooldown = rpcpool.DefaultCooldown
+		}
+		h.downUntil = time.Now().Add(cooldown)
+		h.streak = 0
+		log.Warn().Err(err).Uint64("chain_id", chainID).Dur("cooldown", cooldown).Msg("TRON node unavailable")
+	}
+}
+
+// chainAvailable 链是否有可用节点 (validateRequest 据此提前拒绝)
+func (s *PayoutService) chainAvailable(chainID uint64) error {
+	if pool, ok := s.evmClient(chainID); ok && !pool.Available() {
+		return &UnavailableError{Err: fmt.Errorf("chain %d is unavailable: all RPC endpoints are failing", chainID)}
+	}
+	s.chainMu.RLock()
+	h, ok := s.tronHealth[chainID]
+	s.chainMu.RUnlock()
+	if ok {
+		h.mu.Lock()
+		down := time.Now().Before(h.downUntil)
+		h.mu.Unlock()
+		if down {
+			return &UnavailableError{Err: fmt.Errorf("chain %d is unavailable: TRON node is failing", chainID)}
+		}
+	}
+	return nil
+}
+
+// RPCStatus 各链的节点状态
+func (s *PayoutService) RPCStatus() []ChainRPCStatus {
+	s.chainMu.RLock()
+	pools := s.clients
+	tronHealth := s.tronHealth
+	s.chainMu.RUnlock()
+
+	out := make([]ChainRPCStatus, 0, len(pools)+len(tronHealth))
+	for chainID, pool := range pools {
+		out = append(out, ChainRPCStatus{
+			ChainID:   chainID,
+			Name:      s.chainConfig(chainID).Name,
+			Available: pool.Available(),
+			Endpoints: pool.Status(),
+		})
+	}
+	for chainID, h := range tronHealth {
+		now := time.Now()
+		h.mu.Lock()
+		st := rpcpool.EndpointStatus{
+			URL:       rpcpool.Redact(h.url),
+			Latency:   h.latency,
+			Failures:  h.streak,
+			Available: !now.Before(h.downUntil),
+			LastError: h.lastError,
+		}
+		if !st.Available {
+			until := h.downUntil
+			st.DownUntil = &until
+		}
+		h.mu.Unlock()
+		out = append(out, ChainRPCStatus{
+			ChainID:   chainID,
+			Name:      s.chainConfig(chainID).Name,
+			Available: st.Available,
+			Endpoints: []rpcpool.EndpointStatus{st},
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// scheduleRetryDelay 到期入队遇到链暂时不可用时的重试间隔
+const scheduleRetryDelay = time.Minute
+
+// deferred 是否为定时批次 (执行时间在未来；试运行总是立即返回)
+func deferred(req *BatchPayoutRequest) bool {
+	return !req.Simulate && req.ExecuteAt.After(time.Now())
+}
+
+// newScheduledBatch 由请求构造定时批次记录
+func newScheduledBatch(ctx context.Context, req *BatchPayoutRequest) (*queue.ScheduledBatch, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scheduled batch: %w", err)
+	}
+	return &queue.ScheduledBatch{
+		UserID:        req.UserID,
+		BatchID:       req.BatchID,
+		ExecuteAt:     req.ExecuteAt,
+		Request:       data,
+		Items:         len(req.Items),
+		CorrelationID: correlation.FromContext(ctx),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// scheduledResponse 定时批次的提交响应 (费用、预检和任务清单在到期入队时才产生)
+func (s *PayoutService) scheduledResponse(req *BatchPayoutRequest) *BatchPayoutResponse {
+	message := fmt.Sprintf("Scheduled %d payments for %s", len(req.Items), req.ExecuteAt.UTC().Format(time.RFC3339))
+	testnet := s.isTestnetChain(req.ChainID)
+	if testnet {
+		message = "[TESTNET] " + message
+	}
+	return &BatchPayoutResponse{
+		BatchID:   req.BatchID,
+		Status:    BatchStatusScheduled,
+		Message:   message,
+		Testnet:   testnet,
+		ExecuteAt: req.ExecuteAt,
+	}
+}
+
+// scheduleBatch 保存定时批次，到执行时间后由 RunBatchScheduler 入队
+func (s *PayoutService) scheduleBatch(ctx context.Context, req *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	existing, err := s.queue.GetScheduledBatch(ctx, req.UserID, req.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.State == queue.ScheduleStatePending {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is already scheduled, use UpdateScheduledBatch to change it", req.BatchID)}
+	}
+
+	batch, err := newScheduledBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.queue.ScheduleBatch(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to schedule batch: %w", err)
+	}
+	log.Info().
+		Str("batch_id", req.BatchID).
+		Str("user_id", req.UserID).
+		Str(correlation.LogField, batch.CorrelationID).
+		Int("items", batch.Items).
+		Time("execute_at", req.ExecuteAt).
+		Msg("Batch scheduled")
+	return s.scheduledResponse(req), nil
+}
+
+// UpdateScheduledBatch 在执行时间之前整体替换定时批次 (支付项、执行时间等)。
+// execute_at 为空或已过时立即入队，修改后超过审批阈值时转为待审批。批次已入队或已取消时返回 FailedPrecondition。
+func (s *PayoutService) UpdateScheduledBatch(ctx context.Context, req *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	if req.Simulate {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("simulate is not supported when updating a scheduled batch")}
+	}
+	if err := s.validateRequest(ctx, req); err != nil {
+		if IsUnavailable(err) {
+			return nil, err
+		}
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("validation failed: %w", err)}
+	}
+
+	// 先认领，避免与到期入队或取消并发
+	previous, err := s.queue.ClaimScheduledBatch(ctx, req.UserID, req.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is not scheduled (already queued, cancelled or unknown)", req.BatchID)}
+	}
+	restore := func() {
+		if err := s.queue.ScheduleBatch(ctx, previous); err != nil {
+			log.Error().Err(err).Str("batch_id", req.BatchID).Msg("Failed to restore scheduled batch")
+		}
+	}
+
+	var resp *BatchPayoutResponse
+	switch {
+	case s.requiresApproval(req):
+		// 修改后超过审批阈值: 转为待审批，放行后再按 execute_at 定时或入队
+		requestHash, err := hashRequest(req)
+		if err == nil {
+			resp, err = s.holdForApproval(ctx, req, requestHash)
+		}
+		if err != nil {
+			restore()
+			return nil, err
+		}
+		if err := s.queue.DeleteScheduledBatch(ctx, req.UserID, req.BatchID); err != nil {
+			log.Warn().Err(err).Str("batch_id", req.BatchID).Msg("Failed to delete scheduled batch record")
+		}
+	case deferred(req):
+		batch, err := newScheduledBatch(ctx, req)
+		if err != nil {
+			restore()
+			return nil, err
+		}
+		batch.CreatedAt = previous.CreatedAt
+		if batch.CorrelationID == "" {
+			batch.CorrelationID = previous.CorrelationID
+		}
+		if err := s.queue.ScheduleBatch(ctx, batch); err != nil {
+			restore()
+			return nil, fmt.Errorf("failed to schedule batch: %w", err)
+		}
+		resp = s.scheduledResponse(req)
+	default:
+		if resp, err = s.submitBatch(ctx, req); err != nil {
+			restore()
+			return nil, err
+		}
+		if err := s.queue.DeleteScheduledBatch(ctx, req.UserID, req.BatchID); err != nil {
+			log.Warn().Err(err).Str("batch_id", req.BatchID).Msg("Failed to delete scheduled batch record")
+		}
+	}
+
+	log.Info().
+		Str("batch_id", req.BatchID).
+		Str("user_id", req.UserID).
+		Int("items", len(req.Items)).
+		Time("previous_execute_at", previous.ExecuteAt).
+		Time("execute_at", req.ExecuteAt).
+		Str("status", string(resp.Status)).
+		Msg("Scheduled batch updated")
+	return resp, nil
+}
+
+// cancelScheduledBatch 取消尚未入队的定时批次。批次不在定时索引中时返回 ok=false。
+func (s *PayoutService) cancelScheduledBatch(ctx context.Context, userID, batchID string) (int, bool, error) {
+	batch, err := s.queue.ClaimScheduledBatch(ctx, userID, batchID)
+	if err != nil || batch == nil {
+		return 0, false, err
+	}
+	if err := s.queue.FinishScheduledBatch(ctx, batch, queue.ScheduleStateCancelled, nil); err != nil {
+		return 0, false, err
+	}
+	return batch.Items, true, nil
+}
+
+// scheduledBatchStatus 尚未入队的定时批次状态 (没有定时记录时为 nil)
+func (s *PayoutService) scheduledBatchStatus(ctx context.Context, userID, batchID string) (*BatchStatusResult, error) {
+	batch, err := s.queue.GetScheduledBatch(ctx, userID, batchID)
+	if err != nil || batch == nil {
+		return nil, err
+	}
+	result := &BatchStatusResult{
+		BatchID:    batchID,
+		TotalCount: batch.Items,
+		CreatedAt:  batch.CreatedAt,
+		UpdatedAt:  batch.UpdatedAt,
+		ExecuteAt:  batch.ExecuteAt,
+		Error:      batch.Error,
+	}
+	switch batch.State {
+	case queue.ScheduleStateCancelled:
+		result.Status = BatchStatusCancelled
+		result.CancelledCount = batch.Items
+	case queue.ScheduleStateFailed:
+		result.Status = BatchStatusFailed
+		result.FailedCount = batch.Items
+	default:
+		result.Status = BatchStatusScheduled
+		result.PendingCount = batch.Items
+	}
+	return result, nil
+}
+
+// RunBatchScheduler 定期将到达执行时间的定时批次入队。
+// 入队时重新校验请求并预检余额 (如发薪日当天才注资)，失败的批次标记为 failed 并保留原因供查询。
+func (s *PayoutService) RunBatchScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Batch scheduler started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Batch scheduler stopped")
+			return
+		case <-ticker.C:
+			refs, err := s.queue.DueScheduledBatches(ctx, time.Now())
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to list due scheduled batches")
+				continue
+			}
+			for _, ref := range refs {
+				s.releaseScheduledBatch(ctx, ref)
+			}
+		}
+	}
+}
+
+// releaseScheduledBatch 认领并入队一个到期的定时批次
+func (s *PayoutService) releaseScheduledBatch(ctx context.Context, ref queue.BatchRef) {
+	batch, err := s.queue.ClaimScheduledBatch(ctx, ref.UserID, ref.BatchID)
+	if err != nil {
+		log.Warn().Err(err).Str("batch_id", ref.BatchID).Msg("Failed to claim scheduled batch")
+		return
+	}
+	if batch == nil {
+		return // 其他实例已入队，或刚被取消/修改
+	}
+	ctx = correlation.WithID(ctx, batch.CorrelationID)
+
+	var req BatchPayoutRequest
+	err = json.Unmarshal(batch.Request, &req)
+	if err == nil {
+		err = s.validateRequest(ctx, &req)
+	}
+	var resp *BatchPayoutResponse
+	if err == nil {
+		resp, err = s.submitBatch(ctx, &req)
+	}
+
+	switch {
+	case IsUnavailable(err):
+		log.Warn().Err(err).Str("batch_id", ref.BatchID).Dur("retry_in", scheduleRetryDelay).Msg("Scheduled batch deferred, chain unavailable")
+		if err := s.queue.RetryScheduledBatch(ctx, batch, time.Now().Add(scheduleRetryDelay)); err != nil {
+			log.Error().Err(err).Str("batch_id", ref.BatchID).Msg("Failed to reschedule batch")
+		}
+	case err != nil:
+		log.Error().
+			Err(err).
+			Str("batch_id", ref.BatchID).
+			Str("user_id", ref.UserID).
+			Str(correlation.LogField, batch.CorrelationID).
+			Time("execute_at", batch.ExecuteAt).
+			Msg("Scheduled batch failed to queue")
+		if err := s.queue.FinishScheduledBatch(ctx, batch, queue.ScheduleStateFailed, err); err != nil {
+			log.Error().Err(err).Str("batch_id", ref.BatchID).Msg("Failed to record scheduled batch failure")
+		}
+	default:
+		if err := s.queue.DeleteScheduledBatch(ctx, ref.UserID, ref.BatchID); err != nil {
+			log.Warn().Err(err).Str("batch_id", ref.BatchID).Msg("Failed to delete scheduled batch record")
+		}
+		log.Info().
+			Str("batch_id", ref.BatchID).
+			Str("user_id", ref.UserID).
+			Str(correlation.LogField, batch.CorrelationID).
+			Time("execute_at", batch.ExecuteAt).
+			Int("rejected", len(resp.Rejected)).
+			Msg("Scheduled batch queued")
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/screening"
+	"github.com/rs/zerolog/log"
+)
+
+// screenRecipient 广播前筛查收款地址 (制裁名单、高风险地址)。
+// 命中时返回不可重试的错误 (错误码 SCREENING_BLOCKED)；服务商不可用时返回可重试的错误，不放行。
+// 批次带人工批准的越过标记时跳过。
+func (s *PayoutService) screenRecipient(ctx context.Context, job *queue.Job) error {
+	if s.screener == nil {
+		return nil
+	}
+	if job.ScreeningOverride != "" {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("batch_id", job.BatchID).
+			Str("to", job.ToAddress).
+			Str("reason", job.ScreeningOverride).
+			Msg("Recipient screening overridden for batch")
+		return nil
+	}
+
+	result, err := s.screener.Screen(ctx, job.ChainID, job.ToAddress)
+	if err != nil {
+		return fmt.Errorf("recipient screening unavailable: %w", err)
+	}
+	if result.Blocked(s.cfg.Screening.BlockRisk) {
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("to", job.ToAddress).
+			Bool("sanctioned", result.Sanctioned).
+			Str("risk", result.Risk).
+			Strs("categories", result.Categories).
+			Msg("Recipient blocked by screening")
+		return queue.Permanent(&screening.BlockedError{Result: result})
+	}
+	return nil
+}
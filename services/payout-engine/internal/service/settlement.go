@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/settlement"
+	"github.com/rs/zerolog/log"
+)
+
+// 汇总投递渠道 (各自去重和重试)
+const (
+	settlementChannelWebhook = "webhook"
+	settlementChannelEmail   = "email"
+)
+
+// RunSettlementReporter 每个 UTC 自然日结束 (加 Delay) 后向各租户投递前一日的结算汇总
+func (s *PayoutService) RunSettlementReporter(ctx context.Context, interval time.Duration) {
+	if !s.settlementDests.Enabled() {
+		return
+	}
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	log.Info().Dur("interval", interval).Dur("delay", s.cfg.Settlement.Delay).Msg("Settlement reporter started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastDone time.Time
+	for {
+		start, end := settlementPeriod(time.Now(), s.cfg.Settlement.Delay)
+		if !end.Equal(lastDone) && s.sendSettlementReports(ctx, start, end) {
+			lastDone = end
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// settlementPeriod 最近一个已可汇总的 UTC 自然日 [start, end)
+func settlementPeriod(now time.Time, delay time.Duration) (time.Time, time.Time) {
+	end := now.Add(-delay).UTC().Truncate(24 * time.Hour)
+	return end.Add(-24 * time.Hour), end
+}
+
+// sendSettlementReports 投递一个周期的汇总，全部成功 (或已由其他实例发送) 时返回 true
+func (s *PayoutService) sendSettlementReports(ctx context.Context, start, end time.Time) bool {
+	summaries, err := s.BuildSettlementSummaries(ctx, start, end)
+	if err != nil {
+		log.Error().Err(err).Time("period_start", start).Msg("Failed to build settlement summaries")
+		return false
+	}
+
+	ok := true
+	for tenant, summary := range summaries {
+		dest, found := s.settlementDests.For(tenant)
+		if !found {
+			continue
+		}
+		if dest.WebhookURL != "" {
+			ok = s.deliverSettlement(ctx, summary, settlementChannelWebhook, func() error {
+				return s.settlementSender.SendWebhook(ctx, dest, summary)
+			}) && ok
+		}
+		if len(dest.Email) > 0 && s.settlementSender.EmailEnabled() {
+			ok = s.deliverSettlement(ctx, summary, settlementChannelEmail, func() error {
+				return s.settlementSender.SendEmail(dest, summary)
+			}) && ok
+		}
+	}
+	return ok
+}
+
+// deliverSettlement 占用渠道后发送，失败时释放以便下次重试
+func (s *PayoutService) deliverSettlement(ctx context.Context, summary *settlement.Summary, channel string, send func() error) bool {
+	claimed, err := s.queue.ClaimSettlement(ctx, summary.Tenant, summary.Day(), channel)
+	if err != nil {
+		log.Warn().Err(err).Str("tenant", summary.Tenant).Msg("Failed to claim settlement report")
+		return false
+	}
+	if !claimed {
+		return true // 已发送
+	}
+
+	if err := send(); err != nil {
+		log.Error().Err(err).Str("tenant", summary.Tenant).Str("day", summary.Day()).Str("channel", channel).Msg("Settlement report delivery failed")
+		if err := s.queue.ReleaseSettlement(ctx, summary.Tenant, summary.Day(), channel); err != nil {
+			log.Warn().Err(err).Str("tenant", summary.Tenant).Msg("Failed to release settlement claim")
+		}
+		return false
+	}
+	log.Info().
+		Str("tenant", summary.Tenant).
+		Str("day", summary.Day()).
+		Str("channel", channel).
+		Int("batches", summary.Batches.Total).
+		Msg("Settlement report delivered")
+	return true
+}
+
+// BuildSettlementSummaries 汇总在 [from, to) 内结束 (无未完成任务且最后更新在区间内) 的批次，按租户分组
+func (s *PayoutService) BuildSettlementSummaries(ctx context.Context, from, to time.Time) (map[string]*settlement.Summary, error) {
+	// 批次创建不晚于结束时间；状态保留期外的批次已无法汇总
+	refs, err := s.queue.ListBatches(ctx, "", time.Time{}, to)
+	if err != nil {
+		return nil, err
+	}
+
+	builders := make(map[string]*settlementBuilder)
+	for _, ref := range refs {
+		jobs, err := s.queue.BatchJobs(ctx, ref.UserID, ref.BatchID)
+		if errors.Is(err, queue.ErrBatchNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		batch := summarizeBatch(ref.BatchID, jobs)
+		if batch.PendingCount > 0 || batch.UpdatedAt.Before(from) || !batch.UpdatedAt.Before(to) {
+			continue
+		}
+
+		b, ok := builders[ref.UserID]
+		if !ok {
+			b = newSettlementBuilder(ref.UserID, from, to)
+			builders[ref.UserID] = b
+		}
+		b.add(batch)
+	}
+
+	summaries := make(map[string]*settlement.Summary, len(builders))
+	for tenant, b := range builders {
+		summaries[tenant] = b.build(s.chainConfigs())
+	}
+	return summaries, nil
+}
+
+// settlementBuilder 累加单个租户的批次
+type settlementBuilder struct {
+	summary *settlement.Summary
+	totals  map[settlementTokenKey]*settlement.TokenTotal
+	amounts map[settlementTokenKey]*big.Int
+	gas     map[uint64]*big.Int
+	gasTxs  map[uint64]int
+}
+
+type settlementTokenKey struct {
+	chainID uint64
+	token   string
+}
+
+func newSettlementBuilder(tenant string, from, to time.Time) *settlementBuilder {
+	return &settlementBuilder{
+		summary: &settlement.Summary{
+			Tenant:      tenant,
+			PeriodStart: from.UTC(),
+			PeriodEnd:   to.UTC(),
+			Totals:      []settlement.TokenTotal{},
+			GasSpend:    []settlement.GasTotal{},
+			Failures:    []settlement.Failure{},
+		},
+		totals:  make(map[settlementTokenKey]*settlement.TokenTotal),
+		amounts: make(map[settlementTokenKey]*big.Int),
+		gas:     make(map[uint64]*big.Int),
+		gasTxs:  make(map[uint64]int),
+	}
+}
+
+// addGas 累加一笔交易的网络费 (未上链的交易没有费用)
+func (b *settlementBuilder) addGas(chainID uint64, gasFee string) {
+	fee, ok := new(big.Int).SetString(gasFee, 10)
+	if !ok {
+		return
+	}
+	if b.gas[chainID] == nil {
+		b.gas[chainID] = new(big.Int)
+	}
+	b.gas[chainID].Add(b.gas[chainID], fee)
+	b.gasTxs[chainID]++
+}
+
+func (b *settlementBuilder) add(batch *BatchStatusResult) {
+	sum := b.summary
+	sum.Batches.Total++
+	switch batch.Status {
+	case BatchStatusCompleted:
+		sum.Batches.Completed++
+	case BatchStatusPartialFailed:
+		sum.Batches.PartialFailed++
+	case BatchStatusFailed:
+		sum.Batches.Failed++
+	case BatchStatusCancelled:
+		sum.Batches.Cancelled++
+	}
+
+	for _, job := range batch.Items {
+		sum.Jobs.Total++
+		b.addGas(job.ChainID, job.GasFee)
+		b.addGas(job.ChainID, job.UnwrapGasFee) // 解包交易的网络费
+		b.addGas(job.ChainID, job.PermitGasFee) // 代付授权交易的网络费
+		b.addGas(job.ChainID, job.SwapGasFee)   // 转账前兑换交易的网络费
+		if job.Route != nil {
+			// 跨链路由: 铸造在目标链，approve 和销毁按源链原生代币计入
+			b.addGas(job.ChainID, job.Route.MintGasFee)
+			b.addGas(job.Route.SourceChainID, job.Route.BurnGasFee)
+		}
+
+		switch job.State {
+		case queue.JobStateConfirmed:
+			sum.Jobs.Confirmed++
+			amount, ok := new(big.Int).SetString(job.Amount, 10)
+			if !ok {
+				continue
+			}
+			key := settlementTokenKey{chainID: job.ChainID, token: normalizeTokenKey(job.Asset())}
+			total, ok := b.totals[key]
+			if !ok {
+				total = &settlement.TokenTotal{ChainID: key.chainID, Token: key.token, Symbol: job.TokenSymbol}
+				b.totals[key] = total
+				b.amounts[key] = new(big.Int)
+			}
+			b.amounts[key].Add(b.amounts[key], amount)
+			total.Count++
+		case queue.JobStateFailed:
+			sum.Jobs.Failed++
+			if len(sum.Failures) >= settlement.MaxFailures {
+				sum.FailuresTruncated = true
+				continue
+			}
+			sum.Failures = append(sum.Failures, settlement.Failure{
+				BatchID:   job.BatchID,
+				JobID:     job.ID,
+				ChainID:   job.ChainID,
+				ToAddress: job.ToAddress,
+				Amount:    job.Amount,
+				Token:     job.Asset(),
+				Error:     job.Error,
+			})
+		case queue.JobStateCancelled:
+			sum.Jobs.Cancelled++
+		}
+	}
+}
+
+func (b *settlementBuilder) build(chains map[uint64]config.ChainConfig) *settlement.Summary {
+	sum := b.summary
+	sum.GeneratedAt = time.Now().UTC()
+
+	for key, total := range b.totals {
+		total.Amount = b.amounts[key].String()
+		if total.Token == "" && total.Symbol == "" {
+			total.Symbol = chains[key.chainID].NativeToken
+		}
+		sum.Totals = append(sum.Totals, *total)
+	}
+	sort.Slice(sum.Totals, func(i, j int) bool {
+		if sum.Totals[i].ChainID != sum.Totals[j].ChainID {
+			return sum.Totals[i].ChainID < sum.Totals[j].ChainID
+		}
+		return sum.Totals[i].Token < sum.Totals[j].Token
+	})
+
+	for chainID, fee := range b.gas {
+		sum.GasSpend = append(sum.GasSpend, settlement.GasTotal{
+			ChainID:     chainID,
+			NativeToken: chains[chainID].NativeToken,
+			Amount:      fee.String(),
+			Count:       b.gasTxs[chainID],
+		})
+	}
+	sort.Slice(sum.GasSpend, func(i, j int) bool { return sum.GasSpend[i].ChainID < sum.GasSpend[j].ChainID })
+	return sum
+}
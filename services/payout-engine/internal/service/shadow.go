@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// shadowSubmitTimeout 镜像提交 (校验、预检、入队) 的超时
+const shadowSubmitTimeout = 2 * time.Minute
+
+// shadowID 影子批次、支付项和用户的 ID 前缀，与主网批次的任务互不冲突
+const shadowID = "shadow-"
+
+// mirrorToShadow 影子模式下将已受理的主网批次异步镜像到该链的影子测试网。
+// 镜像失败只记录日志，不影响主网批次。
+func (s *PayoutService) mirrorToShadow(ctx context.Context, req *BatchPayoutRequest, resp *BatchPayoutResponse) {
+	if !s.cfg.Shadow.Enabled || s.isTestnetChain(req.ChainID) || resp.Status == BatchStatusPendingApproval {
+		return
+	}
+	shadowReq, err := s.shadowRequest(req)
+	if err != nil {
+		log.Debug().Err(err).Str("batch_id", req.BatchID).Msg("Batch not mirrored to shadow testnet")
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shadowSubmitTimeout)
+		defer cancel()
+		logger := log.With().
+			Str("batch_id", req.BatchID).
+			Str("shadow_batch_id", shadowReq.BatchID).
+			Uint64("shadow_chain_id", shadowReq.ChainID).
+			Logger()
+
+		shadowResp, err := s.SubmitBatchPayout(ctx, shadowReq)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Shadow batch submission failed")
+			return
+		}
+		logger.Info().
+			Str("status", string(shadowResp.Status)).
+			Int("items", len(shadowReq.Items)).
+			Int("rejected", len(shadowResp.Rejected)).
+			Msg("Batch mirrored to shadow testnet")
+	}()
+}
+
+// shadowRequest 主网批次对应的影子批次: 金额按 SHADOW_SCALE_BPS 缩小 (最小 1)，代币按链配置映射，
+// 未映射的代币项不镜像。付款方为测试网签名器，不带回调、收费、代付、金库、兑换和跨链等选项。
+func (s *PayoutService) shadowRequest(req *BatchPayoutRequest) (*BatchPayoutRequest, error) {
+	shadow := s.chainConfig(req.ChainID).Shadow
+	if shadow.ChainID == 0 {
+		return nil, fmt.Errorf("chain %d has no shadow testnet", req.ChainID)
+	}
+	if !s.isTestnetChain(shadow.ChainID) {
+		return nil, fmt.Errorf("shadow chain %d is not a configured testnet", shadow.ChainID)
+	}
+	from, err := s.payoutAddress(shadow.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("shadow chain %d: %w", shadow.ChainID, err)
+	}
+
+	scale := big.NewInt(int64(s.cfg.Shadow.ScaleBps))
+	var items []PayoutItem
+	for _, item := range req.Items {
+		if item.TokenID != "" {
+			continue // TRC10 资产在测试网上没有对应资产
+		}
+		token := ""
+		if !isNativeToken(item.TokenAddress) {
+			token = shadowToken(shadow.Tokens, item.TokenAddress)
+			if token == "" {
+				continue
+			}
+		}
+		amount, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		amount.Mul(amount, scale).Quo(amount, big.NewInt(10000))
+		if amount.Sign() == 0 {
+			amount.SetInt64(1)
+		}
+		items = append(items, PayoutItem{
+			ID:               shadowID + item.ID,
+			RecipientAddress: item.RecipientAddress,
+			Amount:           amount.String(),
+			TokenAddress:     token,
+			TokenSymbol:      item.TokenSymbol,
+			TokenDecimals:    item.TokenDecimals,
+		})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items can be mirrored to chain %d", shadow.ChainID)
+	}
+
+	return &BatchPayoutRequest{
+		BatchID:      shadowID + req.BatchID,
+		UserID:       shadowID + req.UserID,
+		FromAddress:  from,
+		ChainID:      shadow.ChainID,
+		Items:        items,
+		Priority:     req.Priority,
+		ExecuteAt:    req.ExecuteAt,
+		AllowPartial: true,
+	}, nil
+}
+
+// shadowToken 主网代币在影子测试网上的地址 (EVM 地址不区分大小写)
+func shadowToken(tokens map[string]string, tokenAddress string) string {
+	key := normalizeTokenKey(tokenAddress)
+	for mainnet, testnet := range tokens {
+		if normalizeTokenKey(mainnet) == key {
+			return testnet
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/ledger"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// 签名用途 (签名审计日志)
+const (
+	signPurposePayout      = "payout"
+	signPurposeReplacement = "replacement"
+	signPurposeNonceFill   = "nonce_fill"
+	signPurposeCanary      = "canary"
+	signPurposeUnwrap      = "unwrap"
+	signPurposeUserOp      = "user_operation"
+	signPurposeManifest    = "manifest"
+	signPurposeGasTank     = "gas_tank"
+	signPurposePermit      = "permit"
+	signPurposeSwap        = "swap"
+	signPurposeBridge      = "bridge"
+)
+
+// systemRequestor 引擎自身发起的签名 (gas 补充、nonce 填补、熔断探测等)
+const systemRequestor = "payout-engine"
+
+// signingOp 一次签名的来源
+type signingOp struct {
+	userID  string // 发起签名的租户 (为空时记为 systemRequestor)
+	batchID string
+	jobID   string
+	purpose string
+
+	// 发送地址: 密钥轮换后由该地址对应版本的密钥签名 (为空时使用当前密钥)
+	from common.Address
+}
+
+// jobSigningOp 任务发起的签名
+func jobSigningOp(job *queue.Job, purpose string) signingOp {
+	op := signingOp{userID: job.UserID, batchID: job.BatchID, jobID: job.ID, purpose: purpose}
+	if common.IsHexAddress(job.FromAddress) {
+		op.from = common.HexToAddress(job.FromAddress)
+	}
+	return op
+}
+
+// requestor 发起签名的租户，引擎自身发起时为 systemRequestor
+func (op signingOp) requestor() string {
+	if op.userID == "" {
+		return systemRequestor
+	}
+	return op.userID
+}
+
+// signingContext 附带签名来源，由 kms 签名调用审计记录
+func signingContext(ctx context.Context, op signingOp) context.Context {
+	return kms.WithCaller(ctx, kms.Caller{
+		Requestor:     op.requestor(),
+		UserID:        op.userID,
+		BatchID:       op.batchID,
+		JobID:         op.jobID,
+		Purpose:       op.purpose,
+		CorrelationID: correlation.FromContext(ctx),
+	})
+}
+
+// auditSigning 将签名操作写入审计日志 (未配置数据库时不记录)。
+// 签名成功但未能记录时返回错误，调用方不得使用该签名。
+func (s *PayoutService) auditSigning(ctx context.Context, chainID uint64, op signingOp, digest []byte, provider, keyID string, signErr error) error {
+	if s.ledger == nil {
+		return nil
+	}
+	record := &ledger.SigningRecord{
+		UserID:        op.userID,
+		BatchID:       op.batchID,
+		JobID:         op.jobID,
+		ChainID:       chainID,
+		Purpose:       op.purpose,
+		Digest:        "0x" + hex.EncodeToString(digest),
+		Provider:      provider,
+		KeyID:         keyID,
+		Requestor:     op.requestor(),
+		Result:        ledger.SigningResultSigned,
+		CorrelationID: correlation.FromContext(ctx),
+		SignedAt:      time.Now(),
+	}
+	if signErr != nil {
+		record.Result = ledger.SigningResultFailed
+		record.Error = signErr.Error()
+	}
+	// 签名失败多因上下文超时，审计写入不受其影响
+	if err := s.ledger.RecordSigning(context.WithoutCancel(ctx), record); err != nil {
+		log.Error().
+			Err(err).
+			Str("job_id", op.jobID).
+			Uint64("chain_id", chainID).
+			Str("purpose", op.purpose).
+			Str("digest", record.Digest).
+			Msg("Failed to record signing audit")
+		if signErr != nil {
+			return nil // 签名本身已失败，返回签名错误
+		}
+		return fmt.Errorf("failed to record signing audit: %w", err)
+	}
+	return nil
+}
+
+// SigningRecords 运维查询签名审计日志
+func (s *PayoutService) SigningRecords(ctx context.Context, q ledger.SigningQuery) ([]ledger.SigningRecord, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	return s.ledger.SigningRecords(ctx, q)
+}
+
+// VerifySigningLog 校验签名审计日志的哈希链
+func (s *PayoutService) VerifySigningLog(ctx context.Context) (*ledger.SigningChainCheck, error) {
+	if s.ledger == nil {
+		return nil, &FailedPreconditionError{Err: ErrLedgerDisabled}
+	}
+	return s.ledger.VerifySigningChain(ctx)
+}
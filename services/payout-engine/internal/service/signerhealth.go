@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/rs/zerolog/log"
+)
+
+// 签名器健康告警事件 (投递到 SIGNING_ALERT_WEBHOOK_URL)
+const (
+	SigningEventUnhealthy = "signing.unhealthy" // 签名服务不可达或密钥不可用
+	SigningEventRecovered = "signing.recovered" // 恢复可用
+)
+
+// signerHealthTimeout 单个签名器健康检查的超时
+const signerHealthTimeout = 30 * time.Second
+
+// SignerHealth 签名器健康检查结果
+type SignerHealth struct {
+	Signer    string    `json:"signer"`             // default、chain:<链 ID>、gas_tank、tron 或 tron_gas_tank
+	ChainID   uint64    `json:"chain_id,omitempty"` // 按链配置的签名器
+	Provider  string    `json:"provider"`
+	Address   string    `json:"address"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// SignerHealthAlert 签名器不可用或恢复告警
+type SignerHealthAlert struct {
+	Event string `json:"event"`
+	SignerHealth
+	Timestamp int64 `json:"timestamp"`
+}
+
+type namedSigner struct {
+	name    string
+	chainID uint64
+	signer  kms.Signer
+	tron    bool // 地址以 TRON 格式报告
+}
+
+// healthSigners 需要检查的签名器: 默认、按链配置、gas 补充资金钱包和 TRON
+func (s *PayoutService) healthSigners() []namedSigner {
+	signers := []namedSigner{{name: "default", signer: s.signer}}
+	chainIDs := make([]uint64, 0, len(s.chainSigners))
+	for chainID := range s.chainSigners {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+	for _, chainID := range chainIDs {
+		signers = append(signers, namedSigner{name: fmt.Sprintf("chain:%d", chainID), chainID: chainID, signer: s.chainSigners[chainID]})
+	}
+	if s.gasTankSigner != nil {
+		signers = append(signers, namedSigner{name: "gas_tank", signer: s.gasTankSigner})
+	}
+	if s.tronSigner != nil {
+		signers = append(signers, namedSigner{name: "tron", signer: s.tronSigner, tron: true})
+	}
+	if s.tronGasTankSigner != nil {
+		signers = append(signers, namedSigner{name: "tron_gas_tank", signer: s.tronGasTankSigner, tron: true})
+	}
+	return signers
+}
+
+// CheckSigners 检查各签名器能否访问密钥 (如重新获取公钥)，状态变化时告警
+func (s *PayoutService) CheckSigners(ctx context.Context) []SignerHealth {
+	signers := s.healthSigners()
+	results := make([]SignerHealth, len(signers))
+	var wg sync.WaitGroup
+	for i, ns := range signers {
+		wg.Add(1)
+		go func(i int, ns namedSigner) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, signerHealthTimeout)
+			defer cancel()
+			err := ns.signer.Health(checkCtx)
+			address := ns.signer.Address().Hex()
+			if ns.tron {
+				address = tronSignerAddress(ns.signer)
+			}
+			results[i] = SignerHealth{
+				Signer:    ns.name,
+				ChainID:   ns.chainID,
+				Provider:  ns.signer.Provider(),
+				Address:   address,
+				Healthy:   err == nil,
+				CheckedAt: time.Now(),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+
+	s.signerHealthMu.Lock()
+	previous := make(map[string]SignerHealth, len(s.signerHealth))
+	for _, h := range s.signerHealth {
+		previous[h.Signer] = h
+	}
+	s.signerHealth = results
+	s.signerHealthMu.Unlock()
+
+	for _, h := range results {
+		prev, checked := previous[h.Signer]
+		switch {
+		case !h.Healthy:
+			log.Error().Str("signer", h.Signer).Str("provider", h.Provider).Str("address", h.Address).Str("error", h.Error).Msg("Signer health check failed")
+			if !checked || prev.Healthy {
+				s.sendSignerHealthAlert(ctx, SigningEventUnhealthy, h)
+			}
+		case checked && !prev.Healthy:
+			log.Info().Str("signer", h.Signer).Str("provider", h.Provider).Str("address", h.Address).Msg("Signer recovered")
+			s.sendSignerHealthAlert(ctx, SigningEventRecovered, h)
+		}
+	}
+	return results
+}
+
+// SignerHealth 最近一次签名器健康检查结果 (尚未检查时为空)
+func (s *PayoutService) SignerHealth() []SignerHealth {
+	s.signerHealthMu.RLock()
+	defer s.signerHealthMu.RUnlock()
+	return append([]SignerHealth(nil), s.signerHealth...)
+}
+
+// SignersHealthy 最近一次检查中所有签名器均可用 (尚未检查时为 false)
+func (s *PayoutService) SignersHealthy() bool {
+	s.signerHealthMu.RLock()
+	defer s.signerHealthMu.RUnlock()
+	if len(s.signerHealth) == 0 {
+		return false
+	}
+	for _, h := range s.signerHealth {
+		if !h.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// RunSignerHealth 启动时立即检查签名器 (预热连接并校验密钥)，之后定期检查；
+// 结果变化时调用 setReady (用于 gRPC 健康检查)
+func (s *PayoutService) RunSignerHealth(ctx context.Context, interval time.Duration, setReady func(ready bool)) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	log.Info().Dur("interval", interval).Msg("Signer health checks started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	first := true
+	ready := false
+	for {
+		s.CheckSigners(ctx)
+		if healthy := s.SignersHealthy(); first || healthy != ready {
+			ready, first = healthy, false
+			if setReady != nil {
+				setReady(ready)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Signer health checks stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendSignerHealthAlert 投递告警 (未配置告警地址时不投递)
+func (s *PayoutService) sendSignerHealthAlert(ctx context.Context, event string, h SignerHealth) {
+	if s.cfg.SigningAlertURL == "" {
+		return
+	}
+	body, err := json.Marshal(SignerHealthAlert{Event: event, SignerHealth: h, Timestamp: time.Now().Unix()})
+	if err != nil {
+		return
+	}
+	eventID := fmt.Sprintf("%s:%s:%d", event, h.Signer, h.CheckedAt.UnixMilli())
+	if err := s.webhookSender.Post(ctx, s.cfg.SigningAlertURL, s.cfg.SigningAlertSecret, eventID, body); err != nil {
+		log.Warn().Err(err).Str("event", event).Str("signer", h.Signer).Msg("Failed to deliver signer health alert")
+	}
+}
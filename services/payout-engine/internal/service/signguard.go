@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/rs/zerolog/log"
+)
+
+// 签名异常告警事件
+const (
+	SigningEventAnomaly = "signing.anomaly" // 签名速率突增或非工作时间签名 (仍签名)
+	SigningEventBlocked = "signing.blocked" // 超过签名上限或按规则拒绝签名
+)
+
+// SigningAlert 签名异常告警 (POST 到 SIGNING_ALERT_WEBHOOK_URL，签名方式同批次回调)
+type SigningAlert struct {
+	Event string `json:"event"`
+	kms.Anomaly
+	Message   string `json:"message"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// RunSigningAlerts 投递签名限速器上报的异常 (日志由 kms 记录)
+func (s *PayoutService) RunSigningAlerts(ctx context.Context, anomalies <-chan kms.Anomaly) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case anomaly := <-anomalies:
+			s.sendSigningAlert(ctx, anomaly)
+		}
+	}
+}
+
+// sendSigningAlert 投递告警 (未配置告警地址时不投递)
+func (s *PayoutService) sendSigningAlert(ctx context.Context, anomaly kms.Anomaly) {
+	if s.cfg.SigningAlertURL == "" {
+		return
+	}
+	event := SigningEventAnomaly
+	if anomaly.Blocked {
+		event = SigningEventBlocked
+	}
+	body, err := json.Marshal(SigningAlert{
+		Event:     event,
+		Anomaly:   anomaly,
+		Message:   anomaly.String(),
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+	eventID := fmt.Sprintf("%s:%s:%s:%d", event, anomaly.Kind, anomaly.Address.Hex(), anomaly.At.UnixMilli())
+	if err := s.webhookSender.Post(ctx, s.cfg.SigningAlertURL, s.cfg.SigningAlertSecret, eventID, body); err != nil {
+		log.Warn().Err(err).Str("event", event).Str("kind", anomaly.Kind).Msg("Failed to deliver signing alert")
+	}
+}
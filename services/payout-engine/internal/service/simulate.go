@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/rs/zerolog/log"
+)
+
+// SimulatedItem 模拟执行一笔支付的结果
+type SimulatedItem struct {
+	ItemID   string
+	GasLimit uint64 // eth_estimateGas 结果 (TRON 为 0)
+	GasCost  string // 预计网络费 (原生代币最小单位)
+	Error    string // 预计失败原因 (余额不足、revert)，成功时为空
+}
+
+// estimator 模拟所需的最小 RPC 接口 (*rpcpool.Pool 满足)
+type estimator interface {
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// simulateBatch 试运行批次: 检查余额，对每笔支付执行 eth_call / eth_estimateGas，
+// 返回预计失败的支付项和总网络费。不签名、不广播、不入队，也不占用幂等键。
+func (s *PayoutService) simulateBatch(ctx context.Context, req *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	fees, amounts, err := s.batchAmounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 余额: 按提交顺序累计，找出余额不足的支付项
+	items, err := s.preflightItems(ctx, req, amounts)
+	if err != nil {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("preflight check failed: %w", err)}
+	}
+	balances, err := s.preflightBalances(ctx, req)
+	if err != nil {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("preflight check failed: failed to read balances: %w", err)}
+	}
+	_, short, _ := planBatch(items, balances, true, s.chainConfig(req.ChainID).NativeToken)
+	shortfalls := make(map[string]string, len(short))
+	for _, r := range short {
+		shortfalls[r.ItemID] = r.Reason
+	}
+
+	var results []SimulatedItem
+	if client, ok := s.evmClient(req.ChainID); ok {
+		feeData, err := s.suggestFees(ctx, req.ChainID, req.Priority)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fees: %w", err)
+		}
+		from := common.HexToAddress(req.FromAddress)
+		results = s.simulateEVM(ctx, client, from, req, amounts, shortfalls, feeData, s.chainConfig(req.ChainID).L1Fee)
+	} else {
+		results = simulateTron(req, items, shortfalls)
+	}
+
+	resp := &BatchPayoutResponse{
+		BatchID:    req.BatchID,
+		Fees:       fees,
+		Testnet:    s.isTestnetChain(req.ChainID),
+		Simulated:  true,
+		Simulation: results,
+	}
+	total := new(big.Int)
+	for _, r := range results {
+		if r.Error != "" {
+			resp.Rejected = append(resp.Rejected, RejectedItem{ItemID: r.ItemID, Reason: r.Error})
+			continue
+		}
+		cost, _ := new(big.Int).SetString(r.GasCost, 10)
+		if cost != nil {
+			total.Add(total, cost)
+		}
+	}
+	resp.EstimatedGasCost = total.String()
+	resp.Message = fmt.Sprintf("Simulation: %d of %d items would succeed", len(results)-len(resp.Rejected), len(results))
+
+	log.Info().
+		Str("batch_id", req.BatchID).
+		Int("items", len(results)).
+		Int("failures", len(resp.Rejected)).
+		Str("gas_cost", resp.EstimatedGasCost).
+		Msg("Batch simulated")
+	return resp, nil
+}
+
+// simulateEVM 逐笔估算 Gas。代币转账先 eth_call 确认 transfer 返回 true。
+// 预计网络费按 (base fee + tip) 计算，不超过 max fee；L2 加上 L1 数据费 (l1Fee 见 config.ChainConfig.L1Fee)。
+func (s *PayoutService) simulateEVM(ctx context.Context, client estimator, from common.Address, req *BatchPayoutRequest, amounts []*big.Int, shortfalls map[string]string, fees *gas.Fees, l1Fee string) []SimulatedItem {
+	price := new(big.Int).Add(fees.BaseFee, fees.TipCap)
+	if price.Cmp(fees.FeeCap) > 0 {
+		price = fees.FeeCap
+	}
+
+	results := make([]SimulatedItem, len(req.Items))
+	for i, item := range req.Items {
+		results[i].ItemID = item.ID
+		if reason, ok := shortfalls[item.ID]; ok {
+			results[i].Error = reason
+			continue
+		}
+
+		to := common.HexToAddress(item.RecipientAddress)
+		msg := ethereum.CallMsg{From: from, To: &to, Value: amounts[i]}
+		if !isNativeToken(item.TokenAddress) {
+			data, err := s.erc20ABI.Pack("transfer", to, amounts[i])
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			token := common.HexToAddress(item.TokenAddress)
+			msg = ethereum.CallMsg{From: from, To: &token, Data: data}
+
+			out, err := client.CallContract(ctx, msg, nil)
+			if err != nil {
+				results[i].Error = fmt.Sprintf("transfer reverted: %v", err)
+				continue
+			}
+			if values, err := s.erc20ABI.Unpack("transfer", out); err == nil && len(values) == 1 {
+				if ok, _ := values[0].(bool); !ok {
+					results[i].Error = "transfer returned false"
+					continue
+				}
+			}
+		}
+
+		gasLimit, err := client.EstimateGas(ctx, msg)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("gas estimation failed: %v", err)
+			continue
+		}
+		// Arbitrum 的 L1 部分已包含在 eth_estimateGas 结果中，OP Stack 另行收取
+		l1, err := gas.EstimateL1Cost(ctx, client, l1Fee, types.NewTx(&types.DynamicFeeTx{
+			ChainID:   new(big.Int).SetUint64(req.ChainID),
+			GasTipCap: fees.TipCap,
+			GasFeeCap: fees.FeeCap,
+			Gas:       gasLimit,
+			To:        msg.To,
+			Value:     msg.Value,
+			Data:      msg.Data,
+		}))
+		if err != nil {
+			results[i].Error = fmt.Sprintf("l1 fee estimation failed: %v", err)
+			continue
+		}
+		results[i].GasLimit = gasLimit
+		cost := new(big.Int).Mul(price, new(big.Int).SetUint64(gasLimit))
+		results[i].GasCost = cost.Add(cost, l1.Fee).String()
+	}
+	return results
+}
+
+// simulateTron TRON 没有 eth_estimateGas，只检查余额，网络费按预留值计算
+func simulateTron(req *BatchPayoutRequest, items []preflightItem, shortfalls map[string]string) []SimulatedItem {
+	results := make([]SimulatedItem, len(req.Items))
+	for i, item := range req.Items {
+		results[i].ItemID = item.ID
+		if reason, ok := shortfalls[item.ID]; ok {
+			results[i].Error = reason
+			continue
+		}
+		results[i].GasCost = items[i].gas.String()
+	}
+	return results
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrJobNotFound 任务不存在或状态已过期
+var ErrJobNotFound = errors.New("job not found")
+
+// BatchStatusResult 批次状态汇总
+type BatchStatusResult struct {
+	BatchID        string
+	Status         BatchStatus
+	TotalCount     int
+	CompletedCount int
+	FailedCount    int
+	PendingCount   int // 排队中、处理中或等待重试
+	CancelledCount int
+	Items          []*queue.JobStatus
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	ManifestHash   string    // 提交时的任务清单哈希
+	ExecuteAt      time.Time // 定时批次的执行时间
+	Error          string    // 定时批次到期后未能入队的原因
+	GasFee         string    // 已上链交易的网络费合计 (原生代币最小单位，含解包交易)
+
+	// 已上传到对象存储的批次报告 (见 RunReportWriter)
+	Report *queue.ReportLocation
+
+	// 待审批批次 (见 ApproveBatch)
+	ApprovedBy        []string
+	ApprovalsRequired int
+	ApprovalMessage   string // 审批人签名的文本
+	ValueUSD          string
+}
+
+// GetBatchStatus 查询批次内各支付项状态
+func (s *PayoutService) GetBatchStatus(ctx context.Context, userID, batchID string) (*BatchStatusResult, error) {
+	if userID == "" || batchID == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("user_id and batch_id are required")}
+	}
+	jobs, err := s.queue.BatchJobs(ctx, userID, batchID)
+	if errors.Is(err, queue.ErrBatchNotFound) {
+		// 尚未入队的定时批次
+		if scheduled, serr := s.scheduledBatchStatus(ctx, userID, batchID); serr != nil || scheduled != nil {
+			return scheduled, serr
+		}
+		// 等待审批的批次
+		if held, herr := s.pendingApprovalStatus(ctx, userID, batchID); herr != nil || held != nil {
+			return held, herr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := summarizeBatch(batchID, jobs)
+	manifest, err := s.queue.GetManifest(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		result.ManifestHash = manifest.Hash
+	}
+	if result.Report, err = s.queue.GetReportLocation(ctx, userID, batchID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelBatch 取消批次中尚未发送的支付项。
+// 返回 (已取消数量, 已处理无法取消数量)。
+func (s *PayoutService) CancelBatch(ctx context.Context, userID, batchID, reason string) (int, int, error) {
+	if userID == "" || batchID == "" {
+		return 0, 0, &InvalidArgumentError{Err: fmt.Errorf("user_id and batch_id are required")}
+	}
+	// 待审批批次在放行前整批取消
+	if cancelled, ok, err := s.cancelPendingApproval(ctx, userID, batchID); err != nil || ok {
+		if ok {
+			log.Info().
+				Str("batch_id", batchID).
+				Str("user_id", userID).
+				Str("reason", reason).
+				Int("cancelled", cancelled).
+				Msg("Pending approval batch cancelled")
+		}
+		return cancelled, 0, err
+	}
+	// 定时批次在入队前整批取消
+	if cancelled, ok, err := s.cancelScheduledBatch(ctx, userID, batchID); err != nil || ok {
+		if ok {
+			log.Info().
+				Str("batch_id", batchID).
+				Str("user_id", userID).
+				Str("reason", reason).
+				Int("cancelled", cancelled).
+				Msg("Scheduled batch cancelled")
+		}
+		return cancelled, 0, err
+	}
+	cancelled, processed, err := s.queue.CancelBatch(ctx, userID, batchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	log.Info().
+		Str("batch_id", batchID).
+		Str("user_id", userID).
+		Str("reason", reason).
+		Int("cancelled", cancelled).
+		Int("already_processed", processed).
+		Msg("Batch cancelled")
+	return cancelled, processed, nil
+}
+
+// CancelJobs 取消批次中指定的、尚未发送的支付项，返回已取消和已在处理中 (无法取消) 的支付项。
+// 定时批次入队前只能整批取消或修改。
+func (s *PayoutService) CancelJobs(ctx context.Context, userID, batchID string, jobIDs []string, reason string) (*queue.CancelJobsResult, error) {
+	if userID == "" || batchID == "" {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("user_id and batch_id are required")}
+	}
+	if len(jobIDs) == 0 {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("job_ids is required")}
+	}
+	if scheduled, err := s.queue.GetScheduledBatch(ctx, userID, batchID); err != nil {
+		return nil, err
+	} else if scheduled != nil && scheduled.State == queue.ScheduleStatePending {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is scheduled, cancel or update it as a whole", batchID)}
+	}
+	if held, err := s.queue.GetPendingApproval(ctx, userID, batchID); err != nil {
+		return nil, err
+	} else if held != nil && held.State == queue.ApprovalStatePending {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("batch %s is pending approval, cancel it as a whole", batchID)}
+	}
+
+	result, err := s.queue.CancelJobs(ctx, userID, batchID, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.NotFound) > 0 {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("jobs not in batch %s: %s", batchID, strings.Join(result.NotFound, ", "))}
+	}
+	log.Info().
+		Str("batch_id", batchID).
+		Str("user_id", userID).
+		Str("reason", reason).
+		Strs("cancelled", result.Cancelled).
+		Strs("in_flight", result.InFlight).
+		Msg("Batch items cancelled")
+	return result, nil
+}
+
+// JobFilter 任务查询条件 (零值字段不过滤)
+type JobFilter struct {
+	UserID  string
+	BatchID string
+	ChainID uint64
+	State   queue.JobState
+	From    time.Time // 任务创建时间下限 (含)
+	To      time.Time // 任务创建时间上限 (含)
+}
+
+func (f JobFilter) match(job *queue.JobStatus) bool {
+	if f.ChainID != 0 && job.ChainID != f.ChainID {
+		return false
+	}
+	if f.State != "" && job.State != f.State {
+		return false
+	}
+	if !f.From.IsZero() && job.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && job.CreatedAt.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// ListJobs 按条件列出支付任务 (最新批次在前)
+func (s *PayoutService) ListJobs(ctx context.Context, filter JobFilter, offset, limit int) ([]*queue.JobStatus, int, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var refs []queue.BatchRef
+	switch {
+	case filter.BatchID != "" && filter.UserID != "":
+		refs = []queue.BatchRef{{UserID: filter.UserID, BatchID: filter.BatchID}}
+	case filter.BatchID != "":
+		owners, err := s.queue.BatchOwners(ctx, filter.BatchID)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, owner := range owners {
+			refs = append(refs, queue.BatchRef{UserID: owner, BatchID: filter.BatchID})
+		}
+	default:
+		var err error
+		// 批次创建时间不晚于其任务，按 To 过滤批次不会遗漏
+		if refs, err = s.queue.ListBatches(ctx, filter.UserID, time.Time{}, filter.To); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var matched []*queue.JobStatus
+	for _, ref := range refs {
+		jobs, err := s.queue.BatchJobs(ctx, ref.UserID, ref.BatchID)
+		if errors.Is(err, queue.ErrBatchNotFound) {
+			continue // 状态已过期
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, job := range jobs {
+			if filter.match(job) {
+				matched = append(matched, job)
+			}
+		}
+	}
+
+	total := len(matched)
+	if offset >= total {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
+
+// resolveBatch 由批次 ID 定位所属用户。userID 为空且多个用户使用同一批次 ID 时返回参数错误。
+func (s *PayoutService) resolveBatch(ctx context.Context, userID, batchID string) (string, error) {
+	if userID != "" {
+		return userID, nil
+	}
+	owners, err := s.queue.BatchOwners(ctx, batchID)
+	if err != nil {
+		return "", err
+	}
+	switch len(owners) {
+	case 0:
+		return "", queue.ErrBatchNotFound
+	case 1:
+		return owners[0], nil
+	}
+	return "", &InvalidArgumentError{Err: fmt.Errorf("batch id %q is used by %d users, user_id is required", batchID, len(owners))}
+}
+
+// FindBatch 运维查询批次状态 (userID 可为空)
+func (s *PayoutService) FindBatch(ctx context.Context, userID, batchID string) (*BatchStatusResult, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBatchStatus(ctx, owner, batchID)
+}
+
+// CancelBatchByID 运维取消批次 (userID 可为空)
+func (s *PayoutService) CancelBatchByID(ctx context.Context, userID, batchID, reason string) (int, int, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.CancelBatch(ctx, owner, batchID, reason)
+}
+
+// CancelJobsByID 运维取消批次中的指定支付项 (userID 可为空)
+func (s *PayoutService) CancelJobsByID(ctx context.Context, userID, batchID string, jobIDs []string, reason string) (*queue.CancelJobsResult, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CancelJobs(ctx, owner, batchID, jobIDs, reason)
+}
+
+// FindManifest 运维查询批次任务清单 (userID 可为空)
+func (s *PayoutService) FindManifest(ctx context.Context, userID, batchID string) (*queue.Manifest, error) {
+	owner, err := s.resolveBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.queue.GetManifest(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, queue.ErrBatchNotFound
+	}
+	return manifest, nil
+}
+
+// GetJob 运维查询单个任务 (userID 可为空)
+func (s *PayoutService) GetJob(ctx context.Context, userID, jobID string) (*queue.JobStatus, error) {
+	refs, err := s.queue.JobRefs(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*queue.JobStatus
+	for _, ref := range refs {
+		if userID != "" && ref.UserID != userID {
+			continue
+		}
+		job, err := s.queue.GetJobStatus(ctx, ref, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			found = append(found, job)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, ErrJobNotFound
+	case 1:
+		return found[0], nil
+	}
+	return nil, &InvalidArgumentError{Err: fmt.Errorf("job id %q exists in %d batches, user_id is required", jobID, len(found))}
+}
+
+// summarizeBatch 由任务状态汇总批次状态
+func summarizeBatch(batchID string, jobs []*queue.JobStatus) *BatchStatusResult {
+	result := &BatchStatusResult{BatchID: batchID, TotalCount: len(jobs), Items: jobs}
+	started := 0
+	gasFee := new(big.Int)
+	for _, job := range jobs {
+		gasFee.Add(gasFee, job.TotalGasFee())
+		switch job.State {
+		case queue.JobStateConfirmed:
+			result.CompletedCount++
+		case queue.JobStateFailed:
+			result.FailedCount++
+		case queue.JobStateCancelled:
+			result.CancelledCount++
+		default:
+			result.PendingCount++
+			if job.State != queue.JobStatePending {
+				started++
+			}
+		}
+		if result.CreatedAt.IsZero() || job.CreatedAt.Before(result.CreatedAt) {
+			result.CreatedAt = job.CreatedAt
+		}
+		if job.UpdatedAt.After(result.UpdatedAt) {
+			result.UpdatedAt = job.UpdatedAt
+		}
+	}
+	result.GasFee = gasFee.String()
+
+	switch {
+	case result.PendingCount > 0 && started == 0 && result.CompletedCount+result.FailedCount == 0:
+		result.Status = BatchStatusQueued
+	case result.PendingCount > 0:
+		result.Status = BatchStatusProcessing
+	case result.FailedCount > 0 && result.CompletedCount > 0:
+		result.Status = BatchStatusPartialFailed
+	case result.FailedCount > 0:
+		result.Status = BatchStatusFailed
+	case result.CompletedCount > 0:
+		result.Status = BatchStatusCompleted
+	case result.CancelledCount > 0:
+		result.Status = BatchStatusCancelled
+	}
+	return result
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/rs/zerolog/log"
+)
+
+// minGasBumpPercent is the smallest bump nodes accept for a replacement tx.
+const minGasBumpPercent = 10
+
+// trackPendingTx 记录已广播交易以便卡单检测
+func (s *PayoutService) trackPendingTx(ctx context.Context, job *queue.Job, signedTx *types.Transaction) {
+	s.trackStandardTx(ctx, job, signedTx, &queue.PendingTx{MaxFee: job.MaxFee})
+}
+
+// trackUnwrapTx 记录任务前置的解包交易 (与转账交易分开监控和替换)
+func (s *PayoutService) trackUnwrapTx(ctx context.Context, job *queue.Job, signedTx *types.Transaction) {
+	s.trackStandardTx(ctx, job, signedTx, &queue.PendingTx{Unwrap: true})
+}
+
+// trackPermitTx 记录代付前置的授权交易
+func (s *PayoutService) trackPermitTx(ctx context.Context, job *queue.Job, signedTx *types.Transaction) {
+	s.trackStandardTx(ctx, job, signedTx, &queue.PendingTx{Permit: true})
+}
+
+// trackSwapTx 记录兑换前置的 approve 或兑换交易 (step 见 queue.SwapStepApprove / SwapStepSwap)
+func (s *PayoutService) trackSwapTx(ctx context.Context, job *queue.Job, signedTx *types.Transaction, step string) {
+	s.trackStandardTx(ctx, job, signedTx, &queue.PendingTx{Swap: step})
+}
+
+// trackBridgeTx 记录跨链前置交易 (job 的链和付款地址为交易所在链)
+func (s *PayoutService) trackBridgeTx(ctx context.Context, job *queue.Job, signedTx *types.Transaction, step string) {
+	s.trackStandardTx(ctx, job, signedTx, &queue.PendingTx{Bridge: step})
+}
+
+// trackStandardTx 记录标准以太坊交易
+func (s *PayoutService) trackStandardTx(ctx context.Context, job *queue.Job, tx *types.Transaction, pending *queue.PendingTx) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to encode tx for stuck monitoring")
+		return
+	}
+	s.trackTx(ctx, job, &signedTx{hash: tx.Hash(), raw: raw, fields: tx, tx: tx}, pending)
+}
+
+// trackTx 在 pending 上补全任务和交易字段后记录 (pending.TxFormat 为交易的编码格式)
+func (s *PayoutService) trackTx(ctx context.Context, job *queue.Job, stx *signedTx, pending *queue.PendingTx) {
+	pending.JobID = job.ID
+	pending.BatchID = job.BatchID
+	pending.UserID = job.UserID
+	pending.ChainID = job.ChainID
+	pending.FromAddress = job.FromAddress
+	pending.Nonce = stx.fields.Nonce()
+	pending.TxHash = stx.hash.Hex()
+	pending.RawTx = hex.EncodeToString(stx.raw)
+	pending.SentAt = time.Now()
+	if err := s.queue.TrackPendingTx(ctx, pending); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to track pending tx")
+	}
+}
+
+// RunStuckTxMonitor 定期检测卡住的交易并以相同 nonce 提高 Gas 重新广播，并核对已上链交易是否被重组移出
+func (s *PayoutService) RunStuckTxMonitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Starting stuck transaction monitor")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pending, err := s.queue.ListPendingTxs(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to list pending transactions")
+				continue
+			}
+			blocked := s.repairNonceGaps(ctx, pending)
+			for _, p := range pending {
+				if blocked[p.Key()] {
+					continue // 被 nonce 空缺阻塞，替换无效
+				}
+				if err := s.checkPendingTx(ctx, p); err != nil {
+					log.Error().Err(err).Str("job_id", p.JobID).Str("tx_hash", p.TxHash).Msg("Stuck transaction check failed")
+				}
+			}
+			s.checkReorgs(ctx)
+		}
+	}
+}
+
+// checkPendingTx 检查单笔待确认交易，必要时替换
+func (s *PayoutService) checkPendingTx(ctx context.Context, p *queue.PendingTx) error {
+	client, ok := s.evmClient(p.ChainID)
+	if !ok {
+		return nil
+	}
+
+	// 任一版本上链并达到链的确认数即视为完成
+	var lookupErr error
+	for _, hash := range append([]string{p.TxHash}, p.PrevHashes...) {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			lookupErr = err
+		}
+		if err == nil && receipt != nil {
+			final, err := s.isFinal(ctx, client, p.ChainID, receipt)
+			if err != nil {
+				return fmt.Errorf("failed to get block number: %w", err)
+			}
+			if !final {
+				return nil // 已上链，等待确认数 (不再替换)
+			}
+			log.Info().
+				Str("job_id", p.JobID).
+				Str("tx_hash", hash).
+				Uint64("status", receipt.Status).
+				Int("replacements", p.Replacements).
+				Bool("unwrap", p.Unwrap).
+				Bool("permit", p.Permit).
+				Str("swap", p.Swap).
+				Str("bridge", p.Bridge).
+				Msg("Pending transaction mined")
+			s.recordGasFee(ctx, p, receipt)
+			if p.Prerequisite() {
+				// 前置交易不决定任务结果，失败时转账会因余额或授权不足失败
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					log.Error().Str("job_id", p.JobID).Str("tx_hash", hash).Bool("permit", p.Permit).Msg("Prerequisite transaction reverted")
+				}
+				// 跨链销毁上链后记录跨链消息，任务据此查询证明
+				if p.Bridge == queue.BridgeStepBurn {
+					s.recordRouteBurn(ctx, p, receipt, hash)
+				}
+				return s.queue.RemovePendingTx(ctx, p.Key())
+			}
+			s.recordReceiptOutcome(ctx, p, receipt)
+			s.trackMinedTx(ctx, p, receipt, hash)
+			if p.UserID != "" {
+				s.queue.EmitJobConfirmed(ctx, queue.BatchRef{UserID: p.UserID, BatchID: p.BatchID}, p.JobID)
+			}
+			return s.queue.RemovePendingTx(ctx, p.Key())
+		}
+	}
+
+	// nonce 已被其他交易消耗 (被外部替换或丢弃)
+	confirmedNonce, err := client.NonceAt(ctx, common.HexToAddress(p.FromAddress), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get confirmed nonce: %w", err)
+	}
+	if confirmedNonce > p.Nonce {
+		log.Warn().
+			Str("job_id", p.JobID).
+			Uint64("nonce", p.Nonce).
+			Msg("Nonce consumed by another transaction, stop tracking")
+		// 销毁交易被丢弃: 清除销毁记录，任务重试时重新销毁 (回执查询失败时不能确认未上链，不清除)
+		if p.Bridge == queue.BridgeStepBurn && lookupErr == nil {
+			s.recordRouteBurn(ctx, p, nil, "")
+		}
+		return s.queue.RemovePendingTx(ctx, p.Key())
+	}
+
+	// 私有交易池截止前不替换；超时后改为公共交易池广播
+	if p.PrivateUntil != nil {
+		if time.Now().Before(*p.PrivateUntil) {
+			return nil
+		}
+		return s.publishPrivateTx(ctx, client, p)
+	}
+
+	chainCfg := s.chainConfig(p.ChainID)
+	if time.Since(p.SentAt) < chainCfg.StuckTxTimeout {
+		return nil
+	}
+
+	if p.Replacements >= chainCfg.MaxReplacements {
+		log.Error().
+			Str("job_id", p.JobID).
+			Str("tx_hash", p.TxHash).
+			Int("replacements", p.Replacements).
+			Msg("Transaction still stuck after max replacements, manual intervention required")
+		return nil
+	}
+
+	return s.replaceStuckTx(ctx, client, chainCfg, p)
+}
+
+// isFinal 交易所在区块之上 (含该区块) 是否已达到链配置的确认数
+func (s *PayoutService) isFinal(ctx context.Context, client *rpcpool.Pool, chainID uint64, receipt *types.Receipt) (bool, error) {
+	confirmations := s.chainConfig(chainID).Confirmations
+	if confirmations <= 1 {
+		return true, nil
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+	return confirmationCount(head, receipt.BlockNumber.Uint64()) >= confirmations, nil
+}
+
+// confirmationCount 区块在 head 时的确认数 (所在区块计为 1)
+func confirmationCount(head, block uint64) uint64 {
+	if head < block {
+		return 0 // 节点落后于返回回执的节点
+	}
+	return head - block + 1
+}
+
+// recordGasFee 按回执记录实际网络费 (gasUsed * effectiveGasPrice)，供结算汇总使用
+func (s *PayoutService) recordGasFee(ctx context.Context, p *queue.PendingTx, receipt *types.Receipt) {
+	if receipt.EffectiveGasPrice == nil {
+		return
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	chain := metrics.Label(p.ChainID)
+	metrics.GasUsed.Add(float64(receipt.GasUsed), chain)
+	feeWei, _ := new(big.Float).SetInt(fee).Float64()
+	metrics.GasSpent.Add(feeWei, chain)
+	if p.UserID == "" {
+		return
+	}
+	ref := queue.BatchRef{UserID: p.UserID, BatchID: p.BatchID}
+	var err error
+	switch {
+	case p.Unwrap:
+		err = s.queue.RecordUnwrap(ctx, ref, p.JobID, "", fee.String())
+	case p.Permit:
+		err = s.queue.RecordPermit(ctx, ref, p.JobID, "", fee.String())
+	case p.Swap != "":
+		err = s.queue.RecordSwap(ctx, ref, p.JobID, "", fee.String())
+	case p.Bridge != "":
+		err = s.queue.RecordBridgeFee(ctx, ref, p.JobID, p.Bridge, fee.String())
+	default:
+		err = s.queue.RecordGasFee(ctx, ref, p.JobID, queue.GasCost{
+			Fee:      fee.String(),
+			GasUsed:  receipt.GasUsed,
+			GasPrice: receipt.EffectiveGasPrice.String(),
+		})
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", p.JobID).Msg("Failed to record gas fee")
+	}
+}
+
+// publishPrivateTx 将私有交易池未打包的交易原样广播到公共交易池，之后按普通交易做卡单检测
+func (s *PayoutService) publishPrivateTx(ctx context.Context, client *rpcpool.Pool, p *queue.PendingTx) error {
+	stx, err := parsePendingTx(p)
+	if err != nil {
+		return err
+	}
+	if err := s.broadcastSigned(ctx, client, p.ChainID, stx); err != nil {
+		return fmt.Errorf("failed to broadcast to public mempool: %w", err)
+	}
+
+	log.Warn().
+		Str("job_id", p.JobID).
+		Str("tx_hash", p.TxHash).
+		Uint64("nonce", p.Nonce).
+		Msg("Private transaction not mined before deadline, broadcast to public mempool")
+
+	p.PrivateUntil = nil
+	p.SentAt = time.Now()
+	return s.queue.TrackPendingTx(ctx, p)
+}
+
+// replaceStuckTx 以相同 nonce 和提高的 GasTipCap/GasFeeCap 按原交易的格式重新签名并广播
+func (s *PayoutService) replaceStuckTx(ctx context.Context, client *rpcpool.Pool, chainCfg config.ChainConfig, p *queue.PendingTx) error {
+	old, err := parsePendingTx(p)
+	if err != nil {
+		return err
+	}
+	oldTx := old.fields
+
+	tipCap, feeCap := bumpFees(oldTx.GasTipCap(), oldTx.GasFeeCap(), chainCfg.GasBumpPercent)
+	// 替换后最多支付的网络费不能超过任务的上限，超过时继续等待原交易打包
+	if maxFee, _ := parseMaxFee(p.MaxFee); maxFee != nil && new(big.Int).Mul(feeCap, new(big.Int).SetUint64(oldTx.Gas())).Cmp(maxFee) > 0 {
+		log.Warn().
+			Str("job_id", p.JobID).
+			Str("tx_hash", p.TxHash).
+			Str("gas_fee_cap", feeCap.String()).
+			Str("max_fee", maxFee.String()).
+			Msg("Replacement would exceed the job's max fee, waiting for the stuck transaction")
+		return nil
+	}
+	newTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   oldTx.ChainId(),
+		Nonce:     oldTx.Nonce(),
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       oldTx.Gas(),
+		To:        oldTx.To(),
+		Value:     oldTx.Value(),
+		Data:      oldTx.Data(),
+	})
+
+	replacement, err := s.signChainTx(ctx, p.TxFormat, newTx, p.ChainID, signingOp{userID: p.UserID, batchID: p.BatchID, jobID: p.JobID, purpose: signPurposeReplacement, from: common.HexToAddress(p.FromAddress)})
+	if err != nil {
+		return fmt.Errorf("failed to sign replacement: %w", err)
+	}
+	if err := s.broadcastSigned(ctx, client, p.ChainID, replacement); err != nil {
+		return fmt.Errorf("failed to broadcast replacement: %w", err)
+	}
+
+	log.Warn().
+		Str("job_id", p.JobID).
+		Str("old_tx_hash", p.TxHash).
+		Str("new_tx_hash", replacement.hash.Hex()).
+		Uint64("nonce", p.Nonce).
+		Str("gas_tip_cap", tipCap.String()).
+		Str("gas_fee_cap", feeCap.String()).
+		Int("replacement", p.Replacements+1).
+		Msg("Replaced stuck transaction")
+
+	p.PrevHashes = append(p.PrevHashes, p.TxHash)
+	p.TxHash = replacement.hash.Hex()
+	p.RawTx = hex.EncodeToString(replacement.raw)
+	p.SentAt = time.Now()
+	p.Replacements++
+	return s.queue.TrackPendingTx(ctx, p)
+}
+
+// bumpFees 按百分比提高 tip 和 fee cap (至少 10%，节点替换交易的最低要求)
+func bumpFees(tipCap, feeCap *big.Int, percent int) (*big.Int, *big.Int) {
+	if percent < minGasBumpPercent {
+		percent = minGasBumpPercent
+	}
+	factor := big.NewInt(int64(100 + percent))
+	hundred := big.NewInt(100)
+
+	newTip := new(big.Int).Mul(tipCap, factor)
+	newTip.Div(newTip, hundred)
+	if newTip.Cmp(tipCap) <= 0 {
+		newTip.Add(tipCap, big.NewInt(1))
+	}
+
+	newFee := new(big.Int).Mul(feeCap, factor)
+	newFee.Div(newFee, hundred)
+	if newFee.Cmp(feeCap) <= 0 {
+		newFee.Add(feeCap, big.NewInt(1))
+	}
+	if newFee.Cmp(newTip) < 0 {
+		newFee.Set(newTip)
+	}
+	return newTip, newFee
+}
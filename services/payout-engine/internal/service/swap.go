@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/swap"
+	"github.com/rs/zerolog/log"
+)
+
+// 兑换前置交易预留的 Gas (聚合器未返回估算时使用)
+const (
+	swapApproveGas = 60000
+	swapGas        = 350000
+)
+
+// batchSwap 兑换批次写入任务的兑换参数 (未指定滑点时使用 SWAP_MAX_SLIPPAGE_BPS)
+func (s *PayoutService) batchSwap(req *BatchPayoutRequest) *queue.Swap {
+	if req.Swap == nil {
+		return nil
+	}
+	out := *req.Swap
+	if out.MaxSlippageBps == 0 {
+		out.MaxSlippageBps = s.cfg.Swap.MaxSlippageBps
+	}
+	return &out
+}
+
+// validateSwap 校验兑换批次: 仅 EVM 链且配置了聚合器，全部支付项为同一 ERC20 代币且不同于卖出代币，
+// 滑点不超过 SWAP_MAX_SLIPPAGE_BPS。可兑换的数量在预检时按卖出代币余额报价 (见 swapAvailable)。
+func (s *PayoutService) validateSwap(req *BatchPayoutRequest) error {
+	if s.swapper == nil {
+		return fmt.Errorf("swap payouts are not enabled (SWAP_PROVIDER is not set)")
+	}
+	if _, ok := s.evmClient(req.ChainID); !ok {
+		return fmt.Errorf("swap payouts are only supported on EVM chains")
+	}
+	if req.UseSmartAccount || req.Permit != nil || req.UseTreasury {
+		return fmt.Errorf("swap payouts cannot use a smart account, permit or treasury")
+	}
+	if req.Simulate {
+		return fmt.Errorf("swap payouts cannot be simulated")
+	}
+	if !common.IsHexAddress(req.Swap.SellToken) || isNativeToken(req.Swap.SellToken) {
+		return fmt.Errorf("swap sell_token must be an ERC20 contract address")
+	}
+	if slippage := req.Swap.MaxSlippageBps; slippage < 0 || slippage > s.cfg.Swap.MaxSlippageBps {
+		return fmt.Errorf("swap max_slippage_bps %d exceeds the limit of %d", slippage, s.cfg.Swap.MaxSlippageBps)
+	}
+
+	token := req.Items[0].TokenAddress
+	for i, item := range req.Items {
+		if isNativeToken(item.asset()) || item.TokenID != "" || !strings.EqualFold(item.TokenAddress, token) {
+			return fmt.Errorf("item[%d]: swap payouts must all transfer the same ERC20 token", i)
+		}
+	}
+	if strings.EqualFold(token, req.Swap.SellToken) {
+		return fmt.Errorf("swap sell_token is the payout token; submit the batch without swap")
+	}
+	return nil
+}
+
+// swapAvailable 兑换批次可支付的代币数量: 付款地址全部卖出代币余额的报价按批次滑点折算的最少输出
+func (s *PayoutService) swapAvailable(ctx context.Context, req *BatchPayoutRequest, buyToken string) (*big.Int, error) {
+	params := s.batchSwap(req)
+	sellBalance, err := s.tokenBalance(ctx, req.ChainID, req.FromAddress, params.SellToken)
+	if err != nil {
+		return nil, fmt.Errorf("sell token %s: %w", params.SellToken, err)
+	}
+	if sellBalance.Sign() == 0 {
+		return sellBalance, nil
+	}
+	out, err := s.swapper.Price(ctx, req.ChainID, params.SellToken, buyToken, sellBalance)
+	if err != nil {
+		return nil, fmt.Errorf("%s price failed: %w", s.swapper.Provider(), err)
+	}
+	return swap.ApplyBps(out, 10000-params.MaxSlippageBps), nil
+}
+
+// swapReservation 每笔兑换任务另外预留的网络费 (approve + 兑换，按代币转账的预留折算)
+func swapReservation(tokenGas *big.Int) *big.Int {
+	fee := new(big.Int).Mul(tokenGas, big.NewInt(swapApproveGas+swapGas))
+	return fee.Quo(fee, big.NewInt(erc20TransferGas))
+}
+
+// swapForPayout 兑换任务转账前经聚合器以卖出代币换出任务金额: 以 nonceVal 起依次广播
+// approve (对聚合器的额度不足时) 和兑换交易。返回已广播的交易数，调用方的转账应使用其后的 nonce。
+// 任务已有未回滚的兑换交易 (重试) 时不再兑换。
+func (s *PayoutService) swapForPayout(ctx context.Context, client *rpcpool.Pool, job *queue.Job, nonceVal uint64) (int, error) {
+	ref := queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+	if job.UserID != "" {
+		status, err := s.queue.GetJobStatus(ctx, ref, job.ID)
+		if err == nil && status != nil && status.SwapTxHash != "" {
+			receipt, err := client.TransactionReceipt(ctx, common.HexToHash(status.SwapTxHash))
+			if err != nil || receipt.Status == types.ReceiptStatusSuccessful {
+				log.Debug().Str("job_id", job.ID).Str("swap_tx", status.SwapTxHash).Msg("Payout token already swapped")
+				return 0, nil
+			}
+		}
+	}
+
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return 0, queue.Permanent(fmt.Errorf("invalid amount: %s", job.Amount))
+	}
+	from := common.HexToAddress(job.FromAddress)
+	sellToken := common.HexToAddress(job.Swap.SellToken)
+	quote, err := swap.QuoteExactOut(ctx, s.swapper, job.ChainID, job.Swap.SellToken, job.TokenAddress, amount, job.FromAddress, job.Swap.MaxSlippageBps)
+	if err != nil {
+		return 0, fmt.Errorf("%s quote failed: %w", s.swapper.Provider(), err)
+	}
+	if !common.IsHexAddress(quote.To) || !common.IsHexAddress(quote.AllowanceTarget) {
+		return 0, fmt.Errorf("%s quote returned an invalid target", s.swapper.Provider())
+	}
+
+	balance, err := s.erc20BalanceOf(ctx, client, sellToken, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sell token balance: %w", err)
+	}
+	if balance.Cmp(quote.SellAmount) < 0 {
+		return 0, fmt.Errorf("sell token balance %s is below swap amount %s", balance, quote.SellAmount)
+	}
+
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	spender := common.HexToAddress(quote.AllowanceTarget)
+	allowance, err := s.permitAllowance(ctx, client, sellToken, from, spender)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read swap allowance: %w", err)
+	}
+	if allowance.Cmp(quote.SellAmount) < 0 {
+		data, err := s.treasuryABI.Pack("approve", spender, quote.SellAmount)
+		if err != nil {
+			return 0, fmt.Errorf("failed to pack approve data: %w", err)
+		}
+		tx := newCallTx(job.ChainID, nonceVal, fees.TipCap, fees.FeeCap, calculateGasBuffer(swapApproveGas, job.Priority), sellToken, big.NewInt(0), data)
+		if _, err := s.sendSwapTx(ctx, client, job, tx, queue.SwapStepApprove); err != nil {
+			return 0, fmt.Errorf("failed to send swap approval: %w", err)
+		}
+		sent++
+	}
+
+	gasLimit := quote.Gas
+	if gasLimit == 0 {
+		gasLimit = swapGas
+	}
+	tx := newCallTx(job.ChainID, nonceVal+uint64(sent), fees.TipCap, fees.FeeCap, calculateGasBuffer(gasLimit, job.Priority), common.HexToAddress(quote.To), quote.Value, quote.Data)
+	signedTx, err := s.sendSwapTx(ctx, client, job, tx, queue.SwapStepSwap)
+	if err != nil {
+		return sent, fmt.Errorf("failed to send swap: %w", err)
+	}
+	sent++
+
+	log.Info().
+		Str("job_id", job.ID).
+		Str("tx_hash", signedTx.Hash().Hex()).
+		Str("provider", s.swapper.Provider()).
+		Str("sell_token", job.Swap.SellToken).
+		Str("sell_amount", quote.SellAmount.String()).
+		Str("min_buy_amount", quote.MinBuyAmount.String()).
+		Msg("Swapped into payout token")
+
+	if job.UserID != "" {
+		if err := s.queue.RecordSwap(ctx, ref, job.ID, signedTx.Hash().Hex(), ""); err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record swap tx")
+		}
+	}
+	return sent, nil
+}
+
+// sendSwapTx 签名、广播并记录兑换前置交易 (与转账交易分开监控和替换)
+func (s *PayoutService) sendSwapTx(ctx context.Context, client *rpcpool.Pool, job *queue.Job, tx *types.Transaction, step string) (*types.Transaction, error) {
+	signedTx, err := s.signTransaction(ctx, tx, job.ChainID, jobSigningOp(job, signPurposeSwap))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.broadcastTransaction(ctx, client, job.ChainID, signedTx); err != nil {
+		return nil, err
+	}
+	s.trackSwapTx(ctx, job, signedTx, step)
+	return signedTx, nil
+}
+
+// newCallTx 合约调用交易 (兑换和跨链前置交易使用)
+func newCallTx(chainID, nonceVal uint64, tipCap, feeCap *big.Int, gasLimit uint64, to common.Address, value *big.Int, data []byte) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonceVal,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+}
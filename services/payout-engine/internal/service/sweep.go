@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// sweepUserID 归集任务的用户 (任务状态和账本按该用户记录)
+const sweepUserID = "treasury"
+
+// RunSweepScheduler 定期检查付款钱包各资产余额，超过上限的部分作为任务入队转到冷钱包。
+// 归集任务与支付任务使用相同的签名、nonce、卡单替换和上链确认流程。
+func (s *PayoutService) RunSweepScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	log.Info().Dur("interval", interval).Msg("Sweep scheduler started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for chainID, chainCfg := range s.chainConfigs() {
+			if chainCfg.Sweep.Enabled() {
+				s.sweepChain(ctx, chainID, chainCfg, interval)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Sweep scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweepChain 检查链上每种配置了上限的资产，每种资产每个周期最多入队一笔归集
+func (s *PayoutService) sweepChain(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, interval time.Duration) {
+	cold := chainCfg.Sweep.ColdAddress
+	if !validChainAddress(chainCfg.Type, cold) {
+		log.Warn().Uint64("chain_id", chainID).Str("cold_address", cold).Msg("Invalid sweep cold address, skipping chain")
+		return
+	}
+	hot, err := s.payoutAddress(chainID)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Sweep skipped")
+		return
+	}
+
+	for asset, limits := range chainCfg.Sweep.Ceilings {
+		ceiling, target, err := limits.Limits()
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Invalid sweep ceiling")
+			continue
+		}
+		var balance *big.Int
+		if asset == config.SweepNative {
+			balance, err = s.nativeBalance(ctx, chainID, hot)
+		} else {
+			balance, err = s.tokenBalance(ctx, chainID, hot, asset)
+		}
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Sweep balance check failed")
+			continue
+		}
+		amount := sweepAmount(balance, ceiling, target)
+		if amount == nil {
+			continue
+		}
+
+		claimed, err := s.queue.ClaimSweep(ctx, chainID, strings.ToLower(asset), interval)
+		if err != nil || !claimed {
+			continue
+		}
+		job, err := s.sweepJob(ctx, chainID, chainCfg, hot, asset, amount)
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Failed to build sweep job")
+			continue
+		}
+		if err := s.queue.Push(ctx, job); err != nil {
+			log.Error().Err(err).Uint64("chain_id", chainID).Str("asset", asset).Msg("Failed to queue sweep job")
+			continue
+		}
+		log.Info().
+			Uint64("chain_id", chainID).
+			Str("job_id", job.ID).
+			Str("asset", asset).
+			Str("token", job.TokenSymbol).
+			Str("balance", balance.String()).
+			Str("ceiling", ceiling.String()).
+			Str("amount", amount.String()).
+			Str("to", cold).
+			Msg("Hot wallet above ceiling, sweep queued")
+	}
+}
+
+// sweepAmount 余额超过上限时返回超出保留余额 target 的部分，否则为 nil
+func sweepAmount(balance, ceiling, target *big.Int) *big.Int {
+	if balance.Cmp(ceiling) <= 0 {
+		return nil
+	}
+	return new(big.Int).Sub(balance, target)
+}
+
+// sweepJob 构建从付款钱包到冷钱包的归集任务
+func (s *PayoutService) sweepJob(ctx context.Context, chainID uint64, chainCfg config.ChainConfig, hot, asset string, amount *big.Int) (*queue.Job, error) {
+	now := time.Now()
+	id := fmt.Sprintf("sweep-%d-%d", chainID, now.UnixMilli())
+	job := &queue.Job{
+		BatchID:     id,
+		UserID:      sweepUserID,
+		FromAddress: hot,
+		ToAddress:   chainCfg.Sweep.ColdAddress,
+		Amount:      amount.String(),
+		ChainID:     chainID,
+		Testnet:     chainCfg.Testnet,
+		Priority:    string(gas.PriorityLow), // 归集不紧急，不占用支付的处理顺序
+		CreatedAt:   now,
+		Sweep:       true,
+	}
+	switch {
+	case asset == config.SweepNative:
+		job.TokenSymbol = chainCfg.NativeToken
+		job.TokenDecimals = uint32(chainCfg.Decimals)
+	default:
+		meta, err := s.resolveTokenMetadata(ctx, chainID, asset)
+		if err != nil {
+			return nil, err
+		}
+		if chainCfg.Type == "tron" && isTRC10Asset(asset) {
+			if !amount.IsInt64() {
+				return nil, fmt.Errorf("amount %s exceeds the TRC10 maximum", amount)
+			}
+			job.TokenID = asset
+		} else {
+			job.TokenAddress = asset
+		}
+		job.TokenSymbol = meta.Symbol
+		job.TokenDecimals = meta.Decimals
+	}
+	job.ID = id + "-" + strings.ToLower(job.TokenSymbol)
+	return job, nil
+}
+
+// validChainAddress 地址格式是否符合链类型
+func validChainAddress(chainType, address string) bool {
+	if chainType == "tron" {
+		return isTronAddress(address)
+	}
+	return common.IsHexAddress(address)
+}
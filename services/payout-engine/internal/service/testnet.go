@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// faucetHTTPTimeout 单次水龙头请求超时
+const faucetHTTPTimeout = 30 * time.Second
+
+// isTestnetChain 是否为测试网链 (测试网放宽代币白名单等限制)
+func (s *PayoutService) isTestnetChain(chainID uint64) bool {
+	if s.cfg == nil {
+		return false
+	}
+	return s.chainConfig(chainID).Testnet
+}
+
+// RunFaucetMonitor 测试网模式 (或影子模式) 下定期检查付款钱包余额，低于阈值时请求水龙头充值
+func (s *PayoutService) RunFaucetMonitor(ctx context.Context, interval time.Duration) {
+	if !s.cfg.IsTestnet() && !s.cfg.Shadow.Enabled {
+		return
+	}
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	log.Info().Dur("interval", interval).Msg("Testnet faucet monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for chainID, chainCfg := range s.chainConfigs() {
+			if !chainCfg.Testnet || chainCfg.Faucet.URL == "" {
+				continue
+			}
+			if err := s.topUpIfLow(ctx, chainID, chainCfg); err != nil {
+				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Faucet top-up failed")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Testnet faucet monitor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// topUpIfLow 检查付款钱包余额并在需要时请求水龙头
+func (s *PayoutService) topUpIfLow(ctx context.Context, chainID uint64, chainCfg config.ChainConfig) error {
+	minBalance, ok := new(big.Int).SetString(chainCfg.Faucet.MinBalance, 10)
+	if !ok {
+		return fmt.Errorf("invalid faucet min balance: %s", chainCfg.Faucet.MinBalance)
+	}
+
+	address, balance, err := s.payoutWalletBalance(ctx, chainID)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(minBalance) >= 0 {
+		return nil
+	}
+
+	s.faucetMu.Lock()
+	last := s.faucetRequested[chainID]
+	if time.Since(last) < chainCfg.Faucet.Cooldown {
+		s.faucetMu.Unlock()
+		log.Debug().Uint64("chain_id", chainID).Time("last_request", last).Msg("Faucet cooldown active, skipping top-up")
+		return nil
+	}
+	s.faucetRequested[chainID] = time.Now()
+	s.faucetMu.Unlock()
+
+	log.Info().
+		Uint64("chain_id", chainID).
+		Str("address", address).
+		Str("balance", balance.String()).
+		Str("min_balance", minBalance.String()).
+		Msg("Payout wallet below threshold, requesting faucet top-up")
+
+	return requestFaucet(ctx, chainCfg.Faucet, chainID, address)
+}
+
+// payoutWalletBalance 返回付款钱包地址和原生代币余额
+func (s *PayoutService) payoutWalletBalance(ctx context.Context, chainID uint64) (string, *big.Int, error) {
+	address, err := s.payoutAddress(chainID)
+	if err != nil {
+		return "", nil, err
+	}
+	balance, err := s.nativeBalance(ctx, chainID, address)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return address, balance, nil
+}
+
+// payoutAddress 返回链上的付款钱包地址 (EVM 为该链签名器地址，TRON 为 TRON 签名器地址)
+func (s *PayoutService) payoutAddress(chainID uint64) (string, error) {
+	if _, ok := s.tronClient(chainID); ok {
+		return s.tronPayoutAddress()
+	}
+	signer := s.signerFor(chainID)
+	if signer == nil {
+		return "", fmt.Errorf("signer is not configured")
+	}
+	return signer.Address().Hex(), nil
+}
+
+// tronPayoutAddress TRON 付款签名器的地址
+func (s *PayoutService) tronPayoutAddress() (string, error) {
+	if s.tronSigner == nil {
+		return "", fmt.Errorf("TRON signer is not configured (set TRON_PRIVATE_KEY or PAYOUT_PRIVATE_KEY)")
+	}
+	return tronSignerAddress(s.tronSigner), nil
+}
+
+// requestFaucet 调用水龙头 HTTP 接口
+func requestFaucet(ctx context.Context, faucet config.FaucetConfig, chainID uint64, address string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"address":  address,
+		"chain_id": chainID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, faucetHTTPTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, faucet.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if faucet.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+faucet.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("faucet request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("faucet returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	log.Info().Uint64("chain_id", chainID).Str("address", address).Msg("Faucet top-up requested")
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// errNotTokenContract 地址上没有合约或合约不是 ERC20 (无法读取 symbol/decimals)
+var errNotTokenContract = errors.New("not a token contract")
+
+// tokenCacheAsset 缓存键中的资产 (EVM 地址不区分大小写；TRON 地址和 TRC10 资产 ID 原样保留)
+func tokenCacheAsset(asset string) string {
+	if common.IsHexAddress(asset) {
+		return strings.ToLower(asset)
+	}
+	return asset
+}
+
+// resolveTokenMetadata 返回代币的链上 symbol 和 decimals，优先读取 Redis 缓存
+func (s *PayoutService) resolveTokenMetadata(ctx context.Context, chainID uint64, asset string) (*queue.TokenMetadata, error) {
+	key := tokenCacheAsset(asset)
+	cached, err := s.queue.GetTokenMetadata(ctx, chainID, key)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Str("token", asset).Msg("Failed to read token metadata cache")
+	} else if cached != nil {
+		return cached, nil
+	}
+
+	symbol, decimals, err := s.tokenMetadata(ctx, chainID, asset)
+	if err != nil {
+		return nil, err
+	}
+	meta := &queue.TokenMetadata{Symbol: symbol, Decimals: uint32(decimals), ResolvedAt: time.Now()}
+	if err := s.queue.SetTokenMetadata(ctx, chainID, key, meta, s.cfg.TokenMetadataTTL); err != nil {
+		log.Warn().Err(err).Uint64("chain_id", chainID).Str("token", asset).Msg("Failed to cache token metadata")
+	}
+	return meta, nil
+}
+
+// checkTokenDecimals 核对各支付项声明的 token_decimals 与链上合约一致。
+// 金额折算 (审批阈值、大额检查等) 依赖精度，不信任客户端提供的值。
+func (s *PayoutService) checkTokenDecimals(ctx context.Context, req *BatchPayoutRequest) error {
+	onchain := make(map[string]*queue.TokenMetadata)
+	for _, item := range req.Items {
+		asset := item.asset()
+		if isNativeToken(asset) {
+			continue
+		}
+		if _, ok := onchain[asset]; ok {
+			continue
+		}
+		meta, err := s.resolveTokenMetadata(ctx, req.ChainID, asset)
+		if errors.Is(err, errNotTokenContract) {
+			return fmt.Errorf("token %s: %w", asset, err)
+		}
+		if err != nil {
+			return &UnavailableError{Err: fmt.Errorf("failed to read metadata of token %s: %w", asset, err)}
+		}
+		onchain[asset] = meta
+	}
+	return tokenDecimalsMismatch(req.Items, onchain)
+}
+
+// tokenDecimalsMismatch 返回第一个声明精度与链上不符的支付项
+func tokenDecimalsMismatch(items []PayoutItem, onchain map[string]*queue.TokenMetadata) error {
+	for i, item := range items {
+		meta, ok := onchain[item.asset()]
+		if !ok {
+			continue
+		}
+		if item.TokenDecimals != meta.Decimals {
+			return fmt.Errorf("item[%d]: token_decimals %d does not match %s (%s) on-chain decimals %d",
+				i, item.TokenDecimals, item.asset(), meta.Symbol, meta.Decimals)
+		}
+	}
+	return nil
+}
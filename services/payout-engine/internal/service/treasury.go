@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// treasuryApproveABI ERC20 approve: 金库授权请求中金库执行的调用，兑换前对聚合器授权也使用
+const treasuryApproveABI = `[{"constant":false,"inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+
+// TreasuryAllowanceCode 金库对付款地址的授权额度不足
+const TreasuryAllowanceCode = "TREASURY_ALLOWANCE_INSUFFICIENT"
+
+// treasuryAllowanceRetry 等待金库管理方执行授权 (见 RequestTreasuryApproval)，耗尽后进入死信队列，授权后可重新入队
+var treasuryAllowanceRetry = queue.RetryPolicy{MaxRetries: 6, InitialBackoff: 5 * time.Minute, MaxBackoff: 30 * time.Minute, Multiplier: 2, Jitter: 0.1}
+
+// TreasuryAllowanceError 金库对付款地址的授权额度低于任务金额，不发送
+type TreasuryAllowanceError struct {
+	Treasury  string
+	Token     string
+	Allowance *big.Int
+	Required  *big.Int
+}
+
+func (e *TreasuryAllowanceError) Error() string {
+	return fmt.Sprintf("treasury %s allowance %s for token %s is below %s", e.Treasury, e.Allowance, e.Token, e.Required)
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *TreasuryAllowanceError) ErrorCode() string { return TreasuryAllowanceCode }
+
+// RetryPolicy implements queue.RetryHint.
+func (e *TreasuryAllowanceError) RetryPolicy() queue.RetryPolicy { return treasuryAllowanceRetry }
+
+// batchTreasury 金库出款批次的金库合约地址 (其他批次为空)
+func (s *PayoutService) batchTreasury(req *BatchPayoutRequest) string {
+	if !req.UseTreasury {
+		return ""
+	}
+	return s.chainConfig(req.ChainID).Treasury
+}
+
+// validateTreasury 校验金库出款批次: 仅配置了金库的 EVM 链，且全部为 ERC20 代币转账。
+// 授权额度和金库余额在预检时按批次合计检查 (见 preflightBalances)。
+func (s *PayoutService) validateTreasury(req *BatchPayoutRequest) error {
+	if _, ok := s.evmClient(req.ChainID); !ok {
+		return fmt.Errorf("treasury payouts are only supported on EVM chains")
+	}
+	if s.chainConfig(req.ChainID).Treasury == "" {
+		return fmt.Errorf("no treasury configured on chain_id: %d", req.ChainID)
+	}
+	if req.UseSmartAccount || req.Permit != nil {
+		return fmt.Errorf("treasury payouts cannot use a smart account or permit")
+	}
+	if req.Simulate {
+		return fmt.Errorf("treasury payouts cannot be simulated")
+	}
+	if !common.IsHexAddress(req.FromAddress) {
+		return fmt.Errorf("invalid EVM from_address")
+	}
+	for i, item := range req.Items {
+		if isNativeToken(item.asset()) || item.TokenID != "" {
+			return fmt.Errorf("item[%d]: treasury payouts must transfer ERC20 tokens", i)
+		}
+	}
+	return nil
+}
+
+// treasuryAvailable 金库可支出的代币数量: 余额与对付款地址授权额度中较小的一个
+func (s *PayoutService) treasuryAvailable(ctx context.Context, chainID uint64, treasury, spender, token string, balance *big.Int) (*big.Int, error) {
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	allowance, err := s.permitAllowance(ctx, client, common.HexToAddress(token), common.HexToAddress(treasury), common.HexToAddress(spender))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read treasury allowance: %w", err)
+	}
+	if allowance.Cmp(balance) < 0 {
+		log.Warn().
+			Uint64("chain_id", chainID).
+			Str("treasury", treasury).
+			Str("token", token).
+			Str("allowance", allowance.String()).
+			Msg("Treasury allowance limits payouts below its balance")
+		return allowance, nil
+	}
+	return balance, nil
+}
+
+// checkTreasuryAllowance 发送前确认金库对付款地址的授权额度覆盖任务金额
+func (s *PayoutService) checkTreasuryAllowance(ctx context.Context, client ethCaller, job *queue.Job) error {
+	amount, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("invalid amount: %s", job.Amount))
+	}
+	allowance, err := s.permitAllowance(ctx, client, common.HexToAddress(job.TokenAddress), common.HexToAddress(job.Treasury), common.HexToAddress(job.FromAddress))
+	if err != nil {
+		if errors.Is(err, errPermitUnsupported) {
+			return queue.Permanent(err)
+		}
+		return fmt.Errorf("failed to read treasury allowance: %w", err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		return &TreasuryAllowanceError{Treasury: job.Treasury, Token: job.TokenAddress, Allowance: allowance, Required: amount}
+	}
+	return nil
+}
+
+// TreasuryAllowance 金库对付款签名地址的授权额度和金库余额
+type TreasuryAllowance struct {
+	ChainID   uint64 `json:"chain_id"`
+	Treasury  string `json:"treasury"`
+	Token     string `json:"token"`
+	Spender   string `json:"spender"`
+	Allowance string `json:"allowance"`
+	Balance   string `json:"balance"`
+}
+
+// treasuryAccounts 链的金库合约和付款签名地址 (授权的 spender)
+func (s *PayoutService) treasuryAccounts(chainID uint64) (treasury, spender common.Address, err error) {
+	if _, ok := s.evmClient(chainID); !ok {
+		return treasury, spender, &InvalidArgumentError{Err: fmt.Errorf("unsupported EVM chain_id: %d", chainID)}
+	}
+	configured := s.chainConfig(chainID).Treasury
+	if configured == "" {
+		return treasury, spender, &InvalidArgumentError{Err: fmt.Errorf("no treasury configured on chain_id: %d", chainID)}
+	}
+	signer := s.signerFor(chainID)
+	if signer == nil {
+		return treasury, spender, &FailedPreconditionError{Err: fmt.Errorf("payout signer is not configured")}
+	}
+	return common.HexToAddress(configured), signer.Address(), nil
+}
+
+// GetTreasuryAllowance 读取金库对付款签名地址的代币授权额度和余额
+func (s *PayoutService) GetTreasuryAllowance(ctx context.Context, chainID uint64, token string) (*TreasuryAllowance, error) {
+	treasury, spender, err := s.treasuryAccounts(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(token) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid token address: %s", token)}
+	}
+	client, _ := s.evmClient(chainID)
+	allowance, err := s.permitAllowance(ctx, client, common.HexToAddress(token), treasury, spender)
+	if err != nil {
+		if errors.Is(err, errPermitUnsupported) {
+			return nil, &InvalidArgumentError{Err: err}
+		}
+		return nil, &UnavailableError{Err: err}
+	}
+	balance, err := s.erc20BalanceOf(ctx, client, common.HexToAddress(token), treasury)
+	if err != nil {
+		return nil, &UnavailableError{Err: err}
+	}
+	return &TreasuryAllowance{
+		ChainID:   chainID,
+		Treasury:  treasury.Hex(),
+		Token:     common.HexToAddress(token).Hex(),
+		Spender:   spender.Hex(),
+		Allowance: allowance.String(),
+		Balance:   balance.String(),
+	}, nil
+}
+
+// RequestTreasuryApproval 生成金库对付款签名地址的 approve 调用并记录为待执行的授权请求。
+// 引擎不持有金库的权限，由金库管理方 (如多签) 执行返回的 To / Data。
+func (s *PayoutService) RequestTreasuryApproval(ctx context.Context, chainID uint64, token, amount, reason string) (*queue.TreasuryApproval, error) {
+	treasury, spender, err := s.treasuryAccounts(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(token) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid token address: %s", token)}
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("invalid amount: %s", amount)}
+	}
+	data, err := s.treasuryABI.Pack("approve", spender, value)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddr := common.HexToAddress(token)
+	approval := &queue.TreasuryApproval{
+		ChainID:  chainID,
+		Treasury: treasury.Hex(),
+		Token:    tokenAddr.Hex(),
+		Spender:  spender.Hex(),
+		Amount:   value.String(),
+		To:       tokenAddr.Hex(),
+		Data:     hexutil.Encode(data),
+		Reason:   strings.TrimSpace(reason),
+	}
+	if err := s.queue.CreateTreasuryApproval(ctx, approval); err != nil {
+		return nil, err
+	}
+	log.Info().
+		Uint64("chain_id", chainID).
+		Str("approval_id", approval.ID).
+		Str("treasury", approval.Treasury).
+		Str("token", approval.Token).
+		Str("amount", approval.Amount).
+		Msg("Treasury approval requested")
+	return approval, nil
+}
+
+// ListTreasuryApprovals 链的金库授权请求 (chainID 为 0 时返回所有链)。
+// 等待中的请求在查询时链上额度已达到请求额度则标记为 fulfilled。
+func (s *PayoutService) ListTreasuryApprovals(ctx context.Context, chainID uint64) ([]*queue.TreasuryApproval, error) {
+	approvals, err := s.queue.ListTreasuryApprovals(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals {
+		if approval.Status != queue.TreasuryApprovalPending {
+			continue
+		}
+		client, ok := s.evmClient(approval.ChainID)
+		if !ok {
+			continue
+		}
+		allowance, err := s.permitAllowance(ctx, client, common.HexToAddress(approval.Token), common.HexToAddress(approval.Treasury), common.HexToAddress(approval.Spender))
+		if err != nil {
+			log.Warn().Err(err).Str("approval_id", approval.ID).Msg("Failed to read treasury allowance")
+			continue
+		}
+		requested, ok := new(big.Int).SetString(approval.Amount, 10)
+		if !ok {
+			continue
+		}
+		// 撤销授权 (额度 0) 须恰好为 0
+		if (requested.Sign() == 0 && allowance.Sign() == 0) || (requested.Sign() > 0 && allowance.Cmp(requested) >= 0) {
+			now := time.Now()
+			approval.Status = queue.TreasuryApprovalFulfilled
+			approval.FulfilledAt = &now
+			if err := s.queue.SaveTreasuryApproval(ctx, approval); err != nil {
+				log.Warn().Err(err).Str("approval_id", approval.ID).Msg("Failed to save treasury approval")
+			}
+		}
+	}
+	return approvals, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/queue"
+)
+
+// tronBatchRetention 批次最后一个任务结束后汇总保留的时间
+const tronBatchRetention = time.Hour
+
+// TronBatchReport 本实例处理的一个批次中 TRON 转账的汇总 (并发发送见 queue.SetChainConcurrency)
+type TronBatchReport struct {
+	ChainID   uint64    `json:"chain_id"`
+	UserID    string    `json:"user_id"`
+	BatchID   string    `json:"batch_id"`
+	InFlight  int       `json:"in_flight"`
+	Broadcast int       `json:"broadcast"` // 已广播的交易 (含执行失败)
+	Confirmed int       `json:"confirmed"`
+	Failed    int       `json:"failed"`
+	Fee       string    `json:"fee"` // 燃烧的 TRX 合计 (sun)
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	fee *big.Int
+}
+
+type tronBatchKey struct {
+	chainID uint64
+	batch   queue.BatchRef
+}
+
+// tronBatches 按批次汇总 TRON 任务的结果 (仅内存，重启后清空)
+type tronBatches struct {
+	mu      sync.Mutex
+	batches map[tronBatchKey]*TronBatchReport
+}
+
+// begin 记录任务开始处理，返回的函数在任务结束时记录结果
+func (t *tronBatches) begin(job *queue.Job) func(*queue.JobResult, error) {
+	now := time.Now()
+	key := tronBatchKey{chainID: job.ChainID, batch: queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}}
+
+	t.mu.Lock()
+	if t.batches == nil {
+		t.batches = make(map[tronBatchKey]*TronBatchReport)
+	}
+	for k, r := range t.batches {
+		if r.InFlight == 0 && now.Sub(r.UpdatedAt) > tronBatchRetention {
+			delete(t.batches, k)
+		}
+	}
+	report, ok := t.batches[key]
+	if !ok {
+		report = &TronBatchReport{ChainID: job.ChainID, UserID: job.UserID, BatchID: job.BatchID, StartedAt: now, fee: new(big.Int)}
+		t.batches[key] = report
+	}
+	report.InFlight++
+	report.UpdatedAt = now
+	t.mu.Unlock()
+
+	return func(result *queue.JobResult, err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		report.InFlight--
+		report.UpdatedAt = time.Now()
+		if err == nil && result.TxHash != "" {
+			report.Broadcast++
+		}
+		if err == nil && result.Success {
+			report.Confirmed++
+		} else {
+			report.Failed++
+		}
+		if err == nil && result.GasCost != nil {
+			if fee, ok := new(big.Int).SetString(result.GasCost.Fee, 10); ok {
+				report.fee.Add(report.fee, fee)
+			}
+		}
+	}
+}
+
+// reports 各批次汇总，最近开始的在前
+func (t *tronBatches) reports() []TronBatchReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]TronBatchReport, 0, len(t.batches))
+	for _, r := range t.batches {
+		report := *r
+		report.Fee = r.fee.String()
+		out = append(out, report)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out
+}
+
+// TronBatches 本实例最近处理的 TRON 批次汇总 (失败的尝试计入 failed，重试成功后同时计入 confirmed)
+func (s *PayoutService) TronBatches() []TronBatchReport {
+	return s.tronBatches.reports()
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	tronaddress "github.com/fbsobreira/gotron-sdk/pkg/address"
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// TRON 资源估算
+const (
+	tronEnergyMarginPct   = 20  // 能量估算余量 (%)
+	tronTRC20TxBytes      = 345 // TRC20 transfer 签名后大小 (含 64 字节结果预留)，即消耗的带宽
+	tronNativeTxBytes     = 270 // TRX 转账签名后大小
+	tronTRC10TxBytes      = 285 // TRC10 转账签名后大小 (多出资产 ID)
+	tronDefaultEnergySun  = 420 // 节点未返回价格时的能量单价 (SUN)
+	tronDefaultNetSun     = 1000
+	tronTransferSignature = "a9059cbb"
+
+	// TronResourceCode 能量/带宽不足且 TRX 余额不够燃烧
+	TronResourceCode = "INSUFFICIENT_TRON_RESOURCES"
+)
+
+// tronResourceClient TRON 资源估算所需的节点接口 (*tronclient.GrpcClient)
+type tronResourceClient interface {
+	GetAccount(addr string) (*troncore.Account, error)
+	GetAccountResource(addr string) (*tronapi.AccountResourceMessage, error)
+	TRC20Call(from, contractAddress, data string, constant bool, feeLimit int64) (*tronapi.TransactionExtention, error)
+	GetEnergyPrices() (*tronapi.PricesResponseMessage, error)
+	GetBandwidthPrices() (*tronapi.PricesResponseMessage, error)
+}
+
+// tronResources 付款账户当前可用资源和单价
+type tronResources struct {
+	Energy    int64 // 可用能量 (质押/代理获得)
+	Bandwidth int64 // 可用带宽: 质押带宽与每日免费带宽中较大者 (交易只能使用其中一种)
+	Balance   int64 // TRX 余额 (SUN)
+	EnergySun int64 // 每单位能量燃烧的 SUN
+	NetSun    int64 // 每字节带宽燃烧的 SUN
+}
+
+// tronFeePlan 一笔 TRON 交易的资源估算
+type tronFeePlan struct {
+	Energy    int64 // 预计消耗的能量 (含余量)，TRX/TRC10 转账为 0
+	Bandwidth int64 // 消耗的带宽 (字节)
+	BurnSun   int64 // 资源不足部分需燃烧的 TRX
+	FeeLimit  int64 // TRC20 fee_limit: 全部能量按燃烧计价
+}
+
+// TronResourceError 能量/带宽不足，且 TRX 余额不够燃烧
+type TronResourceError struct {
+	Address            string
+	EnergyNeeded       int64
+	EnergyAvailable    int64
+	BandwidthNeeded    int64
+	BandwidthAvailable int64
+	RequiredSun        int64 // 燃烧的网络费 + 转账金额 (TRX 转账)
+	BurnSun            int64
+	BalanceSun         int64
+}
+
+func (e *TronResourceError) Error() string {
+	return fmt.Sprintf("TRON account %s lacks resources: needs %d energy (%d available) and %d bandwidth (%d available); "+
+		"covering the shortfall burns %s TRX, %s TRX required in total but the balance is %s TRX. "+
+		"Stake TRX for energy/bandwidth (FreezeBalanceV2 or a resource delegation) or top up the account",
+		e.Address, e.EnergyNeeded, e.EnergyAvailable, e.BandwidthNeeded, e.BandwidthAvailable,
+		sunToTRX(e.BurnSun), sunToTRX(e.RequiredSun), sunToTRX(e.BalanceSun))
+}
+
+// ErrorCode implements queue.CodedError.
+func (e *TronResourceError) ErrorCode() string { return TronResourceCode }
+
+// planTronFee 计算资源不足时需燃烧的 TRX 和 fee_limit。
+// amountSun 为 TRX 转账金额 (TRC20/TRC10 为 0)，与燃烧的网络费一起须由余额覆盖。
+func planTronFee(address string, res tronResources, energy, txBytes, amountSun int64) (*tronFeePlan, error) {
+	plan := &tronFeePlan{Bandwidth: txBytes}
+	if energy > 0 {
+		plan.Energy = energy + energy*tronEnergyMarginPct/100
+		plan.FeeLimit = plan.Energy * res.EnergySun
+		if shortfall := plan.Energy - res.Energy; shortfall > 0 {
+			plan.BurnSun += shortfall * res.EnergySun
+		}
+	}
+	// 带宽不足时整笔交易按字节燃烧，不能部分使用
+	if res.Bandwidth < txBytes {
+		plan.BurnSun += txBytes * res.NetSun
+	}
+
+	if required := plan.BurnSun + amountSun; required > res.Balance {
+		return nil, &TronResourceError{
+			Address:            address,
+			EnergyNeeded:       plan.Energy,
+			EnergyAvailable:    res.Energy,
+			BandwidthNeeded:    txBytes,
+			BandwidthAvailable: res.Bandwidth,
+			RequiredSun:        required,
+			BurnSun:            plan.BurnSun,
+			BalanceSun:         res.Balance,
+		}
+	}
+	return plan, nil
+}
+
+// tronAccountResources 读取付款账户的资源、余额和当前单价
+func tronAccountResources(client tronResourceClient, address string) (tronResources, error) {
+	res := tronResources{EnergySun: tronDefaultEnergySun, NetSun: tronDefaultNetSun}
+
+	msg, err := client.GetAccountResource(address)
+	if err != nil {
+		return res, fmt.Errorf("failed to read account resources: %w", err)
+	}
+	res.Energy = max(msg.GetEnergyLimit()-msg.GetEnergyUsed(), 0)
+	res.Bandwidth = max(msg.GetNetLimit()-msg.GetNetUsed(), msg.GetFreeNetLimit()-msg.GetFreeNetUsed(), 0)
+
+	account, err := client.GetAccount(address)
+	if err != nil {
+		return res, fmt.Errorf("failed to read account balance: %w", err)
+	}
+	res.Balance = account.GetBalance()
+
+	if prices, err := client.GetEnergyPrices(); err == nil {
+		if price, ok := currentTronPrice(prices.GetPrices()); ok {
+			res.EnergySun = price
+		}
+	}
+	if prices, err := client.GetBandwidthPrices(); err == nil {
+		if price, ok := currentTronPrice(prices.GetPrices()); ok {
+			res.NetSun = price
+		}
+	}
+	return res, nil
+}
+
+// currentTronPrice 解析价格历史 "timestamp:price,timestamp:price,..." 中最新的价格
+func currentTronPrice(history string) (int64, bool) {
+	entries := strings.Split(strings.TrimSpace(history), ",")
+	_, price, found := strings.Cut(entries[len(entries)-1], ":")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(price, 10, 64)
+	return n, err == nil && n > 0
+}
+
+// estimateTRC20Energy 以常量调用 transfer 估算能量消耗。调用会回滚 (如代币余额不足) 时返回永久错误。
+func estimateTRC20Energy(client tronResourceClient, from, to, token string, amount *big.Int) (int64, error) {
+	recipient, err := tronaddress.Base58ToAddress(to)
+	if err != nil {
+		return 0, queue.Permanent(fmt.Errorf("invalid TRON recipient address %s: %w", to, err))
+	}
+	data := "0x" + tronTransferSignature +
+		common.Bytes2Hex(common.LeftPadBytes(recipient.Bytes()[1:], 32)) +
+		common.Bytes2Hex(common.LeftPadBytes(amount.Bytes(), 32))
+
+	result, err := client.TRC20Call(from, token, data, true, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate TRC20 energy: %w", err)
+	}
+	if ret := result.GetTransaction().GetRet(); len(ret) > 0 && ret[0].GetContractRet() == troncore.Transaction_Result_REVERT {
+		reverted := fmt.Errorf("TRC20 transfer would revert (check the token balance of %s)", from)
+		return 0, queue.Permanent(queue.WithChainError(reverted, &queue.ChainError{Code: queue.ChainErrReverted}))
+	}
+	return result.GetEnergyUsed(), nil
+}
+
+// sunToTRX 格式化 SUN 为 TRX
+func sunToTRX(sun int64) string {
+	return new(big.Rat).SetFrac64(sun, 1_000_000).FloatString(6)
+}
+
+// tronFeeLimit 按账户资源估算 TRON 交易网络费，返回 TRC20 fee_limit (TRX/TRC10 转账为 0)。
+// 能量/带宽和 TRX 余额都不足时返回永久错误，不再广播注定失败的交易。
+// 估算所需的查询失败时退回 TRC20_FEE_LIMIT。
+func (s *PayoutService) tronFeeLimit(client tronResourceClient, job *queue.Job, amount *big.Int) (int64, error) {
+	maxFeeLimit := s.cfg.TRC20FeeLimit
+	if maxFeeLimit <= 0 {
+		maxFeeLimit = 100_000_000 // 100 TRX default
+	}
+	fallback := int64(0)
+	if job.TokenAddress != "" {
+		fallback = maxFeeLimit
+	}
+	// 任务设置了网络费上限时，无法估算的 TRC20 转账最多燃烧该上限
+	maxFee, err := parseMaxFee(job.MaxFee)
+	if err != nil {
+		return 0, queue.Permanent(err)
+	}
+	if maxFee != nil && fallback > 0 && maxFee.Cmp(big.NewInt(fallback)) < 0 {
+		fallback = maxFee.Int64()
+	}
+
+	var energy int64
+	txBytes, amountSun := int64(tronNativeTxBytes), amount.Int64()
+	switch {
+	case job.TokenID != "":
+		// TRC10 是系统合约，只消耗带宽，不需要 fee_limit
+		txBytes, amountSun = tronTRC10TxBytes, 0
+		if err := checkTRC10Balance(client, job.FromAddress, job.TokenID, amount); err != nil {
+			return 0, err
+		}
+	case job.TokenAddress != "":
+		txBytes, amountSun = tronTRC20TxBytes, 0
+		used, err := estimateTRC20Energy(client, job.FromAddress, job.ToAddress, job.TokenAddress, amount)
+		if queue.IsPermanent(err) {
+			return 0, err
+		}
+		if err != nil || used <= 0 {
+			log.Warn().Err(err).Str("job_id", job.ID).Int64("fee_limit", maxFeeLimit).Msg("TRON energy estimation unavailable, using TRC20_FEE_LIMIT")
+			return fallback, nil
+		}
+		energy = used
+	}
+
+	res, err := tronAccountResources(client, job.FromAddress)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Str("from", job.FromAddress).Msg("TRON resource check skipped")
+		return fallback, nil
+	}
+	plan, err := planTronFee(job.FromAddress, res, energy, txBytes, amountSun)
+	if err != nil {
+		return 0, queue.Permanent(err)
+	}
+	if maxFee != nil && big.NewInt(plan.BurnSun).Cmp(maxFee) > 0 {
+		return 0, &FeeCapError{Estimated: big.NewInt(plan.BurnSun), MaxFee: maxFee}
+	}
+	if plan.FeeLimit > maxFeeLimit {
+		return 0, queue.Permanent(fmt.Errorf("estimated TRC20 fee limit %s TRX (%d energy at %d SUN) exceeds TRC20_FEE_LIMIT %s TRX",
+			sunToTRX(plan.FeeLimit), plan.Energy, res.EnergySun, sunToTRX(maxFeeLimit)))
+	}
+
+	log.Debug().
+		Str("job_id", job.ID).
+		Int64("energy", plan.Energy).
+		Int64("energy_available", res.Energy).
+		Int64("bandwidth_available", res.Bandwidth).
+		Int64("burn_sun", plan.BurnSun).
+		Int64("fee_limit", plan.FeeLimit).
+		Msg("TRON fee estimated")
+	return plan.FeeLimit, nil
+}
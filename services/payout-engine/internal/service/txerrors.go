@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/protocol-bank/payout-engine/internal/forksim"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+)
+
+// broadcastRetryPolicies 各类广播失败的重试策略 (未设置的字段使用队列的默认策略)
+var broadcastRetryPolicies = map[rpcpool.TxErrorClass]queue.RetryPolicy{
+	// 已重置 nonce 缓存，下一次使用链上 nonce，尽快重试
+	rpcpool.TxErrorNonceTooLow: {MaxRetries: 5, InitialBackoff: time.Second, Multiplier: 1, Jitter: 0.5},
+	// 前面的 nonce 尚未上链 (或由 nonce 空缺检测补齐)，等待后重试
+	rpcpool.TxErrorNonceTooHigh: {MaxRetries: 5, InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute, Multiplier: 2, Jitter: 0.2},
+	// 重试时重新估算费用
+	rpcpool.TxErrorUnderpriced: {MaxRetries: 5, InitialBackoff: 15 * time.Second, MaxBackoff: 2 * time.Minute, Multiplier: 2, Jitter: 0.3},
+	// 等待补充余额 (见 gas tank)
+	rpcpool.TxErrorInsufficientFunds: {MaxRetries: 4, InitialBackoff: time.Minute, MaxBackoff: 10 * time.Minute, Multiplier: 2, Jitter: 0.1},
+}
+
+// BroadcastError 节点拒绝交易，按错误类别重试 (实现 queue.RetryHint 和 queue.CodedError)
+type BroadcastError struct {
+	Class rpcpool.TxErrorClass
+	Err   error
+}
+
+func (e *BroadcastError) Error() string { return e.Err.Error() }
+func (e *BroadcastError) Unwrap() error { return e.Err }
+
+// ErrorCode 如 TX_NONCE_TOO_LOW
+func (e *BroadcastError) ErrorCode() string { return "TX_" + strings.ToUpper(string(e.Class)) }
+
+// RetryPolicy implements queue.RetryHint.
+func (e *BroadcastError) RetryPolicy() queue.RetryPolicy { return broadcastRetryPolicies[e.Class] }
+
+// classifySendError 对签名或广播失败分类: nonce 与链上不一致时重置 nonce 缓存，
+// 已知类别包装为 BroadcastError，其余原样返回
+func (s *PayoutService) classifySendError(ctx context.Context, chainID uint64, from common.Address, err error) error {
+	class := rpcpool.ClassifyTxError(err)
+	if class.IsNonceError() {
+		s.nonceManager.ResetNonce(ctx, chainID, from)
+	}
+	if _, ok := broadcastRetryPolicies[class]; !ok {
+		return err
+	}
+	return &BroadcastError{Class: class, Err: err}
+}
+
+// TronRejectedError TRON 节点拒绝构建或广播交易 (Code 为节点返回的结果码，如 CONTRACT_VALIDATE_ERROR)
+type TronRejectedError struct {
+	Code string
+	Err  error
+}
+
+func (e *TronRejectedError) Error() string { return e.Err.Error() }
+func (e *TronRejectedError) Unwrap() error { return e.Err }
+
+// broadcastChainErrors 广播失败类别对应的链上错误类别
+var broadcastChainErrors = map[rpcpool.TxErrorClass]queue.ChainErrorCode{
+	rpcpool.TxErrorNonceTooLow:       queue.ChainErrNonceConflict,
+	rpcpool.TxErrorNonceTooHigh:      queue.ChainErrNonceConflict,
+	rpcpool.TxErrorInsufficientFunds: queue.ChainErrInsufficientFunds,
+	rpcpool.TxErrorUnderpriced:       queue.ChainErrRejectedByNode,
+}
+
+// chainErrorOf 按错误链归类任务失败 (见 queue.ChainError)，与链无关的失败 (如支出策略拦截) 返回 nil
+func chainErrorOf(err error) *queue.ChainError {
+	if err == nil {
+		return nil
+	}
+	if chain := queue.ChainErrorOf(err); chain != nil {
+		return chain
+	}
+
+	var (
+		reverted     *forksim.RevertedError
+		broadcast    *BroadcastError
+		resource     *TronResourceError
+		tronRejected *TronRejectedError
+		rpcErr       rpc.Error
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &reverted):
+		return &queue.ChainError{Code: queue.ChainErrReverted, Details: map[string]string{
+			"provider":      reverted.Provider,
+			"revert_reason": reverted.Result.RevertReason,
+		}}
+	case errors.As(err, &broadcast):
+		return &queue.ChainError{Code: broadcastChainErrors[broadcast.Class], Details: map[string]string{"class": string(broadcast.Class)}}
+	case errors.As(err, &resource):
+		return &queue.ChainError{Code: queue.ChainErrInsufficientFunds, Details: map[string]string{
+			"address":     resource.Address,
+			"required":    strconv.FormatInt(resource.RequiredSun, 10),
+			"balance":     strconv.FormatInt(resource.BalanceSun, 10),
+			"energy":      strconv.FormatInt(resource.EnergyNeeded, 10),
+			"bandwidth":   strconv.FormatInt(resource.BandwidthNeeded, 10),
+			"burn_amount": strconv.FormatInt(resource.BurnSun, 10),
+		}}
+	case errors.As(err, &tronRejected):
+		code := queue.ChainErrRejectedByNode
+		if strings.Contains(strings.ToLower(tronRejected.Error()), "balance is not sufficient") {
+			code = queue.ChainErrInsufficientFunds
+		}
+		return &queue.ChainError{Code: code, Details: map[string]string{"node_code": tronRejected.Code}}
+	case errors.As(err, &rpcErr):
+		return rpcChainError(err, rpcErr)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return &queue.ChainError{Code: queue.ChainErrRPCTimeout}
+	case errors.As(err, &netErr):
+		return &queue.ChainError{Code: queue.ChainErrRPCTimeout, Details: map[string]string{"timeout": strconv.FormatBool(netErr.Timeout())}}
+	}
+	return nil
+}
+
+// rpcChainError 节点返回的 JSON-RPC 错误: 估算 gas 时回滚、余额或 nonce 问题，其余视为节点拒绝
+func rpcChainError(err error, rpcErr rpc.Error) *queue.ChainError {
+	details := map[string]string{"rpc_code": strconv.Itoa(rpcErr.ErrorCode())}
+	if strings.Contains(strings.ToLower(rpcErr.Error()), "execution reverted") {
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+			details["revert_data"] = fmt.Sprint(dataErr.ErrorData())
+		}
+		return &queue.ChainError{Code: queue.ChainErrReverted, Details: details}
+	}
+	class := rpcpool.ClassifyTxError(err)
+	if code, ok := broadcastChainErrors[class]; ok {
+		details["class"] = string(class)
+		return &queue.ChainError{Code: code, Details: details}
+	}
+	return &queue.ChainError{Code: queue.ChainErrRejectedByNode, Details: details}
+}
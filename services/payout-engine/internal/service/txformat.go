@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/kms"
+	"github.com/protocol-bank/payout-engine/internal/metrics"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/protocol-bank/payout-engine/internal/rpcpool"
+	"github.com/protocol-bank/payout-engine/internal/tracing"
+	"github.com/protocol-bank/payout-engine/internal/zksync"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// txFormat 一类链的付款交易格式。付款交易先按 EIP-1559 字段构建为 DynamicFeeTx (费用上限、分叉模拟、
+// 卡单替换均基于这些字段)，再由链的格式签名并编码为原始交易。新的链族实现 txFormat 并在 txFormats 中
+// 按 ChainConfig.TxFormat 注册，sendJob 和卡单替换不需要修改。
+type txFormat interface {
+	// sign 签名交易并编码，同时返回签名摘要 (签名失败时也返回，用于审计)
+	sign(ctx context.Context, signer kms.Signer, tx *types.Transaction) (*signedTx, common.Hash, error)
+	// parse 解析已签名的原始交易 (重新广播和替换卡住的交易)
+	parse(raw []byte) (*signedTx, error)
+}
+
+// txFormats ChainConfig.TxFormat 对应的交易格式 (为空时为 EIP-1559)
+var txFormats = map[string]txFormat{
+	config.TxFormatEIP1559: eip1559Format{},
+	config.TxFormatZkSync:  zkSyncFormat{},
+}
+
+// signedTx 已签名的付款交易
+type signedTx struct {
+	hash   common.Hash
+	raw    []byte
+	fields *types.Transaction // EIP-1559 字段 (nonce、费用、gas、to/value/data)
+	tx     *types.Transaction // 标准以太坊交易 (可经私有交易池广播)，其他格式为 nil
+}
+
+// txFormatFor 按名称取交易格式，未注册的名称 (含空值) 使用 EIP-1559
+func txFormatFor(name string) txFormat {
+	if f, ok := txFormats[name]; ok {
+		return f
+	}
+	return eip1559Format{}
+}
+
+// eip1559Format 标准以太坊交易
+type eip1559Format struct{}
+
+func (eip1559Format) sign(ctx context.Context, signer kms.Signer, tx *types.Transaction) (*signedTx, common.Hash, error) {
+	digest := types.LatestSignerForChainID(tx.ChainId()).Hash(tx)
+	signed, err := signer.SignTransaction(ctx, tx, tx.ChainId())
+	if err != nil {
+		return nil, digest, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, digest, err
+	}
+	return &signedTx{hash: signed.Hash(), raw: raw, fields: signed, tx: signed}, digest, nil
+}
+
+func (eip1559Format) parse(raw []byte) (*signedTx, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return &signedTx{hash: tx.Hash(), raw: raw, fields: &tx, tx: &tx}, nil
+}
+
+// zkSyncFormat zkSync Era 的 EIP-712 交易 (类型 0x71)，签名为 EIP-712 摘要
+type zkSyncFormat struct{}
+
+func (zkSyncFormat) sign(ctx context.Context, signer kms.Signer, tx *types.Transaction) (*signedTx, common.Hash, error) {
+	if tx.To() == nil {
+		return nil, common.Hash{}, errors.New("zksync transactions require a recipient")
+	}
+	ztx := &zksync.Transaction{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: tx.GasTipCap(),
+		GasFeeCap: tx.GasFeeCap(),
+		Gas:       tx.Gas(),
+		From:      signer.Address(),
+		To:        *tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	digest := ztx.SigningHash()
+	sig, err := signer.SignHash(ctx, digest.Bytes())
+	if err != nil {
+		return nil, digest, err
+	}
+	raw, err := ztx.Encode(sig)
+	if err != nil {
+		return nil, digest, err
+	}
+	hash, err := ztx.Hash(sig)
+	if err != nil {
+		return nil, digest, err
+	}
+	return &signedTx{hash: hash, raw: raw, fields: tx}, digest, nil
+}
+
+func (zkSyncFormat) parse(raw []byte) (*signedTx, error) {
+	ztx, sig, err := zksync.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := ztx.Hash(sig)
+	if err != nil {
+		return nil, err
+	}
+	fields := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   ztx.ChainID,
+		Nonce:     ztx.Nonce,
+		GasTipCap: ztx.GasTipCap,
+		GasFeeCap: ztx.GasFeeCap,
+		Gas:       ztx.Gas,
+		To:        &ztx.To,
+		Value:     ztx.Value,
+		Data:      ztx.Data,
+	})
+	return &signedTx{hash: hash, raw: raw, fields: fields}, nil
+}
+
+// parsePendingTx 按记录的格式解析待确认交易的原始交易
+func parsePendingTx(p *queue.PendingTx) (*signedTx, error) {
+	raw, err := hex.DecodeString(p.RawTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw tx: %w", err)
+	}
+	stx, err := txFormatFor(p.TxFormat).parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode raw tx: %w", err)
+	}
+	return stx, nil
+}
+
+// signChainTx 按交易格式 (见 ChainConfig.TxFormat) 签名交易 (通过 kms.Signer: 本地私钥、Fireblocks 或 MPC)，并写入签名审计日志
+func (s *PayoutService) signChainTx(ctx context.Context, format string, tx *types.Transaction, chainID uint64, op signingOp) (signed *signedTx, err error) {
+	ctx, span := tracing.Start(ctx, "kms.sign", trace.WithAttributes(tracing.AttrChainID.Int64(int64(chainID))))
+	defer func() { tracing.End(span, err) }()
+
+	signer := s.signerForAddress(chainID, op.from)
+	if signer == nil {
+		return nil, fmt.Errorf("critical: payment processing signer is not configured")
+	}
+	span.SetAttributes(attribute.String("kms.provider", signer.Provider()))
+	start := time.Now()
+	signed, digest, err := txFormatFor(format).sign(signingContext(ctx, op), signer, tx)
+	metrics.SigningLatency.ObserveDuration(start, metrics.Label(chainID), signer.Provider(), signingResult(err))
+	if auditErr := s.auditSigning(ctx, chainID, op, digest.Bytes(), signer.Provider(), signer.Address().Hex(), err); auditErr != nil {
+		return nil, auditErr
+	}
+	return signed, err
+}
+
+// broadcastSigned 广播已签名交易，非标准格式按原始交易广播
+func (s *PayoutService) broadcastSigned(ctx context.Context, client *rpcpool.Pool, chainID uint64, stx *signedTx) error {
+	if stx.tx != nil {
+		return s.broadcastTransaction(ctx, client, chainID, stx.tx)
+	}
+	ctx, span := tracing.Start(ctx, "rpc.broadcast",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tracing.AttrChainID.Int64(int64(chainID)), tracing.AttrTxHash.String(stx.hash.Hex())),
+	)
+	err := client.SendRawTransaction(ctx, stx.raw)
+	if rpcpool.IsAlreadyKnown(err) {
+		err = nil
+	}
+	recordBroadcastFailure(chainID, err)
+	tracing.End(span, err)
+	return err
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// wrappedNativeABI WETH9 / WMATIC 的 withdraw (解包为原生代币)
+const wrappedNativeABI = `[{"constant":false,"inputs":[{"name":"wad","type":"uint256"}],"name":"withdraw","outputs":[],"type":"function"}]`
+
+// unwrapGas WETH9 withdraw 预留的 Gas (实际约 35k)
+const unwrapGas = 50000
+
+// balanceReader 解包判断所需的最小 RPC 接口
+type balanceReader interface {
+	ethCaller
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// unwrapShortfall 原生余额不足以支付 need 时返回需要解包的数量。
+// 无需解包、包装代币余额不足以补齐或原生余额付不起解包网络费 (unwrapFee) 时返回 nil。
+func (s *PayoutService) unwrapShortfall(ctx context.Context, client balanceReader, wrapped, from common.Address, need, unwrapFee *big.Int) (*big.Int, error) {
+	native, err := client.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read native balance: %w", err)
+	}
+	total := new(big.Int).Add(need, unwrapFee)
+	if native.Cmp(need) >= 0 || native.Cmp(unwrapFee) < 0 {
+		return nil, nil
+	}
+	shortfall := new(big.Int).Sub(total, native)
+
+	wrappedBal, err := s.erc20BalanceOf(ctx, client, wrapped, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapped balance: %w", err)
+	}
+	if wrappedBal.Cmp(shortfall) < 0 {
+		return nil, nil
+	}
+	return shortfall, nil
+}
+
+// buildUnwrap 构建 withdraw(amount) 交易
+func (s *PayoutService) buildUnwrap(chainID uint64, wrapped common.Address, amount *big.Int, nonceVal uint64, fees *gas.Fees, gasLimit uint64) (*types.Transaction, error) {
+	data, err := s.wrappedABI.Pack("withdraw", amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack withdraw data: %w", err)
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonceVal,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       gasLimit,
+		To:        &wrapped,
+		Value:     big.NewInt(0),
+		Data:      data,
+	}), nil
+}
+
+// unwrapForNative 链开启 UnwrapNative 且原生余额不足以支付转账金额和网络费时，
+// 先以 nonceVal 广播解包交易补齐差额。返回已广播的解包交易 (无需解包时为 nil)，
+// 调用方的转账交易应使用下一个 nonce。解包交易记录在任务状态上并单独监控和记账。
+func (s *PayoutService) unwrapForNative(ctx context.Context, job *queue.Job, nonceVal uint64) (*types.Transaction, error) {
+	chainCfg := s.chainConfig(job.ChainID)
+	if !chainCfg.UnwrapNative || chainCfg.WrappedNative == "" {
+		return nil, nil
+	}
+	client, ok := s.evmClient(job.ChainID)
+	if !ok {
+		return nil, nil
+	}
+	value, ok := new(big.Int).SetString(job.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", job.Amount)
+	}
+
+	fees, err := s.suggestFees(ctx, job.ChainID, job.Priority)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit := calculateGasBuffer(unwrapGas, job.Priority)
+	unwrapFee := new(big.Int).Mul(fees.FeeCap, new(big.Int).SetUint64(gasLimit))
+	transferFee := new(big.Int).Mul(fees.FeeCap, new(big.Int).SetUint64(calculateGasBuffer(nativeTransferGas, job.Priority)))
+	need := new(big.Int).Add(value, transferFee)
+
+	from := common.HexToAddress(job.FromAddress)
+	wrapped := common.HexToAddress(chainCfg.WrappedNative)
+	amount, err := s.unwrapShortfall(ctx, client, wrapped, from, need, unwrapFee)
+	if err != nil || amount == nil {
+		return nil, err
+	}
+
+	tx, err := s.buildUnwrap(job.ChainID, wrapped, amount, nonceVal, fees, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := s.signTransaction(ctx, tx, job.ChainID, jobSigningOp(job, signPurposeUnwrap))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign unwrap: %w", err)
+	}
+	if err := s.broadcastTransaction(ctx, client, job.ChainID, signedTx); err != nil {
+		return nil, fmt.Errorf("failed to send unwrap: %w", err)
+	}
+
+	txHash := signedTx.Hash().Hex()
+	log.Info().
+		Str("job_id", job.ID).
+		Str("tx_hash", txHash).
+		Str("amount", amount.String()).
+		Str("wrapped", chainCfg.WrappedNative).
+		Msg("Unwrapped native token for payout")
+
+	s.trackUnwrapTx(ctx, job, signedTx)
+	if job.UserID != "" {
+		ref := queue.BatchRef{UserID: job.UserID, BatchID: job.BatchID}
+		if err := s.queue.RecordUnwrap(ctx, ref, job.ID, txHash, ""); err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record unwrap tx")
+		}
+	}
+	return signedTx, nil
+}
+
+// wrappedNativeBalance 链开启 UnwrapNative 时付款地址可解包的包装代币余额，否则为 0
+func (s *PayoutService) wrappedNativeBalance(ctx context.Context, chainID uint64, address string) (*big.Int, error) {
+	chainCfg := s.chainConfig(chainID)
+	if !chainCfg.UnwrapNative || chainCfg.WrappedNative == "" {
+		return big.NewInt(0), nil
+	}
+	client, ok := s.evmClient(chainID)
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return s.erc20BalanceOf(ctx, client, common.HexToAddress(chainCfg.WrappedNative), common.HexToAddress(address))
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/correlation"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// webhookBatchSize 每轮最多投递的事件数
+const webhookBatchSize = 100
+
+// RunWebhookDispatcher 投递批次状态回调事件，失败时按 queue.WebhookRetryPolicy 退避重试
+func (s *PayoutService) RunWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	log.Info().Dur("interval", interval).Msg("Webhook dispatcher started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatchWebhooks(ctx)
+		}
+	}
+}
+
+// dispatchWebhooks 投递一轮到期事件
+func (s *PayoutService) dispatchWebhooks(ctx context.Context) {
+	deliveries, err := s.queue.ClaimDueWebhooks(ctx, time.Now(), webhookBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim webhook events")
+		return
+	}
+
+	for _, d := range deliveries {
+		event := d.Event
+		target, err := s.queue.WebhookFor(ctx, event.UserID, event.BatchID)
+		if err != nil {
+			log.Warn().Err(err).Str("event_id", event.ID).Msg("Failed to load webhook registration")
+			continue // 租期结束后重试
+		}
+		if target == nil {
+			// 注册已过期
+			s.queue.AckWebhook(ctx, event.ID)
+			continue
+		}
+
+		body, err := queue.EncodeWebhookEvent(event, target.SchemaVersion)
+		if err != nil {
+			s.queue.AckWebhook(ctx, event.ID)
+			continue
+		}
+		// 不符合 schema 的 payload 不发出: 按投递失败退避重试，修复上线后仍可送达
+		if err := s.eventSchemas.Validate(event.Type, target.SchemaVersion, body); err != nil {
+			log.Error().Err(err).
+				Str("event_id", event.ID).
+				Str("type", event.Type).
+				Int("schema_version", target.SchemaVersion).
+				Msg("Webhook event failed schema validation")
+			if _, retryErr := s.queue.RetryWebhook(ctx, d, err); retryErr != nil {
+				log.Error().Err(retryErr).Str("event_id", event.ID).Msg("Failed to reschedule webhook event")
+			}
+			continue
+		}
+		if err := s.webhookSender.Post(correlation.WithID(ctx, event.CorrelationID), target.URL, target.Secret, event.ID, body); err != nil {
+			retrying, retryErr := s.queue.RetryWebhook(ctx, d, err)
+			logEvent := log.Warn()
+			if !retrying {
+				logEvent = log.Error()
+			}
+			logEvent.Err(err).
+				Str("event_id", event.ID).
+				Str("type", event.Type).
+				Str("batch_id", event.BatchID).
+				Str(correlation.LogField, event.CorrelationID).
+				Int("attempts", d.Attempts).
+				Bool("retrying", retrying).
+				Msg("Webhook delivery failed")
+			if retryErr != nil {
+				log.Error().Err(retryErr).Str("event_id", event.ID).Msg("Failed to reschedule webhook event")
+			}
+			continue
+		}
+
+		if err := s.queue.AckWebhook(ctx, event.ID); err != nil {
+			log.Warn().Err(err).Str("event_id", event.ID).Msg("Failed to ack webhook event")
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocol-bank/payout-engine/internal/gas"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/rs/zerolog/log"
+)
+
+// x402UserID x402 中继任务的用户 (任务状态和账本按该用户记录)
+const x402UserID = "x402"
+
+// authorizationABI EIP-3009 中继所需的方法
+const authorizationABI = `[{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"validAfter","type":"uint256"},{"name":"validBefore","type":"uint256"},{"name":"nonce","type":"bytes32"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"name":"transferWithAuthorization","outputs":[],"type":"function"},{"constant":true,"inputs":[{"name":"authorizer","type":"address"},{"name":"nonce","type":"bytes32"}],"name":"authorizationState","outputs":[{"name":"","type":"bool"}],"type":"function"},{"constant":true,"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"type":"function"}]`
+
+// transferWithAuthorizationTypeHash EIP-3009 TransferWithAuthorization 结构的类型哈希
+var transferWithAuthorizationTypeHash = crypto.Keccak256Hash([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
+
+// 授权校验失败 (其余错误为 RPC 不可用)
+var (
+	errAuthorizationUnsupported = errors.New("token does not support EIP-3009 transferWithAuthorization")
+	errAuthorizationSignature   = errors.New("authorization signature does not match")
+	errAuthorizationUsed        = errors.New("authorization nonce has already been used on-chain")
+)
+
+// RelayAuthorizationRequest x402 中继请求: 付款方签名的 EIP-3009 授权
+type RelayAuthorizationRequest struct {
+	ChainID     uint64
+	Token       string
+	From        string
+	To          string
+	Value       string
+	ValidAfter  int64
+	ValidBefore int64
+	Nonce       string // 0x 前缀 32 字节
+	Signature   string // 0x 前缀 65 字节 (r, s, v)
+}
+
+// RelayAuthorizationResult 中继任务。同一授权重复提交时返回已有任务 (Replayed)。
+type RelayAuthorizationResult struct {
+	Job      *queue.JobStatus
+	Replayed bool
+}
+
+// parsedAuthorization 解码后的授权参数
+type parsedAuthorization struct {
+	from, to                       common.Address
+	value, validAfter, validBefore *big.Int
+	nonce                          [32]byte
+	v                              uint8
+	r, s                           [32]byte
+}
+
+// parseAuthorization 校验并解码授权 (签名 v 为 27/28，兼容 0/1)
+func parseAuthorization(token, to, value string, auth *queue.Authorization) (*parsedAuthorization, error) {
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("invalid token address: %s", token)
+	}
+	if !common.IsHexAddress(auth.From) {
+		return nil, fmt.Errorf("invalid from address: %s", auth.From)
+	}
+	if !common.IsHexAddress(to) || common.HexToAddress(to) == (common.Address{}) {
+		return nil, fmt.Errorf("invalid to address: %s", to)
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid value: %s", value)
+	}
+	if auth.ValidAfter < 0 || auth.ValidBefore <= auth.ValidAfter {
+		return nil, fmt.Errorf("invalid validity window: validAfter %d, validBefore %d", auth.ValidAfter, auth.ValidBefore)
+	}
+	nonce, err := hexutil.Decode(auth.Nonce)
+	if err != nil || len(nonce) != 32 {
+		return nil, fmt.Errorf("invalid nonce: must be 32 bytes hex")
+	}
+	sig, err := hexutil.Decode(auth.Signature)
+	if err != nil || len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature: must be 65 bytes hex")
+	}
+
+	out := &parsedAuthorization{
+		from:        common.HexToAddress(auth.From),
+		to:          common.HexToAddress(to),
+		value:       amount,
+		validAfter:  big.NewInt(auth.ValidAfter),
+		validBefore: big.NewInt(auth.ValidBefore),
+		v:           sig[64],
+	}
+	copy(out.nonce[:], nonce)
+	copy(out.r[:], sig[:32])
+	copy(out.s[:], sig[32:64])
+	if out.v < 27 {
+		out.v += 27
+	}
+	if out.v != 27 && out.v != 28 {
+		return nil, fmt.Errorf("invalid signature v: %d", sig[64])
+	}
+	return out, nil
+}
+
+// authorizationDigest 付款方签名的 EIP-712 摘要
+func authorizationDigest(domainSeparator common.Hash, p *parsedAuthorization) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		transferWithAuthorizationTypeHash.Bytes(),
+		common.LeftPadBytes(p.from.Bytes(), 32),
+		common.LeftPadBytes(p.to.Bytes(), 32),
+		common.BigToHash(p.value).Bytes(),
+		common.BigToHash(p.validAfter).Bytes(),
+		common.BigToHash(p.validBefore).Bytes(),
+		p.nonce[:],
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator.Bytes(), structHash.Bytes())
+}
+
+// verifyAuthorizationSignature 按代币的 EIP-712 domain 核对授权由 from 签出
+func (s *PayoutService) verifyAuthorizationSignature(ctx context.Context, client ethCaller, token common.Address, p *parsedAuthorization) error {
+	domain, err := callTokenView(ctx, client, s.eip3009ABI, token, errAuthorizationUnsupported, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return err
+	}
+	sig := make([]byte, 65)
+	copy(sig[:32], p.r[:])
+	copy(sig[32:64], p.s[:])
+	sig[64] = p.v - 27
+	pub, err := crypto.SigToPub(authorizationDigest(common.Hash(domain.([32]byte)), p).Bytes(), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != p.from {
+		return fmt.Errorf("%w: not signed by %s", errAuthorizationSignature, p.from.Hex())
+	}
+	return nil
+}
+
+// authorizationUsed 授权 nonce 是否已在链上使用或取消
+func (s *PayoutService) authorizationUsed(ctx context.Context, client ethCaller, token common.Address, p *parsedAuthorization) (bool, error) {
+	used, err := callTokenView(ctx, client, s.eip3009ABI, token, errAuthorizationUnsupported, "authorizationState", p.from, p.nonce)
+	if err != nil {
+		return false, err
+	}
+	return used.(bool), nil
+}
+
+// x402JobID 由 (链, 代币, 授权方, nonce) 确定的任务 ID
+func x402JobID(chainID uint64, token common.Address, p *parsedAuthorization) string {
+	h := crypto.Keccak256(new(big.Int).SetUint64(chainID).Bytes(), token.Bytes(), p.from.Bytes(), p.nonce[:])
+	return fmt.Sprintf("x402-%d-%x", chainID, h[:12])
+}
+
+// RelayAuthorization 校验付款方签名的 EIP-3009 授权并作为任务入队，由付款地址代付 Gas 执行
+// transferWithAuthorization。授权 nonce 在有效期内只中继一次。
+func (s *PayoutService) RelayAuthorization(ctx context.Context, req *RelayAuthorizationRequest) (*RelayAuthorizationResult, error) {
+	if !s.cfg.X402Relayer {
+		return nil, &FailedPreconditionError{Err: fmt.Errorf("x402 relayer is disabled")}
+	}
+	client, ok := s.evmClient(req.ChainID)
+	if !ok {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("x402 relaying is not supported on chain %d", req.ChainID)}
+	}
+	auth := &queue.Authorization{
+		From:        req.From,
+		ValidAfter:  req.ValidAfter,
+		ValidBefore: req.ValidBefore,
+		Nonce:       req.Nonce,
+		Signature:   req.Signature,
+	}
+	p, err := parseAuthorization(req.Token, req.To, req.Value, auth)
+	if err != nil {
+		return nil, &InvalidArgumentError{Err: err}
+	}
+	now := time.Now()
+	if now.Unix() >= req.ValidBefore {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("authorization expired at %d", req.ValidBefore)}
+	}
+	if now.Unix() <= req.ValidAfter {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("authorization is not valid until %d", req.ValidAfter)}
+	}
+	if err := s.checkAllowlist(x402UserID, req.ChainID, req.Token); err != nil {
+		return nil, &InvalidArgumentError{Err: err}
+	}
+
+	token := common.HexToAddress(req.Token)
+	if err := s.verifyAuthorizationSignature(ctx, client, token, p); err != nil {
+		return nil, authorizationError(err)
+	}
+
+	// 同一授权只入队一次 (重复提交返回已有任务)
+	jobID := x402JobID(req.ChainID, token, p)
+	nonceHex := hexutil.Encode(p.nonce[:])
+	ttl := time.Until(time.Unix(req.ValidBefore, 0)) + time.Minute
+	claimed, existing, err := s.queue.ClaimAuthorizationNonce(ctx, req.ChainID, token.Hex(), p.from.Hex(), nonceHex, jobID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		job, err := s.AuthorizationStatus(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+		return &RelayAuthorizationResult{Job: job, Replayed: true}, nil
+	}
+
+	job, err := s.x402Job(ctx, client, req.ChainID, token, jobID, auth, p)
+	if err == nil {
+		err = s.queue.Push(ctx, job)
+	}
+	if err != nil {
+		if rerr := s.queue.ReleaseAuthorizationNonce(ctx, req.ChainID, token.Hex(), p.from.Hex(), nonceHex); rerr != nil {
+			log.Warn().Err(rerr).Str("job_id", jobID).Msg("Failed to release authorization nonce")
+		}
+		return nil, err
+	}
+
+	log.Info().
+		Uint64("chain_id", req.ChainID).
+		Str("job_id", jobID).
+		Str("token", job.TokenSymbol).
+		Str("from", p.from.Hex()).
+		Str("to", p.to.Hex()).
+		Str("value", p.value.String()).
+		Msg("x402 authorization queued for relay")
+
+	status, err := s.AuthorizationStatus(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayAuthorizationResult{Job: status}, nil
+}
+
+// x402Job 构建中继任务 (链上 nonce 已使用时拒绝)
+func (s *PayoutService) x402Job(ctx context.Context, client ethCaller, chainID uint64, token common.Address, jobID string, auth *queue.Authorization, p *parsedAuthorization) (*queue.Job, error) {
+	used, err := s.authorizationUsed(ctx, client, token, p)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if used {
+		return nil, &FailedPreconditionError{Err: errAuthorizationUsed}
+	}
+	meta, err := s.resolveTokenMetadata(ctx, chainID, token.Hex())
+	if errors.Is(err, errNotTokenContract) {
+		return nil, &InvalidArgumentError{Err: fmt.Errorf("token %s: %w", token.Hex(), err)}
+	}
+	if err != nil {
+		return nil, &UnavailableError{Err: fmt.Errorf("failed to read metadata of token %s: %w", token.Hex(), err)}
+	}
+	relayer, err := s.payoutAddress(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return &queue.Job{
+		ID:            jobID,
+		BatchID:       jobID,
+		UserID:        x402UserID,
+		FromAddress:   relayer,
+		ToAddress:     p.to.Hex(),
+		Amount:        p.value.String(),
+		TokenAddress:  token.Hex(),
+		TokenSymbol:   meta.Symbol,
+		TokenDecimals: meta.Decimals,
+		ChainID:       chainID,
+		Testnet:       s.isTestnetChain(chainID),
+		Priority:      string(gas.PriorityHigh), // x402 付款方在等待资源放行
+		CreatedAt:     time.Now(),
+		Authorization: auth,
+	}, nil
+}
+
+// authorizationError 授权校验失败为调用方错误，其余为 RPC 不可用
+func authorizationError(err error) error {
+	if errors.Is(err, errAuthorizationUnsupported) || errors.Is(err, errAuthorizationSignature) {
+		return &InvalidArgumentError{Err: err}
+	}
+	return &UnavailableError{Err: fmt.Errorf("failed to verify authorization: %w", err)}
+}
+
+// AuthorizationStatus 中继任务的状态和交易哈希
+func (s *PayoutService) AuthorizationStatus(ctx context.Context, jobID string) (*queue.JobStatus, error) {
+	job, err := s.queue.GetJobStatus(ctx, queue.BatchRef{UserID: x402UserID, BatchID: jobID}, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// checkAuthorizationExecutable 发送前确认授权仍可执行 (已使用或已过期时永久失败)
+func (s *PayoutService) checkAuthorizationExecutable(ctx context.Context, client ethCaller, job *queue.Job) error {
+	p, err := parseAuthorization(job.TokenAddress, job.ToAddress, job.Amount, job.Authorization)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	if time.Now().Unix() >= job.Authorization.ValidBefore {
+		return queue.Permanent(fmt.Errorf("authorization expired at %d", job.Authorization.ValidBefore))
+	}
+	used, err := s.authorizationUsed(ctx, client, common.HexToAddress(job.TokenAddress), p)
+	if err != nil {
+		return fmt.Errorf("failed to read authorization state: %w", err)
+	}
+	if used {
+		return queue.Permanent(errAuthorizationUsed)
+	}
+	return nil
+}
+
+// packAuthorization 编码 transferWithAuthorization 调用数据
+func (s *PayoutService) packAuthorization(job *queue.Job) ([]byte, error) {
+	p, err := parseAuthorization(job.TokenAddress, job.ToAddress, job.Amount, job.Authorization)
+	if err != nil {
+		return nil, err
+	}
+	return s.eip3009ABI.Pack("transferWithAuthorization", p.from, p.to, p.value, p.validAfter, p.validBefore, p.nonce, p.v, p.r, p.s)
+}
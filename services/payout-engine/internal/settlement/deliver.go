@@ -0,0 +1,129 @@
+package settlement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/webhook"
+)
+
+// SMTPConfig 邮件投递配置 (Host 为空时不发送邮件)
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Sender 投递汇总到 webhook 和邮件
+type Sender struct {
+	webhook *webhook.Sender
+	smtp    SMTPConfig
+}
+
+// NewSender 创建投递器
+func NewSender(smtpCfg SMTPConfig) *Sender {
+	return &Sender{webhook: webhook.NewSender(15 * time.Second), smtp: smtpCfg}
+}
+
+// EmailEnabled 是否配置了邮件服务器
+func (s *Sender) EmailEnabled() bool {
+	return s.smtp.Host != ""
+}
+
+// SendWebhook 以签名 JSON POST 汇总
+func (s *Sender) SendWebhook(ctx context.Context, dest Destination, summary *Summary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	if err := s.webhook.Post(ctx, dest.WebhookURL, dest.Secret, summary.EventID(), body); err != nil {
+		return fmt.Errorf("settlement %w", err)
+	}
+	return nil
+}
+
+// SendEmail 发送汇总邮件: 正文为可读摘要，附带签名 JSON 便于核对
+func (s *Sender) SendEmail(dest Destination, summary *Summary) error {
+	if !s.EmailEnabled() || len(dest.Email) == 0 {
+		return nil
+	}
+	body, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(dest.Email, ", "))
+	fmt.Fprintf(&msg, "Subject: Payout settlement summary %s (%s)\r\n", summary.Day(), summary.Tenant)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprintf(&msg, "%s: %s\r\n", webhook.HeaderEventID, summary.EventID())
+	fmt.Fprintf(&msg, "\r\n")
+	msg.WriteString(Render(summary))
+	fmt.Fprintf(&msg, "\r\n--- signed payload ---\r\n")
+	fmt.Fprintf(&msg, "timestamp: %d\r\nsignature: sha256=%s\r\n\r\n", ts, webhook.Sign(dest.Secret, ts, body))
+	msg.Write(body)
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.smtp.Username != "" {
+		auth = smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, s.smtp.Host)
+	}
+	addr := net.JoinHostPort(s.smtp.Host, strconv.Itoa(s.smtp.Port))
+	if err := smtp.SendMail(addr, auth, s.smtp.From, dest.Email, msg.Bytes()); err != nil {
+		return fmt.Errorf("settlement email failed: %w", err)
+	}
+	return nil
+}
+
+// Render 汇总的纯文本摘要
+func Render(s *Summary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tenant: %s\r\n", s.Tenant)
+	fmt.Fprintf(&b, "Period: %s - %s (UTC)\r\n\r\n", s.PeriodStart.UTC().Format(time.RFC3339), s.PeriodEnd.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Batches: %d (completed %d, partially failed %d, failed %d, cancelled %d)\r\n",
+		s.Batches.Total, s.Batches.Completed, s.Batches.PartialFailed, s.Batches.Failed, s.Batches.Cancelled)
+	fmt.Fprintf(&b, "Payouts: %d (confirmed %d, failed %d, cancelled %d)\r\n\r\n",
+		s.Jobs.Total, s.Jobs.Confirmed, s.Jobs.Failed, s.Jobs.Cancelled)
+
+	if len(s.Totals) > 0 {
+		b.WriteString("Totals (smallest unit):\r\n")
+		for _, t := range s.Totals {
+			symbol := t.Symbol
+			if symbol == "" {
+				symbol = t.Token
+			}
+			fmt.Fprintf(&b, "  chain %d  %-10s %s (%d payouts)\r\n", t.ChainID, symbol, t.Amount, t.Count)
+		}
+		b.WriteString("\r\n")
+	}
+	if len(s.GasSpend) > 0 {
+		b.WriteString("Gas spend (smallest unit):\r\n")
+		for _, g := range s.GasSpend {
+			fmt.Fprintf(&b, "  chain %d  %-10s %s (%d txs)\r\n", g.ChainID, g.NativeToken, g.Amount, g.Count)
+		}
+		b.WriteString("\r\n")
+	}
+	if len(s.Failures) > 0 {
+		fmt.Fprintf(&b, "Failures (%d listed", len(s.Failures))
+		if s.FailuresTruncated {
+			fmt.Fprintf(&b, ", truncated")
+		}
+		b.WriteString("):\r\n")
+		for _, f := range s.Failures {
+			fmt.Fprintf(&b, "  %s/%s chain %d to %s: %s\r\n", f.BatchID, f.JobID, f.ChainID, f.ToAddress, f.Error)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package settlement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultTenant applies to tenants without their own destination.
+const DefaultTenant = "*"
+
+// MaxFailures 单份汇总最多列出的失败明细
+const MaxFailures = 100
+
+// Summary 租户在一个结算周期内已结束批次的汇总
+type Summary struct {
+	Tenant      string    `json:"tenant"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Batches BatchCounts `json:"batches"`
+	Jobs    JobCounts   `json:"jobs"`
+
+	Totals   []TokenTotal `json:"totals"`    // 已确认支付金额 (按链和代币)
+	GasSpend []GasTotal   `json:"gas_spend"` // 已上链交易的实际网络费 (按链)
+
+	Failures          []Failure `json:"failures"`
+	FailuresTruncated bool      `json:"failures_truncated,omitempty"`
+}
+
+// BatchCounts 按最终状态统计的批次数
+type BatchCounts struct {
+	Total         int `json:"total"`
+	Completed     int `json:"completed"`
+	PartialFailed int `json:"partial_failed"`
+	Failed        int `json:"failed"`
+	Cancelled     int `json:"cancelled"`
+}
+
+// JobCounts 按最终状态统计的任务数
+type JobCounts struct {
+	Total     int `json:"total"`
+	Confirmed int `json:"confirmed"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// TokenTotal 某条链上某种代币的支付总额 (最小单位)
+type TokenTotal struct {
+	ChainID uint64 `json:"chain_id"`
+	Token   string `json:"token"` // 原生代币为 ""
+	Symbol  string `json:"symbol,omitempty"`
+	Amount  string `json:"amount"`
+	Count   int    `json:"count"`
+}
+
+// GasTotal 某条链上的网络费合计 (原生代币最小单位)。
+// 仅包含已取得回执的 EVM 交易，TRON 和智能账户支付不计入。
+type GasTotal struct {
+	ChainID     uint64 `json:"chain_id"`
+	NativeToken string `json:"native_token"`
+	Amount      string `json:"amount"`
+	Count       int    `json:"count"`
+}
+
+// Failure 失败任务明细
+type Failure struct {
+	BatchID   string `json:"batch_id"`
+	JobID     string `json:"job_id"`
+	ChainID   uint64 `json:"chain_id"`
+	ToAddress string `json:"to_address"`
+	Amount    string `json:"amount"`
+	Token     string `json:"token"`
+	Error     string `json:"error"`
+}
+
+// Day 周期标识 (UTC 日期)，用于去重
+func (s *Summary) Day() string {
+	return s.PeriodStart.UTC().Format("2006-01-02")
+}
+
+// EventID 汇总的唯一标识，接收方可用于幂等处理
+func (s *Summary) EventID() string {
+	return "settlement:" + s.Tenant + ":" + s.Day()
+}
+
+// Destination 租户汇总的投递目标
+type Destination struct {
+	WebhookURL string   `json:"webhook_url"`
+	Secret     string   `json:"secret"` // webhook 和邮件签名密钥
+	Email      []string `json:"email,omitempty"`
+}
+
+// Destinations 每个租户 (UserID) 的投递目标
+type Destinations struct {
+	tenants map[string]Destination
+}
+
+// file is the on-disk JSON format:
+//
+//	{"tenants": {"*": {"webhook_url": "https://ops.example.com/hooks/payout", "secret": "..."}, "user-123": {"webhook_url": "...", "secret": "...", "email": ["finance@example.com"]}}}
+type file struct {
+	Tenants map[string]Destination `json:"tenants"`
+}
+
+// Load reads a destinations file. An empty path disables summaries.
+func Load(path string) (*Destinations, error) {
+	if path == "" {
+		return &Destinations{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settlement destinations: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse builds destinations from JSON.
+func Parse(data []byte) (*Destinations, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid settlement destinations: %w", err)
+	}
+	for tenant, dest := range f.Tenants {
+		if dest.WebhookURL == "" && len(dest.Email) == 0 {
+			return nil, fmt.Errorf("tenant %s: webhook_url or email is required", tenant)
+		}
+		if dest.Secret == "" {
+			return nil, fmt.Errorf("tenant %s: secret is required", tenant)
+		}
+	}
+	return &Destinations{tenants: f.Tenants}, nil
+}
+
+// Enabled reports whether any destination is configured.
+func (d *Destinations) Enabled() bool {
+	return d != nil && len(d.tenants) > 0
+}
+
+// For returns the destination of a tenant, falling back to the default one.
+func (d *Destinations) For(tenant string) (Destination, bool) {
+	if !d.Enabled() {
+		return Destination{}, false
+	}
+	if dest, ok := d.tenants[tenant]; ok {
+		return dest, true
+	}
+	dest, ok := d.tenants[DefaultTenant]
+	return dest, ok
+}
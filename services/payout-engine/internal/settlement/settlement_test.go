@@ -0,0 +1,75 @@
+package settlement
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/payout-engine/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestinations(t *testing.T) {
+	d, err := Parse([]byte(`{"tenants": {
+		"*": {"webhook_url": "https://ops.example.com/hook", "secret": "s0"},
+		"user-1": {"email": ["finance@example.com"], "secret": "s1"}
+	}}`))
+	require.NoError(t, err)
+	assert.True(t, d.Enabled())
+
+	dest, ok := d.For("user-1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"finance@example.com"}, dest.Email)
+	assert.Empty(t, dest.WebhookURL)
+
+	dest, ok = d.For("user-2")
+	require.True(t, ok, "falls back to default")
+	assert.Equal(t, "s0", dest.Secret)
+
+	_, err = Parse([]byte(`{"tenants": {"user-1": {"webhook_url": "https://x"}}}`))
+	assert.Error(t, err, "secret is required")
+
+	empty, err := Load("")
+	require.NoError(t, err)
+	_, ok = empty.For("user-1")
+	assert.False(t, ok)
+}
+
+func TestSendWebhookSigned(t *testing.T) {
+	summary := &Summary{
+		Tenant:      "user-1",
+		PeriodStart: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Batches:     BatchCounts{Total: 1, Completed: 1},
+		Totals:      []TokenTotal{{ChainID: 1, Symbol: "ETH", Amount: "100", Count: 1}},
+	}
+
+	var got Summary
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256="+webhook.Sign("secret", ts, body), r.Header.Get(webhook.HeaderSignature))
+		assert.Equal(t, "settlement:user-1:2026-10-17", r.Header.Get(webhook.HeaderEventID))
+		require.NoError(t, json.Unmarshal(body, &got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender := NewSender(SMTPConfig{})
+	require.NoError(t, sender.SendWebhook(context.Background(), Destination{WebhookURL: srv.URL, Secret: "secret"}, summary))
+	assert.Equal(t, "100", got.Totals[0].Amount)
+	assert.False(t, sender.EmailEnabled())
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	assert.Error(t, sender.SendWebhook(context.Background(), Destination{WebhookURL: failing.URL, Secret: "secret"}, summary))
+}
@@ -0,0 +1,250 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	default0xURL    = "https://api.0x.org"
+	default1inchURL = "https://api.1inch.dev"
+)
+
+// ZeroEx quotes swaps with the 0x Swap API (v2, AllowanceHolder).
+type ZeroEx struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewZeroEx creates a 0x aggregator client.
+func NewZeroEx(apiKey, baseURL string) *ZeroEx {
+	if baseURL == "" {
+		baseURL = default0xURL
+	}
+	return &ZeroEx{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Provider implements Aggregator.
+func (z *ZeroEx) Provider() string { return Provider0x }
+
+// Price implements Aggregator.
+func (z *ZeroEx) Price(ctx context.Context, chainID uint64, sellToken, buyToken string, sellAmount *big.Int) (*big.Int, error) {
+	q := url.Values{}
+	q.Set("chainId", strconv.FormatUint(chainID, 10))
+	q.Set("sellToken", sellToken)
+	q.Set("buyToken", buyToken)
+	q.Set("sellAmount", sellAmount.String())
+
+	var resp struct {
+		LiquidityAvailable bool   `json:"liquidityAvailable"`
+		BuyAmount          string `json:"buyAmount"`
+	}
+	if err := z.get(ctx, "/swap/allowance-holder/price", q, &resp); err != nil {
+		return nil, fmt.Errorf("0x price failed: %w", err)
+	}
+	if !resp.LiquidityAvailable {
+		return nil, fmt.Errorf("0x has no liquidity from %s to %s", sellToken, buyToken)
+	}
+	return parseAmount("buyAmount", resp.BuyAmount)
+}
+
+// Quote implements Aggregator.
+func (z *ZeroEx) Quote(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	q := url.Values{}
+	q.Set("chainId", strconv.FormatUint(req.ChainID, 10))
+	q.Set("sellToken", req.SellToken)
+	q.Set("buyToken", req.BuyToken)
+	q.Set("sellAmount", req.SellAmount.String())
+	q.Set("taker", req.Taker)
+	q.Set("slippageBps", strconv.Itoa(req.SlippageBps))
+
+	var resp struct {
+		LiquidityAvailable bool   `json:"liquidityAvailable"`
+		SellAmount         string `json:"sellAmount"`
+		BuyAmount          string `json:"buyAmount"`
+		MinBuyAmount       string `json:"minBuyAmount"`
+		Transaction        struct {
+			To    string `json:"to"`
+			Data  string `json:"data"`
+			Gas   string `json:"gas"`
+			Value string `json:"value"`
+		} `json:"transaction"`
+		Issues struct {
+			Allowance *struct {
+				Spender string `json:"spender"`
+			} `json:"allowance"`
+		} `json:"issues"`
+	}
+	if err := z.get(ctx, "/swap/allowance-holder/quote", q, &resp); err != nil {
+		return nil, fmt.Errorf("0x quote failed: %w", err)
+	}
+	if !resp.LiquidityAvailable {
+		return nil, fmt.Errorf("0x has no liquidity from %s to %s", req.SellToken, req.BuyToken)
+	}
+
+	quote := &Quote{To: resp.Transaction.To, AllowanceTarget: resp.Transaction.To}
+	if resp.Issues.Allowance != nil && resp.Issues.Allowance.Spender != "" {
+		quote.AllowanceTarget = resp.Issues.Allowance.Spender
+	}
+	var err error
+	if quote.SellAmount, err = parseAmount("sellAmount", resp.SellAmount); err != nil {
+		return nil, err
+	}
+	if quote.BuyAmount, err = parseAmount("buyAmount", resp.BuyAmount); err != nil {
+		return nil, err
+	}
+	if quote.MinBuyAmount, err = parseAmount("minBuyAmount", resp.MinBuyAmount); err != nil {
+		return nil, err
+	}
+	if quote.Data, err = hexutil.Decode(resp.Transaction.Data); err != nil {
+		return nil, fmt.Errorf("invalid 0x transaction data: %w", err)
+	}
+	if quote.Value, err = parseAmount("value", orZero(resp.Transaction.Value)); err != nil {
+		return nil, err
+	}
+	quote.Gas, _ = strconv.ParseUint(resp.Transaction.Gas, 10, 64)
+	return quote, nil
+}
+
+func (z *ZeroEx) get(ctx context.Context, path string, q url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, z.baseURL+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("0x-api-key", z.apiKey)
+	req.Header.Set("0x-version", "v2")
+	req.Header.Set("Accept", "application/json")
+	return do(z.httpClient, req, out)
+}
+
+// OneInch quotes swaps with the 1inch Swap API (v6).
+type OneInch struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOneInch creates a 1inch aggregator client.
+func NewOneInch(apiKey, baseURL string) *OneInch {
+	if baseURL == "" {
+		baseURL = default1inchURL
+	}
+	return &OneInch{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Provider implements Aggregator.
+func (o *OneInch) Provider() string { return Provider1inch }
+
+// Price implements Aggregator.
+func (o *OneInch) Price(ctx context.Context, chainID uint64, sellToken, buyToken string, sellAmount *big.Int) (*big.Int, error) {
+	q := url.Values{}
+	q.Set("src", sellToken)
+	q.Set("dst", buyToken)
+	q.Set("amount", sellAmount.String())
+
+	var resp struct {
+		DstAmount string `json:"dstAmount"`
+	}
+	if err := o.get(ctx, chainID, "/quote", q, &resp); err != nil {
+		return nil, fmt.Errorf("1inch quote failed: %w", err)
+	}
+	return parseAmount("dstAmount", resp.DstAmount)
+}
+
+// Quote implements Aggregator.
+// 1inch takes slippage in percent and returns the expected output only, so
+// the minimum output is derived from the slippage.
+func (o *OneInch) Quote(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	q := url.Values{}
+	q.Set("src", req.SellToken)
+	q.Set("dst", req.BuyToken)
+	q.Set("amount", req.SellAmount.String())
+	q.Set("from", req.Taker)
+	q.Set("origin", req.Taker)
+	q.Set("slippage", strconv.FormatFloat(float64(req.SlippageBps)/100, 'f', -1, 64))
+	q.Set("disableEstimate", "true")
+
+	var resp struct {
+		DstAmount string `json:"dstAmount"`
+		Tx        struct {
+			To    string      `json:"to"`
+			Data  string      `json:"data"`
+			Value string      `json:"value"`
+			Gas   json.Number `json:"gas"`
+		} `json:"tx"`
+	}
+	if err := o.get(ctx, req.ChainID, "/swap", q, &resp); err != nil {
+		return nil, fmt.Errorf("1inch swap failed: %w", err)
+	}
+
+	quote := &Quote{SellAmount: new(big.Int).Set(req.SellAmount), To: resp.Tx.To, AllowanceTarget: resp.Tx.To}
+	var err error
+	if quote.BuyAmount, err = parseAmount("dstAmount", resp.DstAmount); err != nil {
+		return nil, err
+	}
+	quote.MinBuyAmount = ApplyBps(quote.BuyAmount, 10000-req.SlippageBps)
+	if quote.Data, err = hexutil.Decode(resp.Tx.Data); err != nil {
+		return nil, fmt.Errorf("invalid 1inch transaction data: %w", err)
+	}
+	if quote.Value, err = parseAmount("value", orZero(resp.Tx.Value)); err != nil {
+		return nil, err
+	}
+	quote.Gas, _ = strconv.ParseUint(resp.Tx.Gas.String(), 10, 64)
+	return quote, nil
+}
+
+func (o *OneInch) get(ctx context.Context, chainID uint64, path string, q url.Values, out interface{}) error {
+	endpoint := fmt.Sprintf("%s/swap/v6.0/%d%s?%s", o.baseURL, chainID, path, q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	req.Header.Set("Accept", "application/json")
+	return do(o.httpClient, req, out)
+}
+
+// parseAmount parses a decimal integer amount from an API response.
+func parseAmount(field, s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s in swap response: %q", field, s)
+	}
+	return n, nil
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// do sends the request and decodes a JSON response.
+func do(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
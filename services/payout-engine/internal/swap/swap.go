@@ -0,0 +1,125 @@
+package swap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// Supported aggregators
+const (
+	Provider0x    = "0x"
+	Provider1inch = "1inch"
+)
+
+// DefaultMaxSlippageBps is the slippage cap used when none is configured (3%).
+const DefaultMaxSlippageBps = 300
+
+// priceBufferBps pads the reverse price estimate so the quoted output covers
+// the requested amount after slippage.
+const priceBufferBps = 50
+
+// Quote is an executable swap returned by an aggregator.
+type Quote struct {
+	SellAmount      *big.Int
+	BuyAmount       *big.Int // expected output
+	MinBuyAmount    *big.Int // guaranteed output after slippage
+	To              string   // contract to call
+	Data            []byte
+	Value           *big.Int
+	Gas             uint64 // aggregator gas estimate (0 if unknown)
+	AllowanceTarget string // spender the sell token must be approved to
+}
+
+// QuoteRequest asks for a swap selling an exact amount.
+type QuoteRequest struct {
+	ChainID     uint64
+	SellToken   string
+	BuyToken    string
+	SellAmount  *big.Int
+	Taker       string
+	SlippageBps int
+}
+
+// Aggregator quotes swaps through a DEX aggregator.
+type Aggregator interface {
+	// Price returns the expected output of selling sellAmount, without building a transaction.
+	Price(ctx context.Context, chainID uint64, sellToken, buyToken string, sellAmount *big.Int) (*big.Int, error)
+	// Quote returns an executable swap for req.
+	Quote(ctx context.Context, req QuoteRequest) (*Quote, error)
+	// Provider returns the provider name, used for logging.
+	Provider() string
+}
+
+// Config selects and configures the swap aggregator.
+type Config struct {
+	Provider       string // "" (disabled), "0x" or "1inch"
+	APIKey         string
+	BaseURL        string // Optional: aggregator API base URL
+	MaxSlippageBps int    // Upper bound for per-batch slippage (default 300)
+}
+
+// New creates the aggregator selected by cfg.Provider.
+// An empty provider disables swaps and returns nil.
+func New(cfg Config) (Aggregator, error) {
+	var a Aggregator
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case Provider0x:
+		a = NewZeroEx(cfg.APIKey, cfg.BaseURL)
+	case Provider1inch:
+		a = NewOneInch(cfg.APIKey, cfg.BaseURL)
+	default:
+		return nil, fmt.Errorf("unknown swap provider: %s", cfg.Provider)
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("swap api key is required for %s", cfg.Provider)
+	}
+	return a, nil
+}
+
+// InsufficientOutputError means the quote cannot guarantee the requested output.
+type InsufficientOutputError struct {
+	Want   *big.Int
+	MinOut *big.Int
+}
+
+func (e *InsufficientOutputError) Error() string {
+	return fmt.Sprintf("swap quote guarantees %s, below the required %s", e.MinOut, e.Want)
+}
+
+// QuoteExactOut quotes a swap whose minimum output covers buyAmount.
+// Aggregators quote by sell amount, so the sell amount is estimated from the
+// reverse price and padded by the slippage plus a small buffer.
+func QuoteExactOut(ctx context.Context, a Aggregator, chainID uint64, sellToken, buyToken string, buyAmount *big.Int, taker string, slippageBps int) (*Quote, error) {
+	estimate, err := a.Price(ctx, chainID, buyToken, sellToken, buyAmount)
+	if err != nil {
+		return nil, err
+	}
+	if estimate.Sign() <= 0 {
+		return nil, fmt.Errorf("no swap route from %s to %s", sellToken, buyToken)
+	}
+	sellAmount := ApplyBps(estimate, 10000+slippageBps+priceBufferBps)
+	q, err := a.Quote(ctx, QuoteRequest{
+		ChainID:     chainID,
+		SellToken:   sellToken,
+		BuyToken:    buyToken,
+		SellAmount:  sellAmount,
+		Taker:       taker,
+		SlippageBps: slippageBps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.MinBuyAmount.Cmp(buyAmount) < 0 {
+		return nil, &InsufficientOutputError{Want: buyAmount, MinOut: q.MinBuyAmount}
+	}
+	return q, nil
+}
+
+// ApplyBps returns amount × bps / 10000, rounded down.
+func ApplyBps(amount *big.Int, bps int) *big.Int {
+	out := new(big.Int).Mul(amount, big.NewInt(int64(bps)))
+	return out.Quo(out, big.NewInt(10000))
+}
@@ -0,0 +1,91 @@
+package swap
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	usdt = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+)
+
+func TestZeroExQuoteExactOut(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api-key", r.Header.Get("0x-api-key"))
+		assert.Equal(t, "v2", r.Header.Get("0x-version"))
+		q := r.URL.Query()
+		assert.Equal(t, "1", q.Get("chainId"))
+		switch r.URL.Path {
+		case "/swap/allowance-holder/price":
+			// 反向报价: 卖出 USDT 约得 1001 USDC
+			assert.Equal(t, usdt, q.Get("sellToken"))
+			w.Write([]byte(`{"liquidityAvailable":true,"buyAmount":"1001000000"}`))
+		case "/swap/allowance-holder/quote":
+			assert.Equal(t, usdc, q.Get("sellToken"))
+			assert.Equal(t, "100", q.Get("slippageBps"))
+			assert.Equal(t, "0x0000000000000000000000000000000000000009", q.Get("taker"))
+			// 1001 × (1 + 1% + 0.5%)
+			assert.Equal(t, "1016015000", q.Get("sellAmount"))
+			w.Write([]byte(`{"liquidityAvailable":true,"sellAmount":"1016015000","buyAmount":"1015000000","minBuyAmount":"1004850000",
+				"transaction":{"to":"0x0000000000001fF3684f28c67538d4D072C22734","data":"0xdeadbeef","gas":"210000","value":"0"},
+				"issues":{"allowance":{"spender":"0x0000000000001fF3684f28c67538d4D072C22734","actual":"0"}}}`))
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	agg, err := New(Config{Provider: Provider0x, APIKey: "api-key", BaseURL: srv.URL})
+	require.NoError(t, err)
+	q, err := QuoteExactOut(context.Background(), agg, 1, usdc, usdt, big.NewInt(1_000_000_000), "0x0000000000000000000000000000000000000009", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "1016015000", q.SellAmount.String())
+	assert.Equal(t, "1004850000", q.MinBuyAmount.String())
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, q.Data)
+	assert.Equal(t, uint64(210000), q.Gas)
+	assert.Equal(t, "0x0000000000001fF3684f28c67538d4D072C22734", q.AllowanceTarget)
+
+	// 滑点后的最少输出不足以覆盖支付金额
+	_, err = QuoteExactOut(context.Background(), agg, 1, usdc, usdt, big.NewInt(1_010_000_000), "0x0000000000000000000000000000000000000009", 100)
+	var insufficient *InsufficientOutputError
+	assert.ErrorAs(t, err, &insufficient)
+}
+
+func TestOneInchQuote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer api-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "/swap/v6.0/8453/swap", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "0.5", q.Get("slippage"))
+		assert.Equal(t, "500", q.Get("amount"))
+		w.Write([]byte(`{"dstAmount":"1000","tx":{"to":"0x111111125421cA6dc452d289314280a0f8842A65","data":"0x01","value":"0","gas":180000}}`))
+	}))
+	defer srv.Close()
+
+	q, err := NewOneInch("api-key", srv.URL).Quote(context.Background(), QuoteRequest{
+		ChainID: 8453, SellToken: usdc, BuyToken: usdt, SellAmount: big.NewInt(500), Taker: "0x9", SlippageBps: 50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "995", q.MinBuyAmount.String())
+	assert.Equal(t, "500", q.SellAmount.String())
+	assert.Equal(t, uint64(180000), q.Gas)
+	assert.Equal(t, "0x111111125421cA6dc452d289314280a0f8842A65", q.AllowanceTarget)
+}
+
+func TestNew(t *testing.T) {
+	agg, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, agg)
+
+	_, err = New(Config{Provider: Provider1inch})
+	assert.Error(t, err)
+	_, err = New(Config{Provider: "uniswap", APIKey: "k"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+// Package zksync encodes zkSync Era EIP-712 transactions (type 0x71). The
+// account signs the EIP-712 digest of the transaction instead of the RLP
+// signing hash used by standard Ethereum transactions, and the serialized
+// transaction carries zkSync-specific fields (gas per pubdata byte, factory
+// dependencies, paymaster).
+package zksync
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// TxType is the EIP-2718 type of zkSync EIP-712 transactions.
+const TxType = 0x71
+
+// DefaultGasPerPubdata is the gas per pubdata byte limit used by the zkSync SDKs.
+var DefaultGasPerPubdata = big.NewInt(50000)
+
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	txTypeHash     = crypto.Keccak256Hash([]byte("Transaction(uint256 txType,uint256 from,uint256 to,uint256 gasLimit," +
+		"uint256 gasPerPubdataByteLimit,uint256 maxFeePerGas,uint256 maxPriorityFeePerGas,uint256 paymaster," +
+		"uint256 nonce,uint256 value,bytes data,bytes32[] factoryDeps,bytes paymasterInput)"))
+	domainNameHash    = crypto.Keccak256Hash([]byte("zkSync"))
+	domainVersionHash = crypto.Keccak256Hash([]byte("2"))
+)
+
+// Transaction is an unsigned zkSync EIP-712 transaction. Contract deployment
+// (factory dependencies) and paymasters are not supported.
+type Transaction struct {
+	ChainID       *big.Int
+	Nonce         uint64
+	GasTipCap     *big.Int
+	GasFeeCap     *big.Int
+	Gas           uint64
+	From          common.Address
+	To            common.Address
+	Value         *big.Int
+	Data          []byte
+	GasPerPubdata *big.Int // nil uses DefaultGasPerPubdata
+}
+
+// rlpTx is the serialized field order after the type byte.
+type rlpTx struct {
+	Nonce           uint64
+	GasTipCap       *big.Int
+	GasFeeCap       *big.Int
+	Gas             uint64
+	To              common.Address
+	Value           *big.Int
+	Data            []byte
+	V               *big.Int // y parity
+	R               *big.Int
+	S               *big.Int
+	ChainID         *big.Int
+	From            common.Address
+	GasPerPubdata   *big.Int
+	FactoryDeps     [][]byte
+	CustomSignature []byte
+	PaymasterParams [][]byte // [paymaster, input] or empty
+}
+
+func (tx *Transaction) gasPerPubdata() *big.Int {
+	if tx.GasPerPubdata != nil {
+		return tx.GasPerPubdata
+	}
+	return DefaultGasPerPubdata
+}
+
+// SigningHash returns the EIP-712 digest the sender signs.
+func (tx *Transaction) SigningHash() common.Hash {
+	domain := crypto.Keccak256(
+		domainTypeHash.Bytes(),
+		domainNameHash.Bytes(),
+		domainVersionHash.Bytes(),
+		word(tx.ChainID),
+	)
+	message := crypto.Keccak256(
+		txTypeHash.Bytes(),
+		word(big.NewInt(TxType)),
+		common.LeftPadBytes(tx.From.Bytes(), 32),
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		word(new(big.Int).SetUint64(tx.Gas)),
+		word(tx.gasPerPubdata()),
+		word(tx.GasFeeCap),
+		word(tx.GasTipCap),
+		make([]byte, 32), // paymaster
+		word(new(big.Int).SetUint64(tx.Nonce)),
+		word(tx.Value),
+		crypto.Keccak256(tx.Data),
+		crypto.Keccak256(), // factoryDeps
+		crypto.Keccak256(), // paymasterInput
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+}
+
+// Hash returns the transaction hash for a signature over SigningHash.
+func (tx *Transaction) Hash(sig []byte) (common.Hash, error) {
+	sig, err := normalizeSignature(sig)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(tx.SigningHash().Bytes(), crypto.Keccak256(sig)), nil
+}
+
+// Encode serializes the transaction with a 65-byte [R || S || V] signature
+// over SigningHash (V is 0/1 or 27/28).
+func (tx *Transaction) Encode(sig []byte) ([]byte, error) {
+	custom, err := normalizeSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	parity := custom[64] - 27
+
+	body, err := rlp.EncodeToBytes(&rlpTx{
+		Nonce:           tx.Nonce,
+		GasTipCap:       tx.GasTipCap,
+		GasFeeCap:       tx.GasFeeCap,
+		Gas:             tx.Gas,
+		To:              tx.To,
+		Value:           tx.Value,
+		Data:            tx.Data,
+		V:               big.NewInt(int64(parity)),
+		R:               new(big.Int).SetBytes(sig[:32]),
+		S:               new(big.Int).SetBytes(sig[32:64]),
+		ChainID:         tx.ChainID,
+		From:            tx.From,
+		GasPerPubdata:   tx.gasPerPubdata(),
+		FactoryDeps:     [][]byte{},
+		CustomSignature: custom,
+		PaymasterParams: [][]byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{TxType}, body...), nil
+}
+
+// Decode parses a serialized transaction and returns it with its signature.
+func Decode(raw []byte) (*Transaction, []byte, error) {
+	if len(raw) == 0 || raw[0] != TxType {
+		return nil, nil, errors.New("not a zksync eip-712 transaction")
+	}
+	var dec rlpTx
+	if err := rlp.DecodeBytes(raw[1:], &dec); err != nil {
+		return nil, nil, fmt.Errorf("invalid zksync transaction: %w", err)
+	}
+	if len(dec.FactoryDeps) > 0 || len(dec.PaymasterParams) > 0 {
+		return nil, nil, errors.New("zksync transactions with factory deps or paymaster are not supported")
+	}
+	sig := dec.CustomSignature
+	if len(sig) != crypto.SignatureLength {
+		// Fall back to (yParity, r, s) when no custom signature is attached.
+		if dec.V.Cmp(big.NewInt(1)) > 0 || dec.R.BitLen() > 256 || dec.S.BitLen() > 256 {
+			return nil, nil, errors.New("invalid zksync transaction signature")
+		}
+		sig = make([]byte, crypto.SignatureLength)
+		dec.R.FillBytes(sig[:32])
+		dec.S.FillBytes(sig[32:64])
+		sig[64] = byte(dec.V.Uint64()) + 27
+	}
+	return &Transaction{
+		ChainID:       dec.ChainID,
+		Nonce:         dec.Nonce,
+		GasTipCap:     dec.GasTipCap,
+		GasFeeCap:     dec.GasFeeCap,
+		Gas:           dec.Gas,
+		From:          dec.From,
+		To:            dec.To,
+		Value:         dec.Value,
+		Data:          dec.Data,
+		GasPerPubdata: dec.GasPerPubdata,
+	}, sig, nil
+}
+
+// normalizeSignature copies a 65-byte signature with V as 27/28, the form
+// zkSync uses for the custom signature and the transaction hash.
+func normalizeSignature(sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	out := make([]byte, crypto.SignatureLength)
+	copy(out, sig)
+	if out[64] < 27 {
+		out[64] += 27
+	}
+	if out[64] != 27 && out[64] != 28 {
+		return nil, fmt.Errorf("invalid signature recovery id: %d", sig[64])
+	}
+	return out, nil
+}
+
+func word(v *big.Int) []byte {
+	if v == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
@@ -0,0 +1,114 @@
+package zksync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTx(from common.Address) *Transaction {
+	return &Transaction{
+		ChainID:   big.NewInt(324),
+		Nonce:     7,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(45250000),
+		Gas:       1_200_000,
+		From:      from,
+		To:        common.HexToAddress("0x1d17CBcF0D6D143135aE902365D2E5e2A16538D4"),
+		Value:     big.NewInt(0),
+		Data:      common.FromHex("0xa9059cbb000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa00000000000000000000000000000000000000000000000000000000000f4240"),
+	}
+}
+
+func TestSigningHashMatchesTypedData(t *testing.T) {
+	tx := testTx(common.HexToAddress("0x36615Cf349d7F6344891B1e7CA7C72883F5dc049"))
+
+	// 与 go-ethereum 的通用 EIP-712 实现交叉验证
+	uint256 := func(v *big.Int) *math.HexOrDecimal256 { return (*math.HexOrDecimal256)(v) }
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			"Transaction": {
+				{Name: "txType", Type: "uint256"},
+				{Name: "from", Type: "uint256"},
+				{Name: "to", Type: "uint256"},
+				{Name: "gasLimit", Type: "uint256"},
+				{Name: "gasPerPubdataByteLimit", Type: "uint256"},
+				{Name: "maxFeePerGas", Type: "uint256"},
+				{Name: "maxPriorityFeePerGas", Type: "uint256"},
+				{Name: "paymaster", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "factoryDeps", Type: "bytes32[]"},
+				{Name: "paymasterInput", Type: "bytes"},
+			},
+		},
+		PrimaryType: "Transaction",
+		Domain:      apitypes.TypedDataDomain{Name: "zkSync", Version: "2", ChainId: uint256(tx.ChainID)},
+		Message: apitypes.TypedDataMessage{
+			"txType":                 "113",
+			"from":                   new(big.Int).SetBytes(tx.From.Bytes()).String(),
+			"to":                     new(big.Int).SetBytes(tx.To.Bytes()).String(),
+			"gasLimit":               "1200000",
+			"gasPerPubdataByteLimit": "50000",
+			"maxFeePerGas":           "45250000",
+			"maxPriorityFeePerGas":   "0",
+			"paymaster":              "0",
+			"nonce":                  "7",
+			"value":                  "0",
+			"data":                   tx.Data,
+			"factoryDeps":            []interface{}{},
+			"paymasterInput":         []byte{},
+		},
+	}
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	require.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(want), tx.SigningHash())
+}
+
+func TestEncodeDecode(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx := testTx(crypto.PubkeyToAddress(key.PublicKey))
+	sig, err := crypto.Sign(tx.SigningHash().Bytes(), key)
+	require.NoError(t, err)
+
+	raw, err := tx.Encode(sig)
+	require.NoError(t, err)
+	assert.Equal(t, byte(TxType), raw[0])
+
+	decoded, decodedSig, err := Decode(raw)
+	require.NoError(t, err)
+	assert.Equal(t, tx.From, decoded.From)
+	assert.Equal(t, tx.To, decoded.To)
+	assert.Equal(t, tx.Nonce, decoded.Nonce)
+	assert.Equal(t, tx.Gas, decoded.Gas)
+	assert.Equal(t, tx.Data, decoded.Data)
+	assert.Equal(t, DefaultGasPerPubdata, decoded.GasPerPubdata)
+	assert.Equal(t, tx.SigningHash(), decoded.SigningHash())
+	// customSignature 的 v 为 27/28，哈希按其计算
+	assert.Equal(t, sig[64]+27, decodedSig[64])
+	hash, err := tx.Hash(sig)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(tx.SigningHash().Bytes(), crypto.Keccak256(decodedSig)), hash)
+
+	pub, err := crypto.SigToPub(decoded.SigningHash().Bytes(), append(decodedSig[:64:64], decodedSig[64]-27))
+	require.NoError(t, err)
+	assert.Equal(t, tx.From, crypto.PubkeyToAddress(*pub))
+
+	_, err = tx.Encode(sig[:64])
+	assert.Error(t, err)
+	_, _, err = Decode(append([]byte{0x02}, raw[1:]...))
+	assert.Error(t, err)
+}
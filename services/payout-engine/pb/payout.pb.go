@@ -0,0 +1,3446 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: payout.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 批量状态
+type BatchStatus int32
+
+const (
+	BatchStatus_BATCH_STATUS_UNSPECIFIED         BatchStatus = 0
+	BatchStatus_BATCH_STATUS_QUEUED              BatchStatus = 1 // 已入队
+	BatchStatus_BATCH_STATUS_PROCESSING          BatchStatus = 2 // 处理中
+	BatchStatus_BATCH_STATUS_AWAITING_SIGNATURES BatchStatus = 3 // 等待多签
+	BatchStatus_BATCH_STATUS_COMPLETED           BatchStatus = 4 // 已完成
+	BatchStatus_BATCH_STATUS_PARTIAL_FAILED      BatchStatus = 5 // 部分失败
+	BatchStatus_BATCH_STATUS_FAILED              BatchStatus = 6 // 全部失败
+	BatchStatus_BATCH_STATUS_CANCELLED           BatchStatus = 7 // 已取消
+	BatchStatus_BATCH_STATUS_SCHEDULED           BatchStatus = 8 // 等待定时执行
+	BatchStatus_BATCH_STATUS_PENDING_APPROVAL    BatchStatus = 9 // 超过审批阈值，等待审批人签名
+)
+
+// Enum value maps for BatchStatus.
+var (
+	BatchStatus_name = map[int32]string{
+		0: "BATCH_STATUS_UNSPECIFIED",
+		1: "BATCH_STATUS_QUEUED",
+		2: "BATCH_STATUS_PROCESSING",
+		3: "BATCH_STATUS_AWAITING_SIGNATURES",
+		4: "BATCH_STATUS_COMPLETED",
+		5: "BATCH_STATUS_PARTIAL_FAILED",
+		6: "BATCH_STATUS_FAILED",
+		7: "BATCH_STATUS_CANCELLED",
+		8: "BATCH_STATUS_SCHEDULED",
+		9: "BATCH_STATUS_PENDING_APPROVAL",
+	}
+	BatchStatus_value = map[string]int32{
+		"BATCH_STATUS_UNSPECIFIED":         0,
+		"BATCH_STATUS_QUEUED":              1,
+		"BATCH_STATUS_PROCESSING":          2,
+		"BATCH_STATUS_AWAITING_SIGNATURES": 3,
+		"BATCH_STATUS_COMPLETED":           4,
+		"BATCH_STATUS_PARTIAL_FAILED":      5,
+		"BATCH_STATUS_FAILED":              6,
+		"BATCH_STATUS_CANCELLED":           7,
+		"BATCH_STATUS_SCHEDULED":           8,
+		"BATCH_STATUS_PENDING_APPROVAL":    9,
+	}
+)
+
+func (x BatchStatus) Enum() *BatchStatus {
+	p := new(BatchStatus)
+	*p = x
+	return p
+}
+
+func (x BatchStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BatchStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payout_proto_enumTypes[0].Descriptor()
+}
+
+func (BatchStatus) Type() protoreflect.EnumType {
+	return &file_payout_proto_enumTypes[0]
+}
+
+func (x BatchStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BatchStatus.Descriptor instead.
+func (BatchStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{0}
+}
+
+// 单笔支付状态
+type PayoutStatus int32
+
+const (
+	PayoutStatus_PAYOUT_STATUS_UNSPECIFIED PayoutStatus = 0
+	PayoutStatus_PAYOUT_STATUS_PENDING     PayoutStatus = 1 // 待处理
+	PayoutStatus_PAYOUT_STATUS_SUBMITTED   PayoutStatus = 2 // 已提交
+	PayoutStatus_PAYOUT_STATUS_CONFIRMING  PayoutStatus = 3 // 确认中
+	PayoutStatus_PAYOUT_STATUS_CONFIRMED   PayoutStatus = 4 // 已确认
+	PayoutStatus_PAYOUT_STATUS_FAILED      PayoutStatus = 5 // 失败
+	PayoutStatus_PAYOUT_STATUS_RETRYING    PayoutStatus = 6 // 重试中
+	PayoutStatus_PAYOUT_STATUS_CANCELLED   PayoutStatus = 7 // 已取消
+)
+
+// Enum value maps for PayoutStatus.
+var (
+	PayoutStatus_name = map[int32]string{
+		0: "PAYOUT_STATUS_UNSPECIFIED",
+		1: "PAYOUT_STATUS_PENDING",
+		2: "PAYOUT_STATUS_SUBMITTED",
+		3: "PAYOUT_STATUS_CONFIRMING",
+		4: "PAYOUT_STATUS_CONFIRMED",
+		5: "PAYOUT_STATUS_FAILED",
+		6: "PAYOUT_STATUS_RETRYING",
+		7: "PAYOUT_STATUS_CANCELLED",
+	}
+	PayoutStatus_value = map[string]int32{
+		"PAYOUT_STATUS_UNSPECIFIED": 0,
+		"PAYOUT_STATUS_PENDING":     1,
+		"PAYOUT_STATUS_SUBMITTED":   2,
+		"PAYOUT_STATUS_CONFIRMING":  3,
+		"PAYOUT_STATUS_CONFIRMED":   4,
+		"PAYOUT_STATUS_FAILED":      5,
+		"PAYOUT_STATUS_RETRYING":    6,
+		"PAYOUT_STATUS_CANCELLED":   7,
+	}
+)
+
+func (x PayoutStatus) Enum() *PayoutStatus {
+	p := new(PayoutStatus)
+	*p = x
+	return p
+}
+
+func (x PayoutStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PayoutStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payout_proto_enumTypes[1].Descriptor()
+}
+
+func (PayoutStatus) Type() protoreflect.EnumType {
+	return &file_payout_proto_enumTypes[1]
+}
+
+func (x PayoutStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PayoutStatus.Descriptor instead.
+func (PayoutStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{1}
+}
+
+// 单笔支付项
+type PayoutItem struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                     // 唯一标识
+	RecipientAddress string                 `protobuf:"bytes,2,opt,name=recipient_address,json=recipientAddress,proto3" json:"recipient_address,omitempty"` // 收款地址
+	Amount           string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`                                             // 金额 (wei/smallest unit)
+	TokenAddress     string                 `protobuf:"bytes,4,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`             // 代币合约地址 (空字符串=原生代币)
+	TokenSymbol      string                 `protobuf:"bytes,5,opt,name=token_symbol,json=tokenSymbol,proto3" json:"token_symbol,omitempty"`                // 代币符号
+	TokenDecimals    uint32                 `protobuf:"varint,6,opt,name=token_decimals,json=tokenDecimals,proto3" json:"token_decimals,omitempty"`         // 代币精度
+	VendorName       string                 `protobuf:"bytes,7,opt,name=vendor_name,json=vendorName,proto3" json:"vendor_name,omitempty"`                   // 供应商名称 (可选)
+	VendorId         string                 `protobuf:"bytes,8,opt,name=vendor_id,json=vendorId,proto3" json:"vendor_id,omitempty"`                         // 供应商ID (可选)
+	Memo             string                 `protobuf:"bytes,9,opt,name=memo,proto3" json:"memo,omitempty"`                                                 // 备注 (可选)
+	TokenId          string                 `protobuf:"bytes,10,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`                           // TRC10 资产 ID (仅 TRON，与 token_address 互斥)
+	MaxFee           string                 `protobuf:"bytes,11,opt,name=max_fee,json=maxFee,proto3" json:"max_fee,omitempty"`                              // 网络费上限 (原生代币最小单位 wei/SUN，可选)，预计超过时不发送
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PayoutItem) Reset() {
+	*x = PayoutItem{}
+	mi := &file_payout_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayoutItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayoutItem) ProtoMessage() {}
+
+func (x *PayoutItem) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayoutItem.ProtoReflect.Descriptor instead.
+func (*PayoutItem) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PayoutItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetRecipientAddress() string {
+	if x != nil {
+		return x.RecipientAddress
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetTokenSymbol() string {
+	if x != nil {
+		return x.TokenSymbol
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetTokenDecimals() uint32 {
+	if x != nil {
+		return x.TokenDecimals
+	}
+	return 0
+}
+
+func (x *PayoutItem) GetVendorName() string {
+	if x != nil {
+		return x.VendorName
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetVendorId() string {
+	if x != nil {
+		return x.VendorId
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetMemo() string {
+	if x != nil {
+		return x.Memo
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *PayoutItem) GetMaxFee() string {
+	if x != nil {
+		return x.MaxFee
+	}
+	return ""
+}
+
+// 批量支付请求
+type BatchPayoutRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BatchId     string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`             // 批次ID (客户端生成)
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // 用户ID
+	FromAddress string                 `protobuf:"bytes,3,opt,name=from_address,json=fromAddress,proto3" json:"from_address,omitempty"` // 付款地址
+	ChainId     uint64                 `protobuf:"varint,4,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`            // 链ID
+	Items       []*PayoutItem          `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`                                // 支付项列表
+	// 多签配置 (可选)
+	MultisigConfig *MultiSigConfig `protobuf:"bytes,6,opt,name=multisig_config,json=multisigConfig,proto3" json:"multisig_config,omitempty"`
+	// Gas 配置
+	GasConfig *GasConfig `protobuf:"bytes,7,opt,name=gas_config,json=gasConfig,proto3" json:"gas_config,omitempty"`
+	// 安全配置
+	SecurityConfig  *SecurityConfig `protobuf:"bytes,8,opt,name=security_config,json=securityConfig,proto3" json:"security_config,omitempty"`
+	Priority        string          `protobuf:"bytes,9,opt,name=priority,proto3" json:"priority,omitempty"`                                          // 费用优先级: LOW / MEDIUM / HIGH / URGENT (默认 MEDIUM)
+	UseSmartAccount bool            `protobuf:"varint,10,opt,name=use_smart_account,json=useSmartAccount,proto3" json:"use_smart_account,omitempty"` // 通过 ERC-4337 智能账户发送
+	AllowPartial    bool            `protobuf:"varint,11,opt,name=allow_partial,json=allowPartial,proto3" json:"allow_partial,omitempty"`            // 余额不足时接受能覆盖的支付项
+	IdempotencyKey  string          `protobuf:"bytes,12,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`       // 幂等键 (默认为 batch_id)
+	// 状态回调 (可选): 引擎向该地址 POST 签名事件
+	// job.sent / job.confirmed / job.failed / job.reorged / batch.completed
+	// X-Payout-Signature: sha256=hex(HMAC-SHA256(webhook_secret, X-Payout-Timestamp + "." + body))
+	WebhookUrl    string `protobuf:"bytes,13,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`
+	WebhookSecret string `protobuf:"bytes,14,opt,name=webhook_secret,json=webhookSecret,proto3" json:"webhook_secret,omitempty"` // webhook_url 非空时必填
+	// 人工批准越过收款地址筛查 (制裁/高风险地址)，须填写理由
+	ScreeningOverride       bool   `protobuf:"varint,15,opt,name=screening_override,json=screeningOverride,proto3" json:"screening_override,omitempty"`
+	ScreeningOverrideReason string `protobuf:"bytes,16,opt,name=screening_override_reason,json=screeningOverrideReason,proto3" json:"screening_override_reason,omitempty"`
+	// 试运行: 逐笔 eth_call / eth_estimateGas，返回预计失败 (rejected) 和总网络费 (estimated_gas_cost)，
+	// 不签名、不广播、不入队
+	Simulate bool `protobuf:"varint,17,opt,name=simulate,proto3" json:"simulate,omitempty"`
+	// 状态回调事件 payload 版本 (1: 扁平结构, 2: {id, type, schema_version, created_at, data} 信封)
+	// 0 时使用服务端默认 (WEBHOOK_SCHEMA_VERSION，默认 1)
+	WebhookSchemaVersion int32 `protobuf:"varint,18,opt,name=webhook_schema_version,json=webhookSchemaVersion,proto3" json:"webhook_schema_version,omitempty"`
+	// 定时执行 (可选): 到达该时间后才预检余额并入队，如每月 1 日发薪。
+	// 为空或已过时立即执行；执行前可取消 (CancelBatchPayout) 或修改 (UpdateScheduledBatch)
+	ExecuteAt *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`
+	// 代付 (可选): 源钱包签名的 EIP-2612 permit，引擎用 transferFrom 从源钱包直接转给收款人，
+	// 源钱包私钥无需托管。spender 须为该链付款地址，value 须覆盖批次总额
+	Permit *Permit `protobuf:"bytes,20,opt,name=permit,proto3" json:"permit,omitempty"`
+	// 金库出款 (可选): 以 transferFrom 从该链配置的中央金库合约转给收款人，金库须对 from_address 授权代币额度。
+	// 仅 ERC20 代币，不可与 permit / use_smart_account 同用
+	UseTreasury bool `protobuf:"varint,21,opt,name=use_treasury,json=useTreasury,proto3" json:"use_treasury,omitempty"`
+	// 转账前兑换 (可选): 付款地址持有 sell_token 而支付项为其他代币时，经 DEX 聚合器 (0x / 1inch) 先换出每笔金额再转账。
+	// 支付项须为同一 ERC20 代币，不可与 permit / use_treasury / use_smart_account 同用
+	Swap *Swap `protobuf:"bytes,22,opt,name=swap,proto3" json:"swap,omitempty"`
+	// 跨链路由 (可选): 资金在该链付款地址上时，每笔先经 Circle CCTP 将 USDC 跨到 chain_id 再转账。
+	// 支付项须为 chain_id 上的 USDC，两条链均须配置 CCTP，不可与 permit / use_treasury / swap / use_smart_account 同用
+	SourceChainId uint64 `protobuf:"varint,23,opt,name=source_chain_id,json=sourceChainId,proto3" json:"source_chain_id,omitempty"`
+	// 重复支付项 (收款地址、代币、金额均相同，常见于 CSV 重复行) 的处理，为空时不检查:
+	// "flag" 保留首项，其余标记后不入队; "merge" 合并到首项 (金额相加) 以一笔交易支付。处理结果见响应的 duplicates
+	DuplicateMode string `protobuf:"bytes,24,opt,name=duplicate_mode,json=duplicateMode,proto3" json:"duplicate_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchPayoutRequest) Reset() {
+	*x = BatchPayoutRequest{}
+	mi := &file_payout_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchPayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPayoutRequest) ProtoMessage() {}
+
+func (x *BatchPayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPayoutRequest.ProtoReflect.Descriptor instead.
+func (*BatchPayoutRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchPayoutRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetFromAddress() string {
+	if x != nil {
+		return x.FromAddress
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *BatchPayoutRequest) GetItems() []*PayoutItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetMultisigConfig() *MultiSigConfig {
+	if x != nil {
+		return x.MultisigConfig
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetGasConfig() *GasConfig {
+	if x != nil {
+		return x.GasConfig
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetSecurityConfig() *SecurityConfig {
+	if x != nil {
+		return x.SecurityConfig
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetUseSmartAccount() bool {
+	if x != nil {
+		return x.UseSmartAccount
+	}
+	return false
+}
+
+func (x *BatchPayoutRequest) GetAllowPartial() bool {
+	if x != nil {
+		return x.AllowPartial
+	}
+	return false
+}
+
+func (x *BatchPayoutRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetWebhookSecret() string {
+	if x != nil {
+		return x.WebhookSecret
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetScreeningOverride() bool {
+	if x != nil {
+		return x.ScreeningOverride
+	}
+	return false
+}
+
+func (x *BatchPayoutRequest) GetScreeningOverrideReason() string {
+	if x != nil {
+		return x.ScreeningOverrideReason
+	}
+	return ""
+}
+
+func (x *BatchPayoutRequest) GetSimulate() bool {
+	if x != nil {
+		return x.Simulate
+	}
+	return false
+}
+
+func (x *BatchPayoutRequest) GetWebhookSchemaVersion() int32 {
+	if x != nil {
+		return x.WebhookSchemaVersion
+	}
+	return 0
+}
+
+func (x *BatchPayoutRequest) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetPermit() *Permit {
+	if x != nil {
+		return x.Permit
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetUseTreasury() bool {
+	if x != nil {
+		return x.UseTreasury
+	}
+	return false
+}
+
+func (x *BatchPayoutRequest) GetSwap() *Swap {
+	if x != nil {
+		return x.Swap
+	}
+	return nil
+}
+
+func (x *BatchPayoutRequest) GetSourceChainId() uint64 {
+	if x != nil {
+		return x.SourceChainId
+	}
+	return 0
+}
+
+func (x *BatchPayoutRequest) GetDuplicateMode() string {
+	if x != nil {
+		return x.DuplicateMode
+	}
+	return ""
+}
+
+// 转账前兑换参数
+type Swap struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SellToken      string                 `protobuf:"bytes,1,opt,name=sell_token,json=sellToken,proto3" json:"sell_token,omitempty"`                   // 付款地址持有的代币合约
+	MaxSlippageBps uint32                 `protobuf:"varint,2,opt,name=max_slippage_bps,json=maxSlippageBps,proto3" json:"max_slippage_bps,omitempty"` // 批次最大滑点 (基点，0 时使用服务端上限 SWAP_MAX_SLIPPAGE_BPS)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Swap) Reset() {
+	*x = Swap{}
+	mi := &file_payout_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Swap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Swap) ProtoMessage() {}
+
+func (x *Swap) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Swap.ProtoReflect.Descriptor instead.
+func (*Swap) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Swap) GetSellToken() string {
+	if x != nil {
+		return x.SellToken
+	}
+	return ""
+}
+
+func (x *Swap) GetMaxSlippageBps() uint32 {
+	if x != nil {
+		return x.MaxSlippageBps
+	}
+	return 0
+}
+
+// EIP-2612 permit 签名
+type Permit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`        // 源钱包地址
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`        // 授权额度 (最小单位)
+	Deadline      int64                  `protobuf:"varint,3,opt,name=deadline,proto3" json:"deadline,omitempty"` // 过期时间 (Unix 秒)
+	V             uint32                 `protobuf:"varint,4,opt,name=v,proto3" json:"v,omitempty"`               // 签名 v (0/1 或 27/28)
+	R             string                 `protobuf:"bytes,5,opt,name=r,proto3" json:"r,omitempty"`                // 签名 r (32 字节 hex)
+	S             string                 `protobuf:"bytes,6,opt,name=s,proto3" json:"s,omitempty"`                // 签名 s (32 字节 hex)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Permit) Reset() {
+	*x = Permit{}
+	mi := &file_payout_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Permit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Permit) ProtoMessage() {}
+
+func (x *Permit) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Permit.ProtoReflect.Descriptor instead.
+func (*Permit) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Permit) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Permit) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Permit) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *Permit) GetV() uint32 {
+	if x != nil {
+		return x.V
+	}
+	return 0
+}
+
+func (x *Permit) GetR() string {
+	if x != nil {
+		return x.R
+	}
+	return ""
+}
+
+func (x *Permit) GetS() string {
+	if x != nil {
+		return x.S
+	}
+	return ""
+}
+
+// 多签配置
+type MultiSigConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`                           // 是否启用多签
+	SafeAddress   string                 `protobuf:"bytes,2,opt,name=safe_address,json=safeAddress,proto3" json:"safe_address,omitempty"` // Safe 合约地址
+	Threshold     uint32                 `protobuf:"varint,3,opt,name=threshold,proto3" json:"threshold,omitempty"`                       // 签名阈值
+	Signers       []string               `protobuf:"bytes,4,rep,name=signers,proto3" json:"signers,omitempty"`                            // 签名者列表
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSigConfig) Reset() {
+	*x = MultiSigConfig{}
+	mi := &file_payout_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSigConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSigConfig) ProtoMessage() {}
+
+func (x *MultiSigConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSigConfig.ProtoReflect.Descriptor instead.
+func (*MultiSigConfig) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MultiSigConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *MultiSigConfig) GetSafeAddress() string {
+	if x != nil {
+		return x.SafeAddress
+	}
+	return ""
+}
+
+func (x *MultiSigConfig) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *MultiSigConfig) GetSigners() []string {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+// Gas 配置
+type GasConfig struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	MaxFeePerGas       string                 `protobuf:"bytes,1,opt,name=max_fee_per_gas,json=maxFeePerGas,proto3" json:"max_fee_per_gas,omitempty"`                  // 最大 Gas 价格 (可选)
+	MaxPriorityFee     string                 `protobuf:"bytes,2,opt,name=max_priority_fee,json=maxPriorityFee,proto3" json:"max_priority_fee,omitempty"`              // 最大优先费 (可选)
+	GasLimitMultiplier uint64                 `protobuf:"varint,3,opt,name=gas_limit_multiplier,json=gasLimitMultiplier,proto3" json:"gas_limit_multiplier,omitempty"` // Gas 限制乘数 (默认 120 = 1.2x)
+	AutoAdjust         bool                   `protobuf:"varint,4,opt,name=auto_adjust,json=autoAdjust,proto3" json:"auto_adjust,omitempty"`                           // 自动调整 Gas
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GasConfig) Reset() {
+	*x = GasConfig{}
+	mi := &file_payout_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GasConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GasConfig) ProtoMessage() {}
+
+func (x *GasConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GasConfig.ProtoReflect.Descriptor instead.
+func (*GasConfig) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GasConfig) GetMaxFeePerGas() string {
+	if x != nil {
+		return x.MaxFeePerGas
+	}
+	return ""
+}
+
+func (x *GasConfig) GetMaxPriorityFee() string {
+	if x != nil {
+		return x.MaxPriorityFee
+	}
+	return ""
+}
+
+func (x *GasConfig) GetGasLimitMultiplier() uint64 {
+	if x != nil {
+		return x.GasLimitMultiplier
+	}
+	return 0
+}
+
+func (x *GasConfig) GetAutoAdjust() bool {
+	if x != nil {
+		return x.AutoAdjust
+	}
+	return false
+}
+
+// 安全配置
+type SecurityConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SignedHash    string                 `protobuf:"bytes,1,opt,name=signed_hash,json=signedHash,proto3" json:"signed_hash,omitempty"` // 请求签名哈希
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                    // 请求时间戳
+	Nonce         string                 `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`                             // 防重放 nonce
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecurityConfig) Reset() {
+	*x = SecurityConfig{}
+	mi := &file_payout_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityConfig) ProtoMessage() {}
+
+func (x *SecurityConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityConfig.ProtoReflect.Descriptor instead.
+func (*SecurityConfig) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SecurityConfig) GetSignedHash() string {
+	if x != nil {
+		return x.SignedHash
+	}
+	return ""
+}
+
+func (x *SecurityConfig) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SecurityConfig) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+// 批量支付响应
+type BatchPayoutResponse struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	BatchId                 string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Status                  BatchStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=payout.BatchStatus" json:"status,omitempty"`
+	Message                 string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	EstimatedCompletionTime int64                  `protobuf:"varint,4,opt,name=estimated_completion_time,json=estimatedCompletionTime,proto3" json:"estimated_completion_time,omitempty"` // 预计完成时间 (Unix timestamp)
+	EstimatedGasCost        string                 `protobuf:"bytes,5,opt,name=estimated_gas_cost,json=estimatedGasCost,proto3" json:"estimated_gas_cost,omitempty"`                       // 预计 Gas 费用
+	Rejected                []*RejectedItem        `protobuf:"bytes,6,rep,name=rejected,proto3" json:"rejected,omitempty"`                                                                 // 余额不足未入队的支付项 (仅 allow_partial)；试运行时为预计失败的支付项
+	Testnet                 bool                   `protobuf:"varint,7,opt,name=testnet,proto3" json:"testnet,omitempty"`                                                                  // 测试网支付
+	Replayed                bool                   `protobuf:"varint,8,opt,name=replayed,proto3" json:"replayed,omitempty"`                                                                // 幂等重放，未重复入队
+	ManifestHash            string                 `protobuf:"bytes,9,opt,name=manifest_hash,json=manifestHash,proto3" json:"manifest_hash,omitempty"`                                     // 任务清单哈希 sha256(manifest JSON)
+	ManifestSignature       string                 `protobuf:"bytes,10,opt,name=manifest_signature,json=manifestSignature,proto3" json:"manifest_signature,omitempty"`                     // EIP-191 签名 (manifest_hash 字节)
+	ManifestSigner          string                 `protobuf:"bytes,11,opt,name=manifest_signer,json=manifestSigner,proto3" json:"manifest_signer,omitempty"`                              // 签名地址
+	Simulated               bool                   `protobuf:"varint,12,opt,name=simulated,proto3" json:"simulated,omitempty"`                                                             // 试运行结果，未入队
+	ExecuteAt               *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`                                             // 定时批次的执行时间
+	Duplicates              []*DuplicateItem       `protobuf:"bytes,14,rep,name=duplicates,proto3" json:"duplicates,omitempty"`                                                            // 按 duplicate_mode 处理的重复支付项
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *BatchPayoutResponse) Reset() {
+	*x = BatchPayoutResponse{}
+	mi := &file_payout_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchPayoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPayoutResponse) ProtoMessage() {}
+
+func (x *BatchPayoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPayoutResponse.ProtoReflect.Descriptor instead.
+func (*BatchPayoutResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchPayoutResponse) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetStatus() BatchStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BatchStatus_BATCH_STATUS_UNSPECIFIED
+}
+
+func (x *BatchPayoutResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetEstimatedCompletionTime() int64 {
+	if x != nil {
+		return x.EstimatedCompletionTime
+	}
+	return 0
+}
+
+func (x *BatchPayoutResponse) GetEstimatedGasCost() string {
+	if x != nil {
+		return x.EstimatedGasCost
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetRejected() []*RejectedItem {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+func (x *BatchPayoutResponse) GetTestnet() bool {
+	if x != nil {
+		return x.Testnet
+	}
+	return false
+}
+
+func (x *BatchPayoutResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *BatchPayoutResponse) GetManifestHash() string {
+	if x != nil {
+		return x.ManifestHash
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetManifestSignature() string {
+	if x != nil {
+		return x.ManifestSignature
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetManifestSigner() string {
+	if x != nil {
+		return x.ManifestSigner
+	}
+	return ""
+}
+
+func (x *BatchPayoutResponse) GetSimulated() bool {
+	if x != nil {
+		return x.Simulated
+	}
+	return false
+}
+
+func (x *BatchPayoutResponse) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *BatchPayoutResponse) GetDuplicates() []*DuplicateItem {
+	if x != nil {
+		return x.Duplicates
+	}
+	return nil
+}
+
+// 预检未通过的支付项
+type RejectedItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedItem) Reset() {
+	*x = RejectedItem{}
+	mi := &file_payout_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedItem) ProtoMessage() {}
+
+func (x *RejectedItem) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedItem.ProtoReflect.Descriptor instead.
+func (*RejectedItem) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RejectedItem) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *RejectedItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// 重复支付项的处理结果
+type DuplicateItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	DuplicateOf   string                 `protobuf:"bytes,2,opt,name=duplicate_of,json=duplicateOf,proto3" json:"duplicate_of,omitempty"` // 保留的首个支付项
+	Decision      string                 `protobuf:"bytes,3,opt,name=decision,proto3" json:"decision,omitempty"`                          // flag: 未入队; merge: 金额已并入 duplicate_of
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DuplicateItem) Reset() {
+	*x = DuplicateItem{}
+	mi := &file_payout_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DuplicateItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DuplicateItem) ProtoMessage() {}
+
+func (x *DuplicateItem) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DuplicateItem.ProtoReflect.Descriptor instead.
+func (*DuplicateItem) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DuplicateItem) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *DuplicateItem) GetDuplicateOf() string {
+	if x != nil {
+		return x.DuplicateOf
+	}
+	return ""
+}
+
+func (x *DuplicateItem) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+// 批量状态查询请求
+type BatchStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchStatusRequest) Reset() {
+	*x = BatchStatusRequest{}
+	mi := &file_payout_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchStatusRequest) ProtoMessage() {}
+
+func (x *BatchStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchStatusRequest.ProtoReflect.Descriptor instead.
+func (*BatchStatusRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchStatusRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *BatchStatusRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 批量状态响应
+type BatchStatusResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BatchId           string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Status            BatchStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=payout.BatchStatus" json:"status,omitempty"`
+	TotalCount        int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	CompletedCount    int32                  `protobuf:"varint,4,opt,name=completed_count,json=completedCount,proto3" json:"completed_count,omitempty"`
+	FailedCount       int32                  `protobuf:"varint,5,opt,name=failed_count,json=failedCount,proto3" json:"failed_count,omitempty"`
+	PendingCount      int32                  `protobuf:"varint,6,opt,name=pending_count,json=pendingCount,proto3" json:"pending_count,omitempty"`
+	Items             []*PayoutItemStatus    `protobuf:"bytes,7,rep,name=items,proto3" json:"items,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ManifestHash      string                 `protobuf:"bytes,10,opt,name=manifest_hash,json=manifestHash,proto3" json:"manifest_hash,omitempty"`                 // 提交时的任务清单哈希
+	ExecuteAt         *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`                          // 定时批次的执行时间
+	ErrorMessage      string                 `protobuf:"bytes,12,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`                 // 定时批次到期后未能入队的原因
+	CancelledCount    int32                  `protobuf:"varint,13,opt,name=cancelled_count,json=cancelledCount,proto3" json:"cancelled_count,omitempty"`          // 已取消的支付项
+	ApprovedBy        []string               `protobuf:"bytes,14,rep,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`                       // 待审批批次: 已签名的审批人
+	ApprovalsRequired int32                  `protobuf:"varint,15,opt,name=approvals_required,json=approvalsRequired,proto3" json:"approvals_required,omitempty"` // 待审批批次: 放行所需的审批人数
+	TotalGasFee       string                 `protobuf:"bytes,16,opt,name=total_gas_fee,json=totalGasFee,proto3" json:"total_gas_fee,omitempty"`                  // 已上链交易的网络费合计 (原生代币最小单位)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BatchStatusResponse) Reset() {
+	*x = BatchStatusResponse{}
+	mi := &file_payout_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchStatusResponse) ProtoMessage() {}
+
+func (x *BatchStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchStatusResponse.ProtoReflect.Descriptor instead.
+func (*BatchStatusResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchStatusResponse) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *BatchStatusResponse) GetStatus() BatchStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BatchStatus_BATCH_STATUS_UNSPECIFIED
+}
+
+func (x *BatchStatusResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetCompletedCount() int32 {
+	if x != nil {
+		return x.CompletedCount
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetFailedCount() int32 {
+	if x != nil {
+		return x.FailedCount
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetPendingCount() int32 {
+	if x != nil {
+		return x.PendingCount
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetItems() []*PayoutItemStatus {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchStatusResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *BatchStatusResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *BatchStatusResponse) GetManifestHash() string {
+	if x != nil {
+		return x.ManifestHash
+	}
+	return ""
+}
+
+func (x *BatchStatusResponse) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *BatchStatusResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *BatchStatusResponse) GetCancelledCount() int32 {
+	if x != nil {
+		return x.CancelledCount
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetApprovedBy() []string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return nil
+}
+
+func (x *BatchStatusResponse) GetApprovalsRequired() int32 {
+	if x != nil {
+		return x.ApprovalsRequired
+	}
+	return 0
+}
+
+func (x *BatchStatusResponse) GetTotalGasFee() string {
+	if x != nil {
+		return x.TotalGasFee
+	}
+	return ""
+}
+
+// 单笔支付状态
+type PayoutItemStatus struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RecipientAddress  string                 `protobuf:"bytes,2,opt,name=recipient_address,json=recipientAddress,proto3" json:"recipient_address,omitempty"`
+	Amount            string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status            PayoutStatus           `protobuf:"varint,4,opt,name=status,proto3,enum=payout.PayoutStatus" json:"status,omitempty"`
+	TxHash            string                 `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`                   // 交易哈希
+	Confirmations     uint64                 `protobuf:"varint,6,opt,name=confirmations,proto3" json:"confirmations,omitempty"`                  // 确认数
+	ErrorMessage      string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"` // 错误信息
+	RetryCount        int32                  `protobuf:"varint,8,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`      // 重试次数
+	BatchId           string                 `protobuf:"bytes,9,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	ChainId           uint64                 `protobuf:"varint,10,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	TokenAddress      string                 `protobuf:"bytes,11,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	GasFee            string                 `protobuf:"bytes,13,opt,name=gas_fee,json=gasFee,proto3" json:"gas_fee,omitempty"`                                                                                                              // 实际网络费 (原生代币最小单位，含解包交易)
+	GasUsed           uint64                 `protobuf:"varint,14,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`                                                                                                          // EVM: 回执的 gasUsed
+	GasPrice          string                 `protobuf:"bytes,15,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`                                                                                                        // EVM: 回执的 effectiveGasPrice (wei)
+	ChainErrorCode    string                 `protobuf:"bytes,16,opt,name=chain_error_code,json=chainErrorCode,proto3" json:"chain_error_code,omitempty"`                                                                                    // 链上失败类别: INSUFFICIENT_FUNDS / NONCE_CONFLICT / RPC_TIMEOUT / REVERTED / REJECTED_BY_NODE / REORGED_NONCE_CONSUMED
+	ChainErrorDetails map[string]string      `protobuf:"bytes,17,rep,name=chain_error_details,json=chainErrorDetails,proto3" json:"chain_error_details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 失败详情 (如 revert_reason、rpc_code)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PayoutItemStatus) Reset() {
+	*x = PayoutItemStatus{}
+	mi := &file_payout_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayoutItemStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayoutItemStatus) ProtoMessage() {}
+
+func (x *PayoutItemStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayoutItemStatus.ProtoReflect.Descriptor instead.
+func (*PayoutItemStatus) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PayoutItemStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetRecipientAddress() string {
+	if x != nil {
+		return x.RecipientAddress
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetStatus() PayoutStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PayoutStatus_PAYOUT_STATUS_UNSPECIFIED
+}
+
+func (x *PayoutItemStatus) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *PayoutItemStatus) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+func (x *PayoutItemStatus) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *PayoutItemStatus) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *PayoutItemStatus) GetGasFee() string {
+	if x != nil {
+		return x.GasFee
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *PayoutItemStatus) GetGasPrice() string {
+	if x != nil {
+		return x.GasPrice
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetChainErrorCode() string {
+	if x != nil {
+		return x.ChainErrorCode
+	}
+	return ""
+}
+
+func (x *PayoutItemStatus) GetChainErrorDetails() map[string]string {
+	if x != nil {
+		return x.ChainErrorDetails
+	}
+	return nil
+}
+
+// 支付进度 (流式)
+type PayoutProgress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BatchId         string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	ItemId          string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Status          PayoutStatus           `protobuf:"varint,3,opt,name=status,proto3,enum=payout.PayoutStatus" json:"status,omitempty"`
+	TxHash          string                 `protobuf:"bytes,4,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	Confirmations   uint64                 `protobuf:"varint,5,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	ProgressPercent int32                  `protobuf:"varint,7,opt,name=progress_percent,json=progressPercent,proto3" json:"progress_percent,omitempty"` // 整体进度百分比
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PayoutProgress) Reset() {
+	*x = PayoutProgress{}
+	mi := &file_payout_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayoutProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayoutProgress) ProtoMessage() {}
+
+func (x *PayoutProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayoutProgress.ProtoReflect.Descriptor instead.
+func (*PayoutProgress) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PayoutProgress) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *PayoutProgress) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *PayoutProgress) GetStatus() PayoutStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PayoutStatus_PAYOUT_STATUS_UNSPECIFIED
+}
+
+func (x *PayoutProgress) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *PayoutProgress) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *PayoutProgress) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *PayoutProgress) GetProgressPercent() int32 {
+	if x != nil {
+		return x.ProgressPercent
+	}
+	return 0
+}
+
+// 取消批量请求
+type CancelBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	JobIds        []string               `protobuf:"bytes,4,rep,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"` // 只取消这些支付项 (为空时取消整批)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelBatchRequest) Reset() {
+	*x = CancelBatchRequest{}
+	mi := &file_payout_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelBatchRequest) ProtoMessage() {}
+
+func (x *CancelBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelBatchRequest.ProtoReflect.Descriptor instead.
+func (*CancelBatchRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CancelBatchRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *CancelBatchRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CancelBatchRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CancelBatchRequest) GetJobIds() []string {
+	if x != nil {
+		return x.JobIds
+	}
+	return nil
+}
+
+// 取消批量响应
+type CancelBatchResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Success               bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message               string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CancelledCount        int32                  `protobuf:"varint,3,opt,name=cancelled_count,json=cancelledCount,proto3" json:"cancelled_count,omitempty"`                        // 已取消数量
+	AlreadyProcessedCount int32                  `protobuf:"varint,4,opt,name=already_processed_count,json=alreadyProcessedCount,proto3" json:"already_processed_count,omitempty"` // 已处理无法取消数量
+	CancelledJobIds       []string               `protobuf:"bytes,5,rep,name=cancelled_job_ids,json=cancelledJobIds,proto3" json:"cancelled_job_ids,omitempty"`                    // 指定 job_ids 时: 已取消的支付项
+	InFlightJobIds        []string               `protobuf:"bytes,6,rep,name=in_flight_job_ids,json=inFlightJobIds,proto3" json:"in_flight_job_ids,omitempty"`                     // 指定 job_ids 时: 已在处理中或已结束、无法取消的支付项
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *CancelBatchResponse) Reset() {
+	*x = CancelBatchResponse{}
+	mi := &file_payout_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelBatchResponse) ProtoMessage() {}
+
+func (x *CancelBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelBatchResponse.ProtoReflect.Descriptor instead.
+func (*CancelBatchResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CancelBatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CancelBatchResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CancelBatchResponse) GetCancelledCount() int32 {
+	if x != nil {
+		return x.CancelledCount
+	}
+	return 0
+}
+
+func (x *CancelBatchResponse) GetAlreadyProcessedCount() int32 {
+	if x != nil {
+		return x.AlreadyProcessedCount
+	}
+	return 0
+}
+
+func (x *CancelBatchResponse) GetCancelledJobIds() []string {
+	if x != nil {
+		return x.CancelledJobIds
+	}
+	return nil
+}
+
+func (x *CancelBatchResponse) GetInFlightJobIds() []string {
+	if x != nil {
+		return x.InFlightJobIds
+	}
+	return nil
+}
+
+// 任务列表请求
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BatchId       string                 `protobuf:"bytes,2,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`          // 为空时列出全部批次
+	Status        PayoutStatus           `protobuf:"varint,3,opt,name=status,proto3,enum=payout.PayoutStatus" json:"status,omitempty"` // UNSPECIFIED 表示不过滤
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // 默认 100，最大 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_payout_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListJobsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetStatus() PayoutStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PayoutStatus_PAYOUT_STATUS_UNSPECIFIED
+}
+
+func (x *ListJobsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 任务列表响应 (最新在前)
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*PayoutItemStatus    `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_payout_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListJobsResponse) GetJobs() []*PayoutItemStatus {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 重试请求
+type RetryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ItemIds       []string               `protobuf:"bytes,3,rep,name=item_ids,json=itemIds,proto3" json:"item_ids,omitempty"`       // 要重试的项目ID (空=全部失败项)
+	GasConfig     *GasConfig             `protobuf:"bytes,4,opt,name=gas_config,json=gasConfig,proto3" json:"gas_config,omitempty"` // 新的 Gas 配置
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryRequest) Reset() {
+	*x = RetryRequest{}
+	mi := &file_payout_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryRequest) ProtoMessage() {}
+
+func (x *RetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryRequest.ProtoReflect.Descriptor instead.
+func (*RetryRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RetryRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *RetryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RetryRequest) GetItemIds() []string {
+	if x != nil {
+		return x.ItemIds
+	}
+	return nil
+}
+
+func (x *RetryRequest) GetGasConfig() *GasConfig {
+	if x != nil {
+		return x.GasConfig
+	}
+	return nil
+}
+
+// 重试响应
+type RetryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	RetryCount    int32                  `protobuf:"varint,3,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryResponse) Reset() {
+	*x = RetryResponse{}
+	mi := &file_payout_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryResponse) ProtoMessage() {}
+
+func (x *RetryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryResponse.ProtoReflect.Descriptor instead.
+func (*RetryResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RetryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RetryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RetryResponse) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+// 失败支付列表请求
+type ListFailedPayoutsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"` // 为空时列出全部
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // 默认 100，最大 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFailedPayoutsRequest) Reset() {
+	*x = ListFailedPayoutsRequest{}
+	mi := &file_payout_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFailedPayoutsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFailedPayoutsRequest) ProtoMessage() {}
+
+func (x *ListFailedPayoutsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFailedPayoutsRequest.ProtoReflect.Descriptor instead.
+func (*ListFailedPayoutsRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListFailedPayoutsRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *ListFailedPayoutsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListFailedPayoutsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 失败支付列表响应
+type ListFailedPayoutsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*FailedPayout        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFailedPayoutsResponse) Reset() {
+	*x = ListFailedPayoutsResponse{}
+	mi := &file_payout_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFailedPayoutsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFailedPayoutsResponse) ProtoMessage() {}
+
+func (x *ListFailedPayoutsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFailedPayoutsResponse.ProtoReflect.Descriptor instead.
+func (*ListFailedPayoutsResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListFailedPayoutsResponse) GetItems() []*FailedPayout {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListFailedPayoutsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 死信队列中的失败支付
+type FailedPayout struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BatchId           string                 `protobuf:"bytes,2,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	RecipientAddress  string                 `protobuf:"bytes,3,opt,name=recipient_address,json=recipientAddress,proto3" json:"recipient_address,omitempty"`
+	Amount            string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	TokenAddress      string                 `protobuf:"bytes,5,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	ChainId           uint64                 `protobuf:"varint,6,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ErrorMessage      string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Attempts          int32                  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Permanent         bool                   `protobuf:"varint,9,opt,name=permanent,proto3" json:"permanent,omitempty"` // 不可重试的错误 (需人工处理后再重试)
+	FailedAt          *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	ChainErrorCode    string                 `protobuf:"bytes,11,opt,name=chain_error_code,json=chainErrorCode,proto3" json:"chain_error_code,omitempty"` // 链上失败类别 (见 PayoutItemStatus.chain_error_code)
+	ChainErrorDetails map[string]string      `protobuf:"bytes,12,rep,name=chain_error_details,json=chainErrorDetails,proto3" json:"chain_error_details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FailedPayout) Reset() {
+	*x = FailedPayout{}
+	mi := &file_payout_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailedPayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedPayout) ProtoMessage() {}
+
+func (x *FailedPayout) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedPayout.ProtoReflect.Descriptor instead.
+func (*FailedPayout) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FailedPayout) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetRecipientAddress() string {
+	if x != nil {
+		return x.RecipientAddress
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *FailedPayout) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *FailedPayout) GetPermanent() bool {
+	if x != nil {
+		return x.Permanent
+	}
+	return false
+}
+
+func (x *FailedPayout) GetFailedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FailedAt
+	}
+	return nil
+}
+
+func (x *FailedPayout) GetChainErrorCode() string {
+	if x != nil {
+		return x.ChainErrorCode
+	}
+	return ""
+}
+
+func (x *FailedPayout) GetChainErrorDetails() map[string]string {
+	if x != nil {
+		return x.ChainErrorDetails
+	}
+	return nil
+}
+
+// 钱包库存请求
+type WalletInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"` // 为 0 时返回全部链
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletInventoryRequest) Reset() {
+	*x = WalletInventoryRequest{}
+	mi := &file_payout_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletInventoryRequest) ProtoMessage() {}
+
+func (x *WalletInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletInventoryRequest.ProtoReflect.Descriptor instead.
+func (*WalletInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *WalletInventoryRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+// 钱包库存响应
+type WalletInventoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Wallets       []*WalletInventory     `protobuf:"bytes,1,rep,name=wallets,proto3" json:"wallets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletInventoryResponse) Reset() {
+	*x = WalletInventoryResponse{}
+	mi := &file_payout_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletInventoryResponse) ProtoMessage() {}
+
+func (x *WalletInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletInventoryResponse.ProtoReflect.Descriptor instead.
+func (*WalletInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WalletInventoryResponse) GetWallets() []*WalletInventory {
+	if x != nil {
+		return x.Wallets
+	}
+	return nil
+}
+
+// 付款钱包在某条链上某种代币的库存
+type WalletInventory struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ChainId         uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ChainName       string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Address         string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	TokenAddress    string                 `protobuf:"bytes,4,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"` // 空字符串=原生代币
+	TokenSymbol     string                 `protobuf:"bytes,5,opt,name=token_symbol,json=tokenSymbol,proto3" json:"token_symbol,omitempty"`
+	Balance         string                 `protobuf:"bytes,6,opt,name=balance,proto3" json:"balance,omitempty"`                                     // 链上余额 (最小单位)
+	PendingOutflow  string                 `protobuf:"bytes,7,opt,name=pending_outflow,json=pendingOutflow,proto3" json:"pending_outflow,omitempty"` // 未完成任务的待支出金额 (原生代币含预留网络费)
+	PendingJobs     int32                  `protobuf:"varint,8,opt,name=pending_jobs,json=pendingJobs,proto3" json:"pending_jobs,omitempty"`
+	Available       string                 `protobuf:"bytes,9,opt,name=available,proto3" json:"available,omitempty"`                                       // balance - pending_outflow，可能为负
+	AvgDailyOutflow string                 `protobuf:"bytes,10,opt,name=avg_daily_outflow,json=avgDailyOutflow,proto3" json:"avg_daily_outflow,omitempty"` // 最近 7 天日均支出
+	RunwayDays      *float64               `protobuf:"fixed64,11,opt,name=runway_days,json=runwayDays,proto3,oneof" json:"runway_days,omitempty"`          // 无历史支出时不返回
+	ErrorMessage    string                 `protobuf:"bytes,12,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`            // 读取余额失败
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WalletInventory) Reset() {
+	*x = WalletInventory{}
+	mi := &file_payout_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletInventory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletInventory) ProtoMessage() {}
+
+func (x *WalletInventory) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletInventory.ProtoReflect.Descriptor instead.
+func (*WalletInventory) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WalletInventory) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *WalletInventory) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetTokenSymbol() string {
+	if x != nil {
+		return x.TokenSymbol
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetPendingOutflow() string {
+	if x != nil {
+		return x.PendingOutflow
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetPendingJobs() int32 {
+	if x != nil {
+		return x.PendingJobs
+	}
+	return 0
+}
+
+func (x *WalletInventory) GetAvailable() string {
+	if x != nil {
+		return x.Available
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetAvgDailyOutflow() string {
+	if x != nil {
+		return x.AvgDailyOutflow
+	}
+	return ""
+}
+
+func (x *WalletInventory) GetRunwayDays() float64 {
+	if x != nil && x.RunwayDays != nil {
+		return *x.RunwayDays
+	}
+	return 0
+}
+
+func (x *WalletInventory) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+// Gas 估算请求
+type EstimateGasRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromAddress   string                 `protobuf:"bytes,1,opt,name=from_address,json=fromAddress,proto3" json:"from_address,omitempty"`
+	ChainId       uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Items         []*PayoutItem          `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	UseMultisig   bool                   `protobuf:"varint,4,opt,name=use_multisig,json=useMultisig,proto3" json:"use_multisig,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EstimateGasRequest) Reset() {
+	*x = EstimateGasRequest{}
+	mi := &file_payout_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateGasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateGasRequest) ProtoMessage() {}
+
+func (x *EstimateGasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateGasRequest.ProtoReflect.Descriptor instead.
+func (*EstimateGasRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *EstimateGasRequest) GetFromAddress() string {
+	if x != nil {
+		return x.FromAddress
+	}
+	return ""
+}
+
+func (x *EstimateGasRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *EstimateGasRequest) GetItems() []*PayoutItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *EstimateGasRequest) GetUseMultisig() bool {
+	if x != nil {
+		return x.UseMultisig
+	}
+	return false
+}
+
+// Gas 估算响应
+type EstimateGasResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TotalGasEstimate string                 `protobuf:"bytes,1,opt,name=total_gas_estimate,json=totalGasEstimate,proto3" json:"total_gas_estimate,omitempty"` // 总 Gas 估算
+	GasPrice         string                 `protobuf:"bytes,2,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`                           // 当前 Gas 价格
+	TotalCostWei     string                 `protobuf:"bytes,3,opt,name=total_cost_wei,json=totalCostWei,proto3" json:"total_cost_wei,omitempty"`             // 总成本 (wei)
+	TotalCostUsd     string                 `protobuf:"bytes,4,opt,name=total_cost_usd,json=totalCostUsd,proto3" json:"total_cost_usd,omitempty"`             // 总成本 (USD)
+	Items            []*GasEstimateItem     `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *EstimateGasResponse) Reset() {
+	*x = EstimateGasResponse{}
+	mi := &file_payout_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateGasResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateGasResponse) ProtoMessage() {}
+
+func (x *EstimateGasResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateGasResponse.ProtoReflect.Descriptor instead.
+func (*EstimateGasResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *EstimateGasResponse) GetTotalGasEstimate() string {
+	if x != nil {
+		return x.TotalGasEstimate
+	}
+	return ""
+}
+
+func (x *EstimateGasResponse) GetGasPrice() string {
+	if x != nil {
+		return x.GasPrice
+	}
+	return ""
+}
+
+func (x *EstimateGasResponse) GetTotalCostWei() string {
+	if x != nil {
+		return x.TotalCostWei
+	}
+	return ""
+}
+
+func (x *EstimateGasResponse) GetTotalCostUsd() string {
+	if x != nil {
+		return x.TotalCostUsd
+	}
+	return ""
+}
+
+func (x *EstimateGasResponse) GetItems() []*GasEstimateItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// 单项 Gas 估算
+type GasEstimateItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	GasEstimate   string                 `protobuf:"bytes,2,opt,name=gas_estimate,json=gasEstimate,proto3" json:"gas_estimate,omitempty"`
+	CostWei       string                 `protobuf:"bytes,3,opt,name=cost_wei,json=costWei,proto3" json:"cost_wei,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GasEstimateItem) Reset() {
+	*x = GasEstimateItem{}
+	mi := &file_payout_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GasEstimateItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GasEstimateItem) ProtoMessage() {}
+
+func (x *GasEstimateItem) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GasEstimateItem.ProtoReflect.Descriptor instead.
+func (*GasEstimateItem) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GasEstimateItem) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *GasEstimateItem) GetGasEstimate() string {
+	if x != nil {
+		return x.GasEstimate
+	}
+	return ""
+}
+
+func (x *GasEstimateItem) GetCostWei() string {
+	if x != nil {
+		return x.CostWei
+	}
+	return ""
+}
+
+// 网络费查询请求
+type GasCostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`     // 为空时统计全部租户
+	ChainId       uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"` // 为 0 时返回全部链
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`                       // 默认 to 之前 30 天
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`                           // 默认当前时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GasCostsRequest) Reset() {
+	*x = GasCostsRequest{}
+	mi := &file_payout_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GasCostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GasCostsRequest) ProtoMessage() {}
+
+func (x *GasCostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GasCostsRequest.ProtoReflect.Descriptor instead.
+func (*GasCostsRequest) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GasCostsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GasCostsRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *GasCostsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GasCostsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+// 网络费查询响应
+type GasCostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chains        []*ChainGasCost        `protobuf:"bytes,1,rep,name=chains,proto3" json:"chains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GasCostsResponse) Reset() {
+	*x = GasCostsResponse{}
+	mi := &file_payout_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GasCostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GasCostsResponse) ProtoMessage() {}
+
+func (x *GasCostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GasCostsResponse.ProtoReflect.Descriptor instead.
+func (*GasCostsResponse) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GasCostsResponse) GetChains() []*ChainGasCost {
+	if x != nil {
+		return x.Chains
+	}
+	return nil
+}
+
+// 一条链的实际网络费 (金额为原生代币最小单位)
+type ChainGasCost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	NativeToken   string                 `protobuf:"bytes,3,opt,name=native_token,json=nativeToken,proto3" json:"native_token,omitempty"`
+	Decimals      int32                  `protobuf:"varint,4,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	TotalGasFee   string                 `protobuf:"bytes,5,opt,name=total_gas_fee,json=totalGasFee,proto3" json:"total_gas_fee,omitempty"`
+	Payouts       int64                  `protobuf:"varint,6,opt,name=payouts,proto3" json:"payouts,omitempty"`
+	Transactions  int64                  `protobuf:"varint,7,opt,name=transactions,proto3" json:"transactions,omitempty"`
+	Days          []*DailyGasCost        `protobuf:"bytes,8,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChainGasCost) Reset() {
+	*x = ChainGasCost{}
+	mi := &file_payout_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChainGasCost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainGasCost) ProtoMessage() {}
+
+func (x *ChainGasCost) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainGasCost.ProtoReflect.Descriptor instead.
+func (*ChainGasCost) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ChainGasCost) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *ChainGasCost) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *ChainGasCost) GetNativeToken() string {
+	if x != nil {
+		return x.NativeToken
+	}
+	return ""
+}
+
+func (x *ChainGasCost) GetDecimals() int32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *ChainGasCost) GetTotalGasFee() string {
+	if x != nil {
+		return x.TotalGasFee
+	}
+	return ""
+}
+
+func (x *ChainGasCost) GetPayouts() int64 {
+	if x != nil {
+		return x.Payouts
+	}
+	return 0
+}
+
+func (x *ChainGasCost) GetTransactions() int64 {
+	if x != nil {
+		return x.Transactions
+	}
+	return 0
+}
+
+func (x *ChainGasCost) GetDays() []*DailyGasCost {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+// 一个 UTC 日的实际网络费
+type DailyGasCost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	GasFee        string                 `protobuf:"bytes,2,opt,name=gas_fee,json=gasFee,proto3" json:"gas_fee,omitempty"`
+	Payouts       int64                  `protobuf:"varint,3,opt,name=payouts,proto3" json:"payouts,omitempty"`
+	Transactions  int64                  `protobuf:"varint,4,opt,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyGasCost) Reset() {
+	*x = DailyGasCost{}
+	mi := &file_payout_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyGasCost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyGasCost) ProtoMessage() {}
+
+func (x *DailyGasCost) ProtoReflect() protoreflect.Message {
+	mi := &file_payout_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyGasCost.ProtoReflect.Descriptor instead.
+func (*DailyGasCost) Descriptor() ([]byte, []int) {
+	return file_payout_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DailyGasCost) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *DailyGasCost) GetGasFee() string {
+	if x != nil {
+		return x.GasFee
+	}
+	return ""
+}
+
+func (x *DailyGasCost) GetPayouts() int64 {
+	if x != nil {
+		return x.Payouts
+	}
+	return 0
+}
+
+func (x *DailyGasCost) GetTransactions() int64 {
+	if x != nil {
+		return x.Transactions
+	}
+	return 0
+}
+
+var File_payout_proto protoreflect.FileDescriptor
+
+const file_payout_proto_rawDesc = "" +
+	"\n" +
+	"\fpayout.proto\x12\x06payout\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd6\x02\n" +
+	"\n" +
+	"PayoutItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x11recipient_address\x18\x02 \x01(\tR\x10recipientAddress\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12#\n" +
+	"\rtoken_address\x18\x04 \x01(\tR\ftokenAddress\x12!\n" +
+	"\ftoken_symbol\x18\x05 \x01(\tR\vtokenSymbol\x12%\n" +
+	"\x0etoken_decimals\x18\x06 \x01(\rR\rtokenDecimals\x12\x1f\n" +
+	"\vvendor_name\x18\a \x01(\tR\n" +
+	"vendorName\x12\x1b\n" +
+	"\tvendor_id\x18\b \x01(\tR\bvendorId\x12\x12\n" +
+	"\x04memo\x18\t \x01(\tR\x04memo\x12\x19\n" +
+	"\btoken_id\x18\n" +
+	" \x01(\tR\atokenId\x12\x17\n" +
+	"\amax_fee\x18\v \x01(\tR\x06maxFee\"\xf6\a\n" +
+	"\x12BatchPayoutRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\ffrom_address\x18\x03 \x01(\tR\vfromAddress\x12\x19\n" +
+	"\bchain_id\x18\x04 \x01(\x04R\achainId\x12(\n" +
+	"\x05items\x18\x05 \x03(\v2\x12.payout.PayoutItemR\x05items\x12?\n" +
+	"\x0fmultisig_config\x18\x06 \x01(\v2\x16.payout.MultiSigConfigR\x0emultisigConfig\x120\n" +
+	"\n" +
+	"gas_config\x18\a \x01(\v2\x11.payout.GasConfigR\tgasConfig\x12?\n" +
+	"\x0fsecurity_config\x18\b \x01(\v2\x16.payout.SecurityConfigR\x0esecurityConfig\x12\x1a\n" +
+	"\bpriority\x18\t \x01(\tR\bpriority\x12*\n" +
+	"\x11use_smart_account\x18\n" +
+	" \x01(\bR\x0fuseSmartAccount\x12#\n" +
+	"\rallow_partial\x18\v \x01(\bR\fallowPartial\x12'\n" +
+	"\x0fidempotency_key\x18\f \x01(\tR\x0eidempotencyKey\x12\x1f\n" +
+	"\vwebhook_url\x18\r \x01(\tR\n" +
+	"webhookUrl\x12%\n" +
+	"\x0ewebhook_secret\x18\x0e \x01(\tR\rwebhookSecret\x12-\n" +
+	"\x12screening_override\x18\x0f \x01(\bR\x11screeningOverride\x12:\n" +
+	"\x19screening_override_reason\x18\x10 \x01(\tR\x17screeningOverrideReason\x12\x1a\n" +
+	"\bsimulate\x18\x11 \x01(\bR\bsimulate\x124\n" +
+	"\x16webhook_schema_version\x18\x12 \x01(\x05R\x14webhookSchemaVersion\x129\n" +
+	"\n" +
+	"execute_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x12&\n" +
+	"\x06permit\x18\x14 \x01(\v2\x0e.payout.PermitR\x06permit\x12!\n" +
+	"\fuse_treasury\x18\x15 \x01(\bR\vuseTreasury\x12 \n" +
+	"\x04swap\x18\x16 \x01(\v2\f.payout.SwapR\x04swap\x12&\n" +
+	"\x0fsource_chain_id\x18\x17 \x01(\x04R\rsourceChainId\x12%\n" +
+	"\x0eduplicate_mode\x18\x18 \x01(\tR\rduplicateMode\"O\n" +
+	"\x04Swap\x12\x1d\n" +
+	"\n" +
+	"sell_token\x18\x01 \x01(\tR\tsellToken\x12(\n" +
+	"\x10max_slippage_bps\x18\x02 \x01(\rR\x0emaxSlippageBps\"z\n" +
+	"\x06Permit\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x1a\n" +
+	"\bdeadline\x18\x03 \x01(\x03R\bdeadline\x12\f\n" +
+	"\x01v\x18\x04 \x01(\rR\x01v\x12\f\n" +
+	"\x01r\x18\x05 \x01(\tR\x01r\x12\f\n" +
+	"\x01s\x18\x06 \x01(\tR\x01s\"\x85\x01\n" +
+	"\x0eMultiSigConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12!\n" +
+	"\fsafe_address\x18\x02 \x01(\tR\vsafeAddress\x12\x1c\n" +
+	"\tthreshold\x18\x03 \x01(\rR\tthreshold\x12\x18\n" +
+	"\asigners\x18\x04 \x03(\tR\asigners\"\xaf\x01\n" +
+	"\tGasConfig\x12%\n" +
+	"\x0fmax_fee_per_gas\x18\x01 \x01(\tR\fmaxFeePerGas\x12(\n" +
+	"\x10max_priority_fee\x18\x02 \x01(\tR\x0emaxPriorityFee\x120\n" +
+	"\x14gas_limit_multiplier\x18\x03 \x01(\x04R\x12gasLimitMultiplier\x12\x1f\n" +
+	"\vauto_adjust\x18\x04 \x01(\bR\n" +
+	"autoAdjust\"e\n" +
+	"\x0eSecurityConfig\x12\x1f\n" +
+	"\vsigned_hash\x18\x01 \x01(\tR\n" +
+	"signedHash\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x14\n" +
+	"\x05nonce\x18\x03 \x01(\tR\x05nonce\"\xd6\x04\n" +
+	"\x13BatchPayoutResponse\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.payout.BatchStatusR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12:\n" +
+	"\x19estimated_completion_time\x18\x04 \x01(\x03R\x17estimatedCompletionTime\x12,\n" +
+	"\x12estimated_gas_cost\x18\x05 \x01(\tR\x10estimatedGasCost\x120\n" +
+	"\brejected\x18\x06 \x03(\v2\x14.payout.RejectedItemR\brejected\x12\x18\n" +
+	"\atestnet\x18\a \x01(\bR\atestnet\x12\x1a\n" +
+	"\breplayed\x18\b \x01(\bR\breplayed\x12#\n" +
+	"\rmanifest_hash\x18\t \x01(\tR\fmanifestHash\x12-\n" +
+	"\x12manifest_signature\x18\n" +
+	" \x01(\tR\x11manifestSignature\x12'\n" +
+	"\x0fmanifest_signer\x18\v \x01(\tR\x0emanifestSigner\x12\x1c\n" +
+	"\tsimulated\x18\f \x01(\bR\tsimulated\x129\n" +
+	"\n" +
+	"execute_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x125\n" +
+	"\n" +
+	"duplicates\x18\x0e \x03(\v2\x15.payout.DuplicateItemR\n" +
+	"duplicates\"?\n" +
+	"\fRejectedItem\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"g\n" +
+	"\rDuplicateItem\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12!\n" +
+	"\fduplicate_of\x18\x02 \x01(\tR\vduplicateOf\x12\x1a\n" +
+	"\bdecision\x18\x03 \x01(\tR\bdecision\"H\n" +
+	"\x12BatchStatusRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xb7\x05\n" +
+	"\x13BatchStatusResponse\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.payout.BatchStatusR\x06status\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\x12'\n" +
+	"\x0fcompleted_count\x18\x04 \x01(\x05R\x0ecompletedCount\x12!\n" +
+	"\ffailed_count\x18\x05 \x01(\x05R\vfailedCount\x12#\n" +
+	"\rpending_count\x18\x06 \x01(\x05R\fpendingCount\x12.\n" +
+	"\x05items\x18\a \x03(\v2\x18.payout.PayoutItemStatusR\x05items\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12#\n" +
+	"\rmanifest_hash\x18\n" +
+	" \x01(\tR\fmanifestHash\x129\n" +
+	"\n" +
+	"execute_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x12#\n" +
+	"\rerror_message\x18\f \x01(\tR\ferrorMessage\x12'\n" +
+	"\x0fcancelled_count\x18\r \x01(\x05R\x0ecancelledCount\x12\x1f\n" +
+	"\vapproved_by\x18\x0e \x03(\tR\n" +
+	"approvedBy\x12-\n" +
+	"\x12approvals_required\x18\x0f \x01(\x05R\x11approvalsRequired\x12\"\n" +
+	"\rtotal_gas_fee\x18\x10 \x01(\tR\vtotalGasFee\"\xd2\x05\n" +
+	"\x10PayoutItemStatus\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x11recipient_address\x18\x02 \x01(\tR\x10recipientAddress\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12,\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x14.payout.PayoutStatusR\x06status\x12\x17\n" +
+	"\atx_hash\x18\x05 \x01(\tR\x06txHash\x12$\n" +
+	"\rconfirmations\x18\x06 \x01(\x04R\rconfirmations\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x12\x1f\n" +
+	"\vretry_count\x18\b \x01(\x05R\n" +
+	"retryCount\x12\x19\n" +
+	"\bbatch_id\x18\t \x01(\tR\abatchId\x12\x19\n" +
+	"\bchain_id\x18\n" +
+	" \x01(\x04R\achainId\x12#\n" +
+	"\rtoken_address\x18\v \x01(\tR\ftokenAddress\x129\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x17\n" +
+	"\agas_fee\x18\r \x01(\tR\x06gasFee\x12\x19\n" +
+	"\bgas_used\x18\x0e \x01(\x04R\agasUsed\x12\x1b\n" +
+	"\tgas_price\x18\x0f \x01(\tR\bgasPrice\x12(\n" +
+	"\x10chain_error_code\x18\x10 \x01(\tR\x0echainErrorCode\x12_\n" +
+	"\x13chain_error_details\x18\x11 \x03(\v2/.payout.PayoutItemStatus.ChainErrorDetailsEntryR\x11chainErrorDetails\x1aD\n" +
+	"\x16ChainErrorDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x81\x02\n" +
+	"\x0ePayoutProgress\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\x12,\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x14.payout.PayoutStatusR\x06status\x12\x17\n" +
+	"\atx_hash\x18\x04 \x01(\tR\x06txHash\x12$\n" +
+	"\rconfirmations\x18\x05 \x01(\x04R\rconfirmations\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12)\n" +
+	"\x10progress_percent\x18\a \x01(\x05R\x0fprogressPercent\"y\n" +
+	"\x12CancelBatchRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x17\n" +
+	"\ajob_ids\x18\x04 \x03(\tR\x06jobIds\"\x81\x02\n" +
+	"\x13CancelBatchResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x0fcancelled_count\x18\x03 \x01(\x05R\x0ecancelledCount\x126\n" +
+	"\x17already_processed_count\x18\x04 \x01(\x05R\x15alreadyProcessedCount\x12*\n" +
+	"\x11cancelled_job_ids\x18\x05 \x03(\tR\x0fcancelledJobIds\x12)\n" +
+	"\x11in_flight_job_ids\x18\x06 \x03(\tR\x0einFlightJobIds\"\xa1\x01\n" +
+	"\x0fListJobsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12,\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x14.payout.PayoutStatusR\x06status\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"V\n" +
+	"\x10ListJobsResponse\x12,\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x18.payout.PayoutItemStatusR\x04jobs\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\x8f\x01\n" +
+	"\fRetryRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\bitem_ids\x18\x03 \x03(\tR\aitemIds\x120\n" +
+	"\n" +
+	"gas_config\x18\x04 \x01(\v2\x11.payout.GasConfigR\tgasConfig\"d\n" +
+	"\rRetryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vretry_count\x18\x03 \x01(\x05R\n" +
+	"retryCount\"c\n" +
+	"\x18ListFailedPayoutsRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"]\n" +
+	"\x19ListFailedPayoutsResponse\x12*\n" +
+	"\x05items\x18\x01 \x03(\v2\x14.payout.FailedPayoutR\x05items\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xa3\x04\n" +
+	"\fFailedPayout\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12+\n" +
+	"\x11recipient_address\x18\x03 \x01(\tR\x10recipientAddress\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12#\n" +
+	"\rtoken_address\x18\x05 \x01(\tR\ftokenAddress\x12\x19\n" +
+	"\bchain_id\x18\x06 \x01(\x04R\achainId\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1c\n" +
+	"\tpermanent\x18\t \x01(\bR\tpermanent\x127\n" +
+	"\tfailed_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\bfailedAt\x12(\n" +
+	"\x10chain_error_code\x18\v \x01(\tR\x0echainErrorCode\x12[\n" +
+	"\x13chain_error_details\x18\f \x03(\v2+.payout.FailedPayout.ChainErrorDetailsEntryR\x11chainErrorDetails\x1aD\n" +
+	"\x16ChainErrorDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"3\n" +
+	"\x16WalletInventoryRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\"L\n" +
+	"\x17WalletInventoryResponse\x121\n" +
+	"\awallets\x18\x01 \x03(\v2\x17.payout.WalletInventoryR\awallets\"\xb8\x03\n" +
+	"\x0fWalletInventory\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12#\n" +
+	"\rtoken_address\x18\x04 \x01(\tR\ftokenAddress\x12!\n" +
+	"\ftoken_symbol\x18\x05 \x01(\tR\vtokenSymbol\x12\x18\n" +
+	"\abalance\x18\x06 \x01(\tR\abalance\x12'\n" +
+	"\x0fpending_outflow\x18\a \x01(\tR\x0ependingOutflow\x12!\n" +
+	"\fpending_jobs\x18\b \x01(\x05R\vpendingJobs\x12\x1c\n" +
+	"\tavailable\x18\t \x01(\tR\tavailable\x12*\n" +
+	"\x11avg_daily_outflow\x18\n" +
+	" \x01(\tR\x0favgDailyOutflow\x12$\n" +
+	"\vrunway_days\x18\v \x01(\x01H\x00R\n" +
+	"runwayDays\x88\x01\x01\x12#\n" +
+	"\rerror_message\x18\f \x01(\tR\ferrorMessageB\x0e\n" +
+	"\f_runway_days\"\x9f\x01\n" +
+	"\x12EstimateGasRequest\x12!\n" +
+	"\ffrom_address\x18\x01 \x01(\tR\vfromAddress\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\x12(\n" +
+	"\x05items\x18\x03 \x03(\v2\x12.payout.PayoutItemR\x05items\x12!\n" +
+	"\fuse_multisig\x18\x04 \x01(\bR\vuseMultisig\"\xdb\x01\n" +
+	"\x13EstimateGasResponse\x12,\n" +
+	"\x12total_gas_estimate\x18\x01 \x01(\tR\x10totalGasEstimate\x12\x1b\n" +
+	"\tgas_price\x18\x02 \x01(\tR\bgasPrice\x12$\n" +
+	"\x0etotal_cost_wei\x18\x03 \x01(\tR\ftotalCostWei\x12$\n" +
+	"\x0etotal_cost_usd\x18\x04 \x01(\tR\ftotalCostUsd\x12-\n" +
+	"\x05items\x18\x05 \x03(\v2\x17.payout.GasEstimateItemR\x05items\"h\n" +
+	"\x0fGasEstimateItem\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12!\n" +
+	"\fgas_estimate\x18\x02 \x01(\tR\vgasEstimate\x12\x19\n" +
+	"\bcost_wei\x18\x03 \x01(\tR\acostWei\"\xa1\x01\n" +
+	"\x0fGasCostsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"@\n" +
+	"\x10GasCostsResponse\x12,\n" +
+	"\x06chains\x18\x01 \x03(\v2\x14.payout.ChainGasCostR\x06chains\"\x93\x02\n" +
+	"\fChainGasCost\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12!\n" +
+	"\fnative_token\x18\x03 \x01(\tR\vnativeToken\x12\x1a\n" +
+	"\bdecimals\x18\x04 \x01(\x05R\bdecimals\x12\"\n" +
+	"\rtotal_gas_fee\x18\x05 \x01(\tR\vtotalGasFee\x12\x18\n" +
+	"\apayouts\x18\x06 \x01(\x03R\apayouts\x12\"\n" +
+	"\ftransactions\x18\a \x01(\x03R\ftransactions\x12(\n" +
+	"\x04days\x18\b \x03(\v2\x14.payout.DailyGasCostR\x04days\"\x95\x01\n" +
+	"\fDailyGasCost\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x17\n" +
+	"\agas_fee\x18\x02 \x01(\tR\x06gasFee\x12\x18\n" +
+	"\apayouts\x18\x03 \x01(\x03R\apayouts\x12\"\n" +
+	"\ftransactions\x18\x04 \x01(\x03R\ftransactions*\xb8\x02\n" +
+	"\vBatchStatus\x12\x1c\n" +
+	"\x18BATCH_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13BATCH_STATUS_QUEUED\x10\x01\x12\x1b\n" +
+	"\x17BATCH_STATUS_PROCESSING\x10\x02\x12$\n" +
+	" BATCH_STATUS_AWAITING_SIGNATURES\x10\x03\x12\x1a\n" +
+	"\x16BATCH_STATUS_COMPLETED\x10\x04\x12\x1f\n" +
+	"\x1bBATCH_STATUS_PARTIAL_FAILED\x10\x05\x12\x17\n" +
+	"\x13BATCH_STATUS_FAILED\x10\x06\x12\x1a\n" +
+	"\x16BATCH_STATUS_CANCELLED\x10\a\x12\x1a\n" +
+	"\x16BATCH_STATUS_SCHEDULED\x10\b\x12!\n" +
+	"\x1dBATCH_STATUS_PENDING_APPROVAL\x10\t*\xf3\x01\n" +
+	"\fPayoutStatus\x12\x1d\n" +
+	"\x19PAYOUT_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15PAYOUT_STATUS_PENDING\x10\x01\x12\x1b\n" +
+	"\x17PAYOUT_STATUS_SUBMITTED\x10\x02\x12\x1c\n" +
+	"\x18PAYOUT_STATUS_CONFIRMING\x10\x03\x12\x1b\n" +
+	"\x17PAYOUT_STATUS_CONFIRMED\x10\x04\x12\x18\n" +
+	"\x14PAYOUT_STATUS_FAILED\x10\x05\x12\x1a\n" +
+	"\x16PAYOUT_STATUS_RETRYING\x10\x06\x12\x1b\n" +
+	"\x17PAYOUT_STATUS_CANCELLED\x10\a2\xd2\x06\n" +
+	"\rPayoutService\x12L\n" +
+	"\x11SubmitBatchPayout\x12\x1a.payout.BatchPayoutRequest\x1a\x1b.payout.BatchPayoutResponse\x12I\n" +
+	"\x0eGetBatchStatus\x12\x1a.payout.BatchStatusRequest\x1a\x1b.payout.BatchStatusResponse\x12L\n" +
+	"\x14StreamPayoutProgress\x12\x1a.payout.BatchStatusRequest\x1a\x16.payout.PayoutProgress0\x01\x12L\n" +
+	"\x11CancelBatchPayout\x12\x1a.payout.CancelBatchRequest\x1a\x1b.payout.CancelBatchResponse\x12O\n" +
+	"\x14UpdateScheduledBatch\x12\x1a.payout.BatchPayoutRequest\x1a\x1b.payout.BatchPayoutResponse\x12=\n" +
+	"\bListJobs\x12\x17.payout.ListJobsRequest\x1a\x18.payout.ListJobsResponse\x12A\n" +
+	"\x12RetryFailedPayouts\x12\x14.payout.RetryRequest\x1a\x15.payout.RetryResponse\x12X\n" +
+	"\x11ListFailedPayouts\x12 .payout.ListFailedPayoutsRequest\x1a!.payout.ListFailedPayoutsResponse\x12U\n" +
+	"\x12GetWalletInventory\x12\x1e.payout.WalletInventoryRequest\x1a\x1f.payout.WalletInventoryResponse\x12F\n" +
+	"\vEstimateGas\x12\x1a.payout.EstimateGasRequest\x1a\x1b.payout.EstimateGasResponse\x12@\n" +
+	"\vGetGasCosts\x12\x17.payout.GasCostsRequest\x1a\x18.payout.GasCostsResponseB.Z,github.com/protocol-bank/payout-engine/pb;pbb\x06proto3"
+
+var (
+	file_payout_proto_rawDescOnce sync.Once
+	file_payout_proto_rawDescData []byte
+)
+
+func file_payout_proto_rawDescGZIP() []byte {
+	file_payout_proto_rawDescOnce.Do(func() {
+		file_payout_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_payout_proto_rawDesc), len(file_payout_proto_rawDesc)))
+	})
+	return file_payout_proto_rawDescData
+}
+
+var file_payout_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_payout_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_payout_proto_goTypes = []any{
+	(BatchStatus)(0),                  // 0: payout.BatchStatus
+	(PayoutStatus)(0),                 // 1: payout.PayoutStatus
+	(*PayoutItem)(nil),                // 2: payout.PayoutItem
+	(*BatchPayoutRequest)(nil),        // 3: payout.BatchPayoutRequest
+	(*Swap)(nil),                      // 4: payout.Swap
+	(*Permit)(nil),                    // 5: payout.Permit
+	(*MultiSigConfig)(nil),            // 6: payout.MultiSigConfig
+	(*GasConfig)(nil),                 // 7: payout.GasConfig
+	(*SecurityConfig)(nil),            // 8: payout.SecurityConfig
+	(*BatchPayoutResponse)(nil),       // 9: payout.BatchPayoutResponse
+	(*RejectedItem)(nil),              // 10: payout.RejectedItem
+	(*DuplicateItem)(nil),             // 11: payout.DuplicateItem
+	(*BatchStatusRequest)(nil),        // 12: payout.BatchStatusRequest
+	(*BatchStatusResponse)(nil),       // 13: payout.BatchStatusResponse
+	(*PayoutItemStatus)(nil),          // 14: payout.PayoutItemStatus
+	(*PayoutProgress)(nil),            // 15: payout.PayoutProgress
+	(*CancelBatchRequest)(nil),        // 16: payout.CancelBatchRequest
+	(*CancelBatchResponse)(nil),       // 17: payout.CancelBatchResponse
+	(*ListJobsRequest)(nil),           // 18: payout.ListJobsRequest
+	(*ListJobsResponse)(nil),          // 19: payout.ListJobsResponse
+	(*RetryRequest)(nil),              // 20: payout.RetryRequest
+	(*RetryResponse)(nil),             // 21: payout.RetryResponse
+	(*ListFailedPayoutsRequest)(nil),  // 22: payout.ListFailedPayoutsRequest
+	(*ListFailedPayoutsResponse)(nil), // 23: payout.ListFailedPayoutsResponse
+	(*FailedPayout)(nil),              // 24: payout.FailedPayout
+	(*WalletInventoryRequest)(nil),    // 25: payout.WalletInventoryRequest
+	(*WalletInventoryResponse)(nil),   // 26: payout.WalletInventoryResponse
+	(*WalletInventory)(nil),           // 27: payout.WalletInventory
+	(*EstimateGasRequest)(nil),        // 28: payout.EstimateGasRequest
+	(*EstimateGasResponse)(nil),       // 29: payout.EstimateGasResponse
+	(*GasEstimateItem)(nil),           // 30: payout.GasEstimateItem
+	(*GasCostsRequest)(nil),           // 31: payout.GasCostsRequest
+	(*GasCostsResponse)(nil),          // 32: payout.GasCostsResponse
+	(*ChainGasCost)(nil),              // 33: payout.ChainGasCost
+	(*DailyGasCost)(nil),              // 34: payout.DailyGasCost
+	nil,                               // 35: payout.PayoutItemStatus.ChainErrorDetailsEntry
+	nil,                               // 36: payout.FailedPayout.ChainErrorDetailsEntry
+	(*timestamppb.Timestamp)(nil),     // 37: google.protobuf.Timestamp
+}
+var file_payout_proto_depIdxs = []int32{
+	2,  // 0: payout.BatchPayoutRequest.items:type_name -> payout.PayoutItem
+	6,  // 1: payout.BatchPayoutRequest.multisig_config:type_name -> payout.MultiSigConfig
+	7,  // 2: payout.BatchPayoutRequest.gas_config:type_name -> payout.GasConfig
+	8,  // 3: payout.BatchPayoutRequest.security_config:type_name -> payout.SecurityConfig
+	37, // 4: payout.BatchPayoutRequest.execute_at:type_name -> google.protobuf.Timestamp
+	5,  // 5: payout.BatchPayoutRequest.permit:type_name -> payout.Permit
+	4,  // 6: payout.BatchPayoutRequest.swap:type_name -> payout.Swap
+	0,  // 7: payout.BatchPayoutResponse.status:type_name -> payout.BatchStatus
+	10, // 8: payout.BatchPayoutResponse.rejected:type_name -> payout.RejectedItem
+	37, // 9: payout.BatchPayoutResponse.execute_at:type_name -> google.protobuf.Timestamp
+	11, // 10: payout.BatchPayoutResponse.duplicates:type_name -> payout.DuplicateItem
+	0,  // 11: payout.BatchStatusResponse.status:type_name -> payout.BatchStatus
+	14, // 12: payout.BatchStatusResponse.items:type_name -> payout.PayoutItemStatus
+	37, // 13: payout.BatchStatusResponse.created_at:type_name -> google.protobuf.Timestamp
+	37, // 14: payout.BatchStatusResponse.updated_at:type_name -> google.protobuf.Timestamp
+	37, // 15: payout.BatchStatusResponse.execute_at:type_name -> google.protobuf.Timestamp
+	1,  // 16: payout.PayoutItemStatus.status:type_name -> payout.PayoutStatus
+	37, // 17: payout.PayoutItemStatus.updated_at:type_name -> google.protobuf.Timestamp
+	35, // 18: payout.PayoutItemStatus.chain_error_details:type_name -> payout.PayoutItemStatus.ChainErrorDetailsEntry
+	1,  // 19: payout.PayoutProgress.status:type_name -> payout.PayoutStatus
+	1,  // 20: payout.ListJobsRequest.status:type_name -> payout.PayoutStatus
+	14, // 21: payout.ListJobsResponse.jobs:type_name -> payout.PayoutItemStatus
+	7,  // 22: payout.RetryRequest.gas_config:type_name -> payout.GasConfig
+	24, // 23: payout.ListFailedPayoutsResponse.items:type_name -> payout.FailedPayout
+	37, // 24: payout.FailedPayout.failed_at:type_name -> google.protobuf.Timestamp
+	36, // 25: payout.FailedPayout.chain_error_details:type_name -> payout.FailedPayout.ChainErrorDetailsEntry
+	27, // 26: payout.WalletInventoryResponse.wallets:type_name -> payout.WalletInventory
+	2,  // 27: payout.EstimateGasRequest.items:type_name -> payout.PayoutItem
+	30, // 28: payout.EstimateGasResponse.items:type_name -> payout.GasEstimateItem
+	37, // 29: payout.GasCostsRequest.from:type_name -> google.protobuf.Timestamp
+	37, // 30: payout.GasCostsRequest.to:type_name -> google.protobuf.Timestamp
+	33, // 31: payout.GasCostsResponse.chains:type_name -> payout.ChainGasCost
+	34, // 32: payout.ChainGasCost.days:type_name -> payout.DailyGasCost
+	37, // 33: payout.DailyGasCost.date:type_name -> google.protobuf.Timestamp
+	3,  // 34: payout.PayoutService.SubmitBatchPayout:input_type -> payout.BatchPayoutRequest
+	12, // 35: payout.PayoutService.GetBatchStatus:input_type -> payout.BatchStatusRequest
+	12, // 36: payout.PayoutService.StreamPayoutProgress:input_type -> payout.BatchStatusRequest
+	16, // 37: payout.PayoutService.CancelBatchPayout:input_type -> payout.CancelBatchRequest
+	3,  // 38: payout.PayoutService.UpdateScheduledBatch:input_type -> payout.BatchPayoutRequest
+	18, // 39: payout.PayoutService.ListJobs:input_type -> payout.ListJobsRequest
+	20, // 40: payout.PayoutService.RetryFailedPayouts:input_type -> payout.RetryRequest
+	22, // 41: payout.PayoutService.ListFailedPayouts:input_type -> payout.ListFailedPayoutsRequest
+	25, // 42: payout.PayoutService.GetWalletInventory:input_type -> payout.WalletInventoryRequest
+	28, // 43: payout.PayoutService.EstimateGas:input_type -> payout.EstimateGasRequest
+	31, // 44: payout.PayoutService.GetGasCosts:input_type -> payout.GasCostsRequest
+	9,  // 45: payout.PayoutService.SubmitBatchPayout:output_type -> payout.BatchPayoutResponse
+	13, // 46: payout.PayoutService.GetBatchStatus:output_type -> payout.BatchStatusResponse
+	15, // 47: payout.PayoutService.StreamPayoutProgress:output_type -> payout.PayoutProgress
+	17, // 48: payout.PayoutService.CancelBatchPayout:output_type -> payout.CancelBatchResponse
+	9,  // 49: payout.PayoutService.UpdateScheduledBatch:output_type -> payout.BatchPayoutResponse
+	19, // 50: payout.PayoutService.ListJobs:output_type -> payout.ListJobsResponse
+	21, // 51: payout.PayoutService.RetryFailedPayouts:output_type -> payout.RetryResponse
+	23, // 52: payout.PayoutService.ListFailedPayouts:output_type -> payout.ListFailedPayoutsResponse
+	26, // 53: payout.PayoutService.GetWalletInventory:output_type -> payout.WalletInventoryResponse
+	29, // 54: payout.PayoutService.EstimateGas:output_type -> payout.EstimateGasResponse
+	32, // 55: payout.PayoutService.GetGasCosts:output_type -> payout.GasCostsResponse
+	45, // [45:56] is the sub-list for method output_type
+	34, // [34:45] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
+}
+
+func init() { file_payout_proto_init() }
+func file_payout_proto_init() {
+	if File_payout_proto != nil {
+		return
+	}
+	file_payout_proto_msgTypes[25].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payout_proto_rawDesc), len(file_payout_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   35,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_payout_proto_goTypes,
+		DependencyIndexes: file_payout_proto_depIdxs,
+		EnumInfos:         file_payout_proto_enumTypes,
+		MessageInfos:      file_payout_proto_msgTypes,
+	}.Build()
+	File_payout_proto = out.File
+	file_payout_proto_goTypes = nil
+	file_payout_proto_depIdxs = nil
+}
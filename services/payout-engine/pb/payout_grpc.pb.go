@@ -0,0 +1,531 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: payout.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PayoutService_SubmitBatchPayout_FullMethodName    = "/payout.PayoutService/SubmitBatchPayout"
+	PayoutService_GetBatchStatus_FullMethodName       = "/payout.PayoutService/GetBatchStatus"
+	PayoutService_StreamPayoutProgress_FullMethodName = "/payout.PayoutService/StreamPayoutProgress"
+	PayoutService_CancelBatchPayout_FullMethodName    = "/payout.PayoutService/CancelBatchPayout"
+	PayoutService_UpdateScheduledBatch_FullMethodName = "/payout.PayoutService/UpdateScheduledBatch"
+	PayoutService_ListJobs_FullMethodName             = "/payout.PayoutService/ListJobs"
+	PayoutService_RetryFailedPayouts_FullMethodName   = "/payout.PayoutService/RetryFailedPayouts"
+	PayoutService_ListFailedPayouts_FullMethodName    = "/payout.PayoutService/ListFailedPayouts"
+	PayoutService_GetWalletInventory_FullMethodName   = "/payout.PayoutService/GetWalletInventory"
+	PayoutService_EstimateGas_FullMethodName          = "/payout.PayoutService/EstimateGas"
+	PayoutService_GetGasCosts_FullMethodName          = "/payout.PayoutService/GetGasCosts"
+)
+
+// PayoutServiceClient is the client API for PayoutService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Payout Engine Service - 批量支付引擎
+type PayoutServiceClient interface {
+	// 提交批量支付任务
+	SubmitBatchPayout(ctx context.Context, in *BatchPayoutRequest, opts ...grpc.CallOption) (*BatchPayoutResponse, error)
+	// 查询批量支付状态
+	GetBatchStatus(ctx context.Context, in *BatchStatusRequest, opts ...grpc.CallOption) (*BatchStatusResponse, error)
+	// 流式获取支付进度
+	StreamPayoutProgress(ctx context.Context, in *BatchStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PayoutProgress], error)
+	// 取消批量支付 (定时批次在执行前整批取消)；指定 job_ids 时只取消其中尚未发送的支付项
+	CancelBatchPayout(ctx context.Context, in *CancelBatchRequest, opts ...grpc.CallOption) (*CancelBatchResponse, error)
+	// 修改尚未到执行时间的定时批次 (按 user_id + batch_id 整体替换支付项和执行时间)
+	UpdateScheduledBatch(ctx context.Context, in *BatchPayoutRequest, opts ...grpc.CallOption) (*BatchPayoutResponse, error)
+	// 列出用户的支付任务
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	// 重试失败的支付 (从死信队列重新入队)
+	RetryFailedPayouts(ctx context.Context, in *RetryRequest, opts ...grpc.CallOption) (*RetryResponse, error)
+	// 列出死信队列中的失败支付
+	ListFailedPayouts(ctx context.Context, in *ListFailedPayoutsRequest, opts ...grpc.CallOption) (*ListFailedPayoutsResponse, error)
+	// 查询付款钱包余额、待支出承诺和可用天数预测
+	GetWalletInventory(ctx context.Context, in *WalletInventoryRequest, opts ...grpc.CallOption) (*WalletInventoryResponse, error)
+	// 估算 Gas 费用
+	EstimateGas(ctx context.Context, in *EstimateGasRequest, opts ...grpc.CallOption) (*EstimateGasResponse, error)
+	// 查询已上链交易的实际网络费 (按链、按 UTC 日汇总)，供财务对账
+	GetGasCosts(ctx context.Context, in *GasCostsRequest, opts ...grpc.CallOption) (*GasCostsResponse, error)
+}
+
+type payoutServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPayoutServiceClient(cc grpc.ClientConnInterface) PayoutServiceClient {
+	return &payoutServiceClient{cc}
+}
+
+func (c *payoutServiceClient) SubmitBatchPayout(ctx context.Context, in *BatchPayoutRequest, opts ...grpc.CallOption) (*BatchPayoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchPayoutResponse)
+	err := c.cc.Invoke(ctx, PayoutService_SubmitBatchPayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) GetBatchStatus(ctx context.Context, in *BatchStatusRequest, opts ...grpc.CallOption) (*BatchStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchStatusResponse)
+	err := c.cc.Invoke(ctx, PayoutService_GetBatchStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) StreamPayoutProgress(ctx context.Context, in *BatchStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PayoutProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PayoutService_ServiceDesc.Streams[0], PayoutService_StreamPayoutProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchStatusRequest, PayoutProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PayoutService_StreamPayoutProgressClient = grpc.ServerStreamingClient[PayoutProgress]
+
+func (c *payoutServiceClient) CancelBatchPayout(ctx context.Context, in *CancelBatchRequest, opts ...grpc.CallOption) (*CancelBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelBatchResponse)
+	err := c.cc.Invoke(ctx, PayoutService_CancelBatchPayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) UpdateScheduledBatch(ctx context.Context, in *BatchPayoutRequest, opts ...grpc.CallOption) (*BatchPayoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchPayoutResponse)
+	err := c.cc.Invoke(ctx, PayoutService_UpdateScheduledBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, PayoutService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) RetryFailedPayouts(ctx context.Context, in *RetryRequest, opts ...grpc.CallOption) (*RetryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetryResponse)
+	err := c.cc.Invoke(ctx, PayoutService_RetryFailedPayouts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) ListFailedPayouts(ctx context.Context, in *ListFailedPayoutsRequest, opts ...grpc.CallOption) (*ListFailedPayoutsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFailedPayoutsResponse)
+	err := c.cc.Invoke(ctx, PayoutService_ListFailedPayouts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) GetWalletInventory(ctx context.Context, in *WalletInventoryRequest, opts ...grpc.CallOption) (*WalletInventoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletInventoryResponse)
+	err := c.cc.Invoke(ctx, PayoutService_GetWalletInventory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) EstimateGas(ctx context.Context, in *EstimateGasRequest, opts ...grpc.CallOption) (*EstimateGasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EstimateGasResponse)
+	err := c.cc.Invoke(ctx, PayoutService_EstimateGas_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutServiceClient) GetGasCosts(ctx context.Context, in *GasCostsRequest, opts ...grpc.CallOption) (*GasCostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GasCostsResponse)
+	err := c.cc.Invoke(ctx, PayoutService_GetGasCosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PayoutServiceServer is the server API for PayoutService service.
+// All implementations must embed UnimplementedPayoutServiceServer
+// for forward compatibility.
+//
+// Payout Engine Service - 批量支付引擎
+type PayoutServiceServer interface {
+	// 提交批量支付任务
+	SubmitBatchPayout(context.Context, *BatchPayoutRequest) (*BatchPayoutResponse, error)
+	// 查询批量支付状态
+	GetBatchStatus(context.Context, *BatchStatusRequest) (*BatchStatusResponse, error)
+	// 流式获取支付进度
+	StreamPayoutProgress(*BatchStatusRequest, grpc.ServerStreamingServer[PayoutProgress]) error
+	// 取消批量支付 (定时批次在执行前整批取消)；指定 job_ids 时只取消其中尚未发送的支付项
+	CancelBatchPayout(context.Context, *CancelBatchRequest) (*CancelBatchResponse, error)
+	// 修改尚未到执行时间的定时批次 (按 user_id + batch_id 整体替换支付项和执行时间)
+	UpdateScheduledBatch(context.Context, *BatchPayoutRequest) (*BatchPayoutResponse, error)
+	// 列出用户的支付任务
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	// 重试失败的支付 (从死信队列重新入队)
+	RetryFailedPayouts(context.Context, *RetryRequest) (*RetryResponse, error)
+	// 列出死信队列中的失败支付
+	ListFailedPayouts(context.Context, *ListFailedPayoutsRequest) (*ListFailedPayoutsResponse, error)
+	// 查询付款钱包余额、待支出承诺和可用天数预测
+	GetWalletInventory(context.Context, *WalletInventoryRequest) (*WalletInventoryResponse, error)
+	// 估算 Gas 费用
+	EstimateGas(context.Context, *EstimateGasRequest) (*EstimateGasResponse, error)
+	// 查询已上链交易的实际网络费 (按链、按 UTC 日汇总)，供财务对账
+	GetGasCosts(context.Context, *GasCostsRequest) (*GasCostsResponse, error)
+	mustEmbedUnimplementedPayoutServiceServer()
+}
+
+// UnimplementedPayoutServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPayoutServiceServer struct{}
+
+func (UnimplementedPayoutServiceServer) SubmitBatchPayout(context.Context, *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitBatchPayout not implemented")
+}
+func (UnimplementedPayoutServiceServer) GetBatchStatus(context.Context, *BatchStatusRequest) (*BatchStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBatchStatus not implemented")
+}
+func (UnimplementedPayoutServiceServer) StreamPayoutProgress(*BatchStatusRequest, grpc.ServerStreamingServer[PayoutProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPayoutProgress not implemented")
+}
+func (UnimplementedPayoutServiceServer) CancelBatchPayout(context.Context, *CancelBatchRequest) (*CancelBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelBatchPayout not implemented")
+}
+func (UnimplementedPayoutServiceServer) UpdateScheduledBatch(context.Context, *BatchPayoutRequest) (*BatchPayoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateScheduledBatch not implemented")
+}
+func (UnimplementedPayoutServiceServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedPayoutServiceServer) RetryFailedPayouts(context.Context, *RetryRequest) (*RetryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryFailedPayouts not implemented")
+}
+func (UnimplementedPayoutServiceServer) ListFailedPayouts(context.Context, *ListFailedPayoutsRequest) (*ListFailedPayoutsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFailedPayouts not implemented")
+}
+func (UnimplementedPayoutServiceServer) GetWalletInventory(context.Context, *WalletInventoryRequest) (*WalletInventoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWalletInventory not implemented")
+}
+func (UnimplementedPayoutServiceServer) EstimateGas(context.Context, *EstimateGasRequest) (*EstimateGasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateGas not implemented")
+}
+func (UnimplementedPayoutServiceServer) GetGasCosts(context.Context, *GasCostsRequest) (*GasCostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGasCosts not implemented")
+}
+func (UnimplementedPayoutServiceServer) mustEmbedUnimplementedPayoutServiceServer() {}
+func (UnimplementedPayoutServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafePayoutServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PayoutServiceServer will
+// result in compilation errors.
+type UnsafePayoutServiceServer interface {
+	mustEmbedUnimplementedPayoutServiceServer()
+}
+
+func RegisterPayoutServiceServer(s grpc.ServiceRegistrar, srv PayoutServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPayoutServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PayoutService_ServiceDesc, srv)
+}
+
+func _PayoutService_SubmitBatchPayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).SubmitBatchPayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_SubmitBatchPayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).SubmitBatchPayout(ctx, req.(*BatchPayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_GetBatchStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).GetBatchStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_GetBatchStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).GetBatchStatus(ctx, req.(*BatchStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_StreamPayoutProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PayoutServiceServer).StreamPayoutProgress(m, &grpc.GenericServerStream[BatchStatusRequest, PayoutProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PayoutService_StreamPayoutProgressServer = grpc.ServerStreamingServer[PayoutProgress]
+
+func _PayoutService_CancelBatchPayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).CancelBatchPayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_CancelBatchPayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).CancelBatchPayout(ctx, req.(*CancelBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_UpdateScheduledBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).UpdateScheduledBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_UpdateScheduledBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).UpdateScheduledBatch(ctx, req.(*BatchPayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_RetryFailedPayouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).RetryFailedPayouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_RetryFailedPayouts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).RetryFailedPayouts(ctx, req.(*RetryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_ListFailedPayouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailedPayoutsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).ListFailedPayouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_ListFailedPayouts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).ListFailedPayouts(ctx, req.(*ListFailedPayoutsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_GetWalletInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).GetWalletInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_GetWalletInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).GetWalletInventory(ctx, req.(*WalletInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_EstimateGas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateGasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).EstimateGas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_EstimateGas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).EstimateGas(ctx, req.(*EstimateGasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutService_GetGasCosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GasCostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutServiceServer).GetGasCosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutService_GetGasCosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutServiceServer).GetGasCosts(ctx, req.(*GasCostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PayoutService_ServiceDesc is the grpc.ServiceDesc for PayoutService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PayoutService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "payout.PayoutService",
+	HandlerType: (*PayoutServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitBatchPayout",
+			Handler:    _PayoutService_SubmitBatchPayout_Handler,
+		},
+		{
+			MethodName: "GetBatchStatus",
+			Handler:    _PayoutService_GetBatchStatus_Handler,
+		},
+		{
+			MethodName: "CancelBatchPayout",
+			Handler:    _PayoutService_CancelBatchPayout_Handler,
+		},
+		{
+			MethodName: "UpdateScheduledBatch",
+			Handler:    _PayoutService_UpdateScheduledBatch_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _PayoutService_ListJobs_Handler,
+		},
+		{
+			MethodName: "RetryFailedPayouts",
+			Handler:    _PayoutService_RetryFailedPayouts_Handler,
+		},
+		{
+			MethodName: "ListFailedPayouts",
+			Handler:    _PayoutService_ListFailedPayouts_Handler,
+		},
+		{
+			MethodName: "GetWalletInventory",
+			Handler:    _PayoutService_GetWalletInventory_Handler,
+		},
+		{
+			MethodName: "EstimateGas",
+			Handler:    _PayoutService_EstimateGas_Handler,
+		},
+		{
+			MethodName: "GetGasCosts",
+			Handler:    _PayoutService_GetGasCosts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPayoutProgress",
+			Handler:       _PayoutService_StreamPayoutProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "payout.proto",
+}
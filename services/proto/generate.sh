@@ -20,6 +20,15 @@ protoc \
   --go-grpc_opt=paths=source_relative \
   "$PROTO_DIR"/*.proto
 
+# payout-engine 直接引用的 Go 代码 (go_package: github.com/protocol-bank/payout-engine/pb)
+protoc \
+  --proto_path="$PROTO_DIR" \
+  --go_out="$PROTO_DIR/../payout-engine/pb" \
+  --go_opt=paths=source_relative \
+  --go-grpc_out="$PROTO_DIR/../payout-engine/pb" \
+  --go-grpc_opt=paths=source_relative \
+  "$PROTO_DIR"/payout.proto
+
 # Generate TypeScript code (using ts-proto)
 protoc \
   --proto_path="$PROTO_DIR" \
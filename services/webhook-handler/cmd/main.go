@@ -12,8 +12,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/protocol-bank/webhook-handler/internal/config"
+	"github.com/protocol-bank/webhook-handler/internal/export"
 	"github.com/protocol-bank/webhook-handler/internal/handler"
+	"github.com/protocol-bank/webhook-handler/internal/notify"
+	"github.com/protocol-bank/webhook-handler/internal/rules"
+	"github.com/protocol-bank/webhook-handler/internal/signature"
 	"github.com/protocol-bank/webhook-handler/internal/store"
+	"github.com/protocol-bank/webhook-handler/internal/stream"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -40,32 +45,134 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to initialize store")
 	}
 
-	// 创建处理器
-	rainHandler := handler.NewRainHandler(cfg.Rain, webhookStore)
-	transakHandler := handler.NewTransakHandler(cfg.Transak, webhookStore)
+	// 余额实时推送 (Redis pub/sub → SSE)
+	balanceBroker := stream.NewBroker(webhookStore, stream.Config{
+		TokenSecret: cfg.Stream.TokenSecret,
+		Snapshot: func(ctx context.Context, userID string) ([]stream.BalanceEvent, error) {
+			cards, err := webhookStore.ListUserCardSnapshots(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			events := make([]stream.BalanceEvent, len(cards))
+			for i, c := range cards {
+				events[i] = stream.BalanceEvent{
+					CardID:        c.CardID,
+					Balance:       c.Balance,
+					SpendingLimit: c.SpendingLimit,
+					Currency:      c.Currency,
+				}
+			}
+			return events, nil
+		},
+	})
+
+	// 卡交易对账单导出 (CSV / OFX)，与余额推送共用用户令牌
+	statementExport := export.NewHandler(webhookStore, export.Config{
+		TokenSecret: cfg.Stream.TokenSecret,
+		Balance: func(ctx context.Context, userID string) (float64, string, error) {
+			cards, err := webhookStore.ListUserCardSnapshots(ctx, userID)
+			if err != nil {
+				return 0, "", err
+			}
+			var total float64
+			currency := ""
+			for _, c := range cards {
+				total += c.Balance
+				currency = c.Currency
+			}
+			return total, currency, nil
+		},
+	})
+
+	// 用户通知 (模板存于 notification_templates，运行时重新加载)
+	templates := notify.NewEngine(webhookStore)
+	if err := templates.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Using built-in notification templates")
+	}
+	templateReload, unsubscribeReload, err := webhookStore.Subscribe(ctx, notify.ReloadChannel)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to subscribe to notification template reloads")
+	} else {
+		defer unsubscribeReload()
+	}
+	go templates.Run(ctx, cfg.Notify.ReloadInterval, templateReload)
+	notifier := notify.NewNotifier(templates, webhookStore, webhookStore)
+
+	// 通知与触发它的业务写入在同一事务中写入 notification_outbox，由 Dispatcher 投递并重试
+	notifications := notify.NewDispatcher(webhookStore, notifier, notify.DispatcherConfig{
+		Interval:    cfg.Notify.DispatchInterval,
+		MaxAttempts: cfg.Notify.MaxAttempts,
+	})
+	go notifications.Run(ctx)
+
+	// 授权规则 (存于 authorization_rules，运行时重新加载)
+	authRules := rules.NewEngine(webhookStore)
+	if err := authRules.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Starting without authorization rules")
+	}
+	rulesReload, unsubscribeRules, err := webhookStore.Subscribe(ctx, rules.ReloadChannel)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to subscribe to authorization rule reloads")
+	} else {
+		defer unsubscribeRules()
+	}
+	go authRules.Run(ctx, cfg.Rules.ReloadInterval, rulesReload)
+
+	// 创建处理器 (卡发卡方通过 handler.CardProgram 接入，共享授权/余额/推送逻辑)
+	rainHandler := handler.NewRainHandler(cfg.Rain, webhookStore, balanceBroker, notifications)
+	rainHandler.SetMatchPolicy(handler.MatchPolicy{
+		Window:          cfg.Matching.Window,
+		AmountTolerance: cfg.Matching.AmountTolerance,
+	})
+	rainHandler.SetRules(authRules)
+	transakHandler := handler.NewTransakHandler(cfg.Transak, webhookStore, notifications)
+
+	// 签名验证 (HMAC + 时间戳容差 + 防重放)
+	rainVerifier := signature.NewVerifier(rainHandler.Program().Scheme(), signature.Config{
+		Secret: cfg.Rain.WebhookSecret,
+		Replay: webhookStore,
+	})
+	transakVerifier := signature.NewVerifier(signature.TransakScheme{}, signature.Config{
+		Secret: cfg.Transak.WebhookSecret,
+		Replay: webhookStore,
+	})
 
 	// 设置路由
 	r := chi.NewRouter()
 
 	// 中间件
+	// 请求 ID 沿用上游的 X-Correlation-ID (未携带时生成)，访问日志按该 ID 串联，并在响应头回显
+	middleware.RequestIDHeader = correlationHeader
 	r.Use(middleware.RequestID)
+	r.Use(echoCorrelationID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
 
-	// 健康检查
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(30 * time.Second))
+
+		// 健康检查
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+
+		// Webhook 路由
+		r.Route("/webhooks", func(r chi.Router) {
+			r.With(rainVerifier.Middleware).Post("/rain", rainHandler.HandleWebhook)
+			r.With(rainVerifier.Middleware).Post("/rain/auth", rainHandler.HandleAuthorizationRequest)
+			r.With(transakVerifier.Middleware).Post("/transak", transakHandler.HandleWebhook)
+		})
 	})
 
-	// Webhook 路由
-	r.Route("/webhooks", func(r chi.Router) {
-		r.Post("/rain", rainHandler.HandleWebhook)
-		r.Post("/rain/auth", rainHandler.HandleAuthorizationRequest)
-		r.Post("/transak", transakHandler.HandleWebhook)
-	})
+	// 余额推送 (SSE 长连接) 与对账单导出 (逐页流式输出)，不设请求超时
+	if cfg.Stream.TokenSecret != "" {
+		r.Get("/stream/balances", balanceBroker.ServeHTTP)
+		r.Get("/exports/card-transactions", statementExport.ServeHTTP)
+	} else {
+		log.Warn().Msg("STREAM_TOKEN_SECRET not set, balance streaming and statement export disabled")
+	}
 
 	// 启动 HTTP 服务器
 	server := &http.Server{
@@ -100,3 +207,16 @@ func main() {
 	cancel()
 	log.Info().Msg("Webhook Handler stopped")
 }
+
+// correlationHeader 跨服务关联 ID 请求头 (与 payout-engine 一致)
+const correlationHeader = "X-Correlation-ID"
+
+// echoCorrelationID 在响应头回显请求的关联 ID
+func echoCorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(correlationHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +14,10 @@ type Config struct {
 	Redis    RedisConfig
 	Rain     RainConfig
 	Transak  TransakConfig
+	Stream   StreamConfig
+	Notify   NotifyConfig
+	Matching MatchingConfig
+	Rules    RulesConfig
 }
 
 type DatabaseConfig struct {
@@ -40,9 +45,38 @@ type TransakConfig struct {
 	BaseURL       string
 }
 
+// StreamConfig 余额实时推送 (SSE)
+type StreamConfig struct {
+	TokenSecret string // 与前端共享，用于签发/校验流令牌；为空时禁用推送端点
+}
+
+// NotifyConfig 用户通知模板与 outbox 投递
+type NotifyConfig struct {
+	ReloadInterval   time.Duration // notification_templates 重新加载间隔
+	DispatchInterval time.Duration // notification_outbox 轮询间隔
+	MaxAttempts      int           // 投递失败超过该次数后放弃
+}
+
+// MatchingConfig 卡结算与授权对账 (零值使用默认值)
+type MatchingConfig struct {
+	Window          time.Duration // 只匹配这段时间内的授权
+	AmountTolerance float64       // 结算与授权金额的最大相对差
+}
+
+// RulesConfig 授权规则 (authorization_rules)
+type RulesConfig struct {
+	ReloadInterval time.Duration // 规则重新加载间隔
+}
+
 func Load() (*Config, error) {
 	port, _ := strconv.Atoi(getEnv("HTTP_PORT", "8080"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	templateReload, _ := time.ParseDuration(getEnv("NOTIFICATION_TEMPLATE_RELOAD_INTERVAL", "1m"))
+	notifyDispatch, _ := time.ParseDuration(getEnv("NOTIFICATION_DISPATCH_INTERVAL", "5s"))
+	notifyAttempts, _ := strconv.Atoi(getEnv("NOTIFICATION_MAX_ATTEMPTS", "10"))
+	rulesReload, _ := time.ParseDuration(getEnv("AUTHORIZATION_RULES_RELOAD_INTERVAL", "1m"))
+	matchWindow, _ := time.ParseDuration(getEnv("CARD_SETTLEMENT_MATCH_WINDOW", "0s"))
+	matchTolerance, _ := strconv.ParseFloat(getEnv("CARD_SETTLEMENT_AMOUNT_TOLERANCE", "0"), 64)
 
 	cfg := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
@@ -68,6 +102,21 @@ func Load() (*Config, error) {
 			APIKey:        getEnv("TRANSAK_API_KEY", ""),
 			BaseURL:       getEnv("TRANSAK_BASE_URL", "https://api.transak.com"),
 		},
+		Stream: StreamConfig{
+			TokenSecret: getEnv("STREAM_TOKEN_SECRET", ""),
+		},
+		Notify: NotifyConfig{
+			ReloadInterval:   templateReload,
+			DispatchInterval: notifyDispatch,
+			MaxAttempts:      notifyAttempts,
+		},
+		Matching: MatchingConfig{
+			Window:          matchWindow,
+			AmountTolerance: matchTolerance,
+		},
+		Rules: RulesConfig{
+			ReloadInterval: rulesReload,
+		},
 	}
 
 	return cfg, nil
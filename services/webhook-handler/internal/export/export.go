@@ -0,0 +1,219 @@
+// Package export streams per-user card statements (card transactions and
+// settlements) as CSV or OFX for import into personal finance tools.
+// Rows are read from Postgres page by page and flushed as they arrive.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/webhook-handler/internal/stream"
+	"github.com/rs/zerolog/log"
+)
+
+// Formats supported by the export endpoint
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+)
+
+// maxRange 单次导出的最大时间跨度
+const maxRange = 366 * 24 * time.Hour
+
+// Entry 对账单中的一笔卡交易
+type Entry struct {
+	ID         string // card_transactions.id (分页游标)
+	Program    string
+	ExternalID string // 发卡方交易 ID
+	CardLast4  string
+	Merchant   string
+	Amount     float64 // 发卡方金额 (正数)
+	Currency   string
+	Status     string
+	Type       string // AUTHORIZATION, SETTLEMENT, REFUND
+	PostedAt   time.Time
+}
+
+// SignedAmount 对账单金额: 退款为正 (入账)，消费为负
+func (e Entry) SignedAmount() float64 {
+	if strings.EqualFold(e.Type, "REFUND") {
+		return e.Amount
+	}
+	return -e.Amount
+}
+
+// Query 一页对账单查询: [From, To) 内游标 (After, AfterID) 之后的前 Limit 笔
+type Query struct {
+	From    time.Time
+	To      time.Time
+	After   time.Time
+	AfterID string
+	Limit   int
+}
+
+// Source 读取用户的卡交易 (WebhookStore 基于 Postgres 实现)
+type Source interface {
+	ListCardStatementEntries(ctx context.Context, userID string, q Query) ([]Entry, error)
+}
+
+// BalanceFunc 返回用户卡片当前余额合计 (OFX LEDGERBAL)
+type BalanceFunc func(ctx context.Context, userID string) (float64, string, error)
+
+// Config Handler 配置
+type Config struct {
+	TokenSecret string      // 与前端共享的令牌签名密钥 (与余额推送相同)
+	PageSize    int         // 每页读取行数，默认 500
+	Balance     BalanceFunc // 可选
+}
+
+// Handler GET /exports/card-transactions?from=2026-01-01&to=2026-01-31&format=csv|ofx
+type Handler struct {
+	src      Source
+	cfg      Config
+	pageSize int
+	now      func() time.Time
+}
+
+// NewHandler 创建导出 Handler
+func NewHandler(src Source, cfg Config) *Handler {
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = 500
+	}
+	return &Handler{src: src, cfg: cfg, pageSize: pageSize, now: time.Now}
+}
+
+// ServeHTTP 校验令牌和参数后逐页输出对账单
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); token == "" && strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	userID, err := stream.VerifyToken(h.cfg.TokenSecret, token, h.now())
+	if err != nil {
+		log.Warn().Err(err).Str("remote_addr", r.RemoteAddr).Msg("Rejected statement export")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	from, to, err := parseRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatCSV
+	}
+
+	ctx := r.Context()
+	var out writer
+	switch format {
+	case FormatCSV:
+		out = newCSVWriter(w)
+	case FormatOFX:
+		out = newOFXWriter(w, userID, from, to, h.now(), h.ledgerBalance(ctx, userID))
+	default:
+		http.Error(w, "format must be csv or ofx", http.StatusBadRequest)
+		return
+	}
+
+	// 先读第一页，出错时仍可返回错误状态码
+	q := Query{From: from, To: to, After: from, Limit: h.pageSize}
+	page, err := h.src.ListCardStatementEntries(ctx, userID, q)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to read card statement")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// 大批量导出不受服务器 WriteTimeout 限制
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("card-statement-%s-%s.%s", from.Format("20060102"), to.Add(-time.Second).Format("20060102"), format)
+	w.Header().Set("Content-Type", out.contentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if err := out.begin(); err != nil {
+		return
+	}
+	rows := 0
+	for {
+		for _, e := range page {
+			if err := out.write(e); err != nil {
+				return
+			}
+		}
+		rows += len(page)
+		_ = rc.Flush()
+		if len(page) < h.pageSize {
+			break
+		}
+		last := page[len(page)-1]
+		q.After, q.AfterID = last.PostedAt, last.ID
+		if page, err = h.src.ListCardStatementEntries(ctx, userID, q); err != nil {
+			// 响应已开始，只能截断输出
+			log.Error().Err(err).Str("user_id", userID).Int("rows", rows).Msg("Card statement export aborted")
+			return
+		}
+	}
+
+	if err := out.end(); err != nil {
+		return
+	}
+	_ = rc.Flush()
+	log.Info().Str("user_id", userID).Str("format", format).Int("rows", rows).Msg("Card statement exported")
+}
+
+// ledgerBalance 读取 OFX 账户余额，未配置或读取失败时为 nil
+func (h *Handler) ledgerBalance(ctx context.Context, userID string) *ledgerBalance {
+	if h.cfg.Balance == nil {
+		return nil
+	}
+	amount, currency, err := h.cfg.Balance(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to read card balance for statement")
+		return nil
+	}
+	return &ledgerBalance{Amount: amount, Currency: currency, AsOf: h.now()}
+}
+
+// parseRange 解析 from/to (YYYY-MM-DD 或 RFC3339)。日期形式的 to 包含当天。
+func parseRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, errors.New("from and to are required")
+	}
+	from, _, err := parseTime(fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+	}
+	to, dateOnly, err := parseTime(toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if dateOnly {
+		to = to.Add(24 * time.Hour)
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, errors.New("to must be after from")
+	}
+	if to.Sub(from) > maxRange {
+		return time.Time{}, time.Time{}, errors.New("range must not exceed 366 days")
+	}
+	return from, to, nil
+}
+
+func parseTime(s string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t.UTC(), false, err
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/webhook-handler/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "export-secret"
+
+// memorySource pages through fixed entries the way the Postgres query does
+type memorySource struct {
+	entries []Entry
+	queries []Query
+	err     error
+}
+
+func (m *memorySource) ListCardStatementEntries(ctx context.Context, userID string, q Query) ([]Entry, error) {
+	m.queries = append(m.queries, q)
+	if m.err != nil {
+		return nil, m.err
+	}
+	var page []Entry
+	for _, e := range m.entries {
+		if e.PostedAt.Before(q.From) || !e.PostedAt.Before(q.To) {
+			continue
+		}
+		if e.PostedAt.Before(q.After) || (e.PostedAt.Equal(q.After) && e.ID <= q.AfterID) {
+			continue
+		}
+		page = append(page, e)
+		if len(page) == q.Limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func testEntries() []Entry {
+	base := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	var entries []Entry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, Entry{
+			ID:         fmt.Sprintf("tx-%d", i),
+			Program:    "RAIN",
+			ExternalID: fmt.Sprintf("rain-%d", i),
+			CardLast4:  "4242",
+			Merchant:   "Coffee & Co",
+			Amount:     4.5,
+			Currency:   "USD",
+			Status:     "COMPLETED",
+			Type:       "SETTLEMENT",
+			PostedAt:   base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	entries[4].Type = "REFUND"
+	return entries
+}
+
+func newTestHandler(src Source) *Handler {
+	h := NewHandler(src, Config{
+		TokenSecret: secret,
+		PageSize:    2,
+		Balance: func(ctx context.Context, userID string) (float64, string, error) {
+			return 120.25, "USD", nil
+		},
+	})
+	h.now = func() time.Time { return time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) }
+	return h
+}
+
+func request(h *Handler, query string) *httptest.ResponseRecorder {
+	token := stream.SignToken(secret, "user-1", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+	req := httptest.NewRequest(http.MethodGet, "/exports/card-transactions?"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExportCSV(t *testing.T) {
+	src := &memorySource{entries: testEntries()}
+	rec := request(newTestHandler(src), "from=2026-09-01&to=2026-09-01")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "card-statement-20260901-20260901.csv")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6, "header + 5 rows")
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"2026-09-01T12:00:00Z", "rain-0", "RAIN", "4242", "SETTLEMENT", "COMPLETED", "Coffee & Co", "-4.50", "USD"}, records[1])
+	assert.Equal(t, "4.50", records[5][7], "refunds are credits")
+
+	// 5 rows in pages of 2: the last page is short
+	require.Len(t, src.queries, 3)
+	assert.Equal(t, "tx-3", src.queries[2].AfterID)
+}
+
+func TestExportOFX(t *testing.T) {
+	rec := request(newTestHandler(&memorySource{entries: testEntries()}), "from=2026-09-01&to=2026-09-30&format=ofx")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ofx", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "OFXHEADER:100"))
+	assert.Contains(t, body, "<CURDEF>USD<CCACCTFROM><ACCTID>user-1</CCACCTFROM>")
+	assert.Contains(t, body, "<DTSTART>20260901000000[0:GMT]<DTEND>20261001000000[0:GMT]")
+	assert.Contains(t, body, "<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260901120000[0:GMT]<TRNAMT>-4.50<FITID>RAIN:rain-0<NAME>Coffee &amp; Co")
+	assert.Contains(t, body, "<TRNTYPE>CREDIT")
+	assert.Equal(t, 5, strings.Count(body, "<STMTTRN>"))
+	assert.Contains(t, body, "<LEDGERBAL><BALAMT>120.25")
+	assert.True(t, strings.HasSuffix(body, "</OFX>\r\n"))
+}
+
+func TestExportRejects(t *testing.T) {
+	h := newTestHandler(&memorySource{entries: testEntries()})
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/card-transactions?from=2026-09-01&to=2026-09-30", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	assert.Equal(t, http.StatusBadRequest, request(h, "from=2026-09-01").Code)
+	assert.Equal(t, http.StatusBadRequest, request(h, "from=2026-09-30&to=2026-09-01").Code)
+	assert.Equal(t, http.StatusBadRequest, request(h, "from=2025-01-01&to=2026-09-01").Code)
+	assert.Equal(t, http.StatusBadRequest, request(h, "from=2026-09-01&to=2026-09-30&format=qif").Code)
+
+	failing := newTestHandler(&memorySource{err: errors.New("db down")})
+	assert.Equal(t, http.StatusInternalServerError, request(failing, "from=2026-09-01&to=2026-09-30").Code)
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writer 逐行输出对账单
+type writer interface {
+	contentType() string
+	begin() error
+	write(e Entry) error
+	end() error
+}
+
+// csvWriter 每笔交易一行，带表头
+type csvWriter struct {
+	w *csv.Writer
+}
+
+var csvHeader = []string{"date", "transaction_id", "program", "card_last4", "type", "status", "merchant", "amount", "currency"}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (c *csvWriter) begin() error {
+	return c.flush(c.w.Write(csvHeader))
+}
+
+func (c *csvWriter) write(e Entry) error {
+	return c.flush(c.w.Write([]string{
+		e.PostedAt.UTC().Format(time.RFC3339),
+		e.ExternalID,
+		e.Program,
+		e.CardLast4,
+		e.Type,
+		e.Status,
+		e.Merchant,
+		strconv.FormatFloat(e.SignedAmount(), 'f', 2, 64),
+		e.Currency,
+	}))
+}
+
+func (c *csvWriter) end() error { return nil }
+
+func (c *csvWriter) flush(err error) error {
+	if err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ledgerBalance OFX 账户余额 (LEDGERBAL)
+type ledgerBalance struct {
+	Amount   float64
+	Currency string
+	AsOf     time.Time
+}
+
+// ofxWriter OFX 1.0.2 (SGML) 信用卡对账单，用户所有卡片合并为一个账户
+type ofxWriter struct {
+	w        io.Writer
+	userID   string
+	from, to time.Time
+	now      time.Time
+	balance  *ledgerBalance
+}
+
+const ofxHeader = "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nSECURITY:NONE\r\nENCODING:USASCII\r\nCHARSET:1252\r\nCOMPRESSION:NONE\r\nOLDFILEUID:NONE\r\nNEWFILEUID:NONE\r\n\r\n"
+
+func newOFXWriter(w io.Writer, userID string, from, to, now time.Time, balance *ledgerBalance) *ofxWriter {
+	return &ofxWriter{w: w, userID: userID, from: from, to: to, now: now, balance: balance}
+}
+
+func (o *ofxWriter) contentType() string { return "application/x-ofx" }
+
+func (o *ofxWriter) begin() error {
+	currency := "USD"
+	if o.balance != nil && o.balance.Currency != "" {
+		currency = o.balance.Currency
+	}
+	_, err := fmt.Fprintf(o.w, "%s<OFX>\r\n"+
+		"<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>%s<LANGUAGE>ENG</SONRS></SIGNONMSGSRSV1>\r\n"+
+		"<CREDITCARDMSGSRSV1><CCSTMTTRNRS><TRNUID>0<STATUS><CODE>0<SEVERITY>INFO</STATUS>\r\n"+
+		"<CCSTMTRS><CURDEF>%s<CCACCTFROM><ACCTID>%s</CCACCTFROM>\r\n"+
+		"<BANKTRANLIST><DTSTART>%s<DTEND>%s\r\n",
+		ofxHeader, ofxTime(o.now), ofxText(strings.ToUpper(currency), 3), ofxText(o.userID, 22), ofxTime(o.from), ofxTime(o.to))
+	return err
+}
+
+func (o *ofxWriter) write(e Entry) error {
+	trnType := "DEBIT"
+	if e.SignedAmount() > 0 {
+		trnType = "CREDIT"
+	}
+	memo := strings.TrimSpace(fmt.Sprintf("%s %s card %s %s", e.Type, e.Status, e.CardLast4, e.Currency))
+	_, err := fmt.Fprintf(o.w, "<STMTTRN><TRNTYPE>%s<DTPOSTED>%s<TRNAMT>%s<FITID>%s<NAME>%s<MEMO>%s</STMTTRN>\r\n",
+		trnType, ofxTime(e.PostedAt), strconv.FormatFloat(e.SignedAmount(), 'f', 2, 64),
+		ofxText(e.Program+":"+e.ExternalID, 255), ofxText(e.Merchant, 32), ofxText(memo, 255))
+	return err
+}
+
+func (o *ofxWriter) end() error {
+	if _, err := io.WriteString(o.w, "</BANKTRANLIST>\r\n"); err != nil {
+		return err
+	}
+	if o.balance != nil {
+		if _, err := fmt.Fprintf(o.w, "<LEDGERBAL><BALAMT>%s<DTASOF>%s</LEDGERBAL>\r\n",
+			strconv.FormatFloat(o.balance.Amount, 'f', 2, 64), ofxTime(o.balance.AsOf)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(o.w, "</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>\r\n</OFX>\r\n")
+	return err
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// ofxText 转义 SGML 特殊字符并截断到字段最大长度
+var ofxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ")
+
+func ofxText(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		s = string(r[:max])
+	}
+	return ofxEscaper.Replace(s)
+}
@@ -0,0 +1,483 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/protocol-bank/webhook-handler/internal/notify"
+	"github.com/protocol-bank/webhook-handler/internal/rules"
+	"github.com/protocol-bank/webhook-handler/internal/signature"
+	"github.com/protocol-bank/webhook-handler/internal/store"
+	"github.com/protocol-bank/webhook-handler/internal/stream"
+	"github.com/rs/zerolog/log"
+)
+
+// CardEventType 发卡方无关的卡事件类型
+type CardEventType string
+
+const (
+	CardEventTransaction  CardEventType = "transaction"
+	CardEventCreated      CardEventType = "card_created"
+	CardEventActivated    CardEventType = "card_activated"
+	CardEventSettlement   CardEventType = "settlement"
+	CardEventTopUp        CardEventType = "topup"
+	CardEventLimitUpdated CardEventType = "limit_updated"
+)
+
+// Card statuses stored in corporate_cards.status
+const (
+	cardStatusInactive = "INACTIVE"
+	cardStatusActive   = "ACTIVE"
+)
+
+// CardEvent 由发卡方 Webhook 解析出的统一事件
+type CardEvent struct {
+	ID        string
+	Type      CardEventType
+	IssuerRaw string // 发卡方原始事件类型 (日志用)
+
+	CardID string
+	UserID string
+	Last4  string
+
+	// 交易 / 结算 / 充值
+	TransactionID   string
+	AuthorizationID string // 发卡方提供时用于精确匹配授权
+	MerchantName    string
+	Amount          float64
+	Currency        string
+	Status          string
+	Settled         bool // 交易已结算，需与授权对账
+
+	// 限额变更 (nil 表示取消限额)
+	SpendingLimit *float64
+}
+
+// CardAuthorization 实时授权请求
+type CardAuthorization struct {
+	ID           string
+	CardID       string
+	UserID       string
+	MerchantName string
+	MCC          string // 商户类别码 (发卡方提供时)
+	Amount       float64
+	Currency     string
+}
+
+// DeclineReason 授权结果 (发卡方各自映射为自己的拒绝码)
+type DeclineReason string
+
+const (
+	AuthApproved          DeclineReason = "approved"
+	AuthInsufficientFunds DeclineReason = "insufficient_funds"
+	AuthIssuerDecline     DeclineReason = "issuer_decline"
+)
+
+// CardProgram 发卡方适配器: 负责签名方案、负载解析和授权响应格式
+type CardProgram interface {
+	// Name 发卡方标识，写入 corporate_cards.program (如 "RAIN")
+	Name() string
+	// Scheme Webhook 签名方案
+	Scheme() signature.Scheme
+	// ParseEvent 解析 Webhook 负载。信封可解析但事件数据有误时返回事件和错误，
+	// 该事件会被记录并标记为已处理 (重试无法修复)
+	ParseEvent(body []byte) (*CardEvent, error)
+	// ParseAuthorization 解析实时授权请求
+	ParseAuthorization(body []byte) (*CardAuthorization, error)
+	// AuthorizationResponse 构造发卡方要求的授权响应
+	AuthorizationResponse(req *CardAuthorization, approved bool, reason DeclineReason) interface{}
+}
+
+// CardStore 卡片持久化 (按发卡方隔离外部 ID)。
+// ProcessEvent 的 apply 收到的 ctx 携带事件事务，其中的写入与通知入队一起提交。
+type CardStore interface {
+	IsProcessed(ctx context.Context, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, eventID, payload string) error
+	ProcessEvent(ctx context.Context, source, eventID, eventType string, payload []byte, apply func(ctx context.Context) error) (bool, error)
+	EnqueueNotification(ctx context.Context, n notify.Notification) error
+	UpsertCardStatus(ctx context.Context, program, externalID, userID, last4, status string) error
+	UpdateCardStatusByExternalID(ctx context.Context, program, externalID, status string) error
+	UpdateCardTransaction(ctx context.Context, program, txID, cardID, merchant string, amount float64, currency, status string) error
+	HoldCardAuthorization(ctx context.Context, program string, auth store.CardAuthorization) (bool, error)
+	ListPendingAuthorizations(ctx context.Context, program, cardID string, since time.Time) ([]store.CardAuthorization, error)
+	SettleCardTransaction(ctx context.Context, program string, st store.CardSettlement) (store.SettlementOutcome, error)
+	CreditCardBalance(ctx context.Context, program, cardID string, amount float64) error
+	UpdateCardSpendingLimit(ctx context.Context, program, cardID string, limit *float64) error
+	GetCardSnapshot(ctx context.Context, program, cardID string) (store.CardSnapshot, error)
+}
+
+// CardHandler 卡 Webhook 与授权处理器，限额/授权/推送逻辑在各发卡方间共享
+type CardHandler struct {
+	program       CardProgram
+	store         CardStore
+	broker        *stream.Broker     // 余额变更实时推送 (可选)
+	notifications *notify.Dispatcher // 用户通知 (可选)，经 outbox 投递
+	match         MatchPolicy        // 结算与授权对账
+	rules         *rules.Engine      // 授权规则 (可选)
+}
+
+// NewCardHandler 创建卡处理器
+func NewCardHandler(program CardProgram, store CardStore, broker *stream.Broker, notifications *notify.Dispatcher) *CardHandler {
+	return &CardHandler{
+		program:       program,
+		store:         store,
+		broker:        broker,
+		notifications: notifications,
+		match:         DefaultMatchPolicy,
+	}
+}
+
+// balanceChange 事件事务提交后推送的余额变更
+type balanceChange struct {
+	eventType stream.EventType
+	cardID    string
+	amount    float64
+}
+
+// SetMatchPolicy 设置结算对账策略 (零值字段使用默认值)
+func (h *CardHandler) SetMatchPolicy(p MatchPolicy) {
+	h.match = p.withDefaults()
+}
+
+// SetRules 设置授权规则引擎，规则在余额冻结前评估
+func (h *CardHandler) SetRules(e *rules.Engine) {
+	h.rules = e
+}
+
+// Program 返回发卡方适配器
+func (h *CardHandler) Program() CardProgram {
+	return h.program
+}
+
+// HandleWebhook 处理发卡方 Webhook (须挂载在 signature.Verifier 中间件之后)
+func (h *CardHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read request body")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	// 签名与时间戳已由 signature.Verifier 中间件验证
+
+	evt, parseErr := h.program.ParseEvent(body)
+	if evt == nil {
+		log.Error().Err(parseErr).Str("program", h.program.Name()).Msg("Failed to parse webhook payload")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	// 检查重复处理 (Redis 快速路径，权威去重在事件事务内)
+	dedupID := h.dedupID(evt.ID)
+	processed, err := h.store.IsProcessed(r.Context(), dedupID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check duplicate")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if processed {
+		log.Info().Str("event_id", evt.ID).Msg("Duplicate webhook, skipping")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	log.Info().
+		Str("program", h.program.Name()).
+		Str("event_id", evt.ID).
+		Str("event_type", evt.IssuerRaw).
+		Msg("Processing card webhook")
+
+	// 业务写入、余额变动、通知入队与去重记录在同一事务中提交，
+	// 失败时整体回滚并返回 500，由发卡方重试
+	var change *balanceChange
+	applied, err := h.store.ProcessEvent(r.Context(), h.program.Name(), dedupID, evt.IssuerRaw, body, func(ctx context.Context) error {
+		if parseErr != nil {
+			log.Error().Err(parseErr).Str("event_type", evt.IssuerRaw).Msg("Failed to parse event data")
+			return nil
+		}
+		var err error
+		change, err = h.handleEvent(ctx, evt)
+		if errors.Is(err, store.ErrCardNotFound) {
+			// 重试无法修复: 记录后照常标记为已处理
+			log.Error().Err(err).Str("event_id", evt.ID).Str("event_type", evt.IssuerRaw).Msg("Card webhook references an unknown card")
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Str("event_id", evt.ID).Str("event_type", evt.IssuerRaw).Msg("Failed to process card webhook")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if !applied {
+		log.Info().Str("event_id", evt.ID).Msg("Duplicate webhook, skipping")
+	}
+
+	// 标记为已处理
+	if err := h.store.MarkProcessed(r.Context(), dedupID, string(body)); err != nil {
+		log.Error().Err(err).Msg("Failed to mark as processed")
+	}
+
+	if change != nil {
+		h.publishBalance(r.Context(), change.eventType, change.cardID, change.amount)
+	}
+	if applied && h.notifications != nil {
+		h.notifications.Wake()
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// dedupID 去重键。Rain 沿用无前缀的历史键，其他发卡方加前缀避免事件 ID 冲突。
+func (h *CardHandler) dedupID(eventID string) string {
+	if h.program.Name() == ProgramRain {
+		return eventID
+	}
+	return h.program.Name() + ":" + eventID
+}
+
+// HandleAuthorizationRequest 处理实时授权请求 (须挂载在 signature.Verifier 中间件之后)
+func (h *CardHandler) HandleAuthorizationRequest(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	authReq, err := h.program.ParseAuthorization(body)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	log.Info().
+		Str("program", h.program.Name()).
+		Str("auth_id", authReq.ID).
+		Str("card_id", authReq.CardID).
+		Float64("amount", authReq.Amount).
+		Str("merchant", authReq.MerchantName).
+		Msg("Processing authorization request")
+
+	// 检查用户余额和限额，通过时冻结授权金额
+	approved, reason := h.checkAuthorization(r.Context(), authReq)
+	if approved {
+		h.publishBalance(r.Context(), stream.EventHold, authReq.CardID, authReq.Amount)
+	}
+	event := notify.EventCardAuthorized
+	if !approved {
+		event = notify.EventCardDeclined
+	}
+	err = h.notifyCardholder(r.Context(), authReq.ID, authReq.UserID, authReq.CardID, event, notify.Vars{
+		"Amount":   authReq.Amount,
+		"Currency": authReq.Currency,
+		"Merchant": authReq.MerchantName,
+		"Reason":   string(reason),
+	})
+	if err != nil && !errors.Is(err, store.ErrCardNotFound) {
+		log.Error().Err(err).Str("auth_id", authReq.ID).Str("event", string(event)).Msg("Failed to enqueue notification")
+	} else if err == nil && h.notifications != nil {
+		h.notifications.Wake()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.program.AuthorizationResponse(authReq, approved, reason))
+}
+
+// handleEvent 根据事件类型处理 (在事件事务内执行)，返回提交后需推送的余额变更
+func (h *CardHandler) handleEvent(ctx context.Context, evt *CardEvent) (*balanceChange, error) {
+	switch evt.Type {
+	case CardEventTransaction:
+		return h.handleTransaction(ctx, evt)
+	case CardEventCreated:
+		return nil, h.handleCardCreated(ctx, evt)
+	case CardEventActivated:
+		return nil, h.handleCardActivated(ctx, evt)
+	case CardEventSettlement:
+		return h.handleSettlement(ctx, evt)
+	case CardEventTopUp:
+		return h.handleTopUp(ctx, evt)
+	case CardEventLimitUpdated:
+		return h.handleLimitUpdated(ctx, evt)
+	default:
+		log.Warn().Str("program", h.program.Name()).Str("event_type", evt.IssuerRaw).Msg("Unknown event type")
+		return nil, nil
+	}
+}
+
+// handleTransaction 处理交易事件
+func (h *CardHandler) handleTransaction(ctx context.Context, tx *CardEvent) (*balanceChange, error) {
+	log.Info().
+		Str("tx_id", tx.TransactionID).
+		Str("merchant", tx.MerchantName).
+		Float64("amount", tx.Amount).
+		Str("status", tx.Status).
+		Msg("Card transaction processed")
+
+	// 已结算的交易与授权对账后入账
+	if tx.Settled {
+		return h.settle(ctx, tx)
+	}
+
+	// Sync to Database
+	if err := h.store.UpdateCardTransaction(ctx, h.program.Name(), tx.TransactionID, tx.CardID, tx.MerchantName, tx.Amount, tx.Currency, tx.Status); err != nil {
+		return nil, fmt.Errorf("failed to persist card transaction: %w", err)
+	}
+	return nil, nil
+}
+
+// handleCardCreated 处理卡片创建事件
+func (h *CardHandler) handleCardCreated(ctx context.Context, evt *CardEvent) error {
+	log.Info().Str("event_id", evt.ID).Msg("Card created event")
+
+	if err := h.store.UpsertCardStatus(ctx, h.program.Name(), evt.CardID, evt.UserID, evt.Last4, cardStatusInactive); err != nil {
+		return fmt.Errorf("failed to create card record: %w", err)
+	}
+	return h.notifyCardholder(ctx, evt.ID, evt.UserID, evt.CardID, notify.EventCardCreated, notify.Vars{"Last4": evt.Last4})
+}
+
+// handleCardActivated 处理卡片激活事件
+func (h *CardHandler) handleCardActivated(ctx context.Context, evt *CardEvent) error {
+	log.Info().Str("event_id", evt.ID).Msg("Card activated event")
+
+	if err := h.store.UpdateCardStatusByExternalID(ctx, h.program.Name(), evt.CardID, cardStatusActive); err != nil {
+		return fmt.Errorf("failed to activate card: %w", err)
+	}
+	return h.notifyCardholder(ctx, evt.ID, evt.UserID, evt.CardID, notify.EventCardActivated, notify.Vars{})
+}
+
+// handleSettlement 处理结算事件
+func (h *CardHandler) handleSettlement(ctx context.Context, evt *CardEvent) (*balanceChange, error) {
+	log.Info().Str("event_id", evt.ID).Msg("Settlement event")
+	if evt.TransactionID == "" {
+		// 无交易 ID 无法去重，交给人工处理
+		log.Warn().Str("event_id", evt.ID).Str("card_id", evt.CardID).Float64("amount", evt.Amount).Msg("Settlement without transaction ID, needs manual review")
+		return nil, nil
+	}
+	return h.settle(ctx, evt)
+}
+
+// handleTopUp 处理卡片充值事件
+func (h *CardHandler) handleTopUp(ctx context.Context, evt *CardEvent) (*balanceChange, error) {
+	if evt.Amount <= 0 {
+		log.Warn().Str("card_id", evt.CardID).Float64("amount", evt.Amount).Msg("Ignoring non-positive top-up")
+		return nil, nil
+	}
+
+	if err := h.store.CreditCardBalance(ctx, h.program.Name(), evt.CardID, evt.Amount); err != nil {
+		return nil, fmt.Errorf("failed to credit card balance: %w", err)
+	}
+	log.Info().Str("card_id", evt.CardID).Float64("amount", evt.Amount).Msg("Card topped up")
+	if err := h.notifyCardholder(ctx, evt.ID, evt.UserID, evt.CardID, notify.EventCardTopUp, notify.Vars{"Amount": evt.Amount}); err != nil {
+		return nil, err
+	}
+	return &balanceChange{eventType: stream.EventTopUp, cardID: evt.CardID, amount: evt.Amount}, nil
+}
+
+// handleLimitUpdated 处理限额变更事件
+func (h *CardHandler) handleLimitUpdated(ctx context.Context, evt *CardEvent) (*balanceChange, error) {
+	if err := h.store.UpdateCardSpendingLimit(ctx, h.program.Name(), evt.CardID, evt.SpendingLimit); err != nil {
+		return nil, fmt.Errorf("failed to update spending limit: %w", err)
+	}
+	if err := h.notifyCardholder(ctx, evt.ID, evt.UserID, evt.CardID, notify.EventCardLimitUpdated, notify.Vars{"Limit": evt.SpendingLimit}); err != nil {
+		return nil, err
+	}
+	return &balanceChange{eventType: stream.EventLimit, cardID: evt.CardID}, nil
+}
+
+// publishBalance 推送卡片最新余额/限额给持卡用户
+func (h *CardHandler) publishBalance(ctx context.Context, eventType stream.EventType, cardID string, amount float64) {
+	if h.broker == nil {
+		return
+	}
+	card, err := h.store.GetCardSnapshot(ctx, h.program.Name(), cardID)
+	if err != nil {
+		log.Error().Err(err).Str("card_id", cardID).Msg("Failed to load card for balance event")
+		return
+	}
+	evt := stream.BalanceEvent{
+		Type:          eventType,
+		UserID:        card.UserID,
+		CardID:        card.CardID,
+		Balance:       card.Balance,
+		SpendingLimit: card.SpendingLimit,
+		Amount:        amount,
+		Currency:      card.Currency,
+	}
+	if err := h.broker.Publish(ctx, evt); err != nil {
+		log.Error().Err(err).Str("card_id", cardID).Msg("Failed to publish balance event")
+	}
+}
+
+// notifyCardholder 将持卡人通知写入 outbox (ctx 携带事件事务时随事件提交)。
+// 卡片当前余额、币种作为模板变量 Balance / Currency 补充，事件未携带用户时按卡片查询持卡人。
+func (h *CardHandler) notifyCardholder(ctx context.Context, id, userID, cardID string, event notify.Event, vars notify.Vars) error {
+	if h.notifications == nil {
+		return nil
+	}
+	card, err := h.store.GetCardSnapshot(ctx, h.program.Name(), cardID)
+	if err != nil {
+		return fmt.Errorf("failed to load card for notification: %w", err)
+	}
+	if userID == "" {
+		userID = card.UserID
+	}
+	if userID == "" {
+		log.Warn().Str("card_id", cardID).Str("event", string(event)).Msg("Card has no owner, skipping notification")
+		return nil
+	}
+	vars["Balance"] = card.Balance
+	if _, ok := vars["Currency"]; !ok {
+		vars["Currency"] = card.Currency
+	}
+	return h.store.EnqueueNotification(ctx, notify.Notification{ID: h.dedupID(id), UserID: userID, Event: event, Vars: vars})
+}
+
+// checkAuthorization 先评估授权规则，再从余额中冻结授权金额 (结算时对账)
+func (h *CardHandler) checkAuthorization(ctx context.Context, req *CardAuthorization) (bool, DeclineReason) {
+	// 1. 授权规则 (MCC、金额、时段等，可运行时修改)
+	if h.rules != nil {
+		d := h.rules.Evaluate(rules.Input{
+			Program:  h.program.Name(),
+			CardID:   req.CardID,
+			UserID:   req.UserID,
+			Merchant: req.MerchantName,
+			MCC:      req.MCC,
+			Amount:   req.Amount,
+			Currency: req.Currency,
+			Time:     time.Now(),
+		})
+		for _, name := range d.Flagged {
+			log.Warn().Str("auth_id", req.ID).Str("card_id", req.CardID).Str("rule", name).Msg("Authorization flagged by rule")
+		}
+		if d.Declined() {
+			log.Info().Str("auth_id", req.ID).Str("card_id", req.CardID).Str("rule", d.Rule).Str("reason", d.Reason).Msg("Authorization declined by rule")
+			return false, DeclineReason(d.Reason)
+		}
+	}
+
+	// 2. Hold against User Balance (Pre-funded Model)
+	held, err := h.store.HoldCardAuthorization(ctx, h.program.Name(), store.CardAuthorization{
+		ID:           req.ID,
+		CardID:       req.CardID,
+		MerchantName: req.MerchantName,
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("card_id", req.CardID).Msg("Failed to hold funds during auth")
+		return false, AuthIssuerDecline // Fail safe
+	}
+	if !held {
+		log.Warn().Str("card_id", req.CardID).Float64("req_amount", req.Amount).Msg("Insufficient funds")
+		return false, AuthInsufficientFunds
+	}
+
+	return true, AuthApproved
+}
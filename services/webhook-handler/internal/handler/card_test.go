@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/webhook-handler/internal/config"
+	"github.com/protocol-bank/webhook-handler/internal/notify"
+	"github.com/protocol-bank/webhook-handler/internal/rules"
+	"github.com/protocol-bank/webhook-handler/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memCardStore is an in-memory CardStore and notify.Outbox keyed by program and
+// external card ID. A failed ProcessEvent restores balances and the outbox, as
+// rolling back the database transaction would.
+type memCardStore struct {
+	processed map[string]bool
+	events    map[string]bool // processed_webhook_events
+	outbox    []*memOutboxEntry
+	enqueue   error // EnqueueNotification 失败
+	balances  map[string]float64
+	statuses  map[string]string
+	users     map[string]string
+	auths     map[string]*memAuthorization // program/authorization ID
+	settled   map[string]store.CardSettlement
+	review    map[string]bool // settlements flagged for review
+}
+
+type memOutboxEntry struct {
+	notify.Notification
+	sent bool
+}
+
+type memAuthorization struct {
+	store.CardAuthorization
+	program string
+	settled bool
+}
+
+func newMemCardStore() *memCardStore {
+	return &memCardStore{
+		processed: map[string]bool{},
+		events:    map[string]bool{},
+		balances:  map[string]float64{},
+		statuses:  map[string]string{},
+		users:     map[string]string{},
+		auths:     map[string]*memAuthorization{},
+		settled:   map[string]store.CardSettlement{},
+		review:    map[string]bool{},
+	}
+}
+
+func cardKey(program, cardID string) string { return program + "/" + cardID }
+
+func (m *memCardStore) IsProcessed(_ context.Context, id string) (bool, error) {
+	return m.processed[id], nil
+}
+func (m *memCardStore) MarkProcessed(_ context.Context, id, _ string) error {
+	m.processed[id] = true
+	return nil
+}
+func (m *memCardStore) ProcessEvent(ctx context.Context, source, eventID, _ string, _ []byte, apply func(context.Context) error) (bool, error) {
+	key := source + "/" + eventID
+	if m.events[key] {
+		return false, nil
+	}
+	balances := make(map[string]float64, len(m.balances))
+	for k, v := range m.balances {
+		balances[k] = v
+	}
+	queued := len(m.outbox)
+	if err := apply(ctx); err != nil {
+		m.balances, m.outbox = balances, m.outbox[:queued]
+		return false, err
+	}
+	m.events[key] = true
+	return true, nil
+}
+func (m *memCardStore) EnqueueNotification(_ context.Context, n notify.Notification) error {
+	if m.enqueue != nil {
+		return m.enqueue
+	}
+	m.outbox = append(m.outbox, &memOutboxEntry{Notification: n})
+	return nil
+}
+func (m *memCardStore) ClaimNotifications(_ context.Context, limit int, _ time.Duration) ([]notify.Pending, error) {
+	var out []notify.Pending
+	for i, e := range m.outbox {
+		if !e.sent && len(out) < limit {
+			out = append(out, notify.Pending{Notification: e.Notification, OutboxID: strconv.Itoa(i)})
+		}
+	}
+	return out, nil
+}
+func (m *memCardStore) MarkNotificationSent(_ context.Context, outboxID string) error {
+	i, err := strconv.Atoi(outboxID)
+	if err != nil {
+		return err
+	}
+	m.outbox[i].sent = true
+	return nil
+}
+func (m *memCardStore) RetryNotification(context.Context, string, time.Duration, string) error {
+	return nil
+}
+func (m *memCardStore) FailNotification(context.Context, string, string) error { return nil }
+func (m *memCardStore) UpsertCardStatus(_ context.Context, program, cardID, userID, _, status string) error {
+	m.statuses[cardKey(program, cardID)] = status
+	m.users[cardKey(program, cardID)] = userID
+	return nil
+}
+func (m *memCardStore) UpdateCardStatusByExternalID(_ context.Context, program, cardID, status string) error {
+	m.statuses[cardKey(program, cardID)] = status
+	return nil
+}
+func (m *memCardStore) UpdateCardTransaction(context.Context, string, string, string, string, float64, string, string) error {
+	return nil
+}
+func (m *memCardStore) HoldCardAuthorization(_ context.Context, program string, auth store.CardAuthorization) (bool, error) {
+	if _, ok := m.auths[cardKey(program, auth.ID)]; ok {
+		return true, nil
+	}
+	key := cardKey(program, auth.CardID)
+	if m.balances[key] < auth.Amount {
+		return false, nil
+	}
+	m.balances[key] -= auth.Amount
+	if auth.CreatedAt.IsZero() {
+		auth.CreatedAt = time.Now()
+	}
+	m.auths[cardKey(program, auth.ID)] = &memAuthorization{CardAuthorization: auth, program: program}
+	return true, nil
+}
+func (m *memCardStore) ListPendingAuthorizations(_ context.Context, program, cardID string, since time.Time) ([]store.CardAuthorization, error) {
+	var out []store.CardAuthorization
+	for _, a := range m.auths {
+		if a.program == program && a.CardID == cardID && !a.settled && !a.CreatedAt.Before(since) {
+			out = append(out, a.CardAuthorization)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+func (m *memCardStore) SettleCardTransaction(_ context.Context, program string, st store.CardSettlement) (store.SettlementOutcome, error) {
+	txKey := cardKey(program, st.TransactionID)
+	if _, ok := m.settled[txKey]; ok {
+		return store.SettlementOutcome{}, nil
+	}
+	var out store.SettlementOutcome
+	var hold float64
+	if a, ok := m.auths[cardKey(program, st.AuthorizationID)]; ok && !a.settled && a.CardID == st.CardID {
+		a.settled = true
+		hold = a.Amount
+		out.Matched = true
+	}
+	out.Applied, out.NeedsReview, out.Released = true, !out.Matched, hold-st.Amount
+	m.settled[txKey] = st
+	m.review[txKey] = out.NeedsReview
+	m.balances[cardKey(program, st.CardID)] += out.Released
+	return out, nil
+}
+func (m *memCardStore) CreditCardBalance(_ context.Context, program, cardID string, amount float64) error {
+	m.balances[cardKey(program, cardID)] += amount
+	return nil
+}
+func (m *memCardStore) UpdateCardSpendingLimit(context.Context, string, string, *float64) error {
+	return nil
+}
+func (m *memCardStore) GetCardSnapshot(_ context.Context, program, cardID string) (store.CardSnapshot, error) {
+	key := cardKey(program, cardID)
+	return store.CardSnapshot{Program: program, CardID: cardID, UserID: m.users[key], Balance: m.balances[key], Currency: "USD"}, nil
+}
+
+// memNotifications records published notifications; every user prefers Spanish.
+type memNotifications struct {
+	messages []notify.Message
+}
+
+func (m *memNotifications) UserLocale(context.Context, string) (string, error) { return "es", nil }
+func (m *memNotifications) Publish(_ context.Context, _ string, payload []byte) error {
+	var msg notify.Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func TestRainCardHandler(t *testing.T) {
+	cards := newMemCardStore()
+	h := NewRainHandler(config.RainConfig{}, cards, nil, nil)
+
+	post := func(handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec
+	}
+
+	t.Run("events are scoped to the program", func(t *testing.T) {
+		rec := post(h.HandleWebhook, `{"event_id":"evt-1","event_type":"card.created","data":{"card_id":"c1","user_id":"u1","last4":"4242"}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "INACTIVE", cards.statuses["RAIN/c1"])
+
+		post(h.HandleWebhook, `{"event_id":"evt-2","event_type":"card.topup","data":{"card_id":"c1","amount":50}}`)
+		post(h.HandleWebhook, `{"event_id":"evt-3","event_type":"card.transaction","data":{"card_id":"c1","amount":20,"status":"SETTLED"}}`)
+		assert.Equal(t, 30.0, cards.balances["RAIN/c1"])
+	})
+
+	t.Run("duplicate events are skipped", func(t *testing.T) {
+		post(h.HandleWebhook, `{"event_id":"evt-2","event_type":"card.topup","data":{"card_id":"c1","amount":50}}`)
+		assert.Equal(t, 30.0, cards.balances["RAIN/c1"])
+		assert.True(t, cards.processed["evt-2"], "Rain keeps unprefixed dedup keys")
+	})
+
+	t.Run("authorization uses shared balance checks", func(t *testing.T) {
+		var resp map[string]interface{}
+		rec := post(h.HandleAuthorizationRequest, `{"authorization_id":"a1","card_id":"c1","amount":25}`)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, true, resp["approved"])
+		assert.Equal(t, 5.0, cards.balances["RAIN/c1"], "approved amount is held")
+
+		rec = post(h.HandleAuthorizationRequest, `{"authorization_id":"a2","card_id":"c1","amount":100}`)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, false, resp["approved"])
+		assert.Equal(t, "insufficient_funds", resp["reason"])
+	})
+
+	t.Run("malformed payloads", func(t *testing.T) {
+		rec := post(h.HandleWebhook, `not json`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// 事件数据有误: 确认接收，不再重试
+		rec = post(h.HandleWebhook, `{"event_id":"evt-4","event_type":"card.topup","data":"oops"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, cards.processed["evt-4"])
+		assert.Equal(t, 5.0, cards.balances["RAIN/c1"], "only the approved authorization is held")
+	})
+}
+
+func TestSettlementMatching(t *testing.T) {
+	cards := newMemCardStore()
+	h := NewRainHandler(config.RainConfig{}, cards, nil, nil)
+	post := func(handler http.HandlerFunc, body string) {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	}
+	post(h.HandleWebhook, `{"event_id":"evt-1","event_type":"card.created","data":{"card_id":"c1","user_id":"u1"}}`)
+	post(h.HandleWebhook, `{"event_id":"evt-2","event_type":"card.topup","data":{"card_id":"c1","amount":200}}`)
+	post(h.HandleAuthorizationRequest, `{"authorization_id":"a1","card_id":"c1","merchant_name":"Blue Bottle Coffee","amount":40,"currency":"USD"}`)
+	post(h.HandleAuthorizationRequest, `{"authorization_id":"a2","card_id":"c1","merchant_name":"Uber","amount":25,"currency":"USD"}`)
+	require.Equal(t, 135.0, cards.balances["RAIN/c1"])
+
+	t.Run("heuristic match releases the difference", func(t *testing.T) {
+		// 结算金额含小费，商户名带门店信息
+		post(h.HandleWebhook, `{"event_id":"evt-3","event_type":"card.settlement","data":{"transaction_id":"t1","card_id":"c1","merchant_name":"BLUE BOTTLE COFFEE #12 SF","amount":44,"currency":"USD"}}`)
+		assert.Equal(t, "a1", cards.settled["RAIN/t1"].AuthorizationID)
+		assert.False(t, cards.review["RAIN/t1"])
+		assert.Equal(t, 131.0, cards.balances["RAIN/c1"])
+	})
+
+	t.Run("authorization ID takes precedence", func(t *testing.T) {
+		post(h.HandleWebhook, `{"event_id":"evt-4","event_type":"card.transaction","data":{"transaction_id":"t2","authorization_id":"a2","card_id":"c1","merchant_name":"Uber Trip","amount":20,"status":"SETTLED"}}`)
+		assert.Equal(t, "a2", cards.settled["RAIN/t2"].AuthorizationID)
+		assert.Equal(t, 136.0, cards.balances["RAIN/c1"], "5 released from the hold")
+	})
+
+	t.Run("settling twice is a no-op", func(t *testing.T) {
+		post(h.HandleWebhook, `{"event_id":"evt-5","event_type":"card.transaction","data":{"transaction_id":"t2","authorization_id":"a2","card_id":"c1","amount":20,"status":"COMPLETED"}}`)
+		assert.Equal(t, 136.0, cards.balances["RAIN/c1"])
+	})
+
+	t.Run("unmatched settlement is debited and flagged", func(t *testing.T) {
+		post(h.HandleWebhook, `{"event_id":"evt-6","event_type":"card.settlement","data":{"transaction_id":"t3","card_id":"c1","merchant_name":"Airline","amount":60}}`)
+		assert.Empty(t, cards.settled["RAIN/t3"].AuthorizationID)
+		assert.True(t, cards.review["RAIN/t3"])
+		assert.Equal(t, 76.0, cards.balances["RAIN/c1"])
+	})
+}
+
+func TestMatchAuthorization(t *testing.T) {
+	now := time.Now()
+	pending := []store.CardAuthorization{
+		{ID: "a1", MerchantName: "Amazon", Amount: 100, Currency: "USD", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "a2", MerchantName: "Amazon Marketplace", Amount: 100, Currency: "USD", CreatedAt: now.Add(-time.Hour)},
+		{ID: "a3", MerchantName: "Hotel", Amount: 300, Currency: "EUR", CreatedAt: now},
+	}
+	p := DefaultMatchPolicy
+
+	tests := []struct {
+		name string
+		evt  CardEvent
+		want string
+	}{
+		{"oldest of equal candidates", CardEvent{MerchantName: "AMAZON.COM", Amount: 100, Currency: "USD"}, "a1"},
+		{"exact amount without merchant", CardEvent{Amount: 300, Currency: "EUR"}, "a3"},
+		{"currency must agree", CardEvent{MerchantName: "Hotel", Amount: 300, Currency: "USD"}, ""},
+		{"amount outside tolerance", CardEvent{MerchantName: "Hotel", Amount: 400, Currency: "EUR"}, ""},
+		{"different merchant needs exact amount", CardEvent{MerchantName: "Walmart", Amount: 99, Currency: "USD"}, ""},
+		{"unknown authorization ID", CardEvent{AuthorizationID: "a9", MerchantName: "Amazon", Amount: 100}, ""},
+		{"authorization ID", CardEvent{AuthorizationID: "a2", Amount: 80}, "a2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.matchAuthorization(&tt.evt, pending)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.ID)
+		})
+	}
+}
+
+func TestCardNotifications(t *testing.T) {
+	cards := newMemCardStore()
+	sink := &memNotifications{}
+	engine := notify.NewEngine(nil)
+	notifications := notify.NewDispatcher(cards, notify.NewNotifier(engine, sink, sink), notify.DispatcherConfig{})
+	h := NewRainHandler(config.RainConfig{}, cards, nil, notifications)
+
+	post := func(handler http.HandlerFunc, body string) {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	}
+	post(h.HandleWebhook, `{"event_id":"evt-1","event_type":"card.created","data":{"card_id":"c1","user_id":"u1","last4":"4242"}}`)
+	post(h.HandleWebhook, `{"event_id":"evt-2","event_type":"card.topup","data":{"card_id":"c1","amount":50}}`)
+	post(h.HandleAuthorizationRequest, `{"authorization_id":"a1","card_id":"c1","merchant_name":"Shop","amount":80,"currency":"USD"}`)
+	require.Empty(t, sink.messages, "notifications are only enqueued")
+
+	_, err := notifications.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sink.messages, 3)
+	for _, msg := range sink.messages {
+		assert.Equal(t, "u1", msg.UserID, "card owner resolved from the card record")
+		assert.Equal(t, notify.DefaultLocale, msg.Locale, "falls back without a Spanish template")
+	}
+	assert.Equal(t, notify.EventCardCreated, sink.messages[0].Event)
+	assert.Contains(t, sink.messages[0].Body, "ending in 4242")
+	assert.Equal(t, "50.00 USD was added to your card. Balance: 50.00 USD.", sink.messages[1].Body)
+	assert.Equal(t, notify.EventCardDeclined, sink.messages[2].Event)
+	assert.Equal(t, "a1", sink.messages[2].ID)
+}
+
+func TestCardEventTransaction(t *testing.T) {
+	cards := newMemCardStore()
+	sink := &memNotifications{}
+	notifications := notify.NewDispatcher(cards, notify.NewNotifier(notify.NewEngine(nil), sink, sink), notify.DispatcherConfig{})
+	h := NewRainHandler(config.RainConfig{}, cards, nil, notifications)
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		h.HandleWebhook(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, post(`{"event_id":"evt-1","event_type":"card.created","data":{"card_id":"c1","user_id":"u1"}}`))
+	topUp := `{"event_id":"evt-2","event_type":"card.topup","data":{"card_id":"c1","amount":50}}`
+
+	t.Run("failed notification enqueue rolls back the balance change", func(t *testing.T) {
+		cards.enqueue = errors.New("connection reset")
+		assert.Equal(t, http.StatusInternalServerError, post(topUp), "issuer retries")
+		assert.Equal(t, 0.0, cards.balances["RAIN/c1"])
+		assert.False(t, cards.processed["evt-2"])
+		assert.Len(t, cards.outbox, 1, "only the card.created notification")
+	})
+
+	t.Run("retry applies the event once", func(t *testing.T) {
+		cards.enqueue = nil
+		require.Equal(t, http.StatusOK, post(topUp))
+		delete(cards.processed, "evt-2") // Redis 去重记录丢失时由数据库去重
+		require.Equal(t, http.StatusOK, post(topUp))
+		assert.Equal(t, 50.0, cards.balances["RAIN/c1"])
+		assert.Len(t, cards.outbox, 2)
+	})
+
+	t.Run("dispatcher delivers the outbox", func(t *testing.T) {
+		n, err := notifications.DispatchOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		require.Len(t, sink.messages, 2)
+		assert.Equal(t, "evt-2", sink.messages[1].ID)
+
+		n, err = notifications.DispatchOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n, "sent notifications are not claimed again")
+	})
+}
+
+func TestAuthorizationRules(t *testing.T) {
+	cards := newMemCardStore()
+	cards.balances["RAIN/c1"] = 500
+	h := NewRainHandler(config.RainConfig{}, cards, nil, nil)
+
+	engine := rules.NewEngine(nil)
+	engine.Set([]rules.Rule{{
+		Name:       "no gambling over 100",
+		Conditions: []rules.Condition{{Field: rules.FieldMCC, Op: rules.OpEq, Value: json.RawMessage(`"7995"`)}, {Field: rules.FieldAmount, Op: rules.OpGt, Value: json.RawMessage(`100`)}},
+		Action:     rules.ActionDecline,
+		Reason:     "prohibited_merchant",
+	}})
+	h.SetRules(engine)
+
+	authorize := func(body string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		h.HandleAuthorizationRequest(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := authorize(`{"authorization_id":"a1","card_id":"c1","merchant_category_code":"7995","amount":150}`)
+	assert.Equal(t, false, resp["approved"])
+	assert.Equal(t, "prohibited_merchant", resp["reason"])
+	assert.Equal(t, 500.0, cards.balances["RAIN/c1"], "declined by rule before any hold")
+
+	resp = authorize(`{"authorization_id":"a2","card_id":"c1","merchant_category_code":"7995","amount":50}`)
+	assert.Equal(t, true, resp["approved"])
+	assert.Equal(t, 450.0, cards.balances["RAIN/c1"])
+}
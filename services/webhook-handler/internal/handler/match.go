@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/protocol-bank/webhook-handler/internal/store"
+	"github.com/protocol-bank/webhook-handler/internal/stream"
+	"github.com/rs/zerolog/log"
+)
+
+// MatchPolicy 结算与授权的对账策略
+type MatchPolicy struct {
+	Window          time.Duration // 只匹配这段时间内的授权
+	AmountTolerance float64       // 结算金额与冻结金额的最大相对差 (小费、汇率)
+}
+
+// DefaultMatchPolicy 默认: 30 天内的授权，金额相差不超过 20%
+var DefaultMatchPolicy = MatchPolicy{
+	Window:          30 * 24 * time.Hour,
+	AmountTolerance: 0.2,
+}
+
+func (p MatchPolicy) withDefaults() MatchPolicy {
+	if p.Window <= 0 {
+		p.Window = DefaultMatchPolicy.Window
+	}
+	if p.AmountTolerance <= 0 {
+		p.AmountTolerance = DefaultMatchPolicy.AmountTolerance
+	}
+	return p
+}
+
+// matchAuthorization 为结算找到对应的待结算授权。
+// 发卡方提供授权 ID 时只按 ID 匹配；否则按金额、商户和时间推断:
+// 金额须在容差内且商户相符 (金额完全一致时商户可不同)，
+// 多个候选时优先商户相符、金额最接近、最早的授权。
+func (p MatchPolicy) matchAuthorization(st *CardEvent, pending []store.CardAuthorization) *store.CardAuthorization {
+	if st.AuthorizationID != "" {
+		for i := range pending {
+			if pending[i].ID == st.AuthorizationID {
+				return &pending[i]
+			}
+		}
+		return nil
+	}
+
+	type candidate struct {
+		auth     *store.CardAuthorization
+		merchant bool
+		diff     float64
+	}
+	var candidates []candidate
+	for i := range pending {
+		a := &pending[i]
+		if st.Currency != "" && a.Currency != "" && !strings.EqualFold(st.Currency, a.Currency) {
+			continue
+		}
+		diff := math.Abs(st.Amount - a.Amount)
+		if a.Amount <= 0 || diff/a.Amount > p.AmountTolerance {
+			continue
+		}
+		merchant := sameMerchant(st.MerchantName, a.MerchantName)
+		if !merchant && diff > 0.005 {
+			continue
+		}
+		candidates = append(candidates, candidate{auth: a, merchant: merchant, diff: diff})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].merchant != candidates[j].merchant {
+			return candidates[i].merchant
+		}
+		if candidates[i].diff != candidates[j].diff {
+			return candidates[i].diff < candidates[j].diff
+		}
+		return candidates[i].auth.CreatedAt.Before(candidates[j].auth.CreatedAt)
+	})
+	return candidates[0].auth
+}
+
+// sameMerchant 商户名宽松比较: 忽略大小写和标点，一方包含另一方即视为相同
+// (结算描述常带门店号或城市，如 "STARBUCKS #1234 SEATTLE")
+func sameMerchant(a, b string) bool {
+	a, b = normalizeMerchant(a), normalizeMerchant(b)
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+func normalizeMerchant(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// settle 结算入账: 匹配到授权时释放冻结与结算金额的差额，未匹配时全额扣款并标记人工复核
+func (h *CardHandler) settle(ctx context.Context, evt *CardEvent) (*balanceChange, error) {
+	pending, err := h.store.ListPendingAuthorizations(ctx, h.program.Name(), evt.CardID, time.Now().Add(-h.match.Window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending authorizations: %w", err)
+	}
+	st := store.CardSettlement{
+		TransactionID: evt.TransactionID,
+		CardID:        evt.CardID,
+		MerchantName:  evt.MerchantName,
+		Amount:        evt.Amount,
+		Currency:      evt.Currency,
+		Status:        evt.Status,
+	}
+	if st.Status == "" {
+		st.Status = "SETTLED"
+	}
+	if auth := h.match.matchAuthorization(evt, pending); auth != nil {
+		st.AuthorizationID = auth.ID
+	}
+
+	out, err := h.store.SettleCardTransaction(ctx, h.program.Name(), st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle card transaction %s: %w", evt.TransactionID, err)
+	}
+	if !out.Applied {
+		log.Info().Str("tx_id", evt.TransactionID).Msg("Transaction already settled, skipping")
+		return nil, nil
+	}
+	if out.NeedsReview {
+		log.Warn().
+			Str("tx_id", evt.TransactionID).
+			Str("card_id", evt.CardID).
+			Str("authorization_id", evt.AuthorizationID).
+			Str("merchant", evt.MerchantName).
+			Float64("amount", evt.Amount).
+			Msg("Settlement matched no pending authorization, flagged for review")
+	} else {
+		log.Info().
+			Str("tx_id", evt.TransactionID).
+			Str("authorization_id", st.AuthorizationID).
+			Float64("amount", evt.Amount).
+			Float64("released", out.Released).
+			Msg("Settlement matched authorization")
+	}
+	return &balanceChange{eventType: stream.EventSettlement, cardID: evt.CardID, amount: evt.Amount}, nil
+}
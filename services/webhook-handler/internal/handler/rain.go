@@ -1,21 +1,17 @@
 package handler
 
 import (
-	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
-	"io"
-	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/protocol-bank/webhook-handler/internal/config"
-	"github.com/protocol-bank/webhook-handler/internal/store"
-	"github.com/rs/zerolog/log"
+	"github.com/protocol-bank/webhook-handler/internal/notify"
+	"github.com/protocol-bank/webhook-handler/internal/signature"
+	"github.com/protocol-bank/webhook-handler/internal/stream"
 )
 
+// ProgramRain Rain 发卡方标识
+const ProgramRain = "RAIN"
+
 // RainWebhookPayload Rain 卡 Webhook 负载
 type RainWebhookPayload struct {
 	EventID   string          `json:"event_id"`
@@ -27,6 +23,7 @@ type RainWebhookPayload struct {
 // RainTransaction Rain 交易数据
 type RainTransaction struct {
 	TransactionID    string  `json:"transaction_id"`
+	AuthorizationID  string  `json:"authorization_id"`
 	CardID           string  `json:"card_id"`
 	UserID           string  `json:"user_id"`
 	MerchantName     string  `json:"merchant_name"`
@@ -39,268 +36,118 @@ type RainTransaction struct {
 
 // RainAuthorizationRequest Rain 授权请求
 type RainAuthorizationRequest struct {
-	AuthorizationID string  `json:"authorization_id"`
-	CardID          string  `json:"card_id"`
-	UserID          string  `json:"user_id"`
-	MerchantName    string  `json:"merchant_name"`
-	Amount          float64 `json:"amount"`
-	Currency        string  `json:"currency"`
+	AuthorizationID  string  `json:"authorization_id"`
+	CardID           string  `json:"card_id"`
+	UserID           string  `json:"user_id"`
+	MerchantName     string  `json:"merchant_name"`
+	MerchantCategory string  `json:"merchant_category_code"`
+	Amount           float64 `json:"amount"`
+	Currency         string  `json:"currency"`
 }
 
-// RainHandler Rain Webhook 处理器
-type RainHandler struct {
-	cfg   config.RainConfig
-	store *store.WebhookStore
+// rainEventTypes Rain 事件类型映射
+var rainEventTypes = map[string]CardEventType{
+	"card.transaction":   CardEventTransaction,
+	"card.created":       CardEventCreated,
+	"card.activated":     CardEventActivated,
+	"card.settlement":    CardEventSettlement,
+	"card.topup":         CardEventTopUp,
+	"card.limit_updated": CardEventLimitUpdated,
 }
 
-// NewRainHandler 创建 Rain 处理器
-func NewRainHandler(cfg config.RainConfig, store *store.WebhookStore) *RainHandler {
-	return &RainHandler{
-		cfg:   cfg,
-		store: store,
-	}
+// RainProgram Rain 发卡方适配器
+type RainProgram struct {
+	cfg config.RainConfig
 }
 
-// HandleWebhook 处理 Rain Webhook
-func (h *RainHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	// 读取请求体
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to read request body")
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
+// NewRainHandler 创建 Rain 处理器
+func NewRainHandler(cfg config.RainConfig, store CardStore, broker *stream.Broker, notifications *notify.Dispatcher) *CardHandler {
+	return NewCardHandler(RainProgram{cfg: cfg}, store, broker, notifications)
+}
 
-	// 验证签名
-	signature := r.Header.Get("X-Rain-Signature")
-	timestamp := r.Header.Get("X-Rain-Timestamp")
-	if !h.verifySignature(body, signature, timestamp) {
-		log.Warn().Str("signature", signature).Msg("Invalid webhook signature")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
+// Name implements CardProgram.
+func (RainProgram) Name() string { return ProgramRain }
 
-	// 防重放攻击检查
-	ts, _ := strconv.ParseInt(timestamp, 10, 64)
-	if time.Now().Unix()-ts > 300 { // 5 分钟过期
-		log.Warn().Int64("timestamp", ts).Msg("Webhook timestamp expired")
-		http.Error(w, "Request expired", http.StatusUnauthorized)
-		return
-	}
+// Scheme implements CardProgram.
+func (RainProgram) Scheme() signature.Scheme { return signature.RainScheme{} }
 
-	// 解析负载
+// ParseEvent implements CardProgram.
+func (RainProgram) ParseEvent(body []byte) (*CardEvent, error) {
 	var payload RainWebhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
-		log.Error().Err(err).Msg("Failed to parse webhook payload")
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-
-	// 检查重复处理
-	processed, err := h.store.IsProcessed(r.Context(), payload.EventID)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to check duplicate")
-		http.Error(w, "Internal error", http.StatusInternalServerError)
-		return
-	}
-	if processed {
-		log.Info().Str("event_id", payload.EventID).Msg("Duplicate webhook, skipping")
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	log.Info().
-		Str("event_id", payload.EventID).
-		Str("event_type", payload.EventType).
-		Msg("Processing Rain webhook")
-
-	// 根据事件类型处理
-	switch payload.EventType {
-	case "card.transaction":
-		h.handleTransaction(r.Context(), payload)
-	case "card.created":
-		h.handleCardCreated(r.Context(), payload)
-	case "card.activated":
-		h.handleCardActivated(r.Context(), payload)
-	case "card.settlement":
-		h.handleSettlement(r.Context(), payload)
-	default:
-		log.Warn().Str("event_type", payload.EventType).Msg("Unknown event type")
-	}
-
-	// 标记为已处理
-	if err := h.store.MarkProcessed(r.Context(), payload.EventID, string(body)); err != nil {
-		log.Error().Err(err).Msg("Failed to mark as processed")
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-}
-
-// HandleAuthorizationRequest 处理实时授权请求
-func (h *RainHandler) HandleAuthorizationRequest(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-
-	// 验证签名
-	signature := r.Header.Get("X-Rain-Signature")
-	timestamp := r.Header.Get("X-Rain-Timestamp")
-	if !h.verifySignature(body, signature, timestamp) {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	var authReq RainAuthorizationRequest
-	if err := json.Unmarshal(body, &authReq); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-
-	log.Info().
-		Str("auth_id", authReq.AuthorizationID).
-		Str("card_id", authReq.CardID).
-		Float64("amount", authReq.Amount).
-		Str("merchant", authReq.MerchantName).
-		Msg("Processing authorization request")
-
-	// 检查用户余额和限额
-	approved, reason := h.checkAuthorization(r.Context(), authReq)
-
-	// 返回授权决定
-	response := map[string]interface{}{
-		"authorization_id": authReq.AuthorizationID,
-		"approved":         approved,
-		"reason":           reason,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
-
-// verifySignature 验证 HMAC 签名
-func (h *RainHandler) verifySignature(body []byte, signature, timestamp string) bool {
-	if h.cfg.WebhookSecret == "" {
-		log.Error().Msg("SECURITY: Webhook secret is not configured - rejecting request")
-		return false // SECURITY: Never accept webhooks without secret verification
+		return nil, err
 	}
 
-	// 构造签名消息
-	message := timestamp + "." + string(body)
-
-	// 计算 HMAC-SHA256
-	mac := hmac.New(sha256.New, []byte(h.cfg.WebhookSecret))
-	mac.Write([]byte(message))
-	expectedSig := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedSig))
-}
-
-// handleTransaction 处理交易事件
-func (h *RainHandler) handleTransaction(ctx interface{}, payload RainWebhookPayload) {
-	var tx RainTransaction
-	if err := json.Unmarshal(payload.Data, &tx); err != nil {
-		log.Error().Err(err).Msg("Failed to parse transaction data")
-		return
-	}
-
-	log.Info().
-		Str("tx_id", tx.TransactionID).
-		Str("merchant", tx.MerchantName).
-		Float64("amount", tx.Amount).
-		Str("status", tx.Status).
-		Msg("Card transaction processed")
-
-	// Sync to Database
-	if err := h.store.UpdateCardTransaction(context.Background(), tx.TransactionID, tx.CardID, tx.MerchantName, tx.Amount, tx.Currency, tx.Status); err != nil {
-		log.Error().Err(err).Msg("Failed to persist card transaction")
-	}
-
-	// Update Balance if settled
-	if tx.Status == "SETTLED" || tx.Status == "COMPLETED" {
-		if err := h.store.UpdateCardBalance(context.Background(), tx.CardID, tx.Amount); err != nil {
-			log.Error().Err(err).Msg("Failed to update card balance")
+	evt := &CardEvent{ID: payload.EventID, Type: rainEventTypes[payload.EventType], IssuerRaw: payload.EventType}
+	switch evt.Type {
+	case CardEventTransaction, CardEventSettlement:
+		var tx RainTransaction
+		if err := json.Unmarshal(payload.Data, &tx); err != nil {
+			return evt, err
 		}
+		evt.TransactionID = tx.TransactionID
+		evt.AuthorizationID = tx.AuthorizationID
+		evt.CardID = tx.CardID
+		evt.UserID = tx.UserID
+		evt.MerchantName = tx.MerchantName
+		evt.Amount = tx.Amount
+		evt.Currency = tx.Currency
+		evt.Status = tx.Status
+		evt.Settled = evt.Type == CardEventSettlement || tx.Status == "SETTLED" || tx.Status == "COMPLETED"
+	case CardEventCreated:
+		var card struct {
+			CardID string `json:"card_id"`
+			UserID string `json:"user_id"`
+			Last4  string `json:"last4"`
+		}
+		if err := json.Unmarshal(payload.Data, &card); err != nil {
+			return evt, err
+		}
+		evt.CardID, evt.UserID, evt.Last4 = card.CardID, card.UserID, card.Last4
+	case CardEventActivated, CardEventTopUp:
+		var data struct {
+			CardID string  `json:"card_id"`
+			Amount float64 `json:"amount"`
+		}
+		if err := json.Unmarshal(payload.Data, &data); err != nil {
+			return evt, err
+		}
+		evt.CardID, evt.Amount = data.CardID, data.Amount
+	case CardEventLimitUpdated:
+		var data struct {
+			CardID        string   `json:"card_id"`
+			SpendingLimit *float64 `json:"spending_limit"`
+		}
+		if err := json.Unmarshal(payload.Data, &data); err != nil {
+			return evt, err
+		}
+		evt.CardID, evt.SpendingLimit = data.CardID, data.SpendingLimit
 	}
+	return evt, nil
 }
 
-// handleCardCreated 处理卡片创建事件
-func (h *RainHandler) handleCardCreated(ctx interface{}, payload RainWebhookPayload) {
-	log.Info().Str("event_id", payload.EventID).Msg("Card created event")
-
-	// Parse card data from payload
-	type CardData struct {
-		CardID string `json:"card_id"`
-		UserID string `json:"user_id"`
-		Last4  string `json:"last4"`
-	}
-	var card CardData
-	if err := json.Unmarshal(payload.Data, &card); err != nil {
-		log.Error().Err(err).Msg("Failed to parse card created data")
-		return
-	}
-
-	if err := h.store.UpsertCardStatus(context.Background(), card.CardID, card.UserID, card.Last4, "INACTIVE"); err != nil {
-		log.Error().Err(err).Str("card_id", card.CardID).Msg("Failed to create card record")
-	}
-}
-
-// handleCardActivated 处理卡片激活事件
-func (h *RainHandler) handleCardActivated(ctx interface{}, payload RainWebhookPayload) {
-	log.Info().Str("event_id", payload.EventID).Msg("Card activated event")
-
-	type CardEvent struct {
-		CardID string `json:"card_id"`
-	}
-	var evt CardEvent
-	if err := json.Unmarshal(payload.Data, &evt); err != nil {
-		log.Error().Err(err).Msg("Failed to parse card activated data")
-		return
-	}
-
-	if err := h.store.UpdateCardStatusByExternalID(context.Background(), evt.CardID, "ACTIVE"); err != nil {
-		log.Error().Err(err).Str("card_id", evt.CardID).Msg("Failed to activate card")
-	}
-}
-
-// handleSettlement 处理结算事件
-func (h *RainHandler) handleSettlement(ctx interface{}, payload RainWebhookPayload) {
-	log.Info().Str("event_id", payload.EventID).Msg("Settlement event")
-
-	type SettlementData struct {
-		CardID string  `json:"card_id"`
-		Amount float64 `json:"amount"`
-	}
-	var s SettlementData
-	if err := json.Unmarshal(payload.Data, &s); err != nil {
-		log.Error().Err(err).Msg("Failed to parse settlement data")
-		return
-	}
-
-	// Settlement confirms the final deduction; balance was already reduced at authorization.
-	// Here we log for reconciliation. If amounts differ, adjust.
-	log.Info().Str("card_id", s.CardID).Float64("settled_amount", s.Amount).Msg("Settlement reconciled")
+// ParseAuthorization implements CardProgram.
+func (RainProgram) ParseAuthorization(body []byte) (*CardAuthorization, error) {
+	var req RainAuthorizationRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return &CardAuthorization{
+		ID:           req.AuthorizationID,
+		CardID:       req.CardID,
+		UserID:       req.UserID,
+		MerchantName: req.MerchantName,
+		MCC:          req.MerchantCategory,
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+	}, nil
 }
 
-// checkAuthorization 检查授权
-func (h *RainHandler) checkAuthorization(ctx interface{}, req RainAuthorizationRequest) (bool, string) {
-	// 1. Check User Balance (Pre-funded Model)
-	balance, err := h.store.GetCardBalance(context.Background(), req.CardID)
-	if err != nil {
-		log.Error().Err(err).Str("card_id", req.CardID).Msg("Failed to check balance during auth")
-		return false, "issuer_decline" // Fail safe
-	}
-
-	if balance < req.Amount {
-		log.Warn().Str("card_id", req.CardID).Float64("balance", balance).Float64("req_amount", req.Amount).Msg("Insufficient funds")
-		return false, "insufficient_funds" // Rain specific decline code
+// AuthorizationResponse implements CardProgram. Rain 的拒绝码与通用原因一致。
+func (RainProgram) AuthorizationResponse(req *CardAuthorization, approved bool, reason DeclineReason) interface{} {
+	return map[string]interface{}{
+		"authorization_id": req.ID,
+		"approved":         approved,
+		"reason":           string(reason),
 	}
-
-	// 2. Risk Checks (Example: Block "Gambling" MCC 7995)
-	// if req.MerchantCategoryCode == "7995" { return false, "prohibited_merchant" }
-
-	return true, "approved"
 }
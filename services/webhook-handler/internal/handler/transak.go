@@ -2,14 +2,13 @@ package handler
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/protocol-bank/webhook-handler/internal/config"
+	"github.com/protocol-bank/webhook-handler/internal/notify"
 	"github.com/protocol-bank/webhook-handler/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -38,19 +37,21 @@ type TransakOrder struct {
 
 // TransakHandler Transak Webhook 处理器
 type TransakHandler struct {
-	cfg   config.TransakConfig
-	store *store.WebhookStore
+	cfg           config.TransakConfig
+	store         *store.WebhookStore
+	notifications *notify.Dispatcher // 用户通知 (可选)，经 outbox 投递
 }
 
 // NewTransakHandler 创建 Transak 处理器
-func NewTransakHandler(cfg config.TransakConfig, store *store.WebhookStore) *TransakHandler {
+func NewTransakHandler(cfg config.TransakConfig, store *store.WebhookStore, notifications *notify.Dispatcher) *TransakHandler {
 	return &TransakHandler{
-		cfg:   cfg,
-		store: store,
+		cfg:           cfg,
+		store:         store,
+		notifications: notifications,
 	}
 }
 
-// HandleWebhook 处理 Transak Webhook
+// HandleWebhook 处理 Transak Webhook (须挂载在 signature.Verifier 中间件之后)
 func (h *TransakHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -59,14 +60,6 @@ func (h *TransakHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 验证签名
-	signature := r.Header.Get("X-Transak-Signature")
-	if !h.verifySignature(body, signature) {
-		log.Warn().Str("signature", signature).Msg("Invalid Transak webhook signature")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
 	var payload TransakWebhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		log.Error().Err(err).Msg("Failed to parse webhook payload")
@@ -93,17 +86,34 @@ func (h *TransakHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		Str("order_id", payload.Data.OrderID).
 		Msg("Processing Transak webhook")
 
-	switch payload.EventType {
-	case "ORDER_COMPLETED":
-		h.handleOrderCompleted(r.Context(), payload.Data)
-	case "ORDER_PROCESSING":
-		h.handleOrderProcessing(r.Context(), payload.Data)
-	case "ORDER_FAILED":
-		h.handleOrderFailed(r.Context(), payload.Data)
-	case "ORDER_CANCELLED":
-		h.handleOrderCancelled(r.Context(), payload.Data)
-	default:
-		log.Warn().Str("event_type", payload.EventType).Msg("Unknown Transak event type")
+	// 订单写入与通知入队同一事务提交，失败时返回 500 由 Transak 重试
+	applied, err := h.store.ProcessEvent(r.Context(), "TRANSAK", payload.WebhookID, payload.EventType, body, func(ctx context.Context) error {
+		switch payload.EventType {
+		case "ORDER_COMPLETED":
+			if err := h.handleOrderCompleted(ctx, payload.Data); err != nil {
+				return err
+			}
+			return h.notifyOrder(ctx, payload.WebhookID, notify.EventOrderCompleted, payload.Data)
+		case "ORDER_PROCESSING":
+			h.handleOrderProcessing(ctx, payload.Data)
+		case "ORDER_FAILED":
+			h.handleOrderFailed(ctx, payload.Data)
+			return h.notifyOrder(ctx, payload.WebhookID, notify.EventOrderFailed, payload.Data)
+		case "ORDER_CANCELLED":
+			h.handleOrderCancelled(ctx, payload.Data)
+			return h.notifyOrder(ctx, payload.WebhookID, notify.EventOrderCancelled, payload.Data)
+		default:
+			log.Warn().Str("event_type", payload.EventType).Msg("Unknown Transak event type")
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Str("webhook_id", payload.WebhookID).Msg("Failed to process Transak webhook")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if applied && h.notifications != nil {
+		h.notifications.Wake()
 	}
 
 	if err := h.store.MarkProcessed(r.Context(), payload.WebhookID, string(body)); err != nil {
@@ -114,21 +124,7 @@ func (h *TransakHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-// verifySignature 验证签名
-func (h *TransakHandler) verifySignature(body []byte, signature string) bool {
-	if h.cfg.WebhookSecret == "" {
-		log.Error().Msg("SECURITY: Webhook secret is not configured - rejecting request")
-		return false // SECURITY: Never accept webhooks without secret verification
-	}
-
-	mac := hmac.New(sha256.New, []byte(h.cfg.WebhookSecret))
-	mac.Write(body)
-	expectedSig := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedSig))
-}
-
-func (h *TransakHandler) handleOrderCompleted(ctx interface{}, order TransakOrder) {
+func (h *TransakHandler) handleOrderCompleted(ctx context.Context, order TransakOrder) error {
 	log.Info().
 		Str("order_id", order.OrderID).
 		Float64("fiat_amount", order.FiatAmount).
@@ -138,9 +134,10 @@ func (h *TransakHandler) handleOrderCompleted(ctx interface{}, order TransakOrde
 		Msg("Transak order completed")
 
 	// Save to DB
-	if err := h.store.UpsertFiatOrder(context.Background(), order.OrderID, order.Status, order.FiatAmount, order.FiatCurrency, order.WalletAddress, order.TxHash); err != nil {
-		log.Error().Err(err).Msg("Failed to update fiat order db")
+	if err := h.store.UpsertFiatOrder(ctx, order.OrderID, order.Status, order.FiatAmount, order.FiatCurrency, order.WalletAddress, order.TxHash); err != nil {
+		return fmt.Errorf("failed to update fiat order db: %w", err)
 	}
+	return nil
 }
 
 func (h *TransakHandler) handleOrderProcessing(ctx interface{}, order TransakOrder) {
@@ -154,3 +151,27 @@ func (h *TransakHandler) handleOrderFailed(ctx interface{}, order TransakOrder)
 func (h *TransakHandler) handleOrderCancelled(ctx interface{}, order TransakOrder) {
 	log.Info().Str("order_id", order.OrderID).Msg("Transak order cancelled")
 }
+
+// notifyOrder 将通知写入 outbox，通知钱包所属用户 (钱包未绑定用户时跳过)
+func (h *TransakHandler) notifyOrder(ctx context.Context, id string, event notify.Event, order TransakOrder) error {
+	if h.notifications == nil || order.WalletAddress == "" {
+		return nil
+	}
+	userID, err := h.store.UserIDByWallet(ctx, order.WalletAddress)
+	if err != nil {
+		return fmt.Errorf("failed to look up order owner: %w", err)
+	}
+	if userID == "" {
+		return nil
+	}
+	vars := notify.Vars{
+		"OrderID":        order.OrderID,
+		"FiatAmount":     order.FiatAmount,
+		"FiatCurrency":   order.FiatCurrency,
+		"CryptoAmount":   order.CryptoAmount,
+		"CryptoCurrency": order.CryptoCurrency,
+		"Network":        order.Network,
+		"TxHash":         order.TxHash,
+	}
+	return h.store.EnqueueNotification(ctx, notify.Notification{ID: "transak:" + id, UserID: userID, Event: event, Vars: vars})
+}
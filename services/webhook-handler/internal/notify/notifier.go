@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Message 推送给用户的通知 (发布到 Redis 频道 notifications:<user_id>)
+type Message struct {
+	ID        string `json:"id"` // 来源事件 ID，消费方据此去重
+	Event     Event  `json:"event"`
+	UserID    string `json:"user_id"`
+	Locale    string `json:"locale"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Data      Vars   `json:"data,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// UserLocales 用户语言偏好 (auth_users.locale)，未设置时返回 ""
+type UserLocales interface {
+	UserLocale(ctx context.Context, userID string) (string, error)
+}
+
+// Publisher 跨实例广播 (WebhookStore 基于 Redis 实现)
+type Publisher interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+}
+
+// Notifier 按用户语言渲染并发布通知
+type Notifier struct {
+	engine  *Engine
+	locales UserLocales
+	pub     Publisher
+	now     func() time.Time
+}
+
+// NewNotifier 创建 Notifier
+func NewNotifier(engine *Engine, locales UserLocales, pub Publisher) *Notifier {
+	return &Notifier{engine: engine, locales: locales, pub: pub, now: time.Now}
+}
+
+// ChannelFor 用户通知频道
+func ChannelFor(userID string) string {
+	return "notifications:" + userID
+}
+
+// Notify 渲染并发布一条通知。语言查询失败时使用默认语言。
+func (n *Notifier) Notify(ctx context.Context, id, userID string, event Event, vars Vars) error {
+	if userID == "" {
+		return errors.New("notification has no user_id")
+	}
+	locale, err := n.locales.UserLocale(ctx, userID)
+	if err != nil {
+		locale = DefaultLocale
+	}
+	rendered, err := n.engine.Render(event, locale, vars)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(Message{
+		ID:        id,
+		Event:     event,
+		UserID:    userID,
+		Locale:    rendered.Locale,
+		Title:     rendered.Title,
+		Body:      rendered.Body,
+		Data:      vars,
+		Timestamp: n.now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	return n.pub.Publish(ctx, ChannelFor(userID), payload)
+}
@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memSource struct {
+	templates []Template
+	err       error
+}
+
+func (m *memSource) ListNotificationTemplates(context.Context) ([]Template, error) {
+	return m.templates, m.err
+}
+
+type memLocales map[string]string
+
+func (m memLocales) UserLocale(_ context.Context, userID string) (string, error) {
+	return m[userID], nil
+}
+
+type memPublisher struct {
+	channel string
+	payload []byte
+}
+
+func (m *memPublisher) Publish(_ context.Context, channel string, payload []byte) error {
+	m.channel, m.payload = channel, payload
+	return nil
+}
+
+// flakyPublisher fails the first n publishes.
+type flakyPublisher struct {
+	fail      int
+	published []string
+}
+
+func (f *flakyPublisher) Publish(_ context.Context, _ string, payload []byte) error {
+	if f.fail > 0 {
+		f.fail--
+		return errors.New("redis unavailable")
+	}
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return err
+	}
+	f.published = append(f.published, msg.Body)
+	return nil
+}
+
+type memOutboxEntry struct {
+	Pending
+	due    time.Time
+	sent   bool
+	failed bool
+	cause  string
+}
+
+// memOutbox is an in-memory Outbox with a controllable clock.
+type memOutbox struct {
+	now     time.Time
+	entries []*memOutboxEntry
+}
+
+func (m *memOutbox) find(id string) *memOutboxEntry {
+	for _, e := range m.entries {
+		if e.OutboxID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (m *memOutbox) ClaimNotifications(_ context.Context, limit int, lease time.Duration) ([]Pending, error) {
+	var out []Pending
+	for _, e := range m.entries {
+		if !e.sent && !e.failed && !e.due.After(m.now) && len(out) < limit {
+			e.due = m.now.Add(lease)
+			out = append(out, e.Pending)
+		}
+	}
+	return out, nil
+}
+
+func (m *memOutbox) MarkNotificationSent(_ context.Context, id string) error {
+	m.find(id).sent = true
+	return nil
+}
+
+func (m *memOutbox) RetryNotification(_ context.Context, id string, delay time.Duration, cause string) error {
+	e := m.find(id)
+	e.Attempts++
+	e.due, e.cause = m.now.Add(delay), cause
+	return nil
+}
+
+func (m *memOutbox) FailNotification(_ context.Context, id string, cause string) error {
+	e := m.find(id)
+	e.Attempts++
+	e.failed, e.cause = true, cause
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	ctx := context.Background()
+	src := &memSource{templates: []Template{
+		{EventCardTopUp, "zh-CN", "卡片充值", "已到账 {{money .Amount}} {{.Currency}}"},
+		{EventCardTopUp, "pt", "Recarga", "{{money .Amount}} {{.Currency}} adicionados"},
+		{EventCardActivated, "fr", "Carte activée", "{{.Broken"},
+	}}
+	e := NewEngine(src)
+	vars := Vars{"Amount": 12.5, "Currency": "USD", "Balance": 40.0}
+
+	t.Run("built-in templates before reload", func(t *testing.T) {
+		r, err := e.Render(EventCardTopUp, "zh-CN", vars)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultLocale, r.Locale)
+		assert.Equal(t, "12.50 USD was added to your card. Balance: 40.00 USD.", r.Body)
+	})
+
+	require.NoError(t, e.Reload(ctx))
+
+	t.Run("locale fallback", func(t *testing.T) {
+		r, err := e.Render(EventCardTopUp, "zh_cn", vars)
+		require.NoError(t, err)
+		assert.Equal(t, "zh-cn", r.Locale)
+		assert.Equal(t, "卡片充值", r.Title)
+		assert.Equal(t, "已到账 12.50 USD", r.Body)
+
+		r, err = e.Render(EventCardTopUp, "pt-BR", vars)
+		require.NoError(t, err)
+		assert.Equal(t, "pt", r.Locale)
+
+		r, err = e.Render(EventCardTopUp, "de", vars)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultLocale, r.Locale)
+	})
+
+	t.Run("invalid templates keep the default", func(t *testing.T) {
+		r, err := e.Render(EventCardActivated, "fr", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Card activated", r.Title)
+	})
+
+	t.Run("failed reload keeps current templates", func(t *testing.T) {
+		src.err = errors.New("db down")
+		assert.Error(t, e.Reload(ctx))
+		r, err := e.Render(EventCardTopUp, "pt", vars)
+		require.NoError(t, err)
+		assert.Equal(t, "Recarga", r.Title)
+	})
+
+	t.Run("optional variables", func(t *testing.T) {
+		r, err := e.Render(EventCardLimitUpdated, "", Vars{"Limit": (*float64)(nil), "Currency": "USD"})
+		require.NoError(t, err)
+		assert.Equal(t, "Your spending limit was removed.", r.Body)
+
+		limit := 500.0
+		r, err = e.Render(EventCardLimitUpdated, "", Vars{"Limit": &limit, "Currency": "USD"})
+		require.NoError(t, err)
+		assert.Equal(t, "Your spending limit is now 500.00 USD.", r.Body)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	e := NewEngine(&memSource{templates: []Template{
+		{EventCardDeclined, "es", "Pago rechazado", "{{money .Amount}} {{.Currency}} en {{.Merchant}}"},
+	}})
+	require.NoError(t, e.Reload(context.Background()))
+	pub := &memPublisher{}
+	n := NewNotifier(e, memLocales{"u1": "es-MX"}, pub)
+
+	vars := Vars{"Amount": 9.99, "Currency": "EUR", "Merchant": "Café", "Reason": "insufficient_funds"}
+	require.NoError(t, n.Notify(context.Background(), "auth-1", "u1", EventCardDeclined, vars))
+	assert.Equal(t, "notifications:u1", pub.channel)
+
+	var msg Message
+	require.NoError(t, json.Unmarshal(pub.payload, &msg))
+	assert.Equal(t, "auth-1", msg.ID)
+	assert.Equal(t, "es", msg.Locale)
+	assert.Equal(t, "Pago rechazado", msg.Title)
+	assert.Equal(t, "9.99 EUR en Café", msg.Body)
+
+	// 未设置语言的用户使用默认模板
+	require.NoError(t, n.Notify(context.Background(), "auth-2", "u2", EventCardDeclined, vars))
+	require.NoError(t, json.Unmarshal(pub.payload, &msg))
+	assert.Equal(t, "9.99 EUR at Café was declined due to insufficient funds.", msg.Body)
+
+	assert.Error(t, n.Notify(context.Background(), "auth-3", "", EventCardDeclined, vars))
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	outbox := &memOutbox{now: start}
+	// Vars 经 JSON 存取后 *float64 变为 float64
+	outbox.entries = []*memOutboxEntry{
+		{Pending: Pending{OutboxID: "o1", Notification: Notification{ID: "evt-1", UserID: "u1", Event: EventCardTopUp, Vars: Vars{"Amount": 5.0, "Balance": 20.0, "Currency": "USD"}}}},
+		{Pending: Pending{OutboxID: "o2", Notification: Notification{ID: "evt-2", UserID: "u1", Event: EventCardLimitUpdated, Vars: Vars{"Limit": 300.0, "Currency": "USD"}}}},
+	}
+	pub := &flakyPublisher{fail: 1}
+	d := NewDispatcher(outbox, NewNotifier(NewEngine(nil), memLocales{}, pub), DispatcherConfig{MaxAttempts: 3})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		n, err := d.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"Your spending limit is now 300.00 USD."}, pub.published)
+		assert.Equal(t, 1, outbox.entries[0].Attempts)
+		assert.Equal(t, "redis unavailable", outbox.entries[0].cause)
+		assert.Equal(t, start.Add(retryBase), outbox.entries[0].due)
+
+		n, err = d.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "not due yet")
+
+		outbox.now = outbox.now.Add(retryBase)
+		_, err = d.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.True(t, outbox.entries[0].sent)
+		assert.Equal(t, "5.00 USD was added to your card. Balance: 20.00 USD.", pub.published[1])
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		outbox.entries = append(outbox.entries, &memOutboxEntry{Pending: Pending{OutboxID: "o3", Notification: Notification{ID: "evt-3", Event: EventCardActivated}}})
+		for i := 0; i < 3; i++ {
+			_, err := d.DispatchOnce(ctx)
+			require.NoError(t, err)
+			outbox.now = outbox.now.Add(retryMax)
+		}
+		e := outbox.find("o3")
+		assert.True(t, e.failed, "a notification without a user never succeeds")
+		assert.Equal(t, 3, e.Attempts)
+	})
+
+	t.Run("retry delay", func(t *testing.T) {
+		assert.Equal(t, 10*time.Second, retryDelay(1))
+		assert.Equal(t, 80*time.Second, retryDelay(4))
+		assert.Equal(t, retryMax, retryDelay(9))
+		assert.Equal(t, retryMax, retryDelay(100))
+	})
+}
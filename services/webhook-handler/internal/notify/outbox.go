@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Notification 待发送的通知，与触发它的业务写入在同一数据库事务中写入 outbox
+type Notification struct {
+	ID     string // Message.ID，同一 ID 只入队一次
+	UserID string
+	Event  Event
+	Vars   Vars
+}
+
+// Pending outbox 中已领取、待投递的通知
+type Pending struct {
+	Notification
+	OutboxID string
+	Attempts int // 此前失败的投递次数
+}
+
+// Outbox 通知 outbox (WebhookStore 基于 notification_outbox 表实现)
+type Outbox interface {
+	// ClaimNotifications 领取到期的通知，lease 内不会再被其他实例领取
+	ClaimNotifications(ctx context.Context, limit int, lease time.Duration) ([]Pending, error)
+	MarkNotificationSent(ctx context.Context, outboxID string) error
+	// RetryNotification 记录失败，delay 后重新投递
+	RetryNotification(ctx context.Context, outboxID string, delay time.Duration, cause string) error
+	// FailNotification 记录失败并放弃投递
+	FailNotification(ctx context.Context, outboxID string, cause string) error
+}
+
+// DispatcherConfig 投递参数 (零值字段使用默认值)
+type DispatcherConfig struct {
+	Interval    time.Duration // 轮询间隔 (未被 Wake 唤醒时)
+	BatchSize   int
+	Lease       time.Duration // 领取后多久未确认视为投递中断，可重新领取
+	MaxAttempts int           // 超过后放弃，保留在 outbox 中供排查
+}
+
+// 失败重试的退避: 10s, 20s, 40s ... 最长 30 分钟
+const (
+	retryBase = 10 * time.Second
+	retryMax  = 30 * time.Minute
+)
+
+func (c DispatcherConfig) withDefaults() DispatcherConfig {
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+	return c
+}
+
+// Dispatcher 从 outbox 领取通知并通过 Notifier 发布。
+// 发布成功但确认失败时通知会在 lease 到期后重发，消费方按 Message.ID 去重。
+type Dispatcher struct {
+	outbox   Outbox
+	notifier *Notifier
+	cfg      DispatcherConfig
+	wake     chan struct{}
+}
+
+// NewDispatcher 创建 Dispatcher
+func NewDispatcher(outbox Outbox, notifier *Notifier, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		outbox:   outbox,
+		notifier: notifier,
+		cfg:      cfg.withDefaults(),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Wake 通知已入队的事务提交后调用，立即投递而不必等待下次轮询
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 持续投递 outbox 中的通知，直到 ctx 取消
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// drain 投递所有到期通知
+func (d *Dispatcher) drain(ctx context.Context) {
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Warn().Err(err).Msg("Failed to claim notifications from outbox")
+			}
+			return
+		}
+		if n < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// DispatchOnce 领取并投递一批通知，返回领取的数量
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	batch, err := d.outbox.ClaimNotifications(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range batch {
+		d.deliver(ctx, p)
+	}
+	return len(batch), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, p Pending) {
+	err := d.notifier.Notify(ctx, p.ID, p.UserID, p.Event, p.Vars)
+	if err == nil {
+		if err := d.outbox.MarkNotificationSent(ctx, p.OutboxID); err != nil {
+			log.Error().Err(err).Str("notification_id", p.ID).Msg("Failed to mark notification as sent")
+		}
+		return
+	}
+
+	attempts := p.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		log.Error().Err(err).Str("notification_id", p.ID).Str("event", string(p.Event)).Int("attempts", attempts).Msg("Giving up on notification")
+		err = d.outbox.FailNotification(ctx, p.OutboxID, err.Error())
+	} else {
+		delay := retryDelay(attempts)
+		log.Warn().Err(err).Str("notification_id", p.ID).Str("event", string(p.Event)).Dur("retry_in", delay).Msg("Failed to send notification")
+		err = d.outbox.RetryNotification(ctx, p.OutboxID, delay, err.Error())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("notification_id", p.ID).Msg("Failed to record notification failure")
+	}
+}
+
+// retryDelay 第 attempts 次失败后的重试间隔
+func retryDelay(attempts int) time.Duration {
+	if attempts > 16 {
+		return retryMax
+	}
+	return min(retryBase<<(attempts-1), retryMax)
+}
@@ -0,0 +1,251 @@
+// Package notify renders user-facing card and payment notifications from
+// per-event, per-locale templates. Templates are stored in the
+// notification_templates table and reloaded at runtime, so copy and
+// translations can change without a redeploy. Built-in English templates
+// are the fallback for anything not in the table.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event 通知类型 (notification_templates.event_type)
+type Event string
+
+const (
+	EventCardAuthorized   Event = "card.authorized"      // 刷卡授权通过
+	EventCardDeclined     Event = "card.declined"        // 刷卡被拒
+	EventCardCreated      Event = "card.created"         // 卡片已发行
+	EventCardActivated    Event = "card.activated"       // 卡片已激活
+	EventCardTopUp        Event = "card.topup"           // 卡片充值到账
+	EventCardLimitUpdated Event = "card.limit_updated"   // 限额变更
+	EventOrderCompleted   Event = "fiat_order.completed" // 法币购币完成
+	EventOrderFailed      Event = "fiat_order.failed"    // 法币购币失败
+	EventOrderCancelled   Event = "fiat_order.cancelled" // 法币购币取消
+)
+
+// DefaultLocale 用户未设置语言或无对应翻译时使用
+const DefaultLocale = "en"
+
+// ReloadChannel 管理端修改模板后向该 Redis 频道发布消息，各实例立即重新加载
+const ReloadChannel = "notification:templates:reload"
+
+// Template 一条通知模板。Title 和 Body 使用 Go text/template 语法，变量见各事件的 Vars。
+type Template struct {
+	Event  Event
+	Locale string
+	Title  string
+	Body   string
+}
+
+// Vars 模板变量
+type Vars map[string]interface{}
+
+// Rendered 渲染结果
+type Rendered struct {
+	Locale string // 实际使用的模板语言
+	Title  string
+	Body   string
+}
+
+// Source 模板来源 (WebhookStore 基于 notification_templates 表实现)
+type Source interface {
+	ListNotificationTemplates(ctx context.Context) ([]Template, error)
+}
+
+// defaultTemplates 内置英文模板
+var defaultTemplates = []Template{
+	{EventCardAuthorized, DefaultLocale, "Card payment",
+		"{{money .Amount}} {{.Currency}} at {{.Merchant}}."},
+	{EventCardDeclined, DefaultLocale, "Card payment declined",
+		"{{money .Amount}} {{.Currency}} at {{.Merchant}} was declined{{if eq .Reason \"insufficient_funds\"}} due to insufficient funds{{end}}."},
+	{EventCardCreated, DefaultLocale, "Your card is ready",
+		"Your card{{if .Last4}} ending in {{.Last4}}{{end}} has been issued. Activate it to start spending."},
+	{EventCardActivated, DefaultLocale, "Card activated",
+		"Your card is now active."},
+	{EventCardTopUp, DefaultLocale, "Card topped up",
+		"{{money .Amount}} {{.Currency}} was added to your card. Balance: {{money .Balance}} {{.Currency}}."},
+	{EventCardLimitUpdated, DefaultLocale, "Spending limit updated",
+		"{{if .Limit}}Your spending limit is now {{money .Limit}} {{.Currency}}.{{else}}Your spending limit was removed.{{end}}"},
+	{EventOrderCompleted, DefaultLocale, "Purchase completed",
+		"{{.CryptoAmount}} {{.CryptoCurrency}} for {{money .FiatAmount}} {{.FiatCurrency}} was sent to your wallet."},
+	{EventOrderFailed, DefaultLocale, "Purchase failed",
+		"Your {{money .FiatAmount}} {{.FiatCurrency}} purchase of {{.CryptoCurrency}} could not be completed."},
+	{EventOrderCancelled, DefaultLocale, "Purchase cancelled",
+		"Your {{money .FiatAmount}} {{.FiatCurrency}} purchase of {{.CryptoCurrency}} was cancelled."},
+}
+
+var funcs = template.FuncMap{
+	// money 保留两位小数
+	"money": func(v interface{}) string {
+		switch n := v.(type) {
+		case float64:
+			return fmt.Sprintf("%.2f", n)
+		case *float64:
+			if n == nil {
+				return ""
+			}
+			return fmt.Sprintf("%.2f", *n)
+		default:
+			return fmt.Sprint(v)
+		}
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+type templateKey struct {
+	event  Event
+	locale string
+}
+
+type compiled struct {
+	title *template.Template
+	body  *template.Template
+}
+
+func compile(t Template) (*compiled, error) {
+	name := string(t.Event) + "/" + t.Locale
+	title, err := template.New(name + "/title").Funcs(funcs).Option("missingkey=zero").Parse(t.Title)
+	if err != nil {
+		return nil, err
+	}
+	body, err := template.New(name + "/body").Funcs(funcs).Option("missingkey=zero").Parse(t.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &compiled{title: title, body: body}, nil
+}
+
+// Engine 按事件和语言渲染通知。Reload 原子替换模板集，渲染可并发进行。
+type Engine struct {
+	source Source
+
+	mu        sync.RWMutex
+	templates map[templateKey]*compiled
+}
+
+// NewEngine 创建引擎，初始只包含内置模板 (source 可为 nil)
+func NewEngine(source Source) *Engine {
+	e := &Engine{source: source}
+	e.templates = e.build(nil)
+	return e
+}
+
+// build 内置模板加上来源模板 (覆盖同键)，无法解析的来源模板跳过
+func (e *Engine) build(overrides []Template) map[templateKey]*compiled {
+	set := make(map[templateKey]*compiled, len(defaultTemplates)+len(overrides))
+	for _, t := range defaultTemplates {
+		c, err := compile(t)
+		if err != nil {
+			panic(fmt.Sprintf("notify: invalid default template %s: %v", t.Event, err))
+		}
+		set[templateKey{t.Event, t.Locale}] = c
+	}
+	for _, t := range overrides {
+		locale := NormalizeLocale(t.Locale)
+		c, err := compile(t)
+		if err != nil {
+			log.Warn().Err(err).Str("event", string(t.Event)).Str("locale", locale).Msg("Skipping invalid notification template")
+			continue
+		}
+		set[templateKey{t.Event, locale}] = c
+	}
+	return set
+}
+
+// Reload 从来源重新加载模板。加载失败时保留当前模板。
+func (e *Engine) Reload(ctx context.Context) error {
+	if e.source == nil {
+		return nil
+	}
+	templates, err := e.source.ListNotificationTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	set := e.build(templates)
+
+	e.mu.Lock()
+	e.templates = set
+	e.mu.Unlock()
+	return nil
+}
+
+// Run 定期重新加载模板，reload 收到消息时立即加载 (可为 nil)
+func (e *Engine) Run(ctx context.Context, interval time.Duration, reload <-chan []byte) {
+	if e.source == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-reload:
+			if !ok {
+				reload = nil
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := e.Reload(ctx); err != nil {
+			log.Error().Err(err).Msg("Notification template reload failed")
+		}
+	}
+}
+
+// Render 渲染通知。语言回退顺序: 完整语言标签 (pt-br) → 主语言 (pt) → DefaultLocale，
+// 某个模板执行出错时继续尝试下一个。
+func (e *Engine) Render(event Event, locale string, vars Vars) (Rendered, error) {
+	e.mu.RLock()
+	templates := e.templates
+	e.mu.RUnlock()
+
+	err := fmt.Errorf("no notification template for %s", event)
+	for _, candidate := range localeCandidates(locale) {
+		c := templates[templateKey{event, candidate}]
+		if c == nil {
+			continue
+		}
+		var title, body bytes.Buffer
+		if err = c.title.Execute(&title, vars); err == nil {
+			err = c.body.Execute(&body, vars)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("event", string(event)).Str("locale", candidate).Msg("Notification template failed to render")
+			continue
+		}
+		return Rendered{Locale: candidate, Title: title.String(), Body: body.String()}, nil
+	}
+	return Rendered{}, err
+}
+
+// NormalizeLocale 统一为小写并以 '-' 分隔 (zh_CN → zh-cn)
+func NormalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+func localeCandidates(locale string) []string {
+	locale = NormalizeLocale(locale)
+	var out []string
+	if locale != "" {
+		out = append(out, locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok && base != "" {
+			out = append(out, base)
+		}
+	}
+	return append(out, DefaultLocale)
+}
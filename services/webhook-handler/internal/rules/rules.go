@@ -0,0 +1,405 @@
+// Package rules evaluates card authorizations against declarative business
+// rules (conditions, priority, action). Rules are stored in the
+// authorization_rules table and reloaded at runtime, so compliance can add
+// rules such as "decline MCC 7995 over $100 at night" without a deploy.
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReloadChannel 管理端修改规则后向该 Redis 频道发布消息，各实例立即重新加载
+const ReloadChannel = "authorization:rules:reload"
+
+// Action 规则命中后的动作
+type Action string
+
+const (
+	ActionDecline Action = "decline" // 拒绝授权，停止评估
+	ActionApprove Action = "approve" // 跳过后续规则 (仍需余额冻结成功)
+	ActionFlag    Action = "flag"    // 记录告警，继续评估
+)
+
+// DefaultDeclineReason 拒绝规则未指定原因时使用
+const DefaultDeclineReason = "risk_decline"
+
+// Fields 可用于条件的授权字段
+const (
+	FieldProgram  = "program"
+	FieldCardID   = "card_id"
+	FieldUserID   = "user_id"
+	FieldMerchant = "merchant"
+	FieldMCC      = "mcc"
+	FieldAmount   = "amount"
+	FieldCurrency = "currency"
+	FieldHour     = "hour"    // 规则时区内的小时 (0-23)
+	FieldWeekday  = "weekday" // 规则时区内的星期 (0 = 周日)
+)
+
+// Operators
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpIn       = "in"
+	OpNotIn    = "not_in"
+	OpContains = "contains" // 字符串，不区分大小写
+	OpBetween  = "between"  // 数值 [from, to)，from > to 时跨越午夜 (如 hour [22, 6])
+)
+
+// Rule 一条授权规则。所有条件都满足时执行动作。
+//
+//	{"name": "No late-night gambling", "priority": 10, "timezone": "America/New_York",
+//	 "conditions": [{"field": "mcc", "op": "eq", "value": "7995"},
+//	                {"field": "amount", "op": "gt", "value": 100},
+//	                {"field": "hour", "op": "between", "value": [22, 6]}],
+//	 "action": "decline", "reason": "prohibited_merchant"}
+type Rule struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Priority   int         `json:"priority"` // 越小越先评估
+	Program    string      `json:"program"`  // 为空时适用于所有发卡方
+	Conditions []Condition `json:"conditions"`
+	Action     Action      `json:"action"`
+	Reason     string      `json:"reason"`   // 拒绝原因 (发卡方拒绝码)
+	Timezone   string      `json:"timezone"` // hour / weekday 使用的时区 (默认 UTC)
+}
+
+// Condition 字段比较。value 为数字、字符串，in / not_in / between 时为数组。
+type Condition struct {
+	Field string          `json:"field"`
+	Op    string          `json:"op"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Input 待评估的授权
+type Input struct {
+	Program  string
+	CardID   string
+	UserID   string
+	Merchant string
+	MCC      string
+	Amount   float64
+	Currency string
+	Time     time.Time
+}
+
+// Decision 评估结果
+type Decision struct {
+	Action  Action   // 命中的 decline / approve 规则动作，未命中时为空
+	Rule    string   // 命中规则的名称
+	Reason  string   // 拒绝原因
+	Flagged []string // 命中的 flag 规则名称
+}
+
+// Declined 是否拒绝授权
+func (d Decision) Declined() bool { return d.Action == ActionDecline }
+
+// Source 规则来源 (WebhookStore 基于 authorization_rules 表实现)
+type Source interface {
+	ListAuthorizationRules(ctx context.Context) ([]Rule, error)
+}
+
+type compiledRule struct {
+	Rule
+	location   *time.Location
+	conditions []predicate
+}
+
+type predicate func(in *Input, loc *time.Location) bool
+
+// compile 校验规则并编译条件
+func compile(r Rule) (*compiledRule, error) {
+	switch r.Action {
+	case ActionDecline, ActionApprove, ActionFlag:
+	default:
+		return nil, fmt.Errorf("unknown action %q", r.Action)
+	}
+	c := &compiledRule{Rule: r, location: time.UTC}
+	if r.Timezone != "" {
+		loc, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		c.location = loc
+	}
+	if c.Action == ActionDecline && c.Reason == "" {
+		c.Reason = DefaultDeclineReason
+	}
+	for i, cond := range r.Conditions {
+		p, err := compileCondition(cond)
+		if err != nil {
+			return nil, fmt.Errorf("conditions[%d]: %w", i, err)
+		}
+		c.conditions = append(c.conditions, p)
+	}
+	return c, nil
+}
+
+func compileCondition(cond Condition) (predicate, error) {
+	switch cond.Field {
+	case FieldAmount, FieldHour, FieldWeekday:
+		return compileNumber(cond)
+	case FieldProgram, FieldCardID, FieldUserID, FieldMerchant, FieldMCC, FieldCurrency:
+		return compileString(cond)
+	default:
+		return nil, fmt.Errorf("unknown field %q", cond.Field)
+	}
+}
+
+func numberField(field string, in *Input, loc *time.Location) float64 {
+	switch field {
+	case FieldAmount:
+		return in.Amount
+	case FieldHour:
+		return float64(in.Time.In(loc).Hour())
+	default:
+		return float64(in.Time.In(loc).Weekday())
+	}
+}
+
+func stringField(field string, in *Input) string {
+	switch field {
+	case FieldProgram:
+		return in.Program
+	case FieldCardID:
+		return in.CardID
+	case FieldUserID:
+		return in.UserID
+	case FieldMerchant:
+		return in.Merchant
+	case FieldMCC:
+		return in.MCC
+	default:
+		return in.Currency
+	}
+}
+
+func compileNumber(cond Condition) (predicate, error) {
+	field := cond.Field
+	value := func(in *Input, loc *time.Location) float64 { return numberField(field, in, loc) }
+
+	switch cond.Op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		var want float64
+		if err := json.Unmarshal(cond.Value, &want); err != nil {
+			return nil, fmt.Errorf("%s %s: value must be a number", field, cond.Op)
+		}
+		cmp := map[string]func(a float64) bool{
+			OpEq:  func(a float64) bool { return a == want },
+			OpNe:  func(a float64) bool { return a != want },
+			OpGt:  func(a float64) bool { return a > want },
+			OpGte: func(a float64) bool { return a >= want },
+			OpLt:  func(a float64) bool { return a < want },
+			OpLte: func(a float64) bool { return a <= want },
+		}[cond.Op]
+		return func(in *Input, loc *time.Location) bool { return cmp(value(in, loc)) }, nil
+	case OpIn, OpNotIn:
+		var set []float64
+		if err := json.Unmarshal(cond.Value, &set); err != nil {
+			return nil, fmt.Errorf("%s %s: value must be an array of numbers", field, cond.Op)
+		}
+		negate := cond.Op == OpNotIn
+		return func(in *Input, loc *time.Location) bool {
+			v := value(in, loc)
+			for _, s := range set {
+				if v == s {
+					return !negate
+				}
+			}
+			return negate
+		}, nil
+	case OpBetween:
+		var bounds []float64
+		if err := json.Unmarshal(cond.Value, &bounds); err != nil || len(bounds) != 2 {
+			return nil, fmt.Errorf("%s between: value must be [from, to]", field)
+		}
+		from, to := bounds[0], bounds[1]
+		return func(in *Input, loc *time.Location) bool {
+			v := value(in, loc)
+			if from <= to {
+				return v >= from && v < to
+			}
+			return v >= from || v < to
+		}, nil
+	default:
+		return nil, fmt.Errorf("operator %q is not supported for %s", cond.Op, field)
+	}
+}
+
+func compileString(cond Condition) (predicate, error) {
+	field := cond.Field
+	switch cond.Op {
+	case OpEq, OpNe, OpContains:
+		want, err := stringValue(cond.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", field, cond.Op, err)
+		}
+		switch cond.Op {
+		case OpEq:
+			return func(in *Input, _ *time.Location) bool { return strings.EqualFold(stringField(field, in), want) }, nil
+		case OpNe:
+			return func(in *Input, _ *time.Location) bool { return !strings.EqualFold(stringField(field, in), want) }, nil
+		default:
+			want = strings.ToLower(want)
+			return func(in *Input, _ *time.Location) bool {
+				return strings.Contains(strings.ToLower(stringField(field, in)), want)
+			}, nil
+		}
+	case OpIn, OpNotIn:
+		var raw []json.RawMessage
+		if err := json.Unmarshal(cond.Value, &raw); err != nil {
+			return nil, fmt.Errorf("%s %s: value must be an array", field, cond.Op)
+		}
+		set := make(map[string]bool, len(raw))
+		for _, r := range raw {
+			v, err := stringValue(r)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", field, cond.Op, err)
+			}
+			set[strings.ToLower(v)] = true
+		}
+		negate := cond.Op == OpNotIn
+		return func(in *Input, _ *time.Location) bool {
+			return set[strings.ToLower(stringField(field, in))] != negate
+		}, nil
+	default:
+		return nil, fmt.Errorf("operator %q is not supported for %s", cond.Op, field)
+	}
+}
+
+// stringValue 接受字符串或数字 (MCC 常被写成数字)
+func stringValue(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		if _, err := strconv.ParseFloat(n.String(), 64); err == nil {
+			return n.String(), nil
+		}
+	}
+	return "", fmt.Errorf("value must be a string")
+}
+
+func (c *compiledRule) matches(in *Input) bool {
+	if c.Program != "" && !strings.EqualFold(c.Program, in.Program) {
+		return false
+	}
+	for _, p := range c.conditions {
+		if !p(in, c.location) {
+			return false
+		}
+	}
+	return true
+}
+
+// Engine 按优先级评估规则。Reload 原子替换规则集，评估可并发进行。
+type Engine struct {
+	source Source
+
+	mu    sync.RWMutex
+	rules []*compiledRule
+}
+
+// NewEngine 创建引擎，初始没有规则 (source 可为 nil)
+func NewEngine(source Source) *Engine {
+	return &Engine{source: source}
+}
+
+// Set 替换规则集，无法编译的规则跳过
+func (e *Engine) Set(rules []Rule) {
+	set := make([]*compiledRule, 0, len(rules))
+	for _, r := range rules {
+		c, err := compile(r)
+		if err != nil {
+			log.Warn().Err(err).Str("rule_id", r.ID).Str("rule", r.Name).Msg("Skipping invalid authorization rule")
+			continue
+		}
+		set = append(set, c)
+	}
+	sort.SliceStable(set, func(i, j int) bool { return set[i].Priority < set[j].Priority })
+
+	e.mu.Lock()
+	e.rules = set
+	e.mu.Unlock()
+}
+
+// Reload 从来源重新加载规则。加载失败时保留当前规则。
+func (e *Engine) Reload(ctx context.Context) error {
+	if e.source == nil {
+		return nil
+	}
+	rules, err := e.source.ListAuthorizationRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load authorization rules: %w", err)
+	}
+	e.Set(rules)
+	return nil
+}
+
+// Run 定期重新加载规则，reload 收到消息时立即加载 (可为 nil)
+func (e *Engine) Run(ctx context.Context, interval time.Duration, reload <-chan []byte) {
+	if e.source == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-reload:
+			if !ok {
+				reload = nil
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := e.Reload(ctx); err != nil {
+			log.Error().Err(err).Msg("Authorization rule reload failed")
+		}
+	}
+}
+
+// Evaluate 按优先级评估规则: 第一条命中的 decline / approve 规则决定结果，
+// flag 规则只记录。没有命中时 Action 为空 (继续默认检查)。
+func (e *Engine) Evaluate(in Input) Decision {
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	if in.Time.IsZero() {
+		in.Time = time.Now()
+	}
+	var d Decision
+	for _, r := range rules {
+		if !r.matches(&in) {
+			continue
+		}
+		if r.Action == ActionFlag {
+			d.Flagged = append(d.Flagged, r.Name)
+			continue
+		}
+		d.Action, d.Rule, d.Reason = r.Action, r.Name, r.Reason
+		return d
+	}
+	return d
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	rules []Rule
+	err   error
+}
+
+func (s *staticSource) ListAuthorizationRules(context.Context) ([]Rule, error) {
+	return s.rules, s.err
+}
+
+func parseRule(t *testing.T, raw string) Rule {
+	t.Helper()
+	var r Rule
+	require.NoError(t, json.Unmarshal([]byte(raw), &r))
+	return r
+}
+
+func TestEvaluate(t *testing.T) {
+	nightGambling := parseRule(t, `{"name": "night gambling", "priority": 10, "timezone": "America/New_York",
+		"conditions": [{"field": "mcc", "op": "eq", "value": 7995},
+		               {"field": "amount", "op": "gt", "value": 100},
+		               {"field": "hour", "op": "between", "value": [22, 6]}],
+		"action": "decline", "reason": "prohibited_merchant"}`)
+	trusted := parseRule(t, `{"name": "trusted card", "priority": 5,
+		"conditions": [{"field": "card_id", "op": "in", "value": ["vip-1"]}], "action": "approve"}`)
+	large := parseRule(t, `{"name": "large amount", "priority": 1,
+		"conditions": [{"field": "amount", "op": "gte", "value": 500}], "action": "flag"}`)
+	foreign := parseRule(t, `{"name": "foreign currency", "priority": 20, "program": "RAIN",
+		"conditions": [{"field": "currency", "op": "not_in", "value": ["USD", "EUR"]}], "action": "decline"}`)
+
+	e := NewEngine(nil)
+	e.Set([]Rule{nightGambling, foreign, trusted, large})
+
+	night := time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC) // 23:00 in New York
+	day := time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)  // 14:00 in New York
+
+	t.Run("declines matching rule", func(t *testing.T) {
+		d := e.Evaluate(Input{Program: "RAIN", CardID: "c1", MCC: "7995", Amount: 150, Currency: "USD", Time: night})
+		assert.True(t, d.Declined())
+		assert.Equal(t, "night gambling", d.Rule)
+		assert.Equal(t, "prohibited_merchant", d.Reason)
+	})
+
+	t.Run("all conditions must match", func(t *testing.T) {
+		assert.False(t, e.Evaluate(Input{Program: "RAIN", MCC: "7995", Amount: 150, Currency: "USD", Time: day}).Declined())
+		assert.False(t, e.Evaluate(Input{Program: "RAIN", MCC: "7995", Amount: 50, Currency: "USD", Time: night}).Declined())
+		assert.False(t, e.Evaluate(Input{Program: "RAIN", MCC: "5411", Amount: 150, Currency: "USD", Time: night}).Declined())
+	})
+
+	t.Run("higher priority approve stops evaluation", func(t *testing.T) {
+		d := e.Evaluate(Input{Program: "RAIN", CardID: "vip-1", MCC: "7995", Amount: 600, Currency: "USD", Time: night})
+		assert.Equal(t, ActionApprove, d.Action)
+		assert.Equal(t, []string{"large amount"}, d.Flagged)
+	})
+
+	t.Run("program scoped rules and default reason", func(t *testing.T) {
+		d := e.Evaluate(Input{Program: "RAIN", Amount: 10, Currency: "GBP", Time: day})
+		assert.True(t, d.Declined())
+		assert.Equal(t, DefaultDeclineReason, d.Reason)
+		assert.False(t, e.Evaluate(Input{Program: "OTHER", Amount: 10, Currency: "GBP", Time: day}).Declined())
+	})
+}
+
+func TestInvalidRulesSkipped(t *testing.T) {
+	e := NewEngine(nil)
+	e.Set([]Rule{
+		parseRule(t, `{"name": "bad field", "conditions": [{"field": "country", "op": "eq", "value": "US"}], "action": "decline"}`),
+		parseRule(t, `{"name": "bad op", "conditions": [{"field": "mcc", "op": "gt", "value": "7995"}], "action": "decline"}`),
+		parseRule(t, `{"name": "bad between", "conditions": [{"field": "hour", "op": "between", "value": [22]}], "action": "decline"}`),
+		parseRule(t, `{"name": "bad action", "action": "block"}`),
+		parseRule(t, `{"name": "bad timezone", "timezone": "Mars/Olympus", "action": "decline"}`),
+		parseRule(t, `{"name": "merchant", "conditions": [{"field": "merchant", "op": "contains", "value": "casino"}], "action": "decline"}`),
+	})
+
+	assert.True(t, e.Evaluate(Input{Merchant: "Lucky CASINO Online"}).Declined())
+	assert.False(t, e.Evaluate(Input{Merchant: "Coffee", MCC: "7995", Currency: "USD"}).Declined())
+}
+
+func TestReload(t *testing.T) {
+	source := &staticSource{rules: []Rule{parseRule(t, `{"name": "all", "action": "decline"}`)}}
+	e := NewEngine(source)
+	assert.False(t, e.Evaluate(Input{}).Declined(), "no rules before the first load")
+
+	require.NoError(t, e.Reload(context.Background()))
+	assert.True(t, e.Evaluate(Input{}).Declined())
+
+	source.err = assert.AnError
+	assert.Error(t, e.Reload(context.Background()))
+	assert.True(t, e.Evaluate(Input{}).Declined(), "failed reload keeps current rules")
+}
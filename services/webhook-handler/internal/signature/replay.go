@@ -0,0 +1,50 @@
+package signature
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryReplayCache is an in-process ReplayCache for tests and single-instance use.
+// Multi-instance deployments should use the Redis-backed store.
+type MemoryReplayCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryReplayCache creates an empty cache.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Claim implements ReplayCache.
+func (c *MemoryReplayCache) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, exp := range c.entries {
+		if now.After(exp) {
+			delete(c.entries, k)
+		}
+	}
+
+	if _, ok := c.entries[key]; ok {
+		return false, nil
+	}
+	c.entries[key] = now.Add(ttl)
+	return true, nil
+}
+
+// Release implements ReplayCache.
+func (c *MemoryReplayCache) Release(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RainScheme: X-Rain-Signature = hex(HMAC-SHA256(secret, timestamp + "." + body)),
+// X-Rain-Timestamp = unix seconds.
+type RainScheme struct{}
+
+// Name implements Scheme.
+func (RainScheme) Name() string { return "rain" }
+
+// Parse implements Scheme.
+func (RainScheme) Parse(header http.Header, body []byte) (Envelope, error) {
+	timestamp := header.Get("X-Rain-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || ts <= 0 {
+		return Envelope{}, fmt.Errorf("%w: invalid X-Rain-Timestamp", ErrExpired)
+	}
+
+	message := make([]byte, 0, len(timestamp)+1+len(body))
+	message = append(message, timestamp...)
+	message = append(message, '.')
+	message = append(message, body...)
+
+	return Envelope{
+		Message:   message,
+		Signature: header.Get("X-Rain-Signature"),
+		Timestamp: time.Unix(ts, 0),
+	}, nil
+}
+
+// TransakScheme: X-Transak-Signature = hex(HMAC-SHA256(secret, body)), no timestamp.
+type TransakScheme struct{}
+
+// Name implements Scheme.
+func (TransakScheme) Name() string { return "transak" }
+
+// Parse implements Scheme.
+func (TransakScheme) Parse(header http.Header, body []byte) (Envelope, error) {
+	return Envelope{
+		Message:   body,
+		Signature: header.Get("X-Transak-Signature"),
+	}, nil
+}
@@ -4,20 +4,62 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	_ "github.com/lib/pq"
 	"github.com/protocol-bank/webhook-handler/internal/config"
+	"github.com/protocol-bank/webhook-handler/internal/export"
+	"github.com/protocol-bank/webhook-handler/internal/notify"
+	"github.com/protocol-bank/webhook-handler/internal/rules"
 )
 
+// ErrCardNotFound 事件引用的卡片不存在 (重试无法修复)
+var ErrCardNotFound = errors.New("corporate card not found")
+
 // WebhookStore Webhook 存储
 type WebhookStore struct {
 	db    *sql.DB
 	redis *redis.Client
 }
 
+// queryer *sql.DB 与 *sql.Tx 的公共方法
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type eventTxKey struct{}
+
+// conn ctx 中携带事件事务 (ProcessEvent) 时在事务内执行，否则直接使用连接池
+func (s *WebhookStore) conn(ctx context.Context) queryer {
+	if tx, ok := ctx.Value(eventTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// inTx 在 ctx 的事件事务内执行 fn，没有事件事务时开启独立事务
+func (s *WebhookStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(eventTxKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // NewWebhookStore 创建存储
 func NewWebhookStore(ctx context.Context, dbURL string, redisCfg config.RedisConfig) (*WebhookStore, error) {
 	// 连接数据库
@@ -68,6 +110,44 @@ func (s *WebhookStore) MarkProcessed(ctx context.Context, eventID, payload strin
 	return s.redis.Set(ctx, key, payload, 7*24*time.Hour).Err()
 }
 
+// Claim 记录签名以防重放 (实现 signature.ReplayCache)
+func (s *WebhookStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.redis.SetNX(ctx, key, 1, ttl).Result()
+}
+
+// Release 释放签名记录，使发送方对失败投递的重试不被视为重放 (实现 signature.ReplayCache)
+func (s *WebhookStore) Release(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, key).Err()
+}
+
+// Publish 发布消息 (实现 stream.PubSub)
+func (s *WebhookStore) Publish(ctx context.Context, channel string, payload []byte) error {
+	return s.redis.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe 订阅频道 (实现 stream.PubSub)
+func (s *WebhookStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error, error) {
+	ps := s.redis.Subscribe(ctx, channel)
+	// 等待订阅确认，避免订阅前发布的消息丢失
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, nil, err
+	}
+
+	out := make(chan []byte, 16)
+	go func() {
+		defer close(out)
+		for msg := range ps.Channel() {
+			select {
+			case out <- []byte(msg.Payload):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, ps.Close, nil
+}
+
 // SaveWebhook 保存 Webhook 记录到数据库
 func (s *WebhookStore) SaveWebhook(ctx context.Context, source, eventType, eventID, payload string) error {
 	query := `
@@ -102,70 +182,507 @@ func (s *WebhookStore) UpsertFiatOrder(ctx context.Context, orderID, status stri
 			tx_hash = EXCLUDED.tx_hash,
 			updated_at = NOW()
 	`
-	_, err := s.db.ExecContext(ctx, query, orderID, status, amount, currency, wallet, txHash)
+	_, err := s.conn(ctx).ExecContext(ctx, query, orderID, status, amount, currency, wallet, txHash)
 	return err
 }
 
-// UpdateCardTransaction Records a corporate card transaction (Rain)
-func (s *WebhookStore) UpdateCardTransaction(ctx context.Context, txID, cardID, merchant string, amount float64, currency, status string) error {
+// UpdateCardTransaction Records a corporate card transaction for a card program
+func (s *WebhookStore) UpdateCardTransaction(ctx context.Context, program, txID, cardID, merchant string, amount float64, currency, status string) error {
 	// 1. Get internal Card ID mapping
 	var internalID string
-	err := s.db.QueryRowContext(ctx, "SELECT id FROM corporate_cards WHERE external_id = $1", cardID).Scan(&internalID)
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT id FROM corporate_cards WHERE program = $1 AND external_id = $2", program, cardID).Scan(&internalID)
 	if err == sql.ErrNoRows {
 		// Log warning or create implicit card placeholder? For now, error out.
-		return fmt.Errorf("corporate card %s not found", cardID)
+		return fmt.Errorf("%w: %s", ErrCardNotFound, cardID)
 	} else if err != nil {
 		return err
 	}
 
 	// 2. Insert/Update Transaction
 	query := `
-		INSERT INTO card_transactions (external_id, card_id, merchant_name, amount, currency, status, type, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, 'SETTLEMENT', NOW())
-		ON CONFLICT (external_id) DO UPDATE SET
+		INSERT INTO card_transactions (program, external_id, card_id, merchant_name, amount, currency, status, type, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'SETTLEMENT', NOW())
+		ON CONFLICT (program, external_id) DO UPDATE SET
 			status = EXCLUDED.status,
 			updated_at = NOW()
 	`
-	_, err = s.db.ExecContext(ctx, query, txID, internalID, merchant, amount, currency, status)
+	_, err = s.conn(ctx).ExecContext(ctx, query, program, txID, internalID, merchant, amount, currency, status)
 	return err
 }
 
-// UpdateCardBalance Updates the card balance
-func (s *WebhookStore) UpdateCardBalance(ctx context.Context, cardID string, amount float64) error {
-	query := `
-		UPDATE corporate_cards 
-		SET balance = balance - $2, updated_at = NOW() 
-		WHERE external_id = $1
-	`
-	// Note: Subtract amount for spending
-	_, err := s.db.ExecContext(ctx, query, cardID, amount)
+// CardAuthorization An approved authorization holding funds on a card
+type CardAuthorization struct {
+	ID           string // Issuer authorization ID
+	CardID       string // Issuer card ID
+	MerchantName string
+	Amount       float64 // Held amount
+	Currency     string
+	CreatedAt    time.Time
+}
+
+// CardSettlement A settled card transaction. AuthorizationID is the matched
+// authorization, empty when none matched.
+type CardSettlement struct {
+	TransactionID   string
+	CardID          string
+	MerchantName    string
+	Amount          float64
+	Currency        string
+	Status          string
+	AuthorizationID string
+}
+
+// SettlementOutcome Result of applying a settlement
+type SettlementOutcome struct {
+	Applied     bool    // false if the transaction was already settled
+	Matched     bool    // the authorization was still pending and is now settled
+	Released    float64 // hold minus settled amount credited back (negative: extra debit)
+	NeedsReview bool
+}
+
+// HoldCardAuthorization Records an approved authorization and deducts its amount
+// from the balance. Returns false if the balance is insufficient. Retried
+// authorizations with the same ID are held only once.
+func (s *WebhookStore) HoldCardAuthorization(ctx context.Context, program string, auth CardAuthorization) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var internalID string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM corporate_cards WHERE program = $1 AND external_id = $2 FOR UPDATE", program, auth.CardID).Scan(&internalID)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("%w: %s", ErrCardNotFound, auth.CardID)
+	} else if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO card_authorizations (id, program, external_id, card_id, merchant_name, amount, currency, status, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, 'PENDING', NOW())
+		ON CONFLICT (program, external_id) DO NOTHING
+	`, program, auth.ID, internalID, auth.MerchantName, auth.Amount, auth.Currency)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return true, tx.Commit()
+	}
+
+	res, err = tx.ExecContext(ctx, "UPDATE corporate_cards SET balance = balance - $2, updated_at = NOW() WHERE id = $1 AND balance >= $2", internalID, auth.Amount)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
+// ListPendingAuthorizations Authorizations on a card created since the given time that have not settled yet
+func (s *WebhookStore) ListPendingAuthorizations(ctx context.Context, program, cardID string, since time.Time) ([]CardAuthorization, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, `
+		SELECT a.external_id, c.external_id, COALESCE(a.merchant_name, ''), a.amount, a.currency, a.created_at
+		FROM card_authorizations a
+		JOIN corporate_cards c ON c.id = a.card_id
+		WHERE a.program = $1 AND c.external_id = $2 AND a.status = 'PENDING' AND a.created_at >= $3
+		ORDER BY a.created_at
+	`, program, cardID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auths []CardAuthorization
+	for rows.Next() {
+		var a CardAuthorization
+		if err := rows.Scan(&a.ID, &a.CardID, &a.MerchantName, &a.Amount, &a.Currency, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		auths = append(auths, a)
+	}
+	return auths, rows.Err()
+}
+
+// SettleCardTransaction Records a settlement once per transaction. A matched
+// pending authorization is closed and the difference between its hold and the
+// settled amount is returned to the balance; otherwise the full amount is
+// deducted and the transaction is flagged for review. Runs inside the event
+// transaction when ctx carries one (ProcessEvent).
+func (s *WebhookStore) SettleCardTransaction(ctx context.Context, program string, st CardSettlement) (SettlementOutcome, error) {
+	var out SettlementOutcome
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var internalID string
+		err := tx.QueryRowContext(ctx, "SELECT id FROM corporate_cards WHERE program = $1 AND external_id = $2 FOR UPDATE", program, st.CardID).Scan(&internalID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrCardNotFound, st.CardID)
+		} else if err != nil {
+			return err
+		}
+
+		// 卡片行锁使同一张卡的结算串行，已结算时在任何写入之前返回
+		var settled bool
+		err = tx.QueryRowContext(ctx, "SELECT settled_at IS NOT NULL FROM card_transactions WHERE program = $1 AND external_id = $2", program, st.TransactionID).Scan(&settled)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if settled {
+			return nil
+		}
+
+		var hold float64
+		if st.AuthorizationID != "" {
+			err = tx.QueryRowContext(ctx, `
+				UPDATE card_authorizations SET status = 'SETTLED', settled_amount = $4, transaction_id = $5, updated_at = NOW()
+				WHERE program = $1 AND external_id = $2 AND card_id = $3 AND status = 'PENDING'
+				RETURNING amount
+			`, program, st.AuthorizationID, internalID, st.Amount, st.TransactionID).Scan(&hold)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			out.Matched = err == nil
+		}
+		out.NeedsReview = !out.Matched
+
+		var authID sql.NullString
+		if out.Matched {
+			authID = sql.NullString{String: st.AuthorizationID, Valid: true}
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO card_transactions (id, program, external_id, card_id, merchant_name, amount, currency, status, type, authorization_id, needs_review, settled_at, updated_at)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, 'SETTLEMENT', $8, $9, NOW(), NOW())
+			ON CONFLICT (program, external_id) DO UPDATE SET
+				status = EXCLUDED.status,
+				amount = EXCLUDED.amount,
+				authorization_id = EXCLUDED.authorization_id,
+				needs_review = EXCLUDED.needs_review,
+				settled_at = EXCLUDED.settled_at,
+				updated_at = NOW()
+			WHERE card_transactions.settled_at IS NULL
+			RETURNING id
+		`, program, st.TransactionID, internalID, st.MerchantName, st.Amount, st.Currency, st.Status, authID, out.NeedsReview).Scan(new(string))
+		if err == sql.ErrNoRows {
+			// 行锁下不应发生: 回滚授权状态变更，发卡方重试时按已结算处理
+			return fmt.Errorf("card transaction %s was settled concurrently", st.TransactionID)
+		} else if err != nil {
+			return err
+		}
+
+		out.Applied = true
+		out.Released = hold - st.Amount
+		_, err = tx.ExecContext(ctx, "UPDATE corporate_cards SET balance = balance + $2, updated_at = NOW() WHERE id = $1", internalID, out.Released)
+		return err
+	})
+	if err != nil || !out.Applied {
+		return SettlementOutcome{}, err
+	}
+	return out, nil
+}
+
+// CreditCardBalance Adds funds to the card balance (top-ups)
+func (s *WebhookStore) CreditCardBalance(ctx context.Context, program, cardID string, amount float64) error {
+	query := `UPDATE corporate_cards SET balance = balance + $3, updated_at = NOW() WHERE program = $1 AND external_id = $2`
+	_, err := s.conn(ctx).ExecContext(ctx, query, program, cardID, amount)
 	return err
 }
 
-// GetCardBalance Retrieves current balance
-func (s *WebhookStore) GetCardBalance(ctx context.Context, cardID string) (float64, error) {
-	var balance float64
-	err := s.db.QueryRowContext(ctx, "SELECT balance FROM corporate_cards WHERE external_id = $1", cardID).Scan(&balance)
-	return balance, err
+// UpdateCardSpendingLimit Sets the card spending limit (nil clears it)
+func (s *WebhookStore) UpdateCardSpendingLimit(ctx context.Context, program, cardID string, limit *float64) error {
+	query := `UPDATE corporate_cards SET spending_limit = $3, updated_at = NOW() WHERE program = $1 AND external_id = $2`
+	_, err := s.conn(ctx).ExecContext(ctx, query, program, cardID, limit)
+	return err
+}
+
+// CardSnapshot Current balance and limit of a card
+type CardSnapshot struct {
+	Program       string
+	CardID        string
+	UserID        string
+	Balance       float64
+	SpendingLimit *float64
+	Currency      string
+}
+
+const cardSnapshotColumns = `program, external_id, user_id, balance, spending_limit, COALESCE(currency, 'USD')`
+
+func scanCardSnapshot(row interface{ Scan(...interface{}) error }) (CardSnapshot, error) {
+	var c CardSnapshot
+	var limit sql.NullFloat64
+	if err := row.Scan(&c.Program, &c.CardID, &c.UserID, &c.Balance, &limit, &c.Currency); err != nil {
+		return CardSnapshot{}, err
+	}
+	if limit.Valid {
+		c.SpendingLimit = &limit.Float64
+	}
+	return c, nil
+}
+
+// GetCardSnapshot Retrieves balance, limit and owner by the program's card ID
+func (s *WebhookStore) GetCardSnapshot(ctx context.Context, program, cardID string) (CardSnapshot, error) {
+	row := s.conn(ctx).QueryRowContext(ctx, "SELECT "+cardSnapshotColumns+" FROM corporate_cards WHERE program = $1 AND external_id = $2", program, cardID)
+	c, err := scanCardSnapshot(row)
+	if err == sql.ErrNoRows {
+		return c, fmt.Errorf("%w: %s", ErrCardNotFound, cardID)
+	}
+	return c, err
+}
+
+// ListUserCardSnapshots Retrieves balances of all of a user's cards across programs
+func (s *WebhookStore) ListUserCardSnapshots(ctx context.Context, userID string) ([]CardSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+cardSnapshotColumns+" FROM corporate_cards WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []CardSnapshot
+	for rows.Next() {
+		c, err := scanCardSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, rows.Err()
+}
+
+// ListCardStatementEntries Returns one page of a user's card transactions posted in
+// [q.From, q.To), ordered by posting time then ID, after the (q.After, q.AfterID) cursor
+// (implements export.Source). Declined transactions moved no funds and are omitted.
+func (s *WebhookStore) ListCardStatementEntries(ctx context.Context, userID string, q export.Query) ([]export.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.program, t.external_id, COALESCE(c.last4, ''), COALESCE(t.merchant_name, ''),
+			t.amount, t.currency, t.status, t.type, COALESCE(t.settled_at, t.created_at) AS posted_at
+		FROM card_transactions t
+		JOIN corporate_cards c ON c.id = t.card_id
+		WHERE c.user_id = $1
+			AND t.status <> 'DECLINED'
+			AND COALESCE(t.settled_at, t.created_at) >= $2
+			AND COALESCE(t.settled_at, t.created_at) < $3
+			AND (COALESCE(t.settled_at, t.created_at), t.id) > ($4, $5)
+		ORDER BY posted_at, t.id
+		LIMIT $6
+	`, userID, q.From, q.To, q.After, q.AfterID, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []export.Entry
+	for rows.Next() {
+		var e export.Entry
+		if err := rows.Scan(&e.ID, &e.Program, &e.ExternalID, &e.CardLast4, &e.Merchant,
+			&e.Amount, &e.Currency, &e.Status, &e.Type, &e.PostedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
 }
 
 // UpsertCardStatus Creates or updates a corporate card record
-func (s *WebhookStore) UpsertCardStatus(ctx context.Context, externalID, userID, last4, status string) error {
+func (s *WebhookStore) UpsertCardStatus(ctx context.Context, program, externalID, userID, last4, status string) error {
 	query := `
-		INSERT INTO corporate_cards (id, external_id, user_id, last4, status, updated_at)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
-		ON CONFLICT (external_id) DO UPDATE SET
+		INSERT INTO corporate_cards (id, program, external_id, user_id, last4, status, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (program, external_id) DO UPDATE SET
 			status = EXCLUDED.status,
 			last4 = EXCLUDED.last4,
 			updated_at = NOW()
 	`
-	_, err := s.db.ExecContext(ctx, query, externalID, userID, last4, status)
+	_, err := s.conn(ctx).ExecContext(ctx, query, program, externalID, userID, last4, status)
+	return err
+}
+
+// UpdateCardStatusByExternalID Updates card status by the program's external card ID
+func (s *WebhookStore) UpdateCardStatusByExternalID(ctx context.Context, program, externalID, status string) error {
+	query := `UPDATE corporate_cards SET status = $3, updated_at = NOW() WHERE program = $1 AND external_id = $2`
+	_, err := s.conn(ctx).ExecContext(ctx, query, program, externalID, status)
+	return err
+}
+
+// ListNotificationTemplates Loads all notification templates (implements notify.Source)
+func (s *WebhookStore) ListNotificationTemplates(ctx context.Context) ([]notify.Template, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT event_type, locale, title, body FROM notification_templates")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []notify.Template
+	for rows.Next() {
+		var t notify.Template
+		var event string
+		if err := rows.Scan(&event, &t.Locale, &t.Title, &t.Body); err != nil {
+			return nil, err
+		}
+		t.Event = notify.Event(event)
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// ListAuthorizationRules Loads enabled authorization rules (implements rules.Source)
+func (s *WebhookStore) ListAuthorizationRules(ctx context.Context) ([]rules.Rule, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, priority, COALESCE(program, ''), conditions, action, COALESCE(reason, ''), COALESCE(timezone, '')
+		FROM authorization_rules
+		WHERE enabled = true`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []rules.Rule
+	for rows.Next() {
+		var r rules.Rule
+		var action string
+		var conditions []byte
+		if err := rows.Scan(&r.ID, &r.Name, &r.Priority, &r.Program, &conditions, &action, &r.Reason, &r.Timezone); err != nil {
+			return nil, err
+		}
+		r.Action = rules.Action(action)
+		if err := json.Unmarshal(conditions, &r.Conditions); err != nil {
+			return nil, fmt.Errorf("authorization rule %s: invalid conditions: %w", r.ID, err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UserLocale Returns the user's preferred locale, "" if unset (implements notify.UserLocales)
+func (s *WebhookStore) UserLocale(ctx context.Context, userID string) (string, error) {
+	var locale sql.NullString
+	err := s.db.QueryRowContext(ctx, "SELECT locale FROM auth_users WHERE id = $1", userID).Scan(&locale)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return locale.String, err
+}
+
+// UserIDByWallet Finds the user owning a wallet address, "" if none
+func (s *WebhookStore) UserIDByWallet(ctx context.Context, wallet string) (string, error) {
+	var userID string
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT id FROM auth_users WHERE lower(wallet_address) = lower($1) LIMIT 1", wallet).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return userID, err
+}
+
+// ============================================================================
+// Event Transactions & Notification Outbox
+// ============================================================================
+
+// ProcessEvent 在一个数据库事务内处理 webhook 事件: 先写入去重记录 (processed_webhook_events)，
+// 再以携带该事务的 ctx 调用 apply，其中的存储写入和通知入队随去重记录一起提交。
+// 事件已处理过时不调用 apply 并返回 false；apply 返回错误时整体回滚，发送方重试时重新处理。
+func (s *WebhookStore) ProcessEvent(ctx context.Context, source, eventID, eventType string, payload []byte, apply func(ctx context.Context) error) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO processed_webhook_events (source, event_id, event_type, payload, processed_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (source, event_id) DO NOTHING
+	`, source, eventID, eventType, string(payload))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err := apply(context.WithValue(ctx, eventTxKey{}, tx)); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// EnqueueNotification 将通知写入 outbox，ctx 携带事件事务时随事件一起提交。
+// 同一通知 ID 只入队一次。
+func (s *WebhookStore) EnqueueNotification(ctx context.Context, n notify.Notification) error {
+	vars, err := json.Marshal(n.Vars)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn(ctx).ExecContext(ctx, `
+		INSERT INTO notification_outbox (id, message_id, user_id, event_type, vars, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+		ON CONFLICT (message_id) DO NOTHING
+	`, n.ID, n.UserID, string(n.Event), vars)
+	return err
+}
+
+// ClaimNotifications Claims due outbox notifications for delivery and pushes
+// their next attempt past the lease (implements notify.Outbox). SKIP LOCKED
+// lets several instances claim concurrently.
+func (s *WebhookStore) ClaimNotifications(ctx context.Context, limit int, lease time.Duration) ([]notify.Pending, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE notification_outbox o SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+		FROM (
+			SELECT id FROM notification_outbox
+			WHERE dispatched_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE o.id = due.id
+		RETURNING o.id, o.message_id, o.user_id, o.event_type, o.vars, o.attempts, o.created_at
+	`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []notify.Pending
+	created := make(map[string]time.Time)
+	for rows.Next() {
+		var p notify.Pending
+		var event string
+		var vars []byte
+		var createdAt time.Time
+		if err := rows.Scan(&p.OutboxID, &p.ID, &p.UserID, &event, &vars, &p.Attempts, &createdAt); err != nil {
+			return nil, err
+		}
+		p.Event = notify.Event(event)
+		if err := json.Unmarshal(vars, &p.Vars); err != nil {
+			return nil, fmt.Errorf("outbox notification %s: invalid vars: %w", p.OutboxID, err)
+		}
+		created[p.OutboxID] = createdAt
+		out = append(out, p)
+	}
+	// RETURNING 不保证顺序，按入队先后投递
+	sort.SliceStable(out, func(i, j int) bool { return created[out[i].OutboxID].Before(created[out[j].OutboxID]) })
+	return out, rows.Err()
+}
+
+// MarkNotificationSent Marks an outbox notification as delivered (implements notify.Outbox)
+func (s *WebhookStore) MarkNotificationSent(ctx context.Context, outboxID string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE notification_outbox SET dispatched_at = NOW(), updated_at = NOW() WHERE id = $1", outboxID)
+	return err
+}
+
+// RetryNotification Records a failed delivery and schedules the next attempt (implements notify.Outbox)
+func (s *WebhookStore) RetryNotification(ctx context.Context, outboxID string, delay time.Duration, cause string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, last_error = $3, next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+		WHERE id = $1
+	`, outboxID, delay.Milliseconds(), cause)
 	return err
 }
 
-// UpdateCardStatusByExternalID Updates card status by Rain external card ID
-func (s *WebhookStore) UpdateCardStatusByExternalID(ctx context.Context, externalID, status string) error {
-	query := `UPDATE corporate_cards SET status = $2, updated_at = NOW() WHERE external_id = $1`
-	_, err := s.db.ExecContext(ctx, query, externalID, status)
+// FailNotification Records a failed delivery and stops retrying (implements notify.Outbox)
+func (s *WebhookStore) FailNotification(ctx context.Context, outboxID string, cause string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, last_error = $2, failed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, outboxID, cause)
 	return err
 }
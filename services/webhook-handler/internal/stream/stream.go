@@ -0,0 +1,176 @@
+// Package stream pushes card balance and limit changes to connected frontends
+// over Server-Sent Events. Events fan out through Redis pub/sub so any
+// webhook-handler instance can serve any user's stream.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventType 余额变动类型
+type EventType string
+
+const (
+	EventSnapshot   EventType = "snapshot"   // 连接建立时的当前余额
+	EventSettlement EventType = "settlement" // 交易结算扣款
+	EventTopUp      EventType = "topup"      // 充值
+	EventHold       EventType = "hold"       // 授权冻结
+	EventLimit      EventType = "limit"      // 限额变更
+)
+
+// BalanceEvent 推送给前端的余额/限额变更
+type BalanceEvent struct {
+	Type          EventType `json:"type"`
+	UserID        string    `json:"user_id"`
+	CardID        string    `json:"card_id"`
+	Balance       float64   `json:"balance"`
+	SpendingLimit *float64  `json:"spending_limit,omitempty"`
+	Amount        float64   `json:"amount,omitempty"` // 本次变动金额
+	Currency      string    `json:"currency,omitempty"`
+	Timestamp     int64     `json:"timestamp"`
+}
+
+// PubSub 跨实例广播 (WebhookStore 基于 Redis 实现)
+type PubSub interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	// Subscribe returns a message channel and a function that unsubscribes.
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error, error)
+}
+
+// SnapshotFunc 返回用户当前所有卡片余额，连接建立时首先推送
+type SnapshotFunc func(ctx context.Context, userID string) ([]BalanceEvent, error)
+
+// Config Broker 配置
+type Config struct {
+	TokenSecret string        // 与前端共享的流令牌签名密钥
+	Heartbeat   time.Duration // 心跳间隔，默认 25s (需小于代理空闲超时)
+	Snapshot    SnapshotFunc  // 可选
+}
+
+// Broker 发布余额事件并通过 SSE 推送给用户
+type Broker struct {
+	ps        PubSub
+	cfg       Config
+	now       func() time.Time
+	heartbeat time.Duration
+}
+
+// NewBroker 创建 Broker
+func NewBroker(ps PubSub, cfg Config) *Broker {
+	heartbeat := cfg.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 25 * time.Second
+	}
+	return &Broker{ps: ps, cfg: cfg, now: time.Now, heartbeat: heartbeat}
+}
+
+func channelFor(userID string) string {
+	return fmt.Sprintf("card:balance:%s", userID)
+}
+
+// Publish 广播余额事件给该用户的所有连接
+func (b *Broker) Publish(ctx context.Context, evt BalanceEvent) error {
+	if evt.UserID == "" {
+		return errors.New("balance event has no user_id")
+	}
+	if evt.Timestamp == 0 {
+		evt.Timestamp = b.now().Unix()
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	return b.ps.Publish(ctx, channelFor(evt.UserID), payload)
+}
+
+// ServeHTTP 建立 SSE 连接: GET /stream/balances?token=<stream token>
+// (EventSource 无法设置请求头，令牌通过查询参数传递，也接受 Bearer 头)
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if h := r.Header.Get("Authorization"); token == "" && len(h) > 7 && h[:7] == "Bearer " {
+		token = h[7:]
+	}
+	userID, err := VerifyToken(b.cfg.TokenSecret, token, b.now())
+	if err != nil {
+		log.Warn().Err(err).Str("remote_addr", r.RemoteAddr).Msg("Rejected balance stream connection")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+	msgs, unsubscribe, err := b.ps.Subscribe(ctx, channelFor(userID))
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to subscribe to balance events")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	// 长连接不受服务器 WriteTimeout 限制
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data []byte) error {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	// 先推送当前余额，之后只推送增量
+	if b.cfg.Snapshot != nil {
+		snapshot, err := b.cfg.Snapshot(ctx, userID)
+		if err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Failed to load balance snapshot")
+		}
+		for _, evt := range snapshot {
+			evt.Type = EventSnapshot
+			evt.UserID = userID
+			evt.Timestamp = b.now().Unix()
+			data, _ := json.Marshal(evt)
+			if err := send("balance", data); err != nil {
+				return
+			}
+		}
+	}
+
+	log.Info().Str("user_id", userID).Msg("Balance stream connected")
+	defer log.Info().Str("user_id", userID).Msg("Balance stream disconnected")
+
+	ticker := time.NewTicker(b.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			if err := send("balance", msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
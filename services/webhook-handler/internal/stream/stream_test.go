@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPubSub is an in-process PubSub for tests
+type memoryPubSub struct {
+	mu   sync.Mutex
+	subs map[string][]chan []byte
+}
+
+func newMemoryPubSub() *memoryPubSub {
+	return &memoryPubSub{subs: make(map[string][]chan []byte)}
+}
+
+func (m *memoryPubSub) Publish(ctx context.Context, channel string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.subs[channel] {
+		ch <- payload
+	}
+	return nil
+}
+
+func (m *memoryPubSub) Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error, error) {
+	ch := make(chan []byte, 8)
+	m.mu.Lock()
+	m.subs[channel] = append(m.subs[channel], ch)
+	m.mu.Unlock()
+	return ch, func() error { return nil }, nil
+}
+
+func (m *memoryPubSub) subscribers(channel string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subs[channel])
+}
+
+func TestStreamToken(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token := SignToken("secret", "user:42", now.Add(time.Minute))
+
+	userID, err := VerifyToken("secret", token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "user:42", userID)
+
+	_, err = VerifyToken("other-secret", token, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = VerifyToken("secret", token, now.Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = VerifyToken("secret", "", now)
+	assert.ErrorIs(t, err, ErrMissingToken)
+}
+
+func TestBrokerStreamsBalanceEvents(t *testing.T) {
+	ps := newMemoryPubSub()
+	limit := 500.0
+	broker := NewBroker(ps, Config{
+		TokenSecret: "secret",
+		Snapshot: func(ctx context.Context, userID string) ([]BalanceEvent, error) {
+			return []BalanceEvent{{CardID: "card_1", Balance: 100, SpendingLimit: &limit, Currency: "USD"}}, nil
+		},
+	})
+
+	server := httptest.NewServer(broker)
+	defer server.Close()
+
+	t.Run("rejects missing token", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("sends snapshot then published events", func(t *testing.T) {
+		token := SignToken("secret", "user_1", time.Now().Add(time.Minute))
+		resp, err := http.Get(server.URL + "?token=" + token)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		readData := func() string {
+			for {
+				line, err := reader.ReadString('\n')
+				require.NoError(t, err)
+				if strings.HasPrefix(line, "data: ") {
+					return strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+				}
+			}
+		}
+
+		snapshot := readData()
+		assert.Contains(t, snapshot, `"type":"snapshot"`)
+		assert.Contains(t, snapshot, `"balance":100`)
+		assert.Contains(t, snapshot, `"spending_limit":500`)
+
+		require.Eventually(t, func() bool { return ps.subscribers("card:balance:user_1") == 1 }, time.Second, 10*time.Millisecond)
+
+		// 其他用户的事件不应推送
+		require.NoError(t, broker.Publish(context.Background(), BalanceEvent{Type: EventTopUp, UserID: "user_2", CardID: "card_9", Balance: 1}))
+		require.NoError(t, broker.Publish(context.Background(), BalanceEvent{Type: EventSettlement, UserID: "user_1", CardID: "card_1", Balance: 75, Amount: 25}))
+
+		evt := readData()
+		assert.Contains(t, evt, `"type":"settlement"`)
+		assert.Contains(t, evt, `"balance":75`)
+		assert.Contains(t, evt, `"amount":25`)
+	})
+}
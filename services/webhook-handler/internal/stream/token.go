@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stream token errors
+var (
+	ErrMissingToken = errors.New("missing stream token")
+	ErrInvalidToken = errors.New("invalid stream token")
+	ErrTokenExpired = errors.New("stream token expired")
+)
+
+// SignToken issues a stream token for userID valid until expiresAt.
+// Format: base64url("<user_id>:<unix expiry>") + "." + hex(HMAC-SHA256).
+// The frontend backend mints tokens with the same secret after authenticating the user.
+func SignToken(secret, userID string, expiresAt time.Time) string {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", userID, expiresAt.Unix())))
+	return claims + "." + sign(secret, claims)
+}
+
+// VerifyToken checks the signature and expiry and returns the user ID.
+func VerifyToken(secret, token string, now time.Time) (string, error) {
+	if secret == "" {
+		return "", errors.New("stream token secret not configured")
+	}
+	if token == "" {
+		return "", ErrMissingToken
+	}
+	claims, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(secret, claims))) {
+		return "", ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(claims)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	sep := strings.LastIndexByte(string(raw), ':')
+	if sep <= 0 {
+		return "", ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(string(raw[sep+1:]), 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if now.Unix() > exp {
+		return "", ErrTokenExpired
+	}
+	return string(raw[:sep]), nil
+}
+
+func sign(secret, claims string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(claims))
+	return hex.EncodeToString(mac.Sum(nil))
+}